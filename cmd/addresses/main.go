@@ -20,7 +20,10 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"log"
+	"strings"
 
+	"prime-send-receive-go/internal/api"
 	"prime-send-receive-go/internal/common"
 	"prime-send-receive-go/internal/config"
 	"prime-send-receive-go/internal/database"
@@ -36,16 +39,20 @@ type reportStats struct {
 }
 
 func printUserHeader(user common.UserInfo, addressCount int) {
-	fmt.Printf("\n┌─ User: %s (%s)\n", user.Name, user.Email)
-	fmt.Printf("│  ID: %s\n", user.Id)
-	fmt.Printf("│  Addresses: %d\n", addressCount)
+	fmt.Printf("\n%s User: %s (%s)\n", common.BoxTopPrefix(), user.Name, user.Email)
+	fmt.Printf("%sID: %s\n", common.BoxDetailPrefix(false), user.Id)
+	fmt.Printf("%sAddresses: %d\n", common.BoxDetailPrefix(false), addressCount)
 	common.PrintBoxSeparator(98)
 }
 
 func printAddress(addr models.Address, isLast bool) {
 	symbol := common.BoxPrefix(isLast)
 	assetNetwork := fmt.Sprintf("%s-%s", addr.Asset, addr.Network)
-	fmt.Printf("%s %-30s → %s\n", symbol, assetNetwork, addr.Address)
+	if addr.Label != "" {
+		fmt.Printf("%s %-30s %s %s [%s]\n", symbol, assetNetwork, common.Arrow(), addr.Address, addr.Label)
+	} else {
+		fmt.Printf("%s %-30s %s %s\n", symbol, assetNetwork, common.Arrow(), addr.Address)
+	}
 
 	if shouldPrintAccountIdentifier(addr) {
 		detailSymbol := common.BoxDetailPrefix(isLast)
@@ -64,11 +71,37 @@ func printAddresses(addresses []models.Address) {
 	}
 }
 
-func processUser(ctx context.Context, user common.UserInfo, dbService *database.Service, logger *zap.Logger) (int, error) {
-	addresses, err := dbService.GetAllUserAddresses(ctx, user.Id)
+// filterByLabel keeps the addresses with a label; "none" selects unlabeled addresses
+func filterByLabel(addresses []models.Address, label string) []models.Address {
+	if label == "" {
+		return addresses
+	}
+	if label == "none" {
+		label = ""
+	}
+
+	filtered := make([]models.Address, 0, len(addresses))
+	for _, addr := range addresses {
+		if strings.EqualFold(addr.Label, label) {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
+
+// addressFilter narrows the report; empty fields match everything
+type addressFilter struct {
+	asset   string
+	network string
+	label   string
+}
+
+func processUser(ctx context.Context, user common.UserInfo, dbService *database.Service, filter addressFilter, logger *zap.Logger) (int, error) {
+	addresses, err := dbService.FilterUserAddresses(ctx, user.Id, filter.asset, filter.network)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get addresses: %w", err)
 	}
+	addresses = filterByLabel(addresses, filter.label)
 
 	if len(addresses) == 0 {
 		return 0, nil
@@ -80,13 +113,13 @@ func processUser(ctx context.Context, user common.UserInfo, dbService *database.
 	return len(addresses), nil
 }
 
-func processUsersAndGenerateReport(ctx context.Context, users []common.UserInfo, dbService *database.Service, logger *zap.Logger) reportStats {
+func processUsersAndGenerateReport(ctx context.Context, users []common.UserInfo, dbService *database.Service, filter addressFilter, logger *zap.Logger) reportStats {
 	stats := reportStats{}
 
 	for _, user := range users {
 		stats.totalUsers++
 
-		addressCount, err := processUser(ctx, user, dbService, logger)
+		addressCount, err := processUser(ctx, user, dbService, filter, logger)
 		if err != nil {
 			logger.Error("Failed to process user",
 				zap.String("user_id", user.Id),
@@ -104,42 +137,216 @@ func processUsersAndGenerateReport(ctx context.Context, users []common.UserInfo,
 	return stats
 }
 
+// configuredNetworks returns the enabled asset-network pairs from assets.yaml for the symbol,
+// narrowed to one network when set
+func configuredNetworks(assetsFile, symbol, network string) ([]models.AssetConfig, error) {
+	assetConfigs, err := common.LoadAssetConfig(assetsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset config: %w", err)
+	}
+
+	var configured []models.AssetConfig
+	for _, asset := range assetConfigs {
+		if !asset.IsEnabled() || !strings.EqualFold(asset.Symbol, symbol) {
+			continue
+		}
+		if network != "" && !strings.EqualFold(asset.Network, network) {
+			continue
+		}
+		configured = append(configured, asset)
+	}
+	if len(configured) == 0 {
+		return nil, fmt.Errorf("%s is not configured in %s", symbol, assetsFile)
+	}
+	return configured, nil
+}
+
+// printProvisioningGaps lists users without a deposit address for each configured network of the asset
+func printProvisioningGaps(ctx context.Context, users []common.UserInfo, dbService *database.Service, configured []models.AssetConfig) (int, error) {
+	common.PrintHeader("PROVISIONING GAP REPORT", common.WideWidth)
+
+	gaps := 0
+	for _, asset := range configured {
+		missing, err := dbService.ListUsersMissingAddress(ctx, asset.Symbol, asset.Network)
+		if err != nil {
+			return 0, err
+		}
+		missingIds := make(map[string]bool, len(missing))
+		for _, userId := range missing {
+			missingIds[userId] = true
+		}
+
+		var shown []common.UserInfo
+		for _, user := range users {
+			if missingIds[user.Id] {
+				shown = append(shown, user)
+			}
+		}
+		gaps += len(shown)
+
+		fmt.Printf("\n%s: %d users missing an address\n", asset.AssetNetwork(), len(shown))
+		for i, user := range shown {
+			fmt.Printf("%s %-30s %s (%s)\n", common.BoxPrefix(i == len(shown)-1), user.Email, user.Name, user.Id)
+		}
+	}
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d missing addresses across %d asset networks (%d users queried)",
+		gaps, len(configured), len(users)), common.WideWidth)
+	return gaps, nil
+}
+
+// printDepositInstructions shows what a user is told when depositing the asset. With lazy deposit addresses,
+// services creates the user's missing addresses first; it is nil otherwise.
+func printDepositInstructions(ctx context.Context, dbService *database.Service, services *common.Services, cfg *models.Config, email, asset, network string, logger *zap.Logger) error {
+	user, err := dbService.GetUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	assetConfigs, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load asset config: %w", err)
+	}
+
+	ledger := api.NewLedgerService(dbService, logger.Named("ledger"))
+	ledger.SetAssetConfigs(assetConfigs, cfg.Listener.FundsAvailability)
+	if services != nil {
+		ledger.SetAddressProvisioner(services.Custody, services.DefaultPortfolio.Id, services.Wallets)
+		ledger.SetLazyAddresses(true)
+	}
+	instructions, err := ledger.GetDepositInstructions(ctx, user.Id, asset, network)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader(fmt.Sprintf("DEPOSIT INSTRUCTIONS: %s (%s)", user.Name, user.Email), common.WideWidth)
+	for _, instruction := range instructions {
+		fmt.Printf("\n%s-%s\n", instruction.Asset, instruction.Network)
+		fmt.Printf("   Address:      %s\n", instruction.Address)
+		if instruction.MemoRequired {
+			fmt.Printf("   Memo:         %s\n", instruction.Memo)
+		}
+		if instruction.MinimumDeposit != nil {
+			fmt.Printf("   Minimum:      %s\n", instruction.MinimumDeposit.String())
+		}
+		fmt.Printf("   Confirmation: %s\n", instruction.ConfirmationPolicy)
+		for _, warning := range instruction.Warnings {
+			fmt.Printf("   Warning:      %s\n", warning)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d deposit networks", len(instructions)), common.WideWidth)
+	return nil
+}
+
 func main() {
 	ctx := context.Background()
 
-	logger, loggerCleanup := common.InitializeLogger()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
 	defer loggerCleanup()
 
 	// Parse command line flags
 	emailFlag := flag.String("email", "", "Filter by specific user email (optional)")
+	tenantFlag := flag.String("tenant", "", "Only show users of this tenant (default from TENANT_ID)")
+	labelFlag := flag.String("label", "", "Only show addresses with this label (\"none\" for unlabeled)")
+	addressFlag := flag.String("address", "", "Address to label with --set-label")
+	setLabelFlag := flag.String("set-label", "", "Set the label of --address (use \"none\" to clear) and exit")
+	assetFlag := flag.String("asset", "", "Only show addresses for this asset symbol (optional)")
+	networkFlag := flag.String("network", "", "Only show addresses on this network (optional)")
+	missingFlag := flag.Bool("missing", false, "List users without an address for each configured network of --asset")
+	instructionsFlag := flag.Bool("instructions", false, "Show deposit instructions for --email and --asset (optionally one --network), then exit")
+	common.RegisterOutputFlags(flag.CommandLine)
+	common.RegisterLockFlags(flag.CommandLine)
 	flag.Parse()
+	asset := strings.ToUpper(*assetFlag)
+
+	if *missingFlag && asset == "" {
+		logger.Fatal("--missing requires --asset")
+	}
+	if *instructionsFlag && (asset == "" || *emailFlag == "") {
+		logger.Fatal("--instructions requires --email and --asset")
+	}
 
 	logger.Info("Starting address query")
 
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Fatal("Failed to load config", zap.Error(err))
+	if *tenantFlag != "" {
+		cfg.Tenant.Id = *tenantFlag
 	}
 
 	// Initialize database service (no need for Prime API for read-only operations)
 	logger.Info("Connecting to database", zap.String("path", cfg.Database.Path))
-	dbService, err := common.InitializeDatabaseOnly(ctx, cfg)
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize database", zap.Error(err))
 	}
 	defer dbService.Close()
 
+	if *setLabelFlag != "" {
+		if *addressFlag == "" {
+			logger.Fatal("--set-label requires --address")
+		}
+		label := *setLabelFlag
+		if label == "none" {
+			label = ""
+		}
+		if err := api.NewLedgerService(dbService, logger.Named("ledger")).SetAddressLabel(ctx, *addressFlag, label); err != nil {
+			logger.Fatal("Failed to label address", zap.Error(err))
+		}
+		fmt.Printf("Labeled %s as %q\n", *addressFlag, label)
+		return
+	}
+
+	if *instructionsFlag {
+		// Lazy deposit addresses need Prime to create the user's missing addresses
+		var services *common.Services
+		if cfg.Wallet.LazyAddresses {
+			services, err = common.InitializeServices(ctx, cfg, logger)
+			if err != nil {
+				logger.Fatal("Failed to initialize services", zap.Error(err))
+			}
+			defer services.Close()
+
+			// Serializes wallet creation with cmd/setup and other provisioning runs
+			lock, err := common.AcquireCommandLock(ctx, services.DbService, "provision", logger)
+			if err != nil {
+				logger.Fatal("Cannot provision now", zap.Error(err))
+			}
+			defer lock.Release()
+		}
+		if err := printDepositInstructions(ctx, dbService, services, cfg, *emailFlag, asset, *networkFlag, logger); err != nil {
+			logger.Fatal("Failed to get deposit instructions", zap.Error(err))
+		}
+		return
+	}
+
 	users, err := common.InitializeUsers(ctx, dbService, *emailFlag, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize users", zap.Error(err))
 	}
 
+	if *missingFlag {
+		configured, err := configuredNetworks(cfg.Listener.AssetsFile, asset, *networkFlag)
+		if err != nil {
+			logger.Fatal("Failed to resolve configured networks", zap.Error(err))
+		}
+		gaps, err := printProvisioningGaps(ctx, users, dbService, configured)
+		if err != nil {
+			logger.Fatal("Failed to generate provisioning gap report", zap.Error(err))
+		}
+		logger.Info("Provisioning gap report completed", zap.String("asset", asset), zap.Int("missing", gaps))
+		return
+	}
+
 	// Print header
 	common.PrintHeader("DEPOSIT ADDRESSES REPORT", common.WideWidth)
 
 	// Process users and generate report
-	stats := processUsersAndGenerateReport(ctx, users, dbService, logger)
+	filter := addressFilter{asset: asset, network: *networkFlag, label: *labelFlag}
+	stats := processUsersAndGenerateReport(ctx, users, dbService, filter, logger)
 
 	// Print footer summary
 	summary := fmt.Sprintf("SUMMARY: %d users with addresses (%d total addresses across %d users queried)",
@@ -18,14 +18,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"log"
 	"regexp"
 	"strings"
 
 	"prime-send-receive-go/internal/common"
 	"prime-send-receive-go/internal/config"
 	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/notify"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
@@ -43,6 +47,7 @@ type addressGenerationResult struct {
 type generationStats struct {
 	successCount int
 	failedAssets []string
+	interrupted  bool
 }
 
 func validateEmail(email string) error {
@@ -65,7 +70,7 @@ func validateName(name string) error {
 	return nil
 }
 
-func checkExistingAddress(ctx context.Context, services *common.Services, userId string, assetConfig common.AssetConfig) (bool, error) {
+func checkExistingAddress(ctx context.Context, services *common.Services, userId string, assetConfig models.AssetConfig) (bool, error) {
 	existingAddresses, err := services.DbService.GetAddresses(ctx, userId, assetConfig.Symbol, assetConfig.Network)
 	if err != nil {
 		return false, fmt.Errorf("error checking existing addresses: %w", err)
@@ -79,16 +84,16 @@ func getOrCreateWallet(ctx context.Context, services *common.Services, assetSymb
 		return "", fmt.Errorf("error listing wallets: %w", err)
 	}
 
-	if len(wallets) > 0 {
-		walletId := wallets[0].Id
-		zap.L().Info("Using existing wallet",
+	if wallet := common.SelectTradingWallet(services.Wallets, wallets, assetSymbol); wallet != nil {
+		services.Logger.Info("Using existing wallet",
 			zap.String("asset", assetSymbol),
-			zap.String("wallet_id", walletId))
-		return walletId, nil
+			zap.String("wallet_name", wallet.Name),
+			zap.String("wallet_id", wallet.Id))
+		return wallet.Id, nil
 	}
 
-	walletName := fmt.Sprintf("%s Trading Wallet", assetSymbol)
-	zap.L().Info("Creating new wallet",
+	walletName := common.WalletName(services.Wallets, assetSymbol)
+	services.Logger.Info("Creating new wallet",
 		zap.String("asset", assetSymbol),
 		zap.String("wallet_name", walletName))
 
@@ -100,8 +105,8 @@ func getOrCreateWallet(ctx context.Context, services *common.Services, assetSymb
 	return newWallet.Id, nil
 }
 
-func generateAndStoreAddress(ctx context.Context, services *common.Services, userId string, assetConfig common.AssetConfig, walletId string) (string, error) {
-	zap.L().Info("Creating deposit address",
+func generateAndStoreAddress(ctx context.Context, services *common.Services, userId string, assetConfig models.AssetConfig, walletId string) (string, error) {
+	services.Logger.Info("Creating deposit address",
 		zap.String("asset", assetConfig.Symbol),
 		zap.String("network", assetConfig.Network),
 		zap.String("wallet_id", walletId))
@@ -126,8 +131,8 @@ func generateAndStoreAddress(ctx context.Context, services *common.Services, use
 	return storedAddress.Address, nil
 }
 
-func processAsset(ctx context.Context, services *common.Services, userId string, assetConfig common.AssetConfig) addressGenerationResult {
-	zap.L().Info("Processing asset",
+func processAsset(ctx context.Context, services *common.Services, userId string, assetConfig models.AssetConfig) addressGenerationResult {
+	services.Logger.Info("Processing asset",
 		zap.String("asset", assetConfig.Symbol),
 		zap.String("network", assetConfig.Network))
 
@@ -139,14 +144,14 @@ func processAsset(ctx context.Context, services *common.Services, userId string,
 	// Check if address already exists
 	exists, err := checkExistingAddress(ctx, services, userId, assetConfig)
 	if err != nil {
-		zap.L().Error("Failed to check existing addresses",
+		services.Logger.Error("Failed to check existing addresses",
 			zap.String("asset", assetConfig.Symbol),
 			zap.Error(err))
 		return result
 	}
 
 	if exists {
-		fmt.Printf("✓ %s-%s: Address already exists\n", assetConfig.Symbol, assetConfig.Network)
+		fmt.Printf("%s %s-%s: Address already exists\n", common.StatusMark(true), assetConfig.Symbol, assetConfig.Network)
 		result.success = true
 		return result
 	}
@@ -154,30 +159,30 @@ func processAsset(ctx context.Context, services *common.Services, userId string,
 	// Get or create wallet
 	walletId, err := getOrCreateWallet(ctx, services, assetConfig.Symbol)
 	if err != nil {
-		zap.L().Error("Failed to get or create wallet",
+		services.Logger.Error("Failed to get or create wallet",
 			zap.String("asset", assetConfig.Symbol),
 			zap.Error(err))
-		fmt.Printf("✗ %s-%s: Failed to get wallet\n", assetConfig.Symbol, assetConfig.Network)
+		fmt.Printf("%s %s-%s: Failed to get wallet\n", common.StatusMark(false), assetConfig.Symbol, assetConfig.Network)
 		return result
 	}
 
 	// Generate and store address
 	address, err := generateAndStoreAddress(ctx, services, userId, assetConfig, walletId)
 	if err != nil {
-		zap.L().Error("Failed to generate or store address",
+		services.Logger.Error("Failed to generate or store address",
 			zap.String("asset", assetConfig.Symbol),
 			zap.Error(err))
-		fmt.Printf("✗ %s-%s: Failed to create address\n", assetConfig.Symbol, assetConfig.Network)
+		fmt.Printf("%s %s-%s: Failed to create address\n", common.StatusMark(false), assetConfig.Symbol, assetConfig.Network)
 		return result
 	}
 
-	fmt.Printf("✓ %s-%s: %s\n", assetConfig.Symbol, assetConfig.Network, address)
+	fmt.Printf("%s %s-%s: %s\n", common.StatusMark(true), assetConfig.Symbol, assetConfig.Network, address)
 	result.success = true
 	result.address = address
 	return result
 }
 
-func generateAddressesForUser(ctx context.Context, services *common.Services, userId string, assetConfigs []common.AssetConfig) generationStats {
+func generateAddressesForUser(ctx context.Context, services *common.Services, userId string, assetConfigs []models.AssetConfig, shutdown *common.Shutdown) generationStats {
 	fmt.Printf("Generating deposit addresses for %d assets...\n\n", len(assetConfigs))
 
 	stats := generationStats{
@@ -185,77 +190,140 @@ func generateAddressesForUser(ctx context.Context, services *common.Services, us
 	}
 
 	for _, assetConfig := range assetConfigs {
+		// Stop between addresses so that no address is created in Prime without being stored
+		if shutdown.Requested() {
+			stats.interrupted = true
+			break
+		}
+
 		result := processAsset(ctx, services, userId, assetConfig)
 
 		if result.success {
 			stats.successCount++
 		} else {
-			stats.failedAssets = append(stats.failedAssets, assetConfig.Symbol)
+			stats.failedAssets = append(stats.failedAssets, assetConfig.AssetNetwork())
 		}
 	}
 
 	return stats
 }
 
+// retryCommand returns the command that creates a user's missing deposit addresses
+func retryCommand(tenantId string) string {
+	if tenantId != "" {
+		return fmt.Sprintf("TENANT_ID=%s go run cmd/setup/main.go", tenantId)
+	}
+	return "go run cmd/setup/main.go"
+}
+
+// notifyProvisioningFailure emails operators the asset/network pairs a new user has no address for
+func notifyProvisioningFailure(ctx context.Context, notifier notify.Notifier, user *models.User, failedAssets []string, tenantId string, logger *zap.Logger) {
+	var body strings.Builder
+	fmt.Fprintf(&body, "User %s (%s, id %s) was created, but deposit addresses could not be generated for:\n\n", user.Name, user.Email, user.Id)
+	for _, assetNetwork := range failedAssets {
+		fmt.Fprintf(&body, "  - %s\n", assetNetwork)
+	}
+	fmt.Fprintf(&body, "\nThe user cannot receive deposits in these assets until the addresses exist. Retry with:\n\n  %s\n", retryCommand(tenantId))
+
+	err := notifier.Notify(ctx, notify.Message{
+		Subject: fmt.Sprintf("Deposit address provisioning failed for %s (%d assets)", user.Email, len(failedAssets)),
+		Body:    body.String(),
+	})
+	if err != nil {
+		logger.Error("Failed to send provisioning failure notification", zap.String("user_id", user.Id), zap.Error(err))
+		fmt.Println("Failed to send the provisioning failure notification - see the log")
+		return
+	}
+	logger.Info("Sent provisioning failure notification", zap.String("user_id", user.Id), zap.Strings("failed_assets", failedAssets))
+}
+
 func main() {
 	ctx := context.Background()
 
-	_, loggerCleanup := common.InitializeLogger()
+	// Load configuration first so that flags can default to it
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
 	defer loggerCleanup()
 
 	// Parse command line flags
 	nameFlag := flag.String("name", "", "User's full name (required)")
 	emailFlag := flag.String("email", "", "User's email address (required)")
+	tenantFlag := flag.String("tenant", "", "Tenant to create the user in (default from TENANT_ID, else the default tenant)")
+	notifyFlag := flag.Bool("notify", cfg.Notify.ProvisioningFailures, "Email operators the failed assets if some addresses cannot be created")
+	common.RegisterOutputFlags(flag.CommandLine)
+	common.RegisterLockFlags(flag.CommandLine)
 	flag.Parse()
 
 	// Validate required flags
 	if *nameFlag == "" || *emailFlag == "" {
-		zap.L().Fatal("Both flags are required: --name and --email")
+		logger.Fatal("Both flags are required: --name and --email")
 	}
 
 	// Validate name
 	if err := validateName(*nameFlag); err != nil {
-		zap.L().Fatal("Invalid name", zap.Error(err))
+		logger.Fatal("Invalid name", zap.Error(err))
 	}
 
 	// Validate email
 	if err := validateEmail(*emailFlag); err != nil {
-		zap.L().Fatal("Invalid email", zap.Error(err))
+		logger.Fatal("Invalid email", zap.Error(err))
 	}
 
-	zap.L().Info("Starting user creation process",
+	logger.Info("Starting user creation process",
 		zap.String("name", *nameFlag),
 		zap.String("email", *emailFlag))
 
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		zap.L().Fatal("Failed to load config", zap.Error(err))
+	if *tenantFlag != "" {
+		cfg.Tenant.Id = *tenantFlag
+	}
+
+	var notifier notify.Notifier
+	if *notifyFlag {
+		notifier, err = notify.NewNotifier(cfg.Notify)
+		if err != nil {
+			logger.Fatal("Invalid notification configuration", zap.Error(err))
+		}
+		if notifier == nil {
+			logger.Fatal("--notify needs NOTIFY_SMTP_ADDR, NOTIFY_EMAIL_FROM and NOTIFY_EMAIL_TO")
+		}
 	}
 
 	// Initialize services (both database and Prime API for address generation)
-	zap.L().Info("Initializing services")
-	services, err := common.InitializeServices(ctx, cfg)
+	logger.Info("Initializing services")
+	services, err := common.InitializeServices(ctx, cfg, logger)
 	if err != nil {
-		zap.L().Fatal("Failed to initialize services", zap.Error(err))
+		logger.Fatal("Failed to initialize services", zap.Error(err))
 	}
 	defer services.Close()
 
+	lock, err := common.AcquireCommandLock(ctx, services.DbService, "adduser", logger)
+	if err != nil {
+		logger.Fatal("Cannot add user now", zap.Error(err))
+	}
+	defer lock.Release()
+
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
+
 	// Generate UUID for the new user
 	userId := uuid.New().String()
 
 	// Create user in database
-	zap.L().Info("Creating user in database",
+	logger.Info("Creating user in database",
 		zap.String("id", userId),
 		zap.String("name", *nameFlag),
 		zap.String("email", *emailFlag))
 
 	user, err := services.DbService.CreateUser(ctx, userId, *nameFlag, *emailFlag)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			zap.L().Fatal("User already exists with this email", zap.String("email", *emailFlag))
+		if errors.Is(err, database.ErrUserExists) {
+			logger.Fatal("User already exists with this email", zap.String("email", *emailFlag))
 		}
-		zap.L().Fatal("Failed to create user", zap.Error(err))
+		logger.Fatal("Failed to create user", zap.Error(err))
 	}
 
 	fmt.Println()
@@ -266,15 +334,21 @@ func main() {
 	common.PrintSeparator("=", common.DefaultWidth)
 	fmt.Println()
 
-	zap.L().Info("User created successfully", zap.String("id", user.Id))
+	logger.Info("User created successfully", zap.String("id", user.Id))
+
+	if cfg.Wallet.LazyAddresses {
+		fmt.Println("LAZY_DEPOSIT_ADDRESSES is set - deposit addresses are created on the user's first deposit instructions request")
+		return
+	}
 
 	// Load asset configuration
-	zap.L().Info("Loading asset configuration for address generation")
+	logger.Info("Loading asset configuration for address generation")
 	assetConfigs, err := common.LoadAssetConfig("assets.yaml")
 	if err != nil {
-		zap.L().Fatal("Failed to load asset config", zap.Error(err))
+		logger.Fatal("Failed to load asset config", zap.Error(err))
 	}
-	zap.L().Info("Asset configuration loaded", zap.Int("count", len(assetConfigs)))
+	assetConfigs = common.EnabledAssets(assetConfigs)
+	logger.Info("Asset configuration loaded", zap.Int("count", len(assetConfigs)))
 
 	if len(assetConfigs) == 0 {
 		fmt.Println("No assets configured in assets.yaml")
@@ -284,7 +358,7 @@ func main() {
 	}
 
 	// Generate deposit addresses for all configured assets
-	stats := generateAddressesForUser(ctx, services, user.Id, assetConfigs)
+	stats := generateAddressesForUser(ctx, services, user.Id, assetConfigs, shutdown)
 
 	// Print summary
 	fmt.Println()
@@ -298,16 +372,26 @@ func main() {
 	common.PrintSeparator("=", common.DefaultWidth)
 	fmt.Println()
 
-	if len(stats.failedAssets) > 0 {
-		zap.L().Warn("User created but some addresses failed to generate",
+	if stats.interrupted {
+		logger.Warn("Address generation interrupted",
+			zap.String("user_id", user.Id),
+			zap.Int("successful", stats.successCount),
+			zap.Int("failed", len(stats.failedAssets)))
+		fmt.Println("Interrupted - user created but not all deposit addresses were generated")
+		fmt.Println("Run setup to generate the rest: go run cmd/setup/main.go")
+	} else if len(stats.failedAssets) > 0 {
+		logger.Warn("User created but some addresses failed to generate",
 			zap.String("user_id", user.Id),
 			zap.Int("successful", stats.successCount),
 			zap.Int("failed", len(stats.failedAssets)),
 			zap.Strings("failed_assets", stats.failedAssets))
 		fmt.Println("User created successfully but some deposit addresses failed to generate")
-		fmt.Println("You can re-run setup to retry: go run cmd/setup/main.go")
+		fmt.Printf("You can re-run setup to retry: %s\n", retryCommand(cfg.Tenant.Id))
+		if notifier != nil {
+			notifyProvisioningFailure(ctx, notifier, user, stats.failedAssets, cfg.Tenant.Id, logger)
+		}
 	} else {
-		zap.L().Info("User and all addresses created successfully",
+		logger.Info("User and all addresses created successfully",
 			zap.String("user_id", user.Id),
 			zap.Int("addresses_created", stats.successCount))
 		fmt.Println("User and all deposit addresses created successfully!")
@@ -0,0 +1,204 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  assets info [--fee-window DURATION] SYMBOL")
+}
+
+func printLocalConfig(symbol string, assetConfigs []models.AssetConfig) {
+	common.PrintHeader(fmt.Sprintf("%s - LOCAL CONFIGURATION", symbol), common.WideWidth)
+	if len(assetConfigs) == 0 {
+		fmt.Println("Not configured in assets.yaml")
+	}
+	for i, asset := range assetConfigs {
+		isLast := i == len(assetConfigs)-1
+		interval := "default"
+		if asset.Listener.PollingInterval > 0 {
+			interval = asset.Listener.PollingInterval.String()
+		}
+		fmt.Printf("%s %s (enabled: %t, deposits: %t, withdrawals: %t)\n",
+			common.BoxPrefix(isLast),
+			asset.AssetNetwork(),
+			asset.IsEnabled(),
+			asset.DepositsEnabled(),
+			asset.WithdrawalsEnabled())
+		fmt.Printf("%s polling: %s, credit status: %s, dust threshold: %s\n",
+			common.BoxDetailPrefix(isLast),
+			interval,
+			asset.CreditStatus(),
+			asset.Listener.DustThreshold.String())
+	}
+}
+
+func printPrimeMetadata(metadata *models.AssetMetadata, fees []models.NetworkFeeEstimate, feeWindow time.Duration) {
+	common.PrintHeader(fmt.Sprintf("%s - PRIME METADATA", metadata.Symbol), common.WideWidth)
+	fmt.Printf("Name:              %s\n", metadata.Name)
+	fmt.Printf("Decimal Precision: %s\n", metadata.DecimalPrecision)
+	if metadata.ExplorerUrl != "" {
+		fmt.Printf("Explorer:          %s\n", metadata.ExplorerUrl)
+	}
+
+	fmt.Println("\nNetworks:")
+	for i, network := range metadata.Networks {
+		isLast := i == len(metadata.Networks)-1
+		fmt.Printf("%s %s (%s) default: %t, vault: %t, destination tag required: %t, max decimals: %s\n",
+			common.BoxPrefix(isLast),
+			network.Id,
+			network.Name,
+			network.Default,
+			network.VaultSupported,
+			network.DestinationTagRequired,
+			network.MaxDecimals)
+	}
+
+	fmt.Printf("\nNetwork fees paid by withdrawals in the last %s:\n", feeWindow)
+	if len(fees) == 0 {
+		fmt.Println("  No withdrawals with network fees in this window")
+	}
+	for _, fee := range fees {
+		fmt.Printf("  %-20s avg %s %s, max %s %s (%d withdrawals)\n",
+			fee.Network, fee.Average.String(), fee.FeeSymbol, fee.Max.String(), fee.FeeSymbol, fee.Samples)
+	}
+}
+
+func printLedgerStats(stats *models.AssetLedgerStats) {
+	common.PrintHeader(fmt.Sprintf("%s - LEDGER", stats.Asset), common.WideWidth)
+	fmt.Printf("Total Balance:     %s\n", stats.TotalBalance.String())
+	fmt.Printf("Users Holding:     %d\n", stats.Holders)
+	fmt.Printf("Deposits:          %d\n", stats.DepositCount)
+	if stats.LastDepositAt != nil {
+		fmt.Printf("Last Deposit:      %s\n", stats.LastDepositAt.Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Println("Last Deposit:      never")
+	}
+	common.PrintSeparator("=", common.WideWidth)
+}
+
+func info(ctx context.Context, cfg *models.Config, args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	feeWindowFlag := fs.Duration("fee-window", 7*24*time.Hour, "How far back to look for withdrawals when estimating network fees")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("exactly one asset symbol is required")
+	}
+	symbol := strings.ToUpper(fs.Arg(0))
+
+	assetConfigs, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load asset config: %w", err)
+	}
+	var configured []models.AssetConfig
+	for _, asset := range assetConfigs {
+		if strings.EqualFold(asset.Symbol, symbol) {
+			configured = append(configured, asset)
+		}
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize services: %w", err)
+	}
+	defer services.Close()
+
+	printLocalConfig(symbol, configured)
+
+	// Prime lookups are best effort so the local and ledger views are still shown
+	metadata, err := services.PrimeService.GetAsset(ctx, services.DefaultPortfolio.EntityId, symbol)
+	if err != nil {
+		logger.Warn("Failed to load Prime asset metadata", zap.String("symbol", symbol), zap.Error(err))
+	} else {
+		fees, err := recentNetworkFees(ctx, services, symbol, *feeWindowFlag)
+		if err != nil {
+			logger.Warn("Failed to estimate network fees", zap.String("symbol", symbol), zap.Error(err))
+		}
+		printPrimeMetadata(metadata, fees, *feeWindowFlag)
+	}
+
+	stats, err := services.DbService.GetAssetLedgerStats(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to load ledger stats: %w", err)
+	}
+	printLedgerStats(stats)
+
+	return nil
+}
+
+// recentNetworkFees estimates fees from the withdrawals made by the asset's trading wallet
+func recentNetworkFees(ctx context.Context, services *common.Services, symbol string, window time.Duration) ([]models.NetworkFeeEstimate, error) {
+	wallets, err := services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{symbol})
+	if err != nil {
+		return nil, err
+	}
+	wallet := common.SelectTradingWallet(services.Wallets, wallets, symbol)
+	if wallet == nil {
+		return nil, nil
+	}
+
+	return services.PrimeService.RecentNetworkFees(ctx, services.DefaultPortfolio.Id, wallet.Id, time.Now().UTC().Add(-window))
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "info":
+		err = info(ctx, cfg, args, logger)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Assets command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -17,15 +17,27 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
 
+	"prime-send-receive-go/internal/api"
 	"prime-send-receive-go/internal/common"
 	"prime-send-receive-go/internal/config"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/pricing"
+	"prime-send-receive-go/internal/scrub"
 
+	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
@@ -35,6 +47,40 @@ type balanceStats struct {
 	usersWithBalances int
 }
 
+// valuation converts balances to USD for the optional AUM/dust view
+type valuation struct {
+	pricing       *pricing.Service
+	dustThreshold decimal.Decimal
+	aumUsd        decimal.Decimal
+	dustCount     int
+	unpriced      map[string]bool
+}
+
+// value returns a suffix describing the USD value of a balance and accumulates AUM
+func (v *valuation) value(ctx context.Context, balance models.AccountBalance) string {
+	if v == nil {
+		return ""
+	}
+
+	approx := "≈"
+	if common.PlainOutput() {
+		approx = "~"
+	}
+
+	usd, err := v.pricing.ToUsd(ctx, balance.Asset, balance.Balance)
+	if err != nil {
+		v.unpriced[balance.Asset] = true
+		return fmt.Sprintf(" %s $?", approx)
+	}
+	v.aumUsd = v.aumUsd.Add(usd)
+
+	if !v.dustThreshold.IsZero() && usd.Abs().LessThan(v.dustThreshold) {
+		v.dustCount++
+		return fmt.Sprintf(" %s $%s [dust]", approx, usd.StringFixed(2))
+	}
+	return fmt.Sprintf(" %s $%s", approx, usd.StringFixed(2))
+}
+
 func formatTransactionId(txId string) string {
 	if txId == "" {
 		return "none"
@@ -45,35 +91,46 @@ func formatTransactionId(txId string) string {
 	return txId
 }
 
-func printBalance(balance models.AccountBalance, isLast bool) {
+func printBalance(ctx context.Context, balance models.AccountBalance, isLast bool, v *valuation) {
 	symbol := common.BoxPrefix(isLast)
 	lastTx := formatTransactionId(balance.LastTransactionId)
 
-	fmt.Printf("%s %-15s: %20s (v%d, last_tx: %s, updated: %s)\n",
+	var pending string
+	if !balance.Available.Equal(balance.Balance) {
+		pending = fmt.Sprintf(" [available %s]", balance.Available.String())
+	}
+
+	fmt.Printf("%s %-15s: %20s%s (v%d, last_tx: %s, updated: %s)%s\n",
 		symbol,
 		balance.Asset,
 		balance.Balance.String(),
+		pending,
 		balance.Version,
 		lastTx,
-		balance.UpdatedAt.Format("2006-01-02 15:04:05"))
+		balance.UpdatedAt.Format("2006-01-02 15:04:05"),
+		v.value(ctx, balance))
 }
 
-func printBalances(balances []models.AccountBalance) {
+func printBalances(ctx context.Context, balances []models.AccountBalance, v *valuation) {
 	for i, balance := range balances {
 		isLast := i == len(balances)-1
-		printBalance(balance, isLast)
+		printBalance(ctx, balance, isLast, v)
 	}
 }
 
 func printUserHeader(user common.UserInfo, balanceCount int) {
-	fmt.Printf("\n┌─ User: %s (%s)\n", user.Name, user.Email)
-	fmt.Printf("│  ID: %s\n", user.Id)
-	fmt.Printf("│  Assets: %d\n", balanceCount)
+	fmt.Printf("\n%s User: %s (%s)\n", common.BoxTopPrefix(), user.Name, user.Email)
+	fmt.Printf("%sID: %s\n", common.BoxDetailPrefix(false), user.Id)
+	fmt.Printf("%sAssets: %d\n", common.BoxDetailPrefix(false), balanceCount)
 	common.PrintBoxSeparator(78)
 }
 
-func processUser(ctx context.Context, user common.UserInfo, dbService *database.Service, logger *zap.Logger) (int, error) {
-	balances, err := dbService.GetAllUserBalances(ctx, user.Id)
+func processUser(ctx context.Context, user common.UserInfo, dbService *database.Service, v *valuation, includeZero bool, logger *zap.Logger) (int, error) {
+	getBalances := dbService.GetAllUserBalances
+	if includeZero {
+		getBalances = dbService.GetAllUserBalancesIncludingZero
+	}
+	balances, err := getBalances(ctx, user.Id)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get balances: %w", err)
 	}
@@ -83,18 +140,18 @@ func processUser(ctx context.Context, user common.UserInfo, dbService *database.
 	}
 
 	printUserHeader(user, len(balances))
-	printBalances(balances)
+	printBalances(ctx, balances, v)
 
 	return len(balances), nil
 }
 
-func processUsersAndGenerateReport(ctx context.Context, users []common.UserInfo, dbService *database.Service, logger *zap.Logger) balanceStats {
+func processUsersAndGenerateReport(ctx context.Context, users []common.UserInfo, dbService *database.Service, v *valuation, includeZero bool, logger *zap.Logger) balanceStats {
 	stats := balanceStats{}
 
 	for _, user := range users {
 		stats.totalUsers++
 
-		balanceCount, err := processUser(ctx, user, dbService, logger)
+		balanceCount, err := processUser(ctx, user, dbService, v, includeZero, logger)
 		if err != nil {
 			logger.Error("Failed to process user",
 				zap.String("user_id", user.Id),
@@ -112,47 +169,427 @@ func processUsersAndGenerateReport(ctx context.Context, users []common.UserInfo,
 	return stats
 }
 
+// printNegativeBalanceReport lists accounts currently below zero and recent flagged events
+func printNegativeBalanceReport(ctx context.Context, users []common.UserInfo, dbService *database.Service) error {
+	emails := make(map[string]string, len(users))
+	for _, user := range users {
+		emails[user.Id] = user.Email
+	}
+
+	balances, err := dbService.ListNegativeBalances(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, err := dbService.ListNegativeBalanceEvents(ctx, 50)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("NEGATIVE BALANCE REPORT", common.DefaultWidth)
+
+	var shown []models.AccountBalance
+	for _, balance := range balances {
+		if _, ok := emails[balance.UserId]; ok {
+			shown = append(shown, balance)
+		}
+	}
+
+	fmt.Println("Accounts below zero:")
+	for i, balance := range shown {
+		fmt.Printf("%s %-30s %-15s %20s (last_tx: %s)\n",
+			common.BoxPrefix(i == len(shown)-1),
+			emails[balance.UserId],
+			balance.Asset,
+			balance.Balance.String(),
+			formatTransactionId(balance.LastTransactionId))
+	}
+
+	var shownEvents []models.NegativeBalanceEvent
+	for _, event := range events {
+		if _, ok := emails[event.UserId]; ok {
+			shownEvents = append(shownEvents, event)
+		}
+	}
+
+	fmt.Println("\nRecent flagged events:")
+	for i, event := range shownEvents {
+		fmt.Printf("%s %s %-30s %-15s %s -> %s (%s, %s)\n",
+			common.BoxPrefix(i == len(shownEvents)-1),
+			event.CreatedAt.Format("2006-01-02 15:04:05"),
+			emails[event.UserId],
+			event.Asset,
+			event.BalanceBefore.String(),
+			event.BalanceAfter.String(),
+			event.TransactionType,
+			formatTransactionId(event.TransactionId))
+	}
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d accounts below zero", len(shown)), common.DefaultWidth)
+	return nil
+}
+
+// printInactiveReport lists accounts with no transactions in the last `days` days
+func printInactiveReport(ctx context.Context, users []common.UserInfo, dbService *database.Service, days int, includeZero bool) error {
+	emails := make(map[string]string, len(users))
+	for _, user := range users {
+		emails[user.Id] = user.Email
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -days)
+	accounts, err := dbService.ListInactiveAccounts(ctx, cutoff, includeZero)
+	if err != nil {
+		return err
+	}
+
+	var shown []models.InactiveAccount
+	for _, account := range accounts {
+		if _, ok := emails[account.UserId]; ok {
+			shown = append(shown, account)
+		}
+	}
+
+	common.PrintHeader(fmt.Sprintf("INACTIVE ACCOUNTS (no transactions in %d days)", days), common.DefaultWidth)
+
+	dormantUsers := make(map[string]bool)
+	for i, account := range shown {
+		dormantUsers[account.UserId] = true
+		lastActivity := "never"
+		if !account.LastActivityAt.IsZero() {
+			lastActivity = account.LastActivityAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s %-30s %-15s %20s (last activity: %s)\n",
+			common.BoxPrefix(i == len(shown)-1),
+			emails[account.UserId],
+			account.Asset,
+			account.Balance.String(),
+			lastActivity)
+	}
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d inactive accounts across %d users (cutoff %s)",
+		len(shown), len(dormantUsers), cutoff.Format("2006-01-02")), common.DefaultWidth)
+	return nil
+}
+
+// printHistory lists each user's recent transactions in an asset, with on-chain receipts where known
+func printHistory(ctx context.Context, users []common.UserInfo, ledger *api.LedgerService, asset string, limit int, logger *zap.Logger) {
+	common.PrintHeader(fmt.Sprintf("%s TRANSACTION HISTORY", asset), common.WideWidth)
+
+	shown := 0
+	for _, user := range users {
+		records, err := ledger.GetTransactionHistory(ctx, user.Id, asset, limit, "")
+		if err != nil {
+			logger.Error("Failed to get transaction history", zap.String("user_id", user.Id), zap.Error(err))
+			continue
+		}
+		if len(records) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s User: %s (%s)\n", common.BoxTopPrefix(), user.Name, user.Email)
+		common.PrintBoxSeparator(98)
+		for i, record := range records {
+			isLast := i == len(records)-1
+			fmt.Printf("%s %s  %-12s %20s  %s\n",
+				common.BoxPrefix(isLast),
+				record.ProcessedAt.Format("2006-01-02 15:04:05"),
+				record.Type,
+				record.Amount.String(),
+				record.Status)
+			switch {
+			case record.ExplorerUrl != "":
+				fmt.Printf("%s   %s\n", common.BoxDetailPrefix(isLast), record.ExplorerUrl)
+			case record.TxHash != "":
+				fmt.Printf("%s   tx: %s\n", common.BoxDetailPrefix(isLast), record.TxHash)
+			}
+			for _, link := range record.Links {
+				fmt.Printf("%s   %s %s %s (%s, %s)\n",
+					common.BoxDetailPrefix(isLast),
+					common.Arrow(),
+					strings.ReplaceAll(link.Relation, "_", " "),
+					link.TransactionId,
+					link.Amount.String(),
+					link.CreatedAt.Format("2006-01-02 15:04:05"))
+			}
+		}
+		shown += len(records)
+	}
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d transactions across %d users queried", shown, len(users)), common.WideWidth)
+}
+
+// exportRecord is one user/asset balance in the bulk export
+type exportRecord struct {
+	UserId            string    `json:"user_id"`
+	Email             string    `json:"email"`
+	Asset             string    `json:"asset"`
+	Balance           string    `json:"balance"`
+	Available         string    `json:"available"`
+	Version           int64     `json:"version"`
+	LastTransactionId string    `json:"last_transaction_id"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	ZeroBalance       bool      `json:"zero_balance"`
+}
+
+var exportCSVHeader = []string{
+	"user_id", "email", "asset", "balance", "available", "version",
+	"last_transaction_id", "updated_at", "zero_balance",
+}
+
+func (r exportRecord) csvRecord() []string {
+	return []string{
+		r.UserId,
+		r.Email,
+		r.Asset,
+		r.Balance,
+		r.Available,
+		fmt.Sprintf("%d", r.Version),
+		r.LastTransactionId,
+		r.UpdatedAt.UTC().Format(time.RFC3339),
+		fmt.Sprintf("%t", r.ZeroBalance),
+	}
+}
+
+// writeExport writes the records as CSV with a header row, or as one JSON object per line
+func writeExport(w io.Writer, format string, records []exportRecord) error {
+	if format == "csv" {
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(exportCSVHeader); err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := csvWriter.Write(record.csvRecord()); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportBalances dumps every account balance row, including zero balances, for downstream reporting.
+// With scrubbed set, emails are replaced with pseudonyms so the export can be shared.
+func exportBalances(ctx context.Context, dbService *database.Service, users []common.UserInfo, format, output string, scrubbed bool) (int, error) {
+	emails := make(map[string]string, len(users))
+	for _, user := range users {
+		emails[user.Id] = user.Email
+		if scrubbed {
+			emails[user.Id] = scrub.Email(user.Id)
+		}
+	}
+
+	balances, err := dbService.ListAllAccountBalances(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	records := make([]exportRecord, 0, len(balances))
+	for _, balance := range balances {
+		email, ok := emails[balance.UserId]
+		if !ok {
+			continue
+		}
+		records = append(records, exportRecord{
+			UserId:            balance.UserId,
+			Email:             email,
+			Asset:             balance.Asset,
+			Balance:           balance.Balance.String(),
+			Available:         balance.Available.String(),
+			Version:           balance.Version,
+			LastTransactionId: balance.LastTransactionId,
+			UpdatedAt:         balance.UpdatedAt,
+			ZeroBalance:       balance.Balance.IsZero(),
+		})
+	}
+
+	out := os.Stdout
+	if output != "" {
+		out, err = os.Create(output)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer out.Close()
+	}
+
+	buffered := bufio.NewWriter(out)
+	if err := writeExport(buffered, format, records); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+	return len(records), nil
+}
+
+func printDigest(ctx context.Context, dbService *database.Service, includeTransactions bool) error {
+	digest, err := dbService.ComputeDigest(ctx, includeTransactions)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("LEDGER DIGEST", common.DefaultWidth)
+	fmt.Printf("Balances:      %d rows  %s\n", digest.Balances, digest.BalanceDigest)
+	if includeTransactions {
+		fmt.Printf("Transactions:  %d rows  %s\n", digest.Transactions, digest.TransactionDigest)
+	}
+	common.PrintFooter("Digest: "+digest.Digest, common.DefaultWidth)
+	return nil
+}
+
 func main() {
 	ctx := context.Background()
 
-	logger, loggerCleanup := common.InitializeLogger()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
 	defer loggerCleanup()
 
+	// "balances export" dumps every balance row instead of printing the report
+	fs := flag.CommandLine
+	args := os.Args[1:]
+	exporting := len(args) > 0 && args[0] == "export"
+	if exporting {
+		fs = flag.NewFlagSet("export", flag.ExitOnError)
+		args = args[1:]
+	}
+	common.RegisterOutputFlags(fs)
+
 	// Parse command line flags
-	emailFlag := flag.String("email", "", "Filter by specific user email (optional)")
-	flag.Parse()
+	emailFlag := fs.String("email", "", "Filter by specific user email (optional)")
+	tenantFlag := fs.String("tenant", "", "Only show users of this tenant (default from TENANT_ID)")
+	var usdFlag, negativeFlag, includeZeroFlag, digestFlag, digestTransactionsFlag, scrubFlag *bool
+	var priceSourceFlag, formatFlag, outFlag *string
+	var inactiveDaysFlag *int
+	var historyFlag *string
+	if exporting {
+		formatFlag = fs.String("format", "csv", "Export format: csv or jsonl")
+		outFlag = fs.String("out", "", "Output file (default stdout)")
+		scrubFlag = fs.Bool("scrub", false, "Replace emails with pseudonyms so the export can be shared")
+	} else {
+		usdFlag = fs.Bool("usd", false, "Show USD value per balance, dust flags and total AUM")
+		priceSourceFlag = fs.String("price-source", "", "Price source for --usd: auto, exchange, advanced-trade (default from PRICE_SOURCE)")
+		negativeFlag = fs.Bool("negative", false, "Show accounts below zero and recent negative balance events")
+		includeZeroFlag = fs.Bool("include-zero", false, "Include accounts whose balance is zero")
+		inactiveDaysFlag = fs.Int("inactive-days", 0, "Show accounts with no transactions in this many days (dormancy report)")
+		historyFlag = fs.String("history", "", "Show recent transactions in this asset with on-chain explorer links")
+		digestFlag = fs.Bool("digest", false, "Print a deterministic digest of every balance, e.g. to compare before and after a restore")
+		digestTransactionsFlag = fs.Bool("digest-transactions", false, "Include every transaction in --digest")
+	}
+	if err := fs.Parse(args); err != nil {
+		logger.Fatal("Failed to parse flags", zap.Error(err))
+	}
+	if exporting && *formatFlag != "csv" && *formatFlag != "jsonl" {
+		logger.Fatal("--format must be csv or jsonl", zap.String("format", *formatFlag))
+	}
 
 	logger.Info("Starting balance query")
 
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		logger.Fatal("Failed to load config", zap.Error(err))
+	if *tenantFlag != "" {
+		cfg.Tenant.Id = *tenantFlag
 	}
 
 	// Initialize database service (no need for Prime API for read-only operations)
 	logger.Info("Connecting to database", zap.String("path", cfg.Database.Path))
-	dbService, err := common.InitializeDatabaseOnly(ctx, cfg)
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize database", zap.Error(err))
 	}
 	defer dbService.Close()
 
+	if !exporting && *digestFlag {
+		if err := printDigest(ctx, dbService, *digestTransactionsFlag); err != nil {
+			logger.Fatal("Failed to compute ledger digest", zap.Error(err))
+		}
+		return
+	}
+
 	// Initialize users based on filter
 	users, err := common.InitializeUsers(ctx, dbService, *emailFlag, logger)
 	if err != nil {
 		logger.Fatal("Failed to initialize users", zap.Error(err))
 	}
 
+	if exporting {
+		count, err := exportBalances(ctx, dbService, users, *formatFlag, *outFlag, *scrubFlag)
+		if err != nil {
+			logger.Fatal("Failed to export balances", zap.Error(err))
+		}
+		logger.Info("Balance export completed",
+			zap.Int("balances", count),
+			zap.String("format", *formatFlag),
+			zap.String("output", *outFlag),
+			zap.Bool("scrubbed", *scrubFlag))
+		return
+	}
+
+	if *historyFlag != "" {
+		ledger := api.NewLedgerService(dbService, logger.Named("ledger"))
+		ledger.SetExplorer(cfg.Explorer)
+		printHistory(ctx, users, ledger, strings.ToUpper(*historyFlag), 20, logger)
+		return
+	}
+
+	if *inactiveDaysFlag < 0 {
+		logger.Fatal("--inactive-days must not be negative", zap.Int("inactive_days", *inactiveDaysFlag))
+	}
+	if *inactiveDaysFlag > 0 {
+		if err := printInactiveReport(ctx, users, dbService, *inactiveDaysFlag, *includeZeroFlag); err != nil {
+			logger.Fatal("Failed to generate inactive account report", zap.Error(err))
+		}
+		return
+	}
+
+	if *negativeFlag {
+		if err := printNegativeBalanceReport(ctx, users, dbService); err != nil {
+			logger.Fatal("Failed to generate negative balance report", zap.Error(err))
+		}
+		return
+	}
+
+	var v *valuation
+	if *usdFlag {
+		source := cfg.Pricing.Source
+		if *priceSourceFlag != "" {
+			source = *priceSourceFlag
+		}
+		pricingService, err := pricing.NewService(source, cfg.Pricing.CacheTTL, logger.Named("pricing"))
+		if err != nil {
+			logger.Fatal("Failed to initialize pricing", zap.Error(err))
+		}
+		v = &valuation{
+			pricing:       pricingService,
+			dustThreshold: cfg.Pricing.DustThresholdUsd,
+			unpriced:      make(map[string]bool),
+		}
+	}
+
 	// Print header
 	common.PrintHeader("USER BALANCE REPORT", common.DefaultWidth)
 
 	// Process users and generate report
-	stats := processUsersAndGenerateReport(ctx, users, dbService, logger)
+	stats := processUsersAndGenerateReport(ctx, users, dbService, v, *includeZeroFlag, logger)
 
 	// Print footer summary
 	summary := fmt.Sprintf("SUMMARY: %d users with balances (%d total balances across %d users queried)",
 		stats.usersWithBalances, stats.totalBalances, stats.totalUsers)
+	if v != nil {
+		summary += fmt.Sprintf("\nAUM: $%s (%d dust balances below $%s)",
+			v.aumUsd.StringFixed(2), v.dustCount, v.dustThreshold.String())
+		if len(v.unpriced) > 0 {
+			summary += fmt.Sprintf("\nUnpriced assets excluded from AUM: %d", len(v.unpriced))
+		}
+	}
 	common.PrintFooter(summary, common.DefaultWidth)
 
 	logger.Info("Balance query completed",
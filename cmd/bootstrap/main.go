@@ -0,0 +1,319 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"prime-send-receive-go/internal/app"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// readinessReport collects the outcome of each bootstrap step
+type readinessReport struct {
+	steps []readinessStep
+}
+
+type readinessStep struct {
+	name   string
+	ok     bool
+	detail string
+}
+
+func (r *readinessReport) add(name string, ok bool, detail string) bool {
+	r.steps = append(r.steps, readinessStep{name: name, ok: ok, detail: detail})
+	return ok
+}
+
+func (r *readinessReport) ready() bool {
+	for _, step := range r.steps {
+		if !step.ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *readinessReport) print() {
+	common.PrintHeader("BOOTSTRAP READINESS REPORT", common.WideWidth)
+	for i, step := range r.steps {
+		fmt.Printf("%s %s %-12s %s\n", common.BoxPrefix(i == len(r.steps)-1), common.StatusMark(step.ok), step.name, step.detail)
+	}
+	if r.ready() {
+		common.PrintFooter("READY - start the listener with: go run cmd/listener/main.go", common.WideWidth)
+		return
+	}
+	common.PrintFooter("NOT READY - fix the failed steps and run bootstrap again; completed steps are skipped", common.WideWidth)
+}
+
+// validateConfig checks the settings the first run depends on and returns the enabled assets
+func validateConfig(cfg *models.Config) ([]models.AssetConfig, error) {
+	if !models.IsCustodyProvider(cfg.Custody.Provider) {
+		return nil, fmt.Errorf("unsupported custody provider %q", cfg.Custody.Provider)
+	}
+
+	assetConfigs, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset config: %w", err)
+	}
+	assetConfigs = common.EnabledAssets(assetConfigs)
+	if len(assetConfigs) == 0 {
+		return nil, fmt.Errorf("no enabled assets in %s", cfg.Listener.AssetsFile)
+	}
+
+	return assetConfigs, nil
+}
+
+// getOrCreateWallet adopts the asset's existing trading wallet or creates one
+func getOrCreateWallet(ctx context.Context, services *common.Services, assetSymbol string) (*models.Wallet, bool, error) {
+	wallets, err := services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{assetSymbol})
+	if err != nil {
+		return nil, false, fmt.Errorf("error listing wallets: %w", err)
+	}
+
+	if wallet := common.SelectTradingWallet(services.Wallets, wallets, assetSymbol); wallet != nil {
+		services.Logger.Info("Using existing wallet",
+			zap.String("asset", assetSymbol),
+			zap.String("wallet_name", wallet.Name),
+			zap.String("wallet_id", wallet.Id))
+		return wallet, false, nil
+	}
+
+	walletName := common.WalletName(services.Wallets, assetSymbol)
+	services.Logger.Info("Creating new wallet",
+		zap.String("asset", assetSymbol),
+		zap.String("wallet_name", walletName))
+
+	wallet, err := services.PrimeService.CreateWallet(ctx, services.DefaultPortfolio.Id, walletName, assetSymbol, "TRADING")
+	if err != nil {
+		return nil, false, fmt.Errorf("error creating wallet: %w", err)
+	}
+	return wallet, true, nil
+}
+
+// provisionWallets finds or creates one trading wallet per enabled asset symbol
+func provisionWallets(ctx context.Context, services *common.Services, assetConfigs []models.AssetConfig) (map[string]*models.Wallet, string, bool) {
+	wallets := make(map[string]*models.Wallet)
+	var created, existing int
+	var failed []string
+
+	for _, assetConfig := range assetConfigs {
+		if _, ok := wallets[assetConfig.Symbol]; ok {
+			continue
+		}
+		wallet, isNew, err := getOrCreateWallet(ctx, services, assetConfig.Symbol)
+		if err != nil {
+			services.Logger.Error("Failed to get or create wallet", zap.String("asset", assetConfig.Symbol), zap.Error(err))
+			failed = append(failed, assetConfig.Symbol)
+			continue
+		}
+		wallets[assetConfig.Symbol] = wallet
+		if isNew {
+			created++
+		} else {
+			existing++
+		}
+	}
+
+	detail := fmt.Sprintf("%d existing, %d created", existing, created)
+	if len(failed) > 0 {
+		detail += fmt.Sprintf(", failed: %v", failed)
+	}
+	return wallets, detail, len(failed) == 0
+}
+
+// provisionAddresses creates the missing deposit address of every user for every enabled asset
+func provisionAddresses(ctx context.Context, services *common.Services, shutdown *common.Shutdown, assetConfigs []models.AssetConfig, wallets map[string]*models.Wallet) (string, bool) {
+	users, err := services.DbService.GetUsers(ctx)
+	if err != nil {
+		return fmt.Sprintf("failed to read users: %v", err), false
+	}
+	if len(users) == 0 {
+		return "no users - add them with cmd/adduser or set CREATE_DUMMY_USERS=true", false
+	}
+
+	var created, existing int
+	var failed []string
+	for _, user := range users {
+		for _, assetConfig := range assetConfigs {
+			// Stop between addresses so that no address is created in Prime without being stored
+			if shutdown.Requested() {
+				return fmt.Sprintf("interrupted after creating %d addresses", created), false
+			}
+
+			stored, err := services.DbService.GetAddresses(ctx, user.Id, assetConfig.Symbol, assetConfig.Network)
+			if err == nil && len(stored) > 0 {
+				existing++
+				continue
+			}
+
+			wallet, ok := wallets[assetConfig.Symbol]
+			if err == nil && !ok {
+				err = fmt.Errorf("no wallet for %s", assetConfig.Symbol)
+			}
+			if err == nil {
+				err = createAndStoreAddress(ctx, services, user, assetConfig, wallet)
+			}
+			if err != nil {
+				services.Logger.Error("Failed to provision address",
+					zap.String("user_id", user.Id),
+					zap.String("asset", assetConfig.AssetNetwork()),
+					zap.Error(err))
+				failed = append(failed, fmt.Sprintf("%s/%s", user.Email, assetConfig.AssetNetwork()))
+				continue
+			}
+			created++
+		}
+	}
+
+	detail := fmt.Sprintf("%d users: %d existing, %d created", len(users), existing, created)
+	if len(failed) > 0 {
+		detail += fmt.Sprintf(", failed: %v", failed)
+	}
+	return detail, len(failed) == 0
+}
+
+func createAndStoreAddress(ctx context.Context, services *common.Services, user models.User, assetConfig models.AssetConfig, wallet *models.Wallet) error {
+	depositAddress, err := services.PrimeService.CreateDepositAddress(ctx, services.DefaultPortfolio.Id, wallet.Id, assetConfig.Symbol, assetConfig.Network)
+	if err != nil {
+		return fmt.Errorf("error creating deposit address: %w", err)
+	}
+
+	_, err = services.DbService.StoreAddress(ctx, database.StoreAddressParams{
+		UserId:            user.Id,
+		Asset:             assetConfig.Symbol,
+		Network:           assetConfig.Network,
+		Address:           depositAddress.Address,
+		WalletId:          wallet.Id,
+		AccountIdentifier: depositAddress.Id,
+	})
+	if err != nil {
+		return fmt.Errorf("error storing address to database: %w", err)
+	}
+	return nil
+}
+
+// backfill applies the monitored wallets' transactions over window, so the ledger starts from Prime's history
+func backfill(ctx context.Context, cfg *models.Config, services *common.Services, window time.Duration) (string, bool) {
+	coordinator, err := coordination.NewStore(ctx, cfg.Coordination, services.Logger.Named("coordination"))
+	if err != nil {
+		return fmt.Sprintf("failed to initialize coordination store: %v", err), false
+	}
+	defer coordinator.Close()
+
+	sendReceiveListener, _ := app.NewListener(app.Dependencies{Config: cfg, Services: services, Coordinator: coordinator})
+	if err := sendReceiveListener.LoadMonitoredWallets(ctx, cfg.Listener.AssetsFile); err != nil {
+		return fmt.Sprintf("failed to load monitored wallets: %v", err), false
+	}
+
+	applied, err := sendReceiveListener.Backfill(ctx, window)
+	if err != nil {
+		return fmt.Sprintf("%d transactions applied, then: %v", applied, err), false
+	}
+	return fmt.Sprintf("%d transactions applied from the last %s", applied, window), true
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	backfillWindowFlag := flag.Duration("backfill-window", 24*time.Hour, "How far back the initial backfill scans each wallet")
+	skipBackfillFlag := flag.Bool("skip-backfill", false, "Skip the initial backfill")
+	common.RegisterOutputFlags(flag.CommandLine)
+	common.RegisterLockFlags(flag.CommandLine)
+	flag.Parse()
+
+	report := &readinessReport{}
+	defer func() {
+		report.print()
+		if !report.ready() {
+			loggerCleanup()
+			os.Exit(1)
+		}
+	}()
+
+	assetConfigs, err := validateConfig(cfg)
+	if !report.add("config", err == nil, describe(err, fmt.Sprintf("%d enabled assets in %s", len(assetConfigs), cfg.Listener.AssetsFile))) {
+		return
+	}
+
+	// Opening the database creates and migrates its schema
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if !report.add("migrations", err == nil, describe(err, fmt.Sprintf("schema up to date in %s", cfg.Database.Path))) {
+		return
+	}
+	dbService.Close()
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		report.add("prime", false, err.Error())
+		return
+	}
+	defer services.Close()
+	report.add("prime", true, fmt.Sprintf("portfolio %s (%s)", services.DefaultPortfolio.Name, services.DefaultPortfolio.Id))
+
+	lock, err := common.AcquireCommandLock(ctx, services.DbService, "bootstrap", logger)
+	if err != nil {
+		report.add("lock", false, err.Error())
+		return
+	}
+	defer lock.Release()
+
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
+
+	wallets, detail, ok := provisionWallets(ctx, services, assetConfigs)
+	report.add("wallets", ok, detail)
+
+	detail, ok = provisionAddresses(ctx, services, shutdown, assetConfigs, wallets)
+	if !report.add("addresses", ok, detail) && shutdown.Requested() {
+		return
+	}
+
+	if *skipBackfillFlag {
+		report.add("backfill", true, "skipped (--skip-backfill)")
+		return
+	}
+	detail, ok = backfill(ctx, cfg, services, *backfillWindowFlag)
+	report.add("backfill", ok, detail)
+}
+
+// describe returns the step detail, or the error when the step failed
+func describe(err error, detail string) string {
+	if err != nil {
+		return err.Error()
+	}
+	return detail
+}
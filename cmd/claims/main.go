@@ -0,0 +1,217 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  claims list [--status open|claimed|dismissed|all]")
+	fmt.Println("  claims show --id ID")
+	fmt.Println("  claims claim --id ID --email EMAIL [--asset SYMBOL] [--note NOTE]")
+	fmt.Println("  claims dismiss --id ID --note NOTE")
+}
+
+func listDeposits(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	statusFlag := fs.String("status", database.UnattributedStatusOpen, "Filter by status (open, claimed, dismissed, all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	status := *statusFlag
+	if status == "all" {
+		status = ""
+	}
+
+	deposits, err := dbService.ListUnattributedDeposits(ctx, status)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("UNATTRIBUTED DEPOSITS", common.WideWidth)
+	for i, deposit := range deposits {
+		isLast := i == len(deposits)-1
+		fmt.Printf("%s %s  %s %s to %s (status: %s)\n",
+			common.BoxPrefix(isLast),
+			deposit.Id,
+			deposit.Amount.String(),
+			deposit.Asset,
+			deposit.Address,
+			deposit.Status)
+		fmt.Printf("%s network: %s, from: %s, prime tx: %s, received: %s\n",
+			common.BoxDetailPrefix(isLast),
+			deposit.Network,
+			deposit.SourceAddress,
+			deposit.ExternalTransactionId,
+			deposit.CreatedAt.Format("2006-01-02 15:04:05"))
+		if deposit.Status != database.UnattributedStatusOpen {
+			fmt.Printf("%s resolved: user=%s note=%s\n",
+				common.BoxDetailPrefix(isLast),
+				deposit.ClaimedUserId,
+				deposit.ResolutionNote)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d unattributed deposits", len(deposits)), common.WideWidth)
+	return nil
+}
+
+func showDeposit(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Unattributed deposit id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	deposit, err := dbService.GetUnattributedDeposit(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Id:            %s\n", deposit.Id)
+	fmt.Printf("Status:        %s\n", deposit.Status)
+	fmt.Printf("Amount:        %s %s\n", deposit.Amount.String(), deposit.Asset)
+	fmt.Printf("Network:       %s\n", deposit.Network)
+	fmt.Printf("Address:       %s\n", deposit.Address)
+	fmt.Printf("Source:        %s %s\n", deposit.SourceType, deposit.SourceAddress)
+	fmt.Printf("Prime tx:      %s\n", deposit.ExternalTransactionId)
+	fmt.Printf("Received:      %s\n", deposit.CreatedAt.Format("2006-01-02 15:04:05"))
+	if deposit.ResolvedAt != nil {
+		fmt.Printf("Resolved:      %s (user=%s note=%s)\n",
+			deposit.ResolvedAt.Format("2006-01-02 15:04:05"), deposit.ClaimedUserId, deposit.ResolutionNote)
+	}
+	fmt.Printf("Details:       %s\n", deposit.Details)
+	return nil
+}
+
+func claimDeposit(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("claim", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Unattributed deposit id (required)")
+	emailFlag := fs.String("email", "", "User to credit (required)")
+	assetFlag := fs.String("asset", "", "Ledger symbol to credit, when it differs from the Prime symbol (optional)")
+	noteFlag := fs.String("note", "", "Resolution note (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *emailFlag == "" {
+		return fmt.Errorf("both flags are required: --id, --email")
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := dbService.ClaimUnattributedDeposit(ctx, *idFlag, user.Id, *assetFlag, *noteFlag); err != nil {
+		return err
+	}
+
+	deposit, err := dbService.GetUnattributedDeposit(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	asset := *assetFlag
+	if asset == "" {
+		asset = deposit.Asset
+	}
+	fmt.Printf("Credited %s %s to %s\n", deposit.Amount.String(), asset, user.Email)
+	return nil
+}
+
+func dismissDeposit(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("dismiss", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Unattributed deposit id (required)")
+	noteFlag := fs.String("note", "", "Why the deposit is dismissed, e.g. the Prime id of the return transfer (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *noteFlag == "" {
+		return fmt.Errorf("both flags are required: --id, --note")
+	}
+
+	if err := dbService.DismissUnattributedDeposit(ctx, *idFlag, *noteFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Dismissed unattributed deposit %s\n", *idFlag)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		err = listDeposits(ctx, dbService, args)
+	case "show":
+		err = showDeposit(ctx, dbService, args)
+	case "claim":
+		err = claimDeposit(ctx, dbService, args)
+	case "dismiss":
+		err = dismissDeposit(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Claims command failed", zap.String("command", command), zap.Error(err))
+	}
+}
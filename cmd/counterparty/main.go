@@ -0,0 +1,175 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  counterparty map --counterparty-id ID --email EMAIL [--note NOTE]")
+	fmt.Println("  counterparty expect --reference REF --email EMAIL [--asset SYMBOL] [--amount AMOUNT]")
+	fmt.Println("  counterparty list")
+}
+
+func mapCounterparty(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("map", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	counterpartyFlag := fs.String("counterparty-id", "", "Sender counterparty id from transfer_from (required)")
+	emailFlag := fs.String("email", "", "User to attribute deposits to (required)")
+	noteFlag := fs.String("note", "", "Free-form note (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *counterpartyFlag == "" || *emailFlag == "" {
+		return fmt.Errorf("both flags are required: --counterparty-id, --email")
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := dbService.SetCounterpartyMapping(ctx, *counterpartyFlag, user.Id, *noteFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Transfers from counterparty %s will be credited to %s\n", *counterpartyFlag, user.Email)
+	return nil
+}
+
+func expectDeposit(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("expect", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	referenceFlag := fs.String("reference", "", "Match reference the sender will attach (required)")
+	emailFlag := fs.String("email", "", "User to credit (required)")
+	assetFlag := fs.String("asset", "", "Expected asset symbol (optional)")
+	amountFlag := fs.String("amount", "0", "Expected amount (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *referenceFlag == "" || *emailFlag == "" {
+		return fmt.Errorf("both flags are required: --reference, --email")
+	}
+
+	amount, err := decimal.NewFromString(*amountFlag)
+	if err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := dbService.CreateDepositReference(ctx, *referenceFlag, user.Id, *assetFlag, amount); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deposits with reference %s will be credited to %s\n", *referenceFlag, user.Email)
+	return nil
+}
+
+func list(ctx context.Context, dbService *database.Service) error {
+	mappings, err := dbService.ListCounterpartyMappings(ctx)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("COUNTERPARTY MAPPINGS", common.WideWidth)
+	for i, mapping := range mappings {
+		isLast := i == len(mappings)-1
+		fmt.Printf("%s %s -> %s %s\n", common.BoxPrefix(isLast), mapping.CounterpartyId, mapping.UserId, mapping.Note)
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d counterparty mappings", len(mappings)), common.WideWidth)
+
+	references, err := dbService.ListDepositReferences(ctx)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("DEPOSIT REFERENCES", common.WideWidth)
+	for i, reference := range references {
+		isLast := i == len(references)-1
+		fmt.Printf("%s %s -> %s  %s %s (status: %s)\n",
+			common.BoxPrefix(isLast),
+			reference.Reference,
+			reference.UserId,
+			reference.Amount.String(),
+			reference.Asset,
+			reference.Status)
+		if reference.MatchedTransactionId != "" {
+			fmt.Printf("%s matched transaction: %s\n", common.BoxDetailPrefix(isLast), reference.MatchedTransactionId)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d deposit references", len(references)), common.WideWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "map":
+		err = mapCounterparty(ctx, dbService, args)
+	case "expect":
+		err = expectDeposit(ctx, dbService, args)
+	case "list":
+		err = list(ctx, dbService)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Counterparty command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  deposit-holds list [--status held|released|all]")
+	fmt.Println("  deposit-holds release --id ID [--note NOTE]")
+}
+
+func listHolds(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	statusFlag := fs.String("status", database.DepositHoldStatusHeld, "Filter by status (held, released, all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	status := *statusFlag
+	if status == "all" {
+		status = ""
+	}
+
+	holds, err := dbService.ListDepositHolds(ctx, status)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("DEPOSIT HOLDS", common.WideWidth)
+	for i, hold := range holds {
+		isLast := i == len(holds)-1
+		fmt.Printf("%s %s  %s %s for %s (%s, status: %s)\n",
+			common.BoxPrefix(isLast),
+			hold.Id,
+			hold.Amount.String(),
+			hold.Asset,
+			hold.UserId,
+			hold.Kind,
+			hold.Status)
+		fmt.Printf("%s reason: %s, prime tx: %s, held: %s\n",
+			common.BoxDetailPrefix(isLast),
+			hold.Reason,
+			hold.ExternalTransactionId,
+			hold.CreatedAt.Format("2006-01-02 15:04:05"))
+		if hold.ReleasedAt != nil {
+			fmt.Printf("%s released: %s note=%s\n",
+				common.BoxDetailPrefix(isLast),
+				hold.ReleasedAt.Format("2006-01-02 15:04:05"),
+				hold.ReleaseNote)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d deposit holds", len(holds)), common.WideWidth)
+	return nil
+}
+
+func releaseHold(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("release", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Deposit hold id (required)")
+	noteFlag := fs.String("note", "", "Review note (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	if err := dbService.ReleaseDepositHold(ctx, *idFlag, *noteFlag); err != nil {
+		return err
+	}
+
+	hold, err := dbService.GetDepositHold(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Released %s %s for %s - funds are now spendable\n", hold.Amount.String(), hold.Asset, hold.UserId)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		err = listHolds(ctx, dbService, args)
+	case "release":
+		err = releaseHold(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Deposit hold command failed", zap.String("command", command), zap.Error(err))
+	}
+}
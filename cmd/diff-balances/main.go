@@ -0,0 +1,170 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type diffRequest struct {
+	params database.BalanceDiffParams
+	user   string
+	maxTxs int
+}
+
+func parseFlags() (*diffRequest, error) {
+	fromFlag := flag.String("from", "", "Start of the range, YYYY-MM-DD or RFC3339 (required)")
+	toFlag := flag.String("to", "", "End of the range, YYYY-MM-DD or RFC3339 (default: now)")
+	userFlag := flag.String("user", "", "Only this user, by email or user id")
+	assetFlag := flag.String("asset", "", "Only this asset symbol")
+	allFlag := flag.Bool("all", false, "Also show accounts whose transactions net to zero")
+	maxTxsFlag := flag.Int("max-txs", 20, "Transactions shown per account, most recent last (0 for all)")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *fromFlag == "" {
+		return nil, fmt.Errorf("--from is required")
+	}
+	from, err := common.ParseTimeFlag(*fromFlag, "from")
+	if err != nil {
+		return nil, err
+	}
+	to := time.Now().UTC()
+	if *toFlag != "" {
+		if to, err = common.ParseTimeFlag(*toFlag, "to"); err != nil {
+			return nil, err
+		}
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("--to must be after --from")
+	}
+
+	return &diffRequest{
+		params: database.BalanceDiffParams{
+			From:             from,
+			To:               to,
+			Asset:            strings.ToUpper(*assetFlag),
+			IncludeUnchanged: *allFlag,
+		},
+		user:   *userFlag,
+		maxTxs: *maxTxsFlag,
+	}, nil
+}
+
+func printChange(change models.BalanceChange, email string, maxTxs int, isLast bool) {
+	fmt.Printf("%s %s %s  %s %s %s (net %s, %d transactions)\n",
+		common.BoxPrefix(isLast),
+		email,
+		change.Asset,
+		change.Before.String(),
+		common.Arrow(),
+		change.After.String(),
+		signed(change.Net),
+		len(change.Transactions))
+
+	txs := change.Transactions
+	if maxTxs > 0 && len(txs) > maxTxs {
+		fmt.Printf("%s ... %d earlier transactions not shown\n", common.BoxDetailPrefix(isLast), len(txs)-maxTxs)
+		txs = txs[len(txs)-maxTxs:]
+	}
+	for _, tx := range txs {
+		txType := tx.TransactionType
+		if tx.ReversalOf != "" {
+			txType += " (reversal of " + tx.ReversalOf + ")"
+		}
+		fmt.Printf("%s %s  %-12s %14s  balance %s  %s\n",
+			common.BoxDetailPrefix(isLast),
+			tx.CreatedAt.UTC().Format(time.RFC3339),
+			txType,
+			signed(tx.Amount),
+			tx.BalanceAfter.String(),
+			tx.ExternalTransactionId)
+	}
+}
+
+// signed formats an amount with an explicit + for credits
+func signed(amount decimal.Decimal) string {
+	if amount.IsPositive() {
+		return "+" + amount.String()
+	}
+	return amount.String()
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	req, err := parseFlags()
+	if err != nil {
+		logger.Fatal("Invalid flags", zap.Error(err))
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	if req.params.UserId, err = common.ResolveUserId(ctx, dbService, req.user); err != nil {
+		logger.Fatal("Invalid --user", zap.Error(err))
+	}
+
+	changes, err := dbService.DiffBalances(ctx, req.params)
+	if err != nil {
+		logger.Fatal("Failed to diff balances", zap.Error(err))
+	}
+
+	users, err := dbService.GetUsers(ctx)
+	if err != nil {
+		logger.Fatal("Failed to load users", zap.Error(err))
+	}
+	emails := make(map[string]string, len(users))
+	for _, user := range users {
+		emails[user.Id] = user.Email
+	}
+
+	common.PrintHeader(fmt.Sprintf("BALANCE CHANGES: %s to %s",
+		req.params.From.UTC().Format(time.RFC3339), req.params.To.UTC().Format(time.RFC3339)), common.WideWidth)
+	for i, change := range changes {
+		email := emails[change.UserId]
+		if email == "" {
+			email = change.UserId
+		}
+		printChange(change, email, req.maxTxs, i == len(changes)-1)
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d accounts changed", len(changes)), common.WideWidth)
+}
@@ -0,0 +1,200 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+	"prime-send-receive-go/internal/txdiff"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"go.uber.org/zap"
+)
+
+type diffRequest struct {
+	asset string
+	start time.Time
+	end   time.Time
+	slack time.Duration
+}
+
+func parseFlags() (*diffRequest, error) {
+	assetFlag := flag.String("asset", "", "Asset symbol to compare (required)")
+	startFlag := flag.String("start", "", "Start of the range, YYYY-MM-DD or RFC3339 (required)")
+	endFlag := flag.String("end", "", "End of the range, YYYY-MM-DD or RFC3339 (default: now)")
+	slackFlag := flag.Duration("slack", 24*time.Hour, "How far outside the range to look for the other side of a match")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *assetFlag == "" || *startFlag == "" {
+		return nil, fmt.Errorf("both flags are required: --asset, --start")
+	}
+
+	start, err := common.ParseTimeFlag(*startFlag, "start")
+	if err != nil {
+		return nil, err
+	}
+	end := time.Now().UTC()
+	if *endFlag != "" {
+		if end, err = common.ParseTimeFlag(*endFlag, "end"); err != nil {
+			return nil, err
+		}
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("--end must be after --start")
+	}
+
+	return &diffRequest{
+		asset: strings.ToUpper(*assetFlag),
+		start: start,
+		end:   end,
+		slack: *slackFlag,
+	}, nil
+}
+
+// fetchPrimeTransactions collects the asset's deposits and withdrawals from all of its TRADING wallets
+func fetchPrimeTransactions(ctx context.Context, services *common.Services, req *diffRequest) ([]*model.Transaction, error) {
+	wallets, err := services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{req.asset})
+	if err != nil {
+		return nil, err
+	}
+	wallets = common.TradingWallets(services.Wallets, wallets, req.asset)
+	if len(wallets) == 0 {
+		return nil, fmt.Errorf("no TRADING wallet found for %s", req.asset)
+	}
+
+	var primeTxs []*model.Transaction
+	for _, wallet := range wallets {
+		count, err := services.PrimeService.EachWalletTransaction(ctx, prime.WalletTransactionsQuery{
+			PortfolioId: services.DefaultPortfolio.Id,
+			WalletId:    wallet.Id,
+			Start:       req.start.Add(-req.slack),
+			End:         req.end.Add(req.slack),
+			Types:       []string{"DEPOSIT", "WITHDRAWAL"},
+		}, func(tx *model.Transaction) error {
+			primeTxs = append(primeTxs, tx)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		services.Logger.Info("Fetched Prime wallet transactions", zap.String("wallet_id", wallet.Id), zap.Int("count", count))
+	}
+	return primeTxs, nil
+}
+
+// creditStatusLookup returns the credit status configured for the asset on each network
+func creditStatusLookup(asset string, logger *zap.Logger) func(network string) string {
+	assetConfigs, err := common.LoadAssetConfig("assets.yaml")
+	if err != nil {
+		logger.Warn("Failed to load asset config - assuming the default deposit credit status", zap.Error(err))
+		return func(string) string { return models.DefaultDepositCreditStatus }
+	}
+
+	return func(network string) string {
+		if assetConfig, ok := common.FindAssetConfig(assetConfigs, asset, network); ok {
+			return assetConfig.CreditStatus()
+		}
+		return models.DefaultDepositCreditStatus
+	}
+}
+
+func printFinding(f txdiff.Finding) {
+	switch {
+	case f.PrimeTx != nil && f.LedgerEntry != nil:
+		fmt.Printf("%-16s %-40s %s  Prime %s %s, ledger %s (user %s)\n",
+			f.Kind, f.Key, f.PrimeTx.Created.UTC().Format(time.RFC3339), f.PrimeTx.Type, f.PrimeTx.Amount,
+			f.LedgerEntry.Amount.String(), f.LedgerEntry.UserId)
+	case f.PrimeTx != nil:
+		fmt.Printf("%-16s %-40s %s  Prime %s %s (%s)\n",
+			f.Kind, f.Key, f.PrimeTx.Created.UTC().Format(time.RFC3339), f.PrimeTx.Type, f.PrimeTx.Amount, f.PrimeTx.Status)
+	default:
+		fmt.Printf("%-16s %-40s %s  ledger %s %s (user %s)\n",
+			f.Kind, f.Key, f.LedgerEntry.CreatedAt.UTC().Format(time.RFC3339), f.LedgerEntry.TransactionType,
+			f.LedgerEntry.Amount.String(), f.LedgerEntry.UserId)
+	}
+	fmt.Printf("%-16s %s %s\n", "", common.Arrow(), f.Suggestion)
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	req, err := parseFlags()
+	if err != nil {
+		logger.Fatal("Invalid flags", zap.Error(err))
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	primeTxs, err := fetchPrimeTransactions(ctx, services, req)
+	if err != nil {
+		logger.Fatal("Failed to fetch Prime transactions", zap.Error(err))
+	}
+
+	ledger, err := services.DbService.ListLedgerSyncEntries(ctx, req.asset, req.start.Add(-req.slack), req.end.Add(req.slack))
+	if err != nil {
+		logger.Fatal("Failed to read ledger transactions", zap.Error(err))
+	}
+
+	result := txdiff.Diff(primeTxs, ledger, txdiff.Options{
+		Start:        req.start,
+		End:          req.end,
+		CreditStatus: creditStatusLookup(req.asset, logger),
+	})
+
+	common.PrintHeader(fmt.Sprintf("LEDGER VS PRIME: %s %s to %s", req.asset,
+		req.start.UTC().Format(time.RFC3339), req.end.UTC().Format(time.RFC3339)), common.WideWidth)
+	for _, f := range result.Findings {
+		printFinding(f)
+	}
+
+	counts := make(map[string]int)
+	for _, f := range result.Findings {
+		counts[f.Kind]++
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d matched, %d Prime-only, %d ledger-only, %d amount mismatches",
+		result.Matched, counts[txdiff.KindPrimeOnly], counts[txdiff.KindLedgerOnly], counts[txdiff.KindAmountMismatch]), common.WideWidth)
+
+	// A non-zero exit lets scheduled runs alert on differences
+	if len(result.Findings) > 0 {
+		loggerCleanup()
+		services.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,224 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/prime"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"go.uber.org/zap"
+)
+
+type exportRequest struct {
+	walletId string
+	asset    string
+	start    time.Time
+	end      time.Time
+	types    []string
+	format   string
+	output   string
+}
+
+func parseFlags() (*exportRequest, error) {
+	walletFlag := flag.String("wallet-id", "", "Prime wallet id to export")
+	assetFlag := flag.String("asset", "", "Export the TRADING wallet for this asset symbol instead of --wallet-id")
+	startFlag := flag.String("start", "", "Start of the range, YYYY-MM-DD or RFC3339 (required)")
+	endFlag := flag.String("end", "", "End of the range, YYYY-MM-DD or RFC3339 (default: now)")
+	typesFlag := flag.String("types", "", "Comma-separated transaction types, e.g. DEPOSIT,WITHDRAWAL (default: all)")
+	formatFlag := flag.String("format", "jsonl", "Output format: jsonl or csv")
+	outputFlag := flag.String("output", "", "Output file (default: stdout)")
+	flag.Parse()
+
+	if (*walletFlag == "") == (*assetFlag == "") {
+		return nil, fmt.Errorf("exactly one of --wallet-id or --asset is required")
+	}
+	if *startFlag == "" {
+		return nil, fmt.Errorf("--start is required")
+	}
+	if *formatFlag != "jsonl" && *formatFlag != "csv" {
+		return nil, fmt.Errorf("--format must be jsonl or csv, got %q", *formatFlag)
+	}
+
+	start, err := common.ParseTimeFlag(*startFlag, "start")
+	if err != nil {
+		return nil, err
+	}
+	end, err := common.ParseTimeFlag(*endFlag, "end")
+	if err != nil {
+		return nil, err
+	}
+	if !end.IsZero() && !end.After(start) {
+		return nil, fmt.Errorf("--end must be after --start")
+	}
+
+	var types []string
+	for _, t := range strings.Split(*typesFlag, ",") {
+		if t = strings.ToUpper(strings.TrimSpace(t)); t != "" {
+			types = append(types, t)
+		}
+	}
+
+	return &exportRequest{
+		walletId: *walletFlag,
+		asset:    strings.ToUpper(*assetFlag),
+		start:    start,
+		end:      end,
+		types:    types,
+		format:   *formatFlag,
+		output:   *outputFlag,
+	}, nil
+}
+
+func resolveWalletId(ctx context.Context, services *common.Services, req *exportRequest) (string, error) {
+	if req.walletId != "" {
+		return req.walletId, nil
+	}
+
+	wallets, err := services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{req.asset})
+	if err != nil {
+		return "", err
+	}
+	wallets = common.TradingWallets(services.Wallets, wallets, req.asset)
+	if len(wallets) == 0 {
+		return "", fmt.Errorf("no TRADING wallet found for %s", req.asset)
+	}
+	if len(wallets) > 1 {
+		services.Logger.Warn("Multiple TRADING wallets found - exporting the first, use --wallet-id to choose",
+			zap.String("asset", req.asset),
+			zap.String("wallet_id", wallets[0].Id),
+			zap.Int("count", len(wallets)))
+	}
+	return wallets[0].Id, nil
+}
+
+// newWriter returns a per-transaction writer for the format, and a flush function to call at the end
+func newWriter(w io.Writer, format string) (func(*model.Transaction) error, func() error, error) {
+	if format == "csv" {
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write(prime.TransactionCSVHeader); err != nil {
+			return nil, nil, err
+		}
+		write := func(tx *model.Transaction) error {
+			return csvWriter.Write(prime.TransactionCSVRecord(tx))
+		}
+		flush := func() error {
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+		return write, flush, nil
+	}
+
+	// JSONL keeps the raw Prime payload, one transaction per line
+	encoder := json.NewEncoder(w)
+	write := func(tx *model.Transaction) error {
+		return encoder.Encode(tx)
+	}
+	return write, func() error { return nil }, nil
+}
+
+func export(ctx context.Context, services *common.Services, req *exportRequest) (int, error) {
+	walletId, err := resolveWalletId(ctx, services, req)
+	if err != nil {
+		return 0, err
+	}
+
+	out := os.Stdout
+	if req.output != "" {
+		out, err = os.Create(req.output)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer out.Close()
+	}
+
+	buffered := bufio.NewWriter(out)
+	write, flush, err := newWriter(buffered, req.format)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	services.Logger.Info("Exporting wallet transactions",
+		zap.String("wallet_id", walletId),
+		zap.Time("start", req.start),
+		zap.Time("end", req.end),
+		zap.Strings("types", req.types),
+		zap.String("format", req.format))
+
+	count, err := services.PrimeService.EachWalletTransaction(ctx, prime.WalletTransactionsQuery{
+		PortfolioId: services.DefaultPortfolio.Id,
+		WalletId:    walletId,
+		Start:       req.start,
+		End:         req.end,
+		Types:       req.types,
+	}, write)
+	if err != nil {
+		return count, err
+	}
+
+	if err := flush(); err != nil {
+		return count, fmt.Errorf("failed to write output: %w", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return count, fmt.Errorf("failed to write output: %w", err)
+	}
+	return count, nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	// Logs go to stderr, so stdout carries only the export
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	req, err := parseFlags()
+	if err != nil {
+		logger.Fatal("Invalid flags", zap.Error(err))
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	count, err := export(ctx, services, req)
+	if err != nil {
+		logger.Fatal("Export failed", zap.Int("exported_before_failure", count), zap.Error(err))
+	}
+
+	logger.Info("Export completed", zap.Int("transactions", count), zap.String("output", req.output))
+}
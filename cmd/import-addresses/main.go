@@ -0,0 +1,244 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+type importStats struct {
+	matched    int
+	imported   int
+	mismatched int
+}
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  import-addresses import [--asset SYMBOL] [--dry-run]")
+	fmt.Println("  import-addresses list")
+	fmt.Println("  import-addresses assign --id ADDRESS_ID --email EMAIL")
+}
+
+// reconcileAddress compares one Prime address with the addresses table, storing it under the
+// holding user if it is not there yet
+func reconcileAddress(ctx context.Context, dbService *database.Service, wallet models.Wallet, assetConfig models.AssetConfig, primeAddress models.DepositAddress, dryRun bool, stats *importStats) error {
+	stored, err := dbService.FindAddress(ctx, primeAddress.Address, assetConfig.Network)
+	if err != nil {
+		return err
+	}
+
+	assetNetwork := assetConfig.AssetNetwork()
+	if stored != nil {
+		if stored.WalletId != wallet.Id {
+			stats.mismatched++
+			fmt.Printf("%-10s %-25s %s (stored with wallet %s, Prime lists it under %s)\n",
+				"MISMATCH", assetNetwork, primeAddress.Address, stored.WalletId, wallet.Id)
+			return nil
+		}
+		stats.matched++
+		return nil
+	}
+
+	stats.imported++
+	if dryRun {
+		fmt.Printf("%-10s %-25s %s (wallet %s)\n", "WOULD-ADD", assetNetwork, primeAddress.Address, wallet.Id)
+		return nil
+	}
+
+	accountIdentifier := primeAddress.Id
+	if accountIdentifier == "" {
+		accountIdentifier = primeAddress.Address
+	}
+	addr, err := dbService.ImportHeldAddress(ctx, database.StoreAddressParams{
+		Asset:             assetConfig.Symbol,
+		Network:           assetConfig.Network,
+		Address:           primeAddress.Address,
+		WalletId:          wallet.Id,
+		AccountIdentifier: accountIdentifier,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-10s %-25s %s (id %s)\n", "IMPORTED", assetNetwork, addr.Address, addr.Id)
+	return nil
+}
+
+func importAddresses(ctx context.Context, services *common.Services, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	assetFlag := fs.String("asset", "", "Only import addresses for this asset symbol (optional)")
+	dryRunFlag := fs.Bool("dry-run", false, "Report what would be imported without changing the database")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	assetConfigs, err := common.LoadAssetConfig("assets.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to load asset config: %w", err)
+	}
+
+	common.PrintHeader("PRIME ADDRESS IMPORT", common.WideWidth)
+
+	stats := importStats{}
+	walletsBySymbol := make(map[string][]models.Wallet)
+	for _, assetConfig := range common.EnabledAssets(assetConfigs) {
+		if *assetFlag != "" && !strings.EqualFold(assetConfig.Symbol, *assetFlag) {
+			continue
+		}
+
+		wallets, ok := walletsBySymbol[assetConfig.Symbol]
+		if !ok {
+			wallets, err = services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{assetConfig.Symbol})
+			if err != nil {
+				return err
+			}
+			wallets = common.TradingWallets(services.Wallets, wallets, assetConfig.Symbol)
+			walletsBySymbol[assetConfig.Symbol] = wallets
+		}
+
+		for _, wallet := range wallets {
+			primeAddresses, err := services.PrimeService.ListWalletAddresses(ctx, services.DefaultPortfolio.Id, wallet.Id, assetConfig.Network)
+			if err != nil {
+				return err
+			}
+
+			services.Logger.Info("Reconciling wallet addresses",
+				zap.String("wallet_id", wallet.Id),
+				zap.String("asset_network", assetConfig.AssetNetwork()),
+				zap.Int("prime_addresses", len(primeAddresses)))
+
+			for _, primeAddress := range primeAddresses {
+				if err := reconcileAddress(ctx, services.DbService, wallet, assetConfig, primeAddress, *dryRunFlag, &stats); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	verb := "imported"
+	if *dryRunFlag {
+		verb = "would be imported"
+	}
+	summary := fmt.Sprintf("SUMMARY: %d already stored, %d %s, %d stored under a different wallet",
+		stats.matched, stats.imported, verb, stats.mismatched)
+	common.PrintFooter(summary, common.WideWidth)
+
+	if stats.imported > 0 && !*dryRunFlag {
+		fmt.Println("Imported addresses are held unassigned. Use 'import-addresses assign' to attach each one to a user.")
+	}
+	return nil
+}
+
+func listHeld(ctx context.Context, dbService *database.Service) error {
+	addresses, err := dbService.GetAllUserAddresses(ctx, database.ImportHoldingUserId)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("UNASSIGNED IMPORTED ADDRESSES", common.WideWidth)
+	for i, addr := range addresses {
+		isLast := i == len(addresses)-1
+		fmt.Printf("%s %s  %-25s %s\n", common.BoxPrefix(isLast), addr.Id, fmt.Sprintf("%s-%s", addr.Asset, addr.Network), addr.Address)
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d unassigned addresses", len(addresses)), common.WideWidth)
+	return nil
+}
+
+func assignHeld(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("assign", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Imported address id, as shown by list (required)")
+	emailFlag := fs.String("email", "", "User to assign the address to (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *emailFlag == "" {
+		return fmt.Errorf("both flags are required: --id, --email")
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := dbService.AssignHeldAddress(ctx, *idFlag, user.Id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Assigned address %s to %s\n", *idFlag, user.Email)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "import":
+		services, initErr := common.InitializeServices(ctx, cfg, logger)
+		if initErr != nil {
+			logger.Fatal("Failed to initialize services", zap.Error(initErr))
+		}
+		defer services.Close()
+		err = importAddresses(ctx, services, args)
+	case "list", "assign":
+		dbService, initErr := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if initErr != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(initErr))
+		}
+		defer dbService.Close()
+		if command == "list" {
+			err = listHeld(ctx, dbService)
+		} else {
+			err = assignHeld(ctx, dbService, args)
+		}
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Import addresses command failed", zap.String("command", command), zap.Error(err))
+	}
+}
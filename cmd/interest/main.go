@@ -0,0 +1,207 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type accrualSummary struct {
+	userId     string
+	asset      string
+	days       int
+	total      decimal.Decimal
+	annualRate decimal.Decimal
+	lastDate   string
+}
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  interest accrue [--date YYYY-MM-DD]")
+	fmt.Println("  interest report [--from YYYY-MM-DD] [--to YYYY-MM-DD] [--email EMAIL]")
+}
+
+func accrue(ctx context.Context, cfg *models.Config, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("accrue", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	dateFlag := fs.String("date", "", "Accrual date (default: yesterday, UTC)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if len(cfg.Interest.Rates) == 0 {
+		return fmt.Errorf("no interest rates configured - set INTEREST_RATES (e.g. USDC=0.045,ETH=0.02)")
+	}
+
+	date := time.Now().UTC().AddDate(0, 0, -1)
+	if *dateFlag != "" {
+		parsed, err := time.Parse(database.InterestDateLayout, *dateFlag)
+		if err != nil {
+			return fmt.Errorf("invalid date format: %w", err)
+		}
+		date = parsed
+	}
+
+	accruals, err := dbService.AccrueInterest(ctx, database.AccrueInterestParams{
+		Date:       date,
+		Rates:      cfg.Interest.Rates,
+		MinBalance: cfg.Interest.MinBalance,
+	})
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader(fmt.Sprintf("INTEREST ACCRUAL %s", date.Format(database.InterestDateLayout)), common.DefaultWidth)
+	for i, accrual := range accruals {
+		fmt.Printf("%s %-36s %-10s %15s on %s @ %s\n",
+			common.BoxPrefix(i == len(accruals)-1),
+			accrual.UserId,
+			accrual.Asset,
+			accrual.Amount.String(),
+			accrual.Balance.String(),
+			accrual.AnnualRate.String())
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d accruals posted (existing accruals for this date are skipped)", len(accruals)), common.DefaultWidth)
+	return nil
+}
+
+func report(ctx context.Context, dbService *database.Service, args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	now := time.Now().UTC()
+	fromFlag := fs.String("from", now.AddDate(0, 0, -30).Format(database.InterestDateLayout), "First accrual date (inclusive)")
+	toFlag := fs.String("to", now.Format(database.InterestDateLayout), "Last accrual date (inclusive)")
+	emailFlag := fs.String("email", "", "Filter by specific user email (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	users, err := common.InitializeUsers(ctx, dbService, *emailFlag, logger)
+	if err != nil {
+		return err
+	}
+	usersById := make(map[string]common.UserInfo, len(users))
+	for _, user := range users {
+		usersById[user.Id] = user
+	}
+
+	accruals, err := dbService.ListInterestAccruals(ctx, *fromFlag, *toFlag)
+	if err != nil {
+		return err
+	}
+
+	summaries := make(map[string]*accrualSummary)
+	var keys []string
+	totals := make(map[string]decimal.Decimal)
+	for _, accrual := range accruals {
+		if _, ok := usersById[accrual.UserId]; !ok {
+			continue
+		}
+		key := accrual.UserId + ":" + accrual.Asset
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &accrualSummary{userId: accrual.UserId, asset: accrual.Asset}
+			summaries[key] = summary
+			keys = append(keys, key)
+		}
+		summary.days++
+		summary.total = summary.total.Add(accrual.Amount)
+		summary.annualRate = accrual.AnnualRate
+		summary.lastDate = accrual.AccrualDate
+		totals[accrual.Asset] = totals[accrual.Asset].Add(accrual.Amount)
+	}
+	sort.Strings(keys)
+
+	common.PrintHeader(fmt.Sprintf("INTEREST ACCRUAL REPORT %s to %s", *fromFlag, *toFlag), common.DefaultWidth)
+	for i, key := range keys {
+		summary := summaries[key]
+		user := usersById[summary.userId]
+		fmt.Printf("%s %-25s %-10s %15s over %3d days (rate %s, last %s)\n",
+			common.BoxPrefix(i == len(keys)-1),
+			user.Email,
+			summary.asset,
+			summary.total.String(),
+			summary.days,
+			summary.annualRate.String(),
+			summary.lastDate)
+	}
+
+	assets := make([]string, 0, len(totals))
+	for asset := range totals {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	footer := fmt.Sprintf("SUMMARY: %d user/asset accruals", len(keys))
+	for _, asset := range assets {
+		footer += fmt.Sprintf("\n  %-10s total interest %s", asset, totals[asset].String())
+	}
+	common.PrintFooter(footer, common.DefaultWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "accrue":
+		err = accrue(ctx, cfg, dbService, args)
+	case "report":
+		err = report(ctx, dbService, args, logger)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Interest command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,216 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  invoices create --email EMAIL --asset SYMBOL [--network NETWORK] [--amount AMOUNT] [--reference REF] [--ttl 24h]")
+	fmt.Println("  invoices list")
+}
+
+// findAssetConfig returns the configured asset/network pair for symbol; network may be omitted when
+// the asset is only configured on one network
+func findAssetConfig(assetConfigs []models.AssetConfig, symbol, network string) (models.AssetConfig, error) {
+	var matches []models.AssetConfig
+	for _, assetConfig := range assetConfigs {
+		if assetConfig.Symbol == symbol && (network == "" || assetConfig.Network == network) {
+			matches = append(matches, assetConfig)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return models.AssetConfig{}, fmt.Errorf("%s is not configured in assets.yaml", models.AssetConfig{Symbol: symbol, Network: network}.AssetNetwork())
+	case 1:
+		return matches[0], nil
+	default:
+		return models.AssetConfig{}, fmt.Errorf("%s is configured on several networks, pass --network", symbol)
+	}
+}
+
+func create(ctx context.Context, services *common.Services, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	emailFlag := fs.String("email", "", "User the payment is credited to (required)")
+	assetFlag := fs.String("asset", "", "Asset symbol of the expected payment (required)")
+	networkFlag := fs.String("network", "", "Network (required when the asset is configured on several)")
+	amountFlag := fs.String("amount", "0", "Expected amount (optional, used to flag mismatches)")
+	referenceFlag := fs.String("reference", "", "Invoice reference (default a generated id)")
+	ttlFlag := fs.Duration("ttl", 24*time.Hour, "How long the invoice is payable; later payments are credited but flagged")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *emailFlag == "" || *assetFlag == "" {
+		return fmt.Errorf("both flags are required: --email, --asset")
+	}
+	if *ttlFlag <= 0 {
+		return fmt.Errorf("--ttl must be positive")
+	}
+
+	amount, err := decimal.NewFromString(*amountFlag)
+	if err != nil {
+		return fmt.Errorf("invalid amount: %w", err)
+	}
+
+	reference := *referenceFlag
+	if reference == "" {
+		reference = uuid.New().String()
+	}
+
+	assetConfigs, err := common.LoadAssetConfig("assets.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to load asset config: %w", err)
+	}
+	assetConfig, err := findAssetConfig(assetConfigs, *assetFlag, *networkFlag)
+	if err != nil {
+		return err
+	}
+
+	user, err := services.DbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	// The address is created in the asset's existing trading wallet so the listener already monitors it
+	wallets, err := services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{assetConfig.Symbol})
+	if err != nil {
+		return fmt.Errorf("error listing wallets: %w", err)
+	}
+	wallet := common.SelectTradingWallet(services.Wallets, wallets, assetConfig.Symbol)
+	if wallet == nil {
+		return fmt.Errorf("no %s trading wallet found, run setup first", assetConfig.Symbol)
+	}
+
+	depositAddress, err := services.PrimeService.CreateDepositAddress(ctx, services.DefaultPortfolio.Id, wallet.Id, assetConfig.Symbol, assetConfig.Network)
+	if err != nil {
+		return fmt.Errorf("error creating deposit address: %w", err)
+	}
+
+	invoice, err := services.DbService.CreateInvoiceAddress(ctx, database.CreateInvoiceAddressParams{
+		Reference: reference,
+		Address: database.StoreAddressParams{
+			UserId:            user.Id,
+			Asset:             assetConfig.Symbol,
+			Network:           assetConfig.Network,
+			Address:           depositAddress.Address,
+			WalletId:          wallet.Id,
+			AccountIdentifier: depositAddress.Id,
+		},
+		Amount:    amount,
+		ExpiresAt: time.Now().Add(*ttlFlag),
+	})
+	if err != nil {
+		return fmt.Errorf("error storing invoice address: %w", err)
+	}
+
+	fmt.Printf("Invoice %s for %s: pay %s %s to %s\n", invoice.Reference, user.Email, invoice.Amount.String(), assetConfig.AssetNetwork(), invoice.Address)
+	fmt.Printf("Expires at %s\n", invoice.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+func list(ctx context.Context, dbService *database.Service) error {
+	invoices, err := dbService.ListInvoiceAddresses(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	common.PrintHeader("INVOICE ADDRESSES", common.WideWidth)
+	for i, invoice := range invoices {
+		isLast := i == len(invoices)-1
+		status := invoice.Status
+		if invoice.Expired(now) {
+			status = "expired"
+		}
+		fmt.Printf("%s %s -> %s  %s %s-%s (status: %s)\n",
+			common.BoxPrefix(isLast),
+			invoice.Reference,
+			invoice.UserId,
+			invoice.Amount.String(),
+			invoice.Asset,
+			invoice.Network,
+			status)
+		fmt.Printf("%s address: %s, expires: %s\n", common.BoxDetailPrefix(isLast), invoice.Address, invoice.ExpiresAt.Format(time.RFC3339))
+		if invoice.TransactionId != "" {
+			fmt.Printf("%s paid by transaction: %s\n", common.BoxDetailPrefix(isLast), invoice.TransactionId)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d invoice addresses", len(invoices)), common.WideWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "create":
+		// Creating an invoice generates a Prime deposit address
+		services, err := common.InitializeServices(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize services", zap.Error(err))
+		}
+		defer services.Close()
+		if err := create(ctx, services, args); err != nil {
+			logger.Fatal("Invoice command failed", zap.String("command", command), zap.Error(err))
+		}
+	case "list":
+		dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer dbService.Close()
+		if err := list(ctx, dbService); err != nil {
+			logger.Fatal("Invoice command failed", zap.String("command", command), zap.Error(err))
+		}
+	default:
+		usage()
+		os.Exit(1)
+	}
+}
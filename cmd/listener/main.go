@@ -18,15 +18,13 @@ package main
 
 import (
 	"context"
-	"os"
-	"os/signal"
-	"syscall"
+	"log"
 	"time"
 
-	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/app"
 	"prime-send-receive-go/internal/common"
 	"prime-send-receive-go/internal/config"
-	"prime-send-receive-go/internal/listener"
+	"prime-send-receive-go/internal/coordination"
 
 	"go.uber.org/zap"
 )
@@ -34,62 +32,58 @@ import (
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
-		_, _ = zap.NewProduction()
-		zap.L().Fatal("Failed to load configuration", zap.Error(err))
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	_, loggerCleanup := common.InitializeLogger()
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
 	defer loggerCleanup()
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	zap.L().Info("Starting Prime Send/Receive Listener")
+	logger.Info("Starting Prime Send/Receive Listener")
 
-	services, err := common.InitializeServices(ctx, cfg)
+	services, err := common.InitializeServices(ctx, cfg, logger)
 	if err != nil {
-		zap.L().Fatal("Failed to initialize services", zap.Error(err))
+		logger.Fatal("Failed to initialize services", zap.Error(err))
 	}
 	defer services.Close()
 
-	apiService := api.NewLedgerService(services.DbService)
+	coordinator, err := coordination.NewStore(ctx, cfg.Coordination, logger.Named("coordination"))
+	if err != nil {
+		logger.Fatal("Failed to initialize coordination store", zap.Error(err))
+	}
+	defer coordinator.Close()
 
-	sendReceiveListener := listener.NewSendReceiveListener(listener.SendReceiveListenerConfig{
-		PrimeService:    services.PrimeService,
-		ApiService:      apiService,
-		DbService:       services.DbService,
-		PortfolioId:     services.DefaultPortfolio.Id,
-		LookbackWindow:  cfg.Listener.LookbackWindow,
-		PollingInterval: cfg.Listener.PollingInterval,
-		CleanupInterval: cfg.Listener.CleanupInterval,
-	})
+	deps := app.Dependencies{Config: cfg, Services: services, Coordinator: coordinator}
 
-	if err := sendReceiveListener.Start(ctx, cfg.Listener.AssetsFile); err != nil {
-		zap.L().Fatal("Failed to start send/receive listener", zap.Error(err))
+	runner := app.NewRunner(logger.Named("runner"))
+	_, listenerComponent := app.NewListener(deps)
+	runner.Add(listenerComponent)
+	if cfg.WithdrawalQueue.Enabled {
+		runner.Add(app.NewWithdrawalWorker(deps))
+	}
+	if cfg.Interest.Enabled {
+		runner.Add(app.NewInterestJob(deps))
+	}
+	if cfg.Maintenance.Enabled {
+		runner.Add(app.NewMaintenanceJob(deps))
 	}
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
-	zap.L().Info("Send/Receive listener running - waiting for transactions...")
-	zap.L().Info("Press Ctrl+C to stop")
+	if err := runner.Start(ctx); err != nil {
+		logger.Fatal("Failed to start send/receive listener", zap.Error(err))
+	}
 
-	<-sigChan
-	zap.L().Info("Shutdown signal received, stopping send/receive listener...")
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer shutdownCancel()
+	logger.Info("Send/Receive listener running - waiting for transactions...")
+	logger.Info("Press Ctrl+C to stop")
 
-	done := make(chan struct{})
-	go func() {
-		sendReceiveListener.Stop()
-		close(done)
-	}()
+	<-shutdown.Done()
+	logger.Info("Shutdown signal received, stopping send/receive listener...")
 
-	select {
-	case <-done:
-		zap.L().Info("Send/Receive listener stopped gracefully")
-	case <-shutdownCtx.Done():
-		zap.L().Warn("Forced shutdown after timeout")
+	if runner.Stop(30 * time.Second) {
+		logger.Info("Send/Receive listener stopped gracefully")
 	}
 }
@@ -0,0 +1,118 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+func printReport(report *models.MaintenanceReport) {
+	common.PrintHeader("DATABASE MAINTENANCE", common.DefaultWidth)
+
+	checkpoint := "complete"
+	if report.CheckpointBusy {
+		checkpoint = "partial (database busy)"
+	}
+	fmt.Printf("WAL Checkpoint:   %s, %d of %d frames\n", checkpoint, report.CheckpointedFrames, report.WalFrames)
+	fmt.Printf("Pages:            %d (%d free, %d bytes each)\n", report.PageCount, report.FreelistCount, report.PageSize)
+	tables := make([]string, 0, len(report.Purged))
+	for table := range report.Purged {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		fmt.Printf("Purged:           %d rows from %s\n", report.Purged[table], table)
+	}
+	fmt.Printf("ANALYZE:          %t\n", report.Analyzed)
+	fmt.Printf("VACUUM:           %t\n", report.Vacuumed)
+	if len(report.IntegrityProblems) == 0 {
+		fmt.Printf("Integrity Check:  ok\n")
+	} else {
+		fmt.Printf("Integrity Check:  %d problems\n", len(report.IntegrityProblems))
+		for _, problem := range report.IntegrityProblems {
+			fmt.Printf("  - %s\n", problem)
+		}
+	}
+
+	common.PrintFooter(fmt.Sprintf("Completed in %s", report.Duration.Round(time.Millisecond)), common.DefaultWidth)
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	vacuumFlag := flag.Bool("vacuum", false, "Run VACUUM regardless of MAINTENANCE_VACUUM_FREE_RATIO")
+	noVacuumFlag := flag.Bool("no-vacuum", false, "Never run VACUUM")
+	checkpointFlag := flag.String("checkpoint", database.CheckpointPassive, "WAL checkpoint mode: PASSIVE or TRUNCATE")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *vacuumFlag && *noVacuumFlag {
+		logger.Fatal("--vacuum and --no-vacuum cannot be combined")
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	opts := database.MaintenanceOptions{
+		CheckpointMode:  strings.ToUpper(*checkpointFlag),
+		VacuumFreeRatio: cfg.Maintenance.VacuumFreeRatio,
+		ForceVacuum:     *vacuumFlag,
+		Retention:       cfg.Maintenance.Retention,
+	}
+	if *noVacuumFlag {
+		opts.VacuumFreeRatio = decimal.Zero
+	}
+
+	report, err := dbService.RunMaintenance(ctx, opts)
+	if err != nil {
+		logger.Fatal("Database maintenance failed", zap.Error(err))
+	}
+
+	printReport(report)
+
+	if len(report.IntegrityProblems) > 0 {
+		loggerCleanup()
+		dbService.Close()
+		os.Exit(1)
+	}
+}
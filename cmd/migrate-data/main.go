@@ -0,0 +1,136 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/migrate"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+)
+
+func printChecksum(name string, source, target migrate.Checksum) {
+	assets := make([]string, 0, len(source.Totals))
+	for asset := range source.Totals {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	fmt.Printf("\n%s\n", name)
+	for i, asset := range assets {
+		fmt.Printf("%s %-10s %s %s\n", common.BoxPrefix(i == len(assets)-1), asset,
+			source.Totals[asset].String(), common.StatusMark(source.Totals[asset].Equal(target.Totals[asset])))
+	}
+	fmt.Printf("Checksum: %s %s\n", source.Digest, common.StatusMark(source.Digest == target.Digest))
+}
+
+func printVerification(v *migrate.Verification) {
+	common.PrintHeader("MIGRATION VERIFICATION", common.DefaultWidth)
+	fmt.Printf("%-20s %12s %12s\n", "Table", "Source", "Target")
+	for _, table := range migrate.Tables {
+		fmt.Printf("%-20s %12d %12d %s\n", table, v.SourceCounts[table], v.TargetCounts[table],
+			common.StatusMark(v.SourceCounts[table] == v.TargetCounts[table]))
+	}
+
+	printChecksum("Balances", v.SourceBalances, v.TargetBalances)
+	printChecksum("Transaction amounts", v.SourceTransactions, v.TargetTransactions)
+
+	if len(v.Problems) == 0 {
+		common.PrintFooter("Target matches source", common.DefaultWidth)
+		return
+	}
+	fmt.Println()
+	for _, problem := range v.Problems {
+		fmt.Printf("  - %s\n", problem)
+	}
+	common.PrintFooter(fmt.Sprintf("%d problems found", len(v.Problems)), common.DefaultWidth)
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	sourceFlag := flag.String("source", cfg.Database.Path, "SQLite database to copy from")
+	targetFlag := flag.String("target", os.Getenv("MIGRATE_TARGET_DSN"), "Postgres connection string to copy to (or MIGRATE_TARGET_DSN)")
+	verifyOnlyFlag := flag.Bool("verify-only", false, "Compare source and target without copying")
+	batchSizeFlag := flag.Int("batch-size", 500, "Rows per insert statement")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *targetFlag == "" {
+		logger.Fatal("--target or MIGRATE_TARGET_DSN is required")
+	}
+
+	// Read-only, so the source cannot change while it is copied by this process
+	source, err := sql.Open("sqlite3", "file:"+*sourceFlag+"?mode=ro")
+	if err != nil {
+		logger.Fatal("Failed to open source database", zap.Error(err))
+	}
+	defer source.Close()
+	if err := source.PingContext(ctx); err != nil {
+		logger.Fatal("Failed to open source database", zap.String("path", *sourceFlag), zap.Error(err))
+	}
+
+	target, err := sql.Open("postgres", *targetFlag)
+	if err != nil {
+		logger.Fatal("Failed to open target database", zap.Error(err))
+	}
+	defer target.Close()
+	if err := target.PingContext(ctx); err != nil {
+		logger.Fatal("Failed to connect to target database", zap.Error(err))
+	}
+
+	migrator := migrate.NewMigrator(source, target, migrate.Postgres, *batchSizeFlag, logger.Named("migrate"))
+
+	if !*verifyOnlyFlag {
+		logger.Info("Copying ledger", zap.String("source", *sourceFlag))
+		if _, err := migrator.Copy(ctx); err != nil {
+			logger.Fatal("Migration failed", zap.Error(err))
+		}
+	}
+
+	verification, err := migrator.Verify(ctx)
+	if err != nil {
+		logger.Fatal("Verification failed", zap.Error(err))
+	}
+	printVerification(verification)
+
+	if len(verification.Problems) > 0 {
+		loggerCleanup()
+		source.Close()
+		target.Close()
+		os.Exit(1)
+	}
+}
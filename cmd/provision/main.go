@@ -0,0 +1,119 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func printResult(email string, result *models.ProvisionResult) {
+	title := "DEPOSIT ADDRESS PROVISIONED"
+	if !result.AddressCreated {
+		title = "DEPOSIT ADDRESS ALREADY PROVISIONED"
+	}
+	common.PrintHeader(title, common.DefaultWidth)
+	fmt.Printf("%s User:    %s (%s)\n", common.BoxPrefix(false), email, result.UserId)
+	fmt.Printf("%s Asset:   %s-%s\n", common.BoxPrefix(false), result.Asset, result.Network)
+	fmt.Printf("%s Wallet:  %s\n", common.BoxPrefix(false), result.WalletId)
+	fmt.Printf("%s Address: %s\n", common.BoxPrefix(true), result.Address)
+
+	footer := "Deposits to this address are credited to the user"
+	if result.WalletCreated {
+		footer = "A new trading wallet was created; restart the listener to monitor it"
+	}
+	common.PrintFooter(footer, common.DefaultWidth)
+}
+
+func main() {
+	ctx := context.Background()
+
+	emailFlag := flag.String("email", "", "User email (required)")
+	assetFlag := flag.String("asset", "", "Asset and network to provision, e.g. ETH-ethereum-mainnet (required)")
+	tenantFlag := flag.String("tenant", "", "Tenant the user belongs to; also selects its Prime portfolio (default from TENANT_ID)")
+	jsonFlag := flag.Bool("json", false, "Print the result as JSON")
+	common.RegisterOutputFlags(flag.CommandLine)
+	common.RegisterLockFlags(flag.CommandLine)
+	flag.Parse()
+
+	symbol, network, ok := strings.Cut(*assetFlag, "-")
+	if *emailFlag == "" || !ok || symbol == "" || network == "" {
+		fmt.Println("Usage: provision --email EMAIL --asset SYMBOL-network [--tenant TENANT] [--json]")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *tenantFlag != "" {
+		cfg.Tenant.Id = *tenantFlag
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	assets, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		logger.Fatal("Failed to load asset config", zap.Error(err))
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	// Serializes wallet creation with cmd/setup and other provisioning runs
+	lock, err := common.AcquireCommandLock(ctx, services.DbService, "provision", logger)
+	if err != nil {
+		logger.Fatal("Cannot provision now", zap.Error(err))
+	}
+	defer lock.Release()
+
+	ledger := api.NewLedgerService(services.DbService, logger.Named("ledger"))
+	ledger.SetAssetConfigs(assets, cfg.Listener.FundsAvailability)
+	ledger.SetAddressProvisioner(services.Custody, services.DefaultPortfolio.Id, services.Wallets)
+
+	result, err := ledger.ProvisionAsset(ctx, *emailFlag, symbol, network)
+	if err != nil {
+		logger.Fatal("Failed to provision asset", zap.String("email", *emailFlag), zap.String("asset", *assetFlag), zap.Error(err))
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			logger.Fatal("Failed to encode result", zap.Error(err))
+		}
+		return
+	}
+
+	printResult(*emailFlag, result)
+}
@@ -0,0 +1,104 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func printReceipt(receipt *models.WithdrawalReceipt) {
+	common.PrintHeader("WITHDRAWAL RECEIPT", common.DefaultWidth)
+	fmt.Printf("%s Activity ID:    %s\n", common.BoxPrefix(false), receipt.ActivityId)
+	fmt.Printf("%s Transaction ID: %s\n", common.BoxPrefix(false), valueOrNone(receipt.PrimeTransactionId))
+	fmt.Printf("%s User:           %s\n", common.BoxPrefix(false), valueOrNone(receipt.UserId))
+	fmt.Printf("%s Amount:         %s %s\n", common.BoxPrefix(false), receipt.Amount, receipt.Symbol)
+	fmt.Printf("%s Fee:            %s\n", common.BoxPrefix(false), valueOrNone(receipt.Fee))
+	fmt.Printf("%s Destination:    %s (%s)\n", common.BoxPrefix(false), receipt.Destination, receipt.DestinationType)
+	fmt.Printf("%s Asset:          %s\n", common.BoxPrefix(false), receipt.Asset)
+	fmt.Printf("%s Idempotency:    %s\n", common.BoxPrefix(true), receipt.IdempotencyKey)
+	common.PrintFooter("Submitted to Prime at "+receipt.CreatedAt.Format("2006-01-02 15:04:05"), common.DefaultWidth)
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func main() {
+	ctx := context.Background()
+
+	activityIdFlag := flag.String("activity-id", "", "Prime activity id of the withdrawal (required)")
+	jsonFlag := flag.Bool("json", false, "Print the receipt as JSON")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *activityIdFlag == "" {
+		fmt.Println("Usage: receipt --activity-id ACTIVITY_ID [--json]")
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	ledger := api.NewLedgerService(dbService, logger.Named("ledger"))
+	receipt, err := ledger.GetWithdrawalReceipt(ctx, *activityIdFlag)
+	if errors.Is(err, database.ErrReceiptNotFound) {
+		fmt.Printf("No receipt for activity %s\n", *activityIdFlag)
+		os.Exit(1)
+	}
+	if err != nil {
+		logger.Fatal("Failed to get withdrawal receipt", zap.Error(err))
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(receipt); err != nil {
+			logger.Fatal("Failed to encode receipt", zap.Error(err))
+		}
+		return
+	}
+
+	printReceipt(receipt)
+}
@@ -0,0 +1,107 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func printRecoveries(recoveries []models.WithdrawalRecovery) {
+	common.PrintHeader("WITHDRAWAL RECOVERY", common.WideWidth)
+	counts := make(map[string]int)
+	for i, recovery := range recoveries {
+		isLast := i == len(recoveries)-1
+		w := recovery.Withdrawal
+		counts[recovery.Outcome]++
+		fmt.Printf("%s %s  %s %s for %s (%s)\n",
+			common.BoxPrefix(isLast),
+			w.IdempotencyKey,
+			w.Amount.String(),
+			w.Asset,
+			w.UserId,
+			recovery.Outcome)
+		switch {
+		case recovery.Error != "":
+			fmt.Printf("%s error: %s\n", common.BoxDetailPrefix(isLast), recovery.Error)
+		case recovery.PrimeTransactionId != "":
+			fmt.Printf("%s prime tx: %s\n", common.BoxDetailPrefix(isLast), recovery.PrimeTransactionId)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d released, %d submitted, %d unresolved",
+		counts[models.WithdrawalRecoveryReleased],
+		counts[models.WithdrawalRecoverySubmitted],
+		counts[models.WithdrawalRecoveryUnresolved]), common.WideWidth)
+}
+
+func main() {
+	ctx := context.Background()
+
+	olderThanFlag := flag.Duration("older-than", 10*time.Minute, "Only recover withdrawals reserved at least this long ago")
+	jsonFlag := flag.Bool("json", false, "Print the outcomes as JSON")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	ledger := api.NewLedgerService(services.DbService, logger.Named("ledger"))
+	recoveries, err := ledger.RecoverWithdrawals(ctx, services.Custody, services.DefaultPortfolio.Id, *olderThanFlag)
+	if err != nil {
+		logger.Fatal("Failed to recover withdrawals", zap.Error(err))
+	}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(recoveries); err != nil {
+			logger.Fatal("Failed to encode recoveries", zap.Error(err))
+		}
+	} else {
+		printRecoveries(recoveries)
+	}
+
+	// Unresolved withdrawals keep their holds; a non-zero exit lets a scheduled run flag them
+	for _, recovery := range recoveries {
+		if recovery.Outcome == models.WithdrawalRecoveryUnresolved {
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type refundRequest struct {
+	txId        string
+	amount      decimal.Decimal
+	reason      string
+	requestedBy string
+	queue       bool
+	approve     string
+	reject      string
+	approver    string
+	list        bool
+}
+
+func parseAndValidateFlags() (*refundRequest, error) {
+	txIdFlag := flag.String("tx-id", "", "Deposit to refund: Prime transaction id or ledger transaction id")
+	amountFlag := flag.String("amount", "", "Amount to refund (default the full deposit)")
+	reasonFlag := flag.String("reason", "", "Why the deposit is returned")
+	requestedByFlag := flag.String("requested-by", os.Getenv("USER"), "Who requests the refund")
+	queueFlag := flag.Bool("queue", false, "Queue the refund withdrawal for the background worker instead of calling Prime directly")
+	approveFlag := flag.String("approve", "", "Approve and send a refund waiting for approval, by refund id")
+	rejectFlag := flag.String("reject", "", "Reject a refund waiting for approval, by refund id")
+	approverFlag := flag.String("approver", os.Getenv("USER"), "Who approves or rejects the refund (must differ from the requester)")
+	listFlag := flag.Bool("list", false, "Show recent refunds and exit")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	req := &refundRequest{
+		txId:        *txIdFlag,
+		reason:      *reasonFlag,
+		requestedBy: *requestedByFlag,
+		queue:       *queueFlag,
+		approve:     *approveFlag,
+		reject:      *rejectFlag,
+		approver:    *approverFlag,
+		list:        *listFlag,
+	}
+
+	if req.list || req.approve != "" || req.reject != "" {
+		return req, nil
+	}
+
+	if req.txId == "" {
+		return nil, fmt.Errorf("one of --tx-id, --approve, --reject or --list is required")
+	}
+
+	if *amountFlag != "" {
+		amount, err := decimal.NewFromString(*amountFlag)
+		if err != nil {
+			return nil, fmt.Errorf("invalid amount format: %w", err)
+		}
+		if !amount.IsPositive() {
+			return nil, fmt.Errorf("amount must be greater than zero")
+		}
+		req.amount = amount
+	}
+
+	return req, nil
+}
+
+func printRefunds(ctx context.Context, dbService *database.Service) error {
+	refunds, err := dbService.ListRefunds(ctx, 20)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("REFUNDS", common.WideWidth)
+	pending := 0
+	for i, refund := range refunds {
+		if refund.Status == database.RefundStatusPendingApproval {
+			pending++
+		}
+		isLast := i == len(refunds)-1
+		fmt.Printf("%s %-16s %s %s %s %s\n",
+			common.BoxPrefix(isLast), refund.Status, refund.Amount.String(), refund.Asset, common.Arrow(), refund.Destination)
+		detail := common.BoxDetailPrefix(isLast)
+		fmt.Printf("%s   Refund ID: %s  Deposit: %s\n", detail, refund.Id, refund.DepositTransactionId)
+		fmt.Printf("%s   Requested by: %s  Created: %s\n", detail, refund.RequestedBy, refund.CreatedAt.Format("2006-01-02 15:04:05"))
+		if refund.ApprovedBy != "" {
+			fmt.Printf("%s   Decided by: %s\n", detail, refund.ApprovedBy)
+		}
+		if refund.Reason != "" {
+			fmt.Printf("%s   Reason: %s\n", detail, refund.Reason)
+		}
+		if refund.LastError != "" {
+			fmt.Printf("%s   Last Error: %s\n", detail, refund.LastError)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d refunds, %d pending approval", len(refunds), pending), common.WideWidth)
+
+	return nil
+}
+
+func printRefundResult(result *models.RefundResult) {
+	refund := result.Refund
+	if refund.Status == database.RefundStatusPendingApproval {
+		fmt.Printf("⏳ Refund is above the approval threshold and waits for approval\n")
+		fmt.Printf("   Refund ID:   %s\n", refund.Id)
+		fmt.Printf("   Amount:      %s %s\n", refund.Amount.String(), refund.Asset)
+		fmt.Printf("   Destination: %s\n\n", refund.Destination)
+		fmt.Printf("Another operator approves it with: go run cmd/refund/main.go --approve %s\n", refund.Id)
+		return
+	}
+
+	fmt.Printf("✅ Refund sent\n")
+	fmt.Printf("   Refund ID:       %s\n", refund.Id)
+	fmt.Printf("   Deposit:         %s\n", refund.DepositTransactionId)
+	fmt.Printf("   Amount:          %s %s\n", refund.Amount.String(), refund.Asset)
+	fmt.Printf("   Destination:     %s\n", refund.Destination)
+	fmt.Printf("   Withdrawal:      %s\n", result.Withdrawal.Status)
+	fmt.Printf("   Idempotency Key: %s\n", result.Withdrawal.IdempotencyKey)
+	if result.Withdrawal.ActivityId != "" {
+		fmt.Printf("   Activity ID:     %s\n", result.Withdrawal.ActivityId)
+	}
+	fmt.Printf("   Correlation ID:  %s\n\n", result.Withdrawal.CorrelationId)
+}
+
+func main() {
+	ctx := database.WithTransactionSource(context.Background(), models.TransactionSourceCli)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	req, err := parseAndValidateFlags()
+	if err != nil {
+		logger.Fatal("Invalid flags", zap.Error(err))
+	}
+
+	if req.list || req.reject != "" {
+		dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer dbService.Close()
+
+		if req.list {
+			if err := printRefunds(ctx, dbService); err != nil {
+				logger.Fatal("Failed to read refunds", zap.Error(err))
+			}
+			return
+		}
+
+		if err := dbService.RejectRefund(ctx, req.reject, req.approver); err != nil {
+			logger.Fatal("Failed to reject refund", zap.Error(err))
+		}
+		fmt.Printf("Refund %s rejected; the deposit can be refunded again later\n", req.reject)
+		return
+	}
+
+	logger.Info("Initializing services")
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	ledger := api.NewLedgerService(services.DbService, logger.Named("ledger"))
+	ledger.SetWithdrawalSubmitter(services.Custody, services.DefaultPortfolio.Id)
+	ledger.SetWithdrawalLimits(cfg.Api.WithdrawalDailyLimits)
+	ledger.SetRefundApprovalThresholds(cfg.Refund.ApprovalThresholds)
+
+	var result *models.RefundResult
+	if req.approve != "" {
+		result, err = ledger.ApproveRefund(ctx, req.approve, req.approver, req.queue)
+	} else {
+		result, err = ledger.RequestRefund(ctx, models.RefundRequest{
+			DepositId:   req.txId,
+			Amount:      req.amount,
+			Reason:      req.reason,
+			RequestedBy: req.requestedBy,
+			Queue:       req.queue,
+		})
+	}
+	if err != nil {
+		logger.Fatal("Refund failed", zap.Error(err))
+	}
+
+	printRefundResult(result)
+}
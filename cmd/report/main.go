@@ -0,0 +1,345 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// maxBuckets keeps a heatmap row on one line
+const maxBuckets = 96
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  report activity [--since 24h] [--bucket hour|day] [--detail]    (transaction counts and volumes per asset and wallet over time)")
+	fmt.Println("  report destinations [--since 0] [--min-users 1] [--limit 50] [--detail]    (withdrawal history per destination address)")
+}
+
+// activityRow is one heatmap row: the activity of an asset, or of an asset through one wallet
+type activityRow struct {
+	label   string
+	asset   string
+	counts  []int
+	volumes []decimal.Decimal
+	total   int
+	volume  decimal.Decimal
+}
+
+func showActivity(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("activity", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	sinceFlag := fs.Duration("since", 24*time.Hour, "How far back to report")
+	bucketFlag := fs.String("bucket", "hour", "Bucket size: hour or day")
+	detailFlag := fs.Bool("detail", false, "List the count and volume of every non-empty bucket")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var bucket time.Duration
+	switch *bucketFlag {
+	case "hour":
+		bucket = time.Hour
+	case "day":
+		bucket = 24 * time.Hour
+	default:
+		return fmt.Errorf("--bucket must be hour or day, got %q", *bucketFlag)
+	}
+	if *sinceFlag <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+
+	to := time.Now().UTC()
+	from := to.Add(-*sinceFlag).Truncate(bucket)
+	buckets := int(to.Sub(from)/bucket) + 1
+	if buckets > maxBuckets {
+		return fmt.Errorf("%d buckets do not fit in a report (max %d); use a shorter --since or --bucket day", buckets, maxBuckets)
+	}
+
+	activity, err := dbService.ListWalletActivity(ctx, from, to, bucket)
+	if err != nil {
+		return err
+	}
+
+	byAsset := make(map[string]*activityRow)
+	byWallet := make(map[string]*activityRow)
+	total := 0
+	for _, a := range activity {
+		index := int(a.BucketStart.Sub(from) / bucket)
+		if index < 0 || index >= buckets {
+			continue
+		}
+		walletLabel := a.WalletId
+		if walletLabel == "" {
+			walletLabel = "(unknown wallet)"
+		}
+		addActivity(byAsset, a.Asset, a.Asset, buckets, index, a)
+		addActivity(byWallet, walletLabel+" "+a.Asset, a.Asset, buckets, index, a)
+		total += a.Count
+	}
+
+	unit := "hourly"
+	if bucket == 24*time.Hour {
+		unit = "daily"
+	}
+	fmt.Printf("\n%s buckets from %s to %s UTC\n", unit, from.Format("2006-01-02 15:04"), from.Add(time.Duration(buckets)*bucket).Format("2006-01-02 15:04"))
+
+	printHeatmap("ACTIVITY BY ASSET", sortedRows(byAsset), from, bucket, *detailFlag)
+	printHeatmap("ACTIVITY BY WALLET", sortedRows(byWallet), from, bucket, *detailFlag)
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d transactions across %d assets and %d wallet/asset pairs", total, len(byAsset), len(byWallet)), common.WideWidth)
+	return nil
+}
+
+func addActivity(rows map[string]*activityRow, label, asset string, buckets, index int, a models.WalletActivity) {
+	row, ok := rows[label]
+	if !ok {
+		row = &activityRow{label: label, asset: asset, counts: make([]int, buckets), volumes: make([]decimal.Decimal, buckets)}
+		rows[label] = row
+	}
+	row.counts[index] += a.Count
+	row.volumes[index] = row.volumes[index].Add(a.Volume)
+	row.total += a.Count
+	row.volume = row.volume.Add(a.Volume)
+}
+
+func sortedRows(rows map[string]*activityRow) []*activityRow {
+	result := make([]*activityRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].label < result[j].label
+	})
+	return result
+}
+
+// printHeatmap prints one row per entry, shading each bucket by its count relative to the busiest
+// bucket in the section, followed by the row's totals and its peak bucket
+func printHeatmap(title string, rows []*activityRow, from time.Time, bucket time.Duration, detail bool) {
+	common.PrintHeader(title, common.WideWidth)
+	if len(rows) == 0 {
+		fmt.Println("No transactions in this period")
+		return
+	}
+
+	maxCount, labelWidth := 0, 0
+	for _, row := range rows {
+		for _, count := range row.counts {
+			maxCount = max(maxCount, count)
+		}
+		labelWidth = max(labelWidth, len(row.label))
+	}
+
+	for i, row := range rows {
+		isLast := i == len(rows)-1
+		peak := 0
+		for b, count := range row.counts {
+			if count > row.counts[peak] {
+				peak = b
+			}
+		}
+		fmt.Printf("%s %-*s %s  %d tx, %s %s, peak %d at %s\n",
+			common.BoxPrefix(isLast),
+			labelWidth, row.label,
+			heatmap(row.counts, maxCount),
+			row.total,
+			row.volume.String(), row.asset,
+			row.counts[peak], bucketLabel(from.Add(time.Duration(peak)*bucket), bucket))
+
+		if !detail {
+			continue
+		}
+		for b, count := range row.counts {
+			if count == 0 {
+				continue
+			}
+			fmt.Printf("%s %s %s %d tx, %s %s\n",
+				common.BoxDetailPrefix(isLast),
+				bucketLabel(from.Add(time.Duration(b)*bucket), bucket),
+				common.Arrow(),
+				count,
+				row.volumes[b].String(), row.asset)
+		}
+	}
+}
+
+// heatmap renders one character per bucket, from empty to the section's busiest bucket
+func heatmap(counts []int, maxCount int) string {
+	shades := []string{"·", "░", "▒", "▓", "█"}
+	if common.PlainOutput() {
+		shades = []string{".", "-", "+", "*", "#"}
+	}
+
+	var b strings.Builder
+	for _, count := range counts {
+		level := 0
+		if count > 0 && maxCount > 0 {
+			// Any activity gets at least the lightest shade
+			level = 1 + (count-1)*(len(shades)-2)/max(maxCount-1, 1)
+		}
+		b.WriteString(shades[level])
+	}
+	return b.String()
+}
+
+func showDestinations(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("destinations", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	sinceFlag := fs.Duration("since", 0, "How far back to report (0 for all history)")
+	minUsersFlag := fs.Int("min-users", 1, "Only list destinations used by at least this many users")
+	limitFlag := fs.Int("limit", 50, "Maximum number of destinations to list (0 for all)")
+	detailFlag := fs.Bool("detail", false, "List the users behind every destination")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *sinceFlag < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
+
+	var since time.Time
+	if *sinceFlag > 0 {
+		since = time.Now().UTC().Add(-*sinceFlag)
+	}
+	destinations, err := dbService.ListDestinationActivity(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	var listed []models.DestinationActivity
+	shared := 0
+	for _, destination := range destinations {
+		if len(destination.Users) > 1 {
+			shared++
+		}
+		if len(destination.Users) >= *minUsersFlag {
+			listed = append(listed, destination)
+		}
+	}
+	matching := len(listed)
+	if *limitFlag > 0 && len(listed) > *limitFlag {
+		listed = listed[:*limitFlag]
+	}
+
+	common.PrintHeader("WITHDRAWAL DESTINATIONS", common.WideWidth)
+	if len(listed) == 0 {
+		fmt.Println("No withdrawals to report")
+	}
+	for i, destination := range listed {
+		isLast := i == len(listed)-1
+		fmt.Printf("%s %s  %d users, %d withdrawals, %s, first %s, last %s\n",
+			common.BoxPrefix(isLast),
+			destination.Address,
+			len(destination.Users),
+			destination.Count,
+			formatVolumes(destination.Volumes),
+			destination.FirstSeen.UTC().Format("2006-01-02 15:04"),
+			destination.LastSeen.UTC().Format("2006-01-02 15:04"))
+
+		if !*detailFlag {
+			continue
+		}
+		for _, user := range destination.Users {
+			label := user.UserId
+			if user.Email != "" {
+				label = fmt.Sprintf("%s (%s)", user.Email, user.UserId)
+			}
+			fmt.Printf("%s %s %s %d withdrawals\n", common.BoxDetailPrefix(isLast), label, common.Arrow(), user.Count)
+		}
+	}
+
+	summary := fmt.Sprintf("SUMMARY: %d destinations, %d used by more than one user", len(destinations), shared)
+	if len(listed) < matching {
+		summary += fmt.Sprintf(" (showing %d of %d)", len(listed), matching)
+	}
+	common.PrintFooter(summary, common.WideWidth)
+	return nil
+}
+
+// formatVolumes lists per-asset volumes in asset order, e.g. "1.5 ETH, 200 USDC"
+func formatVolumes(volumes map[string]decimal.Decimal) string {
+	assets := make([]string, 0, len(volumes))
+	for asset := range volumes {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	parts := make([]string, 0, len(assets))
+	for _, asset := range assets {
+		parts = append(parts, volumes[asset].String()+" "+asset)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func bucketLabel(start time.Time, bucket time.Duration) string {
+	if bucket >= 24*time.Hour {
+		return start.Format("2006-01-02")
+	}
+	return start.Format("2006-01-02 15:00")
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "activity":
+		err = showActivity(ctx, dbService, args)
+	case "destinations":
+		err = showDestinations(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Report command failed", zap.String("command", command), zap.Error(err))
+	}
+}
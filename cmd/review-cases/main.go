@@ -0,0 +1,143 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  review-cases list [--status open|closed|all] [--detail]")
+	fmt.Println("  review-cases close --id ID [--note NOTE]")
+}
+
+func listCases(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	statusFlag := fs.String("status", database.ReviewCaseStatusOpen, "Filter by status (open, closed, all)")
+	detailFlag := fs.Bool("detail", false, "Show the detector's findings for each case")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	status := *statusFlag
+	if status == "all" {
+		status = ""
+	}
+
+	cases, err := dbService.ListReviewCases(ctx, status)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("REVIEW CASES", common.WideWidth)
+	for i, reviewCase := range cases {
+		isLast := i == len(cases)-1
+		fmt.Printf("%s %s  %s for %s (%s, status: %s)\n",
+			common.BoxPrefix(isLast),
+			reviewCase.Id,
+			reviewCase.Kind,
+			reviewCase.UserId,
+			reviewCase.Asset,
+			reviewCase.Status)
+		fmt.Printf("%s %s, opened: %s\n",
+			common.BoxDetailPrefix(isLast),
+			reviewCase.Summary,
+			reviewCase.CreatedAt.Format("2006-01-02 15:04:05"))
+		if *detailFlag && reviewCase.Details != "" {
+			fmt.Printf("%s %s\n", common.BoxDetailPrefix(isLast), reviewCase.Details)
+		}
+		if reviewCase.ResolvedAt != nil {
+			fmt.Printf("%s closed: %s note=%s\n",
+				common.BoxDetailPrefix(isLast),
+				reviewCase.ResolvedAt.Format("2006-01-02 15:04:05"),
+				reviewCase.ResolutionNote)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d review cases", len(cases)), common.WideWidth)
+	return nil
+}
+
+func closeCase(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("close", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Review case id (required)")
+	noteFlag := fs.String("note", "", "Outcome of the review (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	if err := dbService.CloseReviewCase(ctx, *idFlag, *noteFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Closed review case %s\n", *idFlag)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		err = listCases(ctx, dbService, args)
+	case "close":
+		err = closeCase(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Review case command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,224 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  rewards create-program --name NAME --asset ASSET --budget AMOUNT")
+	fmt.Println("  rewards grant --program NAME --email EMAIL --amount AMOUNT --reference REF")
+	fmt.Println("  rewards list [--program NAME]")
+}
+
+func parseAmount(value, name string) (decimal.Decimal, error) {
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid %s format: %w", name, err)
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return decimal.Zero, fmt.Errorf("%s must be greater than zero", name)
+	}
+	return amount, nil
+}
+
+func createProgram(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("create-program", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	nameFlag := fs.String("name", "", "Program name (required)")
+	assetFlag := fs.String("asset", "", "Asset symbol the program pays out in (required)")
+	budgetFlag := fs.String("budget", "", "Total budget for the program (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *nameFlag == "" || *assetFlag == "" || *budgetFlag == "" {
+		return fmt.Errorf("all flags are required: --name, --asset, --budget")
+	}
+
+	budget, err := parseAmount(*budgetFlag, "budget")
+	if err != nil {
+		return err
+	}
+
+	program, err := dbService.CreateRewardProgram(ctx, *nameFlag, *assetFlag, budget)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created reward program %s: %s %s budget\n", program.Name, program.Budget.String(), program.Asset)
+	return nil
+}
+
+func grantReward(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("grant", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	programFlag := fs.String("program", "", "Program name (required)")
+	emailFlag := fs.String("email", "", "User email (required)")
+	amountFlag := fs.String("amount", "", "Amount to credit (required)")
+	referenceFlag := fs.String("reference", "", "Unique reference for this grant, e.g. campaign or referral id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *programFlag == "" || *emailFlag == "" || *amountFlag == "" || *referenceFlag == "" {
+		return fmt.Errorf("all flags are required: --program, --email, --amount, --reference")
+	}
+
+	amount, err := parseAmount(*amountFlag, "amount")
+	if err != nil {
+		return err
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	grant, err := dbService.GrantReward(ctx, database.GrantRewardParams{
+		Program:   *programFlag,
+		UserId:    user.Id,
+		Amount:    amount,
+		Reference: *referenceFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	newBalance, err := dbService.GetUserBalance(ctx, user.Id, grant.Asset)
+	if err != nil {
+		return fmt.Errorf("failed to get updated balance: %w", err)
+	}
+
+	fmt.Printf("Granted %s %s to %s (transaction %s, new balance %s)\n",
+		grant.Amount.String(), grant.Asset, user.Email, grant.TransactionId, newBalance.String())
+	return nil
+}
+
+func listPrograms(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	programFlag := fs.String("program", "", "Show recent grants for a single program (optional)")
+	limitFlag := fs.Int("limit", 20, "Number of grants to show with --program")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	common.PrintHeader("REWARD PROGRAMS", common.DefaultWidth)
+
+	programs, err := dbService.ListRewardPrograms(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *programFlag != "" {
+		program, err := dbService.GetRewardProgram(ctx, *programFlag)
+		if err != nil {
+			return err
+		}
+		programs = programs[:0]
+		programs = append(programs, *program)
+	}
+
+	for i, program := range programs {
+		status := "active"
+		if !program.Active {
+			status = "inactive"
+		}
+		fmt.Printf("%s %-20s %-10s granted %s of %s (remaining %s, %s)\n",
+			common.BoxPrefix(i == len(programs)-1),
+			program.Name,
+			program.Asset,
+			program.Granted.String(),
+			program.Budget.String(),
+			program.Budget.Sub(program.Granted).String(),
+			status)
+	}
+
+	if *programFlag != "" {
+		grants, err := dbService.ListRewardGrants(ctx, *programFlag, *limitFlag)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nRecent grants for %s:\n", *programFlag)
+		for i, grant := range grants {
+			fmt.Printf("%s %s  %-36s %15s  ref=%s\n",
+				common.BoxPrefix(i == len(grants)-1),
+				grant.CreatedAt.Format(time.DateTime),
+				grant.UserId,
+				grant.Amount.String(),
+				grant.Reference)
+		}
+	}
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d reward programs", len(programs)), common.DefaultWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "create-program":
+		err = createProgram(ctx, dbService, args)
+	case "grant":
+		err = grantReward(ctx, dbService, args)
+	case "list":
+		err = listPrograms(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Rewards command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,228 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/app"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/listener"
+	"prime-send-receive-go/internal/notify"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Component flags default to the environment configuration
+	listenerFlag := flag.Bool("listener", cfg.Serve.ListenerEnabled, "Run the deposit and withdrawal listener")
+	workerFlag := flag.Bool("withdrawal-worker", cfg.WithdrawalQueue.Enabled, "Run the withdrawal queue worker")
+	interestFlag := flag.Bool("interest", cfg.Interest.Enabled, "Run the daily interest accrual job")
+	reconciliationFlag := flag.Bool("reconciliation", cfg.Serve.ReconciliationEnabled, "Run the periodic balance reconciliation job")
+	invariantsFlag := flag.Bool("invariants", cfg.Serve.InvariantCheckEnabled, "Run the ledger invariant monitor")
+	anomaliesFlag := flag.Bool("anomalies", cfg.Anomaly.Enabled, "Run the per-user velocity anomaly detector")
+	maintenanceFlag := flag.Bool("maintenance", cfg.Maintenance.Enabled, "Run the periodic SQLite maintenance job")
+	metricsFlag := flag.Bool("metrics", cfg.Serve.MetricsEnabled, "Serve /metrics and /healthz")
+	metricsAddrFlag := flag.String("metrics-addr", cfg.Serve.MetricsAddr, "Listen address for the metrics endpoint")
+	tenantFlag := flag.String("tenant", cfg.Tenant.Id, "Serve only this tenant's users, using its Prime portfolio")
+	webhookFlag := flag.Bool("webhook", cfg.Webhook.Enabled, "Accept signed transaction webhooks (requires the listener)")
+	digestFlag := flag.Bool("digest", cfg.Notify.DigestEnabled, "Send the daily notification digest")
+	apiFlag := flag.Bool("api", cfg.Serve.ApiEnabled, "Serve the REST API on API_ADDR")
+	grpcFlag := flag.Bool("grpc", cfg.Serve.GrpcEnabled, "Serve the gRPC ledger service on GRPC_ADDR")
+	flag.Parse()
+	cfg.Tenant.Id = *tenantFlag
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	logger.Info("Starting Prime Send/Receive service",
+		zap.Bool("listener", *listenerFlag),
+		zap.Bool("withdrawal_worker", *workerFlag),
+		zap.Bool("interest", *interestFlag),
+		zap.Bool("reconciliation", *reconciliationFlag),
+		zap.Bool("invariants", *invariantsFlag),
+		zap.Bool("anomalies", *anomaliesFlag),
+		zap.Bool("maintenance", *maintenanceFlag),
+		zap.Bool("metrics", *metricsFlag),
+		zap.Bool("webhook", *webhookFlag),
+		zap.Bool("digest", *digestFlag),
+		zap.Bool("api", *apiFlag),
+		zap.Bool("grpc", *grpcFlag))
+
+	if *grpcFlag && cfg.Api.GrpcToken == "" {
+		logger.Fatal("GRPC_TOKEN must be set to run the gRPC ledger service")
+	}
+
+	if *webhookFlag && len(cfg.Webhook.Secret) < 32 {
+		logger.Fatal("WEBHOOK_SECRET must be at least 32 characters to run the webhook receiver")
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	coordinator, err := coordination.NewStore(ctx, cfg.Coordination, logger.Named("coordination"))
+	if err != nil {
+		logger.Fatal("Failed to initialize coordination store", zap.Error(err))
+	}
+	defer coordinator.Close()
+
+	deps := app.Dependencies{Config: cfg, Services: services, Coordinator: coordinator}
+
+	// Email alerts are optional; a nil notifier means notification settings are not configured
+	notifier, err := notify.NewNotifier(cfg.Notify)
+	if err != nil {
+		logger.Fatal("Invalid notification settings", zap.Error(err))
+	}
+
+	var reconciliationJob *listener.ReconciliationJob
+	var reconciliationComponent app.Component
+	if *reconciliationFlag {
+		reconciliationJob, reconciliationComponent = app.NewReconciliationJob(deps)
+	}
+
+	// Components start in this order and stop in reverse, so health checks keep answering until the end
+	runner := app.NewRunner(logger.Named("runner"))
+	meta, err := app.NewServiceMeta(ctx, cfg, services.DbService, enabledFeatures(map[string]bool{
+		"listener":          *listenerFlag,
+		"withdrawal_worker": *workerFlag,
+		"interest":          *interestFlag,
+		"reconciliation":    *reconciliationFlag,
+		"invariants":        *invariantsFlag,
+		"anomalies":         *anomaliesFlag,
+		"maintenance":       *maintenanceFlag,
+		"metrics":           *metricsFlag,
+		"webhook":           *webhookFlag && *listenerFlag,
+		"digest":            *digestFlag && notifier != nil,
+		"api":               *apiFlag,
+		"grpc":              *grpcFlag,
+	}))
+	if err != nil {
+		logger.Fatal("Failed to describe service for /meta", zap.Error(err))
+	}
+
+	var metricsServer *app.MetricsServer
+	if *metricsFlag {
+		metricsServer = app.NewMetricsServer(*metricsAddrFlag, services.DbService, reconciliationJob, logger.Named("metrics"))
+		ledger := api.NewLedgerService(services.DbService, logger.Named("ledger"))
+		ledger.SetPrimeProbe(services.PrimeService, services.DefaultPortfolio.Id)
+		metricsServer.SetLedgerService(ledger)
+		metricsServer.SetMeta(meta)
+		runner.Add(metricsServer)
+	}
+	if *listenerFlag {
+		sendReceiveListener, listenerComponent := app.NewListener(deps)
+		runner.Add(listenerComponent)
+		if metricsServer != nil {
+			metricsServer.SetListener(sendReceiveListener)
+		}
+		// Started after the listener, which must know its monitored wallets before events arrive
+		if *webhookFlag {
+			runner.Add(app.NewWebhookServer(cfg.Webhook, sendReceiveListener, coordinator, logger.Named("webhook")))
+		}
+	} else if *webhookFlag {
+		logger.Warn("Webhook receiver requires the listener and will not be started")
+	}
+	if *apiFlag {
+		apiServer, err := app.NewApiServer(cfg, services, meta, logger.Named("api"))
+		if err != nil {
+			logger.Fatal("Failed to build API server", zap.Error(err))
+		}
+		if metricsServer != nil {
+			metricsServer.SetRateLimiter(apiServer.RateLimiter())
+			metricsServer.SetBalanceCache(apiServer.BalanceCache())
+		}
+		runner.Add(apiServer)
+	}
+	if *grpcFlag {
+		grpcServer, err := app.NewGrpcServer(cfg, services, logger.Named("grpc"))
+		if err != nil {
+			logger.Fatal("Failed to build gRPC server", zap.Error(err))
+		}
+		runner.Add(grpcServer)
+	}
+	if *workerFlag {
+		runner.Add(app.NewWithdrawalWorker(deps))
+	}
+	if *interestFlag {
+		runner.Add(app.NewInterestJob(deps))
+	}
+	if reconciliationComponent != nil {
+		runner.Add(reconciliationComponent)
+	}
+	if *invariantsFlag {
+		runner.Add(app.NewInvariantJob(deps, notifier))
+	}
+	if *anomaliesFlag {
+		runner.Add(app.NewVelocityJob(deps, notifier))
+	}
+	if *maintenanceFlag {
+		runner.Add(app.NewMaintenanceJob(deps))
+	}
+	if *digestFlag {
+		if notifier == nil {
+			logger.Warn("Notification digest requires NOTIFY_SMTP_ADDR and NOTIFY_EMAIL_TO and will not be started")
+		} else {
+			runner.Add(app.NewDigestJob(deps, notifier, reconciliationJob))
+		}
+	}
+
+	if err := runner.Start(ctx); err != nil {
+		logger.Fatal("Failed to start service", zap.Error(err))
+	}
+
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
+
+	logger.Info("Prime Send/Receive service running")
+
+	<-shutdown.Done()
+	logger.Info("Shutdown signal received, stopping service...")
+
+	if runner.Stop(30 * time.Second) {
+		logger.Info("Service stopped gracefully")
+	}
+}
+
+// enabledFeatures lists the components that will run, sorted so /meta is stable across restarts
+func enabledFeatures(components map[string]bool) []string {
+	features := make([]string, 0, len(components))
+	for name, enabled := range components {
+		if enabled {
+			features = append(features, name)
+		}
+	}
+	sort.Strings(features)
+	return features
+}
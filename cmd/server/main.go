@@ -0,0 +1,89 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"prime-send-receive-go/internal/app"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Runs only the REST API. Use cmd/serve --api to run it next to the listener and workers instead.
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	addrFlag := flag.String("addr", cfg.Api.Addr, "Listen address for the REST API")
+	tenantFlag := flag.String("tenant", cfg.Tenant.Id, "Serve only this tenant's users, using its Prime portfolio")
+	flag.Parse()
+	cfg.Api.Addr = *addrFlag
+	cfg.Tenant.Id = *tenantFlag
+
+	// The listener runs in another process, so cached balances would miss its ledger changes
+	cfg.Api.BalanceCacheSize = 0
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if cfg.Api.AdminToken == "" {
+		logger.Warn("API_ADMIN_TOKEN is not set - POST /users is disabled")
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	meta, err := app.NewServiceMeta(ctx, cfg, services.DbService, []string{"api"})
+	if err != nil {
+		logger.Fatal("Failed to describe service for /meta", zap.Error(err))
+	}
+
+	apiServer, err := app.NewApiServer(cfg, services, meta, logger.Named("api"))
+	if err != nil {
+		logger.Fatal("Failed to build API server", zap.Error(err))
+	}
+
+	runner := app.NewRunner(logger.Named("runner"))
+	runner.Add(apiServer)
+	if err := runner.Start(ctx); err != nil {
+		logger.Fatal("Failed to start API server", zap.Error(err))
+	}
+
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
+
+	<-shutdown.Done()
+	logger.Info("Shutdown signal received, stopping API server...")
+
+	if runner.Stop(10 * time.Second) {
+		logger.Info("API server stopped gracefully")
+	}
+}
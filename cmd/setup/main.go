@@ -21,6 +21,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"prime-send-receive-go/internal/common"
 	"prime-send-receive-go/internal/config"
@@ -31,10 +36,10 @@ import (
 )
 
 // checkExistingAddress checks if user already has an address for the given asset
-func checkExistingAddress(ctx context.Context, services *common.Services, user models.User, assetConfig common.AssetConfig) (bool, error) {
+func checkExistingAddress(ctx context.Context, services *common.Services, user models.User, assetConfig models.AssetConfig) (bool, error) {
 	existingAddresses, err := services.DbService.GetAddresses(ctx, user.Id, assetConfig.Symbol, assetConfig.Network)
 	if err != nil {
-		zap.L().Error("Error checking existing addresses",
+		services.Logger.Error("Error checking existing addresses",
 			zap.String("user_id", user.Id),
 			zap.String("asset", assetConfig.Symbol),
 			zap.Error(err))
@@ -42,7 +47,7 @@ func checkExistingAddress(ctx context.Context, services *common.Services, user m
 	}
 
 	if len(existingAddresses) > 0 {
-		zap.L().Info("User already has addresses for asset",
+		services.Logger.Info("User already has addresses for asset",
 			zap.String("user_id", user.Id),
 			zap.String("asset", assetConfig.Symbol),
 			zap.Int("count", len(existingAddresses)),
@@ -55,18 +60,17 @@ func checkExistingAddress(ctx context.Context, services *common.Services, user m
 
 // getOrCreateWallet retrieves an existing trading wallet or creates a new one
 func getOrCreateWallet(ctx context.Context, services *common.Services, assetSymbol string) (*models.Wallet, error) {
-	zap.L().Debug("Listing wallets for asset", zap.String("asset", assetSymbol))
+	services.Logger.Debug("Listing wallets for asset", zap.String("asset", assetSymbol))
 	wallets, err := services.PrimeService.ListWallets(ctx, services.DefaultPortfolio.Id, "TRADING", []string{assetSymbol})
 	if err != nil {
-		zap.L().Error("Error listing wallets",
+		services.Logger.Error("Error listing wallets",
 			zap.String("asset", assetSymbol),
 			zap.Error(err))
 		return nil, err
 	}
 
-	if len(wallets) > 0 {
-		wallet := &wallets[0]
-		zap.L().Info("Using existing wallet",
+	if wallet := common.SelectTradingWallet(services.Wallets, wallets, assetSymbol); wallet != nil {
+		services.Logger.Info("Using existing wallet",
 			zap.String("asset", assetSymbol),
 			zap.String("wallet_name", wallet.Name),
 			zap.String("wallet_id", wallet.Id))
@@ -74,20 +78,20 @@ func getOrCreateWallet(ctx context.Context, services *common.Services, assetSymb
 	}
 
 	// Create new wallet
-	walletName := fmt.Sprintf("%s Trading Wallet", assetSymbol)
-	zap.L().Info("Creating new wallet",
+	walletName := common.WalletName(services.Wallets, assetSymbol)
+	services.Logger.Info("Creating new wallet",
 		zap.String("asset", assetSymbol),
 		zap.String("wallet_name", walletName))
 
 	wallet, err := services.PrimeService.CreateWallet(ctx, services.DefaultPortfolio.Id, walletName, assetSymbol, "TRADING")
 	if err != nil {
-		zap.L().Error("Error creating wallet",
+		services.Logger.Error("Error creating wallet",
 			zap.String("asset", assetSymbol),
 			zap.Error(err))
 		return nil, err
 	}
 
-	zap.L().Info("Created new wallet",
+	services.Logger.Info("Created new wallet",
 		zap.String("asset", assetSymbol),
 		zap.String("wallet_name", wallet.Name),
 		zap.String("wallet_id", wallet.Id))
@@ -95,22 +99,22 @@ func getOrCreateWallet(ctx context.Context, services *common.Services, assetSymb
 }
 
 // createAndStoreAddress creates a deposit address via Prime API and stores it in the database
-func createAndStoreAddress(ctx context.Context, services *common.Services, user models.User, assetConfig common.AssetConfig, wallet *models.Wallet) error {
-	zap.L().Info("Creating deposit address",
+func createAndStoreAddress(ctx context.Context, services *common.Services, user models.User, assetConfig models.AssetConfig, wallet *models.Wallet) error {
+	services.Logger.Info("Creating deposit address",
 		zap.String("asset", assetConfig.Symbol),
 		zap.String("network", assetConfig.Network),
 		zap.String("wallet_id", wallet.Id))
 
 	depositAddress, err := services.PrimeService.CreateDepositAddress(ctx, services.DefaultPortfolio.Id, wallet.Id, assetConfig.Symbol, assetConfig.Network)
 	if err != nil {
-		zap.L().Error("Error creating deposit address",
+		services.Logger.Error("Error creating deposit address",
 			zap.String("asset", assetConfig.Symbol),
 			zap.String("network", assetConfig.Network),
 			zap.Error(err))
 		return err
 	}
 
-	zap.L().Info("Created deposit address",
+	services.Logger.Info("Created deposit address",
 		zap.String("asset", assetConfig.Symbol),
 		zap.String("network", assetConfig.Network),
 		zap.String("address", depositAddress.Address))
@@ -125,31 +129,31 @@ func createAndStoreAddress(ctx context.Context, services *common.Services, user
 		AccountIdentifier: depositAddress.Id,
 	})
 	if err != nil {
-		zap.L().Error("Error storing address to database",
+		services.Logger.Error("Error storing address to database",
 			zap.String("asset", assetConfig.Symbol),
 			zap.String("address", depositAddress.Address),
 			zap.Error(err))
 		return err
 	}
 
-	zap.L().Info("Stored address to database",
+	services.Logger.Info("Stored address to database",
 		zap.String("id", storedAddress.Id),
 		zap.String("asset", assetConfig.Symbol),
 		zap.String("address", depositAddress.Address))
 
 	addressOutput, err := json.MarshalIndent(depositAddress, "", "  ")
 	if err != nil {
-		zap.L().Error("Error marshaling address to JSON", zap.Error(err))
+		services.Logger.Error("Error marshaling address to JSON", zap.Error(err))
 	} else {
-		zap.L().Debug("Address details", zap.String("json", string(addressOutput)))
+		services.Logger.Debug("Address details", zap.String("json", string(addressOutput)))
 	}
 
 	return nil
 }
 
 // processUserAsset processes a single user-asset combination
-func processUserAsset(ctx context.Context, services *common.Services, user models.User, assetConfig common.AssetConfig) error {
-	zap.L().Info("Processing asset",
+func processUserAsset(ctx context.Context, services *common.Services, user models.User, assetConfig models.AssetConfig, wallets map[string]*models.Wallet) error {
+	services.Logger.Info("Processing asset",
 		zap.String("user_id", user.Id),
 		zap.String("asset", assetConfig.Symbol),
 		zap.String("network", assetConfig.Network))
@@ -166,96 +170,449 @@ func processUserAsset(ctx context.Context, services *common.Services, user model
 	}
 
 	// Get or create wallet
-	wallet, err := getOrCreateWallet(ctx, services, assetConfig.Symbol)
-	if err != nil {
-		return err
+	wallet, ok := wallets[assetConfig.Symbol]
+	if !ok {
+		wallet, err = getOrCreateWallet(ctx, services, assetConfig.Symbol)
+		if err != nil {
+			return err
+		}
+		wallets[assetConfig.Symbol] = wallet
 	}
 
 	// Create and store address
 	return createAndStoreAddress(ctx, services, user, assetConfig, wallet)
 }
 
-func generateAddresses(ctx context.Context, services *common.Services) {
-	zap.L().Info("Loading asset configuration")
+// setupCheckpoint names the setup command's progress record in the database
+const setupCheckpoint = "setup"
+
+// resumeIndex returns the position of the first user/asset pair after the checkpoint, or 0 if the
+// checkpointed pair is no longer in the run
+func resumeIndex(users []models.User, assetConfigs []models.AssetConfig, checkpoint *models.CommandCheckpoint) int {
+	for u, user := range users {
+		if user.Id != checkpoint.UserId {
+			continue
+		}
+		for a, assetConfig := range assetConfigs {
+			if assetConfig.AssetNetwork() == checkpoint.Asset {
+				return u*len(assetConfigs) + a + 1
+			}
+		}
+	}
+	return 0
+}
+
+// loadCheckpoint returns where to start: after the saved checkpoint with --resume, otherwise from the
+// beginning, discarding any stale checkpoint
+func loadCheckpoint(ctx context.Context, services *common.Services, resume bool, users []models.User, assetConfigs []models.AssetConfig) (int, int) {
+	checkpoint, err := services.DbService.GetCheckpoint(ctx, setupCheckpoint)
+	if err != nil {
+		services.Logger.Fatal("Failed to read setup checkpoint", zap.Error(err))
+	}
+
+	if !resume {
+		if checkpoint != nil {
+			services.Logger.Info("Discarding previous setup checkpoint - use --resume to continue from it",
+				zap.String("user_id", checkpoint.UserId),
+				zap.String("asset", checkpoint.Asset),
+				zap.Time("updated_at", checkpoint.UpdatedAt))
+			if err := services.DbService.ClearCheckpoint(ctx, setupCheckpoint); err != nil {
+				services.Logger.Fatal("Failed to clear setup checkpoint", zap.Error(err))
+			}
+		}
+		return 0, 0
+	}
+
+	if checkpoint == nil {
+		services.Logger.Info("No setup checkpoint found - starting from the beginning")
+		return 0, 0
+	}
+
+	start := resumeIndex(users, assetConfigs, checkpoint)
+	if start == 0 {
+		services.Logger.Warn("Checkpointed user or asset no longer configured - starting from the beginning",
+			zap.String("user_id", checkpoint.UserId),
+			zap.String("asset", checkpoint.Asset))
+		return 0, 0
+	}
+
+	services.Logger.Info("Resuming setup from checkpoint",
+		zap.String("after_user_id", checkpoint.UserId),
+		zap.String("after_asset", checkpoint.Asset),
+		zap.Int("previously_processed", checkpoint.Processed),
+		zap.Time("checkpoint_time", checkpoint.UpdatedAt))
+	return start, checkpoint.Processed
+}
+
+func generateAddresses(ctx context.Context, services *common.Services, shutdown *common.Shutdown, resume bool) {
+	services.Logger.Info("Loading asset configuration")
 	assetConfigs, err := common.LoadAssetConfig("assets.yaml")
 	if err != nil {
-		zap.L().Fatal("Failed to load asset config", zap.Error(err))
+		services.Logger.Fatal("Failed to load asset config", zap.Error(err))
 	}
-	zap.L().Info("Asset configuration loaded", zap.Int("count", len(assetConfigs)))
+	assetConfigs = common.EnabledAssets(assetConfigs)
+	services.Logger.Info("Asset configuration loaded", zap.Int("count", len(assetConfigs)))
 
 	users, err := services.DbService.GetUsers(ctx)
 	if err != nil {
-		zap.L().Fatal("Failed to read users from database", zap.Error(err))
+		services.Logger.Fatal("Failed to read users from database", zap.Error(err))
 	}
 
+	start, processed := loadCheckpoint(ctx, services, resume, users, assetConfigs)
+
 	var totalAddresses, failedAddresses int
 	var failedAssets []string
 
-	for _, user := range users {
-		zap.L().Info("Processing user",
+	// Wallets are looked up in Prime once per asset rather than once per user
+	wallets := make(map[string]*models.Wallet)
+
+userLoop:
+	for u, user := range users {
+		if (u+1)*len(assetConfigs) <= start {
+			continue
+		}
+
+		services.Logger.Info("Processing user",
 			zap.String("id", user.Id),
 			zap.String("name", user.Name),
 			zap.String("email", user.Email))
 
-		for _, assetConfig := range assetConfigs {
-			err := processUserAsset(ctx, services, user, assetConfig)
+		for a, assetConfig := range assetConfigs {
+			if u*len(assetConfigs)+a < start {
+				continue
+			}
+
+			// Stop between addresses so that no address is created in Prime without being stored
+			if shutdown.Requested() {
+				break userLoop
+			}
+
+			err := processUserAsset(ctx, services, user, assetConfig, wallets)
 			if err != nil {
 				failedAddresses++
 				failedAssets = append(failedAssets, fmt.Sprintf("%s/%s", user.Name, assetConfig.Symbol))
-			} else {
-				totalAddresses++
+				continue
+			}
+			totalAddresses++
+
+			// The checkpoint only advances past pairs with no earlier failure, so --resume retries failures
+			if failedAddresses > 0 {
+				continue
+			}
+			processed++
+			checkpoint := models.CommandCheckpoint{
+				Command:   setupCheckpoint,
+				UserId:    user.Id,
+				Asset:     assetConfig.AssetNetwork(),
+				Processed: processed,
+			}
+			if wallet, ok := wallets[assetConfig.Symbol]; ok {
+				checkpoint.WalletId = wallet.Id
+			}
+			if err := services.DbService.SaveCheckpoint(ctx, checkpoint); err != nil {
+				services.Logger.Warn("Failed to save setup checkpoint", zap.Error(err))
 			}
 		}
 	}
 
 	// Log summary
+	if shutdown.Requested() {
+		services.Logger.Warn("Address generation interrupted - run setup with --resume to continue",
+			zap.Int("processed_total", processed),
+			zap.Int("users_total", len(users)),
+			zap.Int("total_addresses_created", totalAddresses),
+			zap.Int("failed_addresses", failedAddresses))
+		return
+	}
+
 	if failedAddresses > 0 {
-		zap.L().Warn("Address generation completed with some failures",
+		services.Logger.Warn("Address generation completed with some failures - run setup with --resume to retry them",
 			zap.Int("total_addresses_created", totalAddresses),
 			zap.Int("failed_addresses", failedAddresses),
 			zap.Strings("failed_user_assets", failedAssets))
-	} else {
-		zap.L().Info("Address generation completed successfully",
-			zap.Int("total_addresses_created", totalAddresses))
+		return
+	}
+
+	if err := services.DbService.ClearCheckpoint(ctx, setupCheckpoint); err != nil {
+		services.Logger.Warn("Failed to clear setup checkpoint", zap.Error(err))
+	}
+	services.Logger.Info("Address generation completed successfully",
+		zap.Int("total_addresses_created", totalAddresses))
+}
+
+// setupPlan lists the deposit addresses a setup run would create. It is written by --plan and
+// executed by --apply, so the changes made in Prime can be reviewed first.
+type setupPlan struct {
+	CreatedAt time.Time  `json:"created_at"`
+	Items     []planItem `json:"items"`
+	// Existing counts user/asset pairs that already have an address and need no change
+	Existing int `json:"existing"`
+}
+
+// planItem is one deposit address to create. An empty WalletId means the asset has no known wallet,
+// so apply looks one up in Prime and creates it if there is none.
+type planItem struct {
+	UserId    string `json:"user_id"`
+	UserEmail string `json:"user_email"`
+	Symbol    string `json:"symbol"`
+	Network   string `json:"network"`
+	WalletId  string `json:"wallet_id,omitempty"`
+}
+
+// buildPlan works out which user/asset pairs still need an address from the database alone, without calling Prime
+func buildPlan(ctx context.Context, services *common.Services, users []models.User, assetConfigs []models.AssetConfig) (*setupPlan, error) {
+	plan := &setupPlan{CreatedAt: time.Now().UTC()}
+
+	// Wallets already holding stored addresses are reused rather than looked up in Prime
+	hasAddress := make(map[string]bool)
+	walletIds := make(map[string]string)
+	for _, user := range users {
+		addresses, err := services.DbService.GetAllUserAddresses(ctx, user.Id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read addresses for user %s: %w", user.Id, err)
+		}
+		for _, address := range addresses {
+			hasAddress[user.Id+"/"+address.Asset+"-"+address.Network] = true
+			if address.WalletId != "" {
+				walletIds[address.Asset] = address.WalletId
+			}
+		}
+	}
+
+	for _, user := range users {
+		for _, assetConfig := range assetConfigs {
+			if hasAddress[user.Id+"/"+assetConfig.AssetNetwork()] {
+				plan.Existing++
+				continue
+			}
+			plan.Items = append(plan.Items, planItem{
+				UserId:    user.Id,
+				UserEmail: user.Email,
+				Symbol:    assetConfig.Symbol,
+				Network:   assetConfig.Network,
+				WalletId:  walletIds[assetConfig.Symbol],
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+// printPlan writes the plan for review
+func printPlan(plan *setupPlan) {
+	fmt.Printf("Setup plan: %d addresses to create, %d user/asset pairs already have one\n\n", len(plan.Items), plan.Existing)
+	if len(plan.Items) == 0 {
+		return
+	}
+
+	newWallets := make(map[string]bool)
+	fmt.Printf("%-38s %-30s %-20s %s\n", "USER", "EMAIL", "ASSET", "WALLET")
+	for _, item := range plan.Items {
+		wallet := item.WalletId
+		if wallet == "" {
+			wallet = "(existing Prime TRADING wallet or new)"
+			newWallets[item.Symbol] = true
+		}
+		fmt.Printf("%-38s %-30s %-20s %s\n", item.UserId, item.UserEmail, item.Symbol+"-"+item.Network, wallet)
+	}
+
+	if len(newWallets) > 0 {
+		symbols := make([]string, 0, len(newWallets))
+		for symbol := range newWallets {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		fmt.Printf("\nWallets looked up in Prime, and created if missing: %s\n", strings.Join(symbols, ", "))
+	}
+}
+
+// writePlan prints the plan and saves it for a later --apply
+func writePlan(ctx context.Context, services *common.Services, planFile string) {
+	assetConfigs, err := common.LoadAssetConfig("assets.yaml")
+	if err != nil {
+		services.Logger.Fatal("Failed to load asset config", zap.Error(err))
+	}
+	assetConfigs = common.EnabledAssets(assetConfigs)
+
+	users, err := services.DbService.GetUsers(ctx)
+	if err != nil {
+		services.Logger.Fatal("Failed to read users from database", zap.Error(err))
+	}
+
+	plan, err := buildPlan(ctx, services, users, assetConfigs)
+	if err != nil {
+		services.Logger.Fatal("Failed to build setup plan", zap.Error(err))
+	}
+	printPlan(plan)
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		services.Logger.Fatal("Failed to encode setup plan", zap.Error(err))
+	}
+	if err := os.WriteFile(planFile, data, 0o644); err != nil {
+		services.Logger.Fatal("Failed to write setup plan", zap.String("file", planFile), zap.Error(err))
+	}
+	fmt.Printf("\nPlan saved to %s - review it, then run setup with --apply to create the addresses\n", planFile)
+}
+
+// applyPlan creates the addresses listed in a saved plan. Pairs that gained an address since the plan
+// was written are skipped, so applying a plan twice creates nothing the second time.
+func applyPlan(ctx context.Context, services *common.Services, shutdown *common.Shutdown, planFile string) {
+	data, err := os.ReadFile(planFile)
+	if err != nil {
+		services.Logger.Fatal("Failed to read setup plan - run setup with --plan first", zap.String("file", planFile), zap.Error(err))
+	}
+	var plan setupPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		services.Logger.Fatal("Failed to parse setup plan", zap.String("file", planFile), zap.Error(err))
+	}
+
+	services.Logger.Info("Applying setup plan",
+		zap.String("file", planFile),
+		zap.Time("planned_at", plan.CreatedAt),
+		zap.Int("addresses", len(plan.Items)))
+
+	wallets := make(map[string]*models.Wallet)
+	var created, skipped, failed int
+	for _, item := range plan.Items {
+		// Stop between addresses so that no address is created in Prime without being stored
+		if shutdown.Requested() {
+			break
+		}
+
+		user, err := services.DbService.GetUserById(ctx, item.UserId)
+		if err != nil || user == nil {
+			services.Logger.Warn("Planned user not found - skipping", zap.String("user_id", item.UserId), zap.Error(err))
+			skipped++
+			continue
+		}
+		assetConfig := models.AssetConfig{Symbol: item.Symbol, Network: item.Network}
+
+		exists, err := checkExistingAddress(ctx, services, *user, assetConfig)
+		if err != nil {
+			failed++
+			continue
+		}
+		if exists {
+			skipped++
+			continue
+		}
+
+		wallet, ok := wallets[item.Symbol]
+		if !ok {
+			if item.WalletId != "" {
+				wallet = &models.Wallet{Id: item.WalletId}
+			} else if wallet, err = getOrCreateWallet(ctx, services, item.Symbol); err != nil {
+				failed++
+				continue
+			}
+			wallets[item.Symbol] = wallet
+		}
+
+		if err := createAndStoreAddress(ctx, services, *user, assetConfig, wallet); err != nil {
+			failed++
+			continue
+		}
+		created++
 	}
+
+	if shutdown.Requested() {
+		services.Logger.Warn("Plan apply interrupted - run setup with --apply again to continue",
+			zap.Int("addresses_created", created),
+			zap.Int("skipped", skipped),
+			zap.Int("failed", failed))
+		return
+	}
+	if failed > 0 {
+		services.Logger.Warn("Plan applied with some failures - run setup with --apply again to retry them",
+			zap.Int("addresses_created", created),
+			zap.Int("skipped", skipped),
+			zap.Int("failed", failed))
+		return
+	}
+	services.Logger.Info("Plan applied successfully",
+		zap.Int("addresses_created", created),
+		zap.Int("skipped", skipped))
 }
 
-func runInit(ctx context.Context, services *common.Services) {
-	zap.L().Info("Initializing database and generating addresses")
+func runInit(ctx context.Context, services *common.Services, shutdown *common.Shutdown, resume bool) {
+	services.Logger.Info("Initializing database and generating addresses")
 
-	zap.L().Info("Setting up SQLite database")
+	services.Logger.Info("Setting up SQLite database")
 
-	zap.L().Info("Generating addresses")
-	generateAddresses(ctx, services)
+	services.Logger.Info("Generating addresses")
+	generateAddresses(ctx, services, shutdown, resume)
+	if shutdown.Requested() {
+		return
+	}
 
-	zap.L().Info("Initialization complete")
+	services.Logger.Info("Initialization complete")
 }
 
 func main() {
 	ctx := context.Background()
 
-	_, loggerCleanup := common.InitializeLogger()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
 	defer loggerCleanup()
 
 	initFlag := flag.Bool("init", false, "Initialize the database")
+	resumeFlag := flag.Bool("resume", false, "Continue from where an interrupted or partially failed run stopped")
+	planFlag := flag.Bool("plan", false, "Print and save the addresses that would be created, without calling Prime")
+	applyFlag := flag.Bool("apply", false, "Create the addresses in a plan saved by --plan")
+	planFileFlag := flag.String("plan-file", "setup-plan.json", "File the plan is saved to and applied from")
+	common.RegisterLockFlags(flag.CommandLine)
 	flag.Parse()
 
+	if *planFlag && *applyFlag {
+		logger.Fatal("--plan and --apply cannot be used together")
+	}
+	if (*planFlag || *applyFlag) && (*resumeFlag || *initFlag) {
+		logger.Fatal("--plan and --apply cannot be combined with --resume or --init")
+	}
+
 	// Initialize services at top level
-	cfg, err := config.Load()
+	services, err := common.InitializeServices(ctx, cfg, logger)
 	if err != nil {
-		zap.L().Fatal("Failed to load config", zap.Error(err))
+		logger.Fatal("Failed to initialize services", zap.Error(err))
 	}
+	defer services.Close()
+
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
 
-	services, err := common.InitializeServices(ctx, cfg)
+	if *planFlag {
+		writePlan(ctx, services, *planFileFlag)
+		return
+	}
+
+	// An explicit --apply still creates the planned addresses
+	if cfg.Wallet.LazyAddresses && !*applyFlag {
+		logger.Info("Skipping address generation: deposit addresses are created lazily")
+		fmt.Println("LAZY_DEPOSIT_ADDRESSES is set - deposit addresses are created on each user's first deposit instructions request")
+		fmt.Println("Unset it to create every user's addresses now")
+		return
+	}
+
+	lock, err := common.AcquireCommandLock(ctx, services.DbService, "setup", logger)
 	if err != nil {
-		zap.L().Fatal("Failed to initialize services", zap.Error(err))
+		logger.Fatal("Cannot run setup now", zap.Error(err))
+	}
+	defer lock.Release()
+
+	if *applyFlag {
+		applyPlan(ctx, services, shutdown, *planFileFlag)
+		return
 	}
-	defer services.Close()
 
 	if *initFlag {
-		runInit(ctx, services)
+		runInit(ctx, services, shutdown, *resumeFlag)
 		return
 	}
 
-	generateAddresses(ctx, services)
+	generateAddresses(ctx, services, shutdown, *resumeFlag)
 }
@@ -0,0 +1,205 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/migrate"
+	"prime-send-receive-go/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  state export [--out FILE] [--scrub]")
+	fmt.Println("  state import --in FILE")
+}
+
+// configHash fingerprints the asset configuration, which must match for imported balances to make sense
+func configHash(cfg *models.Config) (string, error) {
+	data, err := os.ReadFile(cfg.Listener.AssetsFile)
+	if err != nil {
+		return "", fmt.Errorf("unable to read %s: %w", cfg.Listener.AssetsFile, err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func printSummary(title string, summary migrate.ArchiveSummary) {
+	common.PrintHeader(title, common.DefaultWidth)
+	for i, table := range migrate.Tables {
+		fmt.Printf("%s %-20s %10d rows\n", common.BoxPrefix(i == len(migrate.Tables)-1), table, summary.Counts[table])
+	}
+	fmt.Printf("\nBalances checksum:     %s\n", summary.Balances.Digest)
+	fmt.Printf("Transactions checksum: %s\n", summary.Transactions.Digest)
+}
+
+func exportState(ctx context.Context, cfg *models.Config, logger *zap.Logger, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	outFlag := fs.String("out", fmt.Sprintf("ledger-state-%s.jsonl.gz", time.Now().Format("20060102-150405")), "Archive file to write")
+	scrubFlag := fs.Bool("scrub", false, "Replace user names and emails with pseudonyms")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	hash, err := configHash(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Read-only, so exporting cannot change the source ledger
+	source, err := sql.Open("sqlite3", "file:"+cfg.Database.Path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer source.Close()
+	if err := source.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to open database %s: %w", cfg.Database.Path, err)
+	}
+
+	// Written next to the destination and renamed once complete, so a failed export leaves no partial archive
+	file, err := os.CreateTemp(filepath.Dir(*outFlag), ".ledger-state-*")
+	if err != nil {
+		return fmt.Errorf("failed to create archive: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	migrator := migrate.NewMigrator(source, nil, migrate.SQLite, 0, logger.Named("migrate"))
+	summary, err := migrator.Export(ctx, file, migrate.ExportOptions{ConfigHash: hash, Scrub: *scrubFlag})
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write archive: %w", closeErr)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(file.Name(), *outFlag); err != nil {
+		return fmt.Errorf("failed to save archive: %w", err)
+	}
+
+	printSummary("LEDGER STATE EXPORT", *summary)
+	scrubbed := "user names and emails included"
+	if *scrubFlag {
+		scrubbed = "user names and emails scrubbed"
+	}
+	common.PrintFooter(fmt.Sprintf("Wrote %s (%s)", *outFlag, scrubbed), common.DefaultWidth)
+	return nil
+}
+
+func importState(ctx context.Context, cfg *models.Config, logger *zap.Logger, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	inFlag := fs.String("in", "", "Archive file written by state export (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *inFlag == "" {
+		return fmt.Errorf("--in is required")
+	}
+
+	file, err := os.Open(*inFlag)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	// Opening the database creates and migrates its schema; the archive is restored into it
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize database: %w", err)
+	}
+	dbService.Close()
+
+	target, err := sql.Open("sqlite3", cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer target.Close()
+
+	migrator := migrate.NewMigrator(nil, target, migrate.SQLite, 0, logger.Named("migrate"))
+	result, err := migrator.Import(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	printSummary("LEDGER STATE IMPORT", result.Summary)
+	fmt.Printf("Exported at:           %s\n", result.Header.CreatedAt.Local().Format("2006-01-02 15:04:05"))
+	fmt.Printf("PII scrubbed:          %t\n", result.Header.Scrubbed)
+	if build := result.Header.Build; build != nil {
+		fmt.Printf("Exported by build:     %s (commit %s, built %s)\n", build.Version, build.Commit, build.BuildDate)
+	}
+
+	hash, err := configHash(cfg)
+	if err != nil {
+		return err
+	}
+	matches := hash == result.Header.ConfigHash
+	fmt.Printf("Asset config matches:  %s\n", common.StatusMark(matches))
+	if !matches {
+		logger.Warn("Asset configuration differs from the exporting environment - check enabled assets before starting services",
+			zap.String("assets_file", cfg.Listener.AssetsFile))
+	}
+
+	common.PrintFooter(fmt.Sprintf("Restored %s into %s", *inFlag, cfg.Database.Path), common.DefaultWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "export":
+		err = exportState(ctx, cfg, logger, args)
+	case "import":
+		err = importState(ctx, cfg, logger, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("State command failed", zap.String("command", command), zap.Error(err))
+	}
+}
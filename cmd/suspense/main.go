@@ -0,0 +1,178 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  suspense list [--status open|credited|returned|all]")
+	fmt.Println("  suspense credit --id ID --email EMAIL [--note NOTE]")
+	fmt.Println("  suspense return --id ID --reference PRIME_TX_ID [--note NOTE]")
+}
+
+func listEntries(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	statusFlag := fs.String("status", database.SuspenseStatusOpen, "Filter by status (open, credited, returned, all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	status := *statusFlag
+	if status == "all" {
+		status = ""
+	}
+
+	entries, err := dbService.ListSuspenseEntries(ctx, status)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("SUSPENSE ENTRIES", common.WideWidth)
+	for i, entry := range entries {
+		isLast := i == len(entries)-1
+		fmt.Printf("%s %s  %s %s received at %s address (status: %s)\n",
+			common.BoxPrefix(isLast),
+			entry.Id,
+			entry.Amount.String(),
+			entry.ReceivedAsset,
+			entry.ExpectedAsset,
+			entry.Status)
+		fmt.Printf("%s address: %s, owner: %s, prime tx: %s, received: %s\n",
+			common.BoxDetailPrefix(isLast),
+			entry.Address,
+			entry.AddressUserId,
+			entry.ExternalTransactionId,
+			entry.CreatedAt.Format("2006-01-02 15:04:05"))
+		if entry.Status != database.SuspenseStatusOpen {
+			fmt.Printf("%s resolved: user=%s reference=%s note=%s\n",
+				common.BoxDetailPrefix(isLast),
+				entry.ResolvedUserId,
+				entry.ResolutionReference,
+				entry.ResolutionNote)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d suspense entries", len(entries)), common.WideWidth)
+	return nil
+}
+
+func creditEntry(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("credit", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Suspense entry id (required)")
+	emailFlag := fs.String("email", "", "User to credit (required)")
+	noteFlag := fs.String("note", "", "Resolution note (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *emailFlag == "" {
+		return fmt.Errorf("both flags are required: --id, --email")
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := dbService.CreditSuspenseToUser(ctx, *idFlag, user.Id, *noteFlag); err != nil {
+		return err
+	}
+
+	entry, err := dbService.GetSuspenseEntry(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Credited %s %s from suspense to %s\n", entry.Amount.String(), entry.ReceivedAsset, user.Email)
+	return nil
+}
+
+func returnEntry(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("return", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Suspense entry id (required)")
+	referenceFlag := fs.String("reference", "", "Prime transaction id of the return transfer (required)")
+	noteFlag := fs.String("note", "", "Resolution note (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *referenceFlag == "" {
+		return fmt.Errorf("both flags are required: --id, --reference")
+	}
+
+	if err := dbService.ReturnSuspenseFunds(ctx, *idFlag, *referenceFlag, *noteFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recorded return of suspense entry %s (reference %s)\n", *idFlag, *referenceFlag)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		err = listEntries(ctx, dbService, args)
+	case "credit":
+		err = creditEntry(ctx, dbService, args)
+	case "return":
+		err = returnEntry(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Suspense command failed", zap.String("command", command), zap.Error(err))
+	}
+}
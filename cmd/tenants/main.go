@@ -0,0 +1,153 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  tenants create --id ID --name NAME [--portfolio-id PORTFOLIO]")
+	fmt.Println("  tenants list")
+	fmt.Println("  tenants assign --email EMAIL --tenant ID")
+}
+
+func createTenant(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Tenant id used with --tenant and TENANT_ID (required)")
+	nameFlag := fs.String("name", "", "Display name (required)")
+	portfolioFlag := fs.String("portfolio-id", "", "Prime portfolio holding the tenant's wallets (default portfolio if empty)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *nameFlag == "" {
+		return fmt.Errorf("--id and --name are required")
+	}
+
+	tenant, err := dbService.CreateTenant(ctx, *idFlag, *nameFlag, *portfolioFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Created tenant %s (%s)\n", tenant.Id, tenant.Name)
+	return nil
+}
+
+func listTenants(ctx context.Context, dbService *database.Service) error {
+	tenants, err := dbService.ListTenants(ctx)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("TENANTS", common.WideWidth)
+	for i, tenant := range tenants {
+		isLast := i == len(tenants)-1
+		portfolio := tenant.PortfolioId
+		if portfolio == "" {
+			portfolio = "default portfolio"
+		}
+		fmt.Printf("%s %s  %s (%s)\n",
+			common.BoxPrefix(isLast),
+			tenant.Id,
+			tenant.Name,
+			portfolio)
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d tenants", len(tenants)), common.WideWidth)
+	return nil
+}
+
+func assignUser(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("assign", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	emailFlag := fs.String("email", "", "User email (required)")
+	tenantFlag := fs.String("tenant", "", "Tenant id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *emailFlag == "" || *tenantFlag == "" {
+		return fmt.Errorf("--email and --tenant are required")
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return err
+	}
+
+	if err := dbService.AssignUserTenant(ctx, user.Id, *tenantFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Moved %s from tenant %s to %s with their addresses, balances and history\n", user.Email, user.TenantId, *tenantFlag)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	// Tenant administration works across tenants
+	cfg.Tenant.Id = ""
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "create":
+		err = createTenant(ctx, dbService, args)
+	case "list":
+		err = listTenants(ctx, dbService)
+	case "assign":
+		err = assignUser(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Tenant command failed", zap.String("command", command), zap.Error(err))
+	}
+}
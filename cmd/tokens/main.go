@@ -0,0 +1,176 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  tokens issue --email EMAIL [--label LABEL]")
+	fmt.Println("  tokens revoke --id ID")
+	fmt.Println("  tokens list [--email EMAIL]")
+}
+
+func issueToken(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("issue", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	emailFlag := fs.String("email", "", "Email of the user the token is scoped to (required)")
+	labelFlag := fs.String("label", "", "Label to identify the token (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *emailFlag == "" {
+		return fmt.Errorf("--email is required")
+	}
+
+	user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+	if err != nil {
+		return err
+	}
+
+	token, apiToken, err := dbService.IssueApiToken(ctx, user.Id, *labelFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Issued token %s for %s (%s)\n", apiToken.Id, user.Name, user.Email)
+	fmt.Printf("Token: %s\n", token)
+	fmt.Println("Store it now - only a hash is kept and it cannot be shown again.")
+	return nil
+}
+
+func revokeToken(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Token id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	if err := dbService.RevokeApiToken(ctx, *idFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Revoked token %s\n", *idFlag)
+	return nil
+}
+
+func listTokens(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	emailFlag := fs.String("email", "", "Only list tokens for this user (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	userId := ""
+	if *emailFlag != "" {
+		user, err := dbService.GetUserByEmail(ctx, *emailFlag)
+		if err != nil {
+			return err
+		}
+		userId = user.Id
+	}
+
+	tokens, err := dbService.ListApiTokens(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("API TOKENS", common.WideWidth)
+	for i, token := range tokens {
+		isLast := i == len(tokens)-1
+		status := "active"
+		if token.RevokedAt != nil {
+			status = "revoked " + token.RevokedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s %s  %s... user %s (%s)\n",
+			common.BoxPrefix(isLast),
+			token.Id,
+			token.Prefix,
+			token.UserId,
+			status)
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = token.LastUsedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%s label: %s, created: %s, last used: %s\n",
+			common.BoxDetailPrefix(isLast),
+			token.Label,
+			token.CreatedAt.Format("2006-01-02 15:04:05"),
+			lastUsed)
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d api tokens", len(tokens)), common.WideWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "issue":
+		err = issueToken(ctx, dbService, args)
+	case "revoke":
+		err = revokeToken(ctx, dbService, args)
+	case "list":
+		err = listTokens(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Token command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,188 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  transactions list [--status pending|confirmed|reversed|failed] [--user EMAIL|ID] [--asset ASSET] [--limit N]")
+	fmt.Println("  transactions history --id TRANSACTION_ID")
+	fmt.Println("  transactions set-status --id TRANSACTION_ID --status STATUS [--note NOTE]")
+}
+
+func listTransactions(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	statusFlag := fs.String("status", database.TransactionStatusPending, "Filter by status (pending, confirmed, reversed, failed, all)")
+	userFlag := fs.String("user", "", "Only this user, by email or user id")
+	assetFlag := fs.String("asset", "", "Only this asset")
+	limitFlag := fs.Int("limit", 50, "Maximum number of transactions to show (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	status := *statusFlag
+	if status == "all" {
+		status = ""
+	}
+	userId, err := common.ResolveUserId(ctx, dbService, *userFlag)
+	if err != nil {
+		return err
+	}
+
+	transactions, err := dbService.ListTransactionsByStatus(ctx, database.TransactionStatusFilter{
+		Status: status,
+		UserId: userId,
+		Asset:  *assetFlag,
+		Limit:  *limitFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("TRANSACTIONS", common.WideWidth)
+	for i, transaction := range transactions {
+		isLast := i == len(transactions)-1
+		fmt.Printf("%s %s  %s %s %s for %s (status: %s)\n",
+			common.BoxPrefix(isLast),
+			transaction.Id,
+			transaction.TransactionType,
+			transaction.Amount.String(),
+			transaction.Asset,
+			transaction.UserId,
+			transaction.Status)
+		fmt.Printf("%s external: %s, created: %s\n",
+			common.BoxDetailPrefix(isLast),
+			transaction.ExternalTransactionId,
+			transaction.CreatedAt.Format("2006-01-02 15:04:05"))
+		if transaction.ReversalOf != "" {
+			fmt.Printf("%s reverses %s\n", common.BoxDetailPrefix(isLast), transaction.ReversalOf)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d transactions", len(transactions)), common.WideWidth)
+	return nil
+}
+
+func showHistory(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Ledger transaction id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	events, err := dbService.ListTransactionStatusEvents(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("STATUS HISTORY "+*idFlag, common.WideWidth)
+	for i, event := range events {
+		isLast := i == len(events)-1
+		fmt.Printf("%s %s  %s %s %s",
+			common.BoxPrefix(isLast),
+			event.CreatedAt.Format("2006-01-02 15:04:05"),
+			event.FromStatus,
+			common.Arrow(),
+			event.ToStatus)
+		if event.Note != "" {
+			fmt.Printf(" note=%s", event.Note)
+		}
+		fmt.Println()
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d status changes", len(events)), common.WideWidth)
+	return nil
+}
+
+func setStatus(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("set-status", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Ledger transaction id (required)")
+	statusFlag := fs.String("status", "", "New status: confirmed, reversed or failed (required)")
+	noteFlag := fs.String("note", "", "Why the status changed (optional)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" || *statusFlag == "" {
+		return fmt.Errorf("--id and --status are required")
+	}
+
+	if err := dbService.UpdateTransactionStatus(ctx, *idFlag, *statusFlag, *noteFlag); err != nil {
+		return err
+	}
+
+	fmt.Printf("Transaction %s is now %s\n", *idFlag, *statusFlag)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		err = listTransactions(ctx, dbService, args)
+	case "history":
+		err = showHistory(ctx, dbService, args)
+	case "set-status":
+		err = setStatus(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Transactions command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,194 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/treasury"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+func printPositions(positions []treasury.Position) {
+	common.PrintHeader("INTRADAY TREASURY REPORT", common.WideWidth)
+	fmt.Printf("%-10s %18s %18s %18s %18s %18s\n",
+		"ASSET", "HOT WALLET", "VAULT", "LIABILITIES", "PENDING W/D", "NET EXPOSURE")
+	common.PrintSeparator("-", common.WideWidth)
+
+	actions := 0
+	for _, position := range positions {
+		fmt.Printf("%-10s %18s %18s %18s %18s %18s\n",
+			position.Asset,
+			position.HotBalance.String(),
+			position.VaultBalance.String(),
+			position.Liabilities.String(),
+			position.PendingWithdrawals.String(),
+			position.NetExposure.String())
+	}
+
+	fmt.Println("\nRecommendations:")
+	for _, position := range positions {
+		switch position.Recommendation {
+		case treasury.RecommendationTopUp:
+			actions++
+			fmt.Printf("  %-10s top up hot wallet by %s (vault holds %s)\n",
+				position.Asset, position.RecommendedAmount.String(), position.VaultBalance.String())
+		case treasury.RecommendationSweep:
+			actions++
+			fmt.Printf("  %-10s sweep %s from hot wallet to vault\n",
+				position.Asset, position.RecommendedAmount.String())
+		}
+	}
+	if actions == 0 {
+		fmt.Println("  All hot wallets within thresholds")
+	}
+
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d assets, %d actions recommended", len(positions), actions), common.WideWidth)
+}
+
+func printTopUps(topUps []models.TreasuryTopUp) {
+	common.PrintHeader("HOT WALLET TOP-UPS", common.WideWidth)
+	for i, topUp := range topUps {
+		isLast := i == len(topUps)-1
+		fmt.Printf("%s %s  %s %s vault %s -> wallet %s (status: %s)\n",
+			common.BoxPrefix(isLast),
+			topUp.CreatedAt.Format("2006-01-02 15:04:05"),
+			topUp.Amount.String(),
+			topUp.Asset,
+			topUp.VaultWalletId,
+			topUp.WalletId,
+			topUp.Status)
+		fmt.Printf("%s id: %s, prime activity: %s\n", common.BoxDetailPrefix(isLast), topUp.Id, topUp.ActivityId)
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d top-ups", len(topUps)), common.WideWidth)
+}
+
+func printLargestHolders(totals []models.AssetTotal, emails map[string]string) {
+	common.PrintHeader("LARGEST HOLDERS", common.WideWidth)
+	for _, total := range totals {
+		fmt.Printf("%s: %s across %d holders\n", total.Asset, total.TotalLiability.String(), total.Holders)
+		for i, holder := range total.LargestHolders {
+			share := decimal.Zero
+			if total.TotalLiability.IsPositive() {
+				share = holder.Balance.Div(total.TotalLiability).Mul(decimal.NewFromInt(100))
+			}
+			email := emails[holder.UserId]
+			if email == "" {
+				email = holder.UserId
+			}
+			fmt.Printf("%s %-30s %20s (%s%%)\n",
+				common.BoxPrefix(i == len(total.LargestHolders)-1),
+				email,
+				holder.Balance.String(),
+				share.StringFixed(1))
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d assets", len(totals)), common.WideWidth)
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	assetFlag := flag.String("asset", "", "Show a single asset (optional)")
+	topUpsFlag := flag.Bool("top-ups", false, "List recent automatic vault top-ups and exit")
+	holdersFlag := flag.Int("holders", 0, "Also list the N largest holders of each asset")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	if *topUpsFlag {
+		dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer dbService.Close()
+
+		topUps, err := dbService.ListTreasuryTopUps(ctx, 50)
+		if err != nil {
+			logger.Fatal("Failed to list top-ups", zap.Error(err))
+		}
+		printTopUps(topUps)
+		return
+	}
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	treasuryService := treasury.NewService(services.DbService, services.PrimeService, services.DefaultPortfolio.Id, cfg.Treasury, logger.Named("treasury"))
+	positions, err := treasuryService.Positions(ctx)
+	if err != nil {
+		logger.Fatal("Failed to build treasury positions", zap.Error(err))
+	}
+
+	if *assetFlag != "" {
+		var filtered []treasury.Position
+		for _, position := range positions {
+			if strings.EqualFold(position.Asset, *assetFlag) {
+				filtered = append(filtered, position)
+			}
+		}
+		positions = filtered
+	}
+
+	printPositions(positions)
+
+	if *holdersFlag > 0 {
+		totals, err := api.NewLedgerService(services.DbService, logger.Named("ledger")).GetAssetTotals(ctx, *holdersFlag)
+		if err != nil {
+			logger.Fatal("Failed to load asset totals", zap.Error(err))
+		}
+		if *assetFlag != "" {
+			var filtered []models.AssetTotal
+			for _, total := range totals {
+				if strings.EqualFold(total.Asset, *assetFlag) {
+					filtered = append(filtered, total)
+				}
+			}
+			totals = filtered
+		}
+
+		users, err := services.DbService.GetUsers(ctx)
+		if err != nil {
+			logger.Fatal("Failed to load users", zap.Error(err))
+		}
+		emails := make(map[string]string, len(users))
+		for _, user := range users {
+			emails[user.Id] = user.Email
+		}
+		printLargestHolders(totals, emails)
+	}
+}
@@ -0,0 +1,240 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"prime-send-receive-go/internal/app"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  tx raw --id ID    (Prime transaction id, withdrawal idempotency key, or ledger transaction id)")
+	fmt.Println("  tx ack --prime-tx-id ID --reason TEXT    (stop the listener retrying a Prime transaction)")
+	fmt.Println("  tx reprocess --prime-tx-id ID    (fetch a Prime transaction and apply it if the ledger is missing it)")
+	fmt.Println("  tx observed [--prime-tx-id ID] [--reason REASON] [--limit N]    (transactions the listener saw but did not apply)")
+}
+
+func showRaw(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("raw", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Transaction id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	record, err := dbService.GetPrimeTransaction(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("PRIME TRANSACTION", common.WideWidth)
+	fmt.Printf("%s %s  %s %s\n", common.BoxPrefix(false), record.Id, record.Type, record.Status)
+	fmt.Printf("%s wallet: %s, idempotency key: %s\n", common.BoxDetailPrefix(false), record.WalletId, record.IdempotencyKey)
+	fmt.Printf("%s first seen: %s, updated: %s\n",
+		common.BoxDetailPrefix(false),
+		record.FirstSeenAt.Format("2006-01-02 15:04:05"),
+		record.UpdatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, record.Payload, "", "  "); err != nil {
+		fmt.Println(string(record.Payload))
+		return nil
+	}
+	fmt.Println(pretty.String())
+	return nil
+}
+
+func showObserved(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("observed", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	primeTxIdFlag := fs.String("prime-tx-id", "", "Only this Prime transaction")
+	reasonFlag := fs.String("reason", "", "Only this skip reason (status, zero_amount, type, deposits_disabled, dust, unmatched, acknowledged)")
+	limitFlag := fs.Int("limit", 50, "Maximum observations to show (0 = all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	observations, err := dbService.ListTransactionObservations(ctx, database.TransactionObservationFilter{
+		PrimeTransactionId: strings.TrimSpace(*primeTxIdFlag),
+		Reason:             strings.TrimSpace(*reasonFlag),
+		Limit:              *limitFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("OBSERVED TRANSACTIONS", common.WideWidth)
+	for i, o := range observations {
+		isLast := i == len(observations)-1
+		fmt.Printf("%s %s  %s %s %s %s (reason: %s)\n",
+			common.BoxPrefix(isLast), o.PrimeTransactionId, o.Type, o.Status, o.Amount, o.Symbol, o.Reason)
+		if o.Detail != "" {
+			fmt.Printf("%s %s\n", common.BoxDetailPrefix(isLast), o.Detail)
+		}
+		fmt.Printf("%s wallet: %s, seen %d times, first: %s, last: %s, in ledger: %s\n",
+			common.BoxDetailPrefix(isLast),
+			o.WalletId,
+			o.SeenCount,
+			o.FirstSeenAt.Format("2006-01-02 15:04:05"),
+			o.LastSeenAt.Format("2006-01-02 15:04:05"),
+			common.StatusMark(o.Applied))
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d observed transactions", len(observations)), common.WideWidth)
+	return nil
+}
+
+func acknowledge(ctx context.Context, dbService *database.Service, args []string, logger *zap.Logger) error {
+	fs := flag.NewFlagSet("ack", flag.ExitOnError)
+	primeTxIdFlag := fs.String("prime-tx-id", "", "Prime transaction id (required)")
+	reasonFlag := fs.String("reason", "", "Why the transaction is skipped, kept as the audit record (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	primeTxId := strings.TrimSpace(*primeTxIdFlag)
+	reason := strings.TrimSpace(*reasonFlag)
+	if primeTxId == "" {
+		return fmt.Errorf("--prime-tx-id is required")
+	}
+	if reason == "" {
+		return fmt.Errorf("--reason is required")
+	}
+
+	ack, err := dbService.AcknowledgePrimeTransaction(ctx, primeTxId, reason)
+	if errors.Is(err, database.ErrAlreadyAcknowledged) {
+		fmt.Printf("%s was already acknowledged at %s: %s\n", ack.PrimeTransactionId,
+			ack.CreatedAt.Format("2006-01-02 15:04:05"), ack.Reason)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Prime transaction acknowledged",
+		zap.String("prime_transaction_id", ack.PrimeTransactionId),
+		zap.String("reason", ack.Reason))
+	fmt.Printf("%s Acknowledged %s - the listener will skip it\n", common.StatusMark(true), ack.PrimeTransactionId)
+	return nil
+}
+
+func reprocess(ctx context.Context, cfg *models.Config, services *common.Services, args []string) error {
+	fs := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	primeTxIdFlag := fs.String("prime-tx-id", "", "Prime transaction id (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	primeTxId := strings.TrimSpace(*primeTxIdFlag)
+	if primeTxId == "" {
+		return fmt.Errorf("--prime-tx-id is required")
+	}
+
+	tx, err := services.Custody.GetTransaction(ctx, services.DefaultPortfolio.Id, primeTxId)
+	if err != nil {
+		return err
+	}
+
+	// Marks go to the shared store when one is configured, so running listeners see the result
+	coordinator, err := coordination.NewStore(ctx, cfg.Coordination, services.Logger.Named("coordination"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize coordination store: %w", err)
+	}
+	defer coordinator.Close()
+
+	sendReceiveListener, _ := app.NewListener(app.Dependencies{Config: cfg, Services: services, Coordinator: coordinator})
+	if err := sendReceiveListener.LoadMonitoredWallets(ctx, cfg.Listener.AssetsFile); err != nil {
+		return err
+	}
+	if err := sendReceiveListener.Reprocess(ctx, *tx); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Reprocessed %s: %s %s %s %s\n", common.StatusMark(true), tx.Id, tx.Type, tx.Status, tx.Amount, tx.Symbol)
+	fmt.Println("Transactions already in the ledger are left unchanged; the log shows the outcome")
+	return nil
+}
+
+func main() {
+	ctx := database.WithTransactionSource(context.Background(), models.TransactionSourceCli)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "raw", "ack", "observed":
+		dbService, initErr := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if initErr != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(initErr))
+		}
+		defer dbService.Close()
+		switch command {
+		case "raw":
+			err = showRaw(ctx, dbService, args)
+		case "observed":
+			err = showObserved(ctx, dbService, args)
+		default:
+			err = acknowledge(ctx, dbService, args, logger)
+		}
+	case "reprocess":
+		services, initErr := common.InitializeServices(ctx, cfg, logger)
+		if initErr != nil {
+			logger.Fatal("Failed to initialize services", zap.Error(initErr))
+		}
+		defer services.Close()
+		err = reprocess(ctx, cfg, services, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Transaction command failed", zap.String("command", command), zap.Error(err))
+	}
+}
@@ -0,0 +1,209 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+
+	"go.uber.org/zap"
+)
+
+type verifyStats struct {
+	verified int
+	drifted  int
+	failed   int
+}
+
+// walletLookup caches Prime lookups so each wallet and wallet/network pair is fetched once
+type walletLookup struct {
+	services  *common.Services
+	wallets   map[string]*models.Wallet
+	addresses map[string][]models.DepositAddress
+}
+
+func newWalletLookup(services *common.Services) *walletLookup {
+	return &walletLookup{
+		services:  services,
+		wallets:   make(map[string]*models.Wallet),
+		addresses: make(map[string][]models.DepositAddress),
+	}
+}
+
+// wallet returns the Prime wallet, or nil if Prime does not know it
+func (l *walletLookup) wallet(ctx context.Context, walletId string) (*models.Wallet, error) {
+	if wallet, ok := l.wallets[walletId]; ok {
+		return wallet, nil
+	}
+
+	wallet, err := l.services.PrimeService.GetWallet(ctx, l.services.DefaultPortfolio.Id, walletId)
+	if errors.Is(err, prime.ErrWalletNotFound) {
+		l.wallets[walletId] = nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	l.wallets[walletId] = wallet
+	return wallet, nil
+}
+
+func (l *walletLookup) walletAddresses(ctx context.Context, walletId, network string) ([]models.DepositAddress, error) {
+	key := walletId + "/" + network
+	if addresses, ok := l.addresses[key]; ok {
+		return addresses, nil
+	}
+
+	addresses, err := l.services.PrimeService.ListWalletAddresses(ctx, l.services.DefaultPortfolio.Id, walletId, network)
+	if err != nil {
+		return nil, err
+	}
+
+	l.addresses[key] = addresses
+	return addresses, nil
+}
+
+func verifyAddress(ctx context.Context, lookup *walletLookup, addr models.Address) models.AddressVerification {
+	wallet, err := lookup.wallet(ctx, addr.WalletId)
+	if err != nil {
+		return models.AddressVerification{Address: addr, Status: models.AddressVerificationFailed, Detail: err.Error()}
+	}
+
+	var primeAddresses []models.DepositAddress
+	if wallet != nil {
+		primeAddresses, err = lookup.walletAddresses(ctx, addr.WalletId, addr.Network)
+		if err != nil {
+			return models.AddressVerification{Address: addr, Status: models.AddressVerificationFailed, Detail: err.Error()}
+		}
+	}
+
+	return prime.VerifyAddress(addr, wallet, primeAddresses)
+}
+
+func printResult(user common.UserInfo, result models.AddressVerification) {
+	assetNetwork := fmt.Sprintf("%s-%s", result.Address.Asset, result.Address.Network)
+	fmt.Printf("%-22s %-30s %-25s %s\n", result.Status, user.Email, assetNetwork, result.Address.Address)
+	if result.Detail != "" {
+		fmt.Printf("%-22s %s\n", "", result.Detail)
+	}
+}
+
+func verifyUser(ctx context.Context, lookup *walletLookup, user common.UserInfo, assetFilter string, stats *verifyStats) error {
+	addresses, err := lookup.services.DbService.GetAllUserAddresses(ctx, user.Id)
+	if err != nil {
+		return fmt.Errorf("failed to get addresses: %w", err)
+	}
+
+	for _, addr := range addresses {
+		if assetFilter != "" && !strings.EqualFold(addr.Asset, assetFilter) {
+			continue
+		}
+
+		result := verifyAddress(ctx, lookup, addr)
+		switch {
+		case result.Status == models.AddressVerified:
+			stats.verified++
+			continue
+		case result.Drifted():
+			stats.drifted++
+			lookup.services.Logger.Warn("Stored address drifted from Prime",
+				zap.String("user_id", user.Id),
+				zap.String("address_id", addr.Id),
+				zap.String("status", result.Status),
+				zap.String("detail", result.Detail))
+		default:
+			stats.failed++
+			lookup.services.Logger.Error("Unable to verify address",
+				zap.String("user_id", user.Id),
+				zap.String("address_id", addr.Id),
+				zap.String("detail", result.Detail))
+		}
+		printResult(user, result)
+	}
+
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	emailFlag := flag.String("email", "", "Only verify addresses of the user with this email (optional)")
+	assetFlag := flag.String("asset", "", "Only verify addresses for this asset symbol (optional)")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	logger.Info("Starting address verification")
+
+	services, err := common.InitializeServices(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize services", zap.Error(err))
+	}
+	defer services.Close()
+
+	users, err := common.InitializeUsers(ctx, services.DbService, *emailFlag, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize users", zap.Error(err))
+	}
+
+	common.PrintHeader("ADDRESS VERIFICATION REPORT", common.WideWidth)
+
+	lookup := newWalletLookup(services)
+	stats := verifyStats{}
+	for _, user := range users {
+		if err := verifyUser(ctx, lookup, user, *assetFlag, &stats); err != nil {
+			lookup.services.Logger.Error("Failed to verify user addresses",
+				zap.String("user_id", user.Id),
+				zap.Error(err))
+			stats.failed++
+		}
+	}
+
+	summary := fmt.Sprintf("SUMMARY: %d verified, %d drifted, %d could not be checked",
+		stats.verified, stats.drifted, stats.failed)
+	common.PrintFooter(summary, common.WideWidth)
+
+	logger.Info("Address verification completed",
+		zap.Int("verified", stats.verified),
+		zap.Int("drifted", stats.drifted),
+		zap.Int("failed", stats.failed))
+
+	// A non-zero exit lets scheduled runs alert on drift
+	if stats.drifted > 0 || stats.failed > 0 {
+		loggerCleanup()
+		services.Close()
+		os.Exit(1)
+	}
+}
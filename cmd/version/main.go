@@ -0,0 +1,60 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/buildinfo"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/database"
+)
+
+// versionReport is the build plus the database schema version it creates
+type versionReport struct {
+	buildinfo.Info
+	SchemaVersion int `json:"schema_version"`
+}
+
+func main() {
+	jsonFlag := flag.Bool("json", false, "Print the version as JSON")
+	common.RegisterOutputFlags(flag.CommandLine)
+	flag.Parse()
+
+	report := versionReport{Info: buildinfo.Get(), SchemaVersion: database.SchemaVersion}
+
+	if *jsonFlag {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatalf("Failed to encode version: %v", err)
+		}
+		return
+	}
+
+	common.PrintHeader("BUILD", common.DefaultWidth)
+	fmt.Printf("%s Version:        %s\n", common.BoxPrefix(false), report.Version)
+	fmt.Printf("%s Commit:         %s\n", common.BoxPrefix(false), report.Commit)
+	fmt.Printf("%s Build date:     %s\n", common.BoxPrefix(false), report.BuildDate)
+	fmt.Printf("%s Go:             %s\n", common.BoxPrefix(false), report.GoVersion)
+	fmt.Printf("%s Schema version: %d\n", common.BoxPrefix(true), report.SchemaVersion)
+	common.PrintFooter("prime-send-receive-go "+report.Version, common.DefaultWidth)
+}
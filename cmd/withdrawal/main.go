@@ -18,27 +18,35 @@ package main
 
 import (
 	"context"
-	"errors"
 	"flag"
 	"fmt"
+	"log"
 	"strings"
 
+	"prime-send-receive-go/internal/api"
 	"prime-send-receive-go/internal/common"
 	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
 	"prime-send-receive-go/internal/prime"
+	"prime-send-receive-go/internal/treasury"
 
-	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 )
 
 type withdrawalRequest struct {
-	email       string
-	asset       string
-	amount      decimal.Decimal
-	destination string
+	email           string
+	asset           string
+	amount          decimal.Decimal
+	destinationType string
+	destination     string
+	queue           bool
+	tenant          string
+	queueStatus     bool
+	batches         bool
+	capacity        bool
 }
 
 type assetInfo struct {
@@ -50,9 +58,33 @@ func parseAndValidateFlags() (*withdrawalRequest, error) {
 	emailFlag := flag.String("email", "", "User email (required)")
 	assetFlag := flag.String("asset", "", "Asset symbol (e.g., BTC, ETH) (required)")
 	amountFlag := flag.String("amount", "", "Amount to withdraw (required)")
-	destinationFlag := flag.String("destination", "", "Destination address (required)")
+	destinationFlag := flag.String("destination", "", "Destination address, payment method id, wallet id or counterparty id (required)")
+	destinationTypeFlag := flag.String("destination-type", prime.DestinationTypeAddress,
+		fmt.Sprintf("Destination type: %s", strings.Join(prime.DestinationTypes, ", ")))
+	tenantFlag := flag.String("tenant", "", "Tenant the user belongs to; also selects its Prime portfolio (default from TENANT_ID)")
+	queueFlag := flag.Bool("queue", false, "Queue the withdrawal for the background worker instead of calling Prime directly")
+	queueStatusFlag := flag.Bool("queue-status", false, "Show the withdrawal queue status and exit")
+	batchesFlag := flag.Bool("batches", false, "Show recent withdrawal batches with reconciliation and exit")
+	capacityFlag := flag.Bool("capacity", false, "Show how much of --asset (a symbol, e.g. BTC) the --email user can withdraw, then exit")
+	common.RegisterOutputFlags(flag.CommandLine)
+	common.RegisterLockFlags(flag.CommandLine)
 	flag.Parse()
 
+	if *queueStatusFlag {
+		return &withdrawalRequest{queueStatus: true}, nil
+	}
+
+	if *batchesFlag {
+		return &withdrawalRequest{batches: true}, nil
+	}
+
+	if *capacityFlag {
+		if *emailFlag == "" || *assetFlag == "" {
+			return nil, fmt.Errorf("--capacity requires --email and --asset")
+		}
+		return &withdrawalRequest{capacity: true, email: *emailFlag, asset: *assetFlag, tenant: *tenantFlag}, nil
+	}
+
 	if *emailFlag == "" || *assetFlag == "" || *amountFlag == "" || *destinationFlag == "" {
 		return nil, fmt.Errorf("all flags are required: --email, --asset, --amount, --destination")
 	}
@@ -66,11 +98,19 @@ func parseAndValidateFlags() (*withdrawalRequest, error) {
 		return nil, fmt.Errorf("amount must be greater than zero")
 	}
 
+	destinationType, err := prime.ParseDestinationType(*destinationTypeFlag)
+	if err != nil {
+		return nil, err
+	}
+
 	return &withdrawalRequest{
-		email:       *emailFlag,
-		asset:       *assetFlag,
-		amount:      amount,
-		destination: *destinationFlag,
+		email:           *emailFlag,
+		asset:           *assetFlag,
+		amount:          amount,
+		destinationType: destinationType,
+		destination:     *destinationFlag,
+		queue:           *queueFlag,
+		tenant:          *tenantFlag,
 	}, nil
 }
 
@@ -85,18 +125,33 @@ func parseAsset(assetStr string) (*assetInfo, error) {
 	}, nil
 }
 
+// checkWithdrawalsEnabled rejects assets whose withdrawals are disabled in assets.yaml
+func checkWithdrawalsEnabled(assetsFile string, asset *assetInfo) error {
+	assetConfigs, err := common.LoadAssetConfig(assetsFile)
+	if err != nil {
+		return fmt.Errorf("failed to load asset config: %w", err)
+	}
+
+	assetConfig, ok := common.FindAssetConfig(assetConfigs, asset.symbol, asset.network)
+	if ok && !assetConfig.WithdrawalsEnabled() {
+		return fmt.Errorf("withdrawals are disabled for %s in %s", assetConfig.AssetNetwork(), assetsFile)
+	}
+	return nil
+}
+
 func verifyBalance(ctx context.Context, services *common.Services, user *models.User, symbol string, amount decimal.Decimal) (decimal.Decimal, error) {
-	currentBalance, err := services.DbService.GetUserBalance(ctx, user.Id, symbol)
+	// Withdrawals may only spend the available balance; held deposits are excluded
+	currentBalance, err := services.DbService.GetAvailableBalance(ctx, user.Id, symbol)
 	if err != nil {
 		return decimal.Zero, fmt.Errorf("failed to get user balance: %w", err)
 	}
 
 	if currentBalance.LessThan(amount) {
-		return currentBalance, fmt.Errorf("insufficient balance: current=%s, requested=%s, shortfall=%s",
+		return currentBalance, fmt.Errorf("%w: available=%s, requested=%s, shortfall=%s", database.ErrInsufficientBalance,
 			currentBalance.String(), amount.String(), amount.Sub(currentBalance).String())
 	}
 
-	zap.L().Info("✅ Balance verification successful",
+	services.Logger.Info("✅ Balance verification successful",
 		zap.String("user", user.Email),
 		zap.String("current_balance", currentBalance.String()),
 		zap.String("withdrawal_amount", amount.String()),
@@ -118,103 +173,84 @@ func getWalletForAsset(ctx context.Context, services *common.Services, userId st
 	return addresses[0].WalletId, nil
 }
 
-func generateIdempotencyKey(userId string) string {
-	userIdSegments := strings.Split(userId, "-")
-	uuidSegments := strings.Split(uuid.New().String(), "-")
-	return userIdSegments[0] + "-" + strings.Join(uuidSegments[1:], "-")
-}
-
-func checkExistingWithdrawal(ctx context.Context, services *common.Services, userId, symbol, idempotencyKey string) (bool, error) {
-	existingTxs, err := services.DbService.GetTransactionHistory(ctx, userId, symbol, 1000, 0)
+func printQueueStatus(ctx context.Context, dbService *database.Service) error {
+	counts, err := dbService.CountQueuedWithdrawalsByStatus(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to check transaction history: %w", err)
+		return err
 	}
 
-	for _, tx := range existingTxs {
-		if tx.ExternalTransactionId == idempotencyKey && tx.TransactionType == "withdrawal" {
-			zap.L().Info("Idempotency key already used - returning existing withdrawal",
-				zap.String("idempotency_key", idempotencyKey),
-				zap.String("transaction_id", tx.Id),
-				zap.String("amount", tx.Amount.String()),
-				zap.Time("processed_at", tx.ProcessedAt))
-
-			fmt.Println("\n✅ Withdrawal already processed (idempotent)")
-			fmt.Printf("   Original transaction ID: %s\n", tx.Id)
-			fmt.Printf("   Amount: %s %s\n", tx.Amount.Neg().String(), symbol)
-			fmt.Printf("   Processed at: %s\n\n", tx.ProcessedAt.Format("2006-01-02 15:04:05"))
-
-			return true, nil
-		}
+	recent, err := dbService.ListQueuedWithdrawals(ctx, "", 20)
+	if err != nil {
+		return err
 	}
 
-	return false, nil
-}
-
-func reserveFunds(ctx context.Context, services *common.Services, userId, symbol string, amount decimal.Decimal, idempotencyKey string) error {
-	fmt.Println("🔄 Reserving funds (debiting local balance)...")
-	zap.L().Info("Debiting balance before withdrawal",
-		zap.String("user_id", userId),
-		zap.String("asset", symbol),
-		zap.String("amount", amount.String()),
-		zap.String("idempotency_key", idempotencyKey))
-
-	err := services.DbService.ProcessWithdrawal(ctx, userId, symbol, amount, idempotencyKey)
-	if err != nil {
-		if errors.Is(err, database.ErrConcurrentModification) {
-			return fmt.Errorf("balance was modified by another withdrawal - please retry")
+	common.PrintHeader("WITHDRAWAL QUEUE STATUS", common.WideWidth)
+	for _, status := range []string{
+		database.WithdrawalQueueStatusQueued,
+		database.WithdrawalQueueStatusProcessing,
+		database.WithdrawalQueueStatusSubmitted,
+		database.WithdrawalQueueStatusFailed,
+	} {
+		fmt.Printf("%-12s %d\n", status+":", counts[status])
+	}
+	common.PrintSeparator("=", common.WideWidth)
+
+	for i, w := range recent {
+		isLast := i == len(recent)-1
+		fmt.Printf("%s %-10s %s %s %s %s (attempts: %d)\n",
+			common.BoxPrefix(isLast), w.Status, w.Amount.String(), w.AssetNetwork, common.Arrow(), w.Destination, w.Attempts)
+		detail := common.BoxDetailPrefix(isLast)
+		fmt.Printf("%s   Queue ID: %s  Created: %s\n", detail, w.Id, w.CreatedAt.Format("2006-01-02 15:04:05"))
+		if w.ActivityId != "" {
+			fmt.Printf("%s   Activity ID: %s\n", detail, w.ActivityId)
 		}
-		if errors.Is(err, database.ErrDuplicateTransaction) {
-			return fmt.Errorf("withdrawal with this idempotency key is already being processed - please retry in a moment")
+		if w.LastError != "" {
+			fmt.Printf("%s   Last Error: %s\n", detail, w.LastError)
 		}
-		return fmt.Errorf("failed to debit balance: %w", err)
 	}
+	fmt.Println()
 
-	fmt.Println("Funds reserved - balance debited locally")
 	return nil
 }
 
-func executeWithdrawal(ctx context.Context, services *common.Services, req *withdrawalRequest, walletId, idempotencyKey string) error {
-	fmt.Println("Creating withdrawal via Prime API...")
-	zap.L().Info("Creating withdrawal",
-		zap.String("portfolio_id", services.DefaultPortfolio.Id),
-		zap.String("wallet_id", walletId),
-		zap.String("amount", req.amount.String()),
-		zap.String("destination", req.destination))
-
-	withdrawal, err := services.PrimeService.CreateWithdrawal(ctx, prime.CreateWithdrawalParams{
-		PortfolioId:        services.DefaultPortfolio.Id,
-		WalletId:           walletId,
-		DestinationAddress: req.destination,
-		Amount:             req.amount.String(),
-		Asset:              req.asset,
-		IdempotencyKey:     idempotencyKey,
-	})
+// printBatches lists recent withdrawal batches and checks each total against its individual withdrawals
+func printBatches(ctx context.Context, dbService *database.Service) error {
+	batches, err := dbService.ListWithdrawalBatches(ctx, 20)
 	if err != nil {
-		return fmt.Errorf("Prime API withdrawal failed: %w", err)
+		return err
 	}
 
-	fmt.Printf("✅ Withdrawal created successfully!\n")
-	fmt.Printf("   Activity ID: %s\n", withdrawal.ActivityId)
-	fmt.Printf("   Amount:      %s %s\n", withdrawal.Amount, withdrawal.Asset)
-	fmt.Printf("   Destination: %s\n\n", withdrawal.Destination)
-
-	return nil
-}
+	common.PrintHeader("WITHDRAWAL BATCHES", common.WideWidth)
+	mismatches := 0
+	for i, batch := range batches {
+		items, err := dbService.ListWithdrawalBatchItems(ctx, batch.Id)
+		if err != nil {
+			return err
+		}
 
-func rollbackWithdrawal(ctx context.Context, services *common.Services, userId, symbol string, amount decimal.Decimal, idempotencyKey string) error {
-	zap.L().Error("Prime API withdrawal failed - rolling back local debit",
-		zap.String("user_id", userId),
-		zap.String("asset", symbol),
-		zap.String("amount", amount.String()))
+		itemTotal := decimal.Zero
+		for _, item := range items {
+			itemTotal = itemTotal.Add(item.Amount)
+		}
 
-	fmt.Println("\n❌ Prime API withdrawal failed - rolling back...")
+		reconciled := common.StatusMark(true) + " reconciled"
+		if !itemTotal.Equal(batch.TotalAmount) || len(items) != batch.ItemCount {
+			reconciled = fmt.Sprintf("%s MISMATCH: %d withdrawals totalling %s", common.StatusMark(false), len(items), itemTotal.String())
+			mismatches++
+		}
 
-	err := services.DbService.ReverseWithdrawal(ctx, userId, symbol, amount, idempotencyKey)
-	if err != nil {
-		return fmt.Errorf("CRITICAL: Failed to rollback withdrawal - manual intervention required: %w", err)
+		isLast := i == len(batches)-1
+		fmt.Printf("%s %-10s %s %s %s %s (%d withdrawals)\n",
+			common.BoxPrefix(isLast), batch.Status, batch.TotalAmount.String(), batch.AssetNetwork, common.Arrow(), batch.Destination, batch.ItemCount)
+		detail := common.BoxDetailPrefix(isLast)
+		fmt.Printf("%s   Batch ID: %s  Created: %s\n", detail, batch.Id, batch.CreatedAt.Format("2006-01-02 15:04:05"))
+		if batch.ActivityId != "" {
+			fmt.Printf("%s   Activity ID: %s\n", detail, batch.ActivityId)
+		}
+		fmt.Printf("%s   %s\n", detail, reconciled)
 	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d batches, %d mismatches", len(batches), mismatches), common.WideWidth)
 
-	fmt.Println("✅ Local balance restored (rollback successful)")
 	return nil
 }
 
@@ -222,7 +258,7 @@ func printWithdrawalSummary(user *models.User, asset string, currentBalance, amo
 	common.PrintHeader("WITHDRAWAL REQUEST", common.DefaultWidth)
 	fmt.Printf("User:              %s (%s)\n", user.Name, user.Email)
 	fmt.Printf("Asset:             %s\n", asset)
-	fmt.Printf("Current Balance:   %s\n", currentBalance.String())
+	fmt.Printf("Available Balance: %s\n", currentBalance.String())
 	fmt.Printf("Withdrawal Amount: %s\n", amount.String())
 	fmt.Printf("Remaining Balance: %s\n", currentBalance.Sub(amount).String())
 	fmt.Printf("Destination:       %s\n", destination)
@@ -231,121 +267,212 @@ func printWithdrawalSummary(user *models.User, asset string, currentBalance, amo
 	fmt.Println()
 }
 
+func printWithdrawalCapacity(user *models.User, capacity *models.WithdrawalCapacity) {
+	common.PrintHeader("WITHDRAWAL CAPACITY", common.DefaultWidth)
+	fmt.Printf("User:              %s (%s)\n", user.Name, user.Email)
+	fmt.Printf("Asset:             %s\n", capacity.Asset)
+	fmt.Printf("Balance:           %s\n", capacity.Balance.String())
+	fmt.Printf("Held:              %s\n", capacity.Held.String())
+	fmt.Printf("Available:         %s\n", capacity.Available.String())
+	if capacity.DailyLimit != nil {
+		fmt.Printf("Daily Limit:       %s (%s remaining today)\n", capacity.DailyLimit.String(), capacity.DailyLimitRemaining.String())
+	} else {
+		fmt.Printf("Daily Limit:       none\n")
+	}
+	fmt.Printf("Withdrawable:      %s\n", capacity.Withdrawable.String())
+	for _, fee := range capacity.EstimatedFees {
+		fmt.Printf("Estimated Fee:     %s %s on %s (max %s, %d samples)\n",
+			fee.Average.String(), fee.FeeSymbol, fee.Network, fee.Max.String(), fee.Samples)
+	}
+	common.PrintSeparator("=", common.DefaultWidth)
+	fmt.Println()
+}
+
+func printWithdrawalResult(result *models.WithdrawalResult) {
+	switch result.Status {
+	case models.WithdrawalReplayed:
+		fmt.Println("✅ Withdrawal already processed (idempotent)")
+		fmt.Printf("   Idempotency Key: %s\n", result.IdempotencyKey)
+		fmt.Printf("   Amount:          %s %s\n\n", result.Amount.String(), result.Asset)
+	case models.WithdrawalQueued:
+		fmt.Printf("✅ Withdrawal queued successfully!\n")
+		fmt.Printf("   Queue ID:        %s\n", result.QueueId)
+		fmt.Printf("   Idempotency Key: %s\n", result.IdempotencyKey)
+		fmt.Printf("   Amount:          %s %s\n", result.Amount.String(), result.Asset)
+		fmt.Printf("   Destination:     %s (%s)\n", result.Destination, result.DestinationType)
+		fmt.Printf("   Available:       %s\n", result.AvailableBalance.String())
+		fmt.Printf("   Correlation ID:  %s\n\n", result.CorrelationId)
+		fmt.Println("The listener's withdrawal worker will submit it to Prime. Check progress with --queue-status")
+	default:
+		fmt.Printf("✅ Withdrawal created successfully!\n")
+		fmt.Printf("   Activity ID: %s\n", result.ActivityId)
+		fmt.Printf("   Amount:      %s %s\n", result.Amount.String(), result.Asset)
+		fmt.Printf("   Destination: %s (%s)\n", result.Destination, result.DestinationType)
+		fmt.Printf("   Available:   %s\n", result.AvailableBalance.String())
+		fmt.Printf("   Correlation: %s\n\n", result.CorrelationId)
+	}
+}
+
 func main() {
-	ctx := context.Background()
+	ctx := database.WithTransactionSource(context.Background(), models.TransactionSourceCli)
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
 
-	_, loggerCleanup := common.InitializeLogger()
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
 	defer loggerCleanup()
 
 	// Parse and validate command line flags
 	req, err := parseAndValidateFlags()
 	if err != nil {
-		zap.L().Fatal("Invalid flags", zap.Error(err))
+		logger.Fatal("Invalid flags", zap.Error(err))
+	}
+
+	if req.queueStatus {
+		dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer dbService.Close()
+
+		if err := printQueueStatus(ctx, dbService); err != nil {
+			logger.Fatal("Failed to read withdrawal queue", zap.Error(err))
+		}
+		return
+	}
+
+	if req.batches {
+		dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to initialize database", zap.Error(err))
+		}
+		defer dbService.Close()
+
+		if err := printBatches(ctx, dbService); err != nil {
+			logger.Fatal("Failed to read withdrawal batches", zap.Error(err))
+		}
+		return
 	}
 
-	zap.L().Info("Starting withdrawal process",
+	logger.Info("Starting withdrawal process",
 		zap.String("email", req.email),
 		zap.String("asset", req.asset),
 		zap.String("amount", req.amount.String()),
 		zap.String("destination", req.destination))
 
-	// Load configuration and initialize services
-	cfg, err := config.Load()
-	if err != nil {
-		zap.L().Fatal("Failed to load config", zap.Error(err))
+	if req.tenant != "" {
+		cfg.Tenant.Id = req.tenant
 	}
 
-	zap.L().Info("Initializing services")
-	services, err := common.InitializeServices(ctx, cfg)
+	logger.Info("Initializing services")
+	services, err := common.InitializeServices(ctx, cfg, logger)
 	if err != nil {
-		zap.L().Fatal("Failed to initialize services", zap.Error(err))
+		logger.Fatal("Failed to initialize services", zap.Error(err))
 	}
 	defer services.Close()
 
+	shutdown := common.NotifyShutdown(logger)
+	defer shutdown.Stop()
+
+	ledger := api.NewLedgerService(services.DbService, logger.Named("ledger"))
+	ledger.SetWithdrawalSubmitter(services.Custody, services.DefaultPortfolio.Id)
+	ledger.SetFeeEstimator(services.PrimeService, services.DefaultPortfolio.Id)
+	ledger.SetWithdrawalLimits(cfg.Api.WithdrawalDailyLimits)
+	ledger.SetWithdrawalStepHook(common.CrashDrillHook(cfg.WithdrawalQueue.CrashAt, logger))
+
 	// Find user by email
-	zap.L().Info("Looking up user by email", zap.String("email", req.email))
+	logger.Info("Looking up user by email", zap.String("email", req.email))
 	targetUser, err := services.DbService.GetUserByEmail(ctx, req.email)
 	if err != nil {
-		zap.L().Fatal("User not found", zap.String("email", req.email), zap.Error(err))
+		logger.Fatal("User not found", zap.String("email", req.email), zap.Error(err))
 	}
 
-	zap.L().Info("User found",
+	logger.Info("User found",
 		zap.String("user_id", targetUser.Id),
 		zap.String("user_name", targetUser.Name),
 		zap.String("user_email", targetUser.Email))
 
+	if req.capacity {
+		capacity, err := ledger.GetWithdrawalCapacity(ctx, targetUser.Id, req.asset)
+		if err != nil {
+			logger.Fatal("Failed to get withdrawal capacity", zap.Error(err))
+		}
+		printWithdrawalCapacity(targetUser, capacity)
+		return
+	}
+
+	lock, err := common.AcquireCommandLock(ctx, services.DbService, "withdrawal", logger)
+	if err != nil {
+		logger.Fatal("Cannot create withdrawal now", zap.Error(err))
+	}
+	defer lock.Release()
+
 	// Parse asset to extract symbol and network
 	asset, err := parseAsset(req.asset)
 	if err != nil {
-		zap.L().Fatal("Invalid asset format", zap.String("asset", req.asset), zap.Error(err))
+		logger.Fatal("Invalid asset format", zap.String("asset", req.asset), zap.Error(err))
+	}
+
+	if err := checkWithdrawalsEnabled(cfg.Listener.AssetsFile, asset); err != nil {
+		logger.Fatal("Withdrawal not allowed", zap.String("asset", req.asset), zap.Error(err))
 	}
 
 	// Verify balance
-	zap.L().Info("Checking user balance",
+	logger.Info("Checking user balance",
 		zap.String("user_id", targetUser.Id),
 		zap.String("symbol", asset.symbol))
 
 	currentBalance, err := verifyBalance(ctx, services, targetUser, asset.symbol, req.amount)
 	if err != nil {
-		zap.L().Fatal("Balance verification failed", zap.Error(err))
+		logger.Fatal("Balance verification failed", zap.Error(err))
 	}
 
 	// Print summary
 	printWithdrawalSummary(targetUser, req.asset, currentBalance, req.amount, req.destination)
 
-	// Get wallet ID
-	zap.L().Info("Looking up wallet ID for asset",
-		zap.String("asset", asset.symbol),
-		zap.String("network", asset.network))
+	// With auto top-up, a withdrawal the hot wallet cannot fund waits in the queue for the vault transfer
+	if cfg.Treasury.AutoTopUp && !req.queue {
+		walletId, err := getWalletForAsset(ctx, services, targetUser.Id, asset)
+		if err != nil {
+			logger.Fatal("Failed to get wallet", zap.Error(err))
+		}
 
-	walletId, err := getWalletForAsset(ctx, services, targetUser.Id, asset)
-	if err != nil {
-		zap.L().Fatal("Failed to get wallet", zap.Error(err))
+		treasuryService := treasury.NewService(services.DbService, services.PrimeService, services.DefaultPortfolio.Id, cfg.Treasury, logger.Named("treasury"))
+		ready, err := treasuryService.EnsureHotBalance(ctx, walletId, asset.symbol, req.amount)
+		if err != nil {
+			logger.Warn("Hot wallet balance check failed - submitting withdrawal directly", zap.Error(err))
+		} else if !ready {
+			fmt.Println("⏳ Hot wallet balance is insufficient - a vault top-up has been requested and the withdrawal will be queued")
+			req.queue = true
+		}
 	}
 
-	zap.L().Info("Found wallet for asset",
-		zap.String("wallet_id", walletId),
-		zap.String("asset", req.asset))
-
-	// Generate idempotency key
-	idempotencyKey := generateIdempotencyKey(targetUser.Id)
-	zap.L().Info("Generated idempotency key",
-		zap.String("user_id", targetUser.Id),
-		zap.String("idempotency_key", idempotencyKey))
-
-	// Check if withdrawal already exists (idempotent)
-	exists, err := checkExistingWithdrawal(ctx, services, targetUser.Id, asset.symbol, idempotencyKey)
-	if err != nil {
-		zap.L().Fatal("Failed to check existing withdrawal", zap.Error(err))
-	}
-	if exists {
-		zap.L().Info("Returning existing withdrawal (idempotent)",
-			zap.String("idempotency_key", idempotencyKey),
-			zap.String("user_id", targetUser.Id),
-			zap.String("asset", asset.symbol))
+	// From here the hold, Prime call and any release run to completion; a signal only stops us before the hold
+	if shutdown.Requested() {
+		logger.Warn("Shutdown requested before funds were reserved - no withdrawal created")
 		return
 	}
 
-	// Reserve funds locally
-	err = reserveFunds(ctx, services, targetUser.Id, asset.symbol, req.amount, idempotencyKey)
+	fmt.Println("🔄 Reserving funds and creating withdrawal...")
+	result, err := ledger.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+		User:            targetUser.Email,
+		Asset:           req.asset,
+		Amount:          req.amount,
+		DestinationType: req.destinationType,
+		Destination:     req.destination,
+		Queue:           req.queue,
+	})
 	if err != nil {
-		zap.L().Fatal("Failed to reserve funds", zap.Error(err))
+		logger.Fatal("Withdrawal failed", zap.Error(err))
 	}
 
-	fmt.Printf("   New balance: %s\n\n", currentBalance.Sub(req.amount).String())
-
-	// Execute withdrawal via Prime API
-	err = executeWithdrawal(ctx, services, req, walletId, idempotencyKey)
-	if err != nil {
-		// Rollback on failure
-		rollbackErr := rollbackWithdrawal(ctx, services, targetUser.Id, asset.symbol, req.amount, idempotencyKey)
-		if rollbackErr != nil {
-			zap.L().Fatal("CRITICAL: Rollback failed", zap.Error(rollbackErr))
-		}
-		zap.L().Fatal("Prime API withdrawal failed (local balance rolled back)", zap.Error(err))
-	}
+	printWithdrawalResult(result)
 
-	zap.L().Info("Withdrawal completed successfully",
+	logger.Info("Withdrawal completed successfully",
+		zap.String(correlation.Field, result.CorrelationId),
+		zap.String("status", result.Status),
 		zap.String("user_id", targetUser.Id),
 		zap.String("asset", asset.symbol),
 		zap.String("amount", req.amount.String()))
@@ -0,0 +1,181 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/config"
+	"prime-send-receive-go/internal/database"
+
+	"go.uber.org/zap"
+)
+
+func usage() {
+	fmt.Println("Usage:")
+	fmt.Println("  withdrawals list [--user EMAIL|ID] [--status STATUS] [--limit N]")
+	fmt.Println("  withdrawals show --id IDEMPOTENCY_KEY")
+}
+
+func listWithdrawals(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	userFlag := fs.String("user", "", "Only this user, by email or user id")
+	statusFlag := fs.String("status", "", "Filter by status (created, submitted, pending, completed, failed, cancelled)")
+	limitFlag := fs.Int("limit", 50, "Maximum number of withdrawals to show (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	userId, err := common.ResolveUserId(ctx, dbService, *userFlag)
+	if err != nil {
+		return err
+	}
+
+	requests, err := dbService.ListWithdrawalRequests(ctx, database.WithdrawalRequestFilter{
+		UserId: userId,
+		Status: *statusFlag,
+		Limit:  *limitFlag,
+	})
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("WITHDRAWALS", common.WideWidth)
+	for i, request := range requests {
+		isLast := i == len(requests)-1
+		fmt.Printf("%s %s  %s %s for %s (status: %s)\n",
+			common.BoxPrefix(isLast),
+			request.IdempotencyKey,
+			request.Amount.String(),
+			request.Asset,
+			request.UserId,
+			request.Status)
+		fmt.Printf("%s %s %s, created: %s, updated: %s\n",
+			common.BoxDetailPrefix(isLast),
+			common.Arrow(),
+			request.Destination,
+			request.CreatedAt.Format("2006-01-02 15:04:05"),
+			request.UpdatedAt.Format("2006-01-02 15:04:05"))
+		if request.PrimeStatus != "" || request.PrimeTransactionId != "" {
+			fmt.Printf("%s prime: %s %s\n",
+				common.BoxDetailPrefix(isLast),
+				request.PrimeStatus,
+				request.PrimeTransactionId)
+		}
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d withdrawals", len(requests)), common.WideWidth)
+	return nil
+}
+
+func showWithdrawal(ctx context.Context, dbService *database.Service, args []string) error {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	common.RegisterOutputFlags(fs)
+	idFlag := fs.String("id", "", "Idempotency key of the withdrawal (required)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *idFlag == "" {
+		return fmt.Errorf("--id is required")
+	}
+
+	request, err := dbService.GetWithdrawalRequest(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+	if request == nil {
+		return fmt.Errorf("no withdrawal with idempotency key %s", *idFlag)
+	}
+	events, err := dbService.ListWithdrawalRequestEvents(ctx, *idFlag)
+	if err != nil {
+		return err
+	}
+
+	common.PrintHeader("WITHDRAWAL "+request.IdempotencyKey, common.WideWidth)
+	fmt.Printf("User:          %s\n", request.UserId)
+	fmt.Printf("Amount:        %s %s\n", request.Amount.String(), request.Asset)
+	fmt.Printf("Destination:   %s\n", request.Destination)
+	fmt.Printf("Wallet:        %s\n", request.WalletId)
+	fmt.Printf("Status:        %s\n", request.Status)
+	fmt.Printf("Prime status:  %s\n", request.PrimeStatus)
+	fmt.Printf("Activity:      %s\n", request.ActivityId)
+	fmt.Printf("Prime tx:      %s\n", request.PrimeTransactionId)
+	fmt.Printf("Created:       %s\n", request.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Println()
+	for i, event := range events {
+		isLast := i == len(events)-1
+		fmt.Printf("%s %s  %s %s %s",
+			common.BoxPrefix(isLast),
+			event.CreatedAt.Format("2006-01-02 15:04:05"),
+			event.FromStatus,
+			common.Arrow(),
+			event.ToStatus)
+		if event.PrimeStatus != "" {
+			fmt.Printf(" (%s)", event.PrimeStatus)
+		}
+		if event.Note != "" {
+			fmt.Printf(" note=%s", event.Note)
+		}
+		fmt.Println()
+	}
+	common.PrintFooter(fmt.Sprintf("SUMMARY: %d status changes", len(events)), common.WideWidth)
+	return nil
+}
+
+func main() {
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	logger, loggerCleanup := common.InitializeLogger(cfg.Log)
+	defer loggerCleanup()
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	dbService, err := common.InitializeDatabaseOnly(ctx, cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer dbService.Close()
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "list":
+		err = listWithdrawals(ctx, dbService, args)
+	case "show":
+		err = showWithdrawal(ctx, dbService, args)
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		logger.Fatal("Withdrawals command failed", zap.String("command", command), zap.Error(err))
+	}
+}
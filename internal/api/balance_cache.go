@@ -0,0 +1,104 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"sync"
+
+	"github.com/shopspring/decimal"
+)
+
+// BalanceCacheStats are the cache counters exported as metrics
+type BalanceCacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+	Entries       int
+}
+
+type balanceCacheKey struct {
+	userId, asset string
+	available     bool
+}
+
+// BalanceCache keeps recently read balances in memory. Entries never expire on their own; they are
+// dropped when the ledger reports a change to the account, so a cached balance is never stale.
+type BalanceCache struct {
+	mu         sync.Mutex
+	entries    map[balanceCacheKey]decimal.Decimal
+	maxEntries int
+	// generation is bumped by every invalidation so that a read racing a ledger change is not cached
+	generation uint64
+	stats      BalanceCacheStats
+}
+
+// NewBalanceCache creates a cache holding at most maxEntries balances
+func NewBalanceCache(maxEntries int) *BalanceCache {
+	return &BalanceCache{
+		entries:    make(map[balanceCacheKey]decimal.Decimal),
+		maxEntries: maxEntries,
+	}
+}
+
+// lookup returns a cached balance, or the generation to pass to store after reading it from the database
+func (c *BalanceCache) lookup(key balanceCacheKey) (decimal.Decimal, uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if balance, ok := c.entries[key]; ok {
+		c.stats.Hits++
+		return balance, 0, true
+	}
+	c.stats.Misses++
+	return decimal.Zero, c.generation, false
+}
+
+// store caches a balance read from the database unless the ledger changed since lookup
+func (c *BalanceCache) store(key balanceCacheKey, balance decimal.Decimal, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	// Hot keys refill quickly, so a full cache is simply emptied rather than tracking recency
+	if len(c.entries) >= c.maxEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = balance
+}
+
+// Invalidate drops the cached balances of one account. It is registered as a ledger balance change handler.
+func (c *BalanceCache) Invalidate(userId, asset string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.stats.Invalidations++
+	delete(c.entries, balanceCacheKey{userId: userId, asset: asset})
+	delete(c.entries, balanceCacheKey{userId: userId, asset: asset, available: true})
+}
+
+// Stats returns the cache counters
+func (c *BalanceCache) Stats() BalanceCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.entries)
+	return stats
+}
@@ -0,0 +1,72 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBalanceCache_InvalidateDropsBothBalances(t *testing.T) {
+	cache := NewBalanceCache(10)
+	total := balanceCacheKey{userId: "user1", asset: "BTC"}
+	available := balanceCacheKey{userId: "user1", asset: "BTC", available: true}
+	other := balanceCacheKey{userId: "user2", asset: "BTC"}
+
+	for _, key := range []balanceCacheKey{total, available, other} {
+		_, generation, ok := cache.lookup(key)
+		if ok {
+			t.Fatalf("Expected miss for %+v", key)
+		}
+		cache.store(key, decimal.NewFromInt(1), generation)
+	}
+
+	if balance, _, ok := cache.lookup(total); !ok || !balance.Equal(decimal.NewFromInt(1)) {
+		t.Errorf("Expected cached balance 1, got %s (hit=%v)", balance, ok)
+	}
+
+	cache.Invalidate("user1", "BTC")
+	if _, _, ok := cache.lookup(total); ok {
+		t.Error("Expected total balance to be invalidated")
+	}
+	if _, _, ok := cache.lookup(available); ok {
+		t.Error("Expected available balance to be invalidated")
+	}
+	if _, _, ok := cache.lookup(other); !ok {
+		t.Error("Expected other user's balance to stay cached")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 5 || stats.Invalidations != 1 || stats.Entries != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestBalanceCache_SkipsReadRacingLedgerChange(t *testing.T) {
+	cache := NewBalanceCache(10)
+	key := balanceCacheKey{userId: "user1", asset: "BTC"}
+
+	_, generation, _ := cache.lookup(key)
+	// The ledger changes between the database read and storing its result
+	cache.Invalidate("user1", "BTC")
+	cache.store(key, decimal.NewFromInt(1), generation)
+
+	if _, _, ok := cache.lookup(key); ok {
+		t.Error("Expected balance read before the change not to be cached")
+	}
+}
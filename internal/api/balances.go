@@ -32,9 +32,11 @@ func (s *LedgerService) GetUserBalance(ctx context.Context, userId, asset string
 		return decimal.Zero, fmt.Errorf("user_id and asset are required")
 	}
 
-	balance, err := s.db.GetUserBalance(ctx, userId, asset)
+	balance, err := s.cachedBalance(balanceCacheKey{userId: userId, asset: asset}, func() (decimal.Decimal, error) {
+		return s.db.GetUserBalance(ctx, userId, asset)
+	})
 	if err != nil {
-		zap.L().Error("Failed to get user balance",
+		s.logger.Error("Failed to get user balance",
 			zap.String("user_id", userId),
 			zap.String("asset_network", asset),
 			zap.Error(err))
@@ -44,6 +46,44 @@ func (s *LedgerService) GetUserBalance(ctx context.Context, userId, asset string
 	return balance, nil
 }
 
+// GetAvailableBalance returns the part of a user's balance that may be withdrawn
+func (s *LedgerService) GetAvailableBalance(ctx context.Context, userId, asset string) (decimal.Decimal, error) {
+	if userId == "" || asset == "" {
+		return decimal.Zero, fmt.Errorf("user_id and asset are required")
+	}
+
+	available, err := s.cachedBalance(balanceCacheKey{userId: userId, asset: asset, available: true}, func() (decimal.Decimal, error) {
+		return s.db.GetAvailableBalance(ctx, userId, asset)
+	})
+	if err != nil {
+		s.logger.Error("Failed to get available balance",
+			zap.String("user_id", userId),
+			zap.String("asset_network", asset),
+			zap.Error(err))
+		return decimal.Zero, fmt.Errorf("failed to retrieve balance")
+	}
+
+	return available, nil
+}
+
+// cachedBalance returns a balance from the cache when one is configured, reading and caching it on a miss
+func (s *LedgerService) cachedBalance(key balanceCacheKey, read func() (decimal.Decimal, error)) (decimal.Decimal, error) {
+	if s.balanceCache == nil {
+		return read()
+	}
+
+	balance, generation, ok := s.balanceCache.lookup(key)
+	if ok {
+		return balance, nil
+	}
+	balance, err := read()
+	if err != nil {
+		return decimal.Zero, err
+	}
+	s.balanceCache.store(key, balance, generation)
+	return balance, nil
+}
+
 // GetUserBalances returns all non-zero balances for a user
 func (s *LedgerService) GetUserBalances(ctx context.Context, userId string) ([]models.UserBalance, error) {
 	if userId == "" {
@@ -52,23 +92,109 @@ func (s *LedgerService) GetUserBalances(ctx context.Context, userId string) ([]m
 
 	balances, err := s.db.GetAllUserBalances(ctx, userId)
 	if err != nil {
-		zap.L().Error("Failed to get user balances", zap.String("user_id", userId), zap.Error(err))
+		s.logger.Error("Failed to get user balances", zap.String("user_id", userId), zap.Error(err))
 		return nil, fmt.Errorf("failed to retrieve balances")
 	}
 
 	result := make([]models.UserBalance, len(balances))
 	for i, balance := range balances {
 		result[i] = models.UserBalance{
-			Asset:   balance.Asset,
-			Balance: balance.Balance,
+			Asset:     balance.Asset,
+			Balance:   balance.Balance,
+			Available: balance.Available,
+		}
+	}
+
+	return result, nil
+}
+
+// GetUserBalancesVersion returns a token that changes whenever any of the user's balances change, so
+// clients polling GetUserBalances can be told nothing changed without reading the balances
+func (s *LedgerService) GetUserBalancesVersion(ctx context.Context, userId string) (string, error) {
+	if userId == "" {
+		return "", fmt.Errorf("user_id is required")
+	}
+
+	version, err := s.db.GetUserBalancesVersion(ctx, userId)
+	if err != nil {
+		s.logger.Error("Failed to get balances version", zap.String("user_id", userId), zap.Error(err))
+		return "", fmt.Errorf("failed to retrieve balances")
+	}
+	return version, nil
+}
+
+// maxBulkBalanceUsers bounds one bulk balance request
+const maxBulkBalanceUsers = 10000
+
+// GetBalancesBulk returns the balance of one asset for many users in one query. Every requested
+// user is returned once, in request order, with zero balances for users without an account.
+func (s *LedgerService) GetBalancesBulk(ctx context.Context, userIds []string, asset string) ([]models.UserAssetBalance, error) {
+	if asset == "" {
+		return nil, fmt.Errorf("asset is required")
+	}
+	if len(userIds) == 0 {
+		return nil, fmt.Errorf("user_ids are required")
+	}
+	if len(userIds) > maxBulkBalanceUsers {
+		return nil, fmt.Errorf("at most %d user_ids per request", maxBulkBalanceUsers)
+	}
+
+	unique := make([]string, 0, len(userIds))
+	seen := make(map[string]bool, len(userIds))
+	for _, userId := range userIds {
+		if userId == "" {
+			return nil, fmt.Errorf("user_ids must not be empty")
+		}
+		if !seen[userId] {
+			seen[userId] = true
+			unique = append(unique, userId)
+		}
+	}
+
+	balances, err := s.db.GetBalancesBulk(ctx, unique, asset)
+	if err != nil {
+		s.logger.Error("Failed to get bulk balances",
+			zap.Int("users", len(unique)),
+			zap.String("asset_network", asset),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve balances")
+	}
+
+	byUser := make(map[string]models.AccountBalance, len(balances))
+	for _, balance := range balances {
+		byUser[balance.UserId] = balance
+	}
+
+	result := make([]models.UserAssetBalance, len(unique))
+	for i, userId := range unique {
+		result[i] = models.UserAssetBalance{UserId: userId, Balance: decimal.Zero, Available: decimal.Zero}
+		if balance, ok := byUser[userId]; ok {
+			result[i].Balance = balance.Balance
+			result[i].Available = balance.Available
 		}
 	}
 
 	return result, nil
 }
 
-// GetTransactionHistory returns paginated transaction history for a user and asset
-func (s *LedgerService) GetTransactionHistory(ctx context.Context, userId, asset string, limit, offset int) ([]models.TransactionRecord, error) {
+// GetAssetTotals returns per-asset liability totals, holder counts and up to topN largest holders
+func (s *LedgerService) GetAssetTotals(ctx context.Context, topN int) ([]models.AssetTotal, error) {
+	if topN < 0 || topN > 100 {
+		return nil, fmt.Errorf("top must be between 0 and 100")
+	}
+
+	totals, err := s.db.GetAssetTotals(ctx, topN)
+	if err != nil {
+		s.logger.Error("Failed to get asset totals", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve asset totals")
+	}
+
+	return totals, nil
+}
+
+// GetTransactionHistory returns a page of transaction history for a user and asset, newest first. before is
+// the id of the last transaction of the previous page, or empty for the first page.
+func (s *LedgerService) GetTransactionHistory(ctx context.Context, userId, asset string, limit int, before string) ([]models.TransactionRecord, error) {
 	if userId == "" || asset == "" {
 		return nil, fmt.Errorf("user_id and asset are required")
 	}
@@ -76,13 +202,10 @@ func (s *LedgerService) GetTransactionHistory(ctx context.Context, userId, asset
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
-	if offset < 0 {
-		offset = 0
-	}
 
-	transactions, err := s.db.GetTransactionHistory(ctx, userId, asset, limit, offset)
+	transactions, err := s.db.GetTransactionHistory(ctx, userId, asset, limit, before)
 	if err != nil {
-		zap.L().Error("Failed to get transaction history",
+		s.logger.Error("Failed to get transaction history",
 			zap.String("user_id", userId),
 			zap.String("asset_network", asset),
 			zap.Error(err))
@@ -102,5 +225,33 @@ func (s *LedgerService) GetTransactionHistory(ctx context.Context, userId, asset
 		}
 	}
 
+	// Links to reversals, re-debits and refunds explain opposite-signed entries; like receipts they are best effort
+	links, err := s.db.GetTransactionLinks(ctx, transactions)
+	if err != nil {
+		s.logger.Warn("Failed to load transaction links", zap.String("user_id", userId), zap.Error(err))
+	}
+	for i, tx := range transactions {
+		result[i].Links = links[tx.Id]
+	}
+
+	// Receipts are best effort; history is still useful without the on-chain links
+	externalIds := make([]string, 0, len(transactions))
+	for _, tx := range transactions {
+		if tx.ExternalTransactionId != "" {
+			externalIds = append(externalIds, tx.ExternalTransactionId)
+		}
+	}
+	receipts, err := s.db.GetTransactionReceipts(ctx, externalIds)
+	if err != nil {
+		s.logger.Warn("Failed to load transaction receipts", zap.String("user_id", userId), zap.Error(err))
+		return result, nil
+	}
+	for i, tx := range transactions {
+		if receipt, ok := receipts[tx.ExternalTransactionId]; ok {
+			result[i].TxHash = receipt.TxHash
+			result[i].ExplorerUrl = s.explorer.TxUrl(receipt.Network, receipt.TxHash)
+		}
+	}
+
 	return result, nil
 }
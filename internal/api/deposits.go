@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"strings"
 
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
 
@@ -30,8 +31,8 @@ import (
 )
 
 // ProcessDeposit handles incoming deposit notifications from Prime API
-func (s *LedgerService) ProcessDeposit(ctx context.Context, address, asset string, amount decimal.Decimal, externalTxId string) (*models.DepositResult, error) {
-	zap.L().Info("Processing deposit from Prime API",
+func (s *LedgerService) ProcessDeposit(ctx context.Context, address, asset string, amount decimal.Decimal, externalTxId string, source models.DepositSource, availability string) (*models.DepositResult, error) {
+	correlation.Logger(ctx, s.logger).Info("Processing deposit from Prime API",
 		zap.String("address", address),
 		zap.String("asset_network", asset),
 		zap.String("amount", amount.String()),
@@ -39,7 +40,7 @@ func (s *LedgerService) ProcessDeposit(ctx context.Context, address, asset strin
 
 	// Validate input
 	if address == "" || asset == "" || amount.LessThanOrEqual(decimal.Zero) || externalTxId == "" {
-		zap.L().Error("Invalid deposit parameters",
+		correlation.Logger(ctx, s.logger).Error("Invalid deposit parameters",
 			zap.String("address", address),
 			zap.String("asset_network", asset),
 			zap.String("amount", amount.String()),
@@ -51,22 +52,33 @@ func (s *LedgerService) ProcessDeposit(ctx context.Context, address, asset strin
 	}
 
 	// Process the deposit through subledger
-	err := s.db.ProcessDeposit(ctx, address, asset, amount, externalTxId)
+	err := s.db.ProcessDeposit(ctx, address, asset, amount, externalTxId, source, availability)
 	if err != nil {
 		if errors.Is(err, database.ErrDuplicateTransaction) {
-			zap.L().Info("Duplicate transaction detected in API service",
+			correlation.Logger(ctx, s.logger).Info("Duplicate transaction detected in API service",
 				zap.String("address", address),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
 				zap.String("external_tx_id", externalTxId))
-		} else if strings.Contains(err.Error(), "no user found for address") {
-			zap.L().Warn("Deposit to unrecognized address",
+		} else if errors.Is(err, database.ErrDepositSuspended) {
+			correlation.Logger(ctx, s.logger).Warn("Deposit asset mismatch - held in suspense",
+				zap.String("address", address),
+				zap.String("asset_network", asset),
+				zap.String("amount", amount.String()),
+				zap.String("external_tx_id", externalTxId))
+			return &models.DepositResult{
+				Success: false,
+				Error:   database.ErrDepositSuspended.Error(),
+				Err:     err,
+			}, nil
+		} else if errors.Is(err, database.ErrUserNotFound) {
+			correlation.Logger(ctx, s.logger).Warn("Deposit to unrecognized address",
 				zap.String("address", address),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
 				zap.String("external_tx_id", externalTxId))
 		} else {
-			zap.L().Error("Deposit processing failed",
+			correlation.Logger(ctx, s.logger).Error("Deposit processing failed",
 				zap.String("address", address),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
@@ -76,12 +88,13 @@ func (s *LedgerService) ProcessDeposit(ctx context.Context, address, asset strin
 		return &models.DepositResult{
 			Success: false,
 			Error:   err.Error(),
+			Err:     err,
 		}, nil
 	}
 
 	user, _, err := s.db.FindUserByAddress(ctx, address)
 	if err != nil || user == nil {
-		zap.L().Error("User lookup failed after deposit processing",
+		correlation.Logger(ctx, s.logger).Error("User lookup failed after deposit processing",
 			zap.String("address", address),
 			zap.Error(err))
 		return &models.DepositResult{
@@ -92,11 +105,11 @@ func (s *LedgerService) ProcessDeposit(ctx context.Context, address, asset strin
 
 	newBalance, err := s.db.GetUserBalance(ctx, user.Id, asset)
 	if err != nil {
-		zap.L().Error("Failed to get updated balance", zap.Error(err))
+		correlation.Logger(ctx, s.logger).Error("Failed to get updated balance", zap.Error(err))
 		newBalance = decimal.Zero
 	}
 
-	zap.L().Info("Deposit processed successfully",
+	correlation.Logger(ctx, s.logger).Info("Deposit processed successfully",
 		zap.String("user_id", user.Id),
 		zap.String("user_name", user.Name),
 		zap.String("asset_network", asset),
@@ -119,3 +132,135 @@ func (s *LedgerService) CreateDepositAddress(ctx context.Context, userId, asset,
 	}
 	return "", fmt.Errorf("address generation requires Prime API integration")
 }
+
+// SetAddressLabel tags a user's deposit address with its purpose, e.g. "primary" or "legacy"
+func (s *LedgerService) SetAddressLabel(ctx context.Context, address, label string) error {
+	if address == "" {
+		return fmt.Errorf("address is required")
+	}
+	return s.db.SetAddressLabel(ctx, address, label)
+}
+
+// GetDepositInstructions returns how a user deposits an asset: the address, memo or tag when the network
+// needs one, minimum deposit, warnings and when the deposit is credited, one entry per network the user has
+// an address on. network narrows the result to one network. Assets disabled in assets.yaml are left out.
+// With lazy addresses enabled, addresses missing for enabled networks are created first.
+func (s *LedgerService) GetDepositInstructions(ctx context.Context, userId, symbol, network string) ([]models.DepositInstructions, error) {
+	if userId == "" || symbol == "" {
+		return nil, fmt.Errorf("user_id and asset are required")
+	}
+
+	if _, err := s.db.GetUserById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	if s.lazyAddresses && s.provisioner != nil {
+		if err := s.provisionMissing(ctx, userId, symbol, network); err != nil {
+			return nil, err
+		}
+	}
+
+	addresses, err := s.db.FilterUserAddresses(ctx, userId, strings.ToUpper(symbol), network)
+	if err != nil {
+		s.logger.Error("Failed to get deposit addresses",
+			zap.String("user_id", userId),
+			zap.String("asset", symbol),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve deposit addresses")
+	}
+
+	instructions := make([]models.DepositInstructions, 0, len(addresses))
+	seen := make(map[string]bool)
+	for _, addr := range addresses {
+		// Addresses are newest first; older addresses on the same network still credit but are not handed out
+		if seen[addr.Network] {
+			continue
+		}
+		seen[addr.Network] = true
+
+		asset, configured := s.findAssetConfig(addr.Asset, addr.Network)
+		if configured && !asset.IsEnabled() {
+			continue
+		}
+		instructions = append(instructions, s.depositInstructions(addr, asset, configured))
+	}
+
+	return instructions, nil
+}
+
+func (s *LedgerService) findAssetConfig(symbol, network string) (models.AssetConfig, bool) {
+	for _, asset := range s.assets {
+		if asset.Symbol == symbol && asset.Network == network {
+			return asset, true
+		}
+	}
+	return models.AssetConfig{}, false
+}
+
+func (s *LedgerService) depositInstructions(addr models.Address, asset models.AssetConfig, configured bool) models.DepositInstructions {
+	availability := s.defaultAvailability
+	if availability == "" {
+		availability = models.AvailabilityImmediate
+	}
+
+	result := models.DepositInstructions{
+		Asset:           addr.Asset,
+		Network:         addr.Network,
+		Address:         addr.Address,
+		DepositsEnabled: true,
+		CreditStatus:    models.DefaultDepositCreditStatus,
+		Availability:    availability,
+	}
+	if configured {
+		result.DepositsEnabled = asset.DepositsEnabled()
+		result.CreditStatus = asset.CreditStatus()
+		result.Availability = asset.FundsAvailability(availability)
+		if asset.Listener.DustThreshold.IsPositive() {
+			minimum := asset.Listener.DustThreshold
+			result.MinimumDeposit = &minimum
+		}
+		if asset.Listener.MemoRequired {
+			result.MemoRequired = true
+			result.Memo = addr.AccountIdentifier
+		}
+	}
+	result.ConfirmationPolicy = confirmationPolicy(result.CreditStatus, result.Availability)
+
+	result.Warnings = []string{fmt.Sprintf("Send only %s on the %s network to this address. Other assets or networks may be lost.", addr.Asset, addr.Network)}
+	if result.MemoRequired {
+		if result.Memo == "" {
+			result.Warnings = append(result.Warnings, "This network requires a memo, but none is recorded for this address. Do not deposit until one is issued.")
+		} else {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Include memo %s with every deposit. Deposits without it cannot be credited automatically.", result.Memo))
+		}
+	}
+	if result.MinimumDeposit != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Deposits below %s %s are not credited.", result.MinimumDeposit.String(), addr.Asset))
+	}
+	if !result.DepositsEnabled {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Deposits of %s on %s are paused. Deposits sent now are credited only after they resume.", addr.Asset, addr.Network))
+	}
+
+	return result
+}
+
+// confirmationPolicy describes when a deposit is credited and when it can be spent
+func confirmationPolicy(creditStatus, availability string) string {
+	var credited string
+	switch creditStatus {
+	case "TRANSACTION_IMPORT_PENDING":
+		credited = "Credited as soon as Prime detects the deposit"
+	case "TRANSACTION_DONE":
+		credited = "Credited when Prime completes the deposit"
+	default:
+		credited = "Credited once Prime has confirmed the deposit on chain"
+	}
+
+	switch availability {
+	case models.AvailabilityDone:
+		return credited + ", available to withdraw when Prime completes it"
+	case models.AvailabilityReview:
+		return credited + ", available to withdraw after a manual review"
+	}
+	return credited + " and available to withdraw immediately"
+}
@@ -0,0 +1,85 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGetDepositInstructions(t *testing.T) {
+	ledger, _, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	_, err := db.StoreAddress(ctx, database.StoreAddressParams{
+		UserId:            "user-1",
+		Asset:             "XRP",
+		Network:           "ripple-mainnet",
+		Address:           "rPrimeDeposit",
+		WalletId:          "wallet-2",
+		AccountIdentifier: "123456",
+	})
+	if err != nil {
+		t.Fatalf("Failed to store address: %v", err)
+	}
+
+	disabled := false
+	ledger.SetAssetConfigs([]models.AssetConfig{
+		{Symbol: "ETH", Network: "ethereum-mainnet", Listener: models.AssetListenerConfig{
+			CreditStatus:  "TRANSACTION_DONE",
+			DustThreshold: decimal.RequireFromString("0.001"),
+		}},
+		{Symbol: "XRP", Network: "ripple-mainnet", Listener: models.AssetListenerConfig{
+			MemoRequired:    true,
+			DepositsEnabled: &disabled,
+		}},
+	}, models.AvailabilityReview)
+
+	instructions, err := ledger.GetDepositInstructions(ctx, "user-1", "eth", "")
+	if err != nil {
+		t.Fatalf("GetDepositInstructions failed: %v", err)
+	}
+	if len(instructions) != 1 {
+		t.Fatalf("Expected one network, got %+v", instructions)
+	}
+	eth := instructions[0]
+	if eth.Address != "0xdeposit" || eth.MemoRequired || eth.Memo != "" || eth.MinimumDeposit == nil || !eth.MinimumDeposit.Equal(decimal.RequireFromString("0.001")) {
+		t.Errorf("Unexpected ETH instructions: %+v", eth)
+	}
+	if eth.CreditStatus != "TRANSACTION_DONE" || eth.Availability != models.AvailabilityReview || !strings.Contains(eth.ConfirmationPolicy, "manual review") {
+		t.Errorf("Expected the asset's credit status and the default availability, got %+v", eth)
+	}
+
+	instructions, err = ledger.GetDepositInstructions(ctx, "user-1", "XRP", "ripple-mainnet")
+	if err != nil || len(instructions) != 1 {
+		t.Fatalf("Expected XRP instructions, got %+v (%v)", instructions, err)
+	}
+	xrp := instructions[0]
+	if !xrp.MemoRequired || xrp.Memo != "123456" || xrp.DepositsEnabled || xrp.MinimumDeposit != nil || len(xrp.Warnings) != 3 {
+		t.Errorf("Expected a memo and a paused deposits warning, got %+v", xrp)
+	}
+
+	if _, err := ledger.GetDepositInstructions(ctx, "unknown", "ETH", ""); err == nil {
+		t.Error("Expected an unknown user to be rejected")
+	}
+}
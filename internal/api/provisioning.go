@@ -0,0 +1,187 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ErrAssetNotProvisionable is returned by ProvisionAsset for assets missing from assets.yaml or disabled there
+var ErrAssetNotProvisionable = errors.New("asset is not enabled in assets.yaml")
+
+// ProvisionAsset gives one user a deposit address for one asset on demand, e.g. when they first ask to
+// deposit it, instead of cmd/setup provisioning every configured asset up front. user is the user's id
+// or email address. An existing address is returned unchanged, so calling it again is safe. The asset's
+// trading wallet is created when the portfolio has none.
+func (s *LedgerService) ProvisionAsset(ctx context.Context, user, symbol, network string) (*models.ProvisionResult, error) {
+	if user == "" || symbol == "" || network == "" {
+		return nil, fmt.Errorf("user, symbol and network are required")
+	}
+	if s.provisioner == nil {
+		return nil, fmt.Errorf("address provisioning is not configured")
+	}
+	if asset, ok := s.findAssetConfig(symbol, network); !ok || !asset.IsEnabled() {
+		return nil, fmt.Errorf("%w: %s-%s", ErrAssetNotProvisionable, symbol, network)
+	}
+
+	owner, err := s.lookupUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests for other users or assets proceed while this one waits on Prime
+	unlock := s.addressLocks.lock(owner.Id + "/" + symbol + "/" + network)
+	defer unlock()
+
+	existing, err := s.db.GetAddresses(ctx, owner.Id, symbol, network)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check existing addresses: %w", err)
+	}
+	if len(existing) > 0 {
+		return &models.ProvisionResult{
+			UserId:   owner.Id,
+			Asset:    symbol,
+			Network:  network,
+			Address:  existing[0].Address,
+			WalletId: existing[0].WalletId,
+		}, nil
+	}
+
+	wallet, walletCreated, err := s.tradingWallet(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	depositAddress, err := s.provisioner.CreateDepositAddress(ctx, s.portfolioId, wallet.Id, symbol, network)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create deposit address: %w", err)
+	}
+	if _, err := s.db.StoreAddress(ctx, database.StoreAddressParams{
+		UserId:            owner.Id,
+		Asset:             symbol,
+		Network:           network,
+		Address:           depositAddress.Address,
+		WalletId:          wallet.Id,
+		AccountIdentifier: depositAddress.Id,
+	}); err != nil {
+		return nil, fmt.Errorf("unable to store deposit address: %w", err)
+	}
+
+	s.logger.Info("Provisioned deposit address",
+		zap.String("user_id", owner.Id),
+		zap.String("asset", symbol),
+		zap.String("network", network),
+		zap.String("wallet_id", wallet.Id),
+		zap.Bool("wallet_created", walletCreated))
+
+	return &models.ProvisionResult{
+		UserId:         owner.Id,
+		Asset:          symbol,
+		Network:        network,
+		Address:        depositAddress.Address,
+		WalletId:       wallet.Id,
+		AddressCreated: true,
+		WalletCreated:  walletCreated,
+	}, nil
+}
+
+// tradingWallet returns the deployment's trading wallet for an asset, creating it when there is none
+func (s *LedgerService) tradingWallet(ctx context.Context, symbol string) (*models.Wallet, bool, error) {
+	// Users provisioning the same asset at once must not create two wallets
+	unlock := s.walletLocks.lock(symbol)
+	defer unlock()
+
+	wallets, err := s.provisioner.ListWallets(ctx, s.portfolioId, "TRADING", []string{symbol})
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to list wallets: %w", err)
+	}
+	if wallet := common.SelectTradingWallet(s.walletConfig, wallets, symbol); wallet != nil {
+		return wallet, false, nil
+	}
+
+	wallet, err := s.provisioner.CreateWallet(ctx, s.portfolioId, common.WalletName(s.walletConfig, symbol), symbol, "TRADING")
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to create wallet: %w", err)
+	}
+	s.logger.Warn("Created trading wallet; restart the listener to monitor it",
+		zap.String("asset", symbol),
+		zap.String("wallet_id", wallet.Id),
+		zap.String("wallet_name", wallet.Name))
+	return wallet, true, nil
+}
+
+// provisionMissing creates the user's missing deposit addresses for the enabled networks of an asset, or
+// for one network when network is set, so that lazy deposit instructions have an address to hand out
+func (s *LedgerService) provisionMissing(ctx context.Context, userId, symbol, network string) error {
+	for _, asset := range s.assets {
+		if !strings.EqualFold(asset.Symbol, symbol) || (network != "" && asset.Network != network) || !asset.IsEnabled() {
+			continue
+		}
+		if _, err := s.ProvisionAsset(ctx, userId, asset.Symbol, asset.Network); err != nil {
+			return fmt.Errorf("unable to provision %s-%s deposit address: %w", asset.Symbol, asset.Network, err)
+		}
+	}
+	return nil
+}
+
+// keyedMutex serializes callers that share a key while callers with different keys run concurrently
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lock blocks until key is free and returns the function that releases it
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = make(map[string]*keyedLock)
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.waiters++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		// Forget keys nobody holds so the map does not grow with every user and asset
+		l.waiters--
+		if l.waiters == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
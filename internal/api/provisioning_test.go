@@ -0,0 +1,177 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+)
+
+// fakeProvisioner holds a portfolio's trading wallets and hands out numbered deposit addresses
+type fakeProvisioner struct {
+	mu        sync.Mutex
+	wallets   []models.Wallet
+	addresses int
+}
+
+func (f *fakeProvisioner) ListWallets(_ context.Context, _, _ string, symbols []string) ([]models.Wallet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var result []models.Wallet
+	for _, wallet := range f.wallets {
+		if wallet.Symbol == symbols[0] {
+			result = append(result, wallet)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeProvisioner) CreateWallet(_ context.Context, _, name, symbol, walletType string) (*models.Wallet, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	wallet := models.Wallet{Id: fmt.Sprintf("wallet-%s", symbol), Name: name, Symbol: symbol, Type: walletType}
+	f.wallets = append(f.wallets, wallet)
+	return &wallet, nil
+}
+
+func (f *fakeProvisioner) CreateDepositAddress(_ context.Context, _, walletId, asset, network string) (*models.DepositAddress, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.addresses++
+	return &models.DepositAddress{Id: fmt.Sprintf("account-%d", f.addresses), Address: fmt.Sprintf("%s-address-%d", walletId, f.addresses), Asset: asset, Network: network}, nil
+}
+
+func TestProvisionAsset(t *testing.T) {
+	ledger, _, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+	disabled := false
+	ledger.SetAssetConfigs([]models.AssetConfig{
+		{Symbol: "ETH", Network: "ethereum-mainnet"},
+		{Symbol: "SOL", Network: "solana-mainnet"},
+		{Symbol: "BTC", Network: "bitcoin-mainnet", Listener: models.AssetListenerConfig{Enabled: &disabled}},
+	}, "")
+
+	_, err := ledger.ProvisionAsset(ctx, "alice@example.com", "SOL", "solana-mainnet")
+	if err == nil {
+		t.Fatal("Expected an error before a provisioner is configured")
+	}
+
+	provisioner := &fakeProvisioner{}
+	ledger.SetAddressProvisioner(provisioner, "portfolio-1", models.WalletConfig{})
+
+	// The user already has an ETH address from setup, which is returned without calling Prime
+	result, err := ledger.ProvisionAsset(ctx, "alice@example.com", "ETH", "ethereum-mainnet")
+	if err != nil {
+		t.Fatalf("ProvisionAsset failed: %v", err)
+	}
+	if result.AddressCreated || result.Address != "0xdeposit" || provisioner.addresses != 0 {
+		t.Errorf("Expected the existing ETH address, got %+v", result)
+	}
+
+	result, err = ledger.ProvisionAsset(ctx, "alice@example.com", "SOL", "solana-mainnet")
+	if err != nil {
+		t.Fatalf("ProvisionAsset failed: %v", err)
+	}
+	if !result.AddressCreated || !result.WalletCreated || result.WalletId != "wallet-SOL" || result.UserId != "user-1" {
+		t.Errorf("Expected a new wallet and address, got %+v", result)
+	}
+	stored, err := db.GetAddresses(ctx, "user-1", "SOL", "solana-mainnet")
+	if err != nil || len(stored) != 1 || stored[0].Address != result.Address || stored[0].AccountIdentifier != "account-1" {
+		t.Fatalf("Expected the new address to be stored, got %+v (%v)", stored, err)
+	}
+
+	// Provisioning again returns the same address and creates nothing
+	again, err := ledger.ProvisionAsset(ctx, "user-1", "SOL", "solana-mainnet")
+	if err != nil {
+		t.Fatalf("ProvisionAsset failed: %v", err)
+	}
+	if again.AddressCreated || again.WalletCreated || again.Address != result.Address || len(provisioner.wallets) != 1 {
+		t.Errorf("Expected the provisioned address to be returned, got %+v", again)
+	}
+
+	for _, tt := range []struct{ symbol, network string }{{"BTC", "bitcoin-mainnet"}, {"DOGE", "dogecoin-mainnet"}} {
+		_, err := ledger.ProvisionAsset(ctx, "user-1", tt.symbol, tt.network)
+		if !errors.Is(err, ErrAssetNotProvisionable) {
+			t.Errorf("%s: expected ErrAssetNotProvisionable, got %v", tt.symbol, err)
+		}
+	}
+	if _, err := ledger.ProvisionAsset(ctx, "bob@example.com", "SOL", "solana-mainnet"); !errors.Is(err, database.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound for an unknown email, got %v", err)
+	}
+}
+
+func TestGetDepositInstructions_LazyAddresses(t *testing.T) {
+	ledger, _, _ := setupWithdrawalTest(t)
+	ctx := context.Background()
+	disabled := false
+	ledger.SetAssetConfigs([]models.AssetConfig{
+		{Symbol: "SOL", Network: "solana-mainnet"},
+		{Symbol: "BTC", Network: "bitcoin-mainnet", Listener: models.AssetListenerConfig{Enabled: &disabled}},
+	}, "")
+	provisioner := &fakeProvisioner{}
+	ledger.SetAddressProvisioner(provisioner, "portfolio-1", models.WalletConfig{})
+
+	// Without lazy addresses a user with no SOL address gets no instructions
+	instructions, err := ledger.GetDepositInstructions(ctx, "user-1", "SOL", "")
+	if err != nil {
+		t.Fatalf("GetDepositInstructions failed: %v", err)
+	}
+	if len(instructions) != 0 || provisioner.addresses != 0 {
+		t.Fatalf("Expected no instructions or addresses, got %+v", instructions)
+	}
+
+	// Concurrent first requests create one wallet and one address and all hand out that address
+	ledger.SetLazyAddresses(true)
+	var wg sync.WaitGroup
+	results := make([][]models.DepositInstructions, 8)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = ledger.GetDepositInstructions(ctx, "user-1", "sol", "")
+		}(i)
+	}
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil {
+			t.Fatalf("GetDepositInstructions failed: %v", errs[i])
+		}
+		if len(results[i]) != 1 || results[i][0].Address != "wallet-SOL-address-1" {
+			t.Errorf("Expected the provisioned SOL address, got %+v", results[i])
+		}
+	}
+	if provisioner.addresses != 1 || len(provisioner.wallets) != 1 {
+		t.Errorf("Expected one address and wallet, got %d and %d", provisioner.addresses, len(provisioner.wallets))
+	}
+
+	// Disabled assets are not provisioned
+	instructions, err = ledger.GetDepositInstructions(ctx, "user-1", "BTC", "bitcoin-mainnet")
+	if err != nil {
+		t.Fatalf("GetDepositInstructions failed: %v", err)
+	}
+	if len(instructions) != 0 || provisioner.addresses != 1 {
+		t.Errorf("Expected nothing provisioned for a disabled asset, got %+v", instructions)
+	}
+}
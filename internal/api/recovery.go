@@ -0,0 +1,102 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"prime-send-receive-go/internal/custody"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+
+	"go.uber.org/zap"
+)
+
+// RecoverWithdrawals resolves withdrawals left held with no record of reaching Prime, e.g. after a crash
+// between reserving a withdrawal and recording Prime's answer. Each is looked up among its wallet's
+// transactions by the idempotency key it was sent under. One Prime never received is released; one Prime
+// has is recorded as submitted and its hold left for the listener to settle. Withdrawals reserved less
+// than minAge ago are skipped, as the request that reserved them may still be running.
+func (s *LedgerService) RecoverWithdrawals(ctx context.Context, provider custody.Provider, portfolioId string, minAge time.Duration) ([]models.WithdrawalRecovery, error) {
+	unsent, err := s.db.ListUnsentWithdrawals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var recoveries []models.WithdrawalRecovery
+	for _, withdrawal := range unsent {
+		if time.Since(withdrawal.CreatedAt) < minAge {
+			continue
+		}
+		recoveries = append(recoveries, s.recoverWithdrawal(ctx, provider, portfolioId, withdrawal))
+	}
+	return recoveries, nil
+}
+
+func (s *LedgerService) recoverWithdrawal(ctx context.Context, provider custody.Provider, portfolioId string, withdrawal models.UnsentWithdrawal) models.WithdrawalRecovery {
+	recovery := models.WithdrawalRecovery{Withdrawal: withdrawal, Outcome: models.WithdrawalRecoveryUnresolved}
+
+	// Batched withdrawals reached Prime under their batch's key
+	key := withdrawal.IdempotencyKey
+	if withdrawal.BatchId != "" {
+		key = withdrawal.BatchId
+	}
+
+	tx, err := custody.FindWithdrawal(ctx, provider, portfolioId, withdrawal.WalletId, key, withdrawal.CreatedAt)
+	if err != nil {
+		recovery.Error = err.Error()
+		return recovery
+	}
+
+	if tx == nil {
+		err := s.db.ReleaseWithdrawal(ctx, withdrawal.UserId, withdrawal.Asset, withdrawal.Amount, withdrawal.IdempotencyKey, "not received by Prime - released by recovery")
+		if err != nil {
+			recovery.Error = err.Error()
+			return recovery
+		}
+		s.logger.Info("Released withdrawal Prime never received",
+			zap.String("user_id", withdrawal.UserId),
+			zap.String("asset", withdrawal.Asset),
+			zap.String("amount", withdrawal.Amount.String()),
+			zap.String("idempotency_key", withdrawal.IdempotencyKey))
+		recovery.Outcome = models.WithdrawalRecoveryReleased
+		return recovery
+	}
+
+	// Prime's transaction id stands in for the activity id the lost response would have carried
+	recovery.PrimeTransactionId = tx.Id
+	if withdrawal.QueueId != "" {
+		if err := s.db.MarkWithdrawalSubmitted(ctx, withdrawal.QueueId, tx.Id); err != nil {
+			recovery.Error = err.Error()
+			return recovery
+		}
+	}
+	if err := s.db.MarkWithdrawalRequestSubmitted(ctx, withdrawal.IdempotencyKey, tx.Id); err != nil {
+		recovery.Error = err.Error()
+		return recovery
+	}
+	if !prime.ReportedAsWithdrawal(withdrawal.DestinationType) {
+		s.captureUnreported(ctx, withdrawal.IdempotencyKey, tx.Id)
+	}
+	s.logger.Info("Found withdrawal at Prime - hold left for the listener",
+		zap.String("user_id", withdrawal.UserId),
+		zap.String("idempotency_key", withdrawal.IdempotencyKey),
+		zap.String("transaction_id", tx.Id))
+	recovery.Outcome = models.WithdrawalRecoverySubmitted
+	return recovery
+}
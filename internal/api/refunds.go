@@ -0,0 +1,160 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// SetRefundApprovalThresholds makes refunds above an asset's threshold wait for approval; assets
+// without a threshold are refunded without approval
+func (s *LedgerService) SetRefundApprovalThresholds(thresholds map[string]decimal.Decimal) {
+	s.refundThresholds = thresholds
+}
+
+// RequestRefund returns a deposit to the on-chain address it was sent from. The refund reserves and debits the user
+// like a withdrawal and the debit records the deposit it returns. Refunds above the approval threshold
+// are recorded as pending_approval and only sent once ApproveRefund is called.
+func (s *LedgerService) RequestRefund(ctx context.Context, req models.RefundRequest) (*models.RefundResult, error) {
+	ctx, _ = correlation.Ensure(ctx)
+	if req.DepositId == "" {
+		return nil, fmt.Errorf("deposit id is required")
+	}
+
+	deposit, err := s.db.GetDeposit(ctx, req.DepositId)
+	if err != nil {
+		return nil, err
+	}
+
+	// Internal transfers record a wallet or account id, which cannot be withdrawn to as an address
+	if !strings.EqualFold(deposit.SourceType, "ADDRESS") || deposit.SourceAddress == "" || deposit.Address == "" {
+		return nil, fmt.Errorf("deposit %s has no recorded source address (source type %q) - use cmd/withdrawal with an explicit destination",
+			deposit.Id, deposit.SourceType)
+	}
+
+	amount := req.Amount
+	if amount.IsZero() {
+		amount = deposit.Amount
+	}
+	if !amount.IsPositive() || amount.GreaterThan(deposit.Amount) {
+		return nil, fmt.Errorf("refund amount must be greater than zero and at most the deposit amount %s", deposit.Amount.String())
+	}
+
+	user, addr, err := s.db.FindUserByAddress(ctx, deposit.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deposit address: %w", err)
+	}
+	if user == nil {
+		return nil, fmt.Errorf("deposit address %s is no longer assigned to an active user", deposit.Address)
+	}
+
+	threshold, limited := s.refundThresholds[deposit.Asset]
+	refund, err := s.db.CreateRefund(ctx, database.CreateRefundParams{
+		DepositTransactionId: deposit.Id,
+		UserId:               deposit.UserId,
+		Asset:                fmt.Sprintf("%s-%s", addr.Asset, addr.Network),
+		Amount:               amount,
+		Destination:          deposit.SourceAddress,
+		Reason:               req.Reason,
+		RequestedBy:          req.RequestedBy,
+		IdempotencyKey:       generateIdempotencyKey(deposit.UserId),
+		NeedsApproval:        limited && amount.GreaterThan(threshold),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if refund.Status == database.RefundStatusPendingApproval {
+		correlation.Logger(ctx, s.logger).Info("Refund is above the approval threshold - waiting for approval",
+			zap.String("refund_id", refund.Id),
+			zap.String("amount", amount.String()),
+			zap.String("threshold", threshold.String()))
+		return &models.RefundResult{Refund: refund}, nil
+	}
+
+	return s.sendRefund(ctx, refund, req.Queue)
+}
+
+// ApproveRefund approves a refund waiting for approval and sends it. The approver must not be the
+// person who requested it.
+func (s *LedgerService) ApproveRefund(ctx context.Context, refundId, approvedBy string, queue bool) (*models.RefundResult, error) {
+	ctx, _ = correlation.Ensure(ctx)
+	if approvedBy == "" {
+		return nil, fmt.Errorf("approver is required")
+	}
+
+	refund, err := s.db.GetRefund(ctx, refundId)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(refund.RequestedBy, approvedBy) {
+		return nil, fmt.Errorf("refund %s must be approved by someone other than its requester", refundId)
+	}
+
+	if err := s.db.ApproveRefund(ctx, refundId, approvedBy); err != nil {
+		return nil, err
+	}
+	refund.Status = database.RefundStatusApproved
+	refund.ApprovedBy = approvedBy
+
+	return s.sendRefund(ctx, refund, queue)
+}
+
+// sendRefund withdraws an approved refund to the deposit's source address
+func (s *LedgerService) sendRefund(ctx context.Context, refund *models.Refund, queue bool) (*models.RefundResult, error) {
+	withdrawal, err := s.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+		User:            refund.UserId,
+		Asset:           refund.Asset,
+		Amount:          refund.Amount,
+		DestinationType: prime.DestinationTypeAddress,
+		Destination:     refund.Destination,
+		IdempotencyKey:  refund.IdempotencyKey,
+		Queue:           queue,
+		RefundOf:        refund.DepositTransactionId,
+	})
+	if err != nil {
+		if updateErr := s.db.UpdateRefundStatus(ctx, refund.Id, database.RefundStatusFailed, err.Error()); updateErr != nil {
+			correlation.Logger(ctx, s.logger).Error("Failed to record refund failure", zap.String("refund_id", refund.Id), zap.Error(updateErr))
+		}
+		return nil, fmt.Errorf("refund %s failed: %w", refund.Id, err)
+	}
+
+	if err := s.db.UpdateRefundStatus(ctx, refund.Id, database.RefundStatusSubmitted, ""); err != nil {
+		correlation.Logger(ctx, s.logger).Error("Refund sent but its status could not be updated",
+			zap.String("refund_id", refund.Id), zap.Error(err))
+	}
+	refund.Status = database.RefundStatusSubmitted
+
+	correlation.Logger(ctx, s.logger).Info("Refund sent",
+		zap.String("refund_id", refund.Id),
+		zap.String("deposit_transaction_id", refund.DepositTransactionId),
+		zap.String("asset", refund.Asset),
+		zap.String("amount", refund.Amount.String()),
+		zap.String("withdrawal_status", withdrawal.Status))
+
+	return &models.RefundResult{Refund: refund, Withdrawal: withdrawal}, nil
+}
@@ -0,0 +1,104 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRequestRefund(t *testing.T) {
+	ledger, submitter, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	sender := models.DepositSource{Type: "ADDRESS", Address: "0xsender"}
+	for txId, amount := range map[string]int64{"deposit-2": 3, "deposit-3": 2} {
+		if err := db.ProcessDeposit(ctx, "0xdeposit", "ETH", decimal.NewFromInt(amount), txId, sender, models.AvailabilityImmediate); err != nil {
+			t.Fatalf("Failed to deposit: %v", err)
+		}
+	}
+
+	// Deposits without a recorded source address cannot be refunded
+	if _, err := ledger.RequestRefund(ctx, models.RefundRequest{DepositId: "deposit-1", RequestedBy: "ops-1"}); err == nil {
+		t.Fatal("Expected a deposit without a source address to be rejected")
+	}
+
+	result, err := ledger.RequestRefund(ctx, models.RefundRequest{DepositId: "deposit-2", RequestedBy: "ops-1"})
+	if err != nil {
+		t.Fatalf("RequestRefund failed: %v", err)
+	}
+	if result.Refund.Status != database.RefundStatusSubmitted || result.Withdrawal == nil {
+		t.Fatalf("Expected the refund to be sent, got %+v", result.Refund)
+	}
+	if len(submitter.calls) != 1 || submitter.calls[0].Destination != "0xsender" || submitter.calls[0].Amount != "3" {
+		t.Fatalf("Expected a 3 ETH withdrawal to the sender, got %+v", submitter.calls)
+	}
+
+	// The debit reaches the ledger when the listener captures the withdrawal
+	if _, err := db.CaptureWithdrawal(ctx, result.Withdrawal.IdempotencyKey, "prime-tx-1"); err != nil {
+		t.Fatalf("CaptureWithdrawal failed: %v", err)
+	}
+	history, err := db.GetTransactionHistory(ctx, "user-1", "ETH", 10, "")
+	if err != nil {
+		t.Fatalf("GetTransactionHistory failed: %v", err)
+	}
+	deposit, err := db.GetDeposit(ctx, "deposit-2")
+	if err != nil {
+		t.Fatalf("GetDeposit failed: %v", err)
+	}
+	linked := false
+	for _, tx := range history {
+		if tx.TransactionType == "withdrawal" && strings.Contains(tx.Reference, "refund_of="+deposit.Id) {
+			linked = true
+		}
+	}
+	if !linked {
+		t.Errorf("Expected the refund debit to reference deposit %s", deposit.Id)
+	}
+
+	if _, err := ledger.RequestRefund(ctx, models.RefundRequest{DepositId: "deposit-2", RequestedBy: "ops-1"}); !errors.Is(err, database.ErrRefundExists) {
+		t.Errorf("Expected ErrRefundExists, got %v", err)
+	}
+
+	// Above the threshold the refund waits for a second person's approval
+	ledger.SetRefundApprovalThresholds(map[string]decimal.Decimal{"ETH": decimal.NewFromInt(1)})
+	result, err = ledger.RequestRefund(ctx, models.RefundRequest{DepositId: "deposit-3", RequestedBy: "ops-1"})
+	if err != nil {
+		t.Fatalf("RequestRefund failed: %v", err)
+	}
+	if result.Refund.Status != database.RefundStatusPendingApproval || len(submitter.calls) != 1 {
+		t.Fatalf("Expected the refund to wait for approval, got %+v", result.Refund)
+	}
+
+	if _, err := ledger.ApproveRefund(ctx, result.Refund.Id, "ops-1", false); err == nil {
+		t.Error("Expected the requester's own approval to be rejected")
+	}
+	approved, err := ledger.ApproveRefund(ctx, result.Refund.Id, "ops-2", false)
+	if err != nil {
+		t.Fatalf("ApproveRefund failed: %v", err)
+	}
+	if approved.Refund.Status != database.RefundStatusSubmitted || approved.Refund.ApprovedBy != "ops-2" || len(submitter.calls) != 2 {
+		t.Errorf("Expected the approved refund to be sent, got %+v", approved.Refund)
+	}
+}
@@ -0,0 +1,55 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// GrantReward credits a promotional reward to a user from a funded reward program
+func (s *LedgerService) GrantReward(ctx context.Context, program, userId string, amount decimal.Decimal, reference string) (*models.RewardGrant, error) {
+	if program == "" || userId == "" || reference == "" {
+		return nil, fmt.Errorf("program, user_id and reference are required")
+	}
+	if amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+
+	grant, err := s.db.GrantReward(ctx, database.GrantRewardParams{
+		Program:   program,
+		UserId:    userId,
+		Amount:    amount,
+		Reference: reference,
+	})
+	if err != nil {
+		s.logger.Error("Failed to grant reward",
+			zap.String("program", program),
+			zap.String("user_id", userId),
+			zap.String("amount", amount.String()),
+			zap.Error(err))
+		return nil, err
+	}
+
+	return grant, nil
+}
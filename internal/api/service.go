@@ -19,25 +19,173 @@ package api
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
 )
 
+// PrimeProbe checks that Prime is reachable and the monitored portfolio still exists
+type PrimeProbe interface {
+	CheckPortfolio(ctx context.Context, portfolioId string) (*models.Portfolio, error)
+}
+
+// WithdrawalSubmitter sends withdrawals to the custody venue; custody.Provider satisfies it
+type WithdrawalSubmitter interface {
+	CreateWithdrawal(ctx context.Context, params models.CreateWithdrawalParams) (*models.Withdrawal, error)
+}
+
+// FeeEstimator estimates network fees from the withdrawals a wallet has recently made
+type FeeEstimator interface {
+	RecentNetworkFees(ctx context.Context, portfolioId, walletId string, since time.Time) ([]models.NetworkFeeEstimate, error)
+}
+
+// AddressProvisioner finds or creates trading wallets and their deposit addresses; custody.Provider satisfies it
+type AddressProvisioner interface {
+	ListWallets(ctx context.Context, portfolioId, walletType string, symbols []string) ([]models.Wallet, error)
+	CreateWallet(ctx context.Context, portfolioId, name, symbol, walletType string) (*models.Wallet, error)
+	CreateDepositAddress(ctx context.Context, portfolioId, walletId, asset, network string) (*models.DepositAddress, error)
+}
+
 // LedgerService provides minimal API
 type LedgerService struct {
-	db *database.Service
+	db           *database.Service
+	logger       *zap.Logger
+	balanceCache *BalanceCache
+	explorer     models.ExplorerConfig
+	primeProbe   PrimeProbe
+	submitter    WithdrawalSubmitter
+	feeEstimator FeeEstimator
+	portfolioId  string
+	// dailyLimits caps each user's withdrawals per UTC day, by asset symbol
+	dailyLimits map[string]decimal.Decimal
+	// refundThresholds is the refund amount above which approval is needed, by asset symbol
+	refundThresholds map[string]decimal.Decimal
+	// assets and defaultAvailability describe deposit policies in deposit instructions
+	assets              []models.AssetConfig
+	defaultAvailability string
+	// provisioner creates wallets and deposit addresses for ProvisionAsset, named by walletConfig
+	provisioner  AddressProvisioner
+	walletConfig models.WalletConfig
+	// addressLocks serialize ProvisionAsset per user/asset/network and walletLocks per asset symbol, so
+	// concurrent requests do not create two addresses or wallets
+	addressLocks keyedMutex
+	walletLocks  keyedMutex
+	// lazyAddresses provisions missing deposit addresses when deposit instructions are requested
+	lazyAddresses bool
+	// stepHook is called as each withdrawal reaches a saga step
+	stepHook models.WithdrawalStepHook
 }
 
-func NewLedgerService(db *database.Service) *LedgerService {
+func NewLedgerService(db *database.Service, logger *zap.Logger) *LedgerService {
 	return &LedgerService{
-		db: db,
+		db:     db,
+		logger: logger,
+	}
+}
+
+// SetBalanceCache serves balance lookups from cache, invalidating entries as the ledger changes.
+// It must be called before the service handles requests.
+func (s *LedgerService) SetBalanceCache(cache *BalanceCache) {
+	s.balanceCache = cache
+	s.db.OnBalanceChange(cache.Invalidate)
+}
+
+// SetExplorer renders block explorer links for on-chain transactions in transaction history
+func (s *LedgerService) SetExplorer(explorer models.ExplorerConfig) {
+	s.explorer = explorer
+}
+
+// SetPrimeProbe lets HealthCheck verify Prime connectivity and the monitored portfolio
+func (s *LedgerService) SetPrimeProbe(probe PrimeProbe, portfolioId string) {
+	s.primeProbe = probe
+	s.portfolioId = portfolioId
+}
+
+// SetWithdrawalSubmitter lets CreateWithdrawalForUser send withdrawals from the portfolio's wallets
+func (s *LedgerService) SetWithdrawalSubmitter(submitter WithdrawalSubmitter, portfolioId string) {
+	s.submitter = submitter
+	s.portfolioId = portfolioId
+}
+
+// SetWithdrawalStepHook calls hook as each withdrawal reaches a saga step. A hook error stops the
+// withdrawal there without cleanup, as a crash would; crash-recovery tests and drills use it.
+func (s *LedgerService) SetWithdrawalStepHook(hook models.WithdrawalStepHook) {
+	s.stepHook = hook
+}
+
+// SetFeeEstimator adds recent network fees from the portfolio's wallets to withdrawal capacity previews
+func (s *LedgerService) SetFeeEstimator(estimator FeeEstimator, portfolioId string) {
+	s.feeEstimator = estimator
+	s.portfolioId = portfolioId
+}
+
+// SetAddressProvisioner enables ProvisionAsset, which finds or creates trading wallets in portfolioId
+// named by the wallet naming convention
+func (s *LedgerService) SetAddressProvisioner(provisioner AddressProvisioner, portfolioId string, wallets models.WalletConfig) {
+	s.provisioner = provisioner
+	s.portfolioId = portfolioId
+	s.walletConfig = wallets
+}
+
+// SetLazyAddresses makes GetDepositInstructions create a user's missing deposit addresses for enabled
+// assets on first request, instead of setup and adduser creating them up front. It needs SetAddressProvisioner.
+func (s *LedgerService) SetLazyAddresses(enabled bool) {
+	s.lazyAddresses = enabled
+}
+
+// SetWithdrawalLimits caps what one user may withdraw of each asset per UTC day; assets without a
+// limit are unlimited
+func (s *LedgerService) SetWithdrawalLimits(dailyLimits map[string]decimal.Decimal) {
+	s.dailyLimits = dailyLimits
+}
+
+// SetAssetConfigs lets deposit instructions describe each asset's deposit policies from assets.yaml.
+// defaultAvailability is the FUNDS_AVAILABILITY policy for assets that do not set their own.
+func (s *LedgerService) SetAssetConfigs(assets []models.AssetConfig, defaultAvailability string) {
+	s.assets = assets
+	s.defaultAvailability = defaultAvailability
+}
+
+// HealthCheck checks the database and, when probePrime is set and a probe is configured, Prime.
+// Prime is optional because each probe is an API call counted against the rate limit.
+func (s *LedgerService) HealthCheck(ctx context.Context, probePrime bool) *models.HealthReport {
+	report := &models.HealthReport{Healthy: true}
+
+	report.Add(checkDependency("database", func() (string, error) {
+		if _, err := s.db.GetUsers(ctx); err != nil {
+			return "", fmt.Errorf("database health check failed: %w", err)
+		}
+		return "", nil
+	}))
+
+	if probePrime && s.primeProbe != nil {
+		report.Add(checkDependency("prime", func() (string, error) {
+			portfolio, err := s.primeProbe.CheckPortfolio(ctx, s.portfolioId)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("portfolio %s (%s)", portfolio.Name, portfolio.Id), nil
+		}))
 	}
+
+	return report
 }
 
-func (s *LedgerService) HealthCheck(ctx context.Context) error {
-	_, err := s.db.GetUsers(ctx)
+func checkDependency(name string, check func() (string, error)) models.DependencyHealth {
+	started := time.Now()
+	detail, err := check()
+	result := models.DependencyHealth{
+		Name:      name,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(started).Milliseconds(),
+		Detail:    detail,
+	}
 	if err != nil {
-		return fmt.Errorf("database health check failed: %w", err)
+		result.Error = err.Error()
 	}
-	return nil
+	return result
 }
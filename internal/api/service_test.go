@@ -0,0 +1,66 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/database/dbtest"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type fakePrimeProbe struct {
+	err error
+}
+
+func (f fakePrimeProbe) CheckPortfolio(ctx context.Context, portfolioId string) (*models.Portfolio, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &models.Portfolio{Id: portfolioId, Name: "Default Portfolio"}, nil
+}
+
+func TestHealthCheck(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+
+	ledger := NewLedgerService(db, zaptest.NewLogger(t))
+
+	report := ledger.HealthCheck(ctx, true)
+	if !report.Healthy || len(report.Dependencies) != 1 || report.Dependencies[0].Name != "database" {
+		t.Fatalf("Expected only a healthy database check without a probe, got %+v", report)
+	}
+
+	ledger.SetPrimeProbe(fakePrimeProbe{}, "portfolio-1")
+	if report := ledger.HealthCheck(ctx, false); len(report.Dependencies) != 1 {
+		t.Errorf("Expected Prime to be skipped unless requested, got %+v", report)
+	}
+	report = ledger.HealthCheck(ctx, true)
+	if !report.Healthy || len(report.Dependencies) != 2 || report.Dependencies[1].Name != "prime" {
+		t.Fatalf("Expected healthy database and Prime checks, got %+v", report)
+	}
+
+	ledger.SetPrimeProbe(fakePrimeProbe{err: errors.New("prime rejected the API credentials")}, "portfolio-1")
+	report = ledger.HealthCheck(ctx, true)
+	if report.Healthy || !report.Dependencies[0].Healthy || report.Dependencies[1].Healthy || report.Dependencies[1].Error == "" {
+		t.Errorf("Expected only Prime to be reported unhealthy, got %+v", report)
+	}
+}
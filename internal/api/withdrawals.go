@@ -19,10 +19,16 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
 
 	"go.uber.org/zap"
 )
@@ -35,7 +41,7 @@ func (s *LedgerService) ProcessWithdrawal(ctx context.Context, userId, asset str
 		}, nil
 	}
 
-	zap.L().Info("Processing withdrawal from Prime API",
+	correlation.Logger(ctx, s.logger).Info("Processing withdrawal from Prime API",
 		zap.String("user_id", userId),
 		zap.String("asset_network", asset),
 		zap.String("amount", amount.String()),
@@ -44,13 +50,13 @@ func (s *LedgerService) ProcessWithdrawal(ctx context.Context, userId, asset str
 	err := s.db.ProcessWithdrawal(ctx, userId, asset, amount, externalTxId)
 	if err != nil {
 		if errors.Is(err, database.ErrDuplicateTransaction) {
-			zap.L().Info("Duplicate withdrawal detected in API service",
+			correlation.Logger(ctx, s.logger).Info("Duplicate withdrawal detected in API service",
 				zap.String("user_id", userId),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
 				zap.String("external_tx_id", externalTxId))
 		} else {
-			zap.L().Error("Withdrawal processing failed",
+			correlation.Logger(ctx, s.logger).Error("Withdrawal processing failed",
 				zap.String("user_id", userId),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
@@ -60,12 +66,13 @@ func (s *LedgerService) ProcessWithdrawal(ctx context.Context, userId, asset str
 		return &models.DepositResult{
 			Success: false,
 			Error:   err.Error(),
+			Err:     err,
 		}, nil
 	}
 
 	user, err := s.db.GetUserById(ctx, userId)
 	if err != nil {
-		zap.L().Error("User lookup failed after withdrawal processing",
+		correlation.Logger(ctx, s.logger).Error("User lookup failed after withdrawal processing",
 			zap.String("user_id", userId),
 			zap.Error(err))
 		return &models.DepositResult{
@@ -76,7 +83,7 @@ func (s *LedgerService) ProcessWithdrawal(ctx context.Context, userId, asset str
 
 	newBalance, err := s.db.GetUserBalance(ctx, userId, asset)
 	if err != nil {
-		zap.L().Error("Balance lookup failed after withdrawal processing",
+		correlation.Logger(ctx, s.logger).Error("Balance lookup failed after withdrawal processing",
 			zap.String("user_id", userId),
 			zap.String("asset_network", asset),
 			zap.Error(err))
@@ -86,7 +93,7 @@ func (s *LedgerService) ProcessWithdrawal(ctx context.Context, userId, asset str
 		}, nil
 	}
 
-	zap.L().Info("Withdrawal processed successfully",
+	correlation.Logger(ctx, s.logger).Info("Withdrawal processed successfully",
 		zap.String("user_id", user.Id),
 		zap.String("user_name", user.Name),
 		zap.String("asset_network", asset),
@@ -102,7 +109,8 @@ func (s *LedgerService) ProcessWithdrawal(ctx context.Context, userId, asset str
 	}, nil
 }
 
-// CreditBackFailedWithdrawal credits back a withdrawal that failed (e.g., TRANSACTION_FAILED, TRANSACTION_CANCELLED)
+// CreditBackFailedWithdrawal returns a withdrawal that failed (e.g., TRANSACTION_FAILED, TRANSACTION_CANCELLED) to
+// the available balance by releasing its hold, or for withdrawals debited before holds, by reversing the debit
 func (s *LedgerService) CreditBackFailedWithdrawal(ctx context.Context, userId, asset string, amount decimal.Decimal, originalTxId string) (*models.DepositResult, error) {
 	if userId == "" || asset == "" || amount.LessThanOrEqual(decimal.Zero) || originalTxId == "" {
 		return &models.DepositResult{
@@ -111,22 +119,22 @@ func (s *LedgerService) CreditBackFailedWithdrawal(ctx context.Context, userId,
 		}, nil
 	}
 
-	zap.L().Info("Crediting back failed withdrawal",
+	correlation.Logger(ctx, s.logger).Info("Crediting back failed withdrawal",
 		zap.String("user_id", userId),
 		zap.String("asset_network", asset),
 		zap.String("amount", amount.String()),
 		zap.String("original_tx_id", originalTxId))
 
-	err := s.db.ReverseWithdrawal(ctx, userId, asset, amount, originalTxId)
+	err := s.db.ReleaseWithdrawal(ctx, userId, asset, amount, originalTxId, "withdrawal failed in Prime")
 	if err != nil {
 		if errors.Is(err, database.ErrDuplicateTransaction) {
-			zap.L().Info("Duplicate credit-back detected in API service",
+			correlation.Logger(ctx, s.logger).Info("Duplicate credit-back detected in API service",
 				zap.String("user_id", userId),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
 				zap.String("original_tx_id", originalTxId))
 		} else {
-			zap.L().Error("Credit-back processing failed",
+			correlation.Logger(ctx, s.logger).Error("Credit-back processing failed",
 				zap.String("user_id", userId),
 				zap.String("asset_network", asset),
 				zap.String("amount", amount.String()),
@@ -136,12 +144,13 @@ func (s *LedgerService) CreditBackFailedWithdrawal(ctx context.Context, userId,
 		return &models.DepositResult{
 			Success: false,
 			Error:   err.Error(),
+			Err:     err,
 		}, nil
 	}
 
 	newBalance, err := s.db.GetUserBalance(ctx, userId, asset)
 	if err != nil {
-		zap.L().Error("Balance lookup failed after credit-back",
+		correlation.Logger(ctx, s.logger).Error("Balance lookup failed after credit-back",
 			zap.String("user_id", userId),
 			zap.String("asset_network", asset),
 			zap.Error(err))
@@ -151,7 +160,7 @@ func (s *LedgerService) CreditBackFailedWithdrawal(ctx context.Context, userId,
 		}, nil
 	}
 
-	zap.L().Info("Failed withdrawal credited back successfully",
+	correlation.Logger(ctx, s.logger).Info("Failed withdrawal credited back successfully",
 		zap.String("user_id", userId),
 		zap.String("asset_network", asset),
 		zap.String("amount", amount.String()),
@@ -165,3 +174,434 @@ func (s *LedgerService) CreditBackFailedWithdrawal(ctx context.Context, userId,
 		NewBalance: newBalance,
 	}, nil
 }
+
+// ErrDailyLimitExceeded is returned when a withdrawal would take a user past the asset's daily limit
+var ErrDailyLimitExceeded = errors.New("daily withdrawal limit exceeded")
+
+// ErrNoWalletForAsset is returned when the user has no deposit address, and so no wallet, for the asset
+var ErrNoWalletForAsset = errors.New("no wallet found for asset")
+
+// feeEstimateWindow is how far back a wallet's withdrawals are sampled for fee estimates
+const feeEstimateWindow = 7 * 24 * time.Hour
+
+// CreateWithdrawalForUser runs a customer withdrawal end to end: it finds the user by email or id, checks the
+// available balance, reserves the amount with a withdrawal hold, then sends the withdrawal to Prime (or queues
+// it for the withdrawal worker), releasing the hold if that fails. The listener captures the hold onto the
+// ledger at TRANSACTION_DONE. A request repeating an idempotency key that was already used is reported as
+// replayed rather than withdrawn again, unless the first request stopped before the withdrawal was sent or
+// queued, in which case it is sent now under the same key. The request's correlation id, taken from ctx or generated,
+// is attached to its log lines and result, and to the queued withdrawal so the worker logs under it too.
+func (s *LedgerService) CreateWithdrawalForUser(ctx context.Context, req models.WithdrawalRequest) (*models.WithdrawalResult, error) {
+	ctx, correlationId := correlation.Ensure(ctx)
+	if req.User == "" || req.Asset == "" || req.Destination == "" {
+		return nil, fmt.Errorf("user, asset and destination are required")
+	}
+	if req.Amount.LessThanOrEqual(decimal.Zero) {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+	if s.submitter == nil && !req.Queue {
+		return nil, fmt.Errorf("withdrawals are not configured")
+	}
+
+	symbol, network, ok := strings.Cut(req.Asset, "-")
+	if !ok || symbol == "" || network == "" {
+		return nil, fmt.Errorf("invalid asset format, expected: SYMBOL-network-type (e.g., ETH-ethereum-mainnet)")
+	}
+
+	destinationType, err := prime.ParseDestinationType(req.DestinationType)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.lookupUser(ctx, req.User)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyKey := req.IdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = generateIdempotencyKey(user.Id)
+	}
+
+	result := &models.WithdrawalResult{
+		Asset:           req.Asset,
+		Amount:          req.Amount,
+		DestinationType: destinationType,
+		Destination:     req.Destination,
+		IdempotencyKey:  idempotencyKey,
+		CorrelationId:   correlationId,
+	}
+
+	replayed, err := s.findWithdrawal(ctx, user.Id, symbol, idempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	unsent, err := s.findResumable(ctx, replayed, symbol)
+	if err != nil {
+		return nil, err
+	}
+	if replayed != nil && unsent == nil {
+		correlation.Logger(ctx, s.logger).Info("Idempotency key already used - returning existing withdrawal",
+			zap.String("user_id", user.Id),
+			zap.String("idempotency_key", idempotencyKey),
+			zap.String("status", replayed.Status))
+		result.Status = models.WithdrawalReplayed
+		result.Amount = replayed.Amount
+		result.Destination = replayed.Destination
+		s.setAvailableBalance(ctx, result, user.Id, symbol)
+		return result, nil
+	}
+
+	var walletId string
+	if unsent != nil {
+		// The first request reserved the withdrawal but stopped before it reached Prime, so it is sent now
+		correlation.Logger(ctx, s.logger).Warn("Idempotency key reserved but never sent - resuming withdrawal",
+			zap.String("user_id", user.Id),
+			zap.String("idempotency_key", idempotencyKey))
+		result.Amount = unsent.Amount
+		result.DestinationType = unsent.DestinationType
+		result.Destination = unsent.Destination
+		walletId = unsent.WalletId
+	} else {
+		walletId, err = s.reserveWithdrawal(ctx, req, user.Id, symbol, network, destinationType, idempotencyKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if req.Queue {
+		result.Status = models.WithdrawalQueued
+		result.QueueId, err = s.db.EnqueueWithdrawal(ctx, database.EnqueueWithdrawalParams{
+			UserId:          user.Id,
+			Asset:           symbol,
+			AssetNetwork:    req.Asset,
+			Amount:          result.Amount,
+			DestinationType: result.DestinationType,
+			Destination:     result.Destination,
+			WalletId:        walletId,
+			IdempotencyKey:  idempotencyKey,
+			CorrelationId:   correlationId,
+		})
+		if err != nil {
+			return nil, s.rollbackWithdrawal(ctx, user.Id, symbol, result.Amount, idempotencyKey, fmt.Errorf("failed to queue withdrawal: %w", err))
+		}
+	} else {
+		result.Status = models.WithdrawalSubmitted
+		withdrawal, err := s.submitter.CreateWithdrawal(ctx, models.CreateWithdrawalParams{
+			PortfolioId:     s.portfolioId,
+			WalletId:        walletId,
+			DestinationType: result.DestinationType,
+			Destination:     result.Destination,
+			Amount:          result.Amount.String(),
+			Asset:           req.Asset,
+			IdempotencyKey:  idempotencyKey,
+		})
+		if err != nil {
+			return nil, s.rollbackWithdrawal(ctx, user.Id, symbol, result.Amount, idempotencyKey, fmt.Errorf("Prime API withdrawal failed: %w", err))
+		}
+		if err := s.reachStep(models.WithdrawalStepSubmitted, idempotencyKey); err != nil {
+			return nil, err
+		}
+		result.ActivityId = withdrawal.ActivityId
+		s.markSubmitted(ctx, idempotencyKey, withdrawal.ActivityId)
+		s.saveWithdrawalReceipt(ctx, user.Id, withdrawal)
+		if !prime.ReportedAsWithdrawal(result.DestinationType) {
+			s.captureUnreported(ctx, idempotencyKey, withdrawal.ActivityId)
+		}
+	}
+
+	correlation.Logger(ctx, s.logger).Info("Withdrawal created",
+		zap.String("user_id", user.Id),
+		zap.String("asset", symbol),
+		zap.String("amount", result.Amount.String()),
+		zap.String("status", result.Status),
+		zap.String("idempotency_key", idempotencyKey))
+
+	s.setAvailableBalance(ctx, result, user.Id, symbol)
+	return result, nil
+}
+
+// lookupUser resolves a user by email address, or by user id when the identifier is not an email
+func (s *LedgerService) lookupUser(ctx context.Context, identifier string) (*models.User, error) {
+	if strings.Contains(identifier, "@") {
+		return s.db.GetUserByEmail(ctx, identifier)
+	}
+	return s.db.GetUserById(ctx, identifier)
+}
+
+// reserveWithdrawal checks the request against the user's available balance and daily limit and places
+// its withdrawal hold. Returns the wallet the withdrawal is sent from.
+func (s *LedgerService) reserveWithdrawal(ctx context.Context, req models.WithdrawalRequest, userId, symbol, network, destinationType, idempotencyKey string) (string, error) {
+	// Withdrawals may only spend the available balance; held deposits are excluded
+	available, err := s.db.GetAvailableBalance(ctx, userId, symbol)
+	if err != nil {
+		return "", fmt.Errorf("failed to get user balance: %w", err)
+	}
+	if available.LessThan(req.Amount) {
+		return "", fmt.Errorf("%w: available=%s, requested=%s, shortfall=%s", database.ErrInsufficientBalance,
+			available.String(), req.Amount.String(), req.Amount.Sub(available).String())
+	}
+
+	remaining, _, limited, err := s.dailyLimitRemaining(ctx, userId, symbol)
+	if err != nil {
+		return "", err
+	}
+	if limited && remaining.LessThan(req.Amount) {
+		return "", fmt.Errorf("%w: remaining today=%s, requested=%s", ErrDailyLimitExceeded, remaining.String(), req.Amount.String())
+	}
+
+	addresses, err := s.db.GetAddresses(ctx, userId, symbol, network)
+	if err != nil {
+		return "", fmt.Errorf("failed to get wallet for asset: %w", err)
+	}
+	if len(addresses) == 0 {
+		return "", fmt.Errorf("%w %s", ErrNoWalletForAsset, req.Asset)
+	}
+	walletId := addresses[0].WalletId
+
+	correlation.Logger(ctx, s.logger).Info("Reserving balance before withdrawal",
+		zap.String("user_id", userId),
+		zap.String("asset", symbol),
+		zap.String("amount", req.Amount.String()),
+		zap.String("destination_type", destinationType),
+		zap.String("idempotency_key", idempotencyKey))
+
+	err = s.db.ReserveWithdrawal(ctx, database.ReserveWithdrawalParams{
+		UserId:          userId,
+		Asset:           symbol,
+		Amount:          req.Amount,
+		IdempotencyKey:  idempotencyKey,
+		DestinationType: destinationType,
+		Destination:     req.Destination,
+		WalletId:        walletId,
+		RefundOf:        req.RefundOf,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrInsufficientBalance) {
+			return "", fmt.Errorf("balance changed since it was checked: %w", err)
+		}
+		if errors.Is(err, database.ErrConcurrentModification) {
+			return "", fmt.Errorf("balance was modified by another withdrawal - please retry: %w", err)
+		}
+		if errors.Is(err, database.ErrDuplicateTransaction) {
+			return "", fmt.Errorf("withdrawal with this idempotency key is already being processed - please retry in a moment: %w", err)
+		}
+		return "", fmt.Errorf("failed to reserve balance: %w", err)
+	}
+	if err := s.reachStep(models.WithdrawalStepReserved, idempotencyKey); err != nil {
+		return "", err
+	}
+	return walletId, nil
+}
+
+// findResumable returns the withdrawal behind a held hold when it never reached Prime and was never
+// queued, so a retry can send it. Queued withdrawals are left to the worker and cmd/recover.
+func (s *LedgerService) findResumable(ctx context.Context, hold *models.WithdrawalHold, symbol string) (*models.UnsentWithdrawal, error) {
+	if hold == nil || hold.Status != database.WithdrawalHoldStatusHeld {
+		return nil, nil
+	}
+	unsent, err := s.db.GetUnsentWithdrawal(ctx, hold.IdempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check withdrawal request: %w", err)
+	}
+	if unsent == nil || unsent.QueueId != "" || unsent.Asset != symbol {
+		return nil, nil
+	}
+	return unsent, nil
+}
+
+// findWithdrawal returns the user's withdrawal reserved under an idempotency key, or nil if there is none.
+// Withdrawals debited before holds were introduced are found on the ledger and reported as captured.
+func (s *LedgerService) findWithdrawal(ctx context.Context, userId, symbol, idempotencyKey string) (*models.WithdrawalHold, error) {
+	hold, err := s.db.GetWithdrawalHold(ctx, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check withdrawal holds: %w", err)
+	}
+	if hold != nil && hold.UserId == userId {
+		return hold, nil
+	}
+
+	existing, err := s.db.GetTransactionHistory(ctx, userId, symbol, 1000, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check transaction history: %w", err)
+	}
+
+	for _, tx := range existing {
+		if tx.ExternalTransactionId == idempotencyKey && tx.TransactionType == "withdrawal" {
+			return &models.WithdrawalHold{
+				IdempotencyKey: idempotencyKey,
+				UserId:         userId,
+				Asset:          symbol,
+				Amount:         tx.Amount.Neg(),
+				Destination:    tx.Address,
+				Status:         database.WithdrawalHoldStatusCaptured,
+				TransactionId:  tx.Id,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// rollbackWithdrawal releases the hold of a withdrawal that could not be sent and returns the cause
+func (s *LedgerService) rollbackWithdrawal(ctx context.Context, userId, symbol string, amount decimal.Decimal, idempotencyKey string, cause error) error {
+	correlation.Logger(ctx, s.logger).Error("Withdrawal failed - releasing reserved balance",
+		zap.String("user_id", userId),
+		zap.String("asset", symbol),
+		zap.String("amount", amount.String()),
+		zap.Error(cause))
+
+	if err := s.reachStep(models.WithdrawalStepRollback, idempotencyKey); err != nil {
+		return err
+	}
+	if err := s.db.ReleaseWithdrawal(ctx, userId, symbol, amount, idempotencyKey, cause.Error()); err != nil {
+		return fmt.Errorf("CRITICAL: failed to release withdrawal hold - manual intervention required: %w (withdrawal error: %v)", err, cause)
+	}
+	return fmt.Errorf("%w (reserved balance released)", cause)
+}
+
+// reachStep reports a saga step to the step hook. An error stops the withdrawal at that step with its
+// hold and request left as they are.
+func (s *LedgerService) reachStep(step models.WithdrawalStep, idempotencyKey string) error {
+	if s.stepHook == nil {
+		return nil
+	}
+	if err := s.stepHook(step, idempotencyKey); err != nil {
+		return fmt.Errorf("withdrawal stopped at %s step: %w", step, err)
+	}
+	return nil
+}
+
+// captureUnreported debits a withdrawal the listener will never see complete as soon as Prime accepts it.
+// The withdrawal stands if the capture fails, and its hold stays in place.
+func (s *LedgerService) captureUnreported(ctx context.Context, idempotencyKey, activityId string) {
+	if _, err := s.db.CaptureWithdrawal(ctx, idempotencyKey, activityId); err != nil {
+		correlation.Logger(ctx, s.logger).Error("Failed to capture withdrawal Prime does not report - hold left in place",
+			zap.String("idempotency_key", idempotencyKey),
+			zap.String("activity_id", activityId),
+			zap.Error(err))
+	}
+}
+
+// markSubmitted records that Prime accepted the withdrawal. The listener attributes the withdrawal by its
+// request whatever its status, so a failed update is only logged.
+func (s *LedgerService) markSubmitted(ctx context.Context, idempotencyKey, activityId string) {
+	if err := s.db.MarkWithdrawalRequestSubmitted(ctx, idempotencyKey, activityId); err != nil {
+		correlation.Logger(ctx, s.logger).Warn("Failed to mark withdrawal request submitted",
+			zap.String("idempotency_key", idempotencyKey),
+			zap.String("activity_id", activityId),
+			zap.Error(err))
+	}
+}
+
+// saveWithdrawalReceipt keeps Prime's response for later lookup; the withdrawal stands if it cannot be saved
+func (s *LedgerService) saveWithdrawalReceipt(ctx context.Context, userId string, withdrawal *models.Withdrawal) {
+	if err := s.db.SaveWithdrawalReceipt(ctx, withdrawal.Receipt(userId)); err != nil {
+		correlation.Logger(ctx, s.logger).Warn("Failed to save withdrawal receipt",
+			zap.String("activity_id", withdrawal.ActivityId),
+			zap.Error(err))
+	}
+}
+
+// GetWithdrawalReceipt returns Prime's response to the withdrawal submitted as activityId
+func (s *LedgerService) GetWithdrawalReceipt(ctx context.Context, activityId string) (*models.WithdrawalReceipt, error) {
+	receipt, err := s.db.GetWithdrawalReceipt(ctx, activityId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get withdrawal receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// setAvailableBalance reports the balance left after the withdrawal; the withdrawal stands if the lookup fails
+func (s *LedgerService) setAvailableBalance(ctx context.Context, result *models.WithdrawalResult, userId, symbol string) {
+	available, err := s.db.GetAvailableBalance(ctx, userId, symbol)
+	if err != nil {
+		correlation.Logger(ctx, s.logger).Warn("Balance lookup failed after withdrawal", zap.String("user_id", userId), zap.Error(err))
+		return
+	}
+	result.AvailableBalance = available
+}
+
+// GetWithdrawalCapacity previews how much of an asset a user can withdraw now: the available balance,
+// what is held, what is left of the daily limit and, with a fee estimator, recent network fees
+func (s *LedgerService) GetWithdrawalCapacity(ctx context.Context, userId, asset string) (*models.WithdrawalCapacity, error) {
+	if userId == "" || asset == "" {
+		return nil, fmt.Errorf("user_id and asset are required")
+	}
+
+	if _, err := s.db.GetUserById(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	balance, err := s.db.GetUserBalance(ctx, userId, asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user balance: %w", err)
+	}
+	available, err := s.db.GetAvailableBalance(ctx, userId, asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get available balance: %w", err)
+	}
+
+	capacity := &models.WithdrawalCapacity{
+		Asset:        asset,
+		Balance:      balance,
+		Available:    available,
+		Held:         decimal.Max(balance.Sub(available), decimal.Zero),
+		Withdrawable: decimal.Max(available, decimal.Zero),
+	}
+
+	remaining, limit, limited, err := s.dailyLimitRemaining(ctx, userId, asset)
+	if err != nil {
+		return nil, err
+	}
+	if limited {
+		capacity.DailyLimit = &limit
+		capacity.DailyLimitRemaining = &remaining
+		capacity.Withdrawable = decimal.Min(capacity.Withdrawable, remaining)
+	}
+
+	capacity.EstimatedFees = s.estimateFees(ctx, userId, asset)
+	return capacity, nil
+}
+
+// dailyLimitRemaining returns how much more of an asset the user may withdraw today (UTC). limited is
+// false when the asset has no daily limit.
+func (s *LedgerService) dailyLimitRemaining(ctx context.Context, userId, symbol string) (remaining, limit decimal.Decimal, limited bool, err error) {
+	limit, limited = s.dailyLimits[symbol]
+	if !limited {
+		return decimal.Zero, decimal.Zero, false, nil
+	}
+
+	withdrawn, err := s.db.WithdrawnSince(ctx, userId, symbol, time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		return decimal.Zero, decimal.Zero, false, fmt.Errorf("failed to check daily withdrawal limit: %w", err)
+	}
+	return decimal.Max(limit.Sub(withdrawn), decimal.Zero), limit, true, nil
+}
+
+// estimateFees returns recent network fees for the user's wallet in the asset, or nil when they cannot be estimated
+func (s *LedgerService) estimateFees(ctx context.Context, userId, symbol string) []models.NetworkFeeEstimate {
+	if s.feeEstimator == nil {
+		return nil
+	}
+
+	addresses, err := s.db.FilterUserAddresses(ctx, userId, symbol, "")
+	if err != nil || len(addresses) == 0 {
+		return nil
+	}
+
+	fees, err := s.feeEstimator.RecentNetworkFees(ctx, s.portfolioId, addresses[0].WalletId, time.Now().UTC().Add(-feeEstimateWindow))
+	if err != nil {
+		correlation.Logger(ctx, s.logger).Warn("Failed to estimate withdrawal fees",
+			zap.String("user_id", userId),
+			zap.String("asset", symbol),
+			zap.Error(err))
+		return nil
+	}
+	return fees
+}
+
+func generateIdempotencyKey(userId string) string {
+	userIdSegments := strings.Split(userId, "-")
+	uuidSegments := strings.Split(uuid.New().String(), "-")
+	return userIdSegments[0] + "-" + strings.Join(uuidSegments[1:], "-")
+}
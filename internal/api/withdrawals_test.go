@@ -0,0 +1,437 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/custody"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/database/dbtest"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeSubmitter stands in for Prime: it records every withdrawal it accepts under its idempotency key and
+// lists them as wallet transactions. Every other custody.Provider method is left unimplemented.
+type fakeSubmitter struct {
+	custody.Provider
+
+	err      error
+	calls    []models.CreateWithdrawalParams
+	accepted map[string]bool
+}
+
+func (f *fakeSubmitter) CreateWithdrawal(ctx context.Context, params models.CreateWithdrawalParams) (*models.Withdrawal, error) {
+	f.calls = append(f.calls, params)
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.accepted == nil {
+		f.accepted = make(map[string]bool)
+	}
+	f.accepted[params.IdempotencyKey] = true
+	return &models.Withdrawal{ActivityId: "activity-1", Asset: params.Asset, Amount: params.Amount, Destination: params.Destination}, nil
+}
+
+func (f *fakeSubmitter) WalletTransactions(ctx context.Context, portfolioId, walletId string, since time.Time, limit int) ([]models.PrimeTransaction, error) {
+	var transactions []models.PrimeTransaction
+	for key := range f.accepted {
+		transactions = append(transactions, models.PrimeTransaction{Id: "tx-" + key, Type: "WITHDRAWAL", IdempotencyKey: key})
+	}
+	return transactions, nil
+}
+
+func setupWithdrawalTest(t *testing.T) (*LedgerService, *fakeSubmitter, *database.Service) {
+	db := dbtest.Open(t)
+	dbtest.CreateUser(t, db, "user-1", "Alice", "alice@example.com")
+	dbtest.StoreAddress(t, db, database.StoreAddressParams{
+		UserId:   "user-1",
+		Asset:    "ETH",
+		Network:  "ethereum-mainnet",
+		Address:  "0xdeposit",
+		WalletId: "wallet-1",
+	})
+	dbtest.Deposit(t, db, "0xdeposit", "ETH", decimal.NewFromInt(10), "deposit-1")
+
+	submitter := &fakeSubmitter{}
+	ledger := NewLedgerService(db, zaptest.NewLogger(t))
+	ledger.SetWithdrawalSubmitter(submitter, "portfolio-1")
+	return ledger, submitter, db
+}
+
+func TestCreateWithdrawalForUser(t *testing.T) {
+	ledger, submitter, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	req := models.WithdrawalRequest{
+		User:           "alice@example.com",
+		Asset:          "ETH-ethereum-mainnet",
+		Amount:         decimal.NewFromInt(4),
+		Destination:    "0xexternal",
+		IdempotencyKey: "withdrawal-1",
+	}
+	result, err := ledger.CreateWithdrawalForUser(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateWithdrawalForUser failed: %v", err)
+	}
+	if result.Status != models.WithdrawalSubmitted || result.ActivityId != "activity-1" || !result.AvailableBalance.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if len(submitter.calls) != 1 || submitter.calls[0].WalletId != "wallet-1" || submitter.calls[0].PortfolioId != "portfolio-1" {
+		t.Fatalf("Expected one withdrawal from the user's wallet, got %+v", submitter.calls)
+	}
+	receipt, err := ledger.GetWithdrawalReceipt(ctx, "activity-1")
+	if err != nil || receipt.UserId != "user-1" || receipt.Destination != "0xexternal" {
+		t.Errorf("Expected a receipt for the submitted withdrawal, got %+v (%v)", receipt, err)
+	}
+
+	// Repeating the key returns the first withdrawal without debiting or calling Prime again
+	result, err = ledger.CreateWithdrawalForUser(ctx, req)
+	if err != nil {
+		t.Fatalf("Replayed withdrawal failed: %v", err)
+	}
+	if result.Status != models.WithdrawalReplayed || !result.Amount.Equal(decimal.NewFromInt(4)) || len(submitter.calls) != 1 {
+		t.Errorf("Expected a replay, got %+v after %d calls", result, len(submitter.calls))
+	}
+
+	// A user id works in place of the email
+	req.User = "user-1"
+	req.IdempotencyKey = ""
+	req.Amount = decimal.NewFromInt(7)
+	if _, err := ledger.CreateWithdrawalForUser(ctx, req); !errors.Is(err, database.ErrInsufficientBalance) {
+		t.Errorf("Expected insufficient balance, got %v", err)
+	}
+	if len(submitter.calls) != 1 {
+		t.Errorf("Expected no Prime call for an unfunded withdrawal, got %d calls", len(submitter.calls))
+	}
+
+	// The withdrawal is held until Prime completes it, so only the available balance has dropped
+	balance, err := db.GetUserBalance(ctx, "user-1", "ETH")
+	if err != nil || !balance.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Expected balance 10, got %s (%v)", balance, err)
+	}
+	available, err := db.GetAvailableBalance(ctx, "user-1", "ETH")
+	if err != nil || !available.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("Expected available balance 6, got %s (%v)", available, err)
+	}
+}
+
+func TestCreateWithdrawalForUser_RollsBackOnPrimeFailure(t *testing.T) {
+	ledger, submitter, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	primeErr := errors.New("prime unavailable")
+	submitter.err = primeErr
+	_, err := ledger.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+		User:        "alice@example.com",
+		Asset:       "ETH-ethereum-mainnet",
+		Amount:      decimal.NewFromInt(4),
+		Destination: "0xexternal",
+	})
+	if !errors.Is(err, primeErr) {
+		t.Fatalf("Expected the Prime error, got %v", err)
+	}
+
+	balance, err := db.GetAvailableBalance(ctx, "user-1", "ETH")
+	if err != nil || !balance.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Expected the hold to be released back to 10, got %s (%v)", balance, err)
+	}
+}
+
+func TestCreateWithdrawalForUser_CapturesWalletTransfers(t *testing.T) {
+	ledger, _, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	// Prime does not report wallet transfers as withdrawals, so the listener would never capture them
+	_, err := ledger.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+		User:            "alice@example.com",
+		Asset:           "ETH-ethereum-mainnet",
+		Amount:          decimal.NewFromInt(4),
+		DestinationType: "wallet",
+		Destination:     "wallet-2",
+		IdempotencyKey:  "transfer-1",
+	})
+	if err != nil {
+		t.Fatalf("CreateWithdrawalForUser failed: %v", err)
+	}
+
+	hold, err := db.GetWithdrawalHold(ctx, "transfer-1")
+	if err != nil || hold == nil || hold.Status != database.WithdrawalHoldStatusCaptured {
+		t.Fatalf("Expected the transfer to be captured on submission, got %+v (%v)", hold, err)
+	}
+	balance, err := db.GetUserBalance(ctx, "user-1", "ETH")
+	if err != nil || !balance.Equal(decimal.NewFromInt(6)) {
+		t.Errorf("Expected balance 6, got %s (%v)", balance, err)
+	}
+}
+
+func TestCreateWithdrawalForUser_Queue(t *testing.T) {
+	ledger, submitter, db := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	result, err := ledger.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+		User:        "alice@example.com",
+		Asset:       "ETH-ethereum-mainnet",
+		Amount:      decimal.NewFromInt(3),
+		Destination: "0xexternal",
+		Queue:       true,
+	})
+	if err != nil {
+		t.Fatalf("Queued withdrawal failed: %v", err)
+	}
+	if result.Status != models.WithdrawalQueued || result.QueueId == "" || result.CorrelationId == "" || len(submitter.calls) != 0 {
+		t.Errorf("Expected a queued withdrawal without a Prime call, got %+v", result)
+	}
+
+	queued, err := db.ListQueuedWithdrawals(ctx, database.WithdrawalQueueStatusQueued, 10)
+	if err != nil || len(queued) != 1 || queued[0].WalletId != "wallet-1" || queued[0].CorrelationId != result.CorrelationId {
+		t.Errorf("Expected one queued withdrawal, got %+v (%v)", queued, err)
+	}
+}
+
+// crashWithdrawal runs a direct withdrawal of 4 ETH under withdrawal-1 that stops at step, as if the
+// process died there, and returns the request so it can be retried
+func crashWithdrawal(t *testing.T, ledger *LedgerService, submitter *fakeSubmitter, step models.WithdrawalStep) models.WithdrawalRequest {
+	t.Helper()
+	if step == models.WithdrawalStepRollback {
+		submitter.err = errors.New("prime unavailable")
+	}
+
+	crash := errors.New("simulated crash")
+	ledger.SetWithdrawalStepHook(func(reached models.WithdrawalStep, idempotencyKey string) error {
+		if reached == step {
+			return crash
+		}
+		return nil
+	})
+	req := models.WithdrawalRequest{
+		User:           "alice@example.com",
+		Asset:          "ETH-ethereum-mainnet",
+		Amount:         decimal.NewFromInt(4),
+		Destination:    "0xexternal",
+		IdempotencyKey: "withdrawal-1",
+	}
+	if _, err := ledger.CreateWithdrawalForUser(context.Background(), req); !errors.Is(err, crash) {
+		t.Fatalf("Expected the withdrawal to stop at the %s step, got %v", step, err)
+	}
+
+	// The process comes back with Prime reachable
+	ledger.SetWithdrawalStepHook(nil)
+	submitter.err = nil
+	return req
+}
+
+// assertWithdrawalState checks withdrawal-1's hold and that the user's 10 ETH is either available or held by it
+func assertWithdrawalState(t *testing.T, db *database.Service, wantHold string, wantAvailable int64) {
+	t.Helper()
+	ctx := context.Background()
+
+	hold, err := db.GetWithdrawalHold(ctx, "withdrawal-1")
+	if err != nil || hold == nil || hold.Status != wantHold {
+		t.Errorf("Expected a %s hold, got %+v (%v)", wantHold, hold, err)
+	}
+	available, err := db.GetAvailableBalance(ctx, "user-1", "ETH")
+	if err != nil || !available.Equal(decimal.NewFromInt(wantAvailable)) {
+		t.Errorf("Expected available balance %d, got %s (%v)", wantAvailable, available, err)
+	}
+	// Nothing is debited until Prime reports completion
+	balance, err := db.GetUserBalance(ctx, "user-1", "ETH")
+	if err != nil || !balance.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("Expected balance 10 with no debit, got %s (%v)", balance, err)
+	}
+	held, err := db.HeldWithdrawalTotals(ctx)
+	if err != nil || !available.Add(held["ETH"]).Equal(balance) {
+		t.Errorf("Expected available %s and held %s to add up to balance %s (%v)", available, held["ETH"], balance, err)
+	}
+}
+
+func TestCreateWithdrawalForUser_RetryAfterCrashAtEachStep(t *testing.T) {
+	for _, step := range []models.WithdrawalStep{models.WithdrawalStepReserved, models.WithdrawalStepSubmitted, models.WithdrawalStepRollback} {
+		t.Run(string(step), func(t *testing.T) {
+			ledger, submitter, db := setupWithdrawalTest(t)
+			ctx := context.Background()
+			req := crashWithdrawal(t, ledger, submitter, step)
+
+			// The first request never recorded Prime's answer, so the retry sends the withdrawal under the
+			// same key; Prime pays out at most once per key
+			result, err := ledger.CreateWithdrawalForUser(ctx, req)
+			if err != nil || result.Status != models.WithdrawalSubmitted || result.ActivityId != "activity-1" {
+				t.Fatalf("Expected the retry to send the withdrawal, got %+v (%v)", result, err)
+			}
+			if len(submitter.accepted) != 1 || !submitter.accepted["withdrawal-1"] {
+				t.Errorf("Expected one withdrawal accepted by Prime, got %v", submitter.accepted)
+			}
+			request, err := db.GetWithdrawalRequest(ctx, "withdrawal-1")
+			if err != nil || request == nil || request.Status != database.WithdrawalRequestStatusSubmitted {
+				t.Errorf("Expected the request submitted, got %+v (%v)", request, err)
+			}
+			assertWithdrawalState(t, db, database.WithdrawalHoldStatusHeld, 6)
+
+			// Now that Prime has it, a further retry replays it
+			calls := len(submitter.calls)
+			result, err = ledger.CreateWithdrawalForUser(ctx, req)
+			if err != nil || result.Status != models.WithdrawalReplayed || len(submitter.calls) != calls {
+				t.Errorf("Expected a replay without a Prime call, got %+v (%v) after %d calls", result, err, len(submitter.calls))
+			}
+		})
+	}
+}
+
+func TestRecoverWithdrawals_AfterCrashAtEachStep(t *testing.T) {
+	tests := []struct {
+		step          models.WithdrawalStep
+		wantOutcome   string
+		wantHold      string
+		wantAvailable int64
+	}{
+		// Prime never received the withdrawal, so its hold is released
+		{models.WithdrawalStepReserved, models.WithdrawalRecoveryReleased, database.WithdrawalHoldStatusReleased, 10},
+		// Prime has it, so the hold stays for the listener to capture
+		{models.WithdrawalStepSubmitted, models.WithdrawalRecoverySubmitted, database.WithdrawalHoldStatusHeld, 6},
+		// Prime rejected it and the crash came before the hold was released
+		{models.WithdrawalStepRollback, models.WithdrawalRecoveryReleased, database.WithdrawalHoldStatusReleased, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.step), func(t *testing.T) {
+			ledger, submitter, db := setupWithdrawalTest(t)
+			ctx := context.Background()
+			crashWithdrawal(t, ledger, submitter, tt.step)
+
+			recoveries, err := ledger.RecoverWithdrawals(ctx, submitter, "portfolio-1", 0)
+			if err != nil || len(recoveries) != 1 || recoveries[0].Outcome != tt.wantOutcome {
+				t.Fatalf("Expected the withdrawal %s, got %+v (%v)", tt.wantOutcome, recoveries, err)
+			}
+			assertWithdrawalState(t, db, tt.wantHold, tt.wantAvailable)
+			if len(submitter.accepted) > 1 {
+				t.Errorf("Expected recovery to send nothing to Prime, got %v", submitter.accepted)
+			}
+
+			// Everything is resolved, so a second run finds nothing
+			recoveries, err = ledger.RecoverWithdrawals(ctx, submitter, "portfolio-1", 0)
+			if err != nil || len(recoveries) != 0 {
+				t.Errorf("Expected nothing left to recover, got %+v (%v)", recoveries, err)
+			}
+		})
+	}
+
+	// A withdrawal reserved moments ago may still be on its way to Prime
+	ledger, submitter, _ := setupWithdrawalTest(t)
+	crashWithdrawal(t, ledger, submitter, models.WithdrawalStepReserved)
+	recoveries, err := ledger.RecoverWithdrawals(context.Background(), submitter, "portfolio-1", time.Hour)
+	if err != nil || len(recoveries) != 0 {
+		t.Errorf("Expected a recent withdrawal to be skipped, got %+v (%v)", recoveries, err)
+	}
+}
+
+type fakeFeeEstimator struct{}
+
+func (fakeFeeEstimator) RecentNetworkFees(ctx context.Context, portfolioId, walletId string, since time.Time) ([]models.NetworkFeeEstimate, error) {
+	return []models.NetworkFeeEstimate{{Network: "ethereum-mainnet", FeeSymbol: "ETH", Samples: 2, Average: decimal.RequireFromString("0.001")}}, nil
+}
+
+func TestGetWithdrawalCapacity(t *testing.T) {
+	ledger, submitter, _ := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	capacity, err := ledger.GetWithdrawalCapacity(ctx, "user-1", "ETH")
+	if err != nil {
+		t.Fatalf("GetWithdrawalCapacity failed: %v", err)
+	}
+	if capacity.DailyLimit != nil || !capacity.Withdrawable.Equal(decimal.NewFromInt(10)) || capacity.EstimatedFees != nil {
+		t.Errorf("Expected the full balance without a limit or fees, got %+v", capacity)
+	}
+
+	ledger.SetWithdrawalLimits(map[string]decimal.Decimal{"ETH": decimal.NewFromInt(5)})
+	ledger.SetFeeEstimator(fakeFeeEstimator{}, "portfolio-1")
+
+	req := models.WithdrawalRequest{
+		User:        "alice@example.com",
+		Asset:       "ETH-ethereum-mainnet",
+		Amount:      decimal.NewFromInt(3),
+		Destination: "0xexternal",
+	}
+	if _, err := ledger.CreateWithdrawalForUser(ctx, req); err != nil {
+		t.Fatalf("CreateWithdrawalForUser failed: %v", err)
+	}
+
+	// A rolled back withdrawal does not use up the limit
+	submitter.err = errors.New("prime unavailable")
+	req.Amount = decimal.NewFromInt(1)
+	if _, err := ledger.CreateWithdrawalForUser(ctx, req); err == nil {
+		t.Fatal("Expected the Prime failure to be returned")
+	}
+	submitter.err = nil
+
+	capacity, err = ledger.GetWithdrawalCapacity(ctx, "user-1", "ETH")
+	if err != nil {
+		t.Fatalf("GetWithdrawalCapacity failed: %v", err)
+	}
+	if !capacity.Available.Equal(decimal.NewFromInt(7)) || !capacity.DailyLimitRemaining.Equal(decimal.NewFromInt(2)) || !capacity.Withdrawable.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("Expected 7 available and 2 withdrawable under the limit, got %+v", capacity)
+	}
+	if len(capacity.EstimatedFees) != 1 || capacity.EstimatedFees[0].Network != "ethereum-mainnet" {
+		t.Errorf("Expected a fee estimate, got %+v", capacity.EstimatedFees)
+	}
+
+	req.Amount = decimal.NewFromInt(3)
+	if _, err := ledger.CreateWithdrawalForUser(ctx, req); !errors.Is(err, ErrDailyLimitExceeded) {
+		t.Errorf("Expected the daily limit to be enforced, got %v", err)
+	}
+
+	if _, err := ledger.GetWithdrawalCapacity(ctx, "unknown", "ETH"); err == nil {
+		t.Error("Expected an unknown user to be rejected")
+	}
+}
+
+func TestProcessResults_CarrySentinelErrors(t *testing.T) {
+	ledger, _, _ := setupWithdrawalTest(t)
+	ctx := context.Background()
+
+	result, err := ledger.ProcessDeposit(ctx, "0xdeposit", "ETH", decimal.NewFromInt(10), "deposit-1", models.DepositSource{}, models.AvailabilityImmediate)
+	if err != nil {
+		t.Fatalf("ProcessDeposit failed: %v", err)
+	}
+	if result.Success || !errors.Is(result.Err, database.ErrDuplicateTransaction) {
+		t.Errorf("Expected ErrDuplicateTransaction for a replayed deposit, got %v", result.Err)
+	}
+
+	result, err = ledger.ProcessDeposit(ctx, "0xunknown", "ETH", decimal.NewFromInt(1), "deposit-2", models.DepositSource{}, models.AvailabilityImmediate)
+	if err != nil {
+		t.Fatalf("ProcessDeposit failed: %v", err)
+	}
+	if result.Success || !errors.Is(result.Err, database.ErrUserNotFound) {
+		t.Errorf("Expected ErrUserNotFound for an unknown address, got %v", result.Err)
+	}
+
+	if _, err := ledger.ProcessWithdrawal(ctx, "user-1", "ETH", decimal.NewFromInt(1), "withdrawal-1"); err != nil {
+		t.Fatalf("ProcessWithdrawal failed: %v", err)
+	}
+	result, err = ledger.ProcessWithdrawal(ctx, "user-1", "ETH", decimal.NewFromInt(1), "withdrawal-1")
+	if err != nil {
+		t.Fatalf("ProcessWithdrawal failed: %v", err)
+	}
+	if result.Success || !errors.Is(result.Err, database.ErrDuplicateTransaction) {
+		t.Errorf("Expected ErrDuplicateTransaction for a replayed withdrawal, got %v", result.Err)
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/httpapi"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ApiServer serves the REST API over the ledger on API_ADDR. Client routes are rate limited per IP and
+// per token and scoped to the token's user; POST /users takes API_ADMIN_TOKEN instead.
+type ApiServer struct {
+	rateLimiter  *httpapi.RateLimiter
+	balanceCache *api.BalanceCache
+	server       *http.Server
+	logger       *zap.Logger
+}
+
+// NewApiServer builds the API server. Withdrawals are queued for the withdrawal worker when
+// cfg.WithdrawalQueue is enabled and submitted to Prime directly otherwise.
+func NewApiServer(cfg *models.Config, services *common.Services, meta models.ServiceMeta, logger *zap.Logger) (*ApiServer, error) {
+	assets, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset config: %w", err)
+	}
+
+	db := services.DbService
+	ledger := newLedgerService(cfg, services, assets, logger)
+
+	s := &ApiServer{
+		rateLimiter: httpapi.NewRateLimiter(cfg.Api, logger),
+		logger:      logger,
+	}
+	if cfg.Api.BalanceCacheSize > 0 {
+		s.balanceCache = api.NewBalanceCache(cfg.Api.BalanceCacheSize)
+		ledger.SetBalanceCache(s.balanceCache)
+	}
+
+	// IP limits apply before authentication so that guessing tokens is throttled too
+	client := func(handler http.Handler) http.Handler {
+		return s.rateLimiter.LimitByIp(httpapi.RequireToken(db, logger, s.rateLimiter.LimitByToken(handler)))
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(httpapi.UserBalancesPattern, client(httpapi.UserBalancesHandler(ledger, logger)))
+	mux.Handle(httpapi.UserTransactionsPattern, client(httpapi.UserTransactionsHandler(ledger, logger)))
+	mux.Handle(httpapi.AddressesPattern, client(httpapi.AddressesHandler(db, logger)))
+	mux.Handle(httpapi.InstructionsPattern, client(httpapi.DepositInstructionsHandler(ledger, logger)))
+	mux.Handle(httpapi.WithdrawalsPattern, client(httpapi.Idempotency(db, logger,
+		httpapi.WithdrawalsHandler(ledger, assets, cfg.WithdrawalQueue.Enabled, logger))))
+	mux.Handle(httpapi.WithdrawalReceiptPattern, client(httpapi.WithdrawalReceiptHandler(ledger, logger)))
+	mux.Handle(httpapi.WithdrawalStatusPattern, client(httpapi.WithdrawalStatusHandler(db, logger)))
+	mux.Handle(httpapi.UserWithdrawalsPattern, client(httpapi.UserWithdrawalsHandler(db, logger)))
+	mux.Handle(httpapi.UsersPattern, s.rateLimiter.LimitByIp(httpapi.RequireAdminToken(cfg.Api.AdminToken,
+		httpapi.CreateUserHandler(db, logger))))
+	mux.Handle(httpapi.MetaPattern, httpapi.MetaHandler(meta))
+
+	s.server = &http.Server{
+		Addr:              cfg.Api.Addr,
+		Handler:           httpapi.StampVersion(meta, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+// newLedgerService builds the ledger the API servers call, submitting withdrawals to the default portfolio
+func newLedgerService(cfg *models.Config, services *common.Services, assets []models.AssetConfig, logger *zap.Logger) *api.LedgerService {
+	ledger := api.NewLedgerService(services.DbService, logger.Named("ledger"))
+	ledger.SetWithdrawalSubmitter(services.Custody, services.DefaultPortfolio.Id)
+	ledger.SetFeeEstimator(services.PrimeService, services.DefaultPortfolio.Id)
+	ledger.SetWithdrawalLimits(cfg.Api.WithdrawalDailyLimits)
+	ledger.SetAssetConfigs(assets, cfg.Listener.FundsAvailability)
+	ledger.SetExplorer(cfg.Explorer)
+	if cfg.Wallet.LazyAddresses {
+		ledger.SetAddressProvisioner(services.Custody, services.DefaultPortfolio.Id, services.Wallets)
+		ledger.SetLazyAddresses(true)
+	}
+	return ledger
+}
+
+func (s *ApiServer) Name() string { return "api" }
+
+// RateLimiter returns the server's rate limiter, for exporting its throttle counts
+func (s *ApiServer) RateLimiter() *httpapi.RateLimiter { return s.rateLimiter }
+
+// BalanceCache returns the server's balance cache, or nil when API_BALANCE_CACHE_SIZE is zero
+func (s *ApiServer) BalanceCache() *api.BalanceCache { return s.balanceCache }
+
+// Start binds the listen address so that a port conflict fails startup, then serves in the background
+func (s *ApiServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("API server failed", zap.Error(err))
+		}
+	}()
+
+	s.logger.Info("API server listening", zap.String("addr", s.server.Addr))
+	return nil
+}
+
+// Stop shuts the server down, waiting briefly for in-flight requests
+func (s *ApiServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Warn("API server shutdown failed", zap.Error(err))
+	}
+}
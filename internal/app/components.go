@@ -0,0 +1,225 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/listener"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/notify"
+	"prime-send-receive-go/internal/treasury"
+
+	"go.uber.org/zap"
+)
+
+// Dependencies are the shared services components are built from
+type Dependencies struct {
+	Config      *models.Config
+	Services    *common.Services
+	Coordinator coordination.Store
+}
+
+// NewListener builds the deposit and withdrawal listener. The listener is returned as well so
+// that the webhook receiver can feed pushed transactions into it.
+func NewListener(deps Dependencies) (*listener.SendReceiveListener, Component) {
+	cfg := deps.Config
+	logger := deps.Services.Logger
+	sendReceiveListener := listener.NewSendReceiveListener(listener.SendReceiveListenerConfig{
+		Custody:             deps.Services.Custody,
+		ApiService:          api.NewLedgerService(deps.Services.DbService, logger.Named("ledger")),
+		DbService:           deps.Services.DbService,
+		PortfolioId:         deps.Services.DefaultPortfolio.Id,
+		LookbackWindow:      cfg.Listener.LookbackWindow,
+		PollingInterval:     cfg.Listener.PollingInterval,
+		CleanupInterval:     cfg.Listener.CleanupInterval,
+		MaxConcurrency:      cfg.Listener.MaxConcurrency,
+		StartupScanWindow:   cfg.Listener.StartupScanWindow,
+		Coordinator:         deps.Coordinator,
+		InstanceId:          cfg.Coordination.InstanceId,
+		WalletLeaseTTL:      cfg.Coordination.WalletLeaseTTL,
+		FundsAvailability:   cfg.Listener.FundsAvailability,
+		TransactionTypes:    cfg.Listener.TransactionTypes,
+		FullScanInterval:    cfg.Listener.FullScanInterval,
+		PageLimit:           cfg.Listener.PageLimit,
+		IdlePollingInterval: cfg.Listener.IdlePollingInterval,
+		Logger:              logger.Named("listener"),
+	})
+
+	if poster := notify.NewWebhookPoster(cfg.Notify); poster != nil {
+		deps.Services.DbService.SetUnattributedDepositHandler(unattributedDepositWebhook(poster, logger.Named("listener")))
+	}
+
+	return sendReceiveListener, NewComponent("listener",
+		func(ctx context.Context) error { return sendReceiveListener.Start(ctx, cfg.Listener.AssetsFile) },
+		sendReceiveListener.Stop)
+}
+
+// unattributedDepositWebhook alerts on a deposit to an unrecognized address and posts it to
+// NOTIFY_WEBHOOK_URL. The deposit is already stored, so a failed post is logged and not retried.
+func unattributedDepositWebhook(poster *notify.WebhookPoster, logger *zap.Logger) database.UnattributedDepositHandler {
+	return func(ctx context.Context, deposit models.UnattributedDeposit) {
+		log := correlation.Logger(ctx, logger)
+		log.Error("ALERT: deposit to unrecognized address - resolve with cmd/claims",
+			zap.String("unattributed_id", deposit.Id),
+			zap.String("external_tx_id", deposit.ExternalTransactionId),
+			zap.String("address", deposit.Address),
+			zap.String("asset", deposit.Asset),
+			zap.String("amount", deposit.Amount.String()))
+
+		if err := poster.Post(ctx, notify.EventUnattributedDeposit, deposit); err != nil {
+			log.Error("Failed to post unattributed deposit webhook",
+				zap.String("unattributed_id", deposit.Id),
+				zap.Error(err))
+		}
+	}
+}
+
+// NewWithdrawalWorker builds the withdrawal queue worker, with automatic vault top-ups when enabled
+func NewWithdrawalWorker(deps Dependencies) Component {
+	cfg := deps.Config
+	services := deps.Services
+
+	var treasuryService *treasury.Service
+	if cfg.Treasury.AutoTopUp {
+		treasuryService = treasury.NewService(services.DbService, services.PrimeService, services.DefaultPortfolio.Id, cfg.Treasury,
+			services.Logger.Named("treasury"))
+	}
+
+	withdrawalWorker := listener.NewWithdrawalWorker(listener.WithdrawalWorkerConfig{
+		Custody:        services.Custody,
+		DbService:      services.DbService,
+		PortfolioId:    services.DefaultPortfolio.Id,
+		PollInterval:   cfg.WithdrawalQueue.PollInterval,
+		SubmitInterval: cfg.WithdrawalQueue.SubmitInterval,
+		BatchSize:      cfg.WithdrawalQueue.BatchSize,
+		MaxAttempts:    cfg.WithdrawalQueue.MaxAttempts,
+		RetryBackoff:   cfg.WithdrawalQueue.RetryBackoff,
+		MaxPerMinute:   cfg.WithdrawalQueue.MaxPerMinute,
+		BatchWindow:    cfg.WithdrawalQueue.BatchWindow,
+		Coordinator:    deps.Coordinator,
+		Treasury:       treasuryService,
+		StepHook:       common.CrashDrillHook(cfg.WithdrawalQueue.CrashAt, services.Logger),
+		Logger:         services.Logger.Named("withdrawal-worker"),
+	})
+
+	return NewComponent("withdrawal-worker", withdrawalWorker.Start, withdrawalWorker.Stop)
+}
+
+// NewInterestJob builds the daily interest accrual job
+func NewInterestJob(deps Dependencies) Component {
+	cfg := deps.Config
+	interestJob := listener.NewInterestJob(listener.InterestJobConfig{
+		DbService:     deps.Services.DbService,
+		Rates:         cfg.Interest.Rates,
+		MinBalance:    cfg.Interest.MinBalance,
+		RunHour:       cfg.Interest.RunHour,
+		CheckInterval: cfg.Interest.CheckInterval,
+		Logger:        deps.Services.Logger.Named("interest-job"),
+	})
+
+	return NewComponent("interest-job",
+		func(ctx context.Context) error {
+			interestJob.Start(ctx)
+			return nil
+		},
+		interestJob.Stop)
+}
+
+// NewReconciliationJob builds the periodic balance reconciliation job. The job is returned as well so
+// that its results can be exported as metrics.
+func NewReconciliationJob(deps Dependencies) (*listener.ReconciliationJob, Component) {
+	reconciliationJob := listener.NewReconciliationJob(deps.Services.DbService, deps.Config.Serve.ReconciliationInterval,
+		deps.Services.Logger.Named("reconciliation-job"))
+
+	return reconciliationJob, NewComponent("reconciliation-job",
+		func(ctx context.Context) error {
+			reconciliationJob.Start(ctx)
+			return nil
+		},
+		reconciliationJob.Stop)
+}
+
+// NewMaintenanceJob builds the periodic SQLite maintenance job
+func NewMaintenanceJob(deps Dependencies) Component {
+	cfg := deps.Config.Maintenance
+	maintenanceJob := listener.NewMaintenanceJob(deps.Services.DbService, cfg.Interval, cfg.VacuumFreeRatio, cfg.Retention,
+		deps.Services.Logger.Named("maintenance-job"))
+
+	return NewComponent("maintenance-job",
+		func(ctx context.Context) error {
+			maintenanceJob.Start(ctx)
+			return nil
+		},
+		maintenanceJob.Stop)
+}
+
+// NewInvariantJob builds the ledger invariant monitor. notifier may be nil, in which case violations
+// are only logged.
+func NewInvariantJob(deps Dependencies, notifier notify.Notifier) Component {
+	invariantJob := listener.NewInvariantJob(deps.Services.DbService, notifier, deps.Config.Serve.InvariantCheckInterval,
+		deps.Services.Logger.Named("invariant-monitor"))
+	return NewComponent("invariant-monitor",
+		func(ctx context.Context) error {
+			invariantJob.Start(ctx)
+			return nil
+		},
+		invariantJob.Stop)
+}
+
+// NewVelocityJob builds the per-user velocity anomaly detector. New review cases are emailed when
+// notifier is set and posted to NOTIFY_WEBHOOK_URL when that is configured.
+func NewVelocityJob(deps Dependencies, notifier notify.Notifier) Component {
+	velocityJob := listener.NewVelocityJob(deps.Services.DbService, notifier, notify.NewWebhookPoster(deps.Config.Notify),
+		deps.Config.Anomaly, deps.Services.Logger.Named("velocity-monitor"))
+	return NewComponent("velocity-monitor",
+		func(ctx context.Context) error {
+			velocityJob.Start(ctx)
+			return nil
+		},
+		velocityJob.Stop)
+}
+
+// NewDigestJob builds the daily notification digest job. reconciliationJob may be nil when reconciliation
+// does not run in this process.
+func NewDigestJob(deps Dependencies, notifier notify.Notifier, reconciliationJob *listener.ReconciliationJob) Component {
+	cfg := deps.Config
+	services := deps.Services
+	treasuryService := treasury.NewService(services.DbService, services.PrimeService, services.DefaultPortfolio.Id, cfg.Treasury,
+		services.Logger.Named("treasury"))
+	digestJob := listener.NewDigestJob(listener.DigestJobConfig{
+		DbService:      services.DbService,
+		Channels:       map[string]notify.Notifier{"email": notifier},
+		RunHour:        cfg.Notify.DigestHour,
+		CheckInterval:  cfg.Notify.DigestCheckInterval,
+		Reconciliation: reconciliationJob,
+		Treasury:       treasuryService,
+		Logger:         services.Logger.Named("digest-job"),
+	})
+
+	return NewComponent("digest-job",
+		func(ctx context.Context) error {
+			digestJob.Start(ctx)
+			return nil
+		},
+		digestJob.Stop)
+}
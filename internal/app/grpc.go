@@ -0,0 +1,96 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/grpcapi"
+	"prime-send-receive-go/internal/models"
+	ledgerv1 "prime-send-receive-go/proto/ledger/v1"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// GrpcServer serves the ledger.v1.LedgerService gRPC API on GRPC_ADDR for internal services. Every call
+// must carry GRPC_TOKEN.
+type GrpcServer struct {
+	addr   string
+	server *grpc.Server
+	logger *zap.Logger
+}
+
+// NewGrpcServer builds the gRPC server. Withdrawals are queued for the withdrawal worker when
+// cfg.WithdrawalQueue is enabled and submitted to Prime directly otherwise.
+func NewGrpcServer(cfg *models.Config, services *common.Services, logger *zap.Logger) (*GrpcServer, error) {
+	assets, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load asset config: %w", err)
+	}
+
+	ledger := newLedgerService(cfg, services, assets, logger)
+	if cfg.Api.BalanceCacheSize > 0 {
+		ledger.SetBalanceCache(api.NewBalanceCache(cfg.Api.BalanceCacheSize))
+	}
+
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcapi.RequireToken(cfg.Api.GrpcToken)))
+	ledgerv1.RegisterLedgerServiceServer(server,
+		grpcapi.NewLedgerServer(ledger, services.DbService, assets, cfg.WithdrawalQueue.Enabled, logger))
+
+	return &GrpcServer{addr: cfg.Api.GrpcAddr, server: server, logger: logger}, nil
+}
+
+func (s *GrpcServer) Name() string { return "grpc" }
+
+// Start binds the listen address so that a port conflict fails startup, then serves in the background
+func (s *GrpcServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", s.addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil {
+			s.logger.Error("gRPC server failed", zap.Error(err))
+		}
+	}()
+
+	s.logger.Info("gRPC server listening", zap.String("addr", s.addr))
+	return nil
+}
+
+// Stop waits briefly for in-flight calls, then closes the remaining connections
+func (s *GrpcServer) Stop() {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		s.logger.Warn("gRPC server did not stop in time, closing connections")
+		s.server.Stop()
+	}
+}
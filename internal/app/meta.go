@@ -0,0 +1,65 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+
+	"prime-send-receive-go/internal/buildinfo"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+)
+
+// NewServiceMeta describes this deployment for /meta: the build and database schema version, the
+// components that are running and the enabled assets from ASSETS_FILE
+func NewServiceMeta(ctx context.Context, cfg *models.Config, dbService *database.Service, features []string) (models.ServiceMeta, error) {
+	schemaVersion, err := dbService.SchemaVersion(ctx)
+	if err != nil {
+		return models.ServiceMeta{}, err
+	}
+
+	assets, err := common.LoadAssetConfig(cfg.Listener.AssetsFile)
+	if err != nil {
+		return models.ServiceMeta{}, err
+	}
+
+	build := buildinfo.Get()
+	meta := models.ServiceMeta{
+		Version:       build.Version,
+		Commit:        build.Commit,
+		BuildDate:     build.BuildDate,
+		SchemaVersion: schemaVersion,
+		Features:      features,
+		Assets:        []models.MetaAsset{},
+	}
+	if meta.Features == nil {
+		meta.Features = []string{}
+	}
+	for _, asset := range assets {
+		if !asset.IsEnabled() {
+			continue
+		}
+		meta.Assets = append(meta.Assets, models.MetaAsset{
+			Symbol:      asset.Symbol,
+			Network:     asset.Network,
+			Deposits:    asset.DepositsEnabled(),
+			Withdrawals: asset.WithdrawalsEnabled(),
+		})
+	}
+	return meta, nil
+}
@@ -0,0 +1,274 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/httpapi"
+	"prime-send-receive-go/internal/listener"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const metricsPrefix = "prime_send_receive_"
+
+// MetricsServer exposes a /healthz liveness probe, a /readyz dependency check, ledger metrics in
+// the Prometheus text format on /metrics and, once SetMeta is called, the deployment's /meta
+type MetricsServer struct {
+	dbService      *database.Service
+	reconciliation *listener.ReconciliationJob
+	rateLimiter    *httpapi.RateLimiter
+	balanceCache   *api.BalanceCache
+	listener       *listener.SendReceiveListener
+	ledger         *api.LedgerService
+	mux            *http.ServeMux
+	server         *http.Server
+	logger         *zap.Logger
+}
+
+// NewMetricsServer creates a metrics server. reconciliation may be nil when the job is not running.
+func NewMetricsServer(addr string, dbService *database.Service, reconciliation *listener.ReconciliationJob, logger *zap.Logger) *MetricsServer {
+	m := &MetricsServer{
+		dbService:      dbService,
+		reconciliation: reconciliation,
+		logger:         logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", m.handleHealth)
+	mux.HandleFunc("/readyz", m.handleReady)
+	mux.HandleFunc("/metrics", m.handleMetrics)
+	m.mux = mux
+	m.server = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return m
+}
+
+func (m *MetricsServer) Name() string { return "metrics" }
+
+// SetRateLimiter exports the API rate limiter's throttle counts alongside the ledger metrics
+func (m *MetricsServer) SetRateLimiter(rateLimiter *httpapi.RateLimiter) {
+	m.rateLimiter = rateLimiter
+}
+
+// SetBalanceCache exports the API balance cache's hit rate alongside the ledger metrics
+func (m *MetricsServer) SetBalanceCache(balanceCache *api.BalanceCache) {
+	m.balanceCache = balanceCache
+}
+
+// SetListener exports the listener's skipped transaction counts alongside the ledger metrics
+func (m *MetricsServer) SetListener(sendReceiveListener *listener.SendReceiveListener) {
+	m.listener = sendReceiveListener
+}
+
+// SetLedgerService runs the ledger health check on /readyz; without it /readyz only checks the database
+func (m *MetricsServer) SetLedgerService(ledger *api.LedgerService) {
+	m.ledger = ledger
+}
+
+// SetMeta serves meta on /meta and stamps every response with its version headers
+func (m *MetricsServer) SetMeta(meta models.ServiceMeta) {
+	m.mux.Handle(httpapi.MetaPattern, httpapi.MetaHandler(meta))
+	m.server.Handler = httpapi.StampVersion(meta, m.mux)
+}
+
+// Start binds the listen address so that a port conflict fails startup, then serves in the background
+func (m *MetricsServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", m.server.Addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", m.server.Addr, err)
+	}
+
+	go func() {
+		if err := m.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.logger.Error("Metrics server failed", zap.Error(err))
+		}
+	}()
+
+	m.logger.Info("Metrics server listening", zap.String("addr", m.server.Addr))
+	return nil
+}
+
+// Stop shuts the server down, waiting briefly for in-flight scrapes
+func (m *MetricsServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.server.Shutdown(ctx); err != nil {
+		m.logger.Warn("Metrics server shutdown failed", zap.Error(err))
+	}
+}
+
+func (m *MetricsServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+// handleReady reports each dependency as JSON, with 503 if any is unhealthy. Prime is only probed
+// with ?prime=true, so frequent readiness polls do not spend Prime API calls.
+func (m *MetricsServer) handleReady(w http.ResponseWriter, r *http.Request) {
+	ledger := m.ledger
+	if ledger == nil {
+		ledger = api.NewLedgerService(m.dbService, m.logger)
+	}
+	report := ledger.HealthCheck(r.Context(), r.URL.Query().Get("prime") == "true")
+
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		m.logger.Warn("Failed to write health report", zap.Error(err))
+	}
+}
+
+func (m *MetricsServer) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	queueCounts, err := m.dbService.CountQueuedWithdrawalsByStatus(ctx)
+	if err != nil {
+		m.logger.Error("Failed to read withdrawal queue for metrics", zap.Error(err))
+		http.Error(w, "failed to collect metrics", http.StatusInternalServerError)
+		return
+	}
+
+	negativeBalances, err := m.dbService.ListNegativeBalances(ctx)
+	if err != nil {
+		m.logger.Error("Failed to read negative balances for metrics", zap.Error(err))
+		http.Error(w, "failed to collect metrics", http.StatusInternalServerError)
+		return
+	}
+
+	var reconciliation *listener.ReconciliationResult
+	if m.reconciliation != nil {
+		result := m.reconciliation.LastResult()
+		reconciliation = &result
+	}
+
+	var throttled map[string]uint64
+	if m.rateLimiter != nil {
+		throttled = m.rateLimiter.Throttled()
+	}
+
+	var cacheStats *api.BalanceCacheStats
+	if m.balanceCache != nil {
+		stats := m.balanceCache.Stats()
+		cacheStats = &stats
+	}
+
+	var skipped map[string]uint64
+	if m.listener != nil {
+		skipped = m.listener.SkippedTransactions()
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeMetrics(w, queueCounts, len(negativeBalances), throttled, cacheStats, skipped, reconciliation)
+}
+
+// writeMetrics renders the metrics in the Prometheus text exposition format
+func writeMetrics(w io.Writer, queueCounts map[string]int, negativeBalances int, throttled map[string]uint64, cacheStats *api.BalanceCacheStats, skipped map[string]uint64, reconciliation *listener.ReconciliationResult) {
+	fmt.Fprintf(w, "# HELP %swithdrawal_queue Withdrawals in the queue by status\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %swithdrawal_queue gauge\n", metricsPrefix)
+	statuses := make([]string, 0, len(queueCounts))
+	for status := range queueCounts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	for _, status := range statuses {
+		fmt.Fprintf(w, "%swithdrawal_queue{status=%q} %d\n", metricsPrefix, status, queueCounts[status])
+	}
+
+	fmt.Fprintf(w, "# HELP %snegative_balances Accounts with a negative balance\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %snegative_balances gauge\n", metricsPrefix)
+	fmt.Fprintf(w, "%snegative_balances %d\n", metricsPrefix, negativeBalances)
+
+	if throttled != nil {
+		fmt.Fprintf(w, "# HELP %sapi_throttled_requests_total API requests rejected by rate limiting\n", metricsPrefix)
+		fmt.Fprintf(w, "# TYPE %sapi_throttled_requests_total counter\n", metricsPrefix)
+		for _, scope := range []string{httpapi.ScopeIp, httpapi.ScopeToken} {
+			fmt.Fprintf(w, "%sapi_throttled_requests_total{scope=%q} %d\n", metricsPrefix, scope, throttled[scope])
+		}
+	}
+
+	if cacheStats != nil {
+		fmt.Fprintf(w, "# HELP %sapi_balance_cache_requests_total API balance lookups by cache result\n", metricsPrefix)
+		fmt.Fprintf(w, "# TYPE %sapi_balance_cache_requests_total counter\n", metricsPrefix)
+		fmt.Fprintf(w, "%sapi_balance_cache_requests_total{result=\"hit\"} %d\n", metricsPrefix, cacheStats.Hits)
+		fmt.Fprintf(w, "%sapi_balance_cache_requests_total{result=\"miss\"} %d\n", metricsPrefix, cacheStats.Misses)
+		fmt.Fprintf(w, "# HELP %sapi_balance_cache_invalidations_total Cached balances dropped because the ledger changed\n", metricsPrefix)
+		fmt.Fprintf(w, "# TYPE %sapi_balance_cache_invalidations_total counter\n", metricsPrefix)
+		fmt.Fprintf(w, "%sapi_balance_cache_invalidations_total %d\n", metricsPrefix, cacheStats.Invalidations)
+		fmt.Fprintf(w, "# HELP %sapi_balance_cache_entries Balances currently cached\n", metricsPrefix)
+		fmt.Fprintf(w, "# TYPE %sapi_balance_cache_entries gauge\n", metricsPrefix)
+		fmt.Fprintf(w, "%sapi_balance_cache_entries %d\n", metricsPrefix, cacheStats.Entries)
+	}
+
+	if skipped != nil {
+		fmt.Fprintf(w, "# HELP %slistener_skipped_transactions_total Transactions skipped because their type is not in LISTENER_TRANSACTION_TYPES\n", metricsPrefix)
+		fmt.Fprintf(w, "# TYPE %slistener_skipped_transactions_total counter\n", metricsPrefix)
+		types := make([]string, 0, len(skipped))
+		for txType := range skipped {
+			types = append(types, txType)
+		}
+		sort.Strings(types)
+		for _, txType := range types {
+			fmt.Fprintf(w, "%slistener_skipped_transactions_total{type=%q} %d\n", metricsPrefix, txType, skipped[txType])
+		}
+	}
+
+	if reconciliation == nil || reconciliation.CompletedAt.IsZero() {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %sreconciliation_checked Balances checked by the last reconciliation run\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %sreconciliation_checked gauge\n", metricsPrefix)
+	fmt.Fprintf(w, "%sreconciliation_checked %d\n", metricsPrefix, reconciliation.Checked)
+	fmt.Fprintf(w, "# HELP %sreconciliation_mismatches Balances that did not match their history in the last run\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %sreconciliation_mismatches gauge\n", metricsPrefix)
+	fmt.Fprintf(w, "%sreconciliation_mismatches %d\n", metricsPrefix, reconciliation.Mismatches)
+	fmt.Fprintf(w, "# HELP %sreconciliation_last_run_timestamp_seconds When the last reconciliation run completed\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %sreconciliation_last_run_timestamp_seconds gauge\n", metricsPrefix)
+	fmt.Fprintf(w, "%sreconciliation_last_run_timestamp_seconds %d\n", metricsPrefix, reconciliation.CompletedAt.Unix())
+
+	if len(reconciliation.AssetTotals) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %sledger_liability Total customer balance per asset at the last reconciliation run\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %sledger_liability gauge\n", metricsPrefix)
+	for _, total := range reconciliation.AssetTotals {
+		fmt.Fprintf(w, "%sledger_liability{asset=%q} %s\n", metricsPrefix, total.Asset, total.TotalLiability.String())
+	}
+	fmt.Fprintf(w, "# HELP %sledger_holders Users with a positive balance per asset at the last reconciliation run\n", metricsPrefix)
+	fmt.Fprintf(w, "# TYPE %sledger_holders gauge\n", metricsPrefix)
+	for _, total := range reconciliation.AssetTotals {
+		fmt.Fprintf(w, "%sledger_holders{asset=%q} %d\n", metricsPrefix, total.Asset, total.Holders)
+	}
+}
@@ -0,0 +1,103 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Component is a long-running part of the service that the Runner starts and stops
+type Component interface {
+	Name() string
+	Start(ctx context.Context) error
+	Stop()
+}
+
+type funcComponent struct {
+	name  string
+	start func(ctx context.Context) error
+	stop  func()
+}
+
+// NewComponent adapts a pair of start/stop functions to a Component
+func NewComponent(name string, start func(ctx context.Context) error, stop func()) Component {
+	return &funcComponent{name: name, start: start, stop: stop}
+}
+
+func (c *funcComponent) Name() string                    { return c.name }
+func (c *funcComponent) Start(ctx context.Context) error { return c.start(ctx) }
+func (c *funcComponent) Stop()                           { c.stop() }
+
+// Runner starts components in the order they were added and stops them in reverse
+type Runner struct {
+	components []Component
+	started    []Component
+	logger     *zap.Logger
+}
+
+// NewRunner creates a runner that logs each component start and stop
+func NewRunner(logger *zap.Logger) *Runner {
+	return &Runner{logger: logger}
+}
+
+// Add registers a component to be started by Start
+func (r *Runner) Add(component Component) {
+	r.components = append(r.components, component)
+}
+
+// Start starts each component in turn. If one fails, the components already started are stopped.
+func (r *Runner) Start(ctx context.Context) error {
+	for _, component := range r.components {
+		r.logger.Info("Starting component", zap.String("component", component.Name()))
+		if err := component.Start(ctx); err != nil {
+			r.stopStarted()
+			return fmt.Errorf("failed to start %s: %w", component.Name(), err)
+		}
+		r.started = append(r.started, component)
+	}
+	return nil
+}
+
+// Stop stops the started components in reverse order, giving up after the timeout.
+// It reports whether every component stopped in time.
+func (r *Runner) Stop(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		r.stopStarted()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		r.logger.Warn("Forced shutdown after timeout", zap.Duration("timeout", timeout))
+		return false
+	}
+}
+
+func (r *Runner) stopStarted() {
+	for i := len(r.started) - 1; i >= 0; i-- {
+		r.logger.Info("Stopping component", zap.String("component", r.started[i].Name()))
+		r.started[i].Stop()
+	}
+	r.started = nil
+}
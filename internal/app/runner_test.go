@@ -0,0 +1,113 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/listener"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunner_StartsInOrderAndStopsInReverse(t *testing.T) {
+	var events []string
+	component := func(name string, startErr error) Component {
+		return NewComponent(name,
+			func(ctx context.Context) error {
+				events = append(events, "start "+name)
+				return startErr
+			},
+			func() { events = append(events, "stop "+name) })
+	}
+
+	runner := NewRunner(zaptest.NewLogger(t))
+	runner.Add(component("metrics", nil))
+	runner.Add(component("listener", nil))
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if !runner.Stop(time.Second) {
+		t.Fatal("Expected a graceful stop")
+	}
+
+	want := []string{"start metrics", "start listener", "stop listener", "stop metrics"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Events = %v, want %v", events, want)
+	}
+
+	// A failed start stops only what was already started
+	events = nil
+	failing := NewRunner(zaptest.NewLogger(t))
+	failing.Add(component("metrics", nil))
+	failing.Add(component("listener", errors.New("no wallets")))
+	failing.Add(component("interest-job", nil))
+	if err := failing.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "listener") {
+		t.Fatalf("Expected listener start failure, got %v", err)
+	}
+
+	want = []string{"start metrics", "start listener", "stop metrics"}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Events = %v, want %v", events, want)
+	}
+}
+
+func TestWriteMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	writeMetrics(&buf, map[string]int{"queued": 3, "failed": 1}, 2, map[string]uint64{"ip": 4}, &api.BalanceCacheStats{Hits: 9, Misses: 3}, map[string]uint64{"CONVERSION": 2}, &listener.ReconciliationResult{
+		CompletedAt: time.Unix(1700000000, 0),
+		Checked:     10,
+		Mismatches:  1,
+		AssetTotals: []models.AssetTotal{{Asset: "BTC", TotalLiability: decimal.RequireFromString("1.5"), Holders: 2}},
+	})
+
+	output := buf.String()
+	for _, line := range []string{
+		`prime_send_receive_withdrawal_queue{status="failed"} 1`,
+		`prime_send_receive_withdrawal_queue{status="queued"} 3`,
+		`prime_send_receive_negative_balances 2`,
+		`prime_send_receive_api_throttled_requests_total{scope="ip"} 4`,
+		`prime_send_receive_api_throttled_requests_total{scope="token"} 0`,
+		`prime_send_receive_api_balance_cache_requests_total{result="hit"} 9`,
+		`prime_send_receive_api_balance_cache_requests_total{result="miss"} 3`,
+		`prime_send_receive_listener_skipped_transactions_total{type="CONVERSION"} 2`,
+		`prime_send_receive_reconciliation_mismatches 1`,
+		`prime_send_receive_reconciliation_last_run_timestamp_seconds 1700000000`,
+		`prime_send_receive_ledger_liability{asset="BTC"} 1.5`,
+		`prime_send_receive_ledger_holders{asset="BTC"} 2`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected metrics to contain %q, got:\n%s", line, output)
+		}
+	}
+
+	// Reconciliation metrics are omitted until the first run completes
+	buf.Reset()
+	writeMetrics(&buf, nil, 0, nil, nil, nil, &listener.ReconciliationResult{})
+	if strings.Contains(buf.String(), "reconciliation") {
+		t.Errorf("Expected no reconciliation metrics before the first run, got:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,214 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/listener"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Webhook request headers. The signature is the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the shared secret.
+const (
+	webhookTimestampHeader = "X-Webhook-Timestamp"
+	webhookSignatureHeader = "X-Webhook-Signature"
+	maxWebhookBody         = 1 << 20
+	// correlationIdHeader returns the correlation id the delivery was logged under
+	correlationIdHeader = "X-Correlation-Id"
+)
+
+// TransactionHandler processes transactions pushed from upstream systems
+type TransactionHandler interface {
+	HandleTransaction(ctx context.Context, tx models.PrimeTransaction) error
+}
+
+// WebhookEvent is the body of a transaction webhook. Id identifies the delivery for replay
+// protection; redeliveries of the same event must reuse it.
+type WebhookEvent struct {
+	Id          string                  `json:"id"`
+	Transaction models.PrimeTransaction `json:"transaction"`
+}
+
+// WebhookServer accepts signed deposit and withdrawal events on /webhooks/transactions and
+// processes them like polled transactions
+type WebhookServer struct {
+	handler     TransactionHandler
+	coordinator coordination.Store
+	secret      []byte
+	tolerance   time.Duration
+	now         func() time.Time
+	server      *http.Server
+	logger      *zap.Logger
+}
+
+// NewWebhookServer creates a webhook receiver. Delivered event ids are remembered in the coordination
+// store, so replays are rejected across instances when it is shared.
+func NewWebhookServer(cfg models.WebhookConfig, handler TransactionHandler, coordinator coordination.Store, logger *zap.Logger) *WebhookServer {
+	s := &WebhookServer{
+		handler:     handler,
+		coordinator: coordinator,
+		secret:      []byte(cfg.Secret),
+		tolerance:   cfg.Tolerance,
+		now:         time.Now,
+		logger:      logger,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhooks/transactions", s.handleTransaction)
+	s.server = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+func (s *WebhookServer) Name() string { return "webhook" }
+
+// Start binds the listen address so that a port conflict fails startup, then serves in the background
+func (s *WebhookServer) Start(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("unable to listen on %s: %w", s.server.Addr, err)
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Webhook server failed", zap.Error(err))
+		}
+	}()
+
+	s.logger.Info("Webhook server listening", zap.String("addr", s.server.Addr))
+	return nil
+}
+
+// Stop shuts the server down, waiting briefly for in-flight deliveries
+func (s *WebhookServer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Warn("Webhook server shutdown failed", zap.Error(err))
+	}
+}
+
+func (s *WebhookServer) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.verify(r.Header.Get(webhookTimestampHeader), r.Header.Get(webhookSignatureHeader), body); err != nil {
+		s.logger.Warn("Rejected webhook", zap.String("remote_addr", r.RemoteAddr), zap.Error(err))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Id == "" || event.Transaction.Id == "" {
+		http.Error(w, "invalid event", http.StatusBadRequest)
+		return
+	}
+
+	// Keep the transaction exactly as delivered for forensics
+	var raw struct {
+		Transaction json.RawMessage `json:"transaction"`
+	}
+	if err := json.Unmarshal(body, &raw); err == nil {
+		event.Transaction.Raw = raw.Transaction
+	}
+
+	// The response carries the correlation id the transaction is processed and logged under
+	ctx, correlationId := correlation.Ensure(r.Context())
+	w.Header().Set(correlationIdHeader, correlationId)
+
+	eventKey := "webhook:" + event.Id
+	if delivered, err := s.coordinator.IsProcessed(ctx, eventKey); err == nil && delivered {
+		correlation.Logger(ctx, s.logger).Info("Ignoring replayed webhook", zap.String("event_id", event.Id))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := s.handler.HandleTransaction(ctx, event.Transaction); err != nil {
+		if errors.Is(err, listener.ErrUnknownWallet) || errors.Is(err, listener.ErrInvalidTransaction) {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+		// The sender retries; the ledger rejects anything already applied
+		correlation.Logger(ctx, s.logger).Error("Failed to process webhook",
+			zap.String("event_id", event.Id),
+			zap.String("transaction_id", event.Transaction.Id),
+			zap.Error(err))
+		http.Error(w, "processing failed", http.StatusInternalServerError)
+		return
+	}
+
+	// Events older than twice the tolerance fail the timestamp check, so that is as long as ids are kept
+	if err := s.coordinator.MarkProcessed(ctx, eventKey, 2*s.tolerance); err != nil {
+		correlation.Logger(ctx, s.logger).Warn("Failed to record webhook delivery", zap.String("event_id", event.Id), zap.Error(err))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// verify checks the signature and that the timestamp is recent, so a captured request cannot be replayed later
+func (s *WebhookServer) verify(timestamp, signature string, body []byte) error {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s", webhookTimestampHeader)
+	}
+
+	age := s.now().Sub(time.Unix(unix, 0))
+	if age > s.tolerance || age < -s.tolerance {
+		return fmt.Errorf("timestamp outside tolerance: %s", age)
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s", webhookSignatureHeader)
+	}
+
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type recordingHandler struct {
+	handled        []string
+	correlationIds []string
+}
+
+func (h *recordingHandler) HandleTransaction(ctx context.Context, tx models.PrimeTransaction) error {
+	h.handled = append(h.handled, tx.Id)
+	h.correlationIds = append(h.correlationIds, correlation.Id(ctx))
+	return nil
+}
+
+func TestWebhookServer_VerifiesSignatureAndRejectsReplays(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+	handler := &recordingHandler{}
+	server := NewWebhookServer(models.WebhookConfig{Secret: secret, Tolerance: 5 * time.Minute},
+		handler, coordination.NewMemoryStore(), zaptest.NewLogger(t))
+	now := time.Unix(1700000000, 0)
+	server.now = func() time.Time { return now }
+
+	body := `{"id":"evt-1","transaction":{"id":"tx-1","wallet_id":"w-1","type":"DEPOSIT"}}`
+	var responseCorrelationId string
+	deliver := func(timestamp time.Time, key string) int {
+		ts := strconv.FormatInt(timestamp.Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(key))
+		mac.Write([]byte(ts + "." + body))
+
+		req := httptest.NewRequest(http.MethodPost, "/webhooks/transactions", strings.NewReader(body))
+		req.Header.Set(webhookTimestampHeader, ts)
+		req.Header.Set(webhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		server.handleTransaction(rec, req)
+		responseCorrelationId = rec.Header().Get(correlationIdHeader)
+		return rec.Code
+	}
+
+	if code := deliver(now, "wrong-secret"); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a bad signature, got %d", code)
+	}
+	if code := deliver(now.Add(-10*time.Minute), secret); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a stale timestamp, got %d", code)
+	}
+	if code := deliver(now, secret); code != http.StatusOK {
+		t.Fatalf("Expected 200 for a valid delivery, got %d", code)
+	}
+	if len(handler.correlationIds) != 1 || responseCorrelationId == "" || handler.correlationIds[0] != responseCorrelationId {
+		t.Errorf("Expected the response to carry the processing correlation id, got %q and %v",
+			responseCorrelationId, handler.correlationIds)
+	}
+	if code := deliver(now, secret); code != http.StatusOK {
+		t.Errorf("Expected a redelivery to be acknowledged, got %d", code)
+	}
+
+	if len(handler.handled) != 1 || handler.handled[0] != "tx-1" {
+		t.Errorf("Expected tx-1 to be processed once, got %v", handler.handled)
+	}
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package buildinfo reports which build of the binary is running. Release builds inject the values with
+//
+//	go build -ldflags "-X prime-send-receive-go/internal/buildinfo.version=v1.2.3 \
+//	  -X prime-send-receive-go/internal/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X prime-send-receive-go/internal/buildinfo.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set at build time with -ldflags "-X"; see the package comment
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// Info identifies a build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the injected build information. Without ldflags the version is "dev", and the commit
+// and its time come from the VCS information Go embeds when building inside a repository (the commit
+// gets a "-dirty" suffix for uncommitted changes), or are "unknown".
+func Get() Info {
+	info := Info{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	if buildInfo, ok := debug.ReadBuildInfo(); ok && (info.Commit == "" || info.BuildDate == "") {
+		var revision, modified, revisionTime string
+		for _, setting := range buildInfo.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.modified":
+				modified = setting.Value
+			case "vcs.time":
+				revisionTime = setting.Value
+			}
+		}
+		if info.Commit == "" && revision != "" {
+			info.Commit = revision
+			if modified == "true" {
+				info.Commit += "-dirty"
+			}
+		}
+		if info.BuildDate == "" {
+			info.BuildDate = revisionTime
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// Version returns the build version, "dev" when it was not injected
+func Version() string {
+	return Get().Version
+}
@@ -21,19 +21,23 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/shopspring/decimal"
 	"gopkg.in/yaml.v2"
+	"prime-send-receive-go/internal/models"
 )
 
-type AssetConfig struct {
-	Symbol  string `yaml:"symbol"`
-	Network string `yaml:"network"`
+type AssetsConfig struct {
+	Assets []models.AssetConfig `yaml:"assets"`
 }
 
-type AssetsConfig struct {
-	Assets []AssetConfig `yaml:"assets"`
+// creditStatuses are the Prime deposit statuses an asset may be credited at
+var creditStatuses = map[string]bool{
+	"TRANSACTION_IMPORT_PENDING": true,
+	"TRANSACTION_IMPORTED":       true,
+	"TRANSACTION_DONE":           true,
 }
 
-func LoadAssetConfig(assetsFile string) ([]AssetConfig, error) {
+func LoadAssetConfig(assetsFile string) ([]models.AssetConfig, error) {
 	var assetsPath string
 	if filepath.IsAbs(assetsFile) {
 		assetsPath = assetsFile
@@ -51,31 +55,87 @@ func LoadAssetConfig(assetsFile string) ([]AssetConfig, error) {
 	}
 
 	var config AssetsConfig
-	if err := yaml.Unmarshal(data, &config); err != nil {
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
 		return nil, fmt.Errorf("unable to parse %s: %w", assetsFile, err)
 	}
 
-	for i, asset := range config.Assets {
+	seen := make(map[string]bool)
+	for i := range config.Assets {
+		asset := &config.Assets[i]
 		if asset.Symbol == "" {
 			return nil, fmt.Errorf("asset at index %d missing symbol", i)
 		}
 		if asset.Network == "" {
 			return nil, fmt.Errorf("asset at index %d missing network", i)
 		}
+		if seen[asset.AssetNetwork()] {
+			return nil, fmt.Errorf("asset %s is listed more than once", asset.AssetNetwork())
+		}
+		seen[asset.AssetNetwork()] = true
+
+		if err := parseAssetListenerConfig(&asset.Listener); err != nil {
+			return nil, fmt.Errorf("asset %s: %w", asset.AssetNetwork(), err)
+		}
 	}
 
 	return config.Assets, nil
 }
 
+// parseAssetListenerConfig validates the per-asset listener settings and parses the dust threshold
+func parseAssetListenerConfig(listener *models.AssetListenerConfig) error {
+	if listener.PollingInterval < 0 {
+		return fmt.Errorf("polling_interval must not be negative")
+	}
+	if listener.CreditStatus != "" && !creditStatuses[listener.CreditStatus] {
+		return fmt.Errorf("unsupported credit_status %q", listener.CreditStatus)
+	}
+	if listener.Availability != "" && !models.IsAvailabilityPolicy(listener.Availability) {
+		return fmt.Errorf("unsupported availability %q (expected immediate, done or review)", listener.Availability)
+	}
+	if listener.DustAmount != "" {
+		threshold, err := decimal.NewFromString(listener.DustAmount)
+		if err != nil {
+			return fmt.Errorf("invalid dust_threshold: %w", err)
+		}
+		if threshold.IsNegative() {
+			return fmt.Errorf("dust_threshold must not be negative")
+		}
+		listener.DustThreshold = threshold
+	}
+	return nil
+}
+
+// EnabledAssets returns the assets that are not disabled in assets.yaml
+func EnabledAssets(assets []models.AssetConfig) []models.AssetConfig {
+	enabled := make([]models.AssetConfig, 0, len(assets))
+	for _, asset := range assets {
+		if asset.IsEnabled() {
+			enabled = append(enabled, asset)
+		}
+	}
+	return enabled
+}
+
+// FindAssetConfig returns the assets.yaml entry for a symbol and network
+func FindAssetConfig(assets []models.AssetConfig, symbol, network string) (models.AssetConfig, bool) {
+	for _, asset := range assets {
+		if asset.Symbol == symbol && asset.Network == network {
+			return asset, true
+		}
+	}
+	return models.AssetConfig{}, false
+}
+
 func LoadAssetSymbols(assetsFile string) ([]string, error) {
 	assets, err := LoadAssetConfig(assetsFile)
 	if err != nil {
 		return nil, err
 	}
 
-	symbols := make([]string, len(assets))
-	for i, asset := range assets {
-		symbols[i] = fmt.Sprintf("%s-%s", asset.Symbol, asset.Network)
+	enabled := EnabledAssets(assets)
+	symbols := make([]string, len(enabled))
+	for i, asset := range enabled {
+		symbols[i] = asset.AssetNetwork()
 	}
 
 	return symbols, nil
@@ -0,0 +1,109 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func writeAssetsFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "assets.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write assets file: %v", err)
+	}
+	return path
+}
+
+func TestLoadAssetConfig_ListenerSettings(t *testing.T) {
+	path := writeAssetsFile(t, `
+assets:
+  - symbol: "USDC"
+    network: "base-mainnet"
+  - symbol: "BTC"
+    network: "bitcoin-mainnet"
+    listener:
+      polling_interval: 2m
+      credit_status: TRANSACTION_DONE
+      dust_threshold: "0.0001"
+      withdrawals_enabled: false
+  - symbol: "SOL"
+    network: "solana-mainnet"
+    listener:
+      enabled: false
+`)
+
+	assets, err := LoadAssetConfig(path)
+	if err != nil {
+		t.Fatalf("Failed to load assets: %v", err)
+	}
+
+	usdc, _ := FindAssetConfig(assets, "USDC", "base-mainnet")
+	if !usdc.DepositsEnabled() || !usdc.WithdrawalsEnabled() || usdc.CreditStatus() != "TRANSACTION_IMPORTED" {
+		t.Errorf("Expected defaults for USDC, got %+v", usdc.Listener)
+	}
+
+	btc, ok := FindAssetConfig(assets, "BTC", "bitcoin-mainnet")
+	if !ok {
+		t.Fatal("Expected BTC to be configured")
+	}
+	if btc.Listener.PollingInterval != 2*time.Minute {
+		t.Errorf("Expected 2m polling interval, got %s", btc.Listener.PollingInterval)
+	}
+	if btc.CreditStatus() != "TRANSACTION_DONE" {
+		t.Errorf("Expected TRANSACTION_DONE credit status, got %s", btc.CreditStatus())
+	}
+	if !btc.Listener.DustThreshold.Equal(decimal.RequireFromString("0.0001")) {
+		t.Errorf("Expected dust threshold 0.0001, got %s", btc.Listener.DustThreshold)
+	}
+	if !btc.DepositsEnabled() || btc.WithdrawalsEnabled() {
+		t.Error("Expected BTC deposits enabled and withdrawals disabled")
+	}
+
+	enabled := EnabledAssets(assets)
+	if len(enabled) != 2 {
+		t.Errorf("Expected SOL to be excluded from enabled assets, got %d", len(enabled))
+	}
+}
+
+func TestLoadAssetConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  string
+	}{
+		{"unknown credit status", "assets:\n  - {symbol: BTC, network: bitcoin-mainnet, listener: {credit_status: SETTLED}}\n", "unsupported credit_status"},
+		{"negative dust", "assets:\n  - {symbol: BTC, network: bitcoin-mainnet, listener: {dust_threshold: \"-1\"}}\n", "must not be negative"},
+		{"duplicate asset", "assets:\n  - {symbol: BTC, network: bitcoin-mainnet}\n  - {symbol: BTC, network: bitcoin-mainnet}\n", "more than once"},
+		{"unknown field", "assets:\n  - {symbol: BTC, network: bitcoin-mainnet, listener: {min_confirmations: 3}}\n", "unable to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadAssetConfig(writeAssetsFile(t, tt.contents))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
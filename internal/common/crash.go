@@ -0,0 +1,46 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"os"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// CrashDrillHook returns a withdrawal step hook that exits the process as a withdrawal reaches step,
+// without running deferred cleanup, or nil when step is empty. It backs WITHDRAWAL_CRASH_AT, which
+// rehearses recovery from a crash at each step of a withdrawal against a test database.
+func CrashDrillHook(step models.WithdrawalStep, logger *zap.Logger) models.WithdrawalStepHook {
+	if step == "" {
+		return nil
+	}
+
+	return func(reached models.WithdrawalStep, idempotencyKey string) error {
+		if reached != step {
+			return nil
+		}
+		logger.Error("Crash drill - exiting at withdrawal step",
+			zap.String("step", string(reached)),
+			zap.String("idempotency_key", idempotencyKey))
+		_ = logger.Sync()
+		os.Exit(3)
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the YYYY-MM-DD form commands accept for dates
+const DateLayout = "2006-01-02"
+
+// ParseTimeFlag parses a date or time flag given as YYYY-MM-DD (midnight UTC) or RFC3339. An empty
+// value is the zero time. name is the flag name, used in the error.
+func ParseTimeFlag(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(DateLayout, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: use YYYY-MM-DD or RFC3339", name, value)
+	}
+	return t, nil
+}
@@ -17,7 +17,10 @@
 package common
 
 import (
+	"flag"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -27,14 +30,53 @@ const (
 	WideWidth    = 100
 )
 
+var (
+	// plainOutput replaces box-drawing characters and arrows with ASCII
+	plainOutput bool
+	// widthOverride, when positive, replaces every report width
+	widthOverride int
+)
+
+// RegisterOutputFlags adds --plain and --width to a command's flag set
+func RegisterOutputFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&plainOutput, "plain", false, "Use plain ASCII instead of box-drawing characters")
+	fs.IntVar(&widthOverride, "width", 0, "Report width in columns (default: fit the terminal)")
+}
+
+// PlainOutput reports whether --plain was requested, for output the helpers do not cover
+func PlainOutput() bool {
+	return plainOutput
+}
+
+// reportWidth resolves the width a helper should draw: --width if set, otherwise the requested
+// width narrowed to the terminal so separators do not wrap
+func reportWidth(width int) int {
+	if widthOverride > 0 {
+		return widthOverride
+	}
+	if columns := terminalWidth(); columns > 0 && columns < width {
+		return columns
+	}
+	return width
+}
+
+// terminalWidth returns the width of the terminal on stdout, or 0 when stdout is not a terminal.
+// COLUMNS takes precedence so the width can be pinned from the environment.
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return stdoutTerminalWidth()
+}
+
 // PrintSeparator prints a separator line with the specified character and width
 func PrintSeparator(char string, width int) {
-	fmt.Println(strings.Repeat(char, width))
+	fmt.Println(strings.Repeat(char, reportWidth(width)))
 }
 
 // PrintSeparatorNewline prints a separator with a newline before it
 func PrintSeparatorNewline(char string, width int) {
-	fmt.Println("\n" + strings.Repeat(char, width))
+	fmt.Println("\n" + strings.Repeat(char, reportWidth(width)))
 }
 
 // PrintHeader prints a formatted header with title and separators
@@ -48,16 +90,34 @@ func PrintHeader(title string, width int) {
 func PrintFooter(message string, width int) {
 	PrintSeparatorNewline("=", width)
 	fmt.Println(message)
-	fmt.Println(strings.Repeat("=", width) + "\n")
+	fmt.Println(strings.Repeat("=", reportWidth(width)) + "\n")
 }
 
 // PrintBoxSeparator prints a box-drawing separator line (for sub-sections)
 func PrintBoxSeparator(width int) {
-	fmt.Println("├" + strings.Repeat("─", width))
+	if plainOutput {
+		fmt.Println("+" + strings.Repeat("-", reportWidth(width)))
+		return
+	}
+	fmt.Println("├" + strings.Repeat("─", reportWidth(width)))
+}
+
+// BoxTopPrefix returns the prefix for the first line of a box, such as a user header
+func BoxTopPrefix() string {
+	if plainOutput {
+		return "+-"
+	}
+	return "┌─"
 }
 
 // BoxPrefix returns the appropriate box-drawing prefix for list items
 func BoxPrefix(isLast bool) string {
+	if plainOutput {
+		if isLast {
+			return "`  "
+		}
+		return "|  "
+	}
 	if isLast {
 		return "└  "
 	}
@@ -69,5 +129,30 @@ func BoxDetailPrefix(isLast bool) string {
 	if isLast {
 		return "   "
 	}
+	if plainOutput {
+		return "|  "
+	}
 	return "│  "
 }
+
+// Arrow returns the arrow used between a label and its value
+func Arrow() string {
+	if plainOutput {
+		return "->"
+	}
+	return "→"
+}
+
+// StatusMark returns a check or cross for a success or failure line
+func StatusMark(ok bool) string {
+	switch {
+	case plainOutput && ok:
+		return "[ok]"
+	case plainOutput:
+		return "[x]"
+	case ok:
+		return "✓"
+	default:
+		return "✗"
+	}
+}
@@ -0,0 +1,54 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import "testing"
+
+func TestReportWidth(t *testing.T) {
+	defer func() { widthOverride = 0 }()
+
+	t.Setenv("COLUMNS", "60")
+	if got := reportWidth(DefaultWidth); got != 60 {
+		t.Errorf("reportWidth in a 60 column terminal = %d, want 60", got)
+	}
+	if got := reportWidth(40); got != 40 {
+		t.Errorf("reportWidth narrower than the terminal = %d, want 40", got)
+	}
+
+	widthOverride = 120
+	if got := reportWidth(DefaultWidth); got != 120 {
+		t.Errorf("reportWidth with --width = %d, want 120", got)
+	}
+}
+
+func TestPlainOutput(t *testing.T) {
+	defer func() { plainOutput = false }()
+
+	if BoxPrefix(false) != "│  " || Arrow() != "→" {
+		t.Errorf("Expected box-drawing output by default")
+	}
+
+	plainOutput = true
+	for _, s := range []string{BoxPrefix(false), BoxPrefix(true), BoxDetailPrefix(false), BoxTopPrefix(), Arrow(), StatusMark(true), StatusMark(false)} {
+		for _, r := range s {
+			if r > 127 {
+				t.Errorf("Expected ASCII in plain mode, got %q", s)
+				break
+			}
+		}
+	}
+}
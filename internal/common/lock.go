@@ -0,0 +1,179 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// CommandLockName is the advisory lock ledger-writing commands hold while they run
+	CommandLockName = "ledger-write"
+
+	// commandLockTtl bounds how long a crashed command can keep the lock; holders refresh it well before then
+	commandLockTtl       = 30 * time.Second
+	commandLockRefresh   = 10 * time.Second
+	commandLockRetryWait = 2 * time.Second
+)
+
+// lockWait is how long to wait for another command to release the lock; zero fails immediately
+var lockWait time.Duration
+
+// RegisterLockFlags adds --wait to a command's flag set
+func RegisterLockFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&lockWait, "wait", 0, "Wait up to this long for another operation to finish (e.g. 2m) instead of failing")
+}
+
+// CommandLock is the ledger write lock held by a running command. Commands that write to the ledger
+// take it so two of them never interleave; it is refreshed in the background until Release.
+type CommandLock struct {
+	dbService *database.Service
+	lock      models.OperationLock
+	logger    *zap.Logger
+	stop      chan struct{}
+	stopped   chan struct{}
+	once      sync.Once
+}
+
+// AcquireCommandLock takes the ledger write lock for operation. If another command holds it, it waits
+// as long as --wait allows and then fails with an error wrapping database.ErrOperationInProgress.
+// A lock left behind by a process on this host that no longer runs is taken over.
+func AcquireCommandLock(ctx context.Context, dbService *database.Service, operation string, logger *zap.Logger) (*CommandLock, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	lock := models.OperationLock{
+		Name:      CommandLockName,
+		Owner:     uuid.NewString(),
+		Operation: operation,
+		Pid:       os.Getpid(),
+		Hostname:  hostname,
+	}
+
+	deadline := time.Now().Add(lockWait)
+	waiting := false
+	for {
+		lock.AcquiredAt = time.Now()
+		lock.ExpiresAt = lock.AcquiredAt.Add(commandLockTtl)
+		err := dbService.AcquireOperationLock(ctx, lock)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, database.ErrOperationInProgress) {
+			return nil, err
+		}
+
+		released, releaseErr := releaseAbandonedLock(ctx, dbService, hostname, logger)
+		if releaseErr != nil {
+			return nil, releaseErr
+		}
+		if released {
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			if lockWait > 0 {
+				return nil, fmt.Errorf("%w - gave up after waiting %s", err, lockWait)
+			}
+			return nil, fmt.Errorf("%w - retry once it finishes, or pass --wait", err)
+		}
+		if !waiting {
+			logger.Info("Waiting for another operation to finish", zap.Duration("wait", lockWait), zap.Error(err))
+			waiting = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(commandLockRetryWait):
+		}
+	}
+
+	l := &CommandLock{
+		dbService: dbService,
+		lock:      lock,
+		logger:    logger,
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go l.refresh()
+
+	logger.Debug("Acquired ledger write lock", zap.String("operation", operation), zap.String("owner", lock.Owner))
+	return l, nil
+}
+
+// releaseAbandonedLock frees the lock if its holder ran on this host and has exited without releasing it
+func releaseAbandonedLock(ctx context.Context, dbService *database.Service, hostname string, logger *zap.Logger) (bool, error) {
+	holder, err := dbService.GetOperationLock(ctx, CommandLockName)
+	if err != nil || holder == nil {
+		return false, err
+	}
+	if holder.Hostname != hostname || processAlive(holder.Pid) {
+		return false, nil
+	}
+
+	logger.Warn("Taking over ledger write lock left by an exited process",
+		zap.String("operation", holder.Operation),
+		zap.Int("pid", holder.Pid))
+	if err := dbService.ReleaseOperationLock(ctx, CommandLockName, holder.Owner); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// refresh extends the lock until Release is called
+func (l *CommandLock) refresh() {
+	defer close(l.stopped)
+	ticker := time.NewTicker(commandLockRefresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			err := l.dbService.RefreshOperationLock(context.Background(), l.lock.Name, l.lock.Owner, time.Now().Add(commandLockTtl))
+			if err != nil {
+				l.logger.Error("Failed to refresh ledger write lock - another operation may start", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Release stops refreshing the lock and frees it. It is safe to call more than once.
+func (l *CommandLock) Release() {
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.stopped
+		if err := l.dbService.ReleaseOperationLock(context.Background(), l.lock.Name, l.lock.Owner); err != nil {
+			l.logger.Warn("Failed to release ledger write lock - it expires on its own", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,74 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/database/dbtest"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestAcquireCommandLock(t *testing.T) {
+	ctx := context.Background()
+	logger := zaptest.NewLogger(t)
+	dbService := dbtest.Open(t)
+
+	lock, err := AcquireCommandLock(ctx, dbService, "setup", logger)
+	if err != nil {
+		t.Fatalf("AcquireCommandLock failed: %v", err)
+	}
+
+	// This process is still running, so a second command fails without --wait
+	if _, err := AcquireCommandLock(ctx, dbService, "withdrawal", logger); !errors.Is(err, database.ErrOperationInProgress) {
+		t.Fatalf("Expected ErrOperationInProgress, got %v", err)
+	}
+
+	lock.Release()
+	lock.Release()
+	second, err := AcquireCommandLock(ctx, dbService, "withdrawal", logger)
+	if err != nil {
+		t.Fatalf("Expected lock to be free after release, got %v", err)
+	}
+	second.Release()
+
+	// A lock left by a process on this host that has exited is taken over
+	hostname, _ := os.Hostname()
+	now := time.Now()
+	if err := dbService.AcquireOperationLock(ctx, models.OperationLock{
+		Name:       CommandLockName,
+		Owner:      "crashed",
+		Operation:  "adduser",
+		Hostname:   hostname,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(time.Hour),
+	}); err != nil {
+		t.Fatalf("Failed to plant abandoned lock: %v", err)
+	}
+	third, err := AcquireCommandLock(ctx, dbService, "withdrawal", logger)
+	if err != nil {
+		t.Fatalf("Expected abandoned lock to be taken over, got %v", err)
+	}
+	third.Release()
+}
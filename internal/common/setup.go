@@ -23,9 +23,13 @@ import (
 	"os"
 	"strings"
 
+	"prime-send-receive-go/internal/buildinfo"
+	"prime-send-receive-go/internal/custody"
 	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/logging"
 	"prime-send-receive-go/internal/models"
 	"prime-send-receive-go/internal/prime"
+	"prime-send-receive-go/internal/screening"
 
 	"github.com/coinbase-samples/prime-sdk-go/credentials"
 	"github.com/joho/godotenv"
@@ -47,18 +51,30 @@ func init() {
 }
 
 type Services struct {
-	DbService        *database.Service
-	PrimeService     *prime.Service
+	DbService    *database.Service
+	PrimeService *prime.Service
+	// Custody is the provider the listener and withdrawal worker run against (Prime by default)
+	Custody          custody.Provider
 	DefaultPortfolio *models.Portfolio
+	// Wallets is the naming convention for the trading wallets created and looked up in DefaultPortfolio
+	Wallets models.WalletConfig
+	// Logger is the process logger; components log through named children of it
+	Logger *zap.Logger
 }
 
-func InitializeLogger() (*zap.Logger, func()) {
-	logger, err := zap.NewProduction()
+// InitializeLogger builds the process logger from cfg. Components are given named children of it,
+// which cfg.Levels can set to their own level.
+func InitializeLogger(cfg models.LogConfig) (*zap.Logger, func()) {
+	logger, err := logging.New(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
-	zap.ReplaceGlobals(logger)
+	build := buildinfo.Get()
+	logger.Info("Starting",
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_date", build.BuildDate))
 
 	cleanup := func() {
 		if err := logger.Sync(); err != nil {
@@ -71,52 +87,131 @@ func InitializeLogger() (*zap.Logger, func()) {
 	return logger, cleanup
 }
 
-func InitializeServices(ctx context.Context, cfg *models.Config) (*Services, error) {
-	dbService, err := database.NewService(ctx, cfg.Database)
+func InitializeServices(ctx context.Context, cfg *models.Config, logger *zap.Logger) (*Services, error) {
+	dbService, err := database.NewService(ctx, cfg.Database, logger.Named("database"))
 	if err != nil {
 		return nil, err
 	}
+	dbService, tenant, err := scopeToTenant(ctx, dbService, cfg.Tenant.Id, logger)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Screening.Enabled {
+		dbService.SetDepositScreener(screening.NewRuleScreener(cfg.Screening).Screen)
+		logger.Info("Inbound deposit screening enabled")
+	}
 
-	zap.L().Info("Loading Prime API credentials")
+	logger.Info("Loading Prime API credentials")
 	creds, err := loadPrimeCredentials()
 	if err != nil {
 		dbService.Close()
 		return nil, err
 	}
 
-	primeService, err := prime.NewService(creds)
+	primeService, err := prime.NewService(creds, logger.Named("prime"))
 	if err != nil {
 		dbService.Close()
 		return nil, err
 	}
 
-	zap.L().Info("Finding default portfolio")
-	defaultPortfolio, err := primeService.FindDefaultPortfolio(ctx)
+	custodyProvider, err := custody.NewProvider(cfg.Custody.Provider, primeService)
 	if err != nil {
 		dbService.Close()
 		return nil, err
 	}
-	zap.L().Info("Using default portfolio",
+
+	var defaultPortfolio *models.Portfolio
+	if tenant != nil && tenant.PortfolioId != "" {
+		logger.Info("Using tenant portfolio", zap.String("tenant_id", tenant.Id))
+		defaultPortfolio, err = findPortfolio(ctx, primeService, tenant.PortfolioId)
+	} else {
+		logger.Info("Finding default portfolio", zap.String("custody_provider", custodyProvider.Name()))
+		defaultPortfolio, err = custodyProvider.FindDefaultPortfolio(ctx)
+	}
+	if err != nil {
+		dbService.Close()
+		return nil, err
+	}
+	logger.Info("Using default portfolio",
 		zap.String("name", defaultPortfolio.Name),
 		zap.String("id", defaultPortfolio.Id))
 
+	if cfg.Custody.CheckEntitlements {
+		if err := checkEntitlements(ctx, primeService, defaultPortfolio.Id, logger); err != nil {
+			dbService.Close()
+			return nil, err
+		}
+	}
+
 	return &Services{
 		DbService:        dbService,
 		PrimeService:     primeService,
+		Custody:          custodyProvider,
 		DefaultPortfolio: defaultPortfolio,
+		Wallets:          cfg.Wallet,
+		Logger:           logger,
 	}, nil
 }
 
 // InitializeDatabaseOnly initializes just the database service without Prime API
 // Useful for read-only operations like querying balances
-func InitializeDatabaseOnly(ctx context.Context, cfg *models.Config) (*database.Service, error) {
-	dbService, err := database.NewService(ctx, cfg.Database)
+func InitializeDatabaseOnly(ctx context.Context, cfg *models.Config, logger *zap.Logger) (*database.Service, error) {
+	dbService, err := database.NewService(ctx, cfg.Database, logger.Named("database"))
+	if err != nil {
+		return nil, err
+	}
+	dbService, _, err = scopeToTenant(ctx, dbService, cfg.Tenant.Id, logger)
 	if err != nil {
 		return nil, err
 	}
 	return dbService, nil
 }
 
+// scopeToTenant limits the database service to one tenant when tenantId is set. The service is
+// closed if the tenant does not exist.
+func scopeToTenant(ctx context.Context, dbService *database.Service, tenantId string, logger *zap.Logger) (*database.Service, *models.Tenant, error) {
+	if tenantId == "" {
+		return dbService, nil, nil
+	}
+
+	tenant, err := dbService.GetTenant(ctx, tenantId)
+	if err != nil {
+		dbService.Close()
+		return nil, nil, err
+	}
+
+	logger.Info("Scoped to tenant", zap.String("tenant_id", tenant.Id), zap.String("name", tenant.Name))
+	return dbService.ForTenant(tenant.Id), tenant, nil
+}
+
+// findPortfolio looks up a tenant's portfolio by id among those the API key can access
+func findPortfolio(ctx context.Context, primeService *prime.Service, portfolioId string) (*models.Portfolio, error) {
+	portfolios, err := primeService.ListPortfolios(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range portfolios {
+		if portfolios[i].Id == portfolioId {
+			return &portfolios[i], nil
+		}
+	}
+	return nil, fmt.Errorf("portfolio %s is not accessible with these credentials", portfolioId)
+}
+
+// checkEntitlements fails with every permission the API key is missing, so they can be granted in one go
+func checkEntitlements(ctx context.Context, primeService *prime.Service, portfolioId string, logger *zap.Logger) error {
+	logger.Info("Checking Prime API key entitlements", zap.String("portfolio_id", portfolioId))
+	missing, err := primeService.MissingEntitlements(ctx, portfolioId)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("prime API key is missing required entitlements for portfolio %s: %s (grant them in the Prime API key settings, or set PRIME_CHECK_ENTITLEMENTS=false to skip this check)",
+			portfolioId, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
 func (cs *Services) Close() {
 	if cs.DbService != nil {
 		cs.DbService.Close()
@@ -0,0 +1,80 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap"
+)
+
+// Shutdown watches for SIGINT and SIGTERM so that commands can stop at a consistent point instead of
+// being killed mid-step. Work in progress keeps its own context, so an in-flight Prime call or database
+// write completes; callers check Requested between steps. A second signal terminates immediately.
+type Shutdown struct {
+	signals chan os.Signal
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NotifyShutdown starts watching for shutdown signals. Call Stop to restore the default handling.
+func NotifyShutdown(logger *zap.Logger) *Shutdown {
+	s := &Shutdown{
+		signals: make(chan os.Signal, 1),
+		done:    make(chan struct{}),
+	}
+	signal.Notify(s.signals, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		sig, ok := <-s.signals
+		if !ok {
+			return
+		}
+		logger.Info("Shutdown signal received - stopping after the current step (send again to force)",
+			zap.String("signal", sig.String()))
+		signal.Stop(s.signals)
+		close(s.done)
+	}()
+
+	return s
+}
+
+// Done is closed once a shutdown signal has been received
+func (s *Shutdown) Done() <-chan struct{} {
+	return s.done
+}
+
+// Requested reports whether a shutdown signal has been received
+func (s *Shutdown) Requested() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop stops watching for signals
+func (s *Shutdown) Stop() {
+	s.once.Do(func() {
+		signal.Stop(s.signals)
+		close(s.signals)
+	})
+}
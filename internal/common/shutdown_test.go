@@ -0,0 +1,49 @@
+//go:build unix
+
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestShutdown_SignalRequestsStop(t *testing.T) {
+	shutdown := NotifyShutdown(zaptest.NewLogger(t))
+	defer shutdown.Stop()
+
+	if shutdown.Requested() {
+		t.Fatal("Expected no shutdown before a signal")
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send SIGTERM: %v", err)
+	}
+
+	select {
+	case <-shutdown.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for shutdown")
+	}
+	if !shutdown.Requested() {
+		t.Error("Expected shutdown to be requested after SIGTERM")
+	}
+}
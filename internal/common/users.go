@@ -19,8 +19,10 @@ package common
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
 
 	"go.uber.org/zap"
 )
@@ -66,3 +68,22 @@ func InitializeUsers(ctx context.Context, dbService *database.Service, emailFilt
 	logger.Info("Retrieved users", zap.Int("count", len(users)))
 	return users, nil
 }
+
+// ResolveUserId turns a --user flag value, an email or a user id, into a user id. An empty value
+// resolves to "" (all users).
+func ResolveUserId(ctx context.Context, dbService *database.Service, identifier string) (string, error) {
+	if identifier == "" {
+		return "", nil
+	}
+	var user *models.User
+	var err error
+	if strings.Contains(identifier, "@") {
+		user, err = dbService.GetUserByEmail(ctx, identifier)
+	} else {
+		user, err = dbService.GetUserById(ctx, identifier)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find user %s: %w", identifier, err)
+	}
+	return user.Id, nil
+}
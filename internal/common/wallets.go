@@ -0,0 +1,66 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// DefaultWalletNameTemplate names trading wallets when WALLET_NAME_TEMPLATE is not set
+const DefaultWalletNameTemplate = "{symbol} Trading Wallet"
+
+// WalletName returns the name of the trading wallet for an asset under the naming convention
+func WalletName(cfg models.WalletConfig, symbol string) string {
+	template := cfg.NameTemplate
+	if template == "" {
+		template = DefaultWalletNameTemplate
+	}
+	return strings.NewReplacer("{env}", cfg.Environment, "{symbol}", symbol).Replace(template)
+}
+
+// TradingWallets returns the wallets that belong to this deployment. With a configured template only
+// wallets carrying the convention's name are recognized, so environments sharing a portfolio keep to
+// their own wallets. Without one, every wallet is returned, with the default-named wallet first, so
+// deployments predating the convention keep using their wallets.
+func TradingWallets(cfg models.WalletConfig, wallets []models.Wallet, symbol string) []models.Wallet {
+	name := WalletName(cfg, symbol)
+
+	var named, others []models.Wallet
+	for _, wallet := range wallets {
+		if wallet.Name == name {
+			named = append(named, wallet)
+		} else {
+			others = append(others, wallet)
+		}
+	}
+
+	if cfg.NameTemplate != "" {
+		return named
+	}
+	return append(named, others...)
+}
+
+// SelectTradingWallet returns the wallet to use for an asset, or nil if none is recognized
+func SelectTradingWallet(cfg models.WalletConfig, wallets []models.Wallet, symbol string) *models.Wallet {
+	recognized := TradingWallets(cfg, wallets, symbol)
+	if len(recognized) == 0 {
+		return nil
+	}
+	return &recognized[0]
+}
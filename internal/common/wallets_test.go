@@ -0,0 +1,60 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestWalletName(t *testing.T) {
+	if got := WalletName(models.WalletConfig{}, "BTC"); got != "BTC Trading Wallet" {
+		t.Errorf("Default wallet name = %q", got)
+	}
+
+	cfg := models.WalletConfig{NameTemplate: "{env}-{symbol}-trading", Environment: "staging"}
+	if got := WalletName(cfg, "USDC"); got != "staging-USDC-trading" {
+		t.Errorf("Templated wallet name = %q", got)
+	}
+}
+
+func TestSelectTradingWallet(t *testing.T) {
+	wallets := []models.Wallet{
+		{Id: "w-prod", Name: "prod-BTC-trading"},
+		{Id: "w-legacy", Name: "BTC Trading Wallet"},
+		{Id: "w-staging", Name: "staging-BTC-trading"},
+	}
+
+	// Without a template the default-named wallet is preferred, falling back to any wallet
+	if wallet := SelectTradingWallet(models.WalletConfig{}, wallets, "BTC"); wallet == nil || wallet.Id != "w-legacy" {
+		t.Errorf("Expected legacy wallet, got %+v", wallet)
+	}
+	if wallet := SelectTradingWallet(models.WalletConfig{}, wallets[:1], "BTC"); wallet == nil || wallet.Id != "w-prod" {
+		t.Errorf("Expected fallback to the only wallet, got %+v", wallet)
+	}
+
+	// With a template only the environment's own wallet is recognized
+	staging := models.WalletConfig{NameTemplate: "{env}-{symbol}-trading", Environment: "staging"}
+	if wallet := SelectTradingWallet(staging, wallets, "BTC"); wallet == nil || wallet.Id != "w-staging" {
+		t.Errorf("Expected staging wallet, got %+v", wallet)
+	}
+	dev := models.WalletConfig{NameTemplate: "{env}-{symbol}-trading", Environment: "dev"}
+	if wallet := SelectTradingWallet(dev, wallets, "BTC"); wallet != nil {
+		t.Errorf("Expected no wallet for dev, got %+v", wallet)
+	}
+}
@@ -20,9 +20,13 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zapcore"
 )
 
 func Load() (*models.Config, error) {
@@ -41,6 +45,21 @@ func Load() (*models.Config, error) {
 		return nil, err
 	}
 
+	startupScanWindow, err := getEnvDuration("LISTENER_STARTUP_SCAN_WINDOW", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fullScanInterval, err := getEnvDuration("LISTENER_FULL_SCAN_INTERVAL", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	idlePollingInterval, err := getEnvDuration("LISTENER_IDLE_POLLING_INTERVAL", 0)
+	if err != nil {
+		return nil, err
+	}
+
 	connMaxLifetime, err := getEnvDuration("DB_CONN_MAX_LIFETIME", 5*time.Minute)
 	if err != nil {
 		return nil, err
@@ -56,25 +75,457 @@ func Load() (*models.Config, error) {
 		return nil, err
 	}
 
+	queuePollInterval, err := getEnvDuration("WITHDRAWAL_QUEUE_POLL_INTERVAL", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	queueSubmitInterval, err := getEnvDuration("WITHDRAWAL_QUEUE_SUBMIT_INTERVAL", 500*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	queueRetryBackoff, err := getEnvDuration("WITHDRAWAL_QUEUE_RETRY_BACKOFF", 30*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	queueBatchWindow, err := getEnvDuration("WITHDRAWAL_BATCH_WINDOW", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	crashAt := getEnvString("WITHDRAWAL_CRASH_AT", "")
+	if crashAt != "" && !models.IsWithdrawalStep(crashAt) {
+		return nil, fmt.Errorf("WITHDRAWAL_CRASH_AT %q is not a withdrawal step (reserved, submitted or rollback)", crashAt)
+	}
+
+	walletLeaseTTL, err := getEnvDuration("WALLET_LEASE_TTL", 2*pollingInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	priceCacheTTL, err := getEnvDuration("PRICE_CACHE_TTL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	dustThresholdUsd, err := getEnvDecimal("DUST_THRESHOLD_USD", decimal.NewFromInt(1))
+	if err != nil {
+		return nil, err
+	}
+
+	interestRates, err := getEnvRates("INTEREST_RATES")
+	if err != nil {
+		return nil, err
+	}
+
+	interestMinBalance, err := getEnvDecimal("INTEREST_MIN_BALANCE", decimal.Zero)
+	if err != nil {
+		return nil, err
+	}
+
+	interestCheckInterval, err := getEnvDuration("INTEREST_CHECK_INTERVAL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	digestCheckInterval, err := getEnvDuration("NOTIFY_DIGEST_CHECK_INTERVAL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	digestHour := getEnvInt("NOTIFY_DIGEST_HOUR", 7)
+	if digestHour < 0 || digestHour > 23 {
+		return nil, fmt.Errorf("NOTIFY_DIGEST_HOUR must be between 0 and 23")
+	}
+
+	treasuryMinHotRatio, err := getEnvDecimal("TREASURY_MIN_HOT_RATIO", decimal.NewFromFloat(0.1))
+	if err != nil {
+		return nil, err
+	}
+
+	treasuryTargetHotRatio, err := getEnvDecimal("TREASURY_TARGET_HOT_RATIO", decimal.NewFromFloat(0.2))
+	if err != nil {
+		return nil, err
+	}
+
+	treasuryMaxHotRatio, err := getEnvDecimal("TREASURY_MAX_HOT_RATIO", decimal.NewFromFloat(0.4))
+	if err != nil {
+		return nil, err
+	}
+
+	topUpRecheckInterval, err := getEnvDuration("TREASURY_TOP_UP_RECHECK_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	topUpTimeout, err := getEnvDuration("TREASURY_TOP_UP_TIMEOUT", 2*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliationInterval, err := getEnvDuration("RECONCILIATION_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	invariantCheckInterval, err := getEnvDuration("INVARIANT_CHECK_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	busyTimeout, err := getEnvDuration("DB_BUSY_TIMEOUT", 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceInterval, err := getEnvDuration("MAINTENANCE_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	vacuumFreeRatio, err := getEnvDecimal("MAINTENANCE_VACUUM_FREE_RATIO", decimal.NewFromFloat(0.2))
+	if err != nil {
+		return nil, err
+	}
+	if vacuumFreeRatio.IsNegative() || vacuumFreeRatio.GreaterThan(decimal.NewFromInt(1)) {
+		return nil, fmt.Errorf("MAINTENANCE_VACUUM_FREE_RATIO must be between 0 and 1, got %s", vacuumFreeRatio)
+	}
+
+	maintenanceRetention, err := getEnvRetention("MAINTENANCE_RETENTION")
+	if err != nil {
+		return nil, err
+	}
+
+	balanceLocking := getEnvString("DB_BALANCE_LOCKING", models.LockingOptimistic)
+	if !models.IsBalanceLocking(balanceLocking) {
+		return nil, fmt.Errorf("DB_BALANCE_LOCKING must be optimistic or pessimistic, got %q", balanceLocking)
+	}
+	balancePolicies, err := getEnvBalancePolicies("DB_BALANCE_POLICIES")
+	if err != nil {
+		return nil, err
+	}
+
+	fundsAvailability := getEnvString("FUNDS_AVAILABILITY", models.AvailabilityImmediate)
+	if !models.IsAvailabilityPolicy(fundsAvailability) {
+		return nil, fmt.Errorf("FUNDS_AVAILABILITY must be immediate, done or review, got %q", fundsAvailability)
+	}
+
+	// Only types that move funds in or out can be applied as deposits or withdrawals
+	transactionTypes := getEnvList("LISTENER_TRANSACTION_TYPES")
+	if len(transactionTypes) == 0 {
+		transactionTypes = []string{"DEPOSIT", "WITHDRAWAL"}
+	}
+	for i, txType := range transactionTypes {
+		transactionTypes[i] = strings.ToUpper(txType)
+		if models.TransactionDirection(transactionTypes[i]) == "" {
+			return nil, fmt.Errorf("LISTENER_TRANSACTION_TYPES: %q is not a deposit or withdrawal type", txType)
+		}
+	}
+
+	if pollingInterval <= 0 {
+		return nil, fmt.Errorf("LISTENER_POLLING_INTERVAL must be greater than zero")
+	}
+	pageLimit := getEnvInt("LISTENER_PAGE_LIMIT", 500)
+	if pageLimit <= 0 {
+		return nil, fmt.Errorf("LISTENER_PAGE_LIMIT must be greater than zero")
+	}
+
+	logLevel, err := getEnvLogLevel("LOG_LEVEL", zapcore.InfoLevel)
+	if err != nil {
+		return nil, err
+	}
+
+	logLevels, err := getEnvLogLevels("LOG_LEVELS")
+	if err != nil {
+		return nil, err
+	}
+
+	screeningHoldAbove, err := getEnvRates("DEPOSIT_SCREENING_HOLD_ABOVE")
+	if err != nil {
+		return nil, err
+	}
+
+	anomalyInterval, err := getEnvDuration("ANOMALY_CHECK_INTERVAL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalyWindow, err := getEnvDuration("ANOMALY_WINDOW", 24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalyBaseline, err := getEnvDuration("ANOMALY_BASELINE", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if anomalyWindow <= 0 || anomalyBaseline < anomalyWindow {
+		return nil, fmt.Errorf("ANOMALY_BASELINE (%s) must be at least ANOMALY_WINDOW (%s), which must be positive", anomalyBaseline, anomalyWindow)
+	}
+
+	anomalyMultiplier, err := getEnvDecimal("ANOMALY_MULTIPLIER", decimal.NewFromInt(5))
+	if err != nil {
+		return nil, err
+	}
+	if anomalyMultiplier.LessThan(decimal.NewFromInt(1)) {
+		return nil, fmt.Errorf("ANOMALY_MULTIPLIER must be at least 1, got %s", anomalyMultiplier)
+	}
+
+	anomalyMinVolume, err := getEnvRates("ANOMALY_MIN_VOLUME")
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawalDailyLimits, err := getEnvRates("WITHDRAWAL_DAILY_LIMITS")
+	if err != nil {
+		return nil, err
+	}
+	for asset, limit := range withdrawalDailyLimits {
+		if !limit.IsPositive() {
+			return nil, fmt.Errorf("WITHDRAWAL_DAILY_LIMITS: limit for %s must be greater than zero", asset)
+		}
+	}
+
+	refundApprovalThresholds, err := getEnvRates("REFUND_APPROVAL_THRESHOLDS")
+	if err != nil {
+		return nil, err
+	}
+	for asset, threshold := range refundApprovalThresholds {
+		if threshold.IsNegative() {
+			return nil, fmt.Errorf("REFUND_APPROVAL_THRESHOLDS: threshold for %s must not be negative", asset)
+		}
+	}
+
+	webhookTolerance, err := getEnvDuration("WEBHOOK_TOLERANCE", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookEnabled := getEnvBool("WEBHOOK_ENABLED", false)
+	webhookSecret := getEnvString("WEBHOOK_SECRET", "")
+	if webhookEnabled && len(webhookSecret) < 32 {
+		return nil, fmt.Errorf("WEBHOOK_SECRET must be at least 32 characters when WEBHOOK_ENABLED is true")
+	}
+
+	apiAdminToken := getEnvString("API_ADMIN_TOKEN", "")
+	if apiAdminToken != "" && len(apiAdminToken) < 32 {
+		return nil, fmt.Errorf("API_ADMIN_TOKEN must be at least 32 characters when set")
+	}
+	grpcToken := getEnvString("GRPC_TOKEN", "")
+	if grpcToken != "" && len(grpcToken) < 32 {
+		return nil, fmt.Errorf("GRPC_TOKEN must be at least 32 characters when set")
+	}
+
+	notifyWebhookUrl := getEnvString("NOTIFY_WEBHOOK_URL", "")
+	notifyWebhookSecret := getEnvString("NOTIFY_WEBHOOK_SECRET", "")
+	if notifyWebhookUrl != "" && len(notifyWebhookSecret) < 32 {
+		return nil, fmt.Errorf("NOTIFY_WEBHOOK_SECRET must be at least 32 characters when NOTIFY_WEBHOOK_URL is set")
+	}
+	notifyWebhookTimeout, err := getEnvDuration("NOTIFY_WEBHOOK_TIMEOUT", 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	custodyProvider := getEnvString("CUSTODY_PROVIDER", models.CustodyProviderPrime)
+	if !models.IsCustodyProvider(custodyProvider) {
+		return nil, fmt.Errorf("CUSTODY_PROVIDER %q is not supported (supported: prime)", custodyProvider)
+	}
+
+	explorerTxUrls, err := getEnvMap("EXPLORER_TX_URLS")
+	if err != nil {
+		return nil, err
+	}
+	for network, template := range defaultExplorerTxUrls {
+		if _, ok := explorerTxUrls[network]; !ok {
+			explorerTxUrls[network] = template
+		}
+	}
+
+	walletNameTemplate := getEnvString("WALLET_NAME_TEMPLATE", "")
+	walletEnv := getEnvString("WALLET_ENV", "")
+	if strings.Contains(walletNameTemplate, "{env}") && walletEnv == "" {
+		return nil, fmt.Errorf("WALLET_ENV must be set when WALLET_NAME_TEMPLATE contains {env}")
+	}
+
+	if treasuryMinHotRatio.IsNegative() || treasuryMinHotRatio.GreaterThan(treasuryTargetHotRatio) || treasuryTargetHotRatio.GreaterThan(treasuryMaxHotRatio) {
+		return nil, fmt.Errorf("treasury ratios must satisfy 0 <= TREASURY_MIN_HOT_RATIO <= TREASURY_TARGET_HOT_RATIO <= TREASURY_MAX_HOT_RATIO, got %s, %s, %s",
+			treasuryMinHotRatio, treasuryTargetHotRatio, treasuryMaxHotRatio)
+	}
+
 	return &models.Config{
 		Database: models.DatabaseConfig{
-			Path:             getEnvString("DATABASE_PATH", "addresses.db"),
-			MaxOpenConns:     getEnvInt("DB_MAX_OPEN_CONNS", 25),
-			MaxIdleConns:     getEnvInt("DB_MAX_IDLE_CONNS", 5),
-			ConnMaxLifetime:  connMaxLifetime,
-			ConnMaxIdleTime:  connMaxIdleTime,
-			PingTimeout:      pingTimeout,
-			CreateDummyUsers: getEnvBool("CREATE_DUMMY_USERS", false),
+			Path:                getEnvString("DATABASE_PATH", "addresses.db"),
+			MaxOpenConns:        getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:        getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime:     connMaxLifetime,
+			ConnMaxIdleTime:     connMaxIdleTime,
+			PingTimeout:         pingTimeout,
+			BusyTimeout:         busyTimeout,
+			BalanceLocking:      balanceLocking,
+			BalancePolicies:     balancePolicies,
+			CreateDummyUsers:    getEnvBool("CREATE_DUMMY_USERS", false),
+			ChartOfAccountsFile: getEnvString("CHART_OF_ACCOUNTS_FILE", ""),
 		},
 		Listener: models.ListenerConfig{
-			LookbackWindow:  lookbackWindow,
-			PollingInterval: pollingInterval,
-			CleanupInterval: cleanupInterval,
-			AssetsFile:      getEnvString("ASSETS_FILE", "assets.yaml"),
+			LookbackWindow:      lookbackWindow,
+			PollingInterval:     pollingInterval,
+			CleanupInterval:     cleanupInterval,
+			MaxConcurrency:      getEnvInt("LISTENER_MAX_CONCURRENCY", 8),
+			AssetsFile:          getEnvString("ASSETS_FILE", "assets.yaml"),
+			StartupScanWindow:   startupScanWindow,
+			FundsAvailability:   fundsAvailability,
+			TransactionTypes:    transactionTypes,
+			FullScanInterval:    fullScanInterval,
+			PageLimit:           pageLimit,
+			IdlePollingInterval: idlePollingInterval,
+		},
+		WithdrawalQueue: models.WithdrawalQueueConfig{
+			Enabled:        getEnvBool("WITHDRAWAL_QUEUE_ENABLED", true),
+			PollInterval:   queuePollInterval,
+			SubmitInterval: queueSubmitInterval,
+			BatchSize:      getEnvInt("WITHDRAWAL_QUEUE_BATCH_SIZE", 20),
+			MaxAttempts:    getEnvInt("WITHDRAWAL_QUEUE_MAX_ATTEMPTS", 5),
+			RetryBackoff:   queueRetryBackoff,
+			MaxPerMinute:   getEnvInt("WITHDRAWAL_QUEUE_MAX_PER_MINUTE", 0),
+			BatchWindow:    queueBatchWindow,
+			CrashAt:        models.WithdrawalStep(crashAt),
+		},
+		Coordination: models.CoordinationConfig{
+			Backend:        getEnvString("COORDINATION_BACKEND", "memory"),
+			RedisAddr:      getEnvString("REDIS_ADDR", "localhost:6379"),
+			RedisPassword:  getEnvString("REDIS_PASSWORD", ""),
+			RedisDB:        getEnvInt("REDIS_DB", 0),
+			RedisKeyPrefix: getEnvString("REDIS_KEY_PREFIX", "prime-send-receive"),
+			InstanceId:     getEnvString("INSTANCE_ID", defaultInstanceId()),
+			WalletLeaseTTL: walletLeaseTTL,
+		},
+		Pricing: models.PricingConfig{
+			Source:           getEnvString("PRICE_SOURCE", "auto"),
+			CacheTTL:         priceCacheTTL,
+			DustThresholdUsd: dustThresholdUsd,
+		},
+		Interest: models.InterestConfig{
+			Enabled:       getEnvBool("INTEREST_ENABLED", false),
+			Rates:         interestRates,
+			MinBalance:    interestMinBalance,
+			RunHour:       getEnvInt("INTEREST_RUN_HOUR", 0),
+			CheckInterval: interestCheckInterval,
+		},
+		Treasury: models.TreasuryConfig{
+			MinHotRatio:          treasuryMinHotRatio,
+			TargetHotRatio:       treasuryTargetHotRatio,
+			MaxHotRatio:          treasuryMaxHotRatio,
+			AutoTopUp:            getEnvBool("TREASURY_AUTO_TOP_UP", false),
+			TopUpRecheckInterval: topUpRecheckInterval,
+			TopUpTimeout:         topUpTimeout,
+		},
+		Serve: models.ServeConfig{
+			ListenerEnabled:        getEnvBool("SERVE_LISTENER_ENABLED", true),
+			ReconciliationEnabled:  getEnvBool("RECONCILIATION_ENABLED", true),
+			ReconciliationInterval: reconciliationInterval,
+			InvariantCheckEnabled:  getEnvBool("INVARIANT_CHECK_ENABLED", true),
+			InvariantCheckInterval: invariantCheckInterval,
+			MetricsEnabled:         getEnvBool("METRICS_ENABLED", true),
+			MetricsAddr:            getEnvString("METRICS_ADDR", ":9090"),
+			ApiEnabled:             getEnvBool("SERVE_API_ENABLED", false),
+			GrpcEnabled:            getEnvBool("SERVE_GRPC_ENABLED", false),
+		},
+		Maintenance: models.MaintenanceConfig{
+			Enabled:         getEnvBool("MAINTENANCE_ENABLED", true),
+			Interval:        maintenanceInterval,
+			VacuumFreeRatio: vacuumFreeRatio,
+			Retention:       maintenanceRetention,
+		},
+		Screening: models.ScreeningConfig{
+			Enabled:           getEnvBool("DEPOSIT_SCREENING_ENABLED", false),
+			HoldAbove:         screeningHoldAbove,
+			HoldUnknownSource: getEnvBool("DEPOSIT_SCREENING_HOLD_UNKNOWN_SOURCE", false),
+			Watchlist:         getEnvList("DEPOSIT_SCREENING_WATCHLIST"),
+		},
+		Anomaly: models.AnomalyConfig{
+			Enabled:    getEnvBool("ANOMALY_DETECTION_ENABLED", false),
+			Interval:   anomalyInterval,
+			Window:     anomalyWindow,
+			Baseline:   anomalyBaseline,
+			Multiplier: anomalyMultiplier,
+			MinCount:   getEnvInt("ANOMALY_MIN_COUNT", 5),
+			MinVolume:  anomalyMinVolume,
+		},
+		Api: models.ApiConfig{
+			RateLimitPerIp:        getEnvInt("API_RATE_LIMIT_PER_IP", 60),
+			RateLimitPerToken:     getEnvInt("API_RATE_LIMIT_PER_TOKEN", 120),
+			RateLimitBurst:        getEnvInt("API_RATE_LIMIT_BURST", 20),
+			TrustForwardedFor:     getEnvBool("API_TRUST_FORWARDED_FOR", false),
+			BalanceCacheSize:      getEnvInt("API_BALANCE_CACHE_SIZE", 10000),
+			WithdrawalDailyLimits: withdrawalDailyLimits,
+			Addr:                  getEnvString("API_ADDR", ":8080"),
+			AdminToken:            apiAdminToken,
+			GrpcAddr:              getEnvString("GRPC_ADDR", ":50051"),
+			GrpcToken:             grpcToken,
+		},
+		Wallet: models.WalletConfig{
+			NameTemplate:  walletNameTemplate,
+			Environment:   walletEnv,
+			LazyAddresses: getEnvBool("LAZY_DEPOSIT_ADDRESSES", false),
+		},
+		Notify: models.NotifyConfig{
+			SmtpAddr:             getEnvString("NOTIFY_SMTP_ADDR", ""),
+			SmtpUsername:         getEnvString("NOTIFY_SMTP_USERNAME", ""),
+			SmtpPassword:         getEnvString("NOTIFY_SMTP_PASSWORD", ""),
+			EmailFrom:            getEnvString("NOTIFY_EMAIL_FROM", ""),
+			EmailTo:              getEnvList("NOTIFY_EMAIL_TO"),
+			ProvisioningFailures: getEnvBool("NOTIFY_PROVISIONING_FAILURES", false),
+			DigestEnabled:        getEnvBool("NOTIFY_DIGEST_ENABLED", false),
+			DigestHour:           digestHour,
+			DigestCheckInterval:  digestCheckInterval,
+			WebhookUrl:           notifyWebhookUrl,
+			WebhookSecret:        notifyWebhookSecret,
+			WebhookTimeout:       notifyWebhookTimeout,
+		},
+		Explorer: models.ExplorerConfig{
+			TxUrls: explorerTxUrls,
+		},
+		Log: models.LogConfig{
+			Level:  logLevel,
+			Levels: logLevels,
+		},
+		Refund: models.RefundConfig{
+			ApprovalThresholds: refundApprovalThresholds,
+		},
+		Tenant: models.TenantConfig{
+			Id: getEnvString("TENANT_ID", ""),
+		},
+		Custody: models.CustodyConfig{
+			Provider:          custodyProvider,
+			CheckEntitlements: getEnvBool("PRIME_CHECK_ENTITLEMENTS", true),
+		},
+		Webhook: models.WebhookConfig{
+			Enabled:   webhookEnabled,
+			Addr:      getEnvString("WEBHOOK_ADDR", ":8081"),
+			Secret:    webhookSecret,
+			Tolerance: webhookTolerance,
 		},
 	}, nil
 }
 
+// defaultExplorerTxUrls are used for networks EXPLORER_TX_URLS does not set
+var defaultExplorerTxUrls = map[string]string{
+	"ethereum-mainnet": "https://etherscan.io/tx/{hash}",
+	"base-mainnet":     "https://basescan.org/tx/{hash}",
+	"bitcoin-mainnet":  "https://mempool.space/tx/{hash}",
+	"solana-mainnet":   "https://solscan.io/tx/{hash}",
+}
+
+// defaultInstanceId identifies this process for wallet leases when INSTANCE_ID is not set
+func defaultInstanceId() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
 func getEnvString(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -93,6 +544,130 @@ func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, erro
 	return defaultValue, nil
 }
 
+func getEnvDecimal(key string, defaultValue decimal.Decimal) (decimal.Decimal, error) {
+	if value := os.Getenv(key); value != "" {
+		parsed, err := decimal.NewFromString(value)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("invalid decimal for %s: %q (%w)", key, value, err)
+		}
+		return parsed, nil
+	}
+	return defaultValue, nil
+}
+
+// getEnvRates parses a comma separated list of ASSET=decimal pairs, e.g. "USDC=0.045,ETH=0.02"
+func getEnvRates(key string) (map[string]decimal.Decimal, error) {
+	rates := make(map[string]decimal.Decimal)
+	value := os.Getenv(key)
+	if value == "" {
+		return rates, nil
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		asset, rateStr, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || asset == "" {
+			return nil, fmt.Errorf("invalid rate for %s: %q (expected ASSET=value)", key, pair)
+		}
+		rate, err := decimal.NewFromString(rateStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate for %s: %q (%w)", key, pair, err)
+		}
+		rates[asset] = rate
+	}
+	return rates, nil
+}
+
+// getEnvMap parses a comma separated list of KEY=value pairs, e.g. "base-mainnet=https://basescan.org/tx/{hash}"
+func getEnvMap(key string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, pair := range getEnvList(key) {
+		name, value, found := strings.Cut(pair, "=")
+		if !found || name == "" || value == "" {
+			return nil, fmt.Errorf("invalid entry for %s: %q (expected KEY=value)", key, pair)
+		}
+		values[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return values, nil
+}
+
+// getEnvRetention parses a comma separated list of table=duration retention policies
+func getEnvRetention(key string) (map[string]time.Duration, error) {
+	values, err := getEnvMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	retention := make(map[string]time.Duration, len(values))
+	for table, value := range values {
+		if _, ok := models.RetentionTables[table]; !ok {
+			return nil, fmt.Errorf("%s: retention is not supported for table %q", key, table)
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%s: invalid retention %q for %s", key, value, table)
+		}
+		retention[table] = duration
+	}
+	return retention, nil
+}
+
+// getEnvBalancePolicies parses a comma separated list of source=policy negative-balance policies
+func getEnvBalancePolicies(key string) (map[string]string, error) {
+	policies, err := getEnvMap(key)
+	if err != nil {
+		return nil, err
+	}
+	for source, policy := range policies {
+		if _, ok := models.DefaultBalancePolicies[models.TransactionSource(source)]; !ok {
+			return nil, fmt.Errorf("%s: unknown transaction source %q (expected api, cli, listener or backfill)", key, source)
+		}
+		if !models.IsBalancePolicy(policy) {
+			return nil, fmt.Errorf("%s: policy for %s must be sync or customer, got %q", key, source, policy)
+		}
+	}
+	return policies, nil
+}
+
+func getEnvLogLevel(key string, defaultValue zapcore.Level) (zapcore.Level, error) {
+	if value := os.Getenv(key); value != "" {
+		level, err := zapcore.ParseLevel(value)
+		if err != nil {
+			return defaultValue, fmt.Errorf("invalid log level for %s: %q (%w)", key, value, err)
+		}
+		return level, nil
+	}
+	return defaultValue, nil
+}
+
+// getEnvLogLevels parses a comma separated list of component=level overrides, e.g. "listener=debug,database=warn"
+func getEnvLogLevels(key string) (map[string]zapcore.Level, error) {
+	values, err := getEnvMap(key)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := make(map[string]zapcore.Level, len(values))
+	for component, value := range values {
+		level, err := zapcore.ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid log level %q for %s", key, value, component)
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
+
+// getEnvList parses a comma separated list, dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getEnvInt(key string, defaultValue int) int {
 	if value := os.Getenv(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
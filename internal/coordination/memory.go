@@ -0,0 +1,135 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package coordination
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type lease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type counter struct {
+	count     int
+	expiresAt time.Time
+}
+
+// MemoryStore keeps coordination state in process memory. It is the default and is only
+// suitable for a single listener instance.
+type MemoryStore struct {
+	mutex     sync.Mutex
+	processed map[string]time.Time
+	counters  map[string]counter
+	leases    map[string]lease
+}
+
+// NewMemoryStore creates an empty in-memory coordination store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		processed: make(map[string]time.Time),
+		counters:  make(map[string]counter),
+		leases:    make(map[string]lease),
+	}
+}
+
+func (m *MemoryStore) IsProcessed(ctx context.Context, txId string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	expiresAt, exists := m.processed[txId]
+	return exists && time.Now().Before(expiresAt), nil
+}
+
+func (m *MemoryStore) MarkProcessed(ctx context.Context, txId string, ttl time.Duration) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.processed[txId] = time.Now().Add(ttl)
+	return nil
+}
+
+func (m *MemoryStore) Sweep(ctx context.Context) (int, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	cleaned := 0
+	for txId, expiresAt := range m.processed {
+		if !now.Before(expiresAt) {
+			delete(m.processed, txId)
+			cleaned++
+		}
+	}
+	for key, c := range m.counters {
+		if !now.Before(c.expiresAt) {
+			delete(m.counters, key)
+		}
+	}
+	for key, l := range m.leases {
+		if !now.Before(l.expiresAt) {
+			delete(m.leases, key)
+		}
+	}
+
+	return cleaned, nil
+}
+
+func (m *MemoryStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	c, exists := m.counters[key]
+	if !exists || !now.Before(c.expiresAt) {
+		c = counter{expiresAt: now.Add(window)}
+	}
+	c.count++
+	m.counters[key] = c
+
+	return c.count <= limit, nil
+}
+
+func (m *MemoryStore) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := time.Now()
+	if l, exists := m.leases[key]; exists && l.owner != owner && now.Before(l.expiresAt) {
+		return false, nil
+	}
+
+	m.leases[key] = lease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (m *MemoryStore) ReleaseLease(ctx context.Context, key, owner string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if l, exists := m.leases[key]; exists && l.owner == owner {
+		delete(m.leases, key)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Close() error {
+	return nil
+}
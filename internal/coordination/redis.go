@@ -0,0 +1,145 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package coordination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// acquireLeaseScript sets the lease if free, or extends it if already held by the same owner
+var acquireLeaseScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == false then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if current == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaseScript deletes the lease only if it is held by the given owner
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisStore keeps coordination state in Redis so several listener instances can share it
+type RedisStore struct {
+	client    *redis.Client
+	keyPrefix string
+}
+
+// NewRedisStore connects to Redis and verifies the connection
+func NewRedisStore(ctx context.Context, cfg models.CoordinationConfig, logger *zap.Logger) (*RedisStore, error) {
+	if cfg.RedisAddr == "" {
+		return nil, fmt.Errorf("redis address cannot be empty")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       cfg.RedisDB,
+	})
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("unable to ping redis: %w", err)
+	}
+
+	logger.Info("Connected to Redis coordination store",
+		zap.String("addr", cfg.RedisAddr),
+		zap.Int("db", cfg.RedisDB),
+		zap.String("key_prefix", cfg.RedisKeyPrefix))
+
+	return &RedisStore{client: client, keyPrefix: cfg.RedisKeyPrefix}, nil
+}
+
+func (r *RedisStore) key(parts ...string) string {
+	k := r.keyPrefix
+	for _, p := range parts {
+		k += ":" + p
+	}
+	return k
+}
+
+func (r *RedisStore) IsProcessed(ctx context.Context, txId string) (bool, error) {
+	n, err := r.client.Exists(ctx, r.key("processed", txId)).Result()
+	if err != nil {
+		return false, fmt.Errorf("unable to check processed transaction: %w", err)
+	}
+	return n > 0, nil
+}
+
+func (r *RedisStore) MarkProcessed(ctx context.Context, txId string, ttl time.Duration) error {
+	if err := r.client.Set(ctx, r.key("processed", txId), 1, ttl).Err(); err != nil {
+		return fmt.Errorf("unable to mark transaction processed: %w", err)
+	}
+	return nil
+}
+
+// Sweep is a no-op: Redis expires processed keys via TTL
+func (r *RedisStore) Sweep(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *RedisStore) Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	bucket := time.Now().UnixNano() / int64(window)
+	redisKey := r.key("ratelimit", key, strconv.FormatInt(bucket, 10))
+
+	pipe := r.client.TxPipeline()
+	incr := pipe.Incr(ctx, redisKey)
+	pipe.PExpire(ctx, redisKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("unable to update rate limit counter: %w", err)
+	}
+
+	return incr.Val() <= int64(limit), nil
+}
+
+func (r *RedisStore) AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	result, err := acquireLeaseScript.Run(ctx, r.client, []string{r.key("lease", key)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("unable to acquire lease: %w", err)
+	}
+	return result == 1, nil
+}
+
+func (r *RedisStore) ReleaseLease(ctx context.Context, key, owner string) error {
+	err := releaseLeaseScript.Run(ctx, r.client, []string{r.key("lease", key)}, owner).Err()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return fmt.Errorf("unable to release lease: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisStore) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package coordination
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Store holds short-lived coordination state shared by listener instances: dedupe of webhook
+// deliveries and per-transaction side steps, rate limiting counters and wallet leases. It is never
+// the ledger of record - balances, transactions and processed transaction ids live in the database.
+type Store interface {
+	// IsProcessed reports whether a Prime transaction was already handled
+	IsProcessed(ctx context.Context, txId string) (bool, error)
+	// MarkProcessed records a Prime transaction as handled for ttl
+	MarkProcessed(ctx context.Context, txId string, ttl time.Duration) error
+	// Sweep removes expired entries and returns how many were removed (no-op where the backend expires keys itself)
+	Sweep(ctx context.Context) (int, error)
+
+	// Allow increments the counter for key in the current window and reports whether it is within limit
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+
+	// AcquireLease takes or renews the lease on key for owner, returning false if another owner holds it
+	AcquireLease(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+	// ReleaseLease gives up a lease held by owner
+	ReleaseLease(ctx context.Context, key, owner string) error
+
+	Close() error
+}
+
+// NewStore creates the coordination store selected by configuration
+func NewStore(ctx context.Context, cfg models.CoordinationConfig, logger *zap.Logger) (Store, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "redis":
+		return NewRedisStore(ctx, cfg, logger)
+	default:
+		return nil, fmt.Errorf("unsupported coordination backend: %q (expected memory or redis)", cfg.Backend)
+	}
+}
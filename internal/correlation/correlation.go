@@ -0,0 +1,65 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package correlation ties together the log lines, results and notifications of one multi-step flow,
+// such as processing a Prime transaction or a withdrawal request, so the flow can be traced in log
+// aggregation.
+package correlation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Field is the log field correlation ids are written to
+const Field = "correlation_id"
+
+type contextKey struct{}
+
+// NewId returns a new correlation id
+func NewId() string {
+	return uuid.New().String()
+}
+
+// WithId returns a context carrying a correlation id
+func WithId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// Ensure returns ctx and its correlation id, attaching a new id when ctx has none
+func Ensure(ctx context.Context) (context.Context, string) {
+	if id := Id(ctx); id != "" {
+		return ctx, id
+	}
+	id := NewId()
+	return WithId(ctx, id), id
+}
+
+// Id returns the correlation id carried by ctx, or "" when there is none
+func Id(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logger returns logger with the correlation id of ctx attached when it has one
+func Logger(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	if id := Id(ctx); id != "" {
+		return logger.With(zap.String(Field, id))
+	}
+	return logger
+}
@@ -0,0 +1,56 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package correlation
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEnsure(t *testing.T) {
+	ctx, id := Ensure(context.Background())
+	if id == "" || Id(ctx) != id {
+		t.Fatalf("Expected a new id on the context, got %q and %q", id, Id(ctx))
+	}
+
+	again, sameId := Ensure(ctx)
+	if sameId != id || Id(again) != id {
+		t.Errorf("Expected the existing id %s to be kept, got %s", id, sameId)
+	}
+}
+
+func TestLogger(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(core)
+
+	Logger(WithId(context.Background(), "flow-1"), logger).Info("with id")
+	Logger(context.Background(), logger).Info("without id")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(entries))
+	}
+	if got := entries[0].ContextMap()[Field]; got != "flow-1" {
+		t.Errorf("Expected %s=flow-1, got %v", Field, got)
+	}
+	if _, ok := entries[1].ContextMap()[Field]; ok {
+		t.Error("Expected no correlation id without one on the context")
+	}
+}
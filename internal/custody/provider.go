@@ -0,0 +1,89 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package custody defines the venue the ledger custodies funds with. The listener and withdrawal
+// worker only depend on Provider, so another venue can be added without touching the subledger.
+package custody
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+)
+
+// Provider is a custody backend: it holds the wallets deposit addresses belong to, reports their
+// transactions and executes withdrawals
+type Provider interface {
+	// Name identifies the provider in logs
+	Name() string
+	FindDefaultPortfolio(ctx context.Context) (*models.Portfolio, error)
+	ListWallets(ctx context.Context, portfolioId, walletType string, symbols []string) ([]models.Wallet, error)
+	CreateWallet(ctx context.Context, portfolioId, name, symbol, walletType string) (*models.Wallet, error)
+	CreateDepositAddress(ctx context.Context, portfolioId, walletId, asset, network string) (*models.DepositAddress, error)
+	// WalletTransactions lists up to limit of a wallet's most recent transactions of every type created
+	// since a time; a limit of zero uses the provider's default page size
+	WalletTransactions(ctx context.Context, portfolioId, walletId string, since time.Time, limit int) ([]models.PrimeTransaction, error)
+	// GetTransaction fetches one transaction by id
+	GetTransaction(ctx context.Context, portfolioId, transactionId string) (*models.PrimeTransaction, error)
+	GetWalletBalance(ctx context.Context, portfolioId, walletId string) (*models.PortfolioBalance, error)
+	CreateWithdrawal(ctx context.Context, params models.CreateWithdrawalParams) (*models.Withdrawal, error)
+}
+
+var _ Provider = (*prime.Service)(nil)
+
+// NewProvider returns the provider selected by configuration. Prime is currently the only
+// implementation; its client is created during service initialization and passed in.
+func NewProvider(name string, primeService *prime.Service) (Provider, error) {
+	switch name {
+	case models.CustodyProviderPrime, "":
+		return primeService, nil
+	default:
+		return nil, fmt.Errorf("unsupported custody provider %q", name)
+	}
+}
+
+// withdrawalLookupLimit is how many of a wallet's transactions FindWithdrawal searches
+const withdrawalLookupLimit = 500
+
+// withdrawalLookupSkew widens FindWithdrawal's search for clock differences between the ledger and Prime
+const withdrawalLookupSkew = 5 * time.Minute
+
+// ErrWithdrawalLookupIncomplete is returned by FindWithdrawal when the wallet has more transactions since
+// the withdrawal than one lookup returns, so its absence cannot be confirmed
+var ErrWithdrawalLookupIncomplete = errors.New("too many wallet transactions to confirm the withdrawal is absent")
+
+// FindWithdrawal returns the transaction the provider created for a withdrawal idempotency key among the
+// wallet's transactions since a time, or nil when there is none, i.e. the provider never accepted it
+func FindWithdrawal(ctx context.Context, provider Provider, portfolioId, walletId, idempotencyKey string, since time.Time) (*models.PrimeTransaction, error) {
+	transactions, err := provider.WalletTransactions(ctx, portfolioId, walletId, since.Add(-withdrawalLookupSkew), withdrawalLookupLimit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list wallet transactions: %w", err)
+	}
+
+	for i := range transactions {
+		if transactions[i].IdempotencyKey == idempotencyKey {
+			return &transactions[i], nil
+		}
+	}
+	if len(transactions) >= withdrawalLookupLimit {
+		return nil, ErrWithdrawalLookupIncomplete
+	}
+	return nil, nil
+}
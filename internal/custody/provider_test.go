@@ -0,0 +1,74 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package custody
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestNewProvider_SelectsByName(t *testing.T) {
+	if _, err := NewProvider("exchange", nil); err == nil {
+		t.Error("Expected an unsupported provider to be rejected")
+	}
+	if !models.IsCustodyProvider(models.CustodyProviderPrime) || models.IsCustodyProvider("exchange") {
+		t.Error("Expected only prime to be supported")
+	}
+}
+
+// walletProvider returns fixed wallet transactions; every other Provider method is left unimplemented
+type walletProvider struct {
+	Provider
+	transactions []models.PrimeTransaction
+}
+
+func (p walletProvider) WalletTransactions(ctx context.Context, portfolioId, walletId string, since time.Time, limit int) ([]models.PrimeTransaction, error) {
+	if len(p.transactions) > limit {
+		return p.transactions[:limit], nil
+	}
+	return p.transactions, nil
+}
+
+func TestFindWithdrawal(t *testing.T) {
+	ctx := context.Background()
+	provider := walletProvider{transactions: []models.PrimeTransaction{
+		{Id: "tx-1", Type: "WITHDRAWAL", IdempotencyKey: "key-1"},
+		{Id: "tx-2", Type: "DEPOSIT"},
+	}}
+
+	tx, err := FindWithdrawal(ctx, provider, "portfolio-1", "wallet-1", "key-1", time.Now())
+	if err != nil || tx == nil || tx.Id != "tx-1" {
+		t.Errorf("Expected tx-1 for key-1, got %+v (%v)", tx, err)
+	}
+	if tx, err := FindWithdrawal(ctx, provider, "portfolio-1", "wallet-1", "key-2", time.Now()); err != nil || tx != nil {
+		t.Errorf("Expected no transaction for key-2, got %+v (%v)", tx, err)
+	}
+
+	// A full page might not reach back to the withdrawal, so its absence is not confirmed
+	busy := walletProvider{}
+	for i := 0; i < withdrawalLookupLimit+1; i++ {
+		busy.transactions = append(busy.transactions, models.PrimeTransaction{Id: fmt.Sprintf("tx-%d", i)})
+	}
+	if _, err := FindWithdrawal(ctx, busy, "portfolio-1", "wallet-1", "key-2", time.Now()); !errors.Is(err, ErrWithdrawalLookupIncomplete) {
+		t.Errorf("Expected ErrWithdrawalLookupIncomplete for a busy wallet, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// ErrAlreadyAcknowledged is returned when a Prime transaction was acknowledged before
+var ErrAlreadyAcknowledged = errors.New("prime transaction already acknowledged")
+
+func (s *Service) initPrimeTransactionAckSchema() error {
+	schema := `
+	-- Prime transactions an operator marked as handled so the listener skips them
+	CREATE TABLE IF NOT EXISTS acknowledged_prime_transactions (
+		prime_transaction_id TEXT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// AcknowledgePrimeTransaction marks a Prime transaction as handled without applying it to the ledger.
+// The first acknowledgement is kept as the audit record; acknowledging again returns ErrAlreadyAcknowledged.
+func (s *Service) AcknowledgePrimeTransaction(ctx context.Context, primeTxId, reason string) (models.PrimeTransactionAck, error) {
+	result, err := s.db.ExecContext(ctx, queryInsertPrimeTransactionAck, primeTxId, reason)
+	if err != nil {
+		return models.PrimeTransactionAck{}, fmt.Errorf("unable to acknowledge %s: %w", primeTxId, err)
+	}
+
+	ack, found, err := s.GetPrimeTransactionAck(ctx, primeTxId)
+	if err != nil {
+		return models.PrimeTransactionAck{}, err
+	}
+	if !found {
+		return models.PrimeTransactionAck{}, fmt.Errorf("acknowledgement of %s was not stored", primeTxId)
+	}
+
+	if inserted, err := result.RowsAffected(); err == nil && inserted == 0 {
+		return ack, fmt.Errorf("%w: %s", ErrAlreadyAcknowledged, primeTxId)
+	}
+	return ack, nil
+}
+
+// GetPrimeTransactionAck returns the acknowledgement of a Prime transaction, if it has one
+func (s *Service) GetPrimeTransactionAck(ctx context.Context, primeTxId string) (models.PrimeTransactionAck, bool, error) {
+	var ack models.PrimeTransactionAck
+	err := s.db.QueryRowContext(ctx, queryGetPrimeTransactionAck, primeTxId).Scan(&ack.PrimeTransactionId, &ack.Reason, &ack.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.PrimeTransactionAck{}, false, nil
+	}
+	if err != nil {
+		return models.PrimeTransactionAck{}, false, fmt.Errorf("unable to query acknowledgement of %s: %w", primeTxId, err)
+	}
+	return ack, true, nil
+}
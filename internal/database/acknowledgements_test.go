@@ -0,0 +1,62 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAcknowledgePrimeTransaction(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initPrimeTransactionAckSchema(); err != nil {
+		t.Fatalf("Failed to create acknowledgement schema: %v", err)
+	}
+
+	if _, found, err := service.GetPrimeTransactionAck(ctx, "prime-stuck"); err != nil || found {
+		t.Fatalf("Expected no acknowledgement, got found=%v err=%v", found, err)
+	}
+
+	ack, err := service.AcknowledgePrimeTransaction(ctx, "prime-stuck", "deposit to a deleted address, refunded manually")
+	if err != nil {
+		t.Fatalf("AcknowledgePrimeTransaction failed: %v", err)
+	}
+	if ack.PrimeTransactionId != "prime-stuck" || ack.CreatedAt.IsZero() {
+		t.Errorf("Unexpected acknowledgement: %+v", ack)
+	}
+
+	found, ok, err := service.GetPrimeTransactionAck(ctx, "prime-stuck")
+	if err != nil || !ok {
+		t.Fatalf("Expected an acknowledgement, got found=%v err=%v", ok, err)
+	}
+	if found.Reason != "deposit to a deleted address, refunded manually" {
+		t.Errorf("Unexpected reason: %s", found.Reason)
+	}
+
+	// The first acknowledgement is kept as the audit record
+	again, err := service.AcknowledgePrimeTransaction(ctx, "prime-stuck", "another reason")
+	if !errors.Is(err, ErrAlreadyAcknowledged) {
+		t.Fatalf("Expected ErrAlreadyAcknowledged, got %v", err)
+	}
+	if again.Reason != found.Reason {
+		t.Errorf("Expected the original reason to be kept, got %s", again.Reason)
+	}
+}
@@ -0,0 +1,288 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// SummarizeActivity totals ledger transactions created in [from, to) by type and asset, sorted by type
+// then asset. Credits that roll back failed withdrawals are reported as models.ActivityReversal rather
+// than as deposits, as are withdrawal holds released in the window, which never reach the ledger.
+func (s *Service) SummarizeActivity(ctx context.Context, from, to time.Time) ([]models.ActivityTotal, error) {
+	rows, err := s.db.QueryContext(ctx, queryListActivitySince, createdAtFrom(from), s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list activity: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	totals := make(map[[2]string]*models.ActivityTotal)
+	for rows.Next() {
+		var transactionType, asset, amountStr string
+		var reversalOf string
+		var createdAt time.Time
+		if err := rows.Scan(&transactionType, &asset, &amountStr, &reversalOf, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan activity: %w", err)
+		}
+		if createdAt.Before(from) || !createdAt.Before(to) {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+		}
+		if reversalOf != "" {
+			transactionType = models.ActivityReversal
+		}
+
+		key := [2]string{transactionType, asset}
+		total, ok := totals[key]
+		if !ok {
+			total = &models.ActivityTotal{Type: transactionType, Asset: asset}
+			totals[key] = total
+		}
+		total.Count++
+		total.Total = total.Total.Add(amount.Abs())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate activity: %w", err)
+	}
+	if err := s.addReleasedWithdrawals(ctx, from, to, totals); err != nil {
+		return nil, err
+	}
+
+	result := make([]models.ActivityTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Type != result[j].Type {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Asset < result[j].Asset
+	})
+	return result, nil
+}
+
+// addReleasedWithdrawals adds the withdrawal holds released in [from, to) to totals as reversals
+func (s *Service) addReleasedWithdrawals(ctx context.Context, from, to time.Time, totals map[[2]string]*models.ActivityTotal) error {
+	rows, err := s.db.QueryContext(ctx, queryListReleasedWithdrawalsSince, createdAtFrom(from), s.tenantId, s.tenantId)
+	if err != nil {
+		return fmt.Errorf("failed to list released withdrawals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	for rows.Next() {
+		var asset, amountStr string
+		var releasedAt time.Time
+		if err := rows.Scan(&asset, &amountStr, &releasedAt); err != nil {
+			return fmt.Errorf("failed to scan released withdrawal: %w", err)
+		}
+		if releasedAt.Before(from) || !releasedAt.Before(to) {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+		}
+
+		key := [2]string{models.ActivityReversal, asset}
+		total, ok := totals[key]
+		if !ok {
+			total = &models.ActivityTotal{Type: models.ActivityReversal, Asset: asset}
+			totals[key] = total
+		}
+		total.Count++
+		total.Total = total.Total.Add(amount)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate released withdrawals: %w", err)
+	}
+	return nil
+}
+
+// ListWalletActivity counts ledger transactions created in [from, to) per wallet and asset in buckets
+// of the given size, aligned to UTC. Buckets without transactions are omitted; the result is sorted by
+// bucket, then wallet, then asset.
+func (s *Service) ListWalletActivity(ctx context.Context, from, to time.Time, bucket time.Duration) ([]models.WalletActivity, error) {
+	if bucket <= 0 {
+		return nil, fmt.Errorf("bucket size must be positive, got %s", bucket)
+	}
+
+	// As in SummarizeActivity, narrow by a day either side and apply the exact window to parsed times
+	rows, err := s.db.QueryContext(ctx, queryListWalletActivitySince, createdAtFrom(from), s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallet activity: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	type activityKey struct {
+		bucketStart time.Time
+		walletId    string
+		asset       string
+	}
+	activity := make(map[activityKey]*models.WalletActivity)
+	for rows.Next() {
+		var asset, amountStr, walletId string
+		var createdAt time.Time
+		if err := rows.Scan(&asset, &amountStr, &createdAt, &walletId); err != nil {
+			return nil, fmt.Errorf("failed to scan wallet activity: %w", err)
+		}
+		if createdAt.Before(from) || !createdAt.Before(to) {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+		}
+
+		key := activityKey{bucketStart: createdAt.UTC().Truncate(bucket), walletId: walletId, asset: asset}
+		entry, ok := activity[key]
+		if !ok {
+			entry = &models.WalletActivity{BucketStart: key.bucketStart, WalletId: walletId, Asset: asset}
+			activity[key] = entry
+		}
+		entry.Count++
+		entry.Volume = entry.Volume.Add(amount.Abs())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate wallet activity: %w", err)
+	}
+
+	result := make([]models.WalletActivity, 0, len(activity))
+	for _, entry := range activity {
+		result = append(result, *entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].BucketStart.Equal(result[j].BucketStart) {
+			return result[i].BucketStart.Before(result[j].BucketStart)
+		}
+		if result[i].WalletId != result[j].WalletId {
+			return result[i].WalletId < result[j].WalletId
+		}
+		return result[i].Asset < result[j].Asset
+	})
+	return result, nil
+}
+
+// ListDestinationActivity aggregates ledger withdrawals created at or after since by destination, for
+// spotting destinations reused across users. Addresses are matched case-insensitively, as in screening,
+// and reported as first seen. The result is sorted by number of users, then withdrawals, then address.
+func (s *Service) ListDestinationActivity(ctx context.Context, since time.Time) ([]models.DestinationActivity, error) {
+	// As in SummarizeActivity, narrow by a day either side and apply the exact cutoff to parsed times
+	rows, err := s.db.QueryContext(ctx, queryListWithdrawalDestinationsSince, createdAtFrom(since), s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list withdrawal destinations: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	destinations := make(map[string]*models.DestinationActivity)
+	userIndex := make(map[[2]string]int)
+	for rows.Next() {
+		var address, asset, userId, email, amountStr string
+		var createdAt time.Time
+		if err := rows.Scan(&address, &asset, &userId, &email, &amountStr, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan withdrawal destination: %w", err)
+		}
+		if createdAt.Before(since) {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+		}
+
+		key := strings.ToLower(address)
+		destination, ok := destinations[key]
+		if !ok {
+			destination = &models.DestinationActivity{Address: address, Volumes: make(map[string]decimal.Decimal), FirstSeen: createdAt, LastSeen: createdAt}
+			destinations[key] = destination
+		}
+		destination.Count++
+		// Withdrawals are recorded as negative amounts
+		destination.Volumes[asset] = destination.Volumes[asset].Add(amount.Abs())
+		if createdAt.Before(destination.FirstSeen) {
+			destination.FirstSeen = createdAt
+			destination.Address = address
+		}
+		if createdAt.After(destination.LastSeen) {
+			destination.LastSeen = createdAt
+		}
+
+		index, ok := userIndex[[2]string{key, userId}]
+		if !ok {
+			index = len(destination.Users)
+			userIndex[[2]string{key, userId}] = index
+			destination.Users = append(destination.Users, models.DestinationUser{UserId: userId, Email: email})
+		}
+		destination.Users[index].Count++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate withdrawal destinations: %w", err)
+	}
+
+	result := make([]models.DestinationActivity, 0, len(destinations))
+	for _, destination := range destinations {
+		sort.Slice(destination.Users, func(i, j int) bool {
+			if destination.Users[i].Count != destination.Users[j].Count {
+				return destination.Users[i].Count > destination.Users[j].Count
+			}
+			return destination.Users[i].UserId < destination.Users[j].UserId
+		})
+		result = append(result, *destination)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if len(result[i].Users) != len(result[j].Users) {
+			return len(result[i].Users) > len(result[j].Users)
+		}
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Address < result[j].Address
+	})
+	return result, nil
+}
@@ -0,0 +1,183 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSummarizeActivity(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+	if err := service.initWithdrawalHoldSchema(); err != nil {
+		t.Fatalf("Failed to create withdrawal hold schema: %v", err)
+	}
+
+	for _, params := range []ProcessTransactionParams{
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("5"), ExternalTxId: "dep1"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("1.5"), ExternalTxId: "dep2"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-2"), ExternalTxId: "wd1"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("2"), ExternalTxId: "wd1:reversal", ReversalOf: "wd1"},
+	} {
+		if _, err := service.subledger.ProcessTransaction(ctx, params); err != nil {
+			t.Fatalf("ProcessTransaction failed: %v", err)
+		}
+	}
+
+	now := time.Now()
+	totals, err := service.SummarizeActivity(ctx, now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SummarizeActivity failed: %v", err)
+	}
+	expected := []models.ActivityTotal{
+		{Type: "deposit", Asset: "ETH", Count: 2, Total: decimal.RequireFromString("6.5")},
+		{Type: "withdrawal", Asset: "ETH", Count: 1, Total: decimal.RequireFromString("2")},
+		{Type: models.ActivityReversal, Asset: "ETH", Count: 1, Total: decimal.RequireFromString("2")},
+	}
+	if len(totals) != len(expected) {
+		t.Fatalf("Expected %d totals, got %+v", len(expected), totals)
+	}
+	for i, want := range expected {
+		got := totals[i]
+		if got.Type != want.Type || got.Asset != want.Asset || got.Count != want.Count || !got.Total.Equal(want.Total) {
+			t.Errorf("Total %d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	totals, err = service.SummarizeActivity(ctx, now.Add(time.Hour), now.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("SummarizeActivity failed: %v", err)
+	}
+	if len(totals) != 0 {
+		t.Errorf("Expected no activity outside the window, got %+v", totals)
+	}
+}
+
+func TestListWalletActivity(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initPrimeTransactionSchema(); err != nil {
+		t.Fatalf("Failed to create prime transaction schema: %v", err)
+	}
+	if _, err := service.db.Exec(`INSERT INTO addresses (id, user_id, asset, network, address, wallet_id, account_identifier)
+		VALUES ('addr1', 'user1', 'ETH', 'ethereum-mainnet', '0xabc', 'wallet-deposit', 'acct1')`); err != nil {
+		t.Fatalf("Failed to insert address: %v", err)
+	}
+	if err := service.SavePrimeTransaction(ctx, models.PrimeTransaction{
+		Id: "prime-wd1", WalletId: "wallet-withdraw", IdempotencyKey: "wd1", Type: "WITHDRAWAL", Raw: []byte(`{}`),
+	}); err != nil {
+		t.Fatalf("SavePrimeTransaction failed: %v", err)
+	}
+
+	for _, params := range []ProcessTransactionParams{
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("5"), ExternalTxId: "dep1", Address: "0xabc"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("1.5"), ExternalTxId: "dep2", Address: "0xabc"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-2"), ExternalTxId: "wd1"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "adjustment", Amount: decimal.RequireFromString("1"), ExternalTxId: "adj1"},
+	} {
+		if _, err := service.subledger.ProcessTransaction(ctx, params); err != nil {
+			t.Fatalf("ProcessTransaction failed: %v", err)
+		}
+	}
+
+	now := time.Now()
+	activity, err := service.ListWalletActivity(ctx, now.Add(-time.Hour), now.Add(time.Hour), 24*time.Hour)
+	if err != nil {
+		t.Fatalf("ListWalletActivity failed: %v", err)
+	}
+	expected := []models.WalletActivity{
+		{WalletId: "", Asset: "ETH", Count: 1, Volume: decimal.RequireFromString("1")},
+		{WalletId: "wallet-deposit", Asset: "ETH", Count: 2, Volume: decimal.RequireFromString("6.5")},
+		{WalletId: "wallet-withdraw", Asset: "ETH", Count: 1, Volume: decimal.RequireFromString("2")},
+	}
+	if len(activity) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %+v", len(expected), activity)
+	}
+	for i, want := range expected {
+		got := activity[i]
+		if got.WalletId != want.WalletId || got.Asset != want.Asset || got.Count != want.Count || !got.Volume.Equal(want.Volume) {
+			t.Errorf("Bucket %d: expected %+v, got %+v", i, want, got)
+		}
+		if !got.BucketStart.Equal(got.BucketStart.Truncate(24 * time.Hour)) {
+			t.Errorf("Bucket %d: start %s is not aligned to the bucket size", i, got.BucketStart)
+		}
+	}
+
+	if _, err := service.ListWalletActivity(ctx, now.Add(-time.Hour), now, 0); err == nil {
+		t.Error("Expected an error for a zero bucket size")
+	}
+}
+
+func TestListDestinationActivity(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := service.db.Exec("INSERT INTO users (id, name, email) VALUES ('user2', 'Other User', 'other@example.com')"); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+
+	for _, params := range []ProcessTransactionParams{
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("10"), ExternalTxId: "dep1"},
+		{UserId: "user2", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("10"), ExternalTxId: "dep2"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-1"), ExternalTxId: "wd1", Address: "0xShared"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-2"), ExternalTxId: "wd2", Address: "0xshared"},
+		{UserId: "user2", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-0.5"), ExternalTxId: "wd3", Address: "0xSHARED"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-3"), ExternalTxId: "wd4", Address: "0xsolo"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-4"), ExternalTxId: "wd5", Address: "0xreversed"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("4"), ExternalTxId: "wd5:reversal", ReversalOf: "wd5"},
+	} {
+		if _, err := service.subledger.ProcessTransaction(ctx, params); err != nil {
+			t.Fatalf("ProcessTransaction failed: %v", err)
+		}
+	}
+
+	destinations, err := service.ListDestinationActivity(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListDestinationActivity failed: %v", err)
+	}
+	if len(destinations) != 2 {
+		t.Fatalf("Expected the shared and solo destinations without the reversed one, got %+v", destinations)
+	}
+
+	shared := destinations[0]
+	if shared.Address != "0xShared" || shared.Count != 3 || !shared.Volumes["ETH"].Equal(decimal.RequireFromString("3.5")) {
+		t.Errorf("Expected 3 withdrawals of 3.5 ETH to 0xShared, got %+v", shared)
+	}
+	if len(shared.Users) != 2 || shared.Users[0].UserId != "user1" || shared.Users[0].Count != 2 || shared.Users[1].Email != "other@example.com" {
+		t.Errorf("Expected both users, busiest first, got %+v", shared.Users)
+	}
+	if shared.LastSeen.Before(shared.FirstSeen) {
+		t.Errorf("Expected last seen %s to follow first seen %s", shared.LastSeen, shared.FirstSeen)
+	}
+	if destinations[1].Address != "0xsolo" || len(destinations[1].Users) != 1 {
+		t.Errorf("Expected 0xsolo with one user, got %+v", destinations[1])
+	}
+
+	if destinations, err := service.ListDestinationActivity(ctx, time.Now().Add(time.Hour)); err != nil || len(destinations) != 0 {
+		t.Errorf("Expected nothing after the cutoff, got %+v (%v)", destinations, err)
+	}
+}
@@ -0,0 +1,84 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Imported addresses that match no user are attached to this inactive holding user until assigned
+const (
+	ImportHoldingUserId    = "imported-addresses-holding"
+	importHoldingUserName  = "Imported addresses (unassigned)"
+	importHoldingUserEmail = "imported-addresses@holding.invalid"
+)
+
+var ErrAddressNotHeld = errors.New("address is not held for assignment")
+
+// FindAddress returns the stored address on a network regardless of its owner, or nil if it is not stored
+func (s *Service) FindAddress(ctx context.Context, address, network string) (*models.Address, error) {
+	var addr models.Address
+	err := s.db.QueryRowContext(ctx, queryFindAddress, address, network).Scan(
+		&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.Label, &addr.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to query address: %w", err)
+	}
+	return &addr, nil
+}
+
+// ImportHeldAddress stores an address found in Prime under the holding user. The holding user is
+// inactive, so deposits to it are treated like deposits to an unknown address until it is assigned.
+func (s *Service) ImportHeldAddress(ctx context.Context, params StoreAddressParams) (*models.Address, error) {
+	if _, err := s.db.ExecContext(ctx, queryInsertHoldingUser, ImportHoldingUserId, importHoldingUserName, importHoldingUserEmail); err != nil {
+		return nil, fmt.Errorf("unable to create import holding user: %w", err)
+	}
+
+	params.UserId = ImportHoldingUserId
+	return s.StoreAddress(ctx, params)
+}
+
+// AssignHeldAddress moves an imported address from the holding user to a real user
+func (s *Service) AssignHeldAddress(ctx context.Context, addressId, userId string) error {
+	result, err := s.db.ExecContext(ctx, queryReassignAddress, userId, addressId, ImportHoldingUserId)
+	if err != nil {
+		return fmt.Errorf("unable to assign address: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", ErrAddressNotHeld, addressId)
+	}
+
+	s.logger.Info("Assigned imported address",
+		zap.String("address_id", addressId),
+		zap.String("user_id", userId))
+	return nil
+}
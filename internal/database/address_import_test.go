@@ -0,0 +1,76 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestImportHeldAddressAndAssign(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	stored, err := service.FindAddress(ctx, "0xABC", "base-mainnet")
+	if err != nil {
+		t.Fatalf("FindAddress failed: %v", err)
+	}
+	if stored != nil {
+		t.Fatalf("Expected no stored address, got %+v", stored)
+	}
+
+	imported, err := service.ImportHeldAddress(ctx, StoreAddressParams{
+		Asset:             "USDC",
+		Network:           "base-mainnet",
+		Address:           "0xabc",
+		WalletId:          "wallet-1",
+		AccountIdentifier: "0xabc",
+	})
+	if err != nil {
+		t.Fatalf("ImportHeldAddress failed: %v", err)
+	}
+	if imported.UserId != ImportHoldingUserId {
+		t.Errorf("Expected address held by %s, got %s", ImportHoldingUserId, imported.UserId)
+	}
+
+	stored, err = service.FindAddress(ctx, "0xABC", "base-mainnet")
+	if err != nil {
+		t.Fatalf("FindAddress failed: %v", err)
+	}
+	if stored == nil || stored.Id != imported.Id {
+		t.Fatalf("Expected case-insensitive match on imported address, got %+v", stored)
+	}
+
+	if err := service.AssignHeldAddress(ctx, imported.Id, "user1"); err != nil {
+		t.Fatalf("AssignHeldAddress failed: %v", err)
+	}
+
+	addresses, err := service.GetAllUserAddresses(ctx, "user1")
+	if err != nil {
+		t.Fatalf("GetAllUserAddresses failed: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0].Id != imported.Id {
+		t.Errorf("Expected the imported address to belong to user1, got %+v", addresses)
+	}
+
+	// Only held addresses can be assigned, so a user's address cannot be moved by mistake
+	if err := service.AssignHeldAddress(ctx, imported.Id, "user1"); !errors.Is(err, ErrAddressNotHeld) {
+		t.Errorf("Expected ErrAddressNotHeld, got %v", err)
+	}
+}
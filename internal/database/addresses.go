@@ -20,6 +20,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 
 	"prime-send-receive-go/internal/models"
 
@@ -37,7 +38,7 @@ type StoreAddressParams struct {
 }
 
 func (s *Service) StoreAddress(ctx context.Context, params StoreAddressParams) (*models.Address, error) {
-	zap.L().Info("Storing address",
+	s.logger.Info("Storing address",
 		zap.String("user_id", params.UserId),
 		zap.String("asset", params.Asset),
 		zap.String("network", params.Network),
@@ -48,29 +49,29 @@ func (s *Service) StoreAddress(ctx context.Context, params StoreAddressParams) (
 
 	addr := &models.Address{}
 	err := s.db.QueryRowContext(ctx, queryInsertAddress, addressId, params.UserId, params.Asset, params.Network, params.Address, params.WalletId, params.AccountIdentifier).Scan(
-		&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.CreatedAt,
+		&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.Label, &addr.CreatedAt,
 	)
 	if err != nil {
-		zap.L().Error("Failed to insert address",
+		s.logger.Error("Failed to insert address",
 			zap.String("user_id", params.UserId),
 			zap.String("asset", params.Asset),
 			zap.Error(err))
 		return nil, fmt.Errorf("unable to insert address: %w", err)
 	}
 
-	zap.L().Info("Address stored successfully", zap.String("id", addressId))
+	s.logger.Info("Address stored successfully", zap.String("id", addressId))
 	return addr, nil
 }
 
 func (s *Service) GetAddresses(ctx context.Context, userId string, asset string, network string) ([]models.Address, error) {
-	zap.L().Debug("Querying addresses",
+	s.logger.Debug("Querying addresses",
 		zap.String("user_id", userId),
 		zap.String("asset", asset),
 		zap.String("network", network))
 
 	rows, err := s.db.QueryContext(ctx, queryGetUserAddresses, userId, asset, network)
 	if err != nil {
-		zap.L().Error("Failed to query addresses",
+		s.logger.Error("Failed to query addresses",
 			zap.String("user_id", userId),
 			zap.String("asset", asset),
 			zap.String("network", network),
@@ -79,16 +80,16 @@ func (s *Service) GetAddresses(ctx context.Context, userId string, asset string,
 	}
 	defer func(rows *sql.Rows) {
 		if err := rows.Close(); err != nil {
-			zap.L().Warn("Failed to close rows", zap.Error(err))
+			s.logger.Warn("Failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
 	var addresses []models.Address
 	for rows.Next() {
 		var addr models.Address
-		err := rows.Scan(&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.CreatedAt)
+		err := rows.Scan(&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.Label, &addr.CreatedAt)
 		if err != nil {
-			zap.L().Error("Failed to scan address row", zap.Error(err))
+			s.logger.Error("Failed to scan address row", zap.Error(err))
 			return nil, fmt.Errorf("unable to scan address row: %w", err)
 		}
 		addresses = append(addresses, addr)
@@ -96,11 +97,11 @@ func (s *Service) GetAddresses(ctx context.Context, userId string, asset string,
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		zap.L().Error("Error during address row iteration", zap.Error(err))
+		s.logger.Error("Error during address row iteration", zap.Error(err))
 		return nil, fmt.Errorf("error iterating address rows: %w", err)
 	}
 
-	zap.L().Debug("Retrieved addresses",
+	s.logger.Debug("Retrieved addresses",
 		zap.String("user_id", userId),
 		zap.String("asset", asset),
 		zap.String("network", network),
@@ -109,27 +110,27 @@ func (s *Service) GetAddresses(ctx context.Context, userId string, asset string,
 }
 
 func (s *Service) GetAllUserAddresses(ctx context.Context, userId string) ([]models.Address, error) {
-	zap.L().Debug("Querying all addresses for user", zap.String("user_id", userId))
+	s.logger.Debug("Querying all addresses for user", zap.String("user_id", userId))
 
 	rows, err := s.db.QueryContext(ctx, queryGetAllUserAddresses, userId)
 	if err != nil {
-		zap.L().Error("Failed to query all addresses",
+		s.logger.Error("Failed to query all addresses",
 			zap.String("user_id", userId),
 			zap.Error(err))
 		return nil, fmt.Errorf("unable to query all addresses: %w", err)
 	}
 	defer func(rows *sql.Rows) {
 		if err := rows.Close(); err != nil {
-			zap.L().Warn("Failed to close rows", zap.Error(err))
+			s.logger.Warn("Failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
 	var addresses []models.Address
 	for rows.Next() {
 		var addr models.Address
-		err := rows.Scan(&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.CreatedAt)
+		err := rows.Scan(&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.Label, &addr.CreatedAt)
 		if err != nil {
-			zap.L().Error("Failed to scan address row", zap.Error(err))
+			s.logger.Error("Failed to scan address row", zap.Error(err))
 			return nil, fmt.Errorf("unable to scan address row: %w", err)
 		}
 		addresses = append(addresses, addr)
@@ -137,39 +138,117 @@ func (s *Service) GetAllUserAddresses(ctx context.Context, userId string) ([]mod
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		zap.L().Error("Error during address row iteration", zap.Error(err))
+		s.logger.Error("Error during address row iteration", zap.Error(err))
 		return nil, fmt.Errorf("error iterating address rows: %w", err)
 	}
 
-	zap.L().Debug("Retrieved all addresses",
+	s.logger.Debug("Retrieved all addresses",
 		zap.String("user_id", userId),
 		zap.Int("count", len(addresses)))
 	return addresses, nil
 }
 
+// FilterUserAddresses returns a user's addresses, limited to one asset and/or network when they are set
+func (s *Service) FilterUserAddresses(ctx context.Context, userId, asset, network string) ([]models.Address, error) {
+	rows, err := s.db.QueryContext(ctx, queryFilterUserAddresses, userId, asset, asset, network, network)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query addresses: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var addresses []models.Address
+	for rows.Next() {
+		var addr models.Address
+		err := rows.Scan(&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.Label, &addr.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan address row: %w", err)
+		}
+		addresses = append(addresses, addr)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating address rows: %w", err)
+	}
+	return addresses, nil
+}
+
+// ListUsersMissingAddress returns the ids of users without a deposit address for the asset and network
+func (s *Service) ListUsersMissingAddress(ctx context.Context, asset, network string) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, queryListUsersMissingAddress, s.tenantId, s.tenantId, asset, network)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query users missing addresses: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var userIds []string
+	for rows.Next() {
+		var userId string
+		if err := rows.Scan(&userId); err != nil {
+			return nil, fmt.Errorf("unable to scan user id: %w", err)
+		}
+		userIds = append(userIds, userId)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating user rows: %w", err)
+	}
+	return userIds, nil
+}
+
 func (s *Service) FindUserByAddress(ctx context.Context, address string) (*models.User, *models.Address, error) {
-	zap.L().Debug("Finding user by address", zap.String("address", address))
+	s.logger.Debug("Finding user by address", zap.String("address", address))
 
 	var user models.User
 	var addr models.Address
-	err := s.db.QueryRowContext(ctx, queryFindUserByAddress, address).Scan(
-		&user.Id, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt,
-		&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.CreatedAt,
+	err := s.db.QueryRowContext(ctx, queryFindUserByAddress, address, s.tenantId, s.tenantId).Scan(
+		&user.Id, &user.Name, &user.Email, &user.TenantId, &user.CreatedAt, &user.UpdatedAt,
+		&addr.Id, &addr.UserId, &addr.Asset, &addr.Network, &addr.Address, &addr.WalletId, &addr.AccountIdentifier, &addr.Label, &addr.CreatedAt,
 	)
 
 	if err == sql.ErrNoRows {
-		zap.L().Debug("No user found for address", zap.String("address", address))
+		s.logger.Debug("No user found for address", zap.String("address", address))
 		return nil, nil, nil
 	}
 
 	if err != nil {
-		zap.L().Error("Failed to query user by address", zap.String("address", address), zap.Error(err))
+		s.logger.Error("Failed to query user by address", zap.String("address", address), zap.Error(err))
 		return nil, nil, fmt.Errorf("unable to query user by address: %w", err)
 	}
 
-	zap.L().Debug("Found user by address",
+	s.logger.Debug("Found user by address",
 		zap.String("address", address),
 		zap.String("user_id", user.Id),
 		zap.String("user_name", user.Name))
 	return &user, &addr, nil
 }
+
+// SetAddressLabel tags a stored address with its purpose; an empty label clears it
+func (s *Service) SetAddressLabel(ctx context.Context, address, label string) error {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if len(label) > 64 || strings.ContainsAny(label, " \t\n,") {
+		return fmt.Errorf("invalid label %q: use up to 64 characters without spaces or commas", label)
+	}
+
+	result, err := s.db.ExecContext(ctx, querySetAddressLabel, label, address)
+	if err != nil {
+		return fmt.Errorf("unable to label address: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("address not found: %s", address)
+	}
+
+	s.logger.Info("Address labeled", zap.String("address", address), zap.String("label", label))
+	return nil
+}
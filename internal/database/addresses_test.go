@@ -0,0 +1,107 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSetAddressLabel(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := service.StoreAddress(ctx, StoreAddressParams{
+		UserId:            "user1",
+		Asset:             "USDC",
+		Network:           "base-mainnet",
+		Address:           "0xabc",
+		WalletId:          "wallet-1",
+		AccountIdentifier: "0xabc",
+	}); err != nil {
+		t.Fatalf("StoreAddress failed: %v", err)
+	}
+
+	if err := service.SetAddressLabel(ctx, "0xABC", " Exchange-Partner "); err != nil {
+		t.Fatalf("SetAddressLabel failed: %v", err)
+	}
+
+	addresses, err := service.GetAllUserAddresses(ctx, "user1")
+	if err != nil {
+		t.Fatalf("GetAllUserAddresses failed: %v", err)
+	}
+	if len(addresses) != 1 || addresses[0].Label != "exchange-partner" {
+		t.Fatalf("Expected normalized label exchange-partner, got %+v", addresses)
+	}
+
+	if err := service.SetAddressLabel(ctx, "0xabc", "two words"); err == nil {
+		t.Error("Expected a label with spaces to be rejected")
+	}
+	if err := service.SetAddressLabel(ctx, "0xmissing", "legacy"); err == nil {
+		t.Error("Expected labeling an unknown address to fail")
+	}
+}
+
+func TestFilterUserAddressesAndMissing(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := service.db.Exec("INSERT INTO users (id, name, email) VALUES ('user2', 'Other User', 'other@example.com')"); err != nil {
+		t.Fatalf("Failed to insert user: %v", err)
+	}
+
+	for _, params := range []StoreAddressParams{
+		{UserId: "user1", Asset: "USDC", Network: "base-mainnet", Address: "0xbase", WalletId: "wallet-1", AccountIdentifier: "0xbase"},
+		{UserId: "user1", Asset: "USDC", Network: "ethereum-mainnet", Address: "0xeth", WalletId: "wallet-2", AccountIdentifier: "0xeth"},
+		{UserId: "user1", Asset: "BTC", Network: "bitcoin-mainnet", Address: "bc1q", WalletId: "wallet-3", AccountIdentifier: "bc1q"},
+		{UserId: "user2", Asset: "USDC", Network: "ethereum-mainnet", Address: "0xeth2", WalletId: "wallet-2", AccountIdentifier: "0xeth2"},
+	} {
+		if _, err := service.StoreAddress(ctx, params); err != nil {
+			t.Fatalf("StoreAddress failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		asset, network string
+		want           int
+	}{
+		{"", "", 3},
+		{"USDC", "", 2},
+		{"USDC", "base-mainnet", 1},
+		{"", "bitcoin-mainnet", 1},
+		{"ETH", "", 0},
+	}
+	for _, tt := range tests {
+		addresses, err := service.FilterUserAddresses(ctx, "user1", tt.asset, tt.network)
+		if err != nil {
+			t.Fatalf("FilterUserAddresses(%q, %q) failed: %v", tt.asset, tt.network, err)
+		}
+		if len(addresses) != tt.want {
+			t.Errorf("FilterUserAddresses(%q, %q) returned %d addresses, want %d", tt.asset, tt.network, len(addresses), tt.want)
+		}
+	}
+
+	missing, err := service.ListUsersMissingAddress(ctx, "USDC", "base-mainnet")
+	if err != nil {
+		t.Fatalf("ListUsersMissingAddress failed: %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "user2" {
+		t.Errorf("Expected only user2 to be missing a USDC base address, got %v", missing)
+	}
+}
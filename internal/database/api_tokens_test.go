@@ -0,0 +1,73 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestApiTokens_IssueAuthenticateRevoke(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	token, issued, err := service.IssueApiToken(ctx, "user1", "mobile app")
+	if err != nil {
+		t.Fatalf("IssueApiToken failed: %v", err)
+	}
+	if !strings.HasPrefix(token, issued.Prefix) {
+		t.Errorf("Expected token to start with prefix %s", issued.Prefix)
+	}
+
+	var stored string
+	if err := service.db.QueryRow("SELECT token_hash FROM api_tokens WHERE id = ?", issued.Id).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored hash: %v", err)
+	}
+	if stored == token || strings.Contains(stored, token) {
+		t.Fatal("Expected only a hash of the token to be stored")
+	}
+
+	authenticated, err := service.AuthenticateApiToken(ctx, token)
+	if err != nil {
+		t.Fatalf("AuthenticateApiToken failed: %v", err)
+	}
+	if authenticated.UserId != "user1" {
+		t.Errorf("Expected token scoped to user1, got %s", authenticated.UserId)
+	}
+
+	if _, err := service.AuthenticateApiToken(ctx, token+"x"); !errors.Is(err, ErrInvalidApiToken) {
+		t.Errorf("Expected ErrInvalidApiToken for unknown token, got %v", err)
+	}
+
+	if err := service.RevokeApiToken(ctx, issued.Id); err != nil {
+		t.Fatalf("RevokeApiToken failed: %v", err)
+	}
+	if _, err := service.AuthenticateApiToken(ctx, token); !errors.Is(err, ErrInvalidApiToken) {
+		t.Errorf("Expected revoked token to be rejected, got %v", err)
+	}
+
+	tokens, err := service.ListApiTokens(ctx, "user1")
+	if err != nil {
+		t.Fatalf("ListApiTokens failed: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].RevokedAt == nil || tokens[0].LastUsedAt == nil {
+		t.Errorf("Expected one revoked, previously used token, got %+v", tokens)
+	}
+}
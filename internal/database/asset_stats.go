@@ -0,0 +1,118 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"prime-send-receive-go/internal/models"
+)
+
+// GetAssetLedgerStats returns the total balance held for an asset across users and its deposit history
+func (s *Service) GetAssetLedgerStats(ctx context.Context, asset string) (*models.AssetLedgerStats, error) {
+	stats := &models.AssetLedgerStats{Asset: asset}
+
+	rows, err := s.db.QueryContext(ctx, queryListAssetBalances, asset)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query asset balances: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	for rows.Next() {
+		var balanceStr string
+		if err := rows.Scan(&balanceStr); err != nil {
+			return nil, fmt.Errorf("unable to scan asset balance: %w", err)
+		}
+		balance, err := decimal.NewFromString(balanceStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse balance '%s': %w", balanceStr, err)
+		}
+		stats.TotalBalance = stats.TotalBalance.Add(balance)
+		if balance.IsPositive() {
+			stats.Holders++
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating asset balances: %w", err)
+	}
+
+	var lastDeposit sql.NullString
+	if err := s.db.QueryRowContext(ctx, queryGetAssetDepositStats, asset).Scan(&stats.DepositCount, &lastDeposit); err != nil {
+		return nil, fmt.Errorf("unable to query asset deposits: %w", err)
+	}
+
+	if lastDeposit.Valid && lastDeposit.String != "" {
+		lastDepositAt, err := parseSQLiteTimestamp(lastDeposit.String)
+		if err != nil {
+			return nil, err
+		}
+		stats.LastDepositAt = &lastDepositAt
+	}
+
+	return stats, nil
+}
+
+// GetAssetTotals returns, per asset, the total user liability, the number of users with a positive
+// balance and up to topN of the largest holders, ordered by asset
+func (s *Service) GetAssetTotals(ctx context.Context, topN int) ([]models.AssetTotal, error) {
+	balances, err := s.ListAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byAsset := make(map[string]*models.AssetTotal)
+	var assets []string
+	for _, balance := range balances {
+		total, ok := byAsset[balance.Asset]
+		if !ok {
+			total = &models.AssetTotal{Asset: balance.Asset}
+			byAsset[balance.Asset] = total
+			assets = append(assets, balance.Asset)
+		}
+		total.TotalLiability = total.TotalLiability.Add(balance.Balance)
+		if balance.Balance.IsPositive() {
+			total.Holders++
+			if topN > 0 {
+				total.LargestHolders = append(total.LargestHolders, models.AssetHolder{UserId: balance.UserId, Balance: balance.Balance})
+			}
+		}
+	}
+
+	sort.Strings(assets)
+	totals := make([]models.AssetTotal, 0, len(assets))
+	for _, asset := range assets {
+		total := byAsset[asset]
+		sort.SliceStable(total.LargestHolders, func(i, j int) bool {
+			return total.LargestHolders[i].Balance.GreaterThan(total.LargestHolders[j].Balance)
+		})
+		if len(total.LargestHolders) > topN {
+			total.LargestHolders = total.LargestHolders[:topN]
+		}
+		totals = append(totals, *total)
+	}
+	return totals, nil
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// BalanceDiffParams selects the accounts compared by DiffBalances. UserId and Asset are optional filters.
+type BalanceDiffParams struct {
+	From   time.Time
+	To     time.Time
+	UserId string
+	Asset  string
+	// IncludeUnchanged also returns accounts whose transactions in the range net to zero
+	IncludeUnchanged bool
+}
+
+// DiffBalances returns every account whose balance at To differs from its balance at From, with the
+// transactions created in (From, To]. Balances are taken from the ledger entries themselves: the balance
+// before the first transaction in the range and after the last. Results are sorted by user then asset.
+func (s *Service) DiffBalances(ctx context.Context, params BalanceDiffParams) ([]models.BalanceChange, error) {
+	rows, err := s.db.QueryContext(ctx, queryListTransactionsBetween,
+		createdAtFrom(params.From), createdAtTo(params.To), s.tenantId, s.tenantId,
+		params.UserId, params.UserId, params.Asset, params.Asset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	changes := make(map[[2]string]*models.BalanceChange)
+	for rows.Next() {
+		tx, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		if !tx.CreatedAt.After(params.From) || tx.CreatedAt.After(params.To) {
+			continue
+		}
+
+		key := [2]string{tx.UserId, tx.Asset}
+		change, ok := changes[key]
+		if !ok {
+			change = &models.BalanceChange{UserId: tx.UserId, Asset: tx.Asset, Before: tx.BalanceBefore}
+			changes[key] = change
+		}
+		change.After = tx.BalanceAfter
+		change.Net = change.Net.Add(tx.Amount)
+		change.Transactions = append(change.Transactions, *tx)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate transactions: %w", err)
+	}
+
+	result := make([]models.BalanceChange, 0, len(changes))
+	for _, change := range changes {
+		if change.After.Equal(change.Before) && !params.IncludeUnchanged {
+			continue
+		}
+		result = append(result, *change)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].UserId != result[j].UserId {
+			return result[i].UserId < result[j].UserId
+		}
+		return result[i].Asset < result[j].Asset
+	})
+	return result, nil
+}
@@ -0,0 +1,90 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDiffBalances(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	for _, id := range []string{"user1", "user2"} {
+		if _, err := service.CreateUser(ctx, id, id, id+"@example.com"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	apply := func(userId, asset, txType string, amount int64, externalId string, at time.Time) {
+		t.Helper()
+		if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+			UserId: userId, Asset: asset, TransactionType: txType, Amount: decimal.NewFromInt(amount), ExternalTxId: externalId,
+		}); err != nil {
+			t.Fatalf("Failed to apply %s: %v", externalId, err)
+		}
+		if _, err := service.db.Exec("UPDATE transactions SET created_at = ? WHERE external_transaction_id = ?", at, externalId); err != nil {
+			t.Fatalf("Failed to backdate %s: %v", externalId, err)
+		}
+	}
+
+	apply("user1", "ETH", "deposit", 10, "dep-1", base)                     // before the range
+	apply("user1", "ETH", "deposit", 5, "dep-2", base.Add(2*time.Hour))     // in range
+	apply("user1", "ETH", "withdrawal", -3, "wd-1", base.Add(3*time.Hour))  // in range
+	apply("user2", "ETH", "deposit", 4, "dep-3", base.Add(4*time.Hour))     // in range
+	apply("user2", "ETH", "withdrawal", -4, "wd-2", base.Add(5*time.Hour))  // in range, nets to zero
+	apply("user1", "USDC", "deposit", 100, "dep-4", base.Add(48*time.Hour)) // after the range
+
+	params := BalanceDiffParams{From: base.Add(time.Hour), To: base.Add(6 * time.Hour)}
+	changes, err := service.DiffBalances(ctx, params)
+	if err != nil {
+		t.Fatalf("DiffBalances failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Expected only user1's ETH to change, got %+v", changes)
+	}
+	change := changes[0]
+	if change.UserId != "user1" || change.Asset != "ETH" {
+		t.Fatalf("Unexpected account: %+v", change)
+	}
+	if !change.Before.Equal(decimal.NewFromInt(10)) || !change.After.Equal(decimal.NewFromInt(12)) || !change.Net.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("Expected 10 -> 12 (net 2), got %s -> %s (net %s)", change.Before, change.After, change.Net)
+	}
+	if len(change.Transactions) != 2 || change.Transactions[0].ExternalTransactionId != "dep-2" || change.Transactions[1].ExternalTransactionId != "wd-1" {
+		t.Errorf("Expected dep-2 then wd-1, got %+v", change.Transactions)
+	}
+
+	params.IncludeUnchanged = true
+	changes, err = service.DiffBalances(ctx, params)
+	if err != nil || len(changes) != 2 || changes[1].UserId != "user2" || !changes[1].Net.IsZero() {
+		t.Fatalf("Expected user2's net-zero activity to be included, got %+v (%v)", changes, err)
+	}
+
+	params.UserId = "user2"
+	changes, err = service.DiffBalances(ctx, params)
+	if err != nil || len(changes) != 1 || changes[0].UserId != "user2" {
+		t.Fatalf("Expected only user2, got %+v (%v)", changes, err)
+	}
+}
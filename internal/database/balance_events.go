@@ -0,0 +1,34 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+// BalanceChangeHandler is called after a committed ledger change altered a user's balance or available balance
+type BalanceChangeHandler func(userId, asset string)
+
+// OnBalanceChange registers a handler for ledger balance changes, e.g. to invalidate cached balances.
+// Handlers run after the change commits, so they must not block for long. Register them before the
+// service is shared between goroutines.
+func (s *Service) OnBalanceChange(handler BalanceChangeHandler) {
+	s.subledger.balanceChangeHandlers = append(s.subledger.balanceChangeHandlers, handler)
+}
+
+// notifyBalanceChange calls the registered balance change handlers
+func (s *SubledgerService) notifyBalanceChange(userId, asset string) {
+	for _, handler := range s.balanceChangeHandlers {
+		handler(userId, asset)
+	}
+}
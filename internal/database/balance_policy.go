@@ -0,0 +1,150 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+type transactionSourceKey struct{}
+
+// WithTransactionSource returns a context whose ledger debits run under the balance policy of source
+func WithTransactionSource(ctx context.Context, source models.TransactionSource) context.Context {
+	return context.WithValue(ctx, transactionSourceKey{}, source)
+}
+
+// TransactionSourceFrom returns the source attached to ctx, or "" when it has none
+func TransactionSourceFrom(ctx context.Context) models.TransactionSource {
+	source, _ := ctx.Value(transactionSourceKey{}).(models.TransactionSource)
+	return source
+}
+
+// balancePolicy returns the policy for the source attached to ctx. A context without a source, or with
+// a source no policy is configured for, keeps the caller's own fallback.
+func (s *Service) balancePolicy(ctx context.Context, fallback models.BalancePolicy) models.BalancePolicy {
+	if policy, ok := s.balancePolicies[TransactionSourceFrom(ctx)]; ok {
+		return policy
+	}
+	return fallback
+}
+
+// NegativeBalanceHandler is called after a transaction that left an account below zero has been committed
+type NegativeBalanceHandler func(event models.NegativeBalanceEvent)
+
+// logNegativeBalance is the default alert for negative balances
+func (s *SubledgerService) logNegativeBalance(event models.NegativeBalanceEvent) {
+	s.logger.Error("ALERT: account balance went below zero",
+		zap.String("user_id", event.UserId),
+		zap.String("asset", event.Asset),
+		zap.String("transaction_id", event.TransactionId),
+		zap.String("transaction_type", event.TransactionType),
+		zap.String("balance_before", event.BalanceBefore.String()),
+		zap.String("balance_after", event.BalanceAfter.String()),
+		zap.String("policy", event.Policy))
+}
+
+// SetNegativeBalanceHandler replaces the alert raised when an account goes below zero.
+// The handler runs after the transaction commits, so it must not block for long.
+func (s *Service) SetNegativeBalanceHandler(handler NegativeBalanceHandler) {
+	if handler == nil {
+		handler = s.subledger.logNegativeBalance
+	}
+	s.subledger.negativeBalanceHandler = handler
+}
+
+// flagNegativeBalance records a negative balance event within the ledger transaction
+func (s *SubledgerService) flagNegativeBalance(ctx context.Context, tx *sql.Tx, transaction *models.Transaction, policy models.BalancePolicy) (*models.NegativeBalanceEvent, error) {
+	event := &models.NegativeBalanceEvent{
+		Id:              uuid.New().String(),
+		UserId:          transaction.UserId,
+		Asset:           transaction.Asset,
+		TransactionId:   transaction.Id,
+		TransactionType: transaction.TransactionType,
+		BalanceBefore:   transaction.BalanceBefore,
+		BalanceAfter:    transaction.BalanceAfter,
+		Policy:          string(policy),
+		CreatedAt:       transaction.CreatedAt,
+	}
+
+	_, err := tx.ExecContext(ctx, queryInsertNegativeBalanceEvent, event.Id, event.UserId, event.Asset,
+		event.TransactionId, event.TransactionType, event.BalanceBefore.String(), event.BalanceAfter.String(),
+		event.Policy, event.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ListNegativeBalances returns every account currently below zero
+func (s *Service) ListNegativeBalances(ctx context.Context) ([]models.AccountBalance, error) {
+	rows, err := s.db.QueryContext(ctx, queryListNegativeBalances)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list negative balances: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	return scanAccountBalances(rows, s.logger)
+}
+
+// ListNegativeBalanceEvents returns the most recent flagged negative balance events
+func (s *Service) ListNegativeBalanceEvents(ctx context.Context, limit int) ([]models.NegativeBalanceEvent, error) {
+	rows, err := s.db.QueryContext(ctx, queryListNegativeBalanceEvents, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list negative balance events: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var events []models.NegativeBalanceEvent
+	for rows.Next() {
+		var event models.NegativeBalanceEvent
+		var beforeStr, afterStr string
+		if err := rows.Scan(&event.Id, &event.UserId, &event.Asset, &event.TransactionId, &event.TransactionType,
+			&beforeStr, &afterStr, &event.Policy, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan negative balance event: %w", err)
+		}
+		if event.BalanceBefore, err = decimal.NewFromString(beforeStr); err != nil {
+			return nil, fmt.Errorf("failed to parse balance_before '%s': %w", beforeStr, err)
+		}
+		if event.BalanceAfter, err = decimal.NewFromString(afterStr); err != nil {
+			return nil, fmt.Errorf("failed to parse balance_after '%s': %w", afterStr, err)
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating negative balance events: %w", err)
+	}
+
+	return events, nil
+}
@@ -19,6 +19,7 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 
 	"github.com/shopspring/decimal"
@@ -28,7 +29,7 @@ import (
 
 // GetBalance returns current balance for user/asset (O(1) lookup)
 func (s *SubledgerService) GetBalance(ctx context.Context, userId, asset string) (decimal.Decimal, error) {
-	zap.L().Debug("Getting balance", zap.String("user_id", userId), zap.String("asset_network", asset))
+	s.logger.Debug("Getting balance", zap.String("user_id", userId), zap.String("asset_network", asset))
 
 	var balanceStr string
 	err := s.db.QueryRowContext(ctx, queryGetBalance, userId, asset).Scan(&balanceStr)
@@ -37,40 +38,222 @@ func (s *SubledgerService) GetBalance(ctx context.Context, userId, asset string)
 		return decimal.Zero, nil
 	}
 	if err != nil {
-		zap.L().Error("Failed to get balance", zap.String("user_id", userId), zap.String("asset_network", asset), zap.Error(err))
+		s.logger.Error("Failed to get balance", zap.String("user_id", userId), zap.String("asset_network", asset), zap.Error(err))
 		return decimal.Zero, fmt.Errorf("failed to get balance: %w", err)
 	}
 
 	balance, err := decimal.NewFromString(balanceStr)
 	if err != nil {
-		zap.L().Error("Failed to parse balance", zap.String("balance_str", balanceStr), zap.Error(err))
+		s.logger.Error("Failed to parse balance", zap.String("balance_str", balanceStr), zap.Error(err))
 		return decimal.Zero, fmt.Errorf("failed to parse balance: %w", err)
 	}
 
-	zap.L().Debug("Retrieved balance", zap.String("user_id", userId), zap.String("asset_network", asset), zap.String("balance", balance.String()))
+	s.logger.Debug("Retrieved balance", zap.String("user_id", userId), zap.String("asset_network", asset), zap.String("balance", balance.String()))
 	return balance, nil
 }
 
+// GetAvailableBalance returns the portion of a user's balance that may be withdrawn
+func (s *SubledgerService) GetAvailableBalance(ctx context.Context, userId, asset string) (decimal.Decimal, error) {
+	var balanceStr string
+	var availableStr sql.NullString
+	err := s.db.QueryRowContext(ctx, queryGetAvailableBalance, userId, asset).Scan(&balanceStr, &availableStr)
+	if err == sql.ErrNoRows {
+		return decimal.Zero, nil
+	}
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get available balance: %w", err)
+	}
+
+	balance, err := decimal.NewFromString(balanceStr)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to parse balance: %w", err)
+	}
+	return parseAvailable(availableStr, balance)
+}
+
+// parseAvailable parses an available_balance column, which is only NULL before backfillAvailableBalances has run
+func parseAvailable(availableStr sql.NullString, balance decimal.Decimal) (decimal.Decimal, error) {
+	if !availableStr.Valid {
+		return balance, nil
+	}
+	available, err := decimal.NewFromString(availableStr.String)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to parse available balance '%s': %w", availableStr.String, err)
+	}
+	return available, nil
+}
+
+// backfillAvailableBalances sets the available balance of accounts created before it was tracked
+// to their balance less any deposits still held
+func (s *SubledgerService) backfillAvailableBalances() error {
+	ctx := context.Background()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	type unsplit struct {
+		userId, asset string
+		balance       decimal.Decimal
+	}
+	var accounts []unsplit
+	rows, err := tx.QueryContext(ctx, queryListUnsplitBalances)
+	if err != nil {
+		return fmt.Errorf("failed to query balances without available amount: %w", err)
+	}
+	for rows.Next() {
+		var account unsplit
+		var balanceStr string
+		if err := rows.Scan(&account.userId, &account.asset, &balanceStr); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan balance: %w", err)
+		}
+		if account.balance, err = decimal.NewFromString(balanceStr); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to parse balance '%s': %w", balanceStr, err)
+		}
+		accounts = append(accounts, account)
+	}
+	if err := rows.Close(); err != nil {
+		return fmt.Errorf("failed to close balance rows: %w", err)
+	}
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	for _, account := range accounts {
+		held, err := s.heldAmount(ctx, tx, account.userId, account.asset)
+		if err != nil {
+			return err
+		}
+		available := account.balance.Sub(held)
+		if _, err := tx.ExecContext(ctx, queryUpdateUnsplitBalance, available.String(), account.userId, account.asset); err != nil {
+			return fmt.Errorf("failed to set available balance: %w", err)
+		}
+	}
+
+	s.logger.Info("Backfilled available balances", zap.Int("accounts", len(accounts)))
+	return tx.Commit()
+}
+
 // GetAllBalances returns all non-zero balances for a user
 func (s *SubledgerService) GetAllBalances(ctx context.Context, userId string) ([]models.AccountBalance, error) {
-	zap.L().Debug("Getting all balances", zap.String("user_id", userId))
+	s.logger.Debug("Getting all balances", zap.String("user_id", userId))
 
 	rows, err := s.db.QueryContext(ctx, queryGetAllUserBalances, userId)
 	if err != nil {
-		zap.L().Error("Failed to get all balances", zap.String("user_id", userId), zap.Error(err))
+		s.logger.Error("Failed to get all balances", zap.String("user_id", userId), zap.Error(err))
+		return nil, fmt.Errorf("failed to get all balances: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	balances, err := scanAccountBalances(rows, s.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug("Retrieved all balances", zap.String("user_id", userId), zap.Int("count", len(balances)))
+	return balances, nil
+}
+
+// GetBalancesVersion returns a token that changes whenever any of the user's balances change, for
+// answering conditional requests without reading the balances
+func (s *SubledgerService) GetBalancesVersion(ctx context.Context, userId string) (string, error) {
+	var accounts, versions int64
+	if err := s.db.QueryRowContext(ctx, queryGetUserBalancesVersion, userId).Scan(&accounts, &versions); err != nil {
+		return "", fmt.Errorf("unable to get balances version: %w", err)
+	}
+	return fmt.Sprintf("%d-%d", accounts, versions), nil
+}
+
+// GetAllBalancesIncludingZero returns every balance row for a user, keeping accounts that have been emptied
+func (s *SubledgerService) GetAllBalancesIncludingZero(ctx context.Context, userId string) ([]models.AccountBalance, error) {
+	rows, err := s.db.QueryContext(ctx, queryGetAllUserBalancesIncludingZero, userId)
+	if err != nil {
 		return nil, fmt.Errorf("failed to get all balances: %w", err)
 	}
 	defer func(rows *sql.Rows) {
 		if err := rows.Close(); err != nil {
-			zap.L().Warn("Failed to close rows", zap.Error(err))
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	return scanAccountBalances(rows, s.logger)
+}
+
+// GetBalancesBulk returns the balance rows of one asset for many users in a single query, limited to
+// one tenant unless tenantId is empty. Users without an account in the asset are left out.
+func (s *SubledgerService) GetBalancesBulk(ctx context.Context, userIds []string, asset, tenantId string) ([]models.AccountBalance, error) {
+	if len(userIds) == 0 {
+		return nil, nil
+	}
+
+	ids, err := json.Marshal(userIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode user ids: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, queryGetBalancesBulk, asset, string(ids), tenantId, tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balances for %d users: %w", len(userIds), err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	return scanAccountBalances(rows, s.logger)
+}
+
+// ListAccountBalances returns every non-zero balance in the ledger ordered by user and asset,
+// limited to one tenant unless tenantId is empty
+func (s *SubledgerService) ListAccountBalances(ctx context.Context, tenantId string) ([]models.AccountBalance, error) {
+	rows, err := s.db.QueryContext(ctx, queryListAccountBalances, tenantId, tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account balances: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
+	return scanAccountBalances(rows, s.logger)
+}
+
+// ListAllAccountBalances returns every balance row including zero balances, ordered by user and
+// asset and limited to one tenant unless tenantId is empty
+func (s *SubledgerService) ListAllAccountBalances(ctx context.Context, tenantId string) ([]models.AccountBalance, error) {
+	rows, err := s.db.QueryContext(ctx, queryListAllAccountBalances, tenantId, tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list all account balances: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	return scanAccountBalances(rows, s.logger)
+}
+
+func scanAccountBalances(rows *sql.Rows, logger *zap.Logger) ([]models.AccountBalance, error) {
 	var balances []models.AccountBalance
 	for rows.Next() {
 		var balance models.AccountBalance
 		var balanceStr string
-		err := rows.Scan(&balance.Id, &balance.UserId, &balance.Asset, &balanceStr,
+		var availableStr sql.NullString
+		err := rows.Scan(&balance.Id, &balance.UserId, &balance.Asset, &balanceStr, &availableStr,
 			&balance.LastTransactionId, &balance.Version, &balance.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan balance: %w", err)
@@ -80,23 +263,26 @@ func (s *SubledgerService) GetAllBalances(ctx context.Context, userId string) ([
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse balance '%s': %w", balanceStr, err)
 		}
+		balance.Available, err = parseAvailable(availableStr, balance.Balance)
+		if err != nil {
+			return nil, err
+		}
 
 		balances = append(balances, balance)
 	}
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		zap.L().Error("Error during balance row iteration", zap.Error(err))
+		logger.Error("Error during balance row iteration", zap.Error(err))
 		return nil, fmt.Errorf("error iterating balance rows: %w", err)
 	}
 
-	zap.L().Debug("Retrieved all balances", zap.String("user_id", userId), zap.Int("count", len(balances)))
 	return balances, nil
 }
 
 // ReconcileBalance verifies that current balance matches sum of all transactions
 func (s *SubledgerService) ReconcileBalance(ctx context.Context, userId, asset string) error {
-	zap.L().Info("Reconciling balance", zap.String("user_id", userId), zap.String("asset_network", asset))
+	s.logger.Info("Reconciling balance", zap.String("user_id", userId), zap.String("asset_network", asset))
 
 	// Get current balance from account_balances table
 	currentBalance, err := s.GetBalance(ctx, userId, asset)
@@ -105,20 +291,35 @@ func (s *SubledgerService) ReconcileBalance(ctx context.Context, userId, asset s
 	}
 
 	// Calculate balance from transaction history
-	var calculatedBalanceStr string
-	err = s.db.QueryRowContext(ctx, queryReconcileBalance, userId, asset).Scan(&calculatedBalanceStr)
+	rows, err := s.db.QueryContext(ctx, queryReconcileBalance, userId, asset)
 	if err != nil {
 		return fmt.Errorf("failed to calculate balance from transactions: %w", err)
 	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
 
-	calculatedBalance, err := decimal.NewFromString(calculatedBalanceStr)
-	if err != nil {
-		return fmt.Errorf("failed to parse calculated balance '%s': %w", calculatedBalanceStr, err)
+	calculatedBalance := decimal.Zero
+	for rows.Next() {
+		var amountStr string
+		if err := rows.Scan(&amountStr); err != nil {
+			return fmt.Errorf("failed to scan transaction amount: %w", err)
+		}
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse transaction amount '%s': %w", amountStr, err)
+		}
+		calculatedBalance = calculatedBalance.Add(amount)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating transaction amounts: %w", err)
 	}
 
 	// Check if balances match (exact decimal comparison)
 	if !currentBalance.Equal(calculatedBalance) {
-		zap.L().Error("Balance reconciliation failed",
+		s.logger.Error("Balance reconciliation failed",
 			zap.String("user_id", userId),
 			zap.String("asset_network", asset),
 			zap.String("current_balance", currentBalance.String()),
@@ -127,7 +328,7 @@ func (s *SubledgerService) ReconcileBalance(ctx context.Context, userId, asset s
 		return fmt.Errorf("balance mismatch: current=%s, calculated=%s", currentBalance.String(), calculatedBalance.String())
 	}
 
-	zap.L().Info("Balance reconciliation successful",
+	s.logger.Info("Balance reconciliation successful",
 		zap.String("user_id", userId),
 		zap.String("asset_network", asset),
 		zap.String("balance", currentBalance.String()))
@@ -18,64 +18,45 @@ package database
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
 	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
 )
 
-func setupBalanceTestDB(t *testing.T) (*Service, func()) {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	subledger := NewSubledgerService(db)
-	service := &Service{
-		db:        db,
-		subledger: subledger,
-	}
-
-	if err := subledger.InitSchema(); err != nil {
-		t.Fatalf("Failed to create subledger schema: %v", err)
-	}
-
-	additionalSchema := `
-		CREATE TABLE users (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			email TEXT NOT NULL UNIQUE
-		);
+// openTestDB opens an in-memory database with the full schema and settings from cfg, e.g. BalancePolicies.
+// Tests outside this package use dbtest.OpenConfig, which this package cannot import.
+func openTestDB(t *testing.T, cfg models.DatabaseConfig) *Service {
+	t.Helper()
 
-		CREATE TABLE addresses (
-			id TEXT PRIMARY KEY,
-			user_id TEXT NOT NULL,
-			asset TEXT NOT NULL,
-			network TEXT NOT NULL,
-			address TEXT NOT NULL UNIQUE,
-			wallet_id TEXT NOT NULL,
-			account_identifier TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-			FOREIGN KEY (user_id) REFERENCES users(id)
-		);
-	`
-
-	if _, err := db.Exec(additionalSchema); err != nil {
-		t.Fatalf("Failed to create additional test schema: %v", err)
+	// Every connection to :memory: opens its own empty database, so exactly one is kept open
+	cfg.Path = ":memory:"
+	cfg.MaxOpenConns = 1
+	cfg.MaxIdleConns = 1
+	if cfg.PingTimeout == 0 {
+		cfg.PingTimeout = time.Second
 	}
 
-	_, err = db.Exec("INSERT INTO users (id, name, email) VALUES (?, ?, ?)",
-		"user1", "Test User", "test@example.com")
+	service, err := NewService(context.Background(), cfg, zaptest.NewLogger(t))
 	if err != nil {
-		t.Fatalf("Failed to insert test user: %v", err)
+		t.Fatalf("Failed to open test database: %v", err)
 	}
+	return service
+}
 
-	cleanup := func() {
-		db.Close()
+// setupBalanceTestDB opens an in-memory database with the full schema and one user, user1
+func setupBalanceTestDB(t *testing.T) (*Service, func()) {
+	service := openTestDB(t, models.DatabaseConfig{})
+	if _, err := service.CreateUser(context.Background(), "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to insert test user: %v", err)
 	}
 
-	return service, cleanup
+	return service, service.Close
 }
 
 func TestGetUserBalance_NoBalance(t *testing.T) {
@@ -105,13 +86,13 @@ func TestGetUserBalance_WithTransactions(t *testing.T) {
 	asset := "BTC"
 
 	depositAmount := decimal.NewFromFloat(2.0)
-	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "deposit", depositAmount, "tx1", "addr1", ""})
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "deposit", Amount: depositAmount, ExternalTxId: "tx1", Address: "addr1"})
 	if err != nil {
 		t.Fatalf("Failed to create deposit: %v", err)
 	}
 
 	withdrawalAmount := decimal.NewFromFloat(-0.5)
-	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "withdrawal", withdrawalAmount, "tx2", "", ""})
+	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "withdrawal", Amount: withdrawalAmount, ExternalTxId: "tx2"})
 	if err != nil {
 		t.Fatalf("Failed to create withdrawal: %v", err)
 	}
@@ -135,13 +116,13 @@ func TestGetAllUserBalances(t *testing.T) {
 	userId := "user1"
 
 	btcAmount := decimal.NewFromFloat(1.0)
-	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{userId, "BTC", "deposit", btcAmount, "tx1", "", ""})
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: "BTC", TransactionType: "deposit", Amount: btcAmount, ExternalTxId: "tx1"})
 	if err != nil {
 		t.Fatalf("Failed to create BTC deposit: %v", err)
 	}
 
 	ethAmount := decimal.NewFromFloat(10.0)
-	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{userId, "ETH", "deposit", ethAmount, "tx2", "", ""})
+	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: "ETH", TransactionType: "deposit", Amount: ethAmount, ExternalTxId: "tx2"})
 	if err != nil {
 		t.Fatalf("Failed to create ETH deposit: %v", err)
 	}
@@ -169,3 +150,251 @@ func TestGetAllUserBalances(t *testing.T) {
 		t.Errorf("Expected ETH balance %s, got %s", expectedETH.String(), found["ETH"].String())
 	}
 }
+
+func TestListAllAccountBalances_IncludesZero(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userId := "user1"
+
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromFloat(1.0), ExternalTxId: "tx1"})
+	if err != nil {
+		t.Fatalf("Failed to create BTC deposit: %v", err)
+	}
+	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromFloat(2.0), ExternalTxId: "tx2"})
+	if err != nil {
+		t.Fatalf("Failed to create ETH deposit: %v", err)
+	}
+	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.NewFromFloat(-2.0), ExternalTxId: "tx3"})
+	if err != nil {
+		t.Fatalf("Failed to create ETH withdrawal: %v", err)
+	}
+
+	nonZero, err := service.ListAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("ListAccountBalances failed: %v", err)
+	}
+	if len(nonZero) != 1 {
+		t.Fatalf("Expected 1 non-zero balance, got %d", len(nonZero))
+	}
+
+	all, err := service.ListAllAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("ListAllAccountBalances failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 balances, got %d", len(all))
+	}
+	if all[1].Asset != "ETH" || !all[1].Balance.IsZero() {
+		t.Errorf("Expected zero ETH balance, got %s %s", all[1].Asset, all[1].Balance.String())
+	}
+	if all[1].LastTransactionId == "" || all[1].Version < 2 {
+		t.Errorf("Expected ETH version and last transaction to be set, got v%d %q", all[1].Version, all[1].LastTransactionId)
+	}
+}
+
+func TestGetBalancesBulk(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// More ids than SQLite allows bind parameters in older builds, to show they are sent as one value
+	const users = 2000
+	userIds := make([]string, 0, users+1)
+	for i := 0; i < users; i++ {
+		userId := fmt.Sprintf("user-%04d", i)
+		userIds = append(userIds, userId)
+		if i%2 == 1 {
+			continue
+		}
+		_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(int64(i + 1)), ExternalTxId: "tx-" + userId})
+		if err != nil {
+			t.Fatalf("Failed to create deposit: %v", err)
+		}
+	}
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user-0000", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: "tx-btc"})
+	if err != nil {
+		t.Fatalf("Failed to create deposit: %v", err)
+	}
+	userIds = append(userIds, "unknown")
+
+	balances, err := service.GetBalancesBulk(ctx, userIds, "USDC")
+	if err != nil {
+		t.Fatalf("GetBalancesBulk failed: %v", err)
+	}
+	if len(balances) != users/2 {
+		t.Fatalf("Expected %d balances, got %d", users/2, len(balances))
+	}
+	if balances[1].UserId != "user-0002" || !balances[1].Balance.Equal(decimal.NewFromInt(3)) || balances[1].Asset != "USDC" {
+		t.Errorf("Unexpected balance: %+v", balances[1])
+	}
+
+	none, err := service.GetBalancesBulk(ctx, nil, "USDC")
+	if err != nil || len(none) != 0 {
+		t.Errorf("Expected no balances for no users, got %v (%v)", none, err)
+	}
+}
+
+func TestGetAssetLedgerStats(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	stats, err := service.GetAssetLedgerStats(ctx, "USDC")
+	if err != nil {
+		t.Fatalf("GetAssetLedgerStats failed: %v", err)
+	}
+	if !stats.TotalBalance.IsZero() || stats.DepositCount != 0 || stats.LastDepositAt != nil {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+
+	transactions := []ProcessTransactionParams{
+		{UserId: "user1", Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(100), ExternalTxId: "tx1", Address: "addr1"},
+		{UserId: "user1", Asset: "USDC", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-30), ExternalTxId: "tx2"},
+		{UserId: "user1", Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(30), ExternalTxId: "tx2:reversal", ReversalOf: "tx2"},
+		{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: "tx3", Address: "addr1"},
+	}
+	for _, params := range transactions {
+		if _, err := service.subledger.ProcessTransaction(ctx, params); err != nil {
+			t.Fatalf("ProcessTransaction failed: %v", err)
+		}
+	}
+
+	stats, err = service.GetAssetLedgerStats(ctx, "USDC")
+	if err != nil {
+		t.Fatalf("GetAssetLedgerStats failed: %v", err)
+	}
+	if !stats.TotalBalance.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("Expected total balance 100, got %s", stats.TotalBalance)
+	}
+	if stats.Holders != 1 {
+		t.Errorf("Expected 1 holder, got %d", stats.Holders)
+	}
+	// Reversals are credits but not deposits
+	if stats.DepositCount != 1 {
+		t.Errorf("Expected 1 deposit, got %d", stats.DepositCount)
+	}
+	if stats.LastDepositAt == nil {
+		t.Error("Expected a last deposit time")
+	}
+}
+
+func TestOnBalanceChange(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	var changed []string
+	service.OnBalanceChange(func(userId, asset string) {
+		changed = append(changed, userId+"/"+asset)
+	})
+
+	ctx := context.Background()
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: "tx1"})
+	if err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+
+	// A rejected debit changes nothing and must not be reported
+	_, err = service.subledger.ProcessTransactionWithPolicy(ctx, ProcessTransactionParams{UserId: "user1", Asset: "BTC", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-5), ExternalTxId: "tx2"}, models.BalancePolicyCustomer)
+	if err == nil {
+		t.Fatal("Expected insufficient balance error")
+	}
+
+	if len(changed) != 1 || changed[0] != "user1/BTC" {
+		t.Errorf("Expected one change for user1/BTC, got %v", changed)
+	}
+}
+
+func TestGetAssetTotals(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, id := range []string{"user2", "user3"} {
+		if _, err := service.db.Exec("INSERT INTO users (id, name, email) VALUES (?, ?, ?)", id, id, id+"@example.com"); err != nil {
+			t.Fatalf("Failed to insert user: %v", err)
+		}
+	}
+
+	deposits := []struct {
+		userId string
+		asset  string
+		amount float64
+	}{
+		{"user1", "BTC", 1.0},
+		{"user2", "BTC", 3.0},
+		{"user3", "BTC", 2.0},
+		{"user1", "ETH", 5.0},
+		{"user2", "ETH", -1.0},
+	}
+	for i, d := range deposits {
+		txType := "deposit"
+		if d.amount < 0 {
+			txType = "withdrawal"
+		}
+		_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: d.userId, Asset: d.asset, TransactionType: txType, Amount: decimal.NewFromFloat(d.amount), ExternalTxId: fmt.Sprintf("tx%d", i)})
+		if err != nil {
+			t.Fatalf("Failed to create transaction: %v", err)
+		}
+	}
+
+	totals, err := service.GetAssetTotals(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetAssetTotals failed: %v", err)
+	}
+	if len(totals) != 2 {
+		t.Fatalf("Expected 2 assets, got %d", len(totals))
+	}
+
+	btc := totals[0]
+	if btc.Asset != "BTC" || !btc.TotalLiability.Equal(decimal.NewFromFloat(6.0)) || btc.Holders != 3 {
+		t.Errorf("Unexpected BTC totals: %+v", btc)
+	}
+	if len(btc.LargestHolders) != 2 || btc.LargestHolders[0].UserId != "user2" || btc.LargestHolders[1].UserId != "user3" {
+		t.Errorf("Expected user2 then user3 as largest BTC holders, got %+v", btc.LargestHolders)
+	}
+
+	// Overdrawn accounts reduce the liability but are not holders
+	eth := totals[1]
+	if eth.Asset != "ETH" || !eth.TotalLiability.Equal(decimal.NewFromFloat(4.0)) || eth.Holders != 1 || len(eth.LargestHolders) != 1 {
+		t.Errorf("Unexpected ETH totals: %+v", eth)
+	}
+}
+
+func TestGetUserBalancesVersion(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	version := func() string {
+		t.Helper()
+		v, err := service.GetUserBalancesVersion(ctx, "user1")
+		if err != nil {
+			t.Fatalf("GetUserBalancesVersion failed: %v", err)
+		}
+		return v
+	}
+
+	seen := map[string]bool{version(): true}
+	steps := []ProcessTransactionParams{
+		{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(2), ExternalTxId: "tx1"},
+		{UserId: "user1", Asset: "BTC", TransactionType: "withdrawal", Amount: decimal.NewFromInt(1), ExternalTxId: "tx2"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: "tx3"},
+	}
+	for _, step := range steps {
+		if _, err := service.subledger.ProcessTransaction(ctx, step); err != nil {
+			t.Fatalf("ProcessTransaction %s failed: %v", step.ExternalTxId, err)
+		}
+		v := version()
+		if seen[v] {
+			t.Errorf("Expected a new version after %s, got %s again", step.ExternalTxId, v)
+		}
+		seen[v] = true
+	}
+
+	if first, second := version(), version(); first != second {
+		t.Errorf("Expected the version to be stable without changes, got %s then %s", first, second)
+	}
+}
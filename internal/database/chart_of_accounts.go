@@ -0,0 +1,167 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"gopkg.in/yaml.v2"
+)
+
+var accountPlaceholder = regexp.MustCompile(`\{[^}]*\}`)
+
+// DefaultChartOfAccounts returns the journal accounts used when no chart of accounts file is configured
+func DefaultChartOfAccounts() models.ChartOfAccounts {
+	return models.ChartOfAccounts{
+		UserAsset:         models.LedgerAccount{Type: "user_asset", Id: "{user}_{asset}"},
+		CustomerLiability: models.LedgerAccount{Type: "system_liability", Id: "user_deposits_{asset}"},
+		Rewards:           models.LedgerAccount{Type: "rewards_funding", Id: "rewards_{asset}"},
+		Interest:          models.LedgerAccount{Type: "interest_expense", Id: "interest_{asset}"},
+		Suspense:          models.LedgerAccount{Type: "suspense", Id: "suspense_{asset}"},
+		SuspenseClearing:  models.LedgerAccount{Type: "suspense_clearing", Id: "suspense_clearing_{asset}"},
+		Fees:              models.LedgerAccount{Type: "fee_expense", Id: "fees_{asset}"},
+		Revenue:           models.LedgerAccount{Type: "revenue", Id: "revenue_{asset}"},
+		Treasury:          models.LedgerAccount{Type: "treasury", Id: "treasury_{asset}"},
+	}
+}
+
+// LoadChartOfAccounts reads a chart of accounts YAML file. Roles missing from the file keep their
+// default account, and the result is validated before it is returned.
+func LoadChartOfAccounts(path string) (models.ChartOfAccounts, error) {
+	chart := DefaultChartOfAccounts()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return chart, fmt.Errorf("unable to read %s: %w", path, err)
+	}
+
+	var overrides models.ChartOfAccounts
+	if err := yaml.UnmarshalStrict(data, &overrides); err != nil {
+		return chart, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+
+	for _, role := range chartRoles() {
+		override := role.account(&overrides)
+		if override.Type != "" {
+			role.account(&chart).Type = override.Type
+		}
+		if override.Id != "" {
+			role.account(&chart).Id = override.Id
+		}
+	}
+
+	if err := ValidateChartOfAccounts(chart); err != nil {
+		return chart, fmt.Errorf("invalid chart of accounts in %s: %w", path, err)
+	}
+
+	return chart, nil
+}
+
+// ValidateChartOfAccounts checks every role has an account, placeholders are known, ids are per asset
+// (and per user for user_asset), and no two roles post to the same account
+func ValidateChartOfAccounts(chart models.ChartOfAccounts) error {
+	seen := make(map[string]string)
+	for _, role := range chartRoles() {
+		account := role.account(&chart)
+		if account.Type == "" || account.Id == "" {
+			return fmt.Errorf("%s: type and id are required", role.name)
+		}
+
+		for _, placeholder := range accountPlaceholder.FindAllString(account.Id, -1) {
+			if placeholder != "{asset}" && placeholder != "{user}" {
+				return fmt.Errorf("%s: unknown placeholder %s in id %q", role.name, placeholder, account.Id)
+			}
+		}
+		if !strings.Contains(account.Id, "{asset}") {
+			return fmt.Errorf("%s: id %q must contain {asset}", role.name, account.Id)
+		}
+		hasUser := strings.Contains(account.Id, "{user}")
+		if role.perUser && !hasUser {
+			return fmt.Errorf("%s: id %q must contain {user}", role.name, account.Id)
+		}
+		if !role.perUser && hasUser {
+			return fmt.Errorf("%s: id %q cannot contain {user}", role.name, account.Id)
+		}
+
+		key := account.Type + "/" + account.Id
+		if other, ok := seen[key]; ok {
+			return fmt.Errorf("%s and %s both post to %s %s", other, role.name, account.Type, account.Id)
+		}
+		seen[key] = role.name
+	}
+	return nil
+}
+
+// SetChartOfAccounts validates and replaces the chart used for new journal entries
+func (s *SubledgerService) SetChartOfAccounts(chart models.ChartOfAccounts) error {
+	if err := ValidateChartOfAccounts(chart); err != nil {
+		return err
+	}
+	s.chart = chart
+	return nil
+}
+
+// ChartOfAccounts returns the chart used for journal entries
+func (s *SubledgerService) ChartOfAccounts() models.ChartOfAccounts {
+	return s.chart
+}
+
+// ChartOfAccounts returns the chart used for journal entries
+func (s *Service) ChartOfAccounts() models.ChartOfAccounts {
+	return s.subledger.ChartOfAccounts()
+}
+
+type chartRole struct {
+	name    string
+	perUser bool
+	account func(chart *models.ChartOfAccounts) *models.LedgerAccount
+}
+
+// chartRoles lists the roles of a chart in a fixed order, named as in the YAML file
+func chartRoles() []chartRole {
+	return []chartRole{
+		{"user_asset", true, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.UserAsset }},
+		{"customer_liability", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.CustomerLiability }},
+		{"rewards", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.Rewards }},
+		{"interest", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.Interest }},
+		{"suspense", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.Suspense }},
+		{"suspense_clearing", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.SuspenseClearing }},
+		{"fees", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.Fees }},
+		{"revenue", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.Revenue }},
+		{"treasury", false, func(c *models.ChartOfAccounts) *models.LedgerAccount { return &c.Treasury }},
+	}
+}
+
+// journalEntry is one side of a double-entry posting
+type journalEntry struct {
+	accountType  string
+	accountId    string
+	debitAmount  decimal.Decimal
+	creditAmount decimal.Decimal
+}
+
+// newJournalEntry posts to account, resolving its id placeholders for the transaction
+func newJournalEntry(account models.LedgerAccount, transaction *models.Transaction, debitAmount, creditAmount decimal.Decimal) journalEntry {
+	accountId := strings.NewReplacer("{user}", transaction.UserId, "{asset}", transaction.Asset).Replace(account.Id)
+	return journalEntry{account.Type, accountId, debitAmount, creditAmount}
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestValidateChartOfAccounts(t *testing.T) {
+	if err := ValidateChartOfAccounts(DefaultChartOfAccounts()); err != nil {
+		t.Fatalf("Default chart should be valid: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(chart *models.ChartOfAccounts)
+		wantErr string
+	}{
+		{"missing asset placeholder", func(c *models.ChartOfAccounts) { c.Fees.Id = "fees" }, "must contain {asset}"},
+		{"user asset without user", func(c *models.ChartOfAccounts) { c.UserAsset.Id = "customer_{asset}" }, "must contain {user}"},
+		{"unknown placeholder", func(c *models.ChartOfAccounts) { c.Revenue.Id = "revenue_{network}_{asset}" }, "unknown placeholder"},
+		{"duplicate account", func(c *models.ChartOfAccounts) { c.Treasury = c.Revenue }, "both post to"},
+		{"missing type", func(c *models.ChartOfAccounts) { c.Interest.Type = "" }, "type and id are required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chart := DefaultChartOfAccounts()
+			tt.mutate(&chart)
+			err := ValidateChartOfAccounts(chart)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestChartOfAccounts_JournalEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chart_of_accounts.yaml")
+	contents := `
+user_asset:
+  type: "2100"
+  id: "cust_{user}_{asset}"
+customer_liability:
+  type: "2000"
+`
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatalf("Failed to write chart file: %v", err)
+	}
+
+	chart, err := LoadChartOfAccounts(path)
+	if err != nil {
+		t.Fatalf("LoadChartOfAccounts failed: %v", err)
+	}
+	if chart.CustomerLiability.Id != DefaultChartOfAccounts().CustomerLiability.Id {
+		t.Errorf("Expected omitted id to keep its default, got %q", chart.CustomerLiability.Id)
+	}
+
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	if err := service.subledger.SetChartOfAccounts(chart); err != nil {
+		t.Fatalf("SetChartOfAccounts failed: %v", err)
+	}
+
+	ctx := context.Background()
+	transaction, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "prime-tx-1", Address: "0xabc",
+	})
+	if err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+
+	rows, err := service.db.Query("SELECT account_type, account_id FROM journal_entries WHERE transaction_id = ? ORDER BY account_type", transaction.Id)
+	if err != nil {
+		t.Fatalf("Failed to query journal entries: %v", err)
+	}
+	defer rows.Close()
+
+	var accounts []string
+	for rows.Next() {
+		var accountType, accountId string
+		if err := rows.Scan(&accountType, &accountId); err != nil {
+			t.Fatalf("Failed to scan journal entry: %v", err)
+		}
+		accounts = append(accounts, accountType+"/"+accountId)
+	}
+
+	want := []string{"2000/user_deposits_USDC", "2100/cust_user1_USDC"}
+	if strings.Join(accounts, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected journal accounts %v, got %v", want, accounts)
+	}
+
+	if err := os.WriteFile(path, []byte("fees:\n  typo: x\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write chart file: %v", err)
+	}
+	if _, err := LoadChartOfAccounts(path); err == nil {
+		t.Error("Expected unknown keys to be rejected")
+	}
+}
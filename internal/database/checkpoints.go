@@ -0,0 +1,137 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func (s *Service) initCheckpointSchema() error {
+	schema := `
+	-- Progress of long-running batch commands, so an interrupted run can resume
+	CREATE TABLE IF NOT EXISTS command_checkpoints (
+		command TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL DEFAULT '',
+		asset TEXT NOT NULL DEFAULT '',
+		wallet_id TEXT NOT NULL DEFAULT '',
+		processed INTEGER NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Where the listener got to in each wallet's transactions
+	CREATE TABLE IF NOT EXISTS listener_checkpoints (
+		wallet_id TEXT PRIMARY KEY,
+		polled_through TIMESTAMP NOT NULL,
+		last_seen_tx_time TIMESTAMP,
+		last_seen_tx_id TEXT NOT NULL DEFAULT '',
+		oldest_open_tx_time TIMESTAMP
+	);
+	`
+
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Checkpoints created before the listener kept a transaction cursor
+	if err := addColumnIfMissing(s.db, s.logger, "listener_checkpoints", "last_seen_tx_time", "TIMESTAMP"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(s.db, s.logger, "listener_checkpoints", "last_seen_tx_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	return addColumnIfMissing(s.db, s.logger, "listener_checkpoints", "oldest_open_tx_time", "TIMESTAMP")
+}
+
+// SaveCheckpoint records the last item a command finished
+func (s *Service) SaveCheckpoint(ctx context.Context, checkpoint models.CommandCheckpoint) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertCommandCheckpoint,
+		checkpoint.Command, checkpoint.UserId, checkpoint.Asset, checkpoint.WalletId, checkpoint.Processed)
+	if err != nil {
+		return fmt.Errorf("unable to save %s checkpoint: %w", checkpoint.Command, err)
+	}
+	return nil
+}
+
+// GetCheckpoint returns a command's saved progress, or nil if it has none
+func (s *Service) GetCheckpoint(ctx context.Context, command string) (*models.CommandCheckpoint, error) {
+	var checkpoint models.CommandCheckpoint
+	err := s.db.QueryRowContext(ctx, queryGetCommandCheckpoint, command).Scan(
+		&checkpoint.Command, &checkpoint.UserId, &checkpoint.Asset, &checkpoint.WalletId,
+		&checkpoint.Processed, &checkpoint.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %s checkpoint: %w", command, err)
+	}
+	return &checkpoint, nil
+}
+
+// ClearCheckpoint removes a command's saved progress once a run completes
+func (s *Service) ClearCheckpoint(ctx context.Context, command string) error {
+	if _, err := s.db.ExecContext(ctx, queryDeleteCommandCheckpoint, command); err != nil {
+		return fmt.Errorf("unable to clear %s checkpoint: %w", command, err)
+	}
+	return nil
+}
+
+// SaveListenerCheckpoint records where the listener got to in a wallet's transactions
+func (s *Service) SaveListenerCheckpoint(ctx context.Context, checkpoint models.ListenerCheckpoint) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertListenerCheckpoint,
+		checkpoint.WalletId, checkpoint.PolledThrough.UTC(),
+		nullTime(checkpoint.LastSeenTxTime), checkpoint.LastSeenTxId, nullTime(checkpoint.OldestOpenTxTime))
+	if err != nil {
+		return fmt.Errorf("unable to save listener checkpoint for wallet %s: %w", checkpoint.WalletId, err)
+	}
+	return nil
+}
+
+// GetListenerCheckpoints returns each wallet's listener checkpoint, keyed by wallet Id
+func (s *Service) GetListenerCheckpoints(ctx context.Context) (map[string]models.ListenerCheckpoint, error) {
+	rows, err := s.db.QueryContext(ctx, queryListListenerCheckpoints)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list listener checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	checkpoints := make(map[string]models.ListenerCheckpoint)
+	for rows.Next() {
+		var checkpoint models.ListenerCheckpoint
+		var lastSeen, oldestOpen sql.NullTime
+		if err := rows.Scan(&checkpoint.WalletId, &checkpoint.PolledThrough, &lastSeen, &checkpoint.LastSeenTxId, &oldestOpen); err != nil {
+			return nil, fmt.Errorf("unable to scan listener checkpoint: %w", err)
+		}
+		checkpoint.LastSeenTxTime = lastSeen.Time
+		checkpoint.OldestOpenTxTime = oldestOpen.Time
+		checkpoints[checkpoint.WalletId] = checkpoint
+	}
+	return checkpoints, rows.Err()
+}
+
+// nullTime stores a zero time as NULL
+func nullTime(t time.Time) sql.NullTime {
+	if t.IsZero() {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestCheckpointSaveGetClear(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initCheckpointSchema(); err != nil {
+		t.Fatalf("Failed to create checkpoint schema: %v", err)
+	}
+
+	checkpoint, err := service.GetCheckpoint(ctx, "setup")
+	if err != nil {
+		t.Fatalf("GetCheckpoint failed: %v", err)
+	}
+	if checkpoint != nil {
+		t.Fatalf("Expected no checkpoint, got %+v", checkpoint)
+	}
+
+	for i, asset := range []string{"BTC-bitcoin", "ETH-ethereum"} {
+		err := service.SaveCheckpoint(ctx, models.CommandCheckpoint{
+			Command:   "setup",
+			UserId:    "user1",
+			Asset:     asset,
+			WalletId:  "wallet-" + asset,
+			Processed: i + 1,
+		})
+		if err != nil {
+			t.Fatalf("SaveCheckpoint failed: %v", err)
+		}
+	}
+
+	checkpoint, err = service.GetCheckpoint(ctx, "setup")
+	if err != nil {
+		t.Fatalf("GetCheckpoint failed: %v", err)
+	}
+	if checkpoint == nil {
+		t.Fatal("Expected a checkpoint")
+	}
+	if checkpoint.Asset != "ETH-ethereum" || checkpoint.WalletId != "wallet-ETH-ethereum" || checkpoint.Processed != 2 {
+		t.Errorf("Expected latest checkpoint to replace the first, got %+v", checkpoint)
+	}
+
+	if err := service.ClearCheckpoint(ctx, "setup"); err != nil {
+		t.Fatalf("ClearCheckpoint failed: %v", err)
+	}
+	checkpoint, err = service.GetCheckpoint(ctx, "setup")
+	if err != nil {
+		t.Fatalf("GetCheckpoint failed: %v", err)
+	}
+	if checkpoint != nil {
+		t.Errorf("Expected checkpoint to be cleared, got %+v", checkpoint)
+	}
+}
+
+func TestListenerCheckpoints(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initCheckpointSchema(); err != nil {
+		t.Fatalf("Failed to create checkpoint schema: %v", err)
+	}
+
+	first := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	second := first.Add(30 * time.Second)
+	for _, polledThrough := range []time.Time{first, second} {
+		err := service.SaveListenerCheckpoint(ctx, models.ListenerCheckpoint{
+			WalletId:         "wallet-1",
+			PolledThrough:    polledThrough,
+			LastSeenTxTime:   polledThrough.Add(-time.Minute),
+			LastSeenTxId:     "tx-" + polledThrough.Format("150405"),
+			OldestOpenTxTime: first.Add(-time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("SaveListenerCheckpoint failed: %v", err)
+		}
+	}
+	if err := service.SaveListenerCheckpoint(ctx, models.ListenerCheckpoint{WalletId: "wallet-2", PolledThrough: first}); err != nil {
+		t.Fatalf("SaveListenerCheckpoint failed: %v", err)
+	}
+
+	checkpoints, err := service.GetListenerCheckpoints(ctx)
+	if err != nil {
+		t.Fatalf("GetListenerCheckpoints failed: %v", err)
+	}
+	if len(checkpoints) != 2 {
+		t.Fatalf("Expected 2 checkpoints, got %v", checkpoints)
+	}
+
+	wallet1 := checkpoints["wallet-1"]
+	if !wallet1.PolledThrough.Equal(second) {
+		t.Errorf("Expected wallet-1 polled through %v, got %v", second, wallet1.PolledThrough)
+	}
+	if !wallet1.LastSeenTxTime.Equal(second.Add(-time.Minute)) || wallet1.LastSeenTxId != "tx-120030" {
+		t.Errorf("Expected wallet-1 cursor at the latest save, got %v %q", wallet1.LastSeenTxTime, wallet1.LastSeenTxId)
+	}
+	if !wallet1.OldestOpenTxTime.Equal(first.Add(-time.Hour)) {
+		t.Errorf("Expected wallet-1 oldest open at %v, got %v", first.Add(-time.Hour), wallet1.OldestOpenTxTime)
+	}
+
+	wallet2 := checkpoints["wallet-2"]
+	if !wallet2.PolledThrough.Equal(first) {
+		t.Errorf("Expected wallet-2 polled through %v, got %v", first, wallet2.PolledThrough)
+	}
+	if !wallet2.LastSeenTxTime.IsZero() || wallet2.LastSeenTxId != "" || !wallet2.OldestOpenTxTime.IsZero() {
+		t.Errorf("Expected wallet-2 to have no cursor, got %+v", wallet2)
+	}
+}
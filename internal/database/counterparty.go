@@ -0,0 +1,254 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// CounterpartyDepositParams describes an internal transfer into a monitored wallet that has no deposit address
+type CounterpartyDepositParams struct {
+	Asset        string
+	Amount       decimal.Decimal
+	ExternalTxId string
+	// MatchReference is Prime's match metadata reference id, checked against registered deposit references
+	MatchReference string
+	// CounterpartyIds are the sender identifiers from transfer_from, checked against counterparty mappings
+	CounterpartyIds []string
+	// Source is the sending side recorded on the ledger entry for source-of-funds reporting
+	Source models.DepositSource
+	// Availability is the funds availability policy resolved for the deposit (models.Availability*)
+	Availability string
+}
+
+func (s *Service) initCounterpartySchema() error {
+	schema := `
+	-- Senders of internal (book) transfers attributed to a user
+	CREATE TABLE IF NOT EXISTS counterparty_mappings (
+		counterparty_id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Expected deposits (e.g. invoices) matched by Prime's match reference id
+	CREATE TABLE IF NOT EXISTS deposit_references (
+		reference TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL DEFAULT '',
+		amount TEXT NOT NULL DEFAULT '0',
+		status TEXT NOT NULL DEFAULT 'open',
+		matched_transaction_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// SetCounterpartyMapping attributes future internal transfers from counterpartyId to userId
+func (s *Service) SetCounterpartyMapping(ctx context.Context, counterpartyId, userId, note string) error {
+	if _, err := s.db.ExecContext(ctx, queryUpsertCounterpartyMapping, counterpartyId, userId, note); err != nil {
+		return fmt.Errorf("unable to save counterparty mapping: %w", err)
+	}
+	return nil
+}
+
+// ListCounterpartyMappings returns all counterparty mappings
+func (s *Service) ListCounterpartyMappings(ctx context.Context) ([]models.CounterpartyMapping, error) {
+	rows, err := s.db.QueryContext(ctx, queryListCounterpartyMappings)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query counterparty mappings: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var mappings []models.CounterpartyMapping
+	for rows.Next() {
+		var mapping models.CounterpartyMapping
+		if err := rows.Scan(&mapping.CounterpartyId, &mapping.UserId, &mapping.Note, &mapping.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan counterparty mapping: %w", err)
+		}
+		mappings = append(mappings, mapping)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating counterparty mappings: %w", err)
+	}
+
+	return mappings, nil
+}
+
+// CreateDepositReference registers an expected deposit. Asset and amount are optional (empty/zero)
+// and only used to warn about mismatches when the deposit arrives.
+func (s *Service) CreateDepositReference(ctx context.Context, reference, userId, asset string, amount decimal.Decimal) error {
+	if _, err := s.db.ExecContext(ctx, queryInsertDepositReference, reference, userId, asset, amount.String()); err != nil {
+		return fmt.Errorf("unable to create deposit reference: %w", err)
+	}
+	return nil
+}
+
+// ListDepositReferences returns all registered deposit references
+func (s *Service) ListDepositReferences(ctx context.Context) ([]models.DepositReference, error) {
+	rows, err := s.db.QueryContext(ctx, queryListDepositReferences)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query deposit references: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var references []models.DepositReference
+	for rows.Next() {
+		reference, err := scanDepositReference(rows)
+		if err != nil {
+			return nil, err
+		}
+		references = append(references, *reference)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deposit references: %w", err)
+	}
+
+	return references, nil
+}
+
+// ProcessCounterpartyDeposit credits an internal transfer that arrived without a deposit address.
+// The user is found by match reference first, then by counterparty mapping; returns ErrUserNotFound otherwise.
+func (s *Service) ProcessCounterpartyDeposit(ctx context.Context, params CounterpartyDepositParams) (string, error) {
+	userId, reference, err := s.attributeCounterpartyDeposit(ctx, params)
+	if err != nil {
+		return "", err
+	}
+
+	holdKind, holdReason := s.depositHold(ctx, models.DepositScreening{
+		UserId:                userId,
+		Asset:                 params.Asset,
+		Amount:                params.Amount,
+		ExternalTransactionId: params.ExternalTxId,
+		Source:                params.Source,
+	}, params.Availability)
+
+	transaction, err := s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId:          userId,
+		Asset:           params.Asset,
+		TransactionType: "deposit",
+		Amount:          params.Amount,
+		ExternalTxId:    params.ExternalTxId,
+		Address:         "",
+		Reference:       reference,
+		SourceType:      params.Source.Type,
+		SourceAddress:   params.Source.Address,
+		HoldReason:      holdReason,
+		HoldKind:        holdKind,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error processing counterparty deposit: %w", err)
+	}
+
+	if params.MatchReference != "" {
+		if _, err := s.db.ExecContext(ctx, queryMatchDepositReference, transaction.Id, params.MatchReference); err != nil {
+			s.logger.Error("Deposit credited but reference could not be marked matched",
+				zap.String("reference", params.MatchReference),
+				zap.String("transaction_id", transaction.Id),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Counterparty deposit attributed",
+		zap.String("user_id", userId),
+		zap.String("asset", params.Asset),
+		zap.String("amount", params.Amount.String()),
+		zap.String("attribution", reference),
+		zap.String("external_tx_id", params.ExternalTxId))
+
+	return userId, nil
+}
+
+// attributeCounterpartyDeposit returns the user a counterparty deposit belongs to and how it was matched
+func (s *Service) attributeCounterpartyDeposit(ctx context.Context, params CounterpartyDepositParams) (string, string, error) {
+	if params.MatchReference != "" {
+		expected, err := scanDepositReference(s.db.QueryRowContext(ctx, queryFindOpenDepositReference, params.MatchReference))
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return "", "", fmt.Errorf("unable to look up deposit reference: %w", err)
+		}
+		if err == nil {
+			if expected.Asset != "" && expected.Asset != params.Asset {
+				s.logger.Warn("Deposit reference asset mismatch",
+					zap.String("reference", expected.Reference),
+					zap.String("expected_asset", expected.Asset),
+					zap.String("received_asset", params.Asset))
+			}
+			if expected.Amount.IsPositive() && !expected.Amount.Equal(params.Amount) {
+				s.logger.Warn("Deposit reference amount mismatch",
+					zap.String("reference", expected.Reference),
+					zap.String("expected_amount", expected.Amount.String()),
+					zap.String("received_amount", params.Amount.String()))
+			}
+			return expected.UserId, fmt.Sprintf("reference:%s", expected.Reference), nil
+		}
+	}
+
+	for _, counterpartyId := range params.CounterpartyIds {
+		if counterpartyId == "" {
+			continue
+		}
+		var userId string
+		err := s.db.QueryRowContext(ctx, queryFindCounterpartyMapping, counterpartyId).Scan(&userId)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("unable to look up counterparty mapping: %w", err)
+		}
+		return userId, fmt.Sprintf("counterparty:%s", counterpartyId), nil
+	}
+
+	return "", "", fmt.Errorf("%w: counterparty deposit %s could not be attributed", ErrUserNotFound, params.ExternalTxId)
+}
+
+func scanDepositReference(row rowScanner) (*models.DepositReference, error) {
+	var reference models.DepositReference
+	var amountStr string
+	if err := row.Scan(&reference.Reference, &reference.UserId, &reference.Asset, &amountStr,
+		&reference.Status, &reference.MatchedTransactionId, &reference.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	reference.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reference amount '%s': %w", amountStr, err)
+	}
+
+	return &reference, nil
+}
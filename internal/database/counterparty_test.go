@@ -0,0 +1,112 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestProcessCounterpartyDeposit(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initCounterpartySchema(); err != nil {
+		t.Fatalf("Failed to create counterparty schema: %v", err)
+	}
+
+	ctx := context.Background()
+
+	// Unknown senders are left unattributed
+	_, err := service.ProcessCounterpartyDeposit(ctx, CounterpartyDepositParams{
+		Asset:           "USDC",
+		Amount:          decimal.NewFromInt(10),
+		ExternalTxId:    "prime-tx-1",
+		CounterpartyIds: []string{"cp-unknown"},
+	})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Expected ErrUserNotFound, got %v", err)
+	}
+
+	if err := service.SetCounterpartyMapping(ctx, "cp-1", "user1", "desk"); err != nil {
+		t.Fatalf("SetCounterpartyMapping failed: %v", err)
+	}
+	if err := service.CreateDepositReference(ctx, "INV-42", "user2", "USDC", decimal.NewFromInt(5)); err != nil {
+		t.Fatalf("CreateDepositReference failed: %v", err)
+	}
+
+	// Counterparty mapping
+	userId, err := service.ProcessCounterpartyDeposit(ctx, CounterpartyDepositParams{
+		Asset:           "USDC",
+		Amount:          decimal.NewFromInt(10),
+		ExternalTxId:    "prime-tx-1",
+		CounterpartyIds: []string{"", "cp-1"},
+	})
+	if err != nil {
+		t.Fatalf("ProcessCounterpartyDeposit failed: %v", err)
+	}
+	if userId != "user1" {
+		t.Errorf("Expected user1, got %s", userId)
+	}
+
+	// Match reference takes precedence over the counterparty mapping
+	userId, err = service.ProcessCounterpartyDeposit(ctx, CounterpartyDepositParams{
+		Asset:           "USDC",
+		Amount:          decimal.NewFromInt(5),
+		ExternalTxId:    "prime-tx-2",
+		MatchReference:  "INV-42",
+		CounterpartyIds: []string{"cp-1"},
+	})
+	if err != nil {
+		t.Fatalf("ProcessCounterpartyDeposit failed: %v", err)
+	}
+	if userId != "user2" {
+		t.Errorf("Expected user2, got %s", userId)
+	}
+
+	references, err := service.ListDepositReferences(ctx)
+	if err != nil {
+		t.Fatalf("ListDepositReferences failed: %v", err)
+	}
+	if len(references) != 1 || references[0].Status != "matched" || references[0].MatchedTransactionId == "" {
+		t.Errorf("Expected reference to be matched, got %+v", references)
+	}
+
+	// A matched reference is not reused
+	userId, err = service.ProcessCounterpartyDeposit(ctx, CounterpartyDepositParams{
+		Asset:          "USDC",
+		Amount:         decimal.NewFromInt(5),
+		ExternalTxId:   "prime-tx-3",
+		MatchReference: "INV-42",
+	})
+	if !errors.Is(err, ErrUserNotFound) {
+		t.Fatalf("Expected ErrUserNotFound for reused reference, got %v (user %s)", err, userId)
+	}
+
+	for user, want := range map[string]int64{"user1": 10, "user2": 5} {
+		balance, err := service.GetUserBalance(ctx, user, "USDC")
+		if err != nil {
+			t.Fatalf("GetUserBalance failed: %v", err)
+		}
+		if !balance.Equal(decimal.NewFromInt(want)) {
+			t.Errorf("Expected %s balance %d, got %s", user, want, balance.String())
+		}
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package dbtest opens throwaway ledger databases for tests in other packages, e.g. the API and
+// listener. Each database has the full schema and is closed when the test ends; Open keeps it in memory
+// and OpenFile on disk, so a test can reopen it as a restarted process would.
+package dbtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+// Open returns a database.Service backed by a fresh in-memory SQLite database with the full schema
+func Open(t testing.TB) *database.Service {
+	t.Helper()
+	return OpenConfig(t, models.DatabaseConfig{})
+}
+
+// OpenConfig is Open with settings from cfg, e.g. BalancePolicies. The path and connection limits are
+// always those of an in-memory database.
+func OpenConfig(t testing.TB, cfg models.DatabaseConfig) *database.Service {
+	t.Helper()
+
+	// Every SQLite connection to :memory: opens its own empty database, so exactly one connection is
+	// opened and kept for the life of the test
+	cfg.Path = ":memory:"
+	cfg.MaxOpenConns = 1
+	cfg.MaxIdleConns = 1
+	cfg.ConnMaxLifetime = 0
+	cfg.ConnMaxIdleTime = 0
+	if cfg.PingTimeout == 0 {
+		cfg.PingTimeout = time.Second
+	}
+
+	service, err := database.NewService(context.Background(), cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(service.Close)
+	return service
+}
+
+// OpenFile opens the file database at path, creating it with the full schema on first use. Opening the
+// same path again, e.g. after closing the first Service, sees everything the earlier one committed.
+func OpenFile(t testing.TB, path string) *database.Service {
+	t.Helper()
+
+	cfg := models.DatabaseConfig{
+		Path:         path,
+		MaxOpenConns: 1,
+		MaxIdleConns: 1,
+		PingTimeout:  time.Second,
+	}
+	service, err := database.NewService(context.Background(), cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to open test database %s: %v", path, err)
+	}
+	t.Cleanup(service.Close)
+	return service
+}
+
+// CreateUser adds a user, failing the test if it cannot
+func CreateUser(t testing.TB, service *database.Service, userId, name, email string) *models.User {
+	t.Helper()
+	user, err := service.CreateUser(context.Background(), userId, name, email)
+	if err != nil {
+		t.Fatalf("Failed to create user %s: %v", userId, err)
+	}
+	return user
+}
+
+// StoreAddress records a user's deposit address, failing the test if it cannot
+func StoreAddress(t testing.TB, service *database.Service, params database.StoreAddressParams) *models.Address {
+	t.Helper()
+	address, err := service.StoreAddress(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Failed to store address %s: %v", params.Address, err)
+	}
+	return address
+}
+
+// Deposit credits amount to the owner of address as an immediately available deposit
+func Deposit(t testing.TB, service *database.Service, address, asset string, amount decimal.Decimal, transactionId string) {
+	t.Helper()
+	err := service.ProcessDeposit(context.Background(), address, asset, amount, transactionId, models.DepositSource{}, models.AvailabilityImmediate)
+	if err != nil {
+		t.Fatalf("Failed to deposit %s: %v", transactionId, err)
+	}
+}
@@ -0,0 +1,323 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Deposit hold statuses
+const (
+	DepositHoldStatusHeld     = "held"
+	DepositHoldStatusReleased = "released"
+)
+
+// Deposit hold kinds
+const (
+	// DepositHoldKindReview holds are released by an operator with cmd/deposit-holds
+	DepositHoldKindReview = "review"
+	// DepositHoldKindSettlement holds are released when the listener sees the deposit reach TRANSACTION_DONE
+	DepositHoldKindSettlement = "settlement"
+)
+
+// DepositScreener decides whether a deposit is credited as spendable or held for review.
+// An error holds the deposit so funds are never released because screening was unavailable.
+type DepositScreener func(ctx context.Context, deposit models.DepositScreening) (models.ScreeningDecision, error)
+
+// SetDepositScreener installs the inbound screening step run before deposits are credited
+func (s *Service) SetDepositScreener(screener DepositScreener) {
+	s.depositScreener = screener
+}
+
+// screenDeposit returns the hold reason for a deposit, or an empty string when it may be spent immediately
+func (s *Service) screenDeposit(ctx context.Context, deposit models.DepositScreening) string {
+	if s.depositScreener == nil {
+		return ""
+	}
+
+	decision, err := s.depositScreener(ctx, deposit)
+	if err != nil {
+		s.logger.Error("Deposit screening failed - holding deposit for review",
+			zap.String("external_tx_id", deposit.ExternalTransactionId),
+			zap.Error(err))
+		return fmt.Sprintf("screening failed: %v", err)
+	}
+	if !decision.Hold {
+		return ""
+	}
+	if decision.Reason == "" {
+		return "held by screening"
+	}
+	return decision.Reason
+}
+
+// depositHold decides whether a deposit is credited as available. Screening holds take precedence
+// over the funds availability policy; an empty kind means the deposit is available immediately.
+func (s *Service) depositHold(ctx context.Context, deposit models.DepositScreening, availability string) (kind, reason string) {
+	if reason := s.screenDeposit(ctx, deposit); reason != "" {
+		return DepositHoldKindReview, reason
+	}
+
+	switch availability {
+	case models.AvailabilityReview:
+		return DepositHoldKindReview, "funds availability policy requires review"
+	case models.AvailabilityDone:
+		return DepositHoldKindSettlement, "awaiting TRANSACTION_DONE"
+	}
+	return "", ""
+}
+
+// insertDepositHold records a hold against a deposit credited in the same database transaction
+func (s *SubledgerService) insertDepositHold(ctx context.Context, tx *sql.Tx, transaction *models.Transaction, kind, reason string) error {
+	if kind == "" {
+		kind = DepositHoldKindReview
+	}
+	_, err := tx.ExecContext(ctx, queryInsertDepositHold, uuid.New().String(), transaction.Id,
+		transaction.ExternalTransactionId, transaction.UserId, transaction.Asset, transaction.Amount.String(), kind, reason, transaction.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record deposit hold: %w", err)
+	}
+
+	if kind == DepositHoldKindSettlement {
+		s.logger.Info("Deposit credited as pending until TRANSACTION_DONE",
+			zap.String("transaction_id", transaction.Id),
+			zap.String("external_tx_id", transaction.ExternalTransactionId),
+			zap.String("user_id", transaction.UserId),
+			zap.String("asset", transaction.Asset),
+			zap.String("amount", transaction.Amount.String()))
+		return nil
+	}
+
+	s.logger.Warn("ALERT: deposit held pending review - release with cmd/deposit-holds",
+		zap.String("transaction_id", transaction.Id),
+		zap.String("external_tx_id", transaction.ExternalTransactionId),
+		zap.String("user_id", transaction.UserId),
+		zap.String("asset", transaction.Asset),
+		zap.String("amount", transaction.Amount.String()),
+		zap.String("reason", reason))
+	return nil
+}
+
+// heldAmount sums a user's unreleased deposit holds for an asset
+func (s *SubledgerService) heldAmount(ctx context.Context, tx *sql.Tx, userId, asset string) (decimal.Decimal, error) {
+	rows, err := tx.QueryContext(ctx, queryHeldAmounts, userId, asset)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to query held deposits: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	total := decimal.Zero
+	for rows.Next() {
+		var amountStr string
+		if err := rows.Scan(&amountStr); err != nil {
+			return decimal.Zero, fmt.Errorf("failed to scan held amount: %w", err)
+		}
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to parse held amount '%s': %w", amountStr, err)
+		}
+		total = total.Add(amount)
+	}
+	if err := rows.Err(); err != nil {
+		return decimal.Zero, fmt.Errorf("error iterating held deposits: %w", err)
+	}
+	return total, nil
+}
+
+// ListDepositHolds returns deposit holds, optionally filtered by status
+func (s *Service) ListDepositHolds(ctx context.Context, status string) ([]models.DepositHold, error) {
+	query := querySelectDepositHolds + " ORDER BY created_at DESC"
+	args := []interface{}{}
+	if status != "" {
+		query = querySelectDepositHolds + " WHERE status = ? ORDER BY created_at DESC"
+		args = append(args, status)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query deposit holds: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var holds []models.DepositHold
+	for rows.Next() {
+		hold, err := scanDepositHold(rows)
+		if err != nil {
+			return nil, err
+		}
+		holds = append(holds, *hold)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deposit holds: %w", err)
+	}
+
+	return holds, nil
+}
+
+// GetDepositHold returns a deposit hold by id
+func (s *Service) GetDepositHold(ctx context.Context, id string) (*models.DepositHold, error) {
+	hold, err := scanDepositHold(s.db.QueryRowContext(ctx, querySelectDepositHolds+" WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrDepositHoldNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// ReleaseDepositHold makes a held deposit available for withdrawal
+func (s *Service) ReleaseDepositHold(ctx context.Context, id, note string) error {
+	hold, err := s.GetDepositHold(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hold.Status != DepositHoldStatusHeld {
+		return fmt.Errorf("deposit hold %s is already %s", id, hold.Status)
+	}
+	return s.releaseHold(ctx, hold, note)
+}
+
+// ReleaseSettledDeposit releases the settlement hold of a deposit Prime reports as TRANSACTION_DONE.
+// Returns false when the deposit has no pending settlement hold.
+func (s *Service) ReleaseSettledDeposit(ctx context.Context, externalTxId string) (bool, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx, queryFindSettlementHold, externalTxId).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to find settlement hold: %w", err)
+	}
+
+	hold, err := s.GetDepositHold(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if err := s.releaseHold(ctx, hold, "TRANSACTION_DONE"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// releaseHold marks a hold released and adds its amount to the account's available balance
+func (s *Service) releaseHold(ctx context.Context, hold *models.DepositHold, note string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, queryReleaseDepositHold, note, hold.Id)
+	if err != nil {
+		return fmt.Errorf("unable to release deposit hold: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("deposit hold %s was released concurrently - %w", hold.Id, ErrConcurrentModification)
+	}
+	if _, err := transitionTransaction(ctx, tx, hold.TransactionId, TransactionStatusConfirmed, "deposit hold released: "+note); err != nil {
+		return err
+	}
+
+	var accountId, balanceStr string
+	var availableStr sql.NullString
+	var version int64
+	if err := tx.QueryRowContext(ctx, queryGetAccountBalance, hold.UserId, hold.Asset).Scan(&accountId, &balanceStr, &availableStr, &version); err != nil {
+		return fmt.Errorf("failed to get account balance: %w", err)
+	}
+	balance, err := decimal.NewFromString(balanceStr)
+	if err != nil {
+		return fmt.Errorf("failed to parse balance '%s': %w", balanceStr, err)
+	}
+	available, err := parseAvailable(availableStr, balance)
+	if err != nil {
+		return err
+	}
+
+	result, err = tx.ExecContext(ctx, queryAdjustAvailableBalance, available.Add(hold.Amount).String(), hold.UserId, hold.Asset, version)
+	if err != nil {
+		return fmt.Errorf("failed to update available balance: %w", err)
+	}
+	if rowsAffected, err = result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("available balance update failed - %w", ErrConcurrentModification)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit hold release: %w", err)
+	}
+	s.subledger.notifyBalanceChange(hold.UserId, hold.Asset)
+
+	s.logger.Info("Deposit hold released",
+		zap.String("hold_id", hold.Id),
+		zap.String("kind", hold.Kind),
+		zap.String("user_id", hold.UserId),
+		zap.String("asset", hold.Asset),
+		zap.String("amount", hold.Amount.String()),
+		zap.String("note", note))
+
+	return nil
+}
+
+func scanDepositHold(row rowScanner) (*models.DepositHold, error) {
+	var hold models.DepositHold
+	var amountStr string
+	var releasedAt sql.NullTime
+	if err := row.Scan(&hold.Id, &hold.TransactionId, &hold.ExternalTransactionId, &hold.UserId, &hold.Asset,
+		&amountStr, &hold.Kind, &hold.Reason, &hold.Status, &hold.ReleaseNote, &hold.CreatedAt, &releasedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	hold.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse hold amount '%s': %w", amountStr, err)
+	}
+	if releasedAt.Valid {
+		hold.ReleasedAt = &releasedAt.Time
+	}
+
+	return &hold, nil
+}
@@ -0,0 +1,156 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDepositHold_BlocksCustomerWithdrawalUntilReleased(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	service.SetDepositScreener(func(ctx context.Context, deposit models.DepositScreening) (models.ScreeningDecision, error) {
+		if deposit.Source.Address == "" {
+			return models.ScreeningDecision{}, errors.New("provider unavailable")
+		}
+		return models.ScreeningDecision{Hold: deposit.Amount.GreaterThan(decimal.NewFromInt(5)), Reason: "large deposit"}, nil
+	})
+
+	// Screening failures hold the deposit rather than releasing it
+	if reason := service.screenDeposit(ctx, models.DepositScreening{Amount: decimal.NewFromInt(1)}); !strings.HasPrefix(reason, "screening failed") {
+		t.Errorf("Expected screening failure to hold, got reason %q", reason)
+	}
+
+	deposits := []models.DepositScreening{
+		{UserId: "user1", Asset: "USDC", Amount: decimal.NewFromInt(3), ExternalTransactionId: "dep-1", Source: models.DepositSource{Address: "0xsmall"}},
+		{UserId: "user1", Asset: "USDC", Amount: decimal.NewFromInt(10), ExternalTransactionId: "dep-2", Source: models.DepositSource{Address: "0xlarge"}},
+	}
+	for _, deposit := range deposits {
+		_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+			UserId:          deposit.UserId,
+			Asset:           deposit.Asset,
+			TransactionType: "deposit",
+			Amount:          deposit.Amount,
+			ExternalTxId:    deposit.ExternalTransactionId,
+			SourceAddress:   deposit.Source.Address,
+			HoldReason:      service.screenDeposit(ctx, deposit),
+		})
+		if err != nil {
+			t.Fatalf("Failed to credit %s: %v", deposit.ExternalTransactionId, err)
+		}
+	}
+
+	holds, err := service.ListDepositHolds(ctx, DepositHoldStatusHeld)
+	if err != nil {
+		t.Fatalf("ListDepositHolds failed: %v", err)
+	}
+	if len(holds) != 1 || holds[0].ExternalTransactionId != "dep-2" || holds[0].Reason != "large deposit" {
+		t.Fatalf("Expected one hold for dep-2, got %+v", holds)
+	}
+
+	// Only the unheld 3 USDC is spendable
+	withdrawal := ProcessTransactionParams{UserId: "user1", Asset: "USDC", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-4), ExternalTxId: "wd-1"}
+	if _, err := service.subledger.ProcessTransactionWithPolicy(ctx, withdrawal, models.BalancePolicyCustomer); !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("Expected ErrInsufficientBalance while held, got %v", err)
+	}
+
+	if err := service.ReleaseDepositHold(ctx, holds[0].Id, "reviewed"); err != nil {
+		t.Fatalf("ReleaseDepositHold failed: %v", err)
+	}
+	if err := service.ReleaseDepositHold(ctx, holds[0].Id, ""); err == nil {
+		t.Error("Expected releasing a released hold to fail")
+	}
+
+	if _, err := service.subledger.ProcessTransactionWithPolicy(ctx, withdrawal, models.BalancePolicyCustomer); err != nil {
+		t.Fatalf("Withdrawal after release failed: %v", err)
+	}
+}
+
+func TestFundsAvailability_SettlementAndBackfill(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	kind, reason := service.depositHold(ctx, models.DepositScreening{}, models.AvailabilityDone)
+	if kind != DepositHoldKindSettlement {
+		t.Fatalf("Expected settlement hold under the done policy, got %q", kind)
+	}
+
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(2),
+		ExternalTxId: "prime-dep-1", HoldKind: kind, HoldReason: reason,
+	})
+	if err != nil {
+		t.Fatalf("Failed to credit deposit: %v", err)
+	}
+
+	available, err := service.GetAvailableBalance(ctx, "user1", "BTC")
+	if err != nil {
+		t.Fatalf("GetAvailableBalance failed: %v", err)
+	}
+	if !available.IsZero() {
+		t.Errorf("Expected nothing available before TRANSACTION_DONE, got %s", available)
+	}
+
+	released, err := service.ReleaseSettledDeposit(ctx, "prime-dep-1")
+	if err != nil || !released {
+		t.Fatalf("Expected settlement hold to be released, got %v, %v", released, err)
+	}
+	if released, _ := service.ReleaseSettledDeposit(ctx, "prime-dep-1"); released {
+		t.Error("Expected a second release to find nothing")
+	}
+
+	balances, err := service.GetAllUserBalances(ctx, "user1")
+	if err != nil {
+		t.Fatalf("GetAllUserBalances failed: %v", err)
+	}
+	if len(balances) != 1 || !balances[0].Available.Equal(decimal.NewFromInt(2)) || !balances[0].Balance.Equal(decimal.NewFromInt(2)) {
+		t.Fatalf("Expected 2 BTC total and available, got %+v", balances)
+	}
+
+	// Accounts from before the split are backfilled as their balance less held deposits
+	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1),
+		ExternalTxId: "prime-dep-2", HoldReason: "large deposit",
+	})
+	if err != nil {
+		t.Fatalf("Failed to credit held deposit: %v", err)
+	}
+	if _, err := service.db.Exec("UPDATE account_balances SET available_balance = NULL"); err != nil {
+		t.Fatalf("Failed to clear available balances: %v", err)
+	}
+	if err := service.subledger.InitSchema(); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	available, err = service.GetAvailableBalance(ctx, "user1", "BTC")
+	if err != nil {
+		t.Fatalf("GetAvailableBalance failed: %v", err)
+	}
+	if !available.Equal(decimal.NewFromInt(2)) {
+		t.Errorf("Expected backfilled available balance 2, got %s", available)
+	}
+}
@@ -0,0 +1,106 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ComputeDigest hashes every balance row, and optionally every transaction, in a fixed order with
+// amounts in canonical decimal form. Two ledgers holding the same data produce the same digest, so
+// digests taken before and after a migration, restore or replication can be compared. Timestamps and
+// row versions are left out because a copy need not preserve them.
+func (s *SubledgerService) ComputeDigest(ctx context.Context, tenantId string, includeTransactions bool) (*models.LedgerDigest, error) {
+	digest := &models.LedgerDigest{ComputedAt: time.Now().UTC()}
+
+	balances, err := s.ListAllAccountBalances(ctx, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	balanceHash := sha256.New()
+	for _, balance := range balances {
+		fmt.Fprintf(balanceHash, "%s|%s|%s|%s\n",
+			balance.UserId, balance.Asset, balance.Balance.String(), balance.Available.String())
+	}
+	digest.Balances = len(balances)
+	digest.BalanceDigest = hex.EncodeToString(balanceHash.Sum(nil))
+
+	combined := sha256.New()
+	fmt.Fprintf(combined, "balances|%s\n", digest.BalanceDigest)
+
+	if includeTransactions {
+		transactionHash := sha256.New()
+		digest.Transactions, err = s.hashTransactions(ctx, tenantId, transactionHash)
+		if err != nil {
+			return nil, err
+		}
+		digest.TransactionDigest = hex.EncodeToString(transactionHash.Sum(nil))
+		fmt.Fprintf(combined, "transactions|%s\n", digest.TransactionDigest)
+	}
+
+	digest.Digest = hex.EncodeToString(combined.Sum(nil))
+	return digest, nil
+}
+
+func (s *SubledgerService) hashTransactions(ctx context.Context, tenantId string, h hash.Hash) (int, error) {
+	rows, err := s.db.QueryContext(ctx, queryListTransactionsForDigest, tenantId, tenantId)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list transactions for digest: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	count := 0
+	for rows.Next() {
+		var id, userId, asset, transactionType, amountStr, balanceAfterStr string
+		var status sql.NullString
+		if err := rows.Scan(&id, &userId, &asset, &transactionType, &amountStr, &balanceAfterStr, &status); err != nil {
+			return 0, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse amount '%s' of %s: %w", amountStr, id, err)
+		}
+		balanceAfter, err := decimal.NewFromString(balanceAfterStr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse balance_after '%s' of %s: %w", balanceAfterStr, id, err)
+		}
+
+		fmt.Fprintf(h, "%s|%s|%s|%s|%s|%s|%s\n",
+			id, userId, asset, transactionType, amount.String(), balanceAfter.String(), status.String)
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating transactions: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,90 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestComputeDigest(t *testing.T) {
+	ctx := context.Background()
+
+	seed := func(t *testing.T, amounts ...string) *Service {
+		service, cleanup := setupBalanceTestDB(t)
+		t.Cleanup(cleanup)
+		for i, amount := range amounts {
+			_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+				UserId:          "user1",
+				Asset:           "ETH",
+				TransactionType: "deposit",
+				Amount:          decimal.RequireFromString(amount),
+				ExternalTxId:    fmt.Sprintf("tx%d", i),
+			})
+			if err != nil {
+				t.Fatalf("ProcessTransaction failed: %v", err)
+			}
+		}
+		return service
+	}
+
+	first := seed(t, "1.5", "0.25")
+	digest, err := first.ComputeDigest(ctx, false)
+	if err != nil {
+		t.Fatalf("ComputeDigest failed: %v", err)
+	}
+	if digest.Balances != 1 || digest.TransactionDigest != "" || digest.Digest == "" {
+		t.Fatalf("Unexpected digest: %+v", digest)
+	}
+
+	again, err := first.ComputeDigest(ctx, false)
+	if err != nil {
+		t.Fatalf("ComputeDigest failed: %v", err)
+	}
+	if again.Digest != digest.Digest {
+		t.Errorf("Expected a stable digest, got %s and %s", digest.Digest, again.Digest)
+	}
+
+	// Another ledger with the same balances matches, even though its row ids differ
+	copied, err := seed(t, "1.75").ComputeDigest(ctx, false)
+	if err != nil {
+		t.Fatalf("ComputeDigest failed: %v", err)
+	}
+	if copied.BalanceDigest != digest.BalanceDigest {
+		t.Errorf("Expected equal balance digests for equal balances")
+	}
+
+	different, err := seed(t, "1.7").ComputeDigest(ctx, false)
+	if err != nil {
+		t.Fatalf("ComputeDigest failed: %v", err)
+	}
+	if different.BalanceDigest == digest.BalanceDigest {
+		t.Errorf("Expected a different digest for a different balance")
+	}
+
+	withTransactions, err := first.ComputeDigest(ctx, true)
+	if err != nil {
+		t.Fatalf("ComputeDigest failed: %v", err)
+	}
+	if withTransactions.Transactions != 2 || withTransactions.TransactionDigest == "" ||
+		withTransactions.BalanceDigest != digest.BalanceDigest || withTransactions.Digest == digest.Digest {
+		t.Errorf("Unexpected digest with transactions: %+v", withTransactions)
+	}
+}
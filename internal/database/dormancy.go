@@ -0,0 +1,75 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ListInactiveAccounts returns accounts with no transactions since the cutoff, ordered by user and
+// asset. Zero balances are skipped unless includeZero is set.
+func (s *Service) ListInactiveAccounts(ctx context.Context, since time.Time, includeZero bool) ([]models.InactiveAccount, error) {
+	rows, err := s.db.QueryContext(ctx, queryListAccountActivity, s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account activity: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var accounts []models.InactiveAccount
+	for rows.Next() {
+		var account models.InactiveAccount
+		var balanceStr string
+		var lastActivity sql.NullString
+		if err := rows.Scan(&account.UserId, &account.Asset, &balanceStr, &account.UpdatedAt, &lastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan account activity: %w", err)
+		}
+
+		account.Balance, err = decimal.NewFromString(balanceStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse balance '%s': %w", balanceStr, err)
+		}
+		if account.Balance.IsZero() && !includeZero {
+			continue
+		}
+
+		if lastActivity.Valid && lastActivity.String != "" {
+			account.LastActivityAt, err = parseSQLiteTimestamp(lastActivity.String)
+			if err != nil {
+				return nil, err
+			}
+			if !account.LastActivityAt.Before(since) {
+				continue
+			}
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
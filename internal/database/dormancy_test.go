@@ -0,0 +1,77 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestListInactiveAccounts(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	deposits := []struct {
+		asset  string
+		amount float64
+		txId   string
+	}{
+		{"BTC", 1.0, "tx-btc"},
+		{"ETH", 2.0, "tx-eth"},
+		{"SOL", 3.0, "tx-sol"},
+	}
+	for _, d := range deposits {
+		_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user1", Asset: d.asset, TransactionType: "deposit", Amount: decimal.NewFromFloat(d.amount), ExternalTxId: d.txId})
+		if err != nil {
+			t.Fatalf("Failed to create %s deposit: %v", d.asset, err)
+		}
+	}
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user1", Asset: "SOL", TransactionType: "withdrawal", Amount: decimal.NewFromFloat(-3.0), ExternalTxId: "tx-sol-out"})
+	if err != nil {
+		t.Fatalf("Failed to create SOL withdrawal: %v", err)
+	}
+
+	// Age BTC and SOL activity past the cutoff; ETH stays recent
+	old := time.Now().Add(-100 * 24 * time.Hour)
+	if _, err := service.db.Exec("UPDATE transactions SET created_at = ? WHERE asset IN ('BTC', 'SOL')", old); err != nil {
+		t.Fatalf("Failed to age transactions: %v", err)
+	}
+
+	cutoff := time.Now().Add(-90 * 24 * time.Hour)
+	accounts, err := service.ListInactiveAccounts(ctx, cutoff, false)
+	if err != nil {
+		t.Fatalf("ListInactiveAccounts failed: %v", err)
+	}
+	if len(accounts) != 1 || accounts[0].Asset != "BTC" {
+		t.Fatalf("Expected only BTC to be inactive, got %+v", accounts)
+	}
+	if !accounts[0].LastActivityAt.Before(cutoff) {
+		t.Errorf("Expected last activity before cutoff, got %s", accounts[0].LastActivityAt)
+	}
+
+	accounts, err = service.ListInactiveAccounts(ctx, cutoff, true)
+	if err != nil {
+		t.Fatalf("ListInactiveAccounts failed: %v", err)
+	}
+	if len(accounts) != 2 || accounts[1].Asset != "SOL" || !accounts[1].Balance.IsZero() {
+		t.Fatalf("Expected BTC and zero-balance SOL, got %+v", accounts)
+	}
+}
@@ -0,0 +1,236 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// exactAmountColumns are the ledger columns that hold amounts. They were declared REAL before
+// amounts were stored as decimal strings, and are rebuilt as TEXT in databases from that time.
+var exactAmountColumns = []struct {
+	table   string
+	columns []string
+}{
+	{"account_balances", []string{"balance", "available_balance"}},
+	{"transactions", []string{"amount", "balance_before", "balance_after"}},
+	{"journal_entries", []string{"debit_amount", "credit_amount"}},
+}
+
+// migrateExactAmounts rebuilds ledger tables whose amount columns are still REAL. SQLite cannot
+// change a column's type, so each table is copied into a new one with TEXT columns, keeping rowids,
+// indexes and triggers. A stored float becomes the shortest decimal that reads back as the same
+// float, which is the value the service read from it before.
+func migrateExactAmounts(db *sql.DB, logger *zap.Logger) error {
+	for _, entry := range exactAmountColumns {
+		columns, err := tableColumnTypes(db, logger, entry.table)
+		if err != nil {
+			return err
+		}
+
+		var floating []string
+		for _, column := range entry.columns {
+			if strings.EqualFold(columns[column], "REAL") {
+				floating = append(floating, column)
+			}
+		}
+		if len(floating) == 0 {
+			continue
+		}
+
+		converted, err := rebuildWithExactAmounts(db, logger, entry.table, floating)
+		if err != nil {
+			return fmt.Errorf("unable to migrate %s amounts to exact decimals: %w", entry.table, err)
+		}
+		logger.Info("Migrated ledger amounts to exact decimals",
+			zap.String("table", entry.table),
+			zap.Strings("columns", floating),
+			zap.Int("values_converted", converted))
+	}
+	return nil
+}
+
+// tableColumnTypes returns the declared type of each of a table's columns
+func tableColumnTypes(db *sql.DB, logger *zap.Logger, table string) (map[string]string, error) {
+	rows, err := db.Query("SELECT name, type FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect table %s: %w", table, err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	types := make(map[string]string)
+	for rows.Next() {
+		var name, columnType string
+		if err := rows.Scan(&name, &columnType); err != nil {
+			return nil, fmt.Errorf("unable to scan table info for %s: %w", table, err)
+		}
+		types[name] = columnType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating table info for %s: %w", table, err)
+	}
+	return types, nil
+}
+
+// rebuildWithExactAmounts replaces table with a copy whose floating columns are TEXT and returns how
+// many float values were converted
+func rebuildWithExactAmounts(db *sql.DB, logger *zap.Logger, table string, floating []string) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var createSql string
+	if err := tx.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&createSql); err != nil {
+		return 0, fmt.Errorf("unable to read definition: %w", err)
+	}
+
+	// Indexes and triggers are dropped with the table and recreated on the copy
+	dependents, err := dependentObjects(tx, logger, table)
+	if err != nil {
+		return 0, err
+	}
+
+	newTable := table + "_exact"
+	definition := regexp.MustCompile(`(?i)^CREATE TABLE\s+("?)`+table+`("?)`).ReplaceAllString(createSql, "CREATE TABLE ${1}"+newTable+"${2}")
+	for _, column := range floating {
+		definition = regexp.MustCompile(`(?i)(\b`+column+`\s+)REAL\b`).ReplaceAllString(definition, "${1}TEXT")
+	}
+	if _, err := tx.Exec(definition); err != nil {
+		return 0, fmt.Errorf("unable to create %s: %w", newTable, err)
+	}
+
+	var columns []string
+	rows, err := tx.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return 0, fmt.Errorf("unable to list columns: %w", err)
+	}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		columns = append(columns, name)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	columnList := strings.Join(columns, ", ")
+	if _, err := tx.Exec(fmt.Sprintf("INSERT INTO %s (rowid, %s) SELECT rowid, %s FROM %s", newTable, columnList, columnList, table)); err != nil {
+		return 0, fmt.Errorf("unable to copy rows: %w", err)
+	}
+
+	// SQLite renders floats copied into TEXT with 15 significant digits; rewrite them from the floats
+	converted := 0
+	for _, column := range floating {
+		count, err := convertFloatColumn(tx, table, newTable, column)
+		if err != nil {
+			return 0, err
+		}
+		converted += count
+	}
+
+	if _, err := tx.Exec("DROP TABLE " + table); err != nil {
+		return 0, fmt.Errorf("unable to drop %s: %w", table, err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", newTable, table)); err != nil {
+		return 0, fmt.Errorf("unable to rename %s: %w", newTable, err)
+	}
+	for _, statement := range dependents {
+		if _, err := tx.Exec(statement); err != nil {
+			return 0, fmt.Errorf("unable to recreate %q: %w", statement, err)
+		}
+	}
+
+	return converted, tx.Commit()
+}
+
+// dependentObjects returns the statements that created a table's explicit indexes and triggers
+func dependentObjects(tx *sql.Tx, logger *zap.Logger, table string) ([]string, error) {
+	rows, err := tx.Query("SELECT sql FROM sqlite_master WHERE type IN ('index', 'trigger') AND tbl_name = ? AND sql IS NOT NULL", table)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read indexes and triggers: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var statements []string
+	for rows.Next() {
+		var statement string
+		if err := rows.Scan(&statement); err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+	return statements, rows.Err()
+}
+
+// convertFloatColumn writes each float in a column of the old table to the copy as a decimal string
+func convertFloatColumn(tx *sql.Tx, table, newTable, column string) (int, error) {
+	type value struct {
+		rowid  int64
+		amount float64
+	}
+
+	rows, err := tx.Query(fmt.Sprintf("SELECT rowid, %s FROM %s WHERE typeof(%s) = 'real'", column, table, column))
+	if err != nil {
+		return 0, fmt.Errorf("unable to read %s: %w", column, err)
+	}
+	var values []value
+	for rows.Next() {
+		var v value
+		if err := rows.Scan(&v.rowid, &v.amount); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("unable to scan %s: %w", column, err)
+		}
+		values = append(values, v)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+
+	update, err := tx.Prepare(fmt.Sprintf("UPDATE %s SET %s = ? WHERE rowid = ?", newTable, column))
+	if err != nil {
+		return 0, err
+	}
+	defer update.Close()
+
+	for _, v := range values {
+		if _, err := update.Exec(decimal.NewFromFloat(v.amount).String(), v.rowid); err != nil {
+			return 0, fmt.Errorf("unable to convert %s: %w", column, err)
+		}
+	}
+	return len(values), nil
+}
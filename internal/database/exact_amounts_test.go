@@ -0,0 +1,157 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestMigrateExactAmounts(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// Ledger tables as they were created when amounts were REAL
+	legacy := `
+	CREATE TABLE IF NOT EXISTS account_balances (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		balance REAL NOT NULL DEFAULT 0,
+		available_balance REAL,
+		last_transaction_id TEXT,
+		version INTEGER NOT NULL DEFAULT 1,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		tenant_id TEXT NOT NULL DEFAULT 'default',
+		UNIQUE(user_id, asset)
+	);
+	CREATE INDEX idx_account_balances_asset ON account_balances(asset);
+	CREATE TRIGGER trg_account_balances_tenant AFTER INSERT ON account_balances BEGIN
+		UPDATE account_balances SET tenant_id = 'triggered' WHERE id = NEW.id;
+	END;
+	CREATE TABLE IF NOT EXISTS transactions (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		transaction_type TEXT NOT NULL,
+		amount REAL NOT NULL,
+		balance_before REAL NOT NULL,
+		balance_after REAL NOT NULL
+	);
+	CREATE TABLE journal_entries (
+		id TEXT PRIMARY KEY,
+		transaction_id TEXT NOT NULL,
+		account_type TEXT NOT NULL,
+		account_id TEXT NOT NULL,
+		debit_amount REAL DEFAULT 0,
+		credit_amount REAL DEFAULT 0
+	);
+	INSERT INTO account_balances (id, user_id, asset, balance, available_balance) VALUES ('b1', 'user1', 'ETH', '0.1', NULL);
+	INSERT INTO transactions VALUES ('t2', 'user1', 'ETH', 'deposit', 0.3, 0, 0.3);
+	INSERT INTO transactions VALUES ('t1', 'user1', 'ETH', 'withdrawal', -0.2, 0.3, 0.1);
+	INSERT INTO journal_entries VALUES ('j1', 't2', 'asset', 'ETH', 0.3, 0);
+	`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	logger := zaptest.NewLogger(t)
+	if err := migrateExactAmounts(db, logger); err != nil {
+		t.Fatalf("migrateExactAmounts failed: %v", err)
+	}
+
+	for _, entry := range exactAmountColumns {
+		types, err := tableColumnTypes(db, logger, entry.table)
+		if err != nil {
+			t.Fatalf("tableColumnTypes failed: %v", err)
+		}
+		for _, column := range entry.columns {
+			if types[column] != "TEXT" {
+				t.Errorf("Expected %s.%s to be TEXT, got %q", entry.table, column, types[column])
+			}
+		}
+	}
+
+	// Floats are rewritten as the decimals they stood for, in the original row order
+	rows, err := db.Query("SELECT id, amount, balance_after FROM transactions ORDER BY rowid")
+	if err != nil {
+		t.Fatalf("Failed to read transactions: %v", err)
+	}
+	var got []string
+	for rows.Next() {
+		var id, amount, balanceAfter string
+		if err := rows.Scan(&id, &amount, &balanceAfter); err != nil {
+			t.Fatalf("Failed to scan transaction: %v", err)
+		}
+		got = append(got, id+" "+amount+" "+balanceAfter)
+	}
+	rows.Close()
+	if len(got) != 2 || got[0] != "t2 0.3 0.3" || got[1] != "t1 -0.2 0.1" {
+		t.Errorf("Unexpected transactions after migration: %v", got)
+	}
+
+	var balance string
+	var available sql.NullString
+	if err := db.QueryRow("SELECT balance, available_balance FROM account_balances WHERE id = 'b1'").Scan(&balance, &available); err != nil {
+		t.Fatalf("Failed to read balance: %v", err)
+	}
+	if balance != "0.1" || available.Valid {
+		t.Errorf("Expected balance 0.1 with no available balance, got %s %v", balance, available)
+	}
+
+	// Indexes and triggers survive the rebuild
+	var objects int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name IN ('idx_account_balances_asset', 'trg_account_balances_tenant')").Scan(&objects); err != nil || objects != 2 {
+		t.Errorf("Expected the index and trigger to be recreated, found %d (%v)", objects, err)
+	}
+
+	// A second run finds nothing to migrate
+	if err := migrateExactAmounts(db, logger); err != nil {
+		t.Fatalf("Second migrateExactAmounts failed: %v", err)
+	}
+}
+
+func TestExactAmountsArePreserved(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// More significant digits than a float holds
+	amount := decimal.RequireFromString("1.123456789012345678")
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: amount, ExternalTxId: "tx1"}); err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+
+	balance, err := service.subledger.GetBalance(ctx, "user1", "ETH")
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if !balance.Equal(amount) {
+		t.Errorf("Expected balance %s, got %s", amount, balance)
+	}
+	if err := service.subledger.ReconcileBalance(ctx, "user1", "ETH"); err != nil {
+		t.Errorf("ReconcileBalance failed: %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// idempotencyPendingTimeout is how long a key may stay pending before a retry takes it over. A request
+// outlives it only if its process died mid-request, since no API request runs for minutes.
+const idempotencyPendingTimeout = 5 * time.Minute
+
+func (s *Service) initIdempotencySchema() error {
+	schema := `
+	-- Responses of API requests sent with an Idempotency-Key, replayed on retry
+	CREATE TABLE IF NOT EXISTS api_idempotency_keys (
+		scope TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status TEXT NOT NULL,
+		status_code INTEGER NOT NULL DEFAULT 0,
+		content_type TEXT NOT NULL DEFAULT '',
+		response_body BLOB,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		completed_at TIMESTAMP,
+		PRIMARY KEY (scope, idempotency_key)
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// BeginIdempotentRequest reserves an idempotency key within a scope (typically the caller's user).
+// It returns nil when the caller should execute the request, or the stored response to replay.
+// A key reused with a different request fails with ErrIdempotencyKeyReused, and one whose first
+// request is still running with ErrIdempotencyKeyInFlight. A key left pending for longer than
+// idempotencyPendingTimeout, e.g. by a crash, is taken over by a retry of the same request.
+func (s *Service) BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*models.IdempotentResponse, error) {
+	result, err := s.db.ExecContext(ctx, queryReserveIdempotencyKey, scope, key, requestHash)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reserve idempotency key: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 1 {
+		return nil, nil
+	}
+
+	var storedHash, status string
+	var response models.IdempotentResponse
+	err = s.db.QueryRowContext(ctx, queryGetIdempotencyKey, scope, key).Scan(
+		&storedHash, &status, &response.StatusCode, &response.ContentType, &response.Body)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The first request failed and released the key in between; let the caller try again
+		return nil, ErrIdempotencyKeyInFlight
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read idempotency key: %w", err)
+	}
+
+	if storedHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if status == "complete" {
+		return &response, nil
+	}
+
+	stale := fmt.Sprintf("-%d seconds", int(idempotencyPendingTimeout.Seconds()))
+	result, err = s.db.ExecContext(ctx, queryTakeOverIdempotencyKey, scope, key, requestHash, stale)
+	if err != nil {
+		return nil, fmt.Errorf("unable to take over idempotency key: %w", err)
+	}
+	rowsAffected, err = result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 1 {
+		s.logger.Warn("Taking over idempotency key left pending", zap.String("scope", scope), zap.String("key", key))
+		return nil, nil
+	}
+	return nil, ErrIdempotencyKeyInFlight
+}
+
+// CompleteIdempotentRequest stores the response to replay for a reserved key
+func (s *Service) CompleteIdempotentRequest(ctx context.Context, scope, key string, response models.IdempotentResponse) error {
+	if _, err := s.db.ExecContext(ctx, queryCompleteIdempotencyKey,
+		response.StatusCode, response.ContentType, response.Body, scope, key); err != nil {
+		return fmt.Errorf("unable to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotentRequest frees a reserved key without storing a response, so a retry runs the request again
+func (s *Service) ReleaseIdempotentRequest(ctx context.Context, scope, key string) error {
+	if _, err := s.db.ExecContext(ctx, queryReleaseIdempotencyKey, scope, key); err != nil {
+		return fmt.Errorf("unable to release idempotency key: %w", err)
+	}
+	return nil
+}
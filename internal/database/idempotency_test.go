@@ -0,0 +1,61 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestBeginIdempotentRequest_TakesOverStalePendingKey(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if stored, err := service.BeginIdempotentRequest(ctx, "user1", "key-1", "hash-1"); err != nil || stored != nil {
+		t.Fatalf("Expected to reserve a new key, got %+v (%v)", stored, err)
+	}
+	if _, err := service.BeginIdempotentRequest(ctx, "user1", "key-1", "hash-1"); !errors.Is(err, ErrIdempotencyKeyInFlight) {
+		t.Fatalf("Expected a fresh pending key to be in flight, got %v", err)
+	}
+
+	// The process handling the first request died and left the key pending
+	if _, err := service.db.Exec(`UPDATE api_idempotency_keys SET created_at = datetime('now', '-10 minutes')
+		WHERE scope = 'user1' AND idempotency_key = 'key-1'`); err != nil {
+		t.Fatalf("Failed to age key: %v", err)
+	}
+	if _, err := service.BeginIdempotentRequest(ctx, "user1", "key-1", "hash-2"); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Expected a different request to still be rejected, got %v", err)
+	}
+	if stored, err := service.BeginIdempotentRequest(ctx, "user1", "key-1", "hash-1"); err != nil || stored != nil {
+		t.Fatalf("Expected a retry to take over the stale key, got %+v (%v)", stored, err)
+	}
+	if _, err := service.BeginIdempotentRequest(ctx, "user1", "key-1", "hash-1"); !errors.Is(err, ErrIdempotencyKeyInFlight) {
+		t.Errorf("Expected the taken over key to be in flight again, got %v", err)
+	}
+
+	response := models.IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}
+	if err := service.CompleteIdempotentRequest(ctx, "user1", "key-1", response); err != nil {
+		t.Fatalf("CompleteIdempotentRequest failed: %v", err)
+	}
+	if stored, err := service.BeginIdempotentRequest(ctx, "user1", "key-1", "hash-1"); err != nil || stored == nil || stored.StatusCode != 201 {
+		t.Errorf("Expected the stored response to be replayed, got %+v (%v)", stored, err)
+	}
+}
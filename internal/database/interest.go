@@ -0,0 +1,202 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// TransactionTypeInterest is the ledger transaction type for accrued interest
+const TransactionTypeInterest = "interest"
+
+const (
+	// InterestDateLayout is the format of accrual dates
+	InterestDateLayout = "2006-01-02"
+
+	// interestDaysPerYear converts annual rates to daily accruals (actual/365)
+	interestDaysPerYear = 365
+
+	// interestPrecision is the number of decimal places interest amounts are rounded down to
+	interestPrecision = 8
+)
+
+// AccrueInterestParams contains the parameters for a daily interest accrual run
+type AccrueInterestParams struct {
+	Date       time.Time
+	Rates      map[string]decimal.Decimal
+	MinBalance decimal.Decimal
+}
+
+func (s *Service) initInterestSchema() error {
+	schema := `
+	-- One interest accrual per user/asset/day
+	CREATE TABLE IF NOT EXISTS interest_accruals (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		accrual_date TEXT NOT NULL,
+		balance TEXT NOT NULL,
+		annual_rate TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		transaction_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user_id, asset, accrual_date)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_interest_accruals_date ON interest_accruals(accrual_date);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// DailyInterest returns the interest earned on balance for one day at annualRate, rounded down
+func DailyInterest(balance, annualRate decimal.Decimal) decimal.Decimal {
+	return balance.Mul(annualRate).Div(decimal.NewFromInt(interestDaysPerYear)).RoundDown(interestPrecision)
+}
+
+// AccrueInterest credits one day of interest to every eligible balance.
+// Balances are eligible when their asset has a configured rate and they are at least MinBalance.
+// Runs are idempotent per user/asset/day, so re-running a date only fills in missing accruals.
+func (s *Service) AccrueInterest(ctx context.Context, params AccrueInterestParams) ([]models.InterestAccrual, error) {
+	accrualDate := params.Date.UTC().Format(InterestDateLayout)
+
+	s.logger.Info("Starting interest accrual",
+		zap.String("accrual_date", accrualDate),
+		zap.Int("rated_assets", len(params.Rates)))
+
+	balances, err := s.ListAccountBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var accruals []models.InterestAccrual
+	for _, balance := range balances {
+		if balance.UserId == SuspenseUserId {
+			continue
+		}
+		rate, ok := params.Rates[balance.Asset]
+		if !ok || !rate.IsPositive() {
+			continue
+		}
+		if !balance.Balance.IsPositive() || balance.Balance.LessThan(params.MinBalance) {
+			continue
+		}
+
+		amount := DailyInterest(balance.Balance, rate)
+		if amount.IsZero() {
+			continue
+		}
+
+		transaction, err := s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+			UserId:          balance.UserId,
+			Asset:           balance.Asset,
+			TransactionType: TransactionTypeInterest,
+			Amount:          amount,
+			ExternalTxId:    fmt.Sprintf("interest:%s:%s:%s", balance.UserId, balance.Asset, accrualDate),
+			Address:         "",
+			Reference:       fmt.Sprintf("Interest accrual %s", accrualDate),
+		})
+		if errors.Is(err, ErrDuplicateTransaction) {
+			s.logger.Debug("Interest already accrued",
+				zap.String("user_id", balance.UserId),
+				zap.String("asset", balance.Asset),
+				zap.String("accrual_date", accrualDate))
+			continue
+		}
+		if err != nil {
+			return accruals, fmt.Errorf("error accruing interest for user %s asset %s: %w", balance.UserId, balance.Asset, err)
+		}
+
+		accrual := models.InterestAccrual{
+			Id:            uuid.New().String(),
+			UserId:        balance.UserId,
+			Asset:         balance.Asset,
+			AccrualDate:   accrualDate,
+			Balance:       balance.Balance,
+			AnnualRate:    rate,
+			Amount:        amount,
+			TransactionId: transaction.Id,
+			CreatedAt:     transaction.CreatedAt,
+		}
+
+		_, err = s.db.ExecContext(ctx, queryInsertInterestAccrual, accrual.Id, accrual.UserId, accrual.Asset,
+			accrual.AccrualDate, accrual.Balance.String(), accrual.AnnualRate.String(), accrual.Amount.String(), accrual.TransactionId)
+		if err != nil {
+			return accruals, fmt.Errorf("unable to record interest accrual: %w", err)
+		}
+
+		accruals = append(accruals, accrual)
+	}
+
+	s.logger.Info("Interest accrual completed",
+		zap.String("accrual_date", accrualDate),
+		zap.Int("accruals", len(accruals)))
+
+	return accruals, nil
+}
+
+// ListInterestAccruals returns accruals between two dates (inclusive, YYYY-MM-DD)
+func (s *Service) ListInterestAccruals(ctx context.Context, fromDate, toDate string) ([]models.InterestAccrual, error) {
+	rows, err := s.db.QueryContext(ctx, queryListInterestAccruals, fromDate, toDate)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query interest accruals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var accruals []models.InterestAccrual
+	for rows.Next() {
+		var accrual models.InterestAccrual
+		var balanceStr, rateStr, amountStr string
+		if err := rows.Scan(&accrual.Id, &accrual.UserId, &accrual.Asset, &accrual.AccrualDate,
+			&balanceStr, &rateStr, &amountStr, &accrual.TransactionId, &accrual.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan interest accrual: %w", err)
+		}
+
+		if accrual.Balance, err = decimal.NewFromString(balanceStr); err != nil {
+			return nil, fmt.Errorf("failed to parse accrual balance '%s': %w", balanceStr, err)
+		}
+		if accrual.AnnualRate, err = decimal.NewFromString(rateStr); err != nil {
+			return nil, fmt.Errorf("failed to parse accrual rate '%s': %w", rateStr, err)
+		}
+		if accrual.Amount, err = decimal.NewFromString(amountStr); err != nil {
+			return nil, fmt.Errorf("failed to parse accrual amount '%s': %w", amountStr, err)
+		}
+
+		accruals = append(accruals, accrual)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating interest accruals: %w", err)
+	}
+
+	return accruals, nil
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAccrueInterest_IdempotentPerDay(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initInterestSchema(); err != nil {
+		t.Fatalf("Failed to create interest schema: %v", err)
+	}
+
+	ctx := context.Background()
+	deposits := []ProcessTransactionParams{
+		{UserId: "user1", Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(36500), ExternalTxId: "dep-1"},
+		{UserId: "user2", Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(5), ExternalTxId: "dep-2"},
+		{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: "dep-3"},
+	}
+	for _, deposit := range deposits {
+		if _, err := service.subledger.ProcessTransaction(ctx, deposit); err != nil {
+			t.Fatalf("Failed to seed deposit: %v", err)
+		}
+	}
+
+	params := AccrueInterestParams{
+		Date:       time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		Rates:      map[string]decimal.Decimal{"USDC": decimal.RequireFromString("0.05")},
+		MinBalance: decimal.NewFromInt(10),
+	}
+
+	accruals, err := service.AccrueInterest(ctx, params)
+	if err != nil {
+		t.Fatalf("AccrueInterest failed: %v", err)
+	}
+
+	// Only user1's USDC balance has a rate and meets the minimum balance
+	if len(accruals) != 1 {
+		t.Fatalf("Expected 1 accrual, got %d", len(accruals))
+	}
+	if !accruals[0].Amount.Equal(decimal.NewFromInt(5)) {
+		t.Errorf("Expected daily interest 5, got %s", accruals[0].Amount.String())
+	}
+
+	again, err := service.AccrueInterest(ctx, params)
+	if err != nil {
+		t.Fatalf("Second AccrueInterest failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Errorf("Expected re-run to post no accruals, got %d", len(again))
+	}
+
+	balance, err := service.GetUserBalance(ctx, "user1", "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance failed: %v", err)
+	}
+	if !balance.Equal(decimal.NewFromInt(36505)) {
+		t.Errorf("Expected balance 36505, got %s", balance.String())
+	}
+
+	listed, err := service.ListInterestAccruals(ctx, "2025-01-01", "2025-01-31")
+	if err != nil {
+		t.Fatalf("ListInterestAccruals failed: %v", err)
+	}
+	if len(listed) != 1 || listed[0].AccrualDate != "2025-01-15" {
+		t.Errorf("Expected one accrual on 2025-01-15, got %+v", listed)
+	}
+}
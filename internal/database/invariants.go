@@ -0,0 +1,184 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ListLedgerEntriesAfter returns up to limit ledger transactions written after sequence, oldest first,
+// each with its journal postings
+func (s *Service) ListLedgerEntriesAfter(ctx context.Context, sequence int64, limit int) ([]models.LedgerEntry, error) {
+	rows, err := s.db.QueryContext(ctx, queryListLedgerEntriesAfter, sequence, s.tenantId, s.tenantId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var entries []models.LedgerEntry
+	index := make(map[string]int)
+	for rows.Next() {
+		var entry models.LedgerEntry
+		var amountStr, beforeStr, afterStr string
+		if err := rows.Scan(&entry.Sequence, &entry.TransactionId, &entry.Asset, &entry.Type, &amountStr, &beforeStr, &afterStr); err != nil {
+			return nil, fmt.Errorf("failed to scan ledger entry: %w", err)
+		}
+		if entry.Amount, err = decimal.NewFromString(amountStr); err != nil {
+			return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+		}
+		if entry.BalanceBefore, err = decimal.NewFromString(beforeStr); err != nil {
+			return nil, fmt.Errorf("failed to parse balance_before '%s': %w", beforeStr, err)
+		}
+		if entry.BalanceAfter, err = decimal.NewFromString(afterStr); err != nil {
+			return nil, fmt.Errorf("failed to parse balance_after '%s': %w", afterStr, err)
+		}
+		index[entry.TransactionId] = len(entries)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate ledger entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return entries, nil
+	}
+
+	if err := s.attachJournalPostings(ctx, entries, index); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// attachJournalPostings loads the journal postings of entries, indexed by transaction id
+func (s *Service) attachJournalPostings(ctx context.Context, entries []models.LedgerEntry, index map[string]int) error {
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.TransactionId
+	}
+	idsJson, err := json.Marshal(ids)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction ids: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, queryListJournalPostings, string(idsJson))
+	if err != nil {
+		return fmt.Errorf("failed to list journal postings: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	for rows.Next() {
+		var transactionId, accountType, debitStr, creditStr string
+		if err := rows.Scan(&transactionId, &accountType, &debitStr, &creditStr); err != nil {
+			return fmt.Errorf("failed to scan journal posting: %w", err)
+		}
+		debit, err := decimal.NewFromString(debitStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse debit '%s': %w", debitStr, err)
+		}
+		credit, err := decimal.NewFromString(creditStr)
+		if err != nil {
+			return fmt.Errorf("failed to parse credit '%s': %w", creditStr, err)
+		}
+		i := index[transactionId]
+		entries[i].Postings = append(entries[i].Postings, models.JournalPosting{AccountType: accountType, Debit: debit, Credit: credit})
+	}
+	return rows.Err()
+}
+
+// LedgerInvariants checks ledger entries incrementally: each entry is checked when it is added and the
+// per-asset journal totals carry over between calls. Entries must be added in sequence order, once.
+type LedgerInvariants struct {
+	userAssetType string
+	liabilityType string
+	// user is the user_asset debit balance per asset
+	user map[string]decimal.Decimal
+	// funding is the credit balance of every other account per asset: the customer liability plus the
+	// operator-funded accounts (rewards, interest) and suspense, which user credits can be posted against
+	funding map[string]decimal.Decimal
+}
+
+// NewLedgerInvariants creates a checker for journals posted with the given chart of accounts
+func NewLedgerInvariants(chart models.ChartOfAccounts) *LedgerInvariants {
+	return &LedgerInvariants{
+		userAssetType: chart.UserAsset.Type,
+		liabilityType: chart.CustomerLiability.Type,
+		user:          make(map[string]decimal.Decimal),
+		funding:       make(map[string]decimal.Decimal),
+	}
+}
+
+// Add checks entries and folds them into the running totals. It returns every violation found: each
+// transaction whose balances do not add up, and each asset whose totals disagree after the entries.
+func (l *LedgerInvariants) Add(entries []models.LedgerEntry) []models.InvariantViolation {
+	var violations []models.InvariantViolation
+	lastTransaction := make(map[string]string)
+
+	for _, entry := range entries {
+		if expected := entry.BalanceBefore.Add(entry.Amount); !entry.BalanceAfter.Equal(expected) {
+			violations = append(violations, models.InvariantViolation{
+				Invariant:     models.InvariantBalanceArithmetic,
+				Asset:         entry.Asset,
+				TransactionId: entry.TransactionId,
+				Detail: fmt.Sprintf("balance_after %s != balance_before %s + amount %s",
+					entry.BalanceAfter.String(), entry.BalanceBefore.String(), entry.Amount.String()),
+			})
+		}
+
+		for _, posting := range entry.Postings {
+			if posting.AccountType == l.userAssetType {
+				l.user[entry.Asset] = l.user[entry.Asset].Add(posting.Debit).Sub(posting.Credit)
+			} else {
+				l.funding[entry.Asset] = l.funding[entry.Asset].Add(posting.Credit).Sub(posting.Debit)
+			}
+		}
+		lastTransaction[entry.Asset] = entry.TransactionId
+	}
+
+	assets := make([]string, 0, len(lastTransaction))
+	for asset := range lastTransaction {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	for _, asset := range assets {
+		if !l.user[asset].Equal(l.funding[asset]) {
+			violations = append(violations, models.InvariantViolation{
+				Invariant:     models.InvariantCustomerLiability,
+				Asset:         asset,
+				TransactionId: lastTransaction[asset],
+				Detail: fmt.Sprintf("%s total %s != %s and funding accounts total %s",
+					l.userAssetType, l.user[asset].String(), l.liabilityType, l.funding[asset].String()),
+			})
+		}
+	}
+	return violations
+}
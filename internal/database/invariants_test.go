@@ -0,0 +1,89 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLedgerInvariants(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	process := func(params ProcessTransactionParams) {
+		if _, err := service.subledger.ProcessTransaction(ctx, params); err != nil {
+			t.Fatalf("ProcessTransaction failed: %v", err)
+		}
+	}
+	process(ProcessTransactionParams{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("5"), ExternalTxId: "dep1"})
+	process(ProcessTransactionParams{UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.RequireFromString("-1.5"), ExternalTxId: "wd1"})
+	process(ProcessTransactionParams{UserId: "user2", Asset: "USDC", TransactionType: TransactionTypeReward, Amount: decimal.RequireFromString("10"), ExternalTxId: "reward1"})
+
+	invariants := NewLedgerInvariants(service.ChartOfAccounts())
+	entries, err := service.ListLedgerEntriesAfter(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("ListLedgerEntriesAfter failed: %v", err)
+	}
+	if len(entries) != 2 || len(entries[0].Postings) != 2 {
+		t.Fatalf("Expected 2 entries with postings, got %+v", entries)
+	}
+	if violations := invariants.Add(entries); len(violations) != 0 {
+		t.Fatalf("Expected no violations, got %+v", violations)
+	}
+
+	rest, err := service.ListLedgerEntriesAfter(ctx, entries[1].Sequence, 10)
+	if err != nil {
+		t.Fatalf("ListLedgerEntriesAfter failed: %v", err)
+	}
+	if len(rest) != 1 || rest[0].Asset != "USDC" {
+		t.Fatalf("Expected the reward entry only, got %+v", rest)
+	}
+	if violations := invariants.Add(rest); len(violations) != 0 {
+		t.Fatalf("Expected rewards to balance against the funding account, got %+v", violations)
+	}
+
+	// A transaction whose balances do not add up, and a journal posting without its other side
+	process(ProcessTransactionParams{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.RequireFromString("2"), ExternalTxId: "dep2"})
+	if _, err := service.db.Exec(`UPDATE transactions SET balance_after = 9 WHERE external_transaction_id = 'dep2'`); err != nil {
+		t.Fatalf("Failed to corrupt transaction: %v", err)
+	}
+	if _, err := service.db.Exec(`DELETE FROM journal_entries WHERE account_type = 'system_liability'
+		AND transaction_id = (SELECT id FROM transactions WHERE external_transaction_id = 'dep2')`); err != nil {
+		t.Fatalf("Failed to corrupt journal: %v", err)
+	}
+
+	broken, err := service.ListLedgerEntriesAfter(ctx, rest[0].Sequence, 10)
+	if err != nil {
+		t.Fatalf("ListLedgerEntriesAfter failed: %v", err)
+	}
+	violations := invariants.Add(broken)
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", violations)
+	}
+	if violations[0].Invariant != models.InvariantBalanceArithmetic || violations[0].TransactionId != broken[0].TransactionId {
+		t.Errorf("Unexpected arithmetic violation: %+v", violations[0])
+	}
+	if violations[1].Invariant != models.InvariantCustomerLiability || violations[1].Asset != "ETH" {
+		t.Errorf("Unexpected liability violation: %+v", violations[1])
+	}
+}
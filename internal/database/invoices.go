@@ -0,0 +1,207 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Invoice address statuses
+const (
+	InvoiceStatusOpen     = "open"
+	InvoiceStatusPaid     = "paid"
+	InvoiceStatusPaidLate = "paid_late"
+)
+
+// CreateInvoiceAddressParams binds a freshly generated deposit address to one expected payment
+type CreateInvoiceAddressParams struct {
+	Reference string
+	Address   StoreAddressParams
+	// Amount is optional (zero) and only used to warn about mismatches when the payment arrives
+	Amount    decimal.Decimal
+	ExpiresAt time.Time
+}
+
+func (s *Service) initInvoiceSchema() error {
+	schema := `
+	-- Deposit addresses generated for a single expected payment
+	CREATE TABLE IF NOT EXISTS invoice_addresses (
+		reference TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		address TEXT NOT NULL UNIQUE,
+		asset TEXT NOT NULL,
+		network TEXT NOT NULL,
+		amount TEXT NOT NULL DEFAULT '0',
+		expires_at TIMESTAMP NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		transaction_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// CreateInvoiceAddress stores the address and the invoice it belongs to together. The address is
+// labelled with the invoice reference so it stands out in address listings.
+func (s *Service) CreateInvoiceAddress(ctx context.Context, params CreateInvoiceAddressParams) (*models.InvoiceAddress, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	addr := params.Address
+	if _, err := tx.ExecContext(ctx, queryInsertAddress, uuid.New().String(), addr.UserId, addr.Asset, addr.Network,
+		addr.Address, addr.WalletId, addr.AccountIdentifier); err != nil {
+		return nil, fmt.Errorf("unable to insert address: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, querySetAddressLabel, fmt.Sprintf("invoice:%s", params.Reference), addr.Address); err != nil {
+		return nil, fmt.Errorf("unable to label invoice address: %w", err)
+	}
+
+	expiresAt := params.ExpiresAt.UTC()
+	if _, err := tx.ExecContext(ctx, queryInsertInvoiceAddress, params.Reference, addr.UserId, addr.Address,
+		addr.Asset, addr.Network, params.Amount.String(), expiresAt); err != nil {
+		return nil, fmt.Errorf("unable to create invoice address: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("unable to commit invoice address: %w", err)
+	}
+
+	s.logger.Info("Invoice address created",
+		zap.String("reference", params.Reference),
+		zap.String("user_id", addr.UserId),
+		zap.String("asset", addr.Asset),
+		zap.String("address", addr.Address),
+		zap.Time("expires_at", expiresAt))
+
+	return s.findInvoiceAddress(ctx, addr.Address)
+}
+
+// ListInvoiceAddresses returns all invoice addresses, newest first
+func (s *Service) ListInvoiceAddresses(ctx context.Context) ([]models.InvoiceAddress, error) {
+	rows, err := s.db.QueryContext(ctx, queryListInvoiceAddresses)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query invoice addresses: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var invoices []models.InvoiceAddress
+	for rows.Next() {
+		invoice, err := scanInvoiceAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, *invoice)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating invoice addresses: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// findInvoiceAddress returns the invoice an address was generated for, or nil for ordinary addresses
+func (s *Service) findInvoiceAddress(ctx context.Context, address string) (*models.InvoiceAddress, error) {
+	invoice, err := scanInvoiceAddress(s.db.QueryRowContext(ctx, queryFindInvoiceAddress, address))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up invoice address: %w", err)
+	}
+	return invoice, nil
+}
+
+// settleInvoice checks a deposit to an invoice address and returns its ledger reference and the
+// status to settle the invoice with, empty when it was already paid. Late payments are credited
+// like any other but flagged.
+func (s *Service) settleInvoice(ctx context.Context, invoice *models.InvoiceAddress, amount decimal.Decimal) (reference, status string) {
+	logger := correlation.Logger(ctx, s.logger).With(zap.String("invoice", invoice.Reference))
+
+	switch {
+	case invoice.Status != InvoiceStatusOpen:
+		logger.Warn("Additional payment to an already paid invoice address",
+			zap.String("status", invoice.Status),
+			zap.String("amount", amount.String()))
+	case invoice.Expired(time.Now()):
+		logger.Warn("ALERT: Late payment to an expired invoice address - crediting and flagging",
+			zap.Time("expires_at", invoice.ExpiresAt),
+			zap.String("amount", amount.String()))
+		status = InvoiceStatusPaidLate
+	default:
+		status = InvoiceStatusPaid
+	}
+
+	if invoice.Amount.IsPositive() && !invoice.Amount.Equal(amount) {
+		logger.Warn("Invoice amount mismatch",
+			zap.String("expected_amount", invoice.Amount.String()),
+			zap.String("received_amount", amount.String()))
+	}
+
+	return fmt.Sprintf("invoice:%s", invoice.Reference), status
+}
+
+// markInvoicePaid records the deposit that settled an open invoice
+func (s *Service) markInvoicePaid(ctx context.Context, invoice *models.InvoiceAddress, status, transactionId string) {
+	if _, err := s.db.ExecContext(ctx, queryMarkInvoicePaid, status, transactionId, invoice.Reference); err != nil {
+		correlation.Logger(ctx, s.logger).Error("Deposit credited but invoice could not be marked paid",
+			zap.String("invoice", invoice.Reference),
+			zap.String("transaction_id", transactionId),
+			zap.Error(err))
+	}
+}
+
+func scanInvoiceAddress(row rowScanner) (*models.InvoiceAddress, error) {
+	var invoice models.InvoiceAddress
+	var amountStr string
+	if err := row.Scan(&invoice.Reference, &invoice.UserId, &invoice.Address, &invoice.Asset, &invoice.Network,
+		&amountStr, &invoice.ExpiresAt, &invoice.Status, &invoice.TransactionId, &invoice.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	invoice.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse invoice amount '%s': %w", amountStr, err)
+	}
+
+	return &invoice, nil
+}
@@ -0,0 +1,95 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestInvoiceAddressPayments(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	createInvoice := func(reference, address string, expiresAt time.Time) {
+		t.Helper()
+		_, err := service.CreateInvoiceAddress(ctx, CreateInvoiceAddressParams{
+			Reference: reference,
+			Address: StoreAddressParams{
+				UserId:            "user1",
+				Asset:             "USDC",
+				Network:           "base-mainnet",
+				Address:           address,
+				WalletId:          "wallet-1",
+				AccountIdentifier: "addr-" + reference,
+			},
+			Amount:    decimal.NewFromInt(25),
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			t.Fatalf("CreateInvoiceAddress failed: %v", err)
+		}
+	}
+
+	createInvoice("INV-1", "0xOnTime", time.Now().Add(time.Hour))
+	createInvoice("INV-2", "0xLate", time.Now().Add(-time.Hour))
+
+	for address, txId := range map[string]string{"0xOnTime": "prime-tx-1", "0xLate": "prime-tx-2"} {
+		if err := service.ProcessDeposit(ctx, address, "USDC", decimal.NewFromInt(25), txId, models.DepositSource{}, ""); err != nil {
+			t.Fatalf("ProcessDeposit to %s failed: %v", address, err)
+		}
+	}
+
+	// Late payments are credited like on-time ones
+	balance, err := service.GetUserBalance(ctx, "user1", "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance failed: %v", err)
+	}
+	if !balance.Equal(decimal.NewFromInt(50)) {
+		t.Errorf("Expected balance 50, got %s", balance)
+	}
+
+	invoices, err := service.ListInvoiceAddresses(ctx)
+	if err != nil {
+		t.Fatalf("ListInvoiceAddresses failed: %v", err)
+	}
+	statuses := make(map[string]models.InvoiceAddress)
+	for _, invoice := range invoices {
+		statuses[invoice.Reference] = invoice
+	}
+	if got := statuses["INV-1"]; got.Status != InvoiceStatusPaid || got.TransactionId == "" {
+		t.Errorf("Expected INV-1 paid with a transaction, got %+v", got)
+	}
+	if got := statuses["INV-2"]; got.Status != InvoiceStatusPaidLate {
+		t.Errorf("Expected INV-2 paid_late, got %s", got.Status)
+	}
+
+	addr, err := service.GetAddresses(ctx, "user1", "USDC", "base-mainnet")
+	if err != nil {
+		t.Fatalf("GetAddresses failed: %v", err)
+	}
+	if len(addr) != 2 || addr[0].Label == "" {
+		t.Errorf("Expected 2 labelled invoice addresses, got %+v", addr)
+	}
+}
@@ -0,0 +1,60 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// ListLedgerSyncEntries returns the deposits and withdrawals recorded for an asset in [start, end) that
+// should each match a Prime wallet transaction. Withdrawal reversals are excluded.
+func (s *Service) ListLedgerSyncEntries(ctx context.Context, asset string, start, end time.Time) ([]models.LedgerSyncEntry, error) {
+	rows, err := s.db.QueryContext(ctx, queryListLedgerSyncEntries, asset,
+		start.UTC().Format(sqliteTimeLayout), end.UTC().Format(sqliteTimeLayout))
+	if err != nil {
+		return nil, fmt.Errorf("unable to query ledger entries: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var entries []models.LedgerSyncEntry
+	for rows.Next() {
+		var entry models.LedgerSyncEntry
+		if err := rows.Scan(&entry.TransactionId, &entry.UserId, &entry.Asset, &entry.TransactionType, &entry.Amount,
+			&entry.ExternalTransactionId, &entry.BatchId, &entry.QueueStatus, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan ledger entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ledger entries: %w", err)
+	}
+
+	return entries, nil
+}
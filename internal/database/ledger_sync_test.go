@@ -0,0 +1,81 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestListLedgerSyncEntries(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initWithdrawalQueueSchema(); err != nil {
+		t.Fatalf("Failed to create withdrawal queue schema: %v", err)
+	}
+
+	ctx := context.Background()
+	start := time.Now().Add(-time.Hour)
+	end := time.Now().Add(time.Hour)
+
+	legs := []ProcessTransactionParams{
+		{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(2), ExternalTxId: "prime-deposit-1"},
+		{UserId: "user1", Asset: "BTC", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-1), ExternalTxId: "user1-key"},
+		{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: "user1-key:reversal", ReversalOf: "user1-key"},
+		{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(5), ExternalTxId: "prime-deposit-2"},
+	}
+	for _, leg := range legs {
+		if _, err := service.subledger.ProcessTransaction(ctx, leg); err != nil {
+			t.Fatalf("Failed to record %s: %v", leg.ExternalTxId, err)
+		}
+	}
+
+	if _, err := service.EnqueueWithdrawal(ctx, EnqueueWithdrawalParams{
+		UserId:         "user1",
+		Asset:          "BTC",
+		AssetNetwork:   "BTC-bitcoin-mainnet",
+		Amount:         decimal.NewFromInt(1),
+		Destination:    "bc1qdestination",
+		WalletId:       "wallet1",
+		IdempotencyKey: "user1-key",
+	}); err != nil {
+		t.Fatalf("EnqueueWithdrawal failed: %v", err)
+	}
+
+	entries, err := service.ListLedgerSyncEntries(ctx, "BTC", start, end)
+	if err != nil {
+		t.Fatalf("ListLedgerSyncEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected deposit and withdrawal without the reversal, got %+v", entries)
+	}
+	if entries[1].ExternalTransactionId != "user1-key" || entries[1].QueueStatus != WithdrawalQueueStatusQueued {
+		t.Errorf("Expected withdrawal joined to its queue entry, got %+v", entries[1])
+	}
+
+	entries, err = service.ListLedgerSyncEntries(ctx, "BTC", end, end.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("ListLedgerSyncEntries failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected no entries after the range, got %d", len(entries))
+	}
+}
@@ -0,0 +1,196 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// WAL checkpoint modes. PASSIVE never waits for other connections; TRUNCATE also resets the WAL file
+// but waits for readers and writers to finish.
+const (
+	CheckpointPassive  = "PASSIVE"
+	CheckpointTruncate = "TRUNCATE"
+)
+
+// MaintenanceOptions controls a maintenance run
+type MaintenanceOptions struct {
+	CheckpointMode string
+	// Retention deletes rows older than the duration from each table in models.RetentionTables; tables
+	// without an entry are kept forever
+	Retention map[string]time.Duration
+	// VacuumFreeRatio is the fraction of free pages above which VACUUM runs; zero disables VACUUM
+	VacuumFreeRatio decimal.Decimal
+	// ForceVacuum runs VACUUM regardless of the free page ratio
+	ForceVacuum bool
+}
+
+// RunMaintenance checkpoints the WAL, refreshes query planner statistics, checks the database and
+// its indexes, and reclaims free pages with VACUUM when enough of the file is unused. VACUUM briefly
+// takes an exclusive lock; other connections wait for it up to the configured busy timeout.
+func (s *Service) RunMaintenance(ctx context.Context, opts MaintenanceOptions) (*models.MaintenanceReport, error) {
+	started := time.Now()
+	report := &models.MaintenanceReport{}
+
+	mode := opts.CheckpointMode
+	if mode == "" {
+		mode = CheckpointPassive
+	}
+	if mode != CheckpointPassive && mode != CheckpointTruncate {
+		return nil, fmt.Errorf("unsupported checkpoint mode %q", mode)
+	}
+
+	// Purge first so the pages freed are counted towards VACUUM
+	purged, err := s.PurgeExpired(ctx, opts.Retention)
+	if err != nil {
+		return nil, err
+	}
+	report.Purged = purged
+
+	var busy int
+	if err := s.db.QueryRowContext(ctx, fmt.Sprintf("PRAGMA wal_checkpoint(%s)", mode)).Scan(
+		&busy, &report.WalFrames, &report.CheckpointedFrames); err != nil {
+		return nil, fmt.Errorf("unable to checkpoint WAL: %w", err)
+	}
+	report.CheckpointBusy = busy != 0
+
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_count").Scan(&report.PageCount); err != nil {
+		return nil, fmt.Errorf("unable to read page count: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA freelist_count").Scan(&report.FreelistCount); err != nil {
+		return nil, fmt.Errorf("unable to read freelist count: %w", err)
+	}
+	if err := s.db.QueryRowContext(ctx, "PRAGMA page_size").Scan(&report.PageSize); err != nil {
+		return nil, fmt.Errorf("unable to read page size: %w", err)
+	}
+
+	problems, err := s.quickCheck(ctx)
+	if err != nil {
+		return nil, err
+	}
+	report.IntegrityProblems = problems
+
+	if _, err := s.db.ExecContext(ctx, "ANALYZE"); err != nil {
+		return nil, fmt.Errorf("unable to analyze database: %w", err)
+	}
+	report.Analyzed = true
+
+	// VACUUM rewrites the whole file, so it is skipped when the database is damaged or mostly in use
+	if len(problems) == 0 && (opts.ForceVacuum || shouldVacuum(report.PageCount, report.FreelistCount, opts.VacuumFreeRatio)) {
+		if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+			return nil, fmt.Errorf("unable to vacuum database: %w", err)
+		}
+		report.Vacuumed = true
+	}
+
+	report.Duration = time.Since(started)
+
+	s.logger.Info("Database maintenance complete",
+		zap.Bool("checkpoint_busy", report.CheckpointBusy),
+		zap.Int("wal_frames", report.WalFrames),
+		zap.Int("checkpointed_frames", report.CheckpointedFrames),
+		zap.Int64("page_count", report.PageCount),
+		zap.Int64("freelist_count", report.FreelistCount),
+		zap.Bool("vacuumed", report.Vacuumed),
+		zap.Int("integrity_problems", len(report.IntegrityProblems)),
+		zap.Duration("duration", report.Duration))
+	return report, nil
+}
+
+// PurgeExpired deletes rows older than their table's retention and returns how many were deleted per table
+func (s *Service) PurgeExpired(ctx context.Context, retention map[string]time.Duration) (map[string]int64, error) {
+	tables := make([]string, 0, len(retention))
+	for table := range retention {
+		if _, ok := models.RetentionTables[table]; !ok {
+			return nil, fmt.Errorf("retention is not supported for table %q", table)
+		}
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	purged := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		if retention[table] <= 0 {
+			continue
+		}
+
+		cutoff := time.Now().Add(-retention[table]).UTC().Format("2006-01-02 15:04:05")
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s < ?", table, models.RetentionTables[table])
+		result, err := s.db.ExecContext(ctx, query, cutoff)
+		if err != nil {
+			return nil, fmt.Errorf("unable to purge %s: %w", table, err)
+		}
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("unable to count rows purged from %s: %w", table, err)
+		}
+		purged[table] = deleted
+
+		if deleted > 0 {
+			s.logger.Info("Purged expired rows",
+				zap.String("table", table),
+				zap.Int64("rows", deleted),
+				zap.Duration("retention", retention[table]))
+		}
+	}
+	return purged, nil
+}
+
+// quickCheck runs PRAGMA quick_check, which verifies the database structure and that indexes are in
+// order, and returns the problems it reports
+func (s *Service) quickCheck(ctx context.Context) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, "PRAGMA quick_check")
+	if err != nil {
+		return nil, fmt.Errorf("unable to check database integrity: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, fmt.Errorf("unable to scan integrity check result: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading integrity check results: %w", err)
+	}
+	return problems, nil
+}
+
+func shouldVacuum(pageCount, freelistCount int64, freeRatio decimal.Decimal) bool {
+	if pageCount == 0 || freelistCount == 0 || !freeRatio.IsPositive() {
+		return false
+	}
+	return decimal.NewFromInt(freelistCount).Div(decimal.NewFromInt(pageCount)).GreaterThanOrEqual(freeRatio)
+}
@@ -0,0 +1,121 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRunMaintenance_VacuumsFragmentedDatabase(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "maintenance.db")+"?_journal_mode=WAL")
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	defer db.Close()
+	service := &Service{db: db, logger: zaptest.NewLogger(t)}
+	ctx := context.Background()
+
+	if _, err := db.Exec("CREATE TABLE blobs (id INTEGER PRIMARY KEY, data TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := db.Exec("CREATE INDEX idx_blobs_data ON blobs(data)"); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	for i := 0; i < 200; i++ {
+		if _, err := db.Exec("INSERT INTO blobs (data) VALUES (?)", fmt.Sprintf("%04d-%01000d", i, i)); err != nil {
+			t.Fatalf("Failed to insert row: %v", err)
+		}
+	}
+	if _, err := db.Exec("DELETE FROM blobs WHERE id > 20"); err != nil {
+		t.Fatalf("Failed to delete rows: %v", err)
+	}
+
+	// Below the free page ratio nothing is vacuumed
+	report, err := service.RunMaintenance(ctx, MaintenanceOptions{VacuumFreeRatio: decimal.NewFromInt(1)})
+	if err != nil {
+		t.Fatalf("RunMaintenance failed: %v", err)
+	}
+	if report.Vacuumed || !report.Analyzed || len(report.IntegrityProblems) != 0 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if report.FreelistCount == 0 {
+		t.Fatalf("Expected free pages after deleting rows, got %+v", report)
+	}
+
+	report, err = service.RunMaintenance(ctx, MaintenanceOptions{
+		CheckpointMode:  CheckpointTruncate,
+		VacuumFreeRatio: decimal.NewFromFloat(0.1),
+	})
+	if err != nil {
+		t.Fatalf("RunMaintenance failed: %v", err)
+	}
+	if !report.Vacuumed {
+		t.Fatalf("Expected VACUUM above the free page ratio, got %+v", report)
+	}
+
+	var freePages int
+	if err := db.QueryRow("PRAGMA freelist_count").Scan(&freePages); err != nil {
+		t.Fatalf("Failed to read freelist: %v", err)
+	}
+	if freePages != 0 {
+		t.Errorf("Expected VACUUM to reclaim free pages, %d left", freePages)
+	}
+
+	if _, err := service.RunMaintenance(ctx, MaintenanceOptions{CheckpointMode: "FULL"}); err == nil {
+		t.Error("Expected an unsupported checkpoint mode to be rejected")
+	}
+}
+
+func TestPurgeExpired(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initPrimeTransactionSchema(); err != nil {
+		t.Fatalf("Failed to create prime transaction schema: %v", err)
+	}
+	if _, err := service.db.Exec(`INSERT INTO prime_transactions (id, payload, updated_at) VALUES
+		('old', x'00', datetime('now', '-40 days')),
+		('recent', x'00', datetime('now', '-1 day'))`); err != nil {
+		t.Fatalf("Failed to insert prime transactions: %v", err)
+	}
+
+	purged, err := service.PurgeExpired(ctx, map[string]time.Duration{"prime_transactions": 30 * 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("PurgeExpired failed: %v", err)
+	}
+	if purged["prime_transactions"] != 1 {
+		t.Errorf("Expected 1 purged row, got %v", purged)
+	}
+
+	var remaining string
+	if err := service.db.QueryRow("SELECT id FROM prime_transactions").Scan(&remaining); err != nil || remaining != "recent" {
+		t.Errorf("Expected only the recent row to remain, got %q (%v)", remaining, err)
+	}
+
+	if _, err := service.PurgeExpired(ctx, map[string]time.Duration{"transactions": time.Hour}); err == nil {
+		t.Error("Expected an error purging a ledger table")
+	}
+}
@@ -0,0 +1,116 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func (s *Service) initTransactionObservationSchema() error {
+	schema := `
+	-- Prime transactions the listener saw but did not apply, with the latest reason
+	CREATE TABLE IF NOT EXISTS transaction_observations (
+		prime_transaction_id TEXT PRIMARY KEY,
+		wallet_id TEXT NOT NULL DEFAULT '',
+		type TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT '',
+		symbol TEXT NOT NULL DEFAULT '',
+		network TEXT NOT NULL DEFAULT '',
+		amount TEXT NOT NULL DEFAULT '',
+		idempotency_key TEXT NOT NULL DEFAULT '',
+		reason TEXT NOT NULL,
+		detail TEXT NOT NULL DEFAULT '',
+		seen_count INTEGER NOT NULL DEFAULT 1,
+		first_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transaction_observations_reason ON transaction_observations(reason, last_seen_at);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// TransactionObservationFilter selects transaction observations; empty fields match everything
+type TransactionObservationFilter struct {
+	PrimeTransactionId string
+	Reason             string
+	Limit              int
+}
+
+// RecordTransactionObservation records why the listener passed over a Prime transaction. A
+// transaction seen again replaces its reason and status and bumps its seen count.
+func (s *Service) RecordTransactionObservation(ctx context.Context, tx models.PrimeTransaction, reason, detail string) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertTransactionObservation,
+		tx.Id, tx.WalletId, tx.Type, tx.Status, tx.Symbol, tx.Network, tx.Amount, tx.IdempotencyKey, reason, detail)
+	if err != nil {
+		return fmt.Errorf("unable to record observation of %s: %w", tx.Id, err)
+	}
+	return nil
+}
+
+// ListTransactionObservations returns observations matching a filter, most recently seen first
+func (s *Service) ListTransactionObservations(ctx context.Context, filter TransactionObservationFilter) ([]models.TransactionObservation, error) {
+	query := querySelectTransactionObservations + " WHERE 1 = 1"
+	args := []interface{}{}
+	if filter.PrimeTransactionId != "" {
+		query += " AND o.prime_transaction_id = ?"
+		args = append(args, filter.PrimeTransactionId)
+	}
+	if filter.Reason != "" {
+		query += " AND o.reason = ?"
+		args = append(args, filter.Reason)
+	}
+	query += " ORDER BY o.last_seen_at DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query transaction observations: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var observations []models.TransactionObservation
+	for rows.Next() {
+		var o models.TransactionObservation
+		if err := rows.Scan(&o.PrimeTransactionId, &o.WalletId, &o.Type, &o.Status, &o.Symbol, &o.Network, &o.Amount,
+			&o.IdempotencyKey, &o.Reason, &o.Detail, &o.SeenCount, &o.FirstSeenAt, &o.LastSeenAt, &o.Applied); err != nil {
+			return nil, fmt.Errorf("unable to scan transaction observation: %w", err)
+		}
+		observations = append(observations, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction observations: %w", err)
+	}
+
+	return observations, nil
+}
@@ -0,0 +1,77 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTransactionObservations(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initTransactionObservationSchema(); err != nil {
+		t.Fatalf("Failed to create observation schema: %v", err)
+	}
+
+	deposit := models.PrimeTransaction{Id: "prime-dep", WalletId: "wallet-1", Type: "DEPOSIT", Status: "TRANSACTION_CREATED", Symbol: "BTC", Amount: "0.5"}
+	if err := service.RecordTransactionObservation(ctx, deposit, models.ObservationStatus, "credited at TRANSACTION_IMPORTED"); err != nil {
+		t.Fatalf("RecordTransactionObservation failed: %v", err)
+	}
+	conversion := models.PrimeTransaction{Id: "prime-conv", WalletId: "wallet-1", Type: "CONVERSION", Status: "TRANSACTION_DONE", Symbol: "BTC", Amount: "1"}
+	if err := service.RecordTransactionObservation(ctx, conversion, models.ObservationType, ""); err != nil {
+		t.Fatalf("RecordTransactionObservation failed: %v", err)
+	}
+
+	// Seeing a transaction again keeps one row with the latest reason
+	deposit.Status = "TRANSACTION_IMPORTED"
+	if err := service.RecordTransactionObservation(ctx, deposit, models.ObservationDust, "threshold 1"); err != nil {
+		t.Fatalf("RecordTransactionObservation failed: %v", err)
+	}
+
+	observations, err := service.ListTransactionObservations(ctx, TransactionObservationFilter{PrimeTransactionId: "prime-dep"})
+	if err != nil {
+		t.Fatalf("ListTransactionObservations failed: %v", err)
+	}
+	if len(observations) != 1 {
+		t.Fatalf("Expected 1 observation, got %d", len(observations))
+	}
+	got := observations[0]
+	if got.Reason != models.ObservationDust || got.Status != "TRANSACTION_IMPORTED" || got.SeenCount != 2 || got.Applied {
+		t.Errorf("Unexpected observation: %+v", got)
+	}
+
+	byReason, err := service.ListTransactionObservations(ctx, TransactionObservationFilter{Reason: models.ObservationType})
+	if err != nil || len(byReason) != 1 || byReason[0].PrimeTransactionId != "prime-conv" {
+		t.Fatalf("Expected the conversion by reason, got %+v (%v)", byReason, err)
+	}
+
+	// Once the ledger records the transaction the observation reports it as applied
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.RequireFromString("0.5"), ExternalTxId: "prime-dep"}); err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+	observations, err = service.ListTransactionObservations(ctx, TransactionObservationFilter{PrimeTransactionId: "prime-dep"})
+	if err != nil || len(observations) != 1 || !observations[0].Applied {
+		t.Errorf("Expected the observation to be applied, got %+v (%v)", observations, err)
+	}
+}
@@ -0,0 +1,114 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func (s *Service) initOperationLockSchema() error {
+	schema := `
+	-- Advisory locks that keep ledger-writing commands from running at the same time
+	CREATE TABLE IF NOT EXISTS operation_locks (
+		name TEXT PRIMARY KEY,
+		owner TEXT NOT NULL,
+		operation TEXT NOT NULL,
+		pid INTEGER NOT NULL DEFAULT 0,
+		hostname TEXT NOT NULL DEFAULT '',
+		acquired_at TIMESTAMP NOT NULL,
+		expires_at TIMESTAMP NOT NULL
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// AcquireOperationLock takes the named lock for lock.Owner until lock.ExpiresAt. A lock whose holder
+// let it expire is taken over. If another owner holds it, the error wraps ErrOperationInProgress and
+// names the holder.
+func (s *Service) AcquireOperationLock(ctx context.Context, lock models.OperationLock) error {
+	result, err := s.db.ExecContext(ctx, queryAcquireOperationLock,
+		lock.Name, lock.Owner, lock.Operation, lock.Pid, lock.Hostname, lock.AcquiredAt.UTC(), lock.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("unable to acquire operation lock %s: %w", lock.Name, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 1 {
+		return nil
+	}
+
+	holder, err := s.GetOperationLock(ctx, lock.Name)
+	if err != nil {
+		return err
+	}
+	if holder == nil {
+		// The holder released the lock in between; let the caller try again
+		return ErrOperationInProgress
+	}
+	return fmt.Errorf("%w: %s (pid %d on %s) has held the %s lock since %s",
+		ErrOperationInProgress, holder.Operation, holder.Pid, holder.Hostname, holder.Name,
+		holder.AcquiredAt.Local().Format("2006-01-02 15:04:05"))
+}
+
+// GetOperationLock returns the current holder of the named lock, or nil if it is free
+func (s *Service) GetOperationLock(ctx context.Context, name string) (*models.OperationLock, error) {
+	var lock models.OperationLock
+	err := s.db.QueryRowContext(ctx, queryGetOperationLock, name).Scan(
+		&lock.Name, &lock.Owner, &lock.Operation, &lock.Pid, &lock.Hostname, &lock.AcquiredAt, &lock.ExpiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get operation lock %s: %w", name, err)
+	}
+	return &lock, nil
+}
+
+// RefreshOperationLock extends a held lock to expiresAt. It fails with ErrOperationLockLost if the
+// lock expired and was taken over by another owner.
+func (s *Service) RefreshOperationLock(ctx context.Context, name, owner string, expiresAt time.Time) error {
+	result, err := s.db.ExecContext(ctx, queryRefreshOperationLock, expiresAt.UTC(), name, owner)
+	if err != nil {
+		return fmt.Errorf("unable to refresh operation lock %s: %w", name, err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrOperationLockLost
+	}
+	return nil
+}
+
+// ReleaseOperationLock frees the named lock if owner still holds it
+func (s *Service) ReleaseOperationLock(ctx context.Context, name, owner string) error {
+	if _, err := s.db.ExecContext(ctx, queryReleaseOperationLock, name, owner); err != nil {
+		return fmt.Errorf("unable to release operation lock %s: %w", name, err)
+	}
+	return nil
+}
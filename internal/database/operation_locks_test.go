@@ -0,0 +1,83 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestOperationLocks(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initOperationLockSchema(); err != nil {
+		t.Fatalf("Failed to create operation lock schema: %v", err)
+	}
+
+	now := time.Now()
+	setup := models.OperationLock{
+		Name:       "ledger-write",
+		Owner:      "owner-setup",
+		Operation:  "setup",
+		Pid:        100,
+		Hostname:   "host-a",
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(time.Minute),
+	}
+	if err := service.AcquireOperationLock(ctx, setup); err != nil {
+		t.Fatalf("AcquireOperationLock failed: %v", err)
+	}
+
+	withdrawal := setup
+	withdrawal.Owner = "owner-withdrawal"
+	withdrawal.Operation = "withdrawal"
+	err := service.AcquireOperationLock(ctx, withdrawal)
+	if !errors.Is(err, ErrOperationInProgress) {
+		t.Fatalf("Expected ErrOperationInProgress, got %v", err)
+	}
+
+	// Once the holder lets the lock expire, the next command takes it over
+	withdrawal.AcquiredAt = now.Add(2 * time.Minute)
+	withdrawal.ExpiresAt = withdrawal.AcquiredAt.Add(time.Minute)
+	if err := service.AcquireOperationLock(ctx, withdrawal); err != nil {
+		t.Fatalf("Expected expired lock to be taken over, got %v", err)
+	}
+	if err := service.RefreshOperationLock(ctx, setup.Name, setup.Owner, now.Add(3*time.Minute)); !errors.Is(err, ErrOperationLockLost) {
+		t.Errorf("Expected ErrOperationLockLost for the previous holder, got %v", err)
+	}
+
+	// Only the holder can release the lock
+	if err := service.ReleaseOperationLock(ctx, setup.Name, setup.Owner); err != nil {
+		t.Fatalf("ReleaseOperationLock failed: %v", err)
+	}
+	holder, err := service.GetOperationLock(ctx, setup.Name)
+	if err != nil || holder == nil || holder.Owner != "owner-withdrawal" {
+		t.Fatalf("Expected withdrawal to hold the lock, got %+v (%v)", holder, err)
+	}
+	if err := service.ReleaseOperationLock(ctx, withdrawal.Name, withdrawal.Owner); err != nil {
+		t.Fatalf("ReleaseOperationLock failed: %v", err)
+	}
+	if holder, err := service.GetOperationLock(ctx, setup.Name); err != nil || holder != nil {
+		t.Errorf("Expected lock to be free, got %+v (%v)", holder, err)
+	}
+}
@@ -0,0 +1,134 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// ErrPrimeTransactionNotFound is returned when no raw payload is stored for a transaction
+var ErrPrimeTransactionNotFound = errors.New("prime transaction not found")
+
+func (s *Service) initPrimeTransactionSchema() error {
+	schema := `
+	-- Latest raw payload Prime reported for each transaction, gzip compressed
+	CREATE TABLE IF NOT EXISTS prime_transactions (
+		id TEXT PRIMARY KEY,
+		wallet_id TEXT NOT NULL DEFAULT '',
+		idempotency_key TEXT NOT NULL DEFAULT '',
+		type TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT '',
+		payload BLOB NOT NULL,
+		first_seen_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_prime_transactions_idempotency_key ON prime_transactions(idempotency_key);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// SavePrimeTransaction stores the raw payload of a Prime transaction, replacing the previous payload
+// so the latest status Prime reported is kept
+func (s *Service) SavePrimeTransaction(ctx context.Context, tx models.PrimeTransaction) error {
+	if len(tx.Raw) == 0 {
+		return nil
+	}
+
+	payload, err := compressPayload(tx.Raw)
+	if err != nil {
+		return fmt.Errorf("unable to compress payload for %s: %w", tx.Id, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, queryUpsertPrimeTransaction,
+		tx.Id, tx.WalletId, tx.IdempotencyKey, tx.Type, tx.Status, payload)
+	if err != nil {
+		return fmt.Errorf("unable to save prime transaction %s: %w", tx.Id, err)
+	}
+	return nil
+}
+
+// GetPrimeTransaction returns the stored payload for a Prime transaction id, a withdrawal idempotency
+// key, or a ledger transaction id, with the payload decompressed
+func (s *Service) GetPrimeTransaction(ctx context.Context, id string) (*models.PrimeTransactionRecord, error) {
+	record, err := s.getPrimeTransaction(ctx, id)
+	if !errors.Is(err, ErrPrimeTransactionNotFound) {
+		return record, err
+	}
+
+	// Fall back to the Prime id or idempotency key a ledger transaction was recorded under
+	var externalId sql.NullString
+	err = s.db.QueryRowContext(ctx, queryGetExternalTransactionId, id).Scan(&externalId)
+	if errors.Is(err, sql.ErrNoRows) || !externalId.Valid || externalId.String == "" {
+		return nil, fmt.Errorf("%w: %s", ErrPrimeTransactionNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up ledger transaction %s: %w", id, err)
+	}
+	return s.getPrimeTransaction(ctx, externalId.String)
+}
+
+func (s *Service) getPrimeTransaction(ctx context.Context, id string) (*models.PrimeTransactionRecord, error) {
+	var record models.PrimeTransactionRecord
+	var payload []byte
+	err := s.db.QueryRowContext(ctx, queryGetPrimeTransaction, id, id).Scan(
+		&record.Id, &record.WalletId, &record.IdempotencyKey, &record.Type, &record.Status,
+		&payload, &record.FirstSeenAt, &record.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPrimeTransactionNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to query prime transaction %s: %w", id, err)
+	}
+
+	record.Payload, err = decompressPayload(payload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decompress payload for %s: %w", record.Id, err)
+	}
+	return &record, nil
+}
+
+func compressPayload(raw []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompressPayload(payload []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
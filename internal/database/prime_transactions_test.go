@@ -0,0 +1,76 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestPrimeTransactions(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initPrimeTransactionSchema(); err != nil {
+		t.Fatalf("Failed to create prime transaction schema: %v", err)
+	}
+
+	tx := models.PrimeTransaction{
+		Id:             "prime-withdrawal",
+		WalletId:       "wallet-1",
+		IdempotencyKey: "user1-withdrawal",
+		Type:           "WITHDRAWAL",
+		Status:         "TRANSACTION_CREATED",
+		Raw:            []byte(`{"id":"prime-withdrawal","status":"TRANSACTION_CREATED"}`),
+	}
+	if err := service.SavePrimeTransaction(ctx, tx); err != nil {
+		t.Fatalf("SavePrimeTransaction failed: %v", err)
+	}
+
+	// A later status replaces the payload
+	tx.Status = "TRANSACTION_DONE"
+	tx.Raw = []byte(`{"id":"prime-withdrawal","status":"TRANSACTION_DONE"}`)
+	if err := service.SavePrimeTransaction(ctx, tx); err != nil {
+		t.Fatalf("SavePrimeTransaction failed: %v", err)
+	}
+
+	if _, err := service.db.Exec(`INSERT INTO transactions (id, user_id, asset, transaction_type, amount, balance_before, balance_after, external_transaction_id)
+		VALUES ('ledger-1', 'user1', 'ETH', 'withdrawal', -1, 2, 1, 'user1-withdrawal')`); err != nil {
+		t.Fatalf("Failed to insert ledger transaction: %v", err)
+	}
+
+	for _, id := range []string{"prime-withdrawal", "user1-withdrawal", "ledger-1"} {
+		record, err := service.GetPrimeTransaction(ctx, id)
+		if err != nil {
+			t.Fatalf("GetPrimeTransaction(%s) failed: %v", id, err)
+		}
+		if record.Id != "prime-withdrawal" || record.Status != "TRANSACTION_DONE" {
+			t.Errorf("GetPrimeTransaction(%s) returned %+v", id, record)
+		}
+		if string(record.Payload) != string(tx.Raw) {
+			t.Errorf("GetPrimeTransaction(%s) payload = %s", id, record.Payload)
+		}
+	}
+
+	if _, err := service.GetPrimeTransaction(ctx, "unknown"); !errors.Is(err, ErrPrimeTransactionNotFound) {
+		t.Errorf("Expected ErrPrimeTransactionNotFound, got %v", err)
+	}
+}
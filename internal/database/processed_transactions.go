@@ -0,0 +1,55 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+func (s *Service) initProcessedTransactionSchema() error {
+	schema := `
+	-- Prime transactions the listener has finished with, kept so a restart never handles one twice
+	CREATE TABLE IF NOT EXISTS processed_transactions (
+		transaction_id TEXT NOT NULL,
+		processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_processed_transactions_transaction_id ON processed_transactions(transaction_id);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// IsTransactionProcessed reports whether the listener has finished with a Prime transaction
+func (s *Service) IsTransactionProcessed(ctx context.Context, transactionId string) (bool, error) {
+	var exists int
+	if err := s.db.QueryRowContext(ctx, queryProcessedTransactionExists, transactionId).Scan(&exists); err != nil {
+		return false, fmt.Errorf("unable to check processed transaction %s: %w", transactionId, err)
+	}
+	return exists == 1, nil
+}
+
+// MarkTransactionProcessed records that the listener has finished with a Prime transaction. Marking it
+// again, e.g. by another listener instance, is a no-op.
+func (s *Service) MarkTransactionProcessed(ctx context.Context, transactionId string) error {
+	if _, err := s.db.ExecContext(ctx, queryMarkTransactionProcessed, transactionId); err != nil {
+		return fmt.Errorf("unable to mark transaction %s processed: %w", transactionId, err)
+	}
+	return nil
+}
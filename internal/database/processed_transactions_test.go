@@ -0,0 +1,76 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestProcessedTransactions_SurviveRestart(t *testing.T) {
+	ctx := context.Background()
+	cfg := models.DatabaseConfig{
+		Path:         filepath.Join(t.TempDir(), "processed.db"),
+		MaxOpenConns: 1,
+		PingTimeout:  time.Second,
+		BusyTimeout:  time.Second,
+	}
+
+	service, err := NewService(ctx, cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	if processed, err := service.IsTransactionProcessed(ctx, "prime-tx-1"); err != nil || processed {
+		t.Fatalf("Expected prime-tx-1 to be unprocessed, got %v (%v)", processed, err)
+	}
+	for i := 0; i < 2; i++ {
+		// Marking again, as a second listener instance would, must not fail
+		if err := service.MarkTransactionProcessed(ctx, "prime-tx-1"); err != nil {
+			t.Fatalf("MarkTransactionProcessed failed: %v", err)
+		}
+	}
+	service.Close()
+
+	// A restarted process still sees the transaction as processed
+	service, err = NewService(ctx, cfg, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+	defer service.Close()
+
+	if processed, err := service.IsTransactionProcessed(ctx, "prime-tx-1"); err != nil || !processed {
+		t.Errorf("Expected prime-tx-1 to be processed after a restart, got %v (%v)", processed, err)
+	}
+	if processed, err := service.IsTransactionProcessed(ctx, "prime-tx-2"); err != nil || processed {
+		t.Errorf("Expected prime-tx-2 to be unprocessed, got %v (%v)", processed, err)
+	}
+
+	var rows int
+	if err := service.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM processed_transactions`).Scan(&rows); err != nil {
+		t.Fatalf("Failed to count processed transactions: %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("Expected one row for prime-tx-1, got %d", rows)
+	}
+}
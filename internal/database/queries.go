@@ -19,48 +19,76 @@ package database
 const (
 	// User queries
 	queryGetActiveUsers = `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, tenant_id, created_at, updated_at
 		FROM users
-		WHERE active = 1
+		WHERE active = 1 AND (? = '' OR tenant_id = ?)
 		ORDER BY created_at`
 
 	queryInsertUser = `
-		INSERT OR IGNORE INTO users (id, name, email) VALUES (?, ?, ?)`
+		INSERT OR IGNORE INTO users (id, name, email, tenant_id) VALUES (?, ?, ?, ?)`
 
 	queryGetUserById = `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, tenant_id, created_at, updated_at
 		FROM users
-		WHERE id = ? AND active = 1`
+		WHERE id = ? AND active = 1 AND (? = '' OR tenant_id = ?)`
 
 	queryGetUserByEmail = `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, tenant_id, created_at, updated_at
 		FROM users
-		WHERE email = ? AND active = 1`
+		WHERE email = ? AND active = 1 AND (? = '' OR tenant_id = ?)`
 
 	// Address queries
 	queryInsertAddress = `
 		INSERT INTO addresses (id, user_id, asset, network, address, wallet_id, account_identifier)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
-		RETURNING *`
+		RETURNING id, user_id, asset, network, address, wallet_id, account_identifier, label, created_at`
 
 	queryGetUserAddresses = `
-		SELECT id, user_id, asset, network, address, wallet_id, account_identifier, created_at
+		SELECT id, user_id, asset, network, address, wallet_id, account_identifier, label, created_at
 		FROM addresses
 		WHERE user_id = ? AND asset = ? AND network = ?
 		ORDER BY created_at DESC`
 
 	queryGetAllUserAddresses = `
-		SELECT id, user_id, asset, network, address, wallet_id, account_identifier, created_at
+		SELECT id, user_id, asset, network, address, wallet_id, account_identifier, label, created_at
 		FROM addresses
 		WHERE user_id = ?
 		ORDER BY asset, created_at DESC`
 
+	queryFilterUserAddresses = `
+		SELECT id, user_id, asset, network, address, wallet_id, account_identifier, label, created_at
+		FROM addresses
+		WHERE user_id = ? AND (? = '' OR asset = ?) AND (? = '' OR network = ?)
+		ORDER BY asset, created_at DESC`
+
+	queryListUsersMissingAddress = `
+		SELECT u.id
+		FROM users u
+		WHERE (? = '' OR u.tenant_id = ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM addresses a
+			WHERE a.user_id = u.id AND a.asset = ? AND a.network = ?
+		  )
+		ORDER BY u.id`
+
 	queryFindUserByAddress = `
-		SELECT u.id, u.name, u.email, u.created_at, u.updated_at,
-		       a.id, a.user_id, a.asset, a.network, a.address, a.wallet_id, a.account_identifier, a.created_at
+		SELECT u.id, u.name, u.email, u.tenant_id, u.created_at, u.updated_at,
+		       a.id, a.user_id, a.asset, a.network, a.address, a.wallet_id, a.account_identifier, a.label, a.created_at
 		FROM users u
 		JOIN addresses a ON u.id = a.user_id
-		WHERE LOWER(a.address) = LOWER(?) AND u.active = 1`
+		WHERE LOWER(a.address) = LOWER(?) AND u.active = 1 AND (? = '' OR u.tenant_id = ?)`
+
+	queryFindAddress = `
+		SELECT id, user_id, asset, network, address, wallet_id, account_identifier, label, created_at
+		FROM addresses
+		WHERE LOWER(address) = LOWER(?) AND network = ?`
+
+	queryInsertHoldingUser = `
+		INSERT OR IGNORE INTO users (id, name, email, active) VALUES (?, ?, ?, 0)`
+
+	queryReassignAddress = `
+		UPDATE addresses SET user_id = ?
+		WHERE id = ? AND user_id = ?`
 
 	// Balance queries
 	queryGetBalance = `
@@ -68,57 +96,982 @@ const (
 		FROM account_balances 
 		WHERE user_id = ? AND asset = ?`
 
+	queryGetAvailableBalance = `
+		SELECT balance, available_balance
+		FROM account_balances
+		WHERE user_id = ? AND asset = ?`
+
+	// Every balance change bumps its account's version and accounts are never deleted, so the count and
+	// sum of a user's versions change whenever any of their balances do
+	queryGetUserBalancesVersion = `
+		SELECT COUNT(*), COALESCE(SUM(version), 0)
+		FROM account_balances
+		WHERE user_id = ?`
+
 	queryGetAllUserBalances = `
-		SELECT id, user_id, asset, balance, last_transaction_id, version, updated_at
+		SELECT id, user_id, asset, balance, available_balance, last_transaction_id, version, updated_at
 		FROM account_balances 
-		WHERE user_id = ? AND balance != 0
+		WHERE user_id = ? AND CAST(balance AS REAL) != 0
 		ORDER BY asset`
 
+	// User ids are passed as one JSON array so any number of users is read in a single query
+	queryGetBalancesBulk = `
+		SELECT id, user_id, asset, balance, available_balance, last_transaction_id, version, updated_at
+		FROM account_balances
+		WHERE asset = ? AND user_id IN (SELECT value FROM json_each(?)) AND (? = '' OR tenant_id = ?)
+		ORDER BY user_id`
+
+	queryGetAllUserBalancesIncludingZero = `
+		SELECT id, user_id, asset, balance, available_balance, last_transaction_id, version, updated_at
+		FROM account_balances
+		WHERE user_id = ?
+		ORDER BY asset`
+
+	queryListAccountBalances = `
+		SELECT id, user_id, asset, balance, available_balance, last_transaction_id, version, updated_at
+		FROM account_balances
+		WHERE CAST(balance AS REAL) != 0 AND (? = '' OR tenant_id = ?)
+		ORDER BY user_id, asset`
+
+	queryListAllAccountBalances = `
+		SELECT id, user_id, asset, balance, available_balance, last_transaction_id, version, updated_at
+		FROM account_balances
+		WHERE ? = '' OR tenant_id = ?
+		ORDER BY user_id, asset`
+
+	queryListTransactionsForDigest = `
+		SELECT id, user_id, asset, transaction_type, amount, balance_after, status
+		FROM transactions
+		WHERE ? = '' OR tenant_id = ?
+		ORDER BY id`
+
+	// Amounts are summed as decimals by the caller; SQL SUM would add them as floating point
+	// Every entry moved the balance when it was applied, whatever its status now; reversals are entries of
+	// their own
 	queryReconcileBalance = `
-		SELECT COALESCE(SUM(amount), 0) as calculated_balance
-		FROM transactions 
-		WHERE user_id = ? AND asset = ? AND status = 'confirmed'`
+		SELECT amount
+		FROM transactions
+		WHERE user_id = ? AND asset = ?`
 
 	// Transaction queries
 	queryCheckDuplicateTransaction = `
 		SELECT id FROM transactions WHERE external_transaction_id = ? LIMIT 1`
 
+	queryCheckDuplicateReversal = `
+		SELECT id FROM transactions WHERE reversal_of = ? LIMIT 1`
+
+	queryBackfillReversalLinks = `
+		UPDATE transactions
+		SET reversal_of = substr(external_transaction_id, 1, length(external_transaction_id) - length('-reversal'))
+		WHERE reversal_of = '' AND transaction_type = 'deposit' AND external_transaction_id LIKE '%-reversal'`
+
 	queryGetAccountBalance = `
-		SELECT id, balance, version 
+		SELECT id, balance, available_balance, version 
 		FROM account_balances 
 		WHERE user_id = ? AND asset = ?`
 
 	queryInsertAccountBalance = `
-		INSERT INTO account_balances (id, user_id, asset, balance, version)
-		VALUES (?, ?, ?, ?, ?)`
+		INSERT INTO account_balances (id, user_id, asset, balance, available_balance, version)
+		VALUES (?, ?, ?, ?, ?, ?)`
 
 	queryInsertTransaction = `
 		INSERT INTO transactions (
 			id, user_id, asset, transaction_type, amount, balance_before, balance_after,
-			external_transaction_id, address, reference, status, created_at, processed_at
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			external_transaction_id, address, reference, status, created_at, processed_at,
+			source_type, source_address, reversal_of
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		RETURNING id, user_id, asset, transaction_type, amount, balance_before, balance_after,
-		          external_transaction_id, address, reference, status, created_at, processed_at`
+		          external_transaction_id, address, reference, status, created_at, processed_at,
+		          source_type, source_address, reversal_of`
 
 	queryUpdateAccountBalance = `
 		UPDATE account_balances 
-		SET balance = ?, last_transaction_id = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		SET balance = ?, available_balance = ?, last_transaction_id = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
 		WHERE user_id = ? AND asset = ? AND version = ?`
 
+	queryAdjustAvailableBalance = `
+		UPDATE account_balances
+		SET available_balance = ?, version = version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND asset = ? AND version = ?`
+
+	queryListUnsplitBalances = `
+		SELECT user_id, asset, balance
+		FROM account_balances
+		WHERE available_balance IS NULL`
+
 	queryInsertJournalEntry = `
 		INSERT INTO journal_entries (id, transaction_id, account_type, account_id, debit_amount, credit_amount)
 		VALUES (?, ?, ?, ?, ?, ?)`
 
 	queryGetTransactionHistory = `
 		SELECT id, user_id, asset, transaction_type, amount, balance_before, balance_after,
-		       external_transaction_id, address, reference, status, created_at, processed_at
-		FROM transactions 
+		       external_transaction_id, address, reference, status, created_at, processed_at,
+		       source_type, source_address, reversal_of
+		FROM transactions
 		WHERE user_id = ? AND asset = ?
-		ORDER BY created_at DESC
-		LIMIT ? OFFSET ?`
+		AND (? = '' OR (created_at, id) < (SELECT created_at, id FROM transactions WHERE id = ?))
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`
 
 	queryGetMostRecentTransactionTime = `
 		SELECT MAX(created_at) 
 		FROM transactions 
 		WHERE external_transaction_id IS NOT NULL AND external_transaction_id != ''`
+
+	// Asset statistics queries
+	queryListAssetBalances = `
+		SELECT balance
+		FROM account_balances
+		WHERE asset = ? AND CAST(balance AS REAL) != 0`
+
+	queryGetAssetDepositStats = `
+		SELECT COUNT(*), MAX(created_at)
+		FROM transactions
+		WHERE asset = ? AND transaction_type = 'deposit'
+			AND reversal_of = ''`
+
+	// Ledger entries created from Prime transactions; queued withdrawals are keyed by their batch when batched
+	queryListLedgerSyncEntries = `
+		SELECT t.id, t.user_id, t.asset, t.transaction_type, t.amount, t.external_transaction_id,
+		       COALESCE(q.batch_id, ''), COALESCE(q.status, ''), t.created_at
+		FROM transactions t
+		LEFT JOIN withdrawal_queue q ON q.idempotency_key = t.external_transaction_id
+		WHERE t.asset = ?
+			AND t.transaction_type IN ('deposit', 'withdrawal', 'suspense_deposit')
+			AND t.external_transaction_id IS NOT NULL AND t.external_transaction_id != ''
+			AND t.reversal_of = ''
+			AND datetime(t.created_at) >= datetime(?) AND datetime(t.created_at) < datetime(?)
+		ORDER BY t.created_at`
+
+	// Withdrawal queue queries
+	queryEnqueueWithdrawal = `
+		INSERT INTO withdrawal_queue (
+			id, user_id, asset, asset_network, amount, destination_type, destination, wallet_id, idempotency_key,
+			correlation_id, status, attempts, next_attempt_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, 'queued', 0, ?)`
+
+	// The rest of a batch is claimed along with any of its withdrawals, so the batch is resubmitted whole
+	queryClaimQueuedWithdrawals = `
+		WITH due AS (
+			SELECT id, batch_id FROM withdrawal_queue
+			WHERE status = 'queued' AND next_attempt_at <= ?
+			ORDER BY created_at
+			LIMIT ?
+		)
+		UPDATE withdrawal_queue
+		SET status = 'processing', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'queued' AND (
+			id IN (SELECT id FROM due)
+			OR batch_id IN (SELECT batch_id FROM due WHERE batch_id IS NOT NULL)
+		)
+		RETURNING id, user_id, asset, asset_network, amount, destination_type, destination, wallet_id, idempotency_key,
+		          status, attempts, COALESCE(last_error, ''), COALESCE(activity_id, ''), next_attempt_at, created_at, updated_at,
+		          correlation_id, COALESCE(batch_id, '')`
+
+	queryMarkWithdrawalSubmitted = `
+		UPDATE withdrawal_queue
+		SET status = 'submitted', activity_id = ?, attempts = attempts + 1, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryScheduleWithdrawalRetry = `
+		UPDATE withdrawal_queue
+		SET status = 'queued', attempts = attempts + 1, last_error = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryMarkWithdrawalFailed = `
+		UPDATE withdrawal_queue
+		SET status = 'failed', attempts = attempts + 1, last_error = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryDeferQueuedWithdrawal = `
+		UPDATE withdrawal_queue
+		SET status = 'queued', last_error = ?, next_attempt_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryRequeueStaleWithdrawals = `
+		UPDATE withdrawal_queue
+		SET status = 'queued', updated_at = CURRENT_TIMESTAMP
+		WHERE status = 'processing'`
+
+	queryListQueuedWithdrawals = `
+		SELECT id, user_id, asset, asset_network, amount, destination_type, destination, wallet_id, idempotency_key,
+		       status, attempts, COALESCE(last_error, ''), COALESCE(activity_id, ''), next_attempt_at, created_at, updated_at,
+		       correlation_id, COALESCE(batch_id, '')
+		FROM withdrawal_queue
+		WHERE (? = '' OR status = ?)
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	queryListBatchWithdrawals = `
+		SELECT id, user_id, asset, asset_network, amount, destination_type, destination, wallet_id, idempotency_key,
+		       status, attempts, COALESCE(last_error, ''), COALESCE(activity_id, ''), next_attempt_at, created_at, updated_at,
+		       correlation_id, COALESCE(batch_id, '')
+		FROM withdrawal_queue
+		WHERE batch_id = ?
+		ORDER BY created_at`
+
+	queryAssignWithdrawalBatch = `
+		UPDATE withdrawal_queue
+		SET batch_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryMarkBatchWithdrawalsSubmitted = `
+		UPDATE withdrawal_queue
+		SET status = 'submitted', activity_id = ?, attempts = attempts + 1, last_error = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE batch_id = ?`
+
+	// Withdrawal batch queries
+	queryUpsertWithdrawalBatch = `
+		INSERT INTO withdrawal_batches (
+			id, asset, asset_network, wallet_id, destination_type, destination, total_amount, item_count, status
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'pending')
+		ON CONFLICT(id) DO UPDATE SET status = 'pending', updated_at = CURRENT_TIMESTAMP`
+
+	queryUpdateWithdrawalBatch = `
+		UPDATE withdrawal_batches
+		SET status = ?, activity_id = COALESCE(NULLIF(?, ''), activity_id), updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryGetWithdrawalBatch = `
+		SELECT id, asset, asset_network, wallet_id, destination_type, destination, total_amount, item_count,
+		       COALESCE(activity_id, ''), status, created_at, updated_at
+		FROM withdrawal_batches
+		WHERE id = ?`
+
+	queryListWithdrawalBatches = `
+		SELECT id, asset, asset_network, wallet_id, destination_type, destination, total_amount, item_count,
+		       COALESCE(activity_id, ''), status, created_at, updated_at
+		FROM withdrawal_batches
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	queryCountQueuedWithdrawalsByStatus = `
+		SELECT status, COUNT(*)
+		FROM withdrawal_queue
+		GROUP BY status
+		ORDER BY status`
+
+	queryListPendingWithdrawalAmounts = `
+		SELECT asset, amount
+		FROM withdrawal_queue
+		WHERE status IN ('queued', 'processing')`
+
+	// Reward queries
+	queryInsertRewardProgram = `
+		INSERT INTO reward_programs (name, asset, budget, granted) VALUES (?, ?, ?, '0')`
+
+	queryGetRewardProgram = `
+		SELECT name, asset, budget, granted, active, created_at
+		FROM reward_programs
+		WHERE name = ?`
+
+	queryListRewardPrograms = `
+		SELECT name, asset, budget, granted, active, created_at
+		FROM reward_programs
+		ORDER BY name`
+
+	queryUpdateRewardProgramGranted = `
+		UPDATE reward_programs SET granted = ? WHERE name = ? AND granted = ?`
+
+	queryInsertRewardGrant = `
+		INSERT INTO reward_grants (id, program, user_id, asset, amount, reference, transaction_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	queryListRewardGrants = `
+		SELECT id, program, user_id, asset, amount, reference, transaction_id, created_at
+		FROM reward_grants
+		WHERE program = ?
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	// Interest accrual queries
+	queryInsertInterestAccrual = `
+		INSERT INTO interest_accruals (id, user_id, asset, accrual_date, balance, annual_rate, amount, transaction_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(user_id, asset, accrual_date) DO NOTHING`
+
+	queryListInterestAccruals = `
+		SELECT id, user_id, asset, accrual_date, balance, annual_rate, amount, transaction_id, created_at
+		FROM interest_accruals
+		WHERE accrual_date >= ? AND accrual_date <= ?
+		ORDER BY user_id, asset, accrual_date`
+
+	// Negative balance queries
+	queryInsertNegativeBalanceEvent = `
+		INSERT INTO negative_balance_events (id, user_id, asset, transaction_id, transaction_type, balance_before, balance_after, policy, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	queryListNegativeBalances = `
+		SELECT id, user_id, asset, balance, available_balance, last_transaction_id, version, updated_at
+		FROM account_balances
+		WHERE CAST(balance AS REAL) < 0
+		ORDER BY user_id, asset`
+
+	// Raw Prime transaction queries
+	queryUpsertPrimeTransaction = `
+		INSERT INTO prime_transactions (id, wallet_id, idempotency_key, type, status, payload)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			status = excluded.status,
+			payload = excluded.payload,
+			updated_at = CURRENT_TIMESTAMP`
+
+	queryGetPrimeTransaction = `
+		SELECT id, wallet_id, idempotency_key, type, status, payload, first_seen_at, updated_at
+		FROM prime_transactions
+		WHERE id = ? OR idempotency_key = ?
+		ORDER BY updated_at DESC
+		LIMIT 1`
+
+	queryGetExternalTransactionId = `
+		SELECT external_transaction_id FROM transactions WHERE id = ?`
+
+	// Receipt queries
+	queryUpsertTransactionReceipt = `
+		INSERT INTO transaction_receipts (prime_transaction_id, idempotency_key, network, tx_hash, completed_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(prime_transaction_id) DO UPDATE SET tx_hash = excluded.tx_hash`
+
+	querySelectTransactionReceipts = `
+		SELECT prime_transaction_id, idempotency_key, network, tx_hash, completed_at
+		FROM transaction_receipts`
+
+	queryInsertWithdrawalReceipt = `
+		INSERT INTO withdrawal_receipts (activity_id, user_id, idempotency_key, prime_transaction_id, symbol, asset, amount, fee, destination_type, destination)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(activity_id) DO NOTHING`
+
+	queryGetWithdrawalReceipt = `
+		SELECT activity_id, user_id, idempotency_key, prime_transaction_id, symbol, asset, amount, fee, destination_type, destination, created_at
+		FROM withdrawal_receipts
+		WHERE activity_id = ?`
+
+	// Acknowledged Prime transaction queries
+	queryInsertPrimeTransactionAck = `
+		INSERT INTO acknowledged_prime_transactions (prime_transaction_id, reason)
+		VALUES (?, ?)
+		ON CONFLICT(prime_transaction_id) DO NOTHING`
+
+	queryGetPrimeTransactionAck = `
+		SELECT prime_transaction_id, reason, created_at
+		FROM acknowledged_prime_transactions
+		WHERE prime_transaction_id = ?`
+
+	// Dormancy queries
+	queryListAccountActivity = `
+		SELECT ab.user_id, ab.asset, ab.balance, ab.updated_at,
+		       (SELECT MAX(t.created_at) FROM transactions t WHERE t.user_id = ab.user_id AND t.asset = ab.asset)
+		FROM account_balances ab
+		WHERE ? = '' OR ab.tenant_id = ?
+		ORDER BY ab.user_id, ab.asset`
+
+	queryListNegativeBalanceEvents = `
+		SELECT id, user_id, asset, transaction_id, transaction_type, balance_before, balance_after, policy, created_at
+		FROM negative_balance_events
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	// Suspense queries
+	queryInsertSuspenseEntry = `
+		INSERT INTO suspense_entries (id, external_transaction_id, address, address_user_id, expected_asset, received_asset, amount, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'open')`
+
+	querySelectSuspenseEntries = `
+		SELECT id, external_transaction_id, address, address_user_id, expected_asset, received_asset, amount, status,
+		       resolved_user_id, resolution_reference, resolution_note, created_at, resolved_at
+		FROM suspense_entries`
+
+	queryResolveSuspenseEntry = `
+		UPDATE suspense_entries
+		SET status = ?, resolved_user_id = ?, resolution_reference = ?, resolution_note = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'open'`
+
+	// Unattributed deposit queries
+	queryInsertUnattributedDeposit = `
+		INSERT INTO unattributed_deposits (id, external_transaction_id, address, asset, network, amount,
+		                                   source_type, source_address, details, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'open')
+		ON CONFLICT(external_transaction_id) DO NOTHING`
+
+	querySelectUnattributedDeposits = `
+		SELECT id, external_transaction_id, address, asset, network, amount, source_type, source_address, details,
+		       status, claimed_user_id, resolution_note, created_at, resolved_at
+		FROM unattributed_deposits`
+
+	queryResolveUnattributedDeposit = `
+		UPDATE unattributed_deposits
+		SET status = ?, claimed_user_id = ?, resolution_note = ?, resolved_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'open'`
+
+	// Counterparty attribution queries
+	queryUpsertCounterpartyMapping = `
+		INSERT INTO counterparty_mappings (counterparty_id, user_id, note) VALUES (?, ?, ?)
+		ON CONFLICT(counterparty_id) DO UPDATE SET user_id = excluded.user_id, note = excluded.note`
+
+	queryFindCounterpartyMapping = `
+		SELECT user_id FROM counterparty_mappings WHERE counterparty_id = ?`
+
+	queryListCounterpartyMappings = `
+		SELECT counterparty_id, user_id, note, created_at
+		FROM counterparty_mappings
+		ORDER BY created_at DESC`
+
+	queryInsertDepositReference = `
+		INSERT INTO deposit_references (reference, user_id, asset, amount) VALUES (?, ?, ?, ?)`
+
+	queryFindOpenDepositReference = `
+		SELECT reference, user_id, asset, amount, status, matched_transaction_id, created_at
+		FROM deposit_references
+		WHERE reference = ? AND status = 'open'`
+
+	queryMatchDepositReference = `
+		UPDATE deposit_references SET status = 'matched', matched_transaction_id = ?
+		WHERE reference = ? AND status = 'open'`
+
+	queryListDepositReferences = `
+		SELECT reference, user_id, asset, amount, status, matched_transaction_id, created_at
+		FROM deposit_references
+		ORDER BY created_at DESC`
+
+	// Invoice address queries
+	queryInsertInvoiceAddress = `
+		INSERT INTO invoice_addresses (reference, user_id, address, asset, network, amount, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	queryFindInvoiceAddress = `
+		SELECT reference, user_id, address, asset, network, amount, expires_at, status, transaction_id, created_at
+		FROM invoice_addresses
+		WHERE LOWER(address) = LOWER(?)`
+
+	queryMarkInvoicePaid = `
+		UPDATE invoice_addresses SET status = ?, transaction_id = ?
+		WHERE reference = ? AND status = 'open'`
+
+	queryListInvoiceAddresses = `
+		SELECT reference, user_id, address, asset, network, amount, expires_at, status, transaction_id, created_at
+		FROM invoice_addresses
+		ORDER BY created_at DESC`
+
+	// Refund queries
+	queryGetDeposit = `
+		SELECT id, user_id, asset, transaction_type, amount, balance_before, balance_after,
+		       external_transaction_id, address, reference, status, created_at, processed_at,
+		       source_type, source_address, reversal_of
+		FROM transactions
+		WHERE (id = ? OR external_transaction_id = ?) AND transaction_type = 'deposit'
+		LIMIT 1`
+
+	queryFindReversal = `
+		SELECT id, user_id, asset, transaction_type, amount, balance_before, balance_after,
+		       external_transaction_id, address, reference, status, created_at, processed_at,
+		       source_type, source_address, reversal_of
+		FROM transactions
+		WHERE reversal_of = ?
+		LIMIT 1`
+
+	queryFindOpenRefund = `
+		SELECT id FROM refunds
+		WHERE deposit_transaction_id = ? AND status NOT IN ('rejected', 'failed')`
+
+	queryInsertRefund = `
+		INSERT INTO refunds (id, deposit_transaction_id, user_id, asset, amount, destination, reason, status, requested_by, idempotency_key)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	queryGetRefund = `
+		SELECT id, deposit_transaction_id, user_id, asset, amount, destination, reason, status,
+		       requested_by, approved_by, idempotency_key, last_error, created_at, updated_at
+		FROM refunds
+		WHERE id = ?`
+
+	queryListRefunds = `
+		SELECT id, deposit_transaction_id, user_id, asset, amount, destination, reason, status,
+		       requested_by, approved_by, idempotency_key, last_error, created_at, updated_at
+		FROM refunds
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	queryDecideRefund = `
+		UPDATE refunds SET status = ?, approved_by = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'pending_approval'`
+
+	queryUpdateRefundStatus = `
+		UPDATE refunds SET status = ?, last_error = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	// Treasury top-up queries
+	queryInsertTreasuryTopUp = `
+		INSERT INTO treasury_top_ups (id, asset, vault_wallet_id, wallet_id, amount, activity_id, status)
+		VALUES (?, ?, ?, ?, ?, ?, 'requested')`
+
+	queryGetOpenTreasuryTopUp = `
+		SELECT id, asset, vault_wallet_id, wallet_id, amount, activity_id, status, created_at, updated_at
+		FROM treasury_top_ups
+		WHERE wallet_id = ? AND status = 'requested'
+		ORDER BY created_at DESC
+		LIMIT 1`
+
+	queryUpdateTreasuryTopUpStatus = `
+		UPDATE treasury_top_ups
+		SET status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?`
+
+	queryListTreasuryTopUps = `
+		SELECT id, asset, vault_wallet_id, wallet_id, amount, activity_id, status, created_at, updated_at
+		FROM treasury_top_ups
+		ORDER BY created_at DESC
+		LIMIT ?`
+
+	// Command checkpoint queries
+	queryUpsertCommandCheckpoint = `
+		INSERT INTO command_checkpoints (command, user_id, asset, wallet_id, processed, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(command) DO UPDATE SET
+			user_id = excluded.user_id,
+			asset = excluded.asset,
+			wallet_id = excluded.wallet_id,
+			processed = excluded.processed,
+			updated_at = CURRENT_TIMESTAMP`
+
+	queryGetCommandCheckpoint = `
+		SELECT command, user_id, asset, wallet_id, processed, updated_at
+		FROM command_checkpoints
+		WHERE command = ?`
+
+	queryDeleteCommandCheckpoint = `
+		DELETE FROM command_checkpoints
+		WHERE command = ?`
+
+	queryUpsertListenerCheckpoint = `
+		INSERT INTO listener_checkpoints (wallet_id, polled_through, last_seen_tx_time, last_seen_tx_id, oldest_open_tx_time)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(wallet_id) DO UPDATE SET
+			polled_through = excluded.polled_through,
+			last_seen_tx_time = excluded.last_seen_tx_time,
+			last_seen_tx_id = excluded.last_seen_tx_id,
+			oldest_open_tx_time = excluded.oldest_open_tx_time`
+
+	queryListListenerCheckpoints = `
+		SELECT wallet_id, polled_through, last_seen_tx_time, last_seen_tx_id, oldest_open_tx_time
+		FROM listener_checkpoints`
+
+	// Processed transaction queries
+	queryProcessedTransactionExists = `
+		SELECT EXISTS(SELECT 1 FROM processed_transactions WHERE transaction_id = ?)`
+
+	queryMarkTransactionProcessed = `
+		INSERT INTO processed_transactions (transaction_id)
+		VALUES (?)
+		ON CONFLICT(transaction_id) DO NOTHING`
+
+	// Deposit hold queries
+	queryInsertDepositHold = `
+		INSERT INTO deposit_holds (id, transaction_id, external_transaction_id, user_id, asset, amount, kind, reason, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, 'held', ?)`
+
+	querySelectDepositHolds = `
+		SELECT id, transaction_id, external_transaction_id, user_id, asset, amount, kind, reason, status, release_note, created_at, released_at
+		FROM deposit_holds`
+
+	queryFindSettlementHold = `
+		SELECT id FROM deposit_holds
+		WHERE external_transaction_id = ? AND kind = 'settlement' AND status = 'held'`
+
+	queryHeldAmounts = `
+		SELECT amount FROM deposit_holds
+		WHERE user_id = ? AND asset = ? AND status = 'held'`
+
+	queryReleaseDepositHold = `
+		UPDATE deposit_holds
+		SET status = 'released', release_note = ?, released_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'held'`
+
+	queryUpdateUnsplitBalance = `
+		UPDATE account_balances
+		SET available_balance = ?
+		WHERE user_id = ? AND asset = ? AND available_balance IS NULL`
+
+	// Withdrawal hold queries
+	queryInsertWithdrawalHold = `
+		INSERT INTO withdrawal_holds (id, idempotency_key, user_id, asset, amount, destination, reference, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'held', ?)`
+
+	querySelectWithdrawalHold = `
+		SELECT id, idempotency_key, user_id, asset, amount, destination, reference, status, transaction_id, note, created_at, resolved_at
+		FROM withdrawal_holds
+		WHERE idempotency_key = ?`
+
+	queryResolveWithdrawalHold = `
+		UPDATE withdrawal_holds
+		SET status = ?, transaction_id = ?, note = ?, resolved_at = ?
+		WHERE idempotency_key = ? AND status = 'held'`
+
+	queryListHeldWithdrawalAmounts = `
+		SELECT h.asset, h.amount
+		FROM withdrawal_holds h
+		JOIN users u ON u.id = h.user_id
+		WHERE h.status = 'held' AND (? = '' OR u.tenant_id = ?)`
+
+	queryListUnsentWithdrawals = `
+		SELECT h.idempotency_key, h.user_id, h.asset, h.amount, h.reference, h.destination, r.wallet_id,
+			COALESCE(q.id, ''), COALESCE(q.batch_id, ''), h.created_at
+		FROM withdrawal_holds h
+		JOIN withdrawal_requests r ON r.idempotency_key = h.idempotency_key
+		JOIN users u ON u.id = h.user_id
+		LEFT JOIN withdrawal_queue q ON q.idempotency_key = h.idempotency_key
+		WHERE h.status = 'held' AND r.status = 'created' AND (q.id IS NULL OR q.status = 'failed')
+			AND (? = '' OR h.idempotency_key = ?) AND (? = '' OR u.tenant_id = ?)
+		ORDER BY h.created_at`
+
+	queryListHeldWithdrawalsSince = `
+		SELECT amount, created_at
+		FROM withdrawal_holds
+		WHERE user_id = ? AND asset = ? AND status = 'held' AND created_at >= ?`
+
+	// API token queries
+	queryInsertApiToken = `
+		INSERT INTO api_tokens (id, user_id, token_hash, prefix, label)
+		VALUES (?, ?, ?, ?, ?)`
+
+	querySelectApiTokens = `
+		SELECT id, user_id, prefix, label, created_at, last_used_at, revoked_at
+		FROM api_tokens`
+
+	queryAuthenticateApiToken = `
+		SELECT t.id, t.user_id, t.prefix, t.label, t.created_at, t.last_used_at, t.revoked_at
+		FROM api_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ? AND t.revoked_at IS NULL AND u.active = 1`
+
+	queryTouchApiToken = `
+		UPDATE api_tokens SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?`
+
+	queryRevokeApiToken = `
+		UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL`
+
+	// API idempotency queries
+	queryReserveIdempotencyKey = `
+		INSERT OR IGNORE INTO api_idempotency_keys (scope, idempotency_key, request_hash, status)
+		VALUES (?, ?, ?, 'pending')`
+
+	queryGetIdempotencyKey = `
+		SELECT request_hash, status, status_code, content_type, response_body
+		FROM api_idempotency_keys
+		WHERE scope = ? AND idempotency_key = ?`
+
+	// Only one retry wins a stale key: taking it over restarts its clock
+	queryTakeOverIdempotencyKey = `
+		UPDATE api_idempotency_keys
+		SET created_at = CURRENT_TIMESTAMP
+		WHERE scope = ? AND idempotency_key = ? AND request_hash = ? AND status = 'pending'
+		  AND created_at < datetime('now', ?)`
+
+	queryCompleteIdempotencyKey = `
+		UPDATE api_idempotency_keys
+		SET status = 'complete', status_code = ?, content_type = ?, response_body = ?, completed_at = CURRENT_TIMESTAMP
+		WHERE scope = ? AND idempotency_key = ? AND status = 'pending'`
+
+	queryReleaseIdempotencyKey = `
+		DELETE FROM api_idempotency_keys WHERE scope = ? AND idempotency_key = ? AND status = 'pending'`
+
+	// Tenant queries
+	queryInsertTenant = `
+		INSERT INTO tenants (id, name, portfolio_id) VALUES (?, ?, ?)`
+
+	querySelectTenants = `
+		SELECT id, name, portfolio_id, created_at FROM tenants`
+
+	queryAssignUserTenant = `
+		UPDATE users SET tenant_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+
+	// Address label queries
+	querySetAddressLabel = `
+		UPDATE addresses SET label = ? WHERE LOWER(address) = LOWER(?)`
+
+	// Activity digest queries
+	queryListActivitySince = `
+		SELECT transaction_type, asset, amount, reversal_of, created_at
+		FROM transactions
+		WHERE created_at >= ? AND (? = '' OR tenant_id = ?)`
+
+	// Reversed withdrawals never left, so they are not counted against their destination
+	queryListWithdrawalDestinationsSince = `
+		SELECT t.address, t.asset, t.user_id, COALESCE(u.email, ''), t.amount, t.created_at
+		FROM transactions t
+		LEFT JOIN users u ON u.id = t.user_id
+		WHERE t.transaction_type = 'withdrawal' AND t.address != '' AND t.created_at >= ?
+		AND (? = '' OR t.tenant_id = ?)
+		AND NOT EXISTS (
+			SELECT 1 FROM transactions r
+			WHERE r.reversal_of = t.external_transaction_id
+		)`
+
+	queryListReleasedWithdrawalsSince = `
+		SELECT h.asset, h.amount, h.resolved_at
+		FROM withdrawal_holds h
+		JOIN users u ON u.id = h.user_id
+		WHERE h.status = 'released' AND h.resolved_at >= ? AND (? = '' OR u.tenant_id = ?)`
+
+	// The wallet comes from the stored Prime transaction (by id or withdrawal idempotency key), falling
+	// back to the wallet of the deposit address
+	queryListWalletActivitySince = `
+		SELECT t.asset, t.amount, t.created_at, COALESCE(
+			(SELECT p.wallet_id FROM prime_transactions p WHERE p.id = t.external_transaction_id AND p.wallet_id != ''),
+			(SELECT p.wallet_id FROM prime_transactions p WHERE p.idempotency_key = t.external_transaction_id AND p.wallet_id != '' LIMIT 1),
+			(SELECT a.wallet_id FROM addresses a WHERE a.address = t.address AND a.asset = t.asset LIMIT 1),
+			'')
+		FROM transactions t
+		WHERE t.created_at >= ? AND (? = '' OR t.tenant_id = ?)`
+
+	// Withdrawal limit queries; rolled back withdrawals have a reversal linked to them
+	queryListWithdrawalsSince = `
+		SELECT w.amount, w.created_at
+		FROM transactions w
+		WHERE w.user_id = ? AND w.asset = ? AND w.transaction_type = 'withdrawal' AND w.created_at >= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM transactions r
+			WHERE r.reversal_of = w.external_transaction_id
+		)`
+
+	// Ledger invariant queries
+	queryListLedgerEntriesAfter = `
+		SELECT rowid, id, asset, transaction_type, amount, balance_before, balance_after
+		FROM transactions
+		WHERE rowid > ? AND (? = '' OR tenant_id = ?)
+		ORDER BY rowid
+		LIMIT ?`
+
+	queryListJournalPostings = `
+		SELECT transaction_id, account_type, debit_amount, credit_amount
+		FROM journal_entries
+		WHERE transaction_id IN (SELECT value FROM json_each(?))`
+
+	// Operation lock queries
+	queryAcquireOperationLock = `
+		INSERT INTO operation_locks (name, owner, operation, pid, hostname, acquired_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			owner = excluded.owner,
+			operation = excluded.operation,
+			pid = excluded.pid,
+			hostname = excluded.hostname,
+			acquired_at = excluded.acquired_at,
+			expires_at = excluded.expires_at
+		WHERE operation_locks.expires_at < excluded.acquired_at`
+
+	queryGetOperationLock = `
+		SELECT name, owner, operation, pid, hostname, acquired_at, expires_at
+		FROM operation_locks
+		WHERE name = ?`
+
+	queryRefreshOperationLock = `
+		UPDATE operation_locks SET expires_at = ? WHERE name = ? AND owner = ?`
+
+	queryReleaseOperationLock = `
+		DELETE FROM operation_locks WHERE name = ? AND owner = ?`
+
+	// Transaction observation queries
+	queryUpsertTransactionObservation = `
+		INSERT INTO transaction_observations (prime_transaction_id, wallet_id, type, status, symbol, network, amount,
+			idempotency_key, reason, detail)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(prime_transaction_id) DO UPDATE SET
+			type = excluded.type,
+			status = excluded.status,
+			amount = excluded.amount,
+			reason = excluded.reason,
+			detail = excluded.detail,
+			seen_count = transaction_observations.seen_count + 1,
+			last_seen_at = CURRENT_TIMESTAMP`
+
+	querySelectTransactionObservations = `
+		SELECT o.prime_transaction_id, o.wallet_id, o.type, o.status, o.symbol, o.network, o.amount, o.idempotency_key,
+		       o.reason, o.detail, o.seen_count, o.first_seen_at, o.last_seen_at,
+		       EXISTS (SELECT 1 FROM transactions t WHERE t.external_transaction_id = o.prime_transaction_id
+		               OR (o.idempotency_key != '' AND t.external_transaction_id = o.idempotency_key))
+		FROM transaction_observations o`
+
+	// Review case queries
+	queryInsertReviewCase = `
+		INSERT OR IGNORE INTO review_cases (id, kind, user_id, asset, case_key, summary, details, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 'open', ?)`
+
+	querySelectReviewCases = `
+		SELECT id, kind, user_id, asset, case_key, summary, details, status, resolution_note, created_at, resolved_at
+		FROM review_cases`
+
+	queryLatestClosedReviewCase = `
+		SELECT resolved_at FROM review_cases
+		WHERE case_key = ? AND status = 'closed'
+		ORDER BY resolved_at DESC LIMIT 1`
+
+	queryCloseReviewCase = `
+		UPDATE review_cases SET status = 'closed', resolution_note = ?, resolved_at = ?
+		WHERE id = ? AND status = 'open'`
+
+	// Deposits and withdrawals for velocity checks. Rolled back withdrawals and the credits reversing
+	// them are left out; withdrawals still held count as soon as they are requested.
+	queryListVelocityEntriesSince = `
+		SELECT t.user_id, t.asset, t.transaction_type, t.amount, t.created_at
+		FROM transactions t
+		WHERE t.transaction_type IN ('deposit', 'withdrawal') AND t.reversal_of = '' AND t.created_at >= ?
+		AND (? = '' OR t.tenant_id = ?)
+		AND NOT EXISTS (
+			SELECT 1 FROM transactions r
+			WHERE r.reversal_of = t.external_transaction_id
+		)
+		UNION ALL
+		SELECT h.user_id, h.asset, 'withdrawal', h.amount, h.created_at
+		FROM withdrawal_holds h
+		JOIN users u ON u.id = h.user_id
+		WHERE h.status = 'held' AND h.created_at >= ? AND (? = '' OR u.tenant_id = ?)`
+
+	// Withdrawal request queries
+	queryCountTable = `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+
+	queryInsertWithdrawalRequest = `
+		INSERT INTO withdrawal_requests (idempotency_key, user_id, asset, amount, wallet_id, destination, status,
+		                                 created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 'created', ?, ?)`
+
+	querySelectWithdrawalRequests = `
+		SELECT idempotency_key, user_id, asset, amount, wallet_id, destination, status, prime_status, activity_id,
+		       prime_transaction_id, created_at, updated_at
+		FROM withdrawal_requests`
+
+	querySelectWithdrawalRequestStatus = `
+		SELECT status, prime_status FROM withdrawal_requests WHERE idempotency_key = ?`
+
+	// Empty prime status, transaction and activity ids leave the stored values; a request already linked
+	// to a Prime transaction keeps its first one
+	queryAdvanceWithdrawalRequest = `
+		UPDATE withdrawal_requests SET
+			status = ?,
+			prime_status = CASE WHEN ? = '' THEN prime_status ELSE ? END,
+			prime_transaction_id = CASE WHEN prime_transaction_id = '' THEN ? ELSE prime_transaction_id END,
+			activity_id = CASE WHEN ? = '' THEN activity_id ELSE ? END,
+			updated_at = ?
+		WHERE idempotency_key = ? AND status = ?`
+
+	queryInsertWithdrawalRequestEvent = `
+		INSERT INTO withdrawal_request_events (idempotency_key, from_status, to_status, prime_status, note, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`
+
+	queryListWithdrawalRequestEvents = `
+		SELECT from_status, to_status, prime_status, note, created_at
+		FROM withdrawal_request_events
+		WHERE idempotency_key = ?
+		ORDER BY id`
+
+	queryRenameReservedWithdrawalRequests = `
+		UPDATE withdrawal_requests SET status = 'created' WHERE status = 'reserved'`
+
+	queryBackfillRequestsFromHolds = `
+		INSERT OR IGNORE INTO withdrawal_requests (idempotency_key, user_id, asset, amount, destination, status,
+		                                           created_at, updated_at)
+		SELECT idempotency_key, user_id, asset, amount, destination,
+		       CASE status WHEN 'held' THEN 'submitted' WHEN 'captured' THEN 'completed' ELSE 'failed' END,
+		       created_at, COALESCE(resolved_at, created_at)
+		FROM withdrawal_holds`
+
+	queryBackfillRequestsFromQueue = `
+		UPDATE withdrawal_requests SET
+			wallet_id = (SELECT q.wallet_id FROM withdrawal_queue q WHERE q.idempotency_key = withdrawal_requests.idempotency_key),
+			activity_id = (SELECT COALESCE(q.activity_id, '') FROM withdrawal_queue q WHERE q.idempotency_key = withdrawal_requests.idempotency_key),
+			status = CASE WHEN status = 'submitted' AND (SELECT q.status FROM withdrawal_queue q WHERE q.idempotency_key = withdrawal_requests.idempotency_key) IN ('queued', 'processing')
+			         THEN 'created' ELSE status END
+		WHERE EXISTS (SELECT 1 FROM withdrawal_queue q WHERE q.idempotency_key = withdrawal_requests.idempotency_key)`
+
+	// Withdrawals debited before holds were introduced; amounts are stored negative
+	queryBackfillRequestsFromLedger = `
+		INSERT OR IGNORE INTO withdrawal_requests (idempotency_key, user_id, asset, amount, destination, status,
+		                                           created_at, updated_at)
+		SELECT t.external_transaction_id, t.user_id, t.asset, LTRIM(t.amount, '-'), t.address,
+		       CASE WHEN EXISTS (SELECT 1 FROM transactions r WHERE r.reversal_of = t.external_transaction_id)
+		            THEN 'failed' ELSE 'submitted' END,
+		       t.created_at, t.created_at
+		FROM transactions t
+		WHERE t.transaction_type = 'withdrawal' AND t.reversal_of = ''`
+
+	// Transactions in insertion order, so each account's entries are in the order they were applied
+	queryListTransactionsBetween = `
+		SELECT id, user_id, asset, transaction_type, amount, balance_before, balance_after,
+		       external_transaction_id, address, reference, status, created_at, processed_at,
+		       source_type, source_address, reversal_of
+		FROM transactions
+		WHERE created_at >= ? AND created_at <= ? AND (? = '' OR tenant_id = ?)
+		AND (? = '' OR user_id = ?) AND (? = '' OR asset = ?)
+		ORDER BY rowid`
+
+	// Transaction status queries
+	querySelectTransactionStatus = `SELECT status FROM transactions WHERE id = ?`
+
+	queryUpdateTransactionStatus = `
+		UPDATE transactions SET status = ? WHERE id = ? AND status = ?`
+
+	queryInsertTransactionStatusEvent = `
+		INSERT INTO transaction_status_events (transaction_id, from_status, to_status, note, created_at)
+		VALUES (?, ?, ?, ?, ?)`
+
+	queryListTransactionStatusEvents = `
+		SELECT transaction_id, from_status, to_status, note, created_at
+		FROM transaction_status_events
+		WHERE transaction_id = ?
+		ORDER BY id`
+
+	queryFindReversedTransaction = `
+		SELECT id FROM transactions WHERE external_transaction_id = ? AND reversal_of = '' LIMIT 1`
+
+	queryListTransactionsByStatus = `
+		SELECT id, user_id, asset, transaction_type, amount, balance_before, balance_after,
+		       external_transaction_id, address, reference, status, created_at, processed_at,
+		       source_type, source_address, reversal_of
+		FROM transactions
+		WHERE (? = '' OR status = ?) AND (? = '' OR user_id = ?) AND (? = '' OR asset = ?)
+		AND (? = '' OR tenant_id = ?)
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?`
+
+	queryBackfillPendingTransactions = `
+		UPDATE transactions SET status = 'pending'
+		WHERE status = 'confirmed' AND id IN (SELECT transaction_id FROM deposit_holds WHERE status = 'held')`
+
+	// Withdrawal debits undone by ReverseWithdrawal failed in Prime; other reversed entries are reversed
+	queryBackfillFailedTransactions = `
+		UPDATE transactions SET status = 'failed'
+		WHERE status IN ('pending', 'confirmed') AND reversal_of = '' AND transaction_type = 'withdrawal'
+		AND external_transaction_id IN (SELECT reversal_of FROM transactions WHERE reversal_of != '')`
+
+	queryBackfillReversedTransactions = `
+		UPDATE transactions SET status = 'reversed'
+		WHERE status IN ('pending', 'confirmed') AND reversal_of = ''
+		AND external_transaction_id IN (SELECT reversal_of FROM transactions WHERE reversal_of != '')`
+
+	// Transaction link queries, completed by GetTransactionLinks with a filter on o.id and t.id. o is the
+	// original entry and t the entry linked to it.
+	queryReversalLinks = `
+		SELECT o.id, o.external_transaction_id, o.amount, o.created_at,
+		       t.id, t.external_transaction_id, t.amount, t.created_at
+		FROM transactions t
+		JOIN transactions o ON o.external_transaction_id = t.reversal_of AND o.reversal_of = '' AND o.user_id = t.user_id
+		WHERE t.reversal_of != ''`
+
+	// A withdrawal rolled back on the ledger that Prime completed anyway is debited again under the Prime
+	// transaction id the withdrawal request was linked to
+	queryRedebitLinks = `
+		SELECT o.id, o.external_transaction_id, o.amount, o.created_at,
+		       t.id, t.external_transaction_id, t.amount, t.created_at
+		FROM withdrawal_requests w
+		JOIN transactions o ON o.external_transaction_id = w.idempotency_key AND o.reversal_of = '' AND o.user_id = w.user_id
+		JOIN transactions t ON t.external_transaction_id = w.prime_transaction_id AND t.reversal_of = '' AND t.user_id = w.user_id
+		WHERE w.prime_transaction_id != '' AND w.prime_transaction_id != w.idempotency_key
+		AND t.transaction_type = 'withdrawal'`
+
+	// Refunds are debited under their idempotency key
+	queryRefundLinks = `
+		SELECT o.id, o.external_transaction_id, o.amount, o.created_at,
+		       t.id, t.external_transaction_id, t.amount, t.created_at
+		FROM refunds f
+		JOIN transactions o ON o.id = f.deposit_transaction_id
+		JOIN transactions t ON t.external_transaction_id = f.idempotency_key AND t.reversal_of = '' AND t.user_id = f.user_id
+		WHERE t.transaction_type = 'withdrawal'`
 )
@@ -0,0 +1,155 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+func (s *Service) initReceiptSchema() error {
+	schema := `
+	-- On-chain transaction hashes of completed Prime deposits and withdrawals
+	CREATE TABLE IF NOT EXISTS transaction_receipts (
+		prime_transaction_id TEXT PRIMARY KEY,
+		idempotency_key TEXT NOT NULL DEFAULT '',
+		network TEXT NOT NULL DEFAULT '',
+		tx_hash TEXT NOT NULL,
+		completed_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transaction_receipts_idempotency_key ON transaction_receipts(idempotency_key);
+
+	-- Prime's response to each submitted withdrawal, keyed by activity id
+	CREATE TABLE IF NOT EXISTS withdrawal_receipts (
+		activity_id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL DEFAULT '',
+		idempotency_key TEXT NOT NULL DEFAULT '',
+		prime_transaction_id TEXT NOT NULL DEFAULT '',
+		symbol TEXT NOT NULL DEFAULT '',
+		asset TEXT NOT NULL DEFAULT '',
+		amount TEXT NOT NULL DEFAULT '',
+		fee TEXT NOT NULL DEFAULT '',
+		destination_type TEXT NOT NULL DEFAULT '',
+		destination TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// SaveTransactionReceipt records the on-chain hash of a completed Prime transaction. Saving the
+// same transaction again updates the hash.
+func (s *Service) SaveTransactionReceipt(ctx context.Context, receipt models.TransactionReceipt) error {
+	_, err := s.db.ExecContext(ctx, queryUpsertTransactionReceipt,
+		receipt.PrimeTransactionId, receipt.IdempotencyKey, receipt.Network, receipt.TxHash, receipt.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("unable to save receipt for %s: %w", receipt.PrimeTransactionId, err)
+	}
+	return nil
+}
+
+// GetTransactionReceipts returns the receipts for ledger transactions, keyed by the external transaction
+// id passed in. A ledger transaction matches a receipt by Prime transaction id (deposits) or by the
+// idempotency key the withdrawal was submitted with.
+func (s *Service) GetTransactionReceipts(ctx context.Context, externalTxIds []string) (map[string]models.TransactionReceipt, error) {
+	receipts := make(map[string]models.TransactionReceipt)
+	if len(externalTxIds) == 0 {
+		return receipts, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(externalTxIds)), ",")
+	query := querySelectTransactionReceipts +
+		" WHERE prime_transaction_id IN (" + placeholders + ") OR idempotency_key IN (" + placeholders + ")"
+	args := make([]interface{}, 0, 2*len(externalTxIds))
+	for _, id := range externalTxIds {
+		args = append(args, id)
+	}
+	for _, id := range externalTxIds {
+		args = append(args, id)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query receipts: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	wanted := make(map[string]bool, len(externalTxIds))
+	for _, id := range externalTxIds {
+		wanted[id] = true
+	}
+
+	for rows.Next() {
+		var receipt models.TransactionReceipt
+		var completedAt sql.NullTime
+		if err := rows.Scan(&receipt.PrimeTransactionId, &receipt.IdempotencyKey, &receipt.Network, &receipt.TxHash, &completedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan receipt: %w", err)
+		}
+		receipt.CompletedAt = completedAt.Time
+
+		if wanted[receipt.PrimeTransactionId] {
+			receipts[receipt.PrimeTransactionId] = receipt
+		}
+		if receipt.IdempotencyKey != "" && wanted[receipt.IdempotencyKey] {
+			receipts[receipt.IdempotencyKey] = receipt
+		}
+	}
+
+	return receipts, rows.Err()
+}
+
+// SaveWithdrawalReceipt records Prime's response to a submitted withdrawal. A receipt is written once;
+// saving the same activity again keeps the original.
+func (s *Service) SaveWithdrawalReceipt(ctx context.Context, receipt models.WithdrawalReceipt) error {
+	_, err := s.db.ExecContext(ctx, queryInsertWithdrawalReceipt,
+		receipt.ActivityId, receipt.UserId, receipt.IdempotencyKey, receipt.PrimeTransactionId, receipt.Symbol,
+		receipt.Asset, receipt.Amount, receipt.Fee, receipt.DestinationType, receipt.Destination)
+	if err != nil {
+		return fmt.Errorf("unable to save withdrawal receipt for %s: %w", receipt.ActivityId, err)
+	}
+	return nil
+}
+
+// GetWithdrawalReceipt returns the receipt of the withdrawal Prime accepted as activityId
+func (s *Service) GetWithdrawalReceipt(ctx context.Context, activityId string) (*models.WithdrawalReceipt, error) {
+	var receipt models.WithdrawalReceipt
+	err := s.db.QueryRowContext(ctx, queryGetWithdrawalReceipt, activityId).Scan(
+		&receipt.ActivityId, &receipt.UserId, &receipt.IdempotencyKey, &receipt.PrimeTransactionId, &receipt.Symbol,
+		&receipt.Asset, &receipt.Amount, &receipt.Fee, &receipt.DestinationType, &receipt.Destination, &receipt.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReceiptNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get withdrawal receipt for %s: %w", activityId, err)
+	}
+	return &receipt, nil
+}
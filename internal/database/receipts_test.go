@@ -0,0 +1,115 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestTransactionReceipts(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initReceiptSchema(); err != nil {
+		t.Fatalf("Failed to create receipt schema: %v", err)
+	}
+
+	completedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	receipts := []models.TransactionReceipt{
+		{PrimeTransactionId: "prime-deposit", Network: "base-mainnet", TxHash: "0xdeposit", CompletedAt: completedAt},
+		{PrimeTransactionId: "prime-withdrawal", IdempotencyKey: "user1-withdrawal", Network: "ethereum-mainnet", TxHash: "0xold", CompletedAt: completedAt},
+	}
+	for _, receipt := range receipts {
+		if err := service.SaveTransactionReceipt(ctx, receipt); err != nil {
+			t.Fatalf("SaveTransactionReceipt failed: %v", err)
+		}
+	}
+
+	// Saving again replaces the hash
+	receipts[1].TxHash = "0xwithdrawal"
+	if err := service.SaveTransactionReceipt(ctx, receipts[1]); err != nil {
+		t.Fatalf("SaveTransactionReceipt failed: %v", err)
+	}
+
+	found, err := service.GetTransactionReceipts(ctx, []string{"prime-deposit", "user1-withdrawal", "unknown"})
+	if err != nil {
+		t.Fatalf("GetTransactionReceipts failed: %v", err)
+	}
+	if len(found) != 2 {
+		t.Fatalf("Expected 2 receipts, got %+v", found)
+	}
+	if found["prime-deposit"].TxHash != "0xdeposit" || found["prime-deposit"].Network != "base-mainnet" {
+		t.Errorf("Unexpected deposit receipt: %+v", found["prime-deposit"])
+	}
+	if found["user1-withdrawal"].TxHash != "0xwithdrawal" || !found["user1-withdrawal"].CompletedAt.Equal(completedAt) {
+		t.Errorf("Expected withdrawal receipt matched by idempotency key, got %+v", found["user1-withdrawal"])
+	}
+}
+
+func TestWithdrawalReceipts(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if err := service.initReceiptSchema(); err != nil {
+		t.Fatalf("Failed to create receipt schema: %v", err)
+	}
+
+	receipt := models.WithdrawalReceipt{
+		ActivityId:         "activity-1",
+		UserId:             "user1",
+		IdempotencyKey:     "user1-withdrawal",
+		PrimeTransactionId: "prime-withdrawal",
+		Symbol:             "ETH",
+		Asset:              "ETH-ethereum-mainnet",
+		Amount:             "1.5",
+		Fee:                "0.0004",
+		DestinationType:    "address",
+		Destination:        "0xdestination",
+	}
+	if err := service.SaveWithdrawalReceipt(ctx, receipt); err != nil {
+		t.Fatalf("SaveWithdrawalReceipt failed: %v", err)
+	}
+
+	// The first receipt for an activity is kept
+	replay := receipt
+	replay.Fee = "0.9"
+	if err := service.SaveWithdrawalReceipt(ctx, replay); err != nil {
+		t.Fatalf("SaveWithdrawalReceipt failed: %v", err)
+	}
+
+	found, err := service.GetWithdrawalReceipt(ctx, "activity-1")
+	if err != nil {
+		t.Fatalf("GetWithdrawalReceipt failed: %v", err)
+	}
+	if found.Fee != "0.0004" || found.PrimeTransactionId != "prime-withdrawal" || found.UserId != "user1" {
+		t.Errorf("Unexpected withdrawal receipt: %+v", found)
+	}
+	if found.CreatedAt.IsZero() {
+		t.Error("Expected created_at to be set")
+	}
+
+	if _, err := service.GetWithdrawalReceipt(ctx, "unknown"); !errors.Is(err, ErrReceiptNotFound) {
+		t.Errorf("Expected ErrReceiptNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Refund statuses
+const (
+	RefundStatusPendingApproval = "pending_approval"
+	RefundStatusApproved        = "approved"
+	RefundStatusRejected        = "rejected"
+	RefundStatusSubmitted       = "submitted"
+	RefundStatusFailed          = "failed"
+)
+
+// CreateRefundParams describes a refund of a deposit to its source address
+type CreateRefundParams struct {
+	DepositTransactionId string
+	UserId               string
+	// Asset is the symbol and network the withdrawal is made in, e.g. ETH-ethereum-mainnet
+	Asset          string
+	Amount         decimal.Decimal
+	Destination    string
+	Reason         string
+	RequestedBy    string
+	IdempotencyKey string
+	// NeedsApproval starts the refund in pending_approval instead of approved
+	NeedsApproval bool
+}
+
+func (s *Service) initRefundSchema() error {
+	schema := `
+	-- Deposits returned to the address they were sent from
+	CREATE TABLE IF NOT EXISTS refunds (
+		id TEXT PRIMARY KEY,
+		deposit_transaction_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		reason TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL,
+		requested_by TEXT NOT NULL DEFAULT '',
+		approved_by TEXT NOT NULL DEFAULT '',
+		idempotency_key TEXT NOT NULL UNIQUE,
+		last_error TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- A deposit is refunded at most once; rejected and failed refunds may be requested again
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_open_deposit ON refunds(deposit_transaction_id)
+		WHERE status NOT IN ('rejected', 'failed');
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// GetDeposit finds a deposit by its ledger transaction id or its Prime transaction id
+func (s *Service) GetDeposit(ctx context.Context, id string) (*models.Transaction, error) {
+	deposit, err := scanTransaction(s.db.QueryRowContext(ctx, queryGetDeposit, id, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrDepositNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get deposit: %w", err)
+	}
+	return deposit, nil
+}
+
+// CreateRefund records a refund request. Fails with ErrRefundExists while the deposit has a refund
+// that was not rejected or failed.
+func (s *Service) CreateRefund(ctx context.Context, params CreateRefundParams) (*models.Refund, error) {
+	var existingId string
+	err := s.db.QueryRowContext(ctx, queryFindOpenRefund, params.DepositTransactionId).Scan(&existingId)
+	if err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRefundExists, existingId)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unable to check existing refunds: %w", err)
+	}
+
+	status := RefundStatusApproved
+	if params.NeedsApproval {
+		status = RefundStatusPendingApproval
+	}
+
+	refundId := uuid.New().String()
+	_, err = s.db.ExecContext(ctx, queryInsertRefund, refundId, params.DepositTransactionId, params.UserId, params.Asset,
+		params.Amount.String(), params.Destination, params.Reason, status, params.RequestedBy, params.IdempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create refund: %w", err)
+	}
+
+	correlation.Logger(ctx, s.logger).Info("Refund requested",
+		zap.String("refund_id", refundId),
+		zap.String("deposit_transaction_id", params.DepositTransactionId),
+		zap.String("asset", params.Asset),
+		zap.String("amount", params.Amount.String()),
+		zap.String("status", status))
+
+	return s.GetRefund(ctx, refundId)
+}
+
+// GetRefund returns a refund by id, or ErrRefundNotFound
+func (s *Service) GetRefund(ctx context.Context, id string) (*models.Refund, error) {
+	refund, err := scanRefund(s.db.QueryRowContext(ctx, queryGetRefund, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrRefundNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get refund: %w", err)
+	}
+	return refund, nil
+}
+
+// ListRefunds returns the most recent refunds, newest first
+func (s *Service) ListRefunds(ctx context.Context, limit int) ([]models.Refund, error) {
+	rows, err := s.db.QueryContext(ctx, queryListRefunds, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query refunds: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var refunds []models.Refund
+	for rows.Next() {
+		refund, err := scanRefund(rows)
+		if err != nil {
+			return nil, err
+		}
+		refunds = append(refunds, *refund)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating refunds: %w", err)
+	}
+
+	return refunds, nil
+}
+
+// ApproveRefund approves a refund waiting in pending_approval
+func (s *Service) ApproveRefund(ctx context.Context, id, approvedBy string) error {
+	return s.decideRefund(ctx, id, RefundStatusApproved, approvedBy)
+}
+
+// RejectRefund rejects a refund waiting in pending_approval; the deposit may be refunded again later
+func (s *Service) RejectRefund(ctx context.Context, id, rejectedBy string) error {
+	return s.decideRefund(ctx, id, RefundStatusRejected, rejectedBy)
+}
+
+func (s *Service) decideRefund(ctx context.Context, id, status, decidedBy string) error {
+	result, err := s.db.ExecContext(ctx, queryDecideRefund, status, decidedBy, id)
+	if err != nil {
+		return fmt.Errorf("unable to update refund: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check refund update: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: no refund %s is pending approval", ErrRefundNotFound, id)
+	}
+
+	s.logger.Info("Refund decided",
+		zap.String("refund_id", id),
+		zap.String("status", status),
+		zap.String("decided_by", decidedBy))
+	return nil
+}
+
+// UpdateRefundStatus records the outcome of sending an approved refund
+func (s *Service) UpdateRefundStatus(ctx context.Context, id, status, lastError string) error {
+	if _, err := s.db.ExecContext(ctx, queryUpdateRefundStatus, status, lastError, id); err != nil {
+		return fmt.Errorf("unable to update refund status: %w", err)
+	}
+	return nil
+}
+
+func scanRefund(row rowScanner) (*models.Refund, error) {
+	var refund models.Refund
+	var amountStr string
+	if err := row.Scan(&refund.Id, &refund.DepositTransactionId, &refund.UserId, &refund.Asset, &amountStr,
+		&refund.Destination, &refund.Reason, &refund.Status, &refund.RequestedBy, &refund.ApprovedBy,
+		&refund.IdempotencyKey, &refund.LastError, &refund.CreatedAt, &refund.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	refund.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse refund amount '%s': %w", amountStr, err)
+	}
+
+	return &refund, nil
+}
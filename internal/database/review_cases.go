@@ -0,0 +1,213 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Review case statuses
+const (
+	ReviewCaseStatusOpen   = "open"
+	ReviewCaseStatusClosed = "closed"
+)
+
+// ReviewCaseKindVelocity cases are opened by the velocity anomaly detector
+const ReviewCaseKindVelocity = "velocity"
+
+// OpenReviewCaseParams describes a finding for an operator to investigate
+type OpenReviewCaseParams struct {
+	Kind    string
+	UserId  string
+	Asset   string
+	Key     string
+	Summary string
+	Details string
+}
+
+func (s *Service) initReviewCaseSchema() error {
+	schema := `
+	-- Findings raised for operators to investigate, such as unusual deposit or withdrawal velocity
+	CREATE TABLE IF NOT EXISTS review_cases (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		user_id TEXT NOT NULL DEFAULT '',
+		asset TEXT NOT NULL DEFAULT '',
+		case_key TEXT NOT NULL,
+		summary TEXT NOT NULL,
+		details TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		resolution_note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		resolved_at TIMESTAMP
+	);
+
+	-- At most one open case per finding
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_review_cases_open_key ON review_cases(case_key) WHERE status = 'open';
+	CREATE INDEX IF NOT EXISTS idx_review_cases_status ON review_cases(status);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// OpenReviewCase records a finding unless a case with the same key is still open, or was closed after
+// reopenAfter. It returns the new case, or nil when the finding was already known.
+func (s *Service) OpenReviewCase(ctx context.Context, params OpenReviewCaseParams, reopenAfter time.Time) (*models.ReviewCase, error) {
+	var closedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, queryLatestClosedReviewCase, params.Key).Scan(&closedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("unable to query review cases: %w", err)
+	}
+	if closedAt.Valid && closedAt.Time.After(reopenAfter) {
+		return nil, nil
+	}
+
+	reviewCase := models.ReviewCase{
+		Id:        uuid.New().String(),
+		Kind:      params.Kind,
+		UserId:    params.UserId,
+		Asset:     params.Asset,
+		Key:       params.Key,
+		Summary:   params.Summary,
+		Details:   params.Details,
+		Status:    ReviewCaseStatusOpen,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	result, err := s.db.ExecContext(ctx, queryInsertReviewCase, reviewCase.Id, reviewCase.Kind, reviewCase.UserId,
+		reviewCase.Asset, reviewCase.Key, reviewCase.Summary, reviewCase.Details, reviewCase.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open review case: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, nil
+	}
+
+	s.logger.Warn("Review case opened",
+		zap.String("case_id", reviewCase.Id),
+		zap.String("kind", reviewCase.Kind),
+		zap.String("user_id", reviewCase.UserId),
+		zap.String("asset", reviewCase.Asset),
+		zap.String("summary", reviewCase.Summary))
+
+	return &reviewCase, nil
+}
+
+// ListReviewCases returns review cases, newest first, optionally filtered by status
+func (s *Service) ListReviewCases(ctx context.Context, status string) ([]models.ReviewCase, error) {
+	query := querySelectReviewCases + " ORDER BY created_at DESC"
+	args := []interface{}{}
+	if status != "" {
+		query = querySelectReviewCases + " WHERE status = ? ORDER BY created_at DESC"
+		args = append(args, status)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query review cases: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var cases []models.ReviewCase
+	for rows.Next() {
+		reviewCase, err := scanReviewCase(rows)
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, *reviewCase)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating review cases: %w", err)
+	}
+
+	return cases, nil
+}
+
+// GetReviewCase returns a review case by id
+func (s *Service) GetReviewCase(ctx context.Context, id string) (*models.ReviewCase, error) {
+	reviewCase, err := scanReviewCase(s.db.QueryRowContext(ctx, querySelectReviewCases+" WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrReviewCaseNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return reviewCase, nil
+}
+
+// CloseReviewCase closes an open review case. note records the outcome of the review.
+func (s *Service) CloseReviewCase(ctx context.Context, id, note string) error {
+	reviewCase, err := s.GetReviewCase(ctx, id)
+	if err != nil {
+		return err
+	}
+	if reviewCase.Status != ReviewCaseStatusOpen {
+		return fmt.Errorf("review case %s is already %s", id, reviewCase.Status)
+	}
+
+	result, err := s.db.ExecContext(ctx, queryCloseReviewCase, note, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("unable to close review case: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("review case %s was closed concurrently - %w", id, ErrConcurrentModification)
+	}
+
+	s.logger.Info("Review case closed",
+		zap.String("case_id", id),
+		zap.String("kind", reviewCase.Kind),
+		zap.String("user_id", reviewCase.UserId))
+
+	return nil
+}
+
+func scanReviewCase(row rowScanner) (*models.ReviewCase, error) {
+	var reviewCase models.ReviewCase
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&reviewCase.Id, &reviewCase.Kind, &reviewCase.UserId, &reviewCase.Asset, &reviewCase.Key,
+		&reviewCase.Summary, &reviewCase.Details, &reviewCase.Status, &reviewCase.ResolutionNote,
+		&reviewCase.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	if resolvedAt.Valid {
+		reviewCase.ResolvedAt = &resolvedAt.Time
+	}
+	return &reviewCase, nil
+}
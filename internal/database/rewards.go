@@ -0,0 +1,283 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// TransactionTypeReward is the ledger transaction type for promotional credits
+const TransactionTypeReward = "reward"
+
+// GrantRewardParams contains the parameters for crediting a reward
+type GrantRewardParams struct {
+	Program   string
+	UserId    string
+	Amount    decimal.Decimal
+	Reference string
+}
+
+func (s *Service) initRewardsSchema() error {
+	schema := `
+	-- Promotional credit programs and their budgets
+	CREATE TABLE IF NOT EXISTS reward_programs (
+		name TEXT PRIMARY KEY,
+		asset TEXT NOT NULL,
+		budget TEXT NOT NULL,
+		granted TEXT NOT NULL DEFAULT '0',
+		active BOOLEAN NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- Individual rewards credited to users
+	CREATE TABLE IF NOT EXISTS reward_grants (
+		id TEXT PRIMARY KEY,
+		program TEXT NOT NULL REFERENCES reward_programs(name),
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		reference TEXT NOT NULL DEFAULT '',
+		transaction_id TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_reward_grants_program ON reward_grants(program);
+	CREATE INDEX IF NOT EXISTS idx_reward_grants_user_id ON reward_grants(user_id);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// CreateRewardProgram creates a reward program funded with a fixed budget of asset
+func (s *Service) CreateRewardProgram(ctx context.Context, name, asset string, budget decimal.Decimal) (*models.RewardProgram, error) {
+	s.logger.Info("Creating reward program",
+		zap.String("program", name),
+		zap.String("asset", asset),
+		zap.String("budget", budget.String()))
+
+	if _, err := s.db.ExecContext(ctx, queryInsertRewardProgram, name, asset, budget.String()); err != nil {
+		return nil, fmt.Errorf("unable to insert reward program: %w", err)
+	}
+
+	return s.GetRewardProgram(ctx, name)
+}
+
+// GetRewardProgram returns a reward program by name
+func (s *Service) GetRewardProgram(ctx context.Context, name string) (*models.RewardProgram, error) {
+	program, err := scanRewardProgram(s.db.QueryRowContext(ctx, queryGetRewardProgram, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrRewardProgramNotFound, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to query reward program: %w", err)
+	}
+	return program, nil
+}
+
+// ListRewardPrograms returns all reward programs with their budget usage
+func (s *Service) ListRewardPrograms(ctx context.Context) ([]models.RewardProgram, error) {
+	rows, err := s.db.QueryContext(ctx, queryListRewardPrograms)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query reward programs: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var programs []models.RewardProgram
+	for rows.Next() {
+		program, err := scanRewardProgram(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan reward program: %w", err)
+		}
+		programs = append(programs, *program)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reward programs: %w", err)
+	}
+
+	return programs, nil
+}
+
+// ListRewardGrants returns the most recent grants for a program
+func (s *Service) ListRewardGrants(ctx context.Context, program string, limit int) ([]models.RewardGrant, error) {
+	rows, err := s.db.QueryContext(ctx, queryListRewardGrants, program, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query reward grants: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var grants []models.RewardGrant
+	for rows.Next() {
+		var grant models.RewardGrant
+		var amountStr string
+		if err := rows.Scan(&grant.Id, &grant.Program, &grant.UserId, &grant.Asset, &amountStr,
+			&grant.Reference, &grant.TransactionId, &grant.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan reward grant: %w", err)
+		}
+		grant.Amount, err = decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse reward amount '%s': %w", amountStr, err)
+		}
+		grants = append(grants, grant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reward grants: %w", err)
+	}
+
+	return grants, nil
+}
+
+// GrantReward credits a reward to a user, drawing down the program budget.
+// Each program/user/reference combination can only be granted once.
+func (s *Service) GrantReward(ctx context.Context, params GrantRewardParams) (*models.RewardGrant, error) {
+	program, err := s.GetRewardProgram(ctx, params.Program)
+	if err != nil {
+		return nil, err
+	}
+	if !program.Active {
+		return nil, fmt.Errorf("reward program %s is not active", program.Name)
+	}
+
+	// Reserve budget first so concurrent grants cannot overspend
+	if err := s.adjustRewardBudget(ctx, program.Name, params.Amount); err != nil {
+		return nil, err
+	}
+
+	externalTxId := fmt.Sprintf("reward:%s:%s:%s", program.Name, params.UserId, params.Reference)
+	transaction, err := s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId:          params.UserId,
+		Asset:           program.Asset,
+		TransactionType: TransactionTypeReward,
+		Amount:          params.Amount,
+		ExternalTxId:    externalTxId,
+		Address:         "",
+		Reference:       fmt.Sprintf("Reward: %s", program.Name),
+	})
+	if err != nil {
+		if releaseErr := s.adjustRewardBudget(ctx, program.Name, params.Amount.Neg()); releaseErr != nil {
+			s.logger.Error("Failed to release reward budget after failed grant",
+				zap.String("program", program.Name),
+				zap.String("amount", params.Amount.String()),
+				zap.Error(releaseErr))
+		}
+		return nil, fmt.Errorf("error processing reward transaction: %w", err)
+	}
+
+	grant := &models.RewardGrant{
+		Id:            uuid.New().String(),
+		Program:       program.Name,
+		UserId:        params.UserId,
+		Asset:         program.Asset,
+		Amount:        params.Amount,
+		Reference:     params.Reference,
+		TransactionId: transaction.Id,
+		CreatedAt:     transaction.CreatedAt,
+	}
+
+	_, err = s.db.ExecContext(ctx, queryInsertRewardGrant, grant.Id, grant.Program, grant.UserId,
+		grant.Asset, grant.Amount.String(), grant.Reference, grant.TransactionId)
+	if err != nil {
+		// The ledger credit is already committed; the grant record is informational
+		s.logger.Error("Reward credited but grant record could not be stored",
+			zap.String("program", program.Name),
+			zap.String("transaction_id", transaction.Id),
+			zap.Error(err))
+	}
+
+	s.logger.Info("Reward granted",
+		zap.String("program", program.Name),
+		zap.String("user_id", params.UserId),
+		zap.String("asset", program.Asset),
+		zap.String("amount", params.Amount.String()),
+		zap.String("transaction_id", transaction.Id))
+
+	return grant, nil
+}
+
+// adjustRewardBudget adds delta to a program's granted total, rejecting increases beyond the budget.
+// Uses compare-and-swap on the previous granted value to stay safe under concurrent grants.
+func (s *Service) adjustRewardBudget(ctx context.Context, name string, delta decimal.Decimal) error {
+	for attempt := 0; attempt < 5; attempt++ {
+		program, err := s.GetRewardProgram(ctx, name)
+		if err != nil {
+			return err
+		}
+
+		newGranted := program.Granted.Add(delta)
+		if delta.IsPositive() && newGranted.GreaterThan(program.Budget) {
+			return fmt.Errorf("%w: program=%s budget=%s granted=%s requested=%s",
+				ErrRewardBudgetExceeded, name, program.Budget.String(), program.Granted.String(), delta.String())
+		}
+
+		result, err := s.db.ExecContext(ctx, queryUpdateRewardProgramGranted, newGranted.String(), name, program.Granted.String())
+		if err != nil {
+			return fmt.Errorf("unable to update reward budget: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("unable to check rows affected: %w", err)
+		}
+		if rowsAffected == 1 {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("reward budget update failed - %w", ErrConcurrentModification)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanRewardProgram(row rowScanner) (*models.RewardProgram, error) {
+	var program models.RewardProgram
+	var budgetStr, grantedStr string
+	if err := row.Scan(&program.Name, &program.Asset, &budgetStr, &grantedStr, &program.Active, &program.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	program.Budget, err = decimal.NewFromString(budgetStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reward budget '%s': %w", budgetStr, err)
+	}
+	program.Granted, err = decimal.NewFromString(grantedStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse reward granted '%s': %w", grantedStr, err)
+	}
+
+	return &program, nil
+}
@@ -0,0 +1,90 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGrantReward_EnforcesBudgetAndIdempotency(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initRewardsSchema(); err != nil {
+		t.Fatalf("Failed to create rewards schema: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := service.CreateRewardProgram(ctx, "launch", "USDC", decimal.NewFromInt(100)); err != nil {
+		t.Fatalf("CreateRewardProgram failed: %v", err)
+	}
+
+	grant, err := service.GrantReward(ctx, GrantRewardParams{
+		Program:   "launch",
+		UserId:    "user1",
+		Amount:    decimal.NewFromInt(60),
+		Reference: "signup",
+	})
+	if err != nil {
+		t.Fatalf("GrantReward failed: %v", err)
+	}
+	if grant.Asset != "USDC" {
+		t.Errorf("Expected asset USDC, got %s", grant.Asset)
+	}
+
+	balance, err := service.GetUserBalance(ctx, "user1", "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance failed: %v", err)
+	}
+	if !balance.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("Expected balance 60, got %s", balance.String())
+	}
+
+	// Same reference must not credit twice, and must not consume budget
+	_, err = service.GrantReward(ctx, GrantRewardParams{
+		Program:   "launch",
+		UserId:    "user1",
+		Amount:    decimal.NewFromInt(10),
+		Reference: "signup",
+	})
+	if !errors.Is(err, ErrDuplicateTransaction) {
+		t.Fatalf("Expected ErrDuplicateTransaction, got %v", err)
+	}
+
+	// Exceeding the remaining budget is rejected
+	_, err = service.GrantReward(ctx, GrantRewardParams{
+		Program:   "launch",
+		UserId:    "user2",
+		Amount:    decimal.NewFromInt(50),
+		Reference: "signup",
+	})
+	if !errors.Is(err, ErrRewardBudgetExceeded) {
+		t.Fatalf("Expected ErrRewardBudgetExceeded, got %v", err)
+	}
+
+	program, err := service.GetRewardProgram(ctx, "launch")
+	if err != nil {
+		t.Fatalf("GetRewardProgram failed: %v", err)
+	}
+	if !program.Granted.Equal(decimal.NewFromInt(60)) {
+		t.Errorf("Expected granted 60, got %s", program.Granted.String())
+	}
+}
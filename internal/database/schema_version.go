@@ -0,0 +1,57 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// SchemaVersion identifies the schema this build creates. Bump it with any schema change clients or
+// tools may need to detect; it is stored in SQLite's user_version and reported on /meta.
+const SchemaVersion = 3
+
+// stampSchemaVersion records SchemaVersion once the schema is up to date. A database last opened by a
+// newer build keeps its version, so an older binary does not hide that the schema moved on.
+func (s *Service) stampSchemaVersion() error {
+	var current int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&current); err != nil {
+		return err
+	}
+	if current > SchemaVersion {
+		s.logger.Warn("Database schema is newer than this build",
+			zap.Int("database_schema_version", current),
+			zap.Int("build_schema_version", SchemaVersion))
+		return nil
+	}
+	if current == SchemaVersion {
+		return nil
+	}
+	_, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion))
+	return err
+}
+
+// SchemaVersion returns the schema version recorded in the database
+func (s *Service) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("unable to read schema version: %w", err)
+	}
+	return version, nil
+}
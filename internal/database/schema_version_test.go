@@ -0,0 +1,48 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStampSchemaVersion(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := service.stampSchemaVersion(); err != nil {
+		t.Fatalf("stampSchemaVersion failed: %v", err)
+	}
+	version, err := service.SchemaVersion(ctx)
+	if err != nil || version != SchemaVersion {
+		t.Fatalf("Expected schema version %d, got %d (%v)", SchemaVersion, version, err)
+	}
+
+	// A database stamped by a newer build keeps its version
+	if _, err := service.db.Exec("PRAGMA user_version = 99"); err != nil {
+		t.Fatalf("Failed to set user_version: %v", err)
+	}
+	if err := service.stampSchemaVersion(); err != nil {
+		t.Fatalf("stampSchemaVersion failed: %v", err)
+	}
+	version, err = service.SchemaVersion(ctx)
+	if err != nil || version != 99 {
+		t.Errorf("Expected schema version 99 to be kept, got %d (%v)", version, err)
+	}
+}
@@ -19,9 +19,11 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/models"
 
 	"github.com/google/uuid"
@@ -31,11 +33,21 @@ import (
 )
 
 type Service struct {
-	db        *sql.DB
-	subledger *SubledgerService
+	db              *sql.DB
+	logger          *zap.Logger
+	subledger       *SubledgerService
+	suspenseHandler SuspenseHandler
+	// unattributedHandler is told about deposits to addresses no user owns
+	unattributedHandler UnattributedDepositHandler
+	depositScreener     DepositScreener
+	// tenantId scopes user and balance lookups to one tenant; empty means all tenants
+	tenantId string
+	// balancePolicies is the negative-balance policy for debits from each transaction source. It is
+	// built once in NewService and only read afterwards, so it needs no lock.
+	balancePolicies map[models.TransactionSource]models.BalancePolicy
 }
 
-func NewService(ctx context.Context, cfg models.DatabaseConfig) (*Service, error) {
+func NewService(ctx context.Context, cfg models.DatabaseConfig, logger *zap.Logger) (*Service, error) {
 	// Validate configuration
 	if cfg.Path == "" {
 		return nil, fmt.Errorf("database path cannot be empty")
@@ -50,8 +62,17 @@ func NewService(ctx context.Context, cfg models.DatabaseConfig) (*Service, error
 		return nil, fmt.Errorf("ping timeout must be positive, got %v", cfg.PingTimeout)
 	}
 
-	zap.L().Info("Opening SQLite database", zap.String("file", cfg.Path))
-	db, err := sql.Open("sqlite3", cfg.Path+"?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=1000")
+	logger.Info("Opening SQLite database", zap.String("file", cfg.Path))
+	dsn := cfg.Path + "?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=1000"
+	if cfg.BusyTimeout > 0 {
+		dsn += fmt.Sprintf("&_busy_timeout=%d", cfg.BusyTimeout.Milliseconds())
+	}
+	// SQLite has no SELECT ... FOR UPDATE; BEGIN IMMEDIATE takes the write lock up front instead, so
+	// a balance read inside a transaction cannot be changed by another connection before it is written
+	if cfg.BalanceLocking == models.LockingPessimistic {
+		dsn += "&_txlock=immediate"
+	}
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("unable to open database: %w", err)
 	}
@@ -73,8 +94,27 @@ func NewService(ctx context.Context, cfg models.DatabaseConfig) (*Service, error
 		return nil, fmt.Errorf("unable to ping database: %w", err)
 	}
 
-	subledger := NewSubledgerService(db)
-	service := &Service{db: db, subledger: subledger}
+	subledger := NewSubledgerService(db, logger)
+	if cfg.ChartOfAccountsFile != "" {
+		chart, err := LoadChartOfAccounts(cfg.ChartOfAccountsFile)
+		if err != nil {
+			err := db.Close()
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("unable to load chart of accounts: %w", err)
+		}
+		subledger.chart = chart
+		logger.Info("Loaded chart of accounts", zap.String("file", cfg.ChartOfAccountsFile))
+	}
+	balancePolicies := make(map[models.TransactionSource]models.BalancePolicy, len(models.DefaultBalancePolicies))
+	for source, policy := range models.DefaultBalancePolicies {
+		balancePolicies[source] = policy
+	}
+	for source, policy := range cfg.BalancePolicies {
+		balancePolicies[models.TransactionSource(source)] = models.BalancePolicy(policy)
+	}
+	service := &Service{db: db, logger: logger, subledger: subledger, balancePolicies: balancePolicies}
 	if err := service.initSchema(cfg.CreateDummyUsers); err != nil {
 		err := db.Close()
 		if err != nil {
@@ -92,13 +132,197 @@ func NewService(ctx context.Context, cfg models.DatabaseConfig) (*Service, error
 		return nil, fmt.Errorf("unable to initialize subledger schema: %w", err)
 	}
 
-	zap.L().Info("Database service initialized successfully")
+	if err := service.initTenantSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize tenant schema: %w", err)
+	}
+
+	if err := service.initWithdrawalQueueSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize withdrawal queue schema: %w", err)
+	}
+
+	if err := service.initWithdrawalHoldSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize withdrawal hold schema: %w", err)
+	}
+
+	if err := service.initWithdrawalRequestSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize withdrawal request schema: %w", err)
+	}
+
+	if err := service.initRewardsSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize rewards schema: %w", err)
+	}
+
+	if err := service.initInterestSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize interest schema: %w", err)
+	}
+
+	if err := service.initSuspenseSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize suspense schema: %w", err)
+	}
+
+	if err := service.initCounterpartySchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize counterparty schema: %w", err)
+	}
+
+	if err := service.initTreasurySchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize treasury schema: %w", err)
+	}
+
+	if err := service.initCheckpointSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize checkpoint schema: %w", err)
+	}
+
+	if err := service.initProcessedTransactionSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize processed transaction schema: %w", err)
+	}
+
+	if err := service.initApiTokenSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize API token schema: %w", err)
+	}
+
+	if err := service.initIdempotencySchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize idempotency schema: %w", err)
+	}
+
+	if err := service.initReceiptSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize receipt schema: %w", err)
+	}
+
+	if err := service.initPrimeTransactionSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize prime transaction schema: %w", err)
+	}
+
+	if err := service.initPrimeTransactionAckSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize acknowledged transaction schema: %w", err)
+	}
+
+	if err := service.initInvoiceSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize invoice schema: %w", err)
+	}
+
+	if err := service.initRefundSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize refund schema: %w", err)
+	}
+
+	if err := service.initOperationLockSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize operation lock schema: %w", err)
+	}
+
+	if err := service.initUnattributedSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize unattributed deposit schema: %w", err)
+	}
+
+	if err := service.initReviewCaseSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize review case schema: %w", err)
+	}
+
+	if err := service.initTransactionObservationSchema(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to initialize transaction observation schema: %w", err)
+	}
+
+	if err := service.stampSchemaVersion(); err != nil {
+		err := db.Close()
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unable to record schema version: %w", err)
+	}
+
+	logger.Info("Database service initialized successfully")
 	return service, nil
 }
 
 func (s *Service) Close() {
 	if err := s.db.Close(); err != nil {
-		zap.L().Warn("Failed to close database connection", zap.Error(err))
+		s.logger.Warn("Failed to close database connection", zap.Error(err))
 	}
 }
 
@@ -148,6 +372,17 @@ func (s *Service) initSchema(createDummyUsers bool) error {
 		return err
 	}
 
+	// Users and their addresses belong to a tenant; existing rows join the default tenant
+	for _, table := range []string{"users", "addresses"} {
+		if err := addColumnIfMissing(s.db, s.logger, table, "tenant_id", "TEXT NOT NULL DEFAULT '"+DefaultTenantId+"'"); err != nil {
+			return err
+		}
+	}
+
+	if err := addColumnIfMissing(s.db, s.logger, "addresses", "label", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
 	// Insert 3 dummy users for testing if configured to do so
 	if createDummyUsers {
 		users := []struct {
@@ -161,15 +396,15 @@ func (s *Service) initSchema(createDummyUsers bool) error {
 		}
 
 		for _, user := range users {
-			_, err := s.db.Exec(queryInsertUser, user.id, user.name, user.email)
+			_, err := s.db.Exec(queryInsertUser, user.id, user.name, user.email, DefaultTenantId)
 			if err != nil {
-				zap.L().Error("Failed to insert dummy user", zap.String("name", user.name), zap.Error(err))
+				s.logger.Error("Failed to insert dummy user", zap.String("name", user.name), zap.Error(err))
 			} else {
-				zap.L().Info("Dummy user created", zap.String("id", user.id), zap.String("name", user.name))
+				s.logger.Info("Dummy user created", zap.String("id", user.id), zap.String("name", user.name))
 			}
 		}
 	} else {
-		zap.L().Info("Skipping dummy user creation (CREATE_DUMMY_USERS=false)")
+		s.logger.Info("Skipping dummy user creation (CREATE_DUMMY_USERS=false)")
 	}
 
 	return nil
@@ -181,11 +416,45 @@ func (s *Service) GetUserBalance(ctx context.Context, userId string, asset strin
 	return s.subledger.GetBalance(ctx, userId, asset)
 }
 
+// GetAvailableBalance returns the part of a user's balance that may be withdrawn
+func (s *Service) GetAvailableBalance(ctx context.Context, userId string, asset string) (decimal.Decimal, error) {
+	return s.subledger.GetAvailableBalance(ctx, userId, asset)
+}
+
 func (s *Service) GetAllUserBalances(ctx context.Context, userId string) ([]models.AccountBalance, error) {
 	return s.subledger.GetAllBalances(ctx, userId)
 }
 
-func (s *Service) ProcessDeposit(ctx context.Context, address, asset string, amount decimal.Decimal, transactionId string) error {
+// GetUserBalancesVersion returns a token that changes whenever any of the user's balances change
+func (s *Service) GetUserBalancesVersion(ctx context.Context, userId string) (string, error) {
+	return s.subledger.GetBalancesVersion(ctx, userId)
+}
+
+func (s *Service) GetAllUserBalancesIncludingZero(ctx context.Context, userId string) ([]models.AccountBalance, error) {
+	return s.subledger.GetAllBalancesIncludingZero(ctx, userId)
+}
+
+func (s *Service) ListAccountBalances(ctx context.Context) ([]models.AccountBalance, error) {
+	return s.subledger.ListAccountBalances(ctx, s.tenantId)
+}
+
+func (s *Service) GetBalancesBulk(ctx context.Context, userIds []string, asset string) ([]models.AccountBalance, error) {
+	return s.subledger.GetBalancesBulk(ctx, userIds, asset, s.tenantId)
+}
+
+func (s *Service) ListAllAccountBalances(ctx context.Context) ([]models.AccountBalance, error) {
+	return s.subledger.ListAllAccountBalances(ctx, s.tenantId)
+}
+
+// ComputeDigest returns a deterministic digest of the balances, and optionally the transactions,
+// this service can see
+func (s *Service) ComputeDigest(ctx context.Context, includeTransactions bool) (*models.LedgerDigest, error) {
+	return s.subledger.ComputeDigest(ctx, s.tenantId, includeTransactions)
+}
+
+// ProcessDeposit credits a deposit to the owner of the receiving address. availability is the funds
+// availability policy resolved for the deposit (models.Availability*); an empty policy means immediate.
+func (s *Service) ProcessDeposit(ctx context.Context, address, asset string, amount decimal.Decimal, transactionId string, source models.DepositSource, availability string) error {
 	// Find user by address
 	user, addr, err := s.FindUserByAddress(ctx, address)
 	if err != nil {
@@ -193,36 +462,69 @@ func (s *Service) ProcessDeposit(ctx context.Context, address, asset string, amo
 	}
 
 	if user == nil {
-		zap.L().Warn("Deposit to unknown address", zap.String("address", address))
-		return fmt.Errorf("no user found for address: %s", address)
+		correlation.Logger(ctx, s.logger).Warn("Deposit to unknown address", zap.String("address", address))
+		return fmt.Errorf("%w for address %s", ErrUserNotFound, address)
 	}
 
 	// Use canonical symbol from address table (not Prime API's symbol which varies by network)
 	// e.g., Prime API returns "BASEUSDC" but we store as symbol="USDC", network="base-mainnet"
 	canonicalSymbol := addr.Asset
 
+	// A different asset arrived at this address (e.g. a token sent to an ETH address) - hold it in suspense
+	if !symbolsMatch(asset, canonicalSymbol) {
+		return s.holdInSuspense(ctx, user, addr, asset, amount, transactionId, source)
+	}
+
 	if canonicalSymbol != asset {
-		zap.L().Info("Using canonical symbol from address table",
+		correlation.Logger(ctx, s.logger).Info("Using canonical symbol from address table",
 			zap.String("address", address),
 			zap.String("prime_api_symbol", asset),
 			zap.String("canonical_symbol", canonicalSymbol),
 			zap.String("network", addr.Network))
 	}
 
-	_, err = s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+	// Addresses generated for an invoice settle it; the reference ties the ledger entry to the invoice
+	invoice, err := s.findInvoiceAddress(ctx, address)
+	if err != nil {
+		return err
+	}
+	var reference, invoiceStatus string
+	if invoice != nil {
+		reference, invoiceStatus = s.settleInvoice(ctx, invoice, amount)
+	}
+
+	holdKind, holdReason := s.depositHold(ctx, models.DepositScreening{
+		UserId:                user.Id,
+		Asset:                 canonicalSymbol,
+		Network:               addr.Network,
+		Amount:                amount,
+		ExternalTransactionId: transactionId,
+		Address:               address,
+		Source:                source,
+	}, availability)
+
+	transaction, err := s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
 		UserId:          user.Id,
 		Asset:           canonicalSymbol,
 		TransactionType: "deposit",
 		Amount:          amount,
 		ExternalTxId:    transactionId,
 		Address:         address,
-		Reference:       "",
+		SourceType:      source.Type,
+		SourceAddress:   source.Address,
+		Reference:       reference,
+		HoldReason:      holdReason,
+		HoldKind:        holdKind,
 	})
 	if err != nil {
 		return fmt.Errorf("error processing deposit transaction: %w", err)
 	}
 
-	zap.L().Info("Deposit processed successfully",
+	if invoiceStatus != "" {
+		s.markInvoicePaid(ctx, invoice, invoiceStatus, transaction.Id)
+	}
+
+	correlation.Logger(ctx, s.logger).Info("Deposit processed successfully",
 		zap.String("user_id", user.Id),
 		zap.String("user_name", user.Name),
 		zap.String("canonical_symbol", canonicalSymbol),
@@ -232,50 +534,70 @@ func (s *Service) ProcessDeposit(ctx context.Context, address, asset string, amo
 	return nil
 }
 
-// ProcessWithdrawal processes a withdrawal transaction for a user by user Id
+// ProcessWithdrawal processes a withdrawal transaction for a user by user Id.
+// Used when syncing withdrawals from Prime, so unless the transaction source on ctx is configured
+// otherwise the balance may go negative (flagged).
 func (s *Service) ProcessWithdrawal(ctx context.Context, userId, asset string, amount decimal.Decimal, transactionId string) error {
-	user, err := s.GetUserById(ctx, userId)
+	return s.processWithdrawal(ctx, ProcessTransactionParams{
+		UserId:       userId,
+		Asset:        asset,
+		Amount:       amount,
+		ExternalTxId: transactionId,
+	}, s.balancePolicy(ctx, models.BalancePolicySync))
+}
+
+// destinationTypeOrDefault treats an empty destination type as an on-chain address
+func destinationTypeOrDefault(destinationType string) string {
+	if destinationType == "" {
+		return "address"
+	}
+	return destinationType
+}
+
+// processWithdrawal debits params.Amount (a positive withdrawal amount) from the user's balance
+func (s *Service) processWithdrawal(ctx context.Context, params ProcessTransactionParams, policy models.BalancePolicy) error {
+	user, err := s.GetUserById(ctx, params.UserId)
 	if err != nil {
-		zap.L().Warn("Withdrawal for unknown user", zap.String("user_id", userId))
+		correlation.Logger(ctx, s.logger).Warn("Withdrawal for unknown user", zap.String("user_id", params.UserId))
 		return fmt.Errorf("error getting user: %w", err)
 	}
 
 	// Get current balance for logging purposes (no validation for historical transactions)
-	currentBalance, err := s.GetUserBalance(ctx, userId, asset)
+	currentBalance, err := s.GetUserBalance(ctx, params.UserId, params.Asset)
 	if err != nil {
 		return fmt.Errorf("error getting current balance: %w", err)
 	}
 
-	zap.L().Info("Processing withdrawal information",
-		zap.String("user_id", userId),
-		zap.String("asset_network", asset),
+	correlation.Logger(ctx, s.logger).Info("Processing withdrawal information",
+		zap.String("user_id", params.UserId),
+		zap.String("asset_network", params.Asset),
 		zap.String("current_balance", currentBalance.String()),
-		zap.String("withdrawal_amount", amount.String()))
+		zap.String("withdrawal_amount", params.Amount.String()))
 
-	_, err = s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+	_, err = s.subledger.ProcessTransactionWithPolicy(ctx, ProcessTransactionParams{
 		UserId:          user.Id,
-		Asset:           asset,
+		Asset:           params.Asset,
 		TransactionType: "withdrawal",
-		Amount:          amount.Neg(),
-		ExternalTxId:    transactionId,
-		Address:         "",
-		Reference:       "",
-	})
+		Amount:          params.Amount.Neg(),
+		ExternalTxId:    params.ExternalTxId,
+		Address:         params.Address,
+		Reference:       params.Reference,
+	}, policy)
 	if err != nil {
 		return fmt.Errorf("error processing withdrawal transaction: %w", err)
 	}
 
-	zap.L().Info("Withdrawal processed successfully",
+	correlation.Logger(ctx, s.logger).Info("Withdrawal processed successfully",
 		zap.String("user_id", user.Id),
 		zap.String("user_name", user.Name),
-		zap.String("asset_network", asset),
-		zap.String("amount", amount.String()))
+		zap.String("asset_network", params.Asset),
+		zap.String("amount", params.Amount.String()))
 
 	return nil
 }
 
-func (s *Service) GetTransactionHistory(ctx context.Context, userId, asset string, limit, offset int) ([]models.Transaction, error) {
-	return s.subledger.GetTransactionHistory(ctx, userId, asset, limit, offset)
+func (s *Service) GetTransactionHistory(ctx context.Context, userId, asset string, limit int, before string) ([]models.Transaction, error) {
+	return s.subledger.GetTransactionHistory(ctx, userId, asset, limit, before)
 }
 
 func (s *Service) ReconcileUserBalance(ctx context.Context, userId, asset string) error {
@@ -286,11 +608,18 @@ func (s *Service) GetMostRecentTransactionTime(ctx context.Context) (time.Time,
 	return s.subledger.GetMostRecentTransactionTime(ctx)
 }
 
-// ReverseWithdrawal credits back a withdrawal that failed (rollback)
+// ReversalExternalTxId is the external id a reversal of originalTxId is recorded under. It cannot
+// collide with the Prime transaction ids or idempotency keys the listener records.
+func ReversalExternalTxId(originalTxId string) string {
+	return originalTxId + ":reversal"
+}
+
+// ReverseWithdrawal credits back a withdrawal that failed (rollback). Returns ErrDuplicateTransaction
+// if the withdrawal was already reversed.
 func (s *Service) ReverseWithdrawal(ctx context.Context, userId, asset string, amount decimal.Decimal, originalTxId string) error {
-	reversalTxId := originalTxId + "-reversal"
+	reversalTxId := ReversalExternalTxId(originalTxId)
 
-	zap.L().Info("Reversing failed withdrawal",
+	correlation.Logger(ctx, s.logger).Info("Reversing failed withdrawal",
 		zap.String("user_id", userId),
 		zap.String("asset", asset),
 		zap.String("amount", amount.String()),
@@ -306,15 +635,63 @@ func (s *Service) ReverseWithdrawal(ctx context.Context, userId, asset string, a
 		ExternalTxId:    reversalTxId,
 		Address:         "",
 		Reference:       "Reversal of failed withdrawal",
+		ReversalOf:      originalTxId,
+		ReversedStatus:  TransactionStatusFailed,
 	})
 	if err != nil {
 		return fmt.Errorf("error reversing withdrawal: %w", err)
 	}
 
-	zap.L().Info("Withdrawal reversed successfully",
+	correlation.Logger(ctx, s.logger).Info("Withdrawal reversed successfully",
 		zap.String("user_id", userId),
 		zap.String("asset", asset),
 		zap.String("amount", amount.String()))
 
 	return nil
 }
+
+// FindReversal returns the ledger entry that reversed originalTxId, or nil if it was not reversed
+func (s *Service) FindReversal(ctx context.Context, originalTxId string) (*models.Transaction, error) {
+	transaction, err := scanTransaction(s.db.QueryRowContext(ctx, queryFindReversal, originalTxId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to look up reversal: %w", err)
+	}
+	return transaction, nil
+}
+
+// addColumnIfMissing adds a column to an existing table, for schemas created by older versions
+func addColumnIfMissing(db *sql.DB, logger *zap.Logger, table, column, definition string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("unable to inspect table %s: %w", table, err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, columnType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &columnType, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("unable to scan table info for %s: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating table info for %s: %w", table, err)
+	}
+
+	logger.Info("Adding missing column", zap.String("table", table), zap.String("column", column))
+	if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("unable to add column %s.%s: %w", table, column, err)
+	}
+	return nil
+}
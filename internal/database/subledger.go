@@ -19,24 +19,55 @@ package database
 import (
 	"database/sql"
 	"errors"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
 )
 
 // Sentinel errors for database operations
 var (
-	ErrDuplicateTransaction   = errors.New("duplicate transaction")
-	ErrConcurrentModification = errors.New("concurrent modification detected")
-	ErrUserNotFound           = errors.New("no user found for address")
+	ErrDuplicateTransaction    = errors.New("duplicate transaction")
+	ErrConcurrentModification  = errors.New("concurrent modification detected")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrRewardProgramNotFound   = errors.New("reward program not found")
+	ErrRewardBudgetExceeded    = errors.New("reward budget exceeded")
+	ErrInsufficientBalance     = errors.New("insufficient balance")
+	ErrDepositSuspended        = errors.New("deposit asset does not match address - held in suspense")
+	ErrSuspenseEntryNotFound   = errors.New("suspense entry not found")
+	ErrDepositHoldNotFound     = errors.New("deposit hold not found")
+	ErrInvalidApiToken         = errors.New("invalid or revoked api token")
+	ErrIdempotencyKeyReused    = errors.New("idempotency key was used with a different request")
+	ErrIdempotencyKeyInFlight  = errors.New("a request with this idempotency key is in progress")
+	ErrDepositNotFound         = errors.New("deposit not found")
+	ErrRefundNotFound          = errors.New("refund not found")
+	ErrRefundExists            = errors.New("deposit already has an open refund")
+	ErrReceiptNotFound         = errors.New("withdrawal receipt not found")
+	ErrOperationInProgress     = errors.New("another operation in progress")
+	ErrOperationLockLost       = errors.New("operation lock is no longer held")
+	ErrUnattributedNotFound    = errors.New("unattributed deposit not found")
+	ErrReviewCaseNotFound      = errors.New("review case not found")
+	ErrTransactionNotFound     = errors.New("transaction not found")
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
 )
 
 // SubledgerService handles subledger operations
 type SubledgerService struct {
-	db *sql.DB
+	db                     *sql.DB
+	logger                 *zap.Logger
+	negativeBalanceHandler NegativeBalanceHandler
+	balanceChangeHandlers  []BalanceChangeHandler
+	chart                  models.ChartOfAccounts
 }
 
-func NewSubledgerService(db *sql.DB) *SubledgerService {
-	return &SubledgerService{
-		db: db,
+func NewSubledgerService(db *sql.DB, logger *zap.Logger) *SubledgerService {
+	s := &SubledgerService{
+		db:     db,
+		logger: logger,
+		chart:  DefaultChartOfAccounts(),
 	}
+	s.negativeBalanceHandler = s.logNegativeBalance
+	return s
 }
 
 func (s *SubledgerService) InitSchema() error {
@@ -46,7 +77,10 @@ func (s *SubledgerService) InitSchema() error {
 		id TEXT PRIMARY KEY,
 		user_id TEXT NOT NULL,
 		asset TEXT NOT NULL,
-		balance REAL NOT NULL DEFAULT 0,
+		-- Amounts are decimal strings so they are stored exactly; SQL only tests their sign
+		balance TEXT NOT NULL DEFAULT '0',
+		-- Portion of the balance that may be withdrawn; deposits still held are excluded
+		available_balance TEXT,
 		last_transaction_id TEXT,
 		version INTEGER NOT NULL DEFAULT 1,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -59,15 +93,20 @@ func (s *SubledgerService) InitSchema() error {
 		user_id TEXT NOT NULL,
 		asset TEXT NOT NULL,
 		transaction_type TEXT NOT NULL,
-		amount REAL NOT NULL,
-		balance_before REAL NOT NULL,
-		balance_after REAL NOT NULL,
+		amount TEXT NOT NULL,
+		balance_before TEXT NOT NULL,
+		balance_after TEXT NOT NULL,
 		external_transaction_id TEXT,
 		address TEXT,
 		reference TEXT,
 		status TEXT DEFAULT 'confirmed',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		processed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		-- Where a deposit came from, as reported by Prime's transfer_from
+		source_type TEXT NOT NULL DEFAULT '',
+		source_address TEXT NOT NULL DEFAULT '',
+		-- External id of the transaction a reversal undoes
+		reversal_of TEXT NOT NULL DEFAULT ''
 	);
 
 	-- Performance Indexes for Account Balances
@@ -76,7 +115,10 @@ func (s *SubledgerService) InitSchema() error {
 	CREATE UNIQUE INDEX IF NOT EXISTS idx_account_balances_user_asset ON account_balances(user_id, asset);
 
 	-- Performance Indexes for Transactions
-	CREATE INDEX IF NOT EXISTS idx_transactions_user_asset ON transactions(user_id, asset);
+	-- History pages are read newest first per user/asset, keyset paginated on (created_at, id). The index
+	-- also serves every lookup by user and asset, replacing the former (user_id, asset) index.
+	CREATE INDEX IF NOT EXISTS idx_transactions_history ON transactions(user_id, asset, created_at DESC, id DESC);
+	DROP INDEX IF EXISTS idx_transactions_user_asset;
 	CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at);
 	CREATE INDEX IF NOT EXISTS idx_transactions_external_id ON transactions(external_transaction_id);
 	CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id);
@@ -89,15 +131,90 @@ func (s *SubledgerService) InitSchema() error {
 		transaction_id TEXT NOT NULL,
 		account_type TEXT NOT NULL,
 		account_id TEXT NOT NULL,
-		debit_amount REAL DEFAULT 0,
-		credit_amount REAL DEFAULT 0,
+		debit_amount TEXT DEFAULT '0',
+		credit_amount TEXT DEFAULT '0',
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_journal_transaction_id ON journal_entries(transaction_id);
 	CREATE INDEX IF NOT EXISTS idx_journal_account ON journal_entries(account_type, account_id);
+
+	-- Negative Balance Events (sync operations that drove an account below zero)
+	CREATE TABLE IF NOT EXISTS negative_balance_events (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		transaction_id TEXT NOT NULL,
+		transaction_type TEXT NOT NULL,
+		balance_before TEXT NOT NULL,
+		balance_after TEXT NOT NULL,
+		policy TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_negative_balance_events_user ON negative_balance_events(user_id, asset);
+
+	-- Deposits held by inbound screening; held amounts cannot be withdrawn until released
+	CREATE TABLE IF NOT EXISTS deposit_holds (
+		id TEXT PRIMARY KEY,
+		transaction_id TEXT NOT NULL UNIQUE,
+		external_transaction_id TEXT NOT NULL,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		kind TEXT NOT NULL DEFAULT 'review',
+		reason TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'held',
+		release_note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		released_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_deposit_holds_user_asset ON deposit_holds(user_id, asset, status);
 	`
 
-	_, err := s.db.Exec(schema)
-	return err
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Databases created before deposit sources were recorded
+	if err := addColumnIfMissing(s.db, s.logger, "transactions", "source_type", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(s.db, s.logger, "transactions", "source_address", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_transactions_source_address ON transactions(source_address)`); err != nil {
+		return err
+	}
+
+	// Databases created before reversals were linked; those used "<original>-reversal" as the external id
+	if err := addColumnIfMissing(s.db, s.logger, "transactions", "reversal_of", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(queryBackfillReversalLinks); err != nil {
+		return err
+	}
+	if _, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_transactions_reversal_of ON transactions(reversal_of)`); err != nil {
+		return err
+	}
+
+	// Databases created before balances were split into total and available
+	if err := addColumnIfMissing(s.db, s.logger, "account_balances", "available_balance", "TEXT"); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(s.db, s.logger, "deposit_holds", "kind", "TEXT NOT NULL DEFAULT 'review'"); err != nil {
+		return err
+	}
+
+	if err := s.initTransactionStatusSchema(); err != nil {
+		return err
+	}
+
+	// Databases created when amounts were stored as floating point
+	if err := migrateExactAmounts(s.db, s.logger); err != nil {
+		return err
+	}
+	return s.backfillAvailableBalances()
 }
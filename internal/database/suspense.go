@@ -0,0 +1,314 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// SuspenseUserId is the ledger account holder for funds awaiting operator resolution
+const SuspenseUserId = "suspense"
+
+// Suspense transaction types
+const (
+	TransactionTypeSuspenseDeposit = "suspense_deposit"
+	TransactionTypeSuspenseRelease = "suspense_release"
+	TransactionTypeSuspenseCredit  = "suspense_credit"
+	TransactionTypeSuspenseReturn  = "suspense_return"
+)
+
+// Suspense entry statuses
+const (
+	SuspenseStatusOpen     = "open"
+	SuspenseStatusCredited = "credited"
+	SuspenseStatusReturned = "returned"
+)
+
+// SuspenseHandler is called when a deposit is moved to suspense so operators can be notified. ctx
+// carries the correlation id of the deposit being processed.
+type SuspenseHandler func(ctx context.Context, entry models.SuspenseEntry)
+
+// logSuspenseEntry is the default operator notification for suspense deposits
+func (s *Service) logSuspenseEntry(ctx context.Context, entry models.SuspenseEntry) {
+	correlation.Logger(ctx, s.logger).Error("ALERT: deposit held in suspense - asset does not match address",
+		zap.String("suspense_id", entry.Id),
+		zap.String("external_tx_id", entry.ExternalTransactionId),
+		zap.String("address", entry.Address),
+		zap.String("address_user_id", entry.AddressUserId),
+		zap.String("expected_asset", entry.ExpectedAsset),
+		zap.String("received_asset", entry.ReceivedAsset),
+		zap.String("amount", entry.Amount.String()))
+}
+
+// SetSuspenseHandler replaces the notification raised when a deposit is moved to suspense
+func (s *Service) SetSuspenseHandler(handler SuspenseHandler) {
+	s.suspenseHandler = handler
+}
+
+func (s *Service) initSuspenseSchema() error {
+	schema := `
+	-- Deposits whose asset did not match the receiving address
+	CREATE TABLE IF NOT EXISTS suspense_entries (
+		id TEXT PRIMARY KEY,
+		external_transaction_id TEXT NOT NULL UNIQUE,
+		address TEXT NOT NULL,
+		address_user_id TEXT NOT NULL,
+		expected_asset TEXT NOT NULL,
+		received_asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'open',
+		resolved_user_id TEXT NOT NULL DEFAULT '',
+		resolution_reference TEXT NOT NULL DEFAULT '',
+		resolution_note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		resolved_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_suspense_entries_status ON suspense_entries(status);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// symbolsMatch reports whether a Prime API symbol refers to the canonical address asset.
+// Prime prefixes some symbols with their network (e.g. "BASEUSDC" for USDC on Base).
+func symbolsMatch(primeSymbol, canonicalSymbol string) bool {
+	prime := strings.ToUpper(primeSymbol)
+	canonical := strings.ToUpper(canonicalSymbol)
+	return prime == canonical || strings.HasSuffix(prime, canonical)
+}
+
+// holdInSuspense credits a mismatched deposit to the suspense account and notifies operators
+func (s *Service) holdInSuspense(ctx context.Context, user *models.User, addr *models.Address, receivedAsset string, amount decimal.Decimal, transactionId string, source models.DepositSource) error {
+	_, err := s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId:          SuspenseUserId,
+		Asset:           receivedAsset,
+		TransactionType: TransactionTypeSuspenseDeposit,
+		Amount:          amount,
+		ExternalTxId:    transactionId,
+		Address:         addr.Address,
+		Reference:       fmt.Sprintf("Expected %s for user %s", addr.Asset, user.Id),
+		SourceType:      source.Type,
+		SourceAddress:   source.Address,
+	})
+	if err != nil {
+		return fmt.Errorf("error crediting suspense account: %w", err)
+	}
+
+	entry := models.SuspenseEntry{
+		Id:                    uuid.New().String(),
+		ExternalTransactionId: transactionId,
+		Address:               addr.Address,
+		AddressUserId:         user.Id,
+		ExpectedAsset:         addr.Asset,
+		ReceivedAsset:         receivedAsset,
+		Amount:                amount,
+		Status:                SuspenseStatusOpen,
+	}
+
+	_, err = s.db.ExecContext(ctx, queryInsertSuspenseEntry, entry.Id, entry.ExternalTransactionId, entry.Address,
+		entry.AddressUserId, entry.ExpectedAsset, entry.ReceivedAsset, entry.Amount.String())
+	if err != nil {
+		return fmt.Errorf("unable to record suspense entry: %w", err)
+	}
+
+	handler := s.suspenseHandler
+	if handler == nil {
+		handler = s.logSuspenseEntry
+	}
+	handler(ctx, entry)
+
+	return fmt.Errorf("%w: received %s at %s address %s", ErrDepositSuspended, receivedAsset, addr.Asset, addr.Address)
+}
+
+// ListSuspenseEntries returns suspense entries, optionally filtered by status
+func (s *Service) ListSuspenseEntries(ctx context.Context, status string) ([]models.SuspenseEntry, error) {
+	query := querySelectSuspenseEntries + " ORDER BY created_at DESC"
+	args := []interface{}{}
+	if status != "" {
+		query = querySelectSuspenseEntries + " WHERE status = ? ORDER BY created_at DESC"
+		args = append(args, status)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query suspense entries: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var entries []models.SuspenseEntry
+	for rows.Next() {
+		entry, err := scanSuspenseEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating suspense entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetSuspenseEntry returns a suspense entry by id
+func (s *Service) GetSuspenseEntry(ctx context.Context, id string) (*models.SuspenseEntry, error) {
+	entry, err := scanSuspenseEntry(s.db.QueryRowContext(ctx, querySelectSuspenseEntries+" WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrSuspenseEntryNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// CreditSuspenseToUser releases a suspense entry to a user's balance in the received asset
+func (s *Service) CreditSuspenseToUser(ctx context.Context, id, userId, note string) error {
+	entry, err := s.openSuspenseEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.GetUserById(ctx, userId); err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	_, err = s.subledger.ProcessTransactions(ctx, []ProcessTransactionParams{
+		{
+			UserId:          SuspenseUserId,
+			Asset:           entry.ReceivedAsset,
+			TransactionType: TransactionTypeSuspenseRelease,
+			Amount:          entry.Amount.Neg(),
+			ExternalTxId:    fmt.Sprintf("suspense:%s:release", entry.Id),
+			Address:         entry.Address,
+			Reference:       fmt.Sprintf("Released to user %s", userId),
+		},
+		{
+			UserId:          userId,
+			Asset:           entry.ReceivedAsset,
+			TransactionType: TransactionTypeSuspenseCredit,
+			Amount:          entry.Amount,
+			ExternalTxId:    fmt.Sprintf("suspense:%s:credit", entry.Id),
+			Address:         entry.Address,
+			Reference:       fmt.Sprintf("Suspense %s", entry.Id),
+		},
+	}, models.BalancePolicySync)
+	if err != nil && !errors.Is(err, ErrDuplicateTransaction) {
+		return fmt.Errorf("error crediting suspense funds: %w", err)
+	}
+
+	return s.markSuspenseResolved(ctx, entry, SuspenseStatusCredited, userId, "", note)
+}
+
+// ReturnSuspenseFunds records that a suspense entry was sent back to its sender.
+// The on-chain return is performed outside the ledger; reference identifies it (e.g. Prime transaction id).
+func (s *Service) ReturnSuspenseFunds(ctx context.Context, id, reference, note string) error {
+	entry, err := s.openSuspenseEntry(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId:          SuspenseUserId,
+		Asset:           entry.ReceivedAsset,
+		TransactionType: TransactionTypeSuspenseReturn,
+		Amount:          entry.Amount.Neg(),
+		ExternalTxId:    fmt.Sprintf("suspense:%s:return", entry.Id),
+		Address:         entry.Address,
+		Reference:       reference,
+	})
+	if err != nil && !errors.Is(err, ErrDuplicateTransaction) {
+		return fmt.Errorf("error recording suspense return: %w", err)
+	}
+
+	return s.markSuspenseResolved(ctx, entry, SuspenseStatusReturned, "", reference, note)
+}
+
+func (s *Service) openSuspenseEntry(ctx context.Context, id string) (*models.SuspenseEntry, error) {
+	entry, err := s.GetSuspenseEntry(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != SuspenseStatusOpen {
+		return nil, fmt.Errorf("suspense entry %s is already %s", id, entry.Status)
+	}
+	return entry, nil
+}
+
+func (s *Service) markSuspenseResolved(ctx context.Context, entry *models.SuspenseEntry, status, userId, reference, note string) error {
+	result, err := s.db.ExecContext(ctx, queryResolveSuspenseEntry, status, userId, reference, note, entry.Id)
+	if err != nil {
+		return fmt.Errorf("unable to resolve suspense entry: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("suspense entry %s was resolved concurrently - %w", entry.Id, ErrConcurrentModification)
+	}
+
+	s.logger.Info("Suspense entry resolved",
+		zap.String("suspense_id", entry.Id),
+		zap.String("status", status),
+		zap.String("received_asset", entry.ReceivedAsset),
+		zap.String("amount", entry.Amount.String()),
+		zap.String("user_id", userId),
+		zap.String("reference", reference))
+
+	return nil
+}
+
+func scanSuspenseEntry(row rowScanner) (*models.SuspenseEntry, error) {
+	var entry models.SuspenseEntry
+	var amountStr string
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&entry.Id, &entry.ExternalTransactionId, &entry.Address, &entry.AddressUserId,
+		&entry.ExpectedAsset, &entry.ReceivedAsset, &amountStr, &entry.Status,
+		&entry.ResolvedUserId, &entry.ResolutionReference, &entry.ResolutionNote, &entry.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	entry.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse suspense amount '%s': %w", amountStr, err)
+	}
+	if resolvedAt.Valid {
+		entry.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &entry, nil
+}
@@ -0,0 +1,114 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSymbolsMatch(t *testing.T) {
+	tests := []struct {
+		prime     string
+		canonical string
+		want      bool
+	}{
+		{"ETH", "ETH", true},
+		{"BASEUSDC", "USDC", true},
+		{"usdc", "USDC", true},
+		{"USDC", "ETH", false},
+		{"ETH", "USDC", false},
+	}
+
+	for _, tt := range tests {
+		if got := symbolsMatch(tt.prime, tt.canonical); got != tt.want {
+			t.Errorf("symbolsMatch(%q, %q) = %v, want %v", tt.prime, tt.canonical, got, tt.want)
+		}
+	}
+}
+
+func TestSuspense_HoldAndReturn(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initSuspenseSchema(); err != nil {
+		t.Fatalf("Failed to create suspense schema: %v", err)
+	}
+
+	var notified []models.SuspenseEntry
+	service.SetSuspenseHandler(func(ctx context.Context, entry models.SuspenseEntry) {
+		notified = append(notified, entry)
+	})
+
+	ctx := context.Background()
+	user := &models.User{Id: "user1"}
+	addr := &models.Address{Address: "0xabc", Asset: "ETH", Network: "ethereum-mainnet"}
+
+	err := service.holdInSuspense(ctx, user, addr, "USDC", decimal.NewFromInt(25), "prime-tx-1", models.DepositSource{})
+	if !errors.Is(err, ErrDepositSuspended) {
+		t.Fatalf("Expected ErrDepositSuspended, got %v", err)
+	}
+	if len(notified) != 1 {
+		t.Fatalf("Expected operators to be notified once, got %d", len(notified))
+	}
+
+	// Nothing is credited to the address owner
+	balance, err := service.GetUserBalance(ctx, "user1", "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance failed: %v", err)
+	}
+	if !balance.IsZero() {
+		t.Errorf("Expected user balance 0, got %s", balance.String())
+	}
+
+	suspense, err := service.GetUserBalance(ctx, SuspenseUserId, "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance for suspense failed: %v", err)
+	}
+	if !suspense.Equal(decimal.NewFromInt(25)) {
+		t.Errorf("Expected suspense balance 25, got %s", suspense.String())
+	}
+
+	entries, err := service.ListSuspenseEntries(ctx, SuspenseStatusOpen)
+	if err != nil {
+		t.Fatalf("ListSuspenseEntries failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 open suspense entry, got %d", len(entries))
+	}
+
+	if err := service.ReturnSuspenseFunds(ctx, entries[0].Id, "prime-return-1", "sent back to sender"); err != nil {
+		t.Fatalf("ReturnSuspenseFunds failed: %v", err)
+	}
+
+	suspense, err = service.GetUserBalance(ctx, SuspenseUserId, "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance for suspense failed: %v", err)
+	}
+	if !suspense.IsZero() {
+		t.Errorf("Expected suspense balance 0 after return, got %s", suspense.String())
+	}
+
+	if err := service.ReturnSuspenseFunds(ctx, entries[0].Id, "prime-return-1", ""); err == nil {
+		t.Error("Expected resolving a closed suspense entry to fail")
+	}
+}
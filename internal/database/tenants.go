@@ -0,0 +1,207 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// DefaultTenantId is the tenant users belong to unless they are assigned to another
+const DefaultTenantId = "default"
+
+var ErrTenantNotFound = errors.New("tenant not found")
+
+func (s *Service) initTenantSchema() error {
+	schema := `
+	-- Business entities served by this deployment, each with its own Prime portfolio
+	CREATE TABLE IF NOT EXISTS tenants (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL,
+		portfolio_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	INSERT OR IGNORE INTO tenants (id, name) VALUES ('` + DefaultTenantId + `', 'Default');
+	`
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	for _, table := range []string{"account_balances", "transactions"} {
+		if err := addColumnIfMissing(s.db, s.logger, table, "tenant_id", "TEXT NOT NULL DEFAULT '"+DefaultTenantId+"'"); err != nil {
+			return err
+		}
+	}
+
+	// Addresses, balances and transactions take their tenant from the owning user, so the
+	// insert paths do not need to know about tenants
+	scoped := `
+	CREATE INDEX IF NOT EXISTS idx_users_tenant ON users(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_addresses_tenant ON addresses(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_account_balances_tenant ON account_balances(tenant_id);
+	CREATE INDEX IF NOT EXISTS idx_transactions_tenant ON transactions(tenant_id);
+
+	CREATE TRIGGER IF NOT EXISTS trg_addresses_tenant AFTER INSERT ON addresses BEGIN
+		UPDATE addresses SET tenant_id = COALESCE((SELECT tenant_id FROM users WHERE id = NEW.user_id), tenant_id)
+		WHERE id = NEW.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS trg_addresses_tenant_reassign AFTER UPDATE OF user_id ON addresses BEGIN
+		UPDATE addresses SET tenant_id = COALESCE((SELECT tenant_id FROM users WHERE id = NEW.user_id), tenant_id)
+		WHERE id = NEW.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS trg_account_balances_tenant AFTER INSERT ON account_balances BEGIN
+		UPDATE account_balances SET tenant_id = COALESCE((SELECT tenant_id FROM users WHERE id = NEW.user_id), tenant_id)
+		WHERE id = NEW.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS trg_transactions_tenant AFTER INSERT ON transactions BEGIN
+		UPDATE transactions SET tenant_id = COALESCE((SELECT tenant_id FROM users WHERE id = NEW.user_id), tenant_id)
+		WHERE id = NEW.id;
+	END;
+	`
+
+	_, err := s.db.Exec(scoped)
+	return err
+}
+
+// ForTenant returns a service whose user and balance lookups only see one tenant's users, and which
+// creates new users in that tenant. The returned service shares the connection pool; set handlers
+// and screeners on it rather than on the unscoped service.
+func (s *Service) ForTenant(tenantId string) *Service {
+	scoped := *s
+	scoped.tenantId = tenantId
+	return &scoped
+}
+
+// TenantId is the tenant the service is scoped to, empty when it sees all tenants
+func (s *Service) TenantId() string {
+	return s.tenantId
+}
+
+func (s *Service) tenantOrDefault() string {
+	if s.tenantId == "" {
+		return DefaultTenantId
+	}
+	return s.tenantId
+}
+
+// CreateTenant registers a tenant. An empty portfolioId keeps its users in the default portfolio.
+func (s *Service) CreateTenant(ctx context.Context, id, name, portfolioId string) (*models.Tenant, error) {
+	if _, err := s.db.ExecContext(ctx, queryInsertTenant, id, name, portfolioId); err != nil {
+		return nil, fmt.Errorf("unable to create tenant: %w", err)
+	}
+
+	s.logger.Info("Tenant created",
+		zap.String("tenant_id", id),
+		zap.String("name", name),
+		zap.String("portfolio_id", portfolioId))
+
+	return s.GetTenant(ctx, id)
+}
+
+// GetTenant returns a tenant, or ErrTenantNotFound
+func (s *Service) GetTenant(ctx context.Context, id string) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := s.db.QueryRowContext(ctx, querySelectTenants+" WHERE id = ?", id).Scan(
+		&tenant.Id, &tenant.Name, &tenant.PortfolioId, &tenant.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrTenantNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to query tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+// ListTenants returns all tenants
+func (s *Service) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	rows, err := s.db.QueryContext(ctx, querySelectTenants+" ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("unable to query tenants: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var tenants []models.Tenant
+	for rows.Next() {
+		var tenant models.Tenant
+		if err := rows.Scan(&tenant.Id, &tenant.Name, &tenant.PortfolioId, &tenant.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// AssignUserTenant moves a user, with their addresses, balances and history, to another tenant
+func (s *Service) AssignUserTenant(ctx context.Context, userId, tenantId string) error {
+	if _, err := s.GetTenant(ctx, tenantId); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Warn("Failed to rollback tenant assignment", zap.Error(err))
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, queryAssignUserTenant, tenantId, userId)
+	if err != nil {
+		return fmt.Errorf("unable to assign tenant: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found: %s", userId)
+	}
+
+	for _, table := range []string{"addresses", "account_balances", "transactions"} {
+		if _, err := tx.ExecContext(ctx, "UPDATE "+table+" SET tenant_id = ? WHERE user_id = ?", tenantId, userId); err != nil {
+			return fmt.Errorf("unable to move %s to tenant: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("unable to commit tenant assignment: %w", err)
+	}
+
+	s.logger.Info("User assigned to tenant", zap.String("user_id", userId), zap.String("tenant_id", tenantId))
+	return nil
+}
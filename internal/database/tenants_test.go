@@ -0,0 +1,85 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTenants_ScopeBalancesAndFollowUsers(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if _, err := service.CreateTenant(ctx, "acme", "Acme Corp", "portfolio-acme"); err != nil {
+		t.Fatalf("CreateTenant failed: %v", err)
+	}
+	if _, err := service.db.Exec("INSERT INTO users (id, name, email, tenant_id) VALUES ('user2', 'Acme User', 'ops@acme.example', 'acme')"); err != nil {
+		t.Fatalf("Failed to insert tenant user: %v", err)
+	}
+
+	for i, userId := range []string{"user1", "user2"} {
+		if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+			UserId:          userId,
+			Asset:           "USDC",
+			TransactionType: "deposit",
+			Amount:          decimal.NewFromInt(int64(10 * (i + 1))),
+			ExternalTxId:    "deposit-" + userId,
+		}); err != nil {
+			t.Fatalf("Failed to credit %s: %v", userId, err)
+		}
+	}
+
+	var tenantId string
+	if err := service.db.QueryRow("SELECT tenant_id FROM transactions WHERE user_id = 'user2'").Scan(&tenantId); err != nil || tenantId != "acme" {
+		t.Fatalf("Expected transactions to take the user's tenant, got %q (%v)", tenantId, err)
+	}
+
+	balances, err := service.ForTenant("acme").ListAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("ListAccountBalances failed: %v", err)
+	}
+	if len(balances) != 1 || balances[0].UserId != "user2" {
+		t.Fatalf("Expected only the acme user's balance, got %+v", balances)
+	}
+
+	all, err := service.ListAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("ListAccountBalances failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Errorf("Expected the unscoped service to see both balances, got %d", len(all))
+	}
+
+	if err := service.AssignUserTenant(ctx, "user1", "acme"); err != nil {
+		t.Fatalf("AssignUserTenant failed: %v", err)
+	}
+	balances, err = service.ForTenant("acme").ListAccountBalances(ctx)
+	if err != nil {
+		t.Fatalf("ListAccountBalances failed: %v", err)
+	}
+	if len(balances) != 2 {
+		t.Errorf("Expected the moved user's balance in acme, got %d balances", len(balances))
+	}
+
+	if err := service.AssignUserTenant(ctx, "user1", "missing"); err == nil {
+		t.Error("Expected assigning an unknown tenant to fail")
+	}
+}
@@ -0,0 +1,122 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"prime-send-receive-go/internal/models"
+)
+
+// Transaction link relations, read as "<entry> <relation> <linked entry>"
+const (
+	LinkReverses    = "reverses"
+	LinkReversedBy  = "reversed_by"
+	LinkRedebits    = "redebits"
+	LinkRedebitedBy = "redebited_by"
+	LinkRefunds     = "refunds"
+	LinkRefundedBy  = "refunded_by"
+)
+
+// transactionLinkQueries pair an original entry with the entry that undoes or redoes it. Each selects the
+// original's id, external id, amount and created_at, then the same for the linked entry, and is completed
+// with a filter on both ids. linkedRelation is how the linked entry relates to the original, and
+// originalRelation the other way round.
+var transactionLinkQueries = []struct {
+	query                            string
+	linkedRelation, originalRelation string
+}{
+	{queryReversalLinks, LinkReverses, LinkReversedBy},
+	{queryRedebitLinks, LinkRedebits, LinkRedebitedBy},
+	{queryRefundLinks, LinkRefunds, LinkRefundedBy},
+}
+
+// GetTransactionLinks returns, by ledger id, the entries linked to each of transactions: reversals and
+// the entries they reverse, withdrawals debited again after a rollback because Prime sent them anyway,
+// and refunds of deposits. Linked entries need not be among transactions.
+func (s *Service) GetTransactionLinks(ctx context.Context, transactions []models.Transaction) (map[string][]models.TransactionLink, error) {
+	links := make(map[string][]models.TransactionLink)
+	if len(transactions) == 0 {
+		return links, nil
+	}
+
+	wanted := make(map[string]bool, len(transactions))
+	args := make([]interface{}, 0, 2*len(transactions))
+	for _, tx := range transactions {
+		wanted[tx.Id] = true
+		args = append(args, tx.Id)
+	}
+	args = append(args, args...)
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(transactions)), ",")
+	filter := " AND (o.id IN (" + placeholders + ") OR t.id IN (" + placeholders + "))"
+
+	for _, link := range transactionLinkQueries {
+		pairs, err := s.queryTransactionLinks(ctx, link.query+filter, args)
+		if err != nil {
+			return nil, err
+		}
+		for _, pair := range pairs {
+			original, linked := pair[0], pair[1]
+			if wanted[original.TransactionId] {
+				linked.Relation = link.originalRelation
+				links[original.TransactionId] = append(links[original.TransactionId], linked)
+			}
+			if wanted[linked.TransactionId] {
+				original.Relation = link.linkedRelation
+				links[linked.TransactionId] = append(links[linked.TransactionId], original)
+			}
+		}
+	}
+	return links, nil
+}
+
+func (s *Service) queryTransactionLinks(ctx context.Context, query string, args []interface{}) ([][2]models.TransactionLink, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query transaction links: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var pairs [][2]models.TransactionLink
+	for rows.Next() {
+		var pair [2]models.TransactionLink
+		var amounts [2]string
+		if err := rows.Scan(&pair[0].TransactionId, &pair[0].ExternalTransactionId, &amounts[0], &pair[0].CreatedAt,
+			&pair[1].TransactionId, &pair[1].ExternalTransactionId, &amounts[1], &pair[1].CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan transaction link: %w", err)
+		}
+		for i := range pair {
+			if pair[i].Amount, err = decimal.NewFromString(amounts[i]); err != nil {
+				return nil, fmt.Errorf("failed to parse linked amount '%s': %w", amounts[i], err)
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction links: %w", err)
+	}
+	return pairs, nil
+}
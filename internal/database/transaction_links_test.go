@@ -0,0 +1,106 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestGetTransactionLinks(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	apply := func(transactionType, externalTxId string, amount int64) *models.Transaction {
+		t.Helper()
+		transaction, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+			UserId: "user1", Asset: "ETH", TransactionType: transactionType, Amount: decimal.NewFromInt(amount), ExternalTxId: externalTxId,
+		})
+		if err != nil {
+			t.Fatalf("Failed to apply %s: %v", externalTxId, err)
+		}
+		return transaction
+	}
+
+	deposit := apply("deposit", "dep-1", 10)
+	// A withdrawal debited before holds, rolled back, then debited again when Prime sent it anyway
+	withdrawal := apply("withdrawal", "wd-1", -2)
+	if err := service.ReverseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(2), "wd-1"); err != nil {
+		t.Fatalf("ReverseWithdrawal failed: %v", err)
+	}
+	reversal, err := service.FindReversal(ctx, "wd-1")
+	if err != nil || reversal == nil {
+		t.Fatalf("Expected a reversal of wd-1, got %v (%v)", reversal, err)
+	}
+	now := time.Now()
+	if _, err := service.db.Exec(queryInsertWithdrawalRequest, "wd-1", "user1", "ETH", "2", "", "0xexternal", now, now); err != nil {
+		t.Fatalf("Failed to record withdrawal request: %v", err)
+	}
+	if _, err := service.AdvanceWithdrawalRequest(ctx, "wd-1", WithdrawalTransition{
+		Status: WithdrawalRequestStatusCompleted, PrimeTransactionId: "prime-1",
+	}); err != nil {
+		t.Fatalf("AdvanceWithdrawalRequest failed: %v", err)
+	}
+	redebit := apply("withdrawal", "prime-1", -2)
+	// A refund of the deposit, debited under its idempotency key
+	if _, err := service.db.Exec(`INSERT INTO refunds (id, deposit_transaction_id, user_id, asset, amount, destination, status, idempotency_key)
+		VALUES ('refund-1', ?, 'user1', 'ETH', '3', '0xsource', 'submitted', 'refund-key')`, deposit.Id); err != nil {
+		t.Fatalf("Failed to record refund: %v", err)
+	}
+	refund := apply("withdrawal", "refund-key", -3)
+	unrelated := apply("deposit", "dep-2", 1)
+
+	links, err := service.GetTransactionLinks(ctx, []models.Transaction{*withdrawal, *reversal, *redebit, *refund, *unrelated})
+	if err != nil {
+		t.Fatalf("GetTransactionLinks failed: %v", err)
+	}
+
+	expect := func(name, transactionId string, want ...string) {
+		t.Helper()
+		var got []string
+		for _, link := range links[transactionId] {
+			got = append(got, link.Relation+" "+link.TransactionId)
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: expected links %v, got %v", name, want, got)
+			return
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("%s: expected link %s, got %s", name, want[i], got[i])
+			}
+		}
+	}
+	expect("withdrawal", withdrawal.Id, LinkReversedBy+" "+reversal.Id, LinkRedebitedBy+" "+redebit.Id)
+	expect("reversal", reversal.Id, LinkReverses+" "+withdrawal.Id)
+	expect("redebit", redebit.Id, LinkRedebits+" "+withdrawal.Id)
+	// The deposit is not in the page, but the refund still links to it
+	expect("refund", refund.Id, LinkRefunds+" "+deposit.Id)
+	expect("unrelated", unrelated.Id)
+	if link := links[reversal.Id][0]; !link.Amount.Equal(decimal.NewFromInt(-2)) || link.ExternalTransactionId != "wd-1" {
+		t.Errorf("Unexpected link %+v", link)
+	}
+}
@@ -0,0 +1,235 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+)
+
+// Ledger entry statuses. Every entry moves the balance when it is applied; the status records whether
+// it has settled. A deposit credited with a hold is pending until the hold is released, and an entry
+// undone by a reversal is reversed, or failed when it was a withdrawal that failed in Prime.
+const (
+	TransactionStatusPending   = "pending"
+	TransactionStatusConfirmed = "confirmed"
+	TransactionStatusReversed  = "reversed"
+	TransactionStatusFailed    = "failed"
+)
+
+// transactionStatusTransitions lists the statuses each status may move to; reversed and failed are final
+var transactionStatusTransitions = map[string][]string{
+	TransactionStatusPending:   {TransactionStatusConfirmed, TransactionStatusReversed, TransactionStatusFailed},
+	TransactionStatusConfirmed: {TransactionStatusReversed, TransactionStatusFailed},
+}
+
+// TransactionStatusFilter selects the entries ListTransactionsByStatus returns; empty fields match all
+type TransactionStatusFilter struct {
+	Status string
+	UserId string
+	Asset  string
+	Limit  int
+}
+
+func (s *SubledgerService) initTransactionStatusSchema() error {
+	var existing int
+	if err := s.db.QueryRow(queryCountTable, "transaction_status_events").Scan(&existing); err != nil {
+		return err
+	}
+
+	schema := `
+	-- Every status change of a ledger entry, oldest first
+	CREATE TABLE IF NOT EXISTS transaction_status_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		transaction_id TEXT NOT NULL,
+		from_status TEXT NOT NULL,
+		to_status TEXT NOT NULL,
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_transaction_status_events_transaction_id ON transaction_status_events(transaction_id);
+	`
+
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	// Entries written before statuses were tracked are all confirmed; settle them from their deposit holds
+	// and reversals once, when the history table is first created
+	for _, backfill := range []string{queryBackfillPendingTransactions, queryBackfillFailedTransactions, queryBackfillReversedTransactions} {
+		if _, err := s.db.Exec(backfill); err != nil {
+			return fmt.Errorf("unable to backfill transaction statuses: %w", err)
+		}
+	}
+	return nil
+}
+
+// UpdateTransactionStatus moves a ledger entry to a new status and records the change. It does not
+// touch balances: the entry was applied when it was written, and reversals are separate entries.
+// Returns ErrInvalidStatusTransition when the entry cannot move from its current status.
+func (s *Service) UpdateTransactionStatus(ctx context.Context, transactionId, status, note string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	from, err := transitionTransaction(ctx, tx, transactionId, status, note)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction status: %w", err)
+	}
+
+	correlation.Logger(ctx, s.logger).Info("Transaction status updated",
+		zap.String("transaction_id", transactionId),
+		zap.String("from_status", from),
+		zap.String("to_status", status),
+		zap.String("note", note))
+	return nil
+}
+
+// ListTransactionStatusEvents returns the status changes of a ledger entry, oldest first
+func (s *Service) ListTransactionStatusEvents(ctx context.Context, transactionId string) ([]models.TransactionStatusEvent, error) {
+	rows, err := s.db.QueryContext(ctx, queryListTransactionStatusEvents, transactionId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query transaction status events: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var events []models.TransactionStatusEvent
+	for rows.Next() {
+		var event models.TransactionStatusEvent
+		if err := rows.Scan(&event.TransactionId, &event.FromStatus, &event.ToStatus, &event.Note, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan transaction status event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction status events: %w", err)
+	}
+	return events, nil
+}
+
+// ListTransactionsByStatus returns ledger entries, newest first, optionally only those in one status
+func (s *Service) ListTransactionsByStatus(ctx context.Context, filter TransactionStatusFilter) ([]models.Transaction, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, queryListTransactionsByStatus, filter.Status, filter.Status,
+		filter.UserId, filter.UserId, filter.Asset, filter.Asset, s.tenantId, s.tenantId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query transactions by status: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var transactions []models.Transaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, *transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transactions: %w", err)
+	}
+	return transactions, nil
+}
+
+// transitionTransaction moves a ledger entry to status within tx and returns the status it moved from
+func transitionTransaction(ctx context.Context, tx *sql.Tx, transactionId, status, note string) (string, error) {
+	var current string
+	err := tx.QueryRowContext(ctx, querySelectTransactionStatus, transactionId).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrTransactionNotFound, transactionId)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to get transaction status: %w", err)
+	}
+	if !canTransitionTransaction(current, status) {
+		return current, fmt.Errorf("%w: transaction %s is %s, cannot become %s", ErrInvalidStatusTransition, transactionId, current, status)
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, queryUpdateTransactionStatus, status, transactionId, current)
+	if err != nil {
+		return "", fmt.Errorf("unable to update transaction status: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return "", fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return "", fmt.Errorf("transaction %s status was updated concurrently - %w", transactionId, ErrConcurrentModification)
+	}
+
+	if _, err := tx.ExecContext(ctx, queryInsertTransactionStatusEvent, transactionId, current, status, note, now); err != nil {
+		return "", fmt.Errorf("unable to record transaction status event: %w", err)
+	}
+	return current, nil
+}
+
+// markReversed moves the entry recorded under originalTxId to status once a reversal of it is written.
+// An original that is not on the ledger, e.g. a withdrawal rolled back before it was debited, is skipped.
+func markReversed(ctx context.Context, tx *sql.Tx, originalTxId, status, note string) error {
+	var transactionId string
+	err := tx.QueryRowContext(ctx, queryFindReversedTransaction, originalTxId).Scan(&transactionId)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to find reversed transaction: %w", err)
+	}
+	_, err = transitionTransaction(ctx, tx, transactionId, status, note)
+	return err
+}
+
+// canTransitionTransaction reports whether a ledger entry may move from one status to another
+func canTransitionTransaction(from, to string) bool {
+	for _, allowed := range transactionStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestTransactionStatus_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	apply := func(params ProcessTransactionParams) *models.Transaction {
+		t.Helper()
+		params.UserId, params.Asset = "user1", "ETH"
+		transaction, err := service.subledger.ProcessTransaction(ctx, params)
+		if err != nil {
+			t.Fatalf("Failed to apply %s: %v", params.ExternalTxId, err)
+		}
+		return transaction
+	}
+	expectStatus := func(transactionId, status string) {
+		t.Helper()
+		var current string
+		if err := service.db.QueryRow(querySelectTransactionStatus, transactionId).Scan(&current); err != nil {
+			t.Fatalf("Failed to read status: %v", err)
+		}
+		if current != status {
+			t.Errorf("Expected %s to be %s, got %s", transactionId, status, current)
+		}
+	}
+
+	// Held deposits are pending until their hold is released
+	held := apply(ProcessTransactionParams{TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-held", HoldReason: "review"})
+	expectStatus(held.Id, TransactionStatusPending)
+	holds, err := service.ListDepositHolds(ctx, DepositHoldStatusHeld)
+	if err != nil || len(holds) != 1 {
+		t.Fatalf("Expected one held deposit, got %+v (%v)", holds, err)
+	}
+	if err := service.ReleaseDepositHold(ctx, holds[0].Id, "reviewed"); err != nil {
+		t.Fatalf("ReleaseDepositHold failed: %v", err)
+	}
+	expectStatus(held.Id, TransactionStatusConfirmed)
+
+	// A withdrawal reversed because it failed in Prime fails; the reversal itself is confirmed
+	withdrawal := apply(ProcessTransactionParams{TransactionType: "withdrawal", Amount: decimal.NewFromInt(-2), ExternalTxId: "wd-1"})
+	expectStatus(withdrawal.Id, TransactionStatusConfirmed)
+	if err := service.ReverseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(2), "wd-1"); err != nil {
+		t.Fatalf("ReverseWithdrawal failed: %v", err)
+	}
+	expectStatus(withdrawal.Id, TransactionStatusFailed)
+	reversal, err := service.FindReversal(ctx, "wd-1")
+	if err != nil || reversal == nil {
+		t.Fatalf("Expected a reversal of wd-1, got %v (%v)", reversal, err)
+	}
+	expectStatus(reversal.Id, TransactionStatusConfirmed)
+
+	// Failed and reversed are final
+	err = service.UpdateTransactionStatus(ctx, withdrawal.Id, TransactionStatusConfirmed, "retry")
+	if !errors.Is(err, ErrInvalidStatusTransition) {
+		t.Errorf("Expected ErrInvalidStatusTransition, got %v", err)
+	}
+	if err := service.UpdateTransactionStatus(ctx, "missing", TransactionStatusReversed, ""); !errors.Is(err, ErrTransactionNotFound) {
+		t.Errorf("Expected ErrTransactionNotFound, got %v", err)
+	}
+	if err := service.UpdateTransactionStatus(ctx, held.Id, TransactionStatusReversed, "charged back"); err != nil {
+		t.Fatalf("UpdateTransactionStatus failed: %v", err)
+	}
+
+	events, err := service.ListTransactionStatusEvents(ctx, held.Id)
+	if err != nil {
+		t.Fatalf("ListTransactionStatusEvents failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ToStatus != TransactionStatusConfirmed || events[1].FromStatus != TransactionStatusConfirmed ||
+		events[1].ToStatus != TransactionStatusReversed || events[1].Note != "charged back" {
+		t.Errorf("Unexpected status events %+v", events)
+	}
+
+	confirmed, err := service.ListTransactionsByStatus(ctx, TransactionStatusFilter{Status: TransactionStatusConfirmed, UserId: "user1"})
+	if err != nil {
+		t.Fatalf("ListTransactionsByStatus failed: %v", err)
+	}
+	if len(confirmed) != 1 || confirmed[0].Id != reversal.Id {
+		t.Errorf("Expected only the reversal to be confirmed, got %+v", confirmed)
+	}
+	if all, err := service.ListTransactionsByStatus(ctx, TransactionStatusFilter{Limit: 2}); err != nil || len(all) != 2 {
+		t.Errorf("Expected two entries with a limit of 2, got %d (%v)", len(all), err)
+	}
+
+	// Statuses do not change balances, so reconciliation still sums every entry
+	if err := service.ReconcileUserBalance(ctx, "user1", "ETH"); err != nil {
+		t.Errorf("ReconcileUserBalance failed: %v", err)
+	}
+
+	if _, err := service.db.Exec("DROP TABLE transaction_status_events"); err != nil {
+		t.Fatalf("Failed to drop table: %v", err)
+	}
+	if _, err := service.db.Exec("UPDATE transactions SET status = 'confirmed'"); err != nil {
+		t.Fatalf("Failed to reset statuses: %v", err)
+	}
+	// Databases from before statuses were tracked are backfilled when the history table is created
+	if err := service.subledger.initTransactionStatusSchema(); err != nil {
+		t.Fatalf("initTransactionStatusSchema failed: %v", err)
+	}
+	expectStatus(withdrawal.Id, TransactionStatusFailed)
+	expectStatus(held.Id, TransactionStatusConfirmed)
+	expectStatus(reversal.Id, TransactionStatusConfirmed)
+}
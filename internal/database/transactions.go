@@ -25,6 +25,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/models"
 )
 
@@ -37,184 +38,320 @@ type ProcessTransactionParams struct {
 	ExternalTxId    string
 	Address         string
 	Reference       string
+	// SourceType and SourceAddress record where a deposit came from (Prime's transfer_from)
+	SourceType    string
+	SourceAddress string
+	// HoldReason, when set, records a deposit hold with the credit so the amount is not available until released.
+	// HoldKind is DepositHoldKindReview (operator release, the default) or DepositHoldKindSettlement.
+	HoldReason string
+	HoldKind   string
+	// Reserved marks a debit whose amount a withdrawal hold already took out of the available balance,
+	// so only the total balance changes
+	Reserved bool
+	// ReversalOf links a reversal to the external id of the transaction it undoes. Only one reversal
+	// per original transaction is accepted.
+	ReversalOf string
+	// ReversedStatus is the status the undone transaction moves to, TransactionStatusReversed by default
+	ReversedStatus string
 }
 
-// ProcessTransaction atomically updates balance and records transaction
+// ProcessTransaction atomically updates balance and records transaction.
+// It applies the sync policy: negative balances are allowed (historical syncs) but flagged.
 func (s *SubledgerService) ProcessTransaction(ctx context.Context, params ProcessTransactionParams) (*models.Transaction, error) {
+	return s.ProcessTransactionWithPolicy(ctx, params, models.BalancePolicySync)
+}
+
+// ProcessTransactionWithPolicy atomically updates balance and records transaction,
+// enforcing the given negative-balance policy
+func (s *SubledgerService) ProcessTransactionWithPolicy(ctx context.Context, params ProcessTransactionParams, policy models.BalancePolicy) (*models.Transaction, error) {
+	transactions, err := s.ProcessTransactions(ctx, []ProcessTransactionParams{params}, policy)
+	if err != nil {
+		return nil, err
+	}
+	return transactions[0], nil
+}
+
+// ProcessTransactions applies several balance changes in a single database transaction.
+// Either every leg is recorded or none is, which keeps multi-account moves balanced.
+func (s *SubledgerService) ProcessTransactions(ctx context.Context, legs []ProcessTransactionParams, policy models.BalancePolicy) ([]*models.Transaction, error) {
+	// Start database transaction for atomicity
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	transactions := make([]*models.Transaction, 0, len(legs))
+	var negativeEvents []models.NegativeBalanceEvent
+	for _, params := range legs {
+		transaction, negativeEvent, err := s.applyTransaction(ctx, tx, params, policy)
+		if err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, transaction)
+		if negativeEvent != nil {
+			negativeEvents = append(negativeEvents, *negativeEvent)
+		}
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	for _, transaction := range transactions {
+		s.notifyBalanceChange(transaction.UserId, transaction.Asset)
+	}
+	for _, event := range negativeEvents {
+		s.negativeBalanceHandler(event)
+	}
+
+	return transactions, nil
+}
 
-	zap.L().Info("Processing transaction",
+// applyTransaction records one balance change within an open database transaction
+func (s *SubledgerService) applyTransaction(ctx context.Context, tx *sql.Tx, params ProcessTransactionParams, policy models.BalancePolicy) (*models.Transaction, *models.NegativeBalanceEvent, error) {
+	correlation.Logger(ctx, s.logger).Info("Processing transaction",
 		zap.String("user_id", params.UserId),
 		zap.String("asset_network", params.Asset),
 		zap.String("type", params.TransactionType),
 		zap.String("amount", params.Amount.String()),
-		zap.String("external_tx_id", params.ExternalTxId))
+		zap.String("external_tx_id", params.ExternalTxId),
+		zap.String("policy", string(policy)))
 
 	// Check for duplicate external transaction Id
 	if params.ExternalTxId != "" {
 		var existingTxId string
-		err := s.db.QueryRowContext(ctx, queryCheckDuplicateTransaction, params.ExternalTxId).Scan(&existingTxId)
+		err := tx.QueryRowContext(ctx, queryCheckDuplicateTransaction, params.ExternalTxId).Scan(&existingTxId)
 		if err == nil {
-			zap.L().Warn("Duplicate external transaction Id detected, skipping",
+			correlation.Logger(ctx, s.logger).Warn("Duplicate external transaction Id detected, skipping",
 				zap.String("external_tx_id", params.ExternalTxId),
 				zap.String("existing_internal_tx_id", existingTxId))
-			return nil, fmt.Errorf("%w: external_transaction_id %s already exists", ErrDuplicateTransaction, params.ExternalTxId)
+			return nil, nil, fmt.Errorf("%w: external_transaction_id %s already exists", ErrDuplicateTransaction, params.ExternalTxId)
 		} else if err != sql.ErrNoRows {
-			return nil, fmt.Errorf("failed to check for duplicate transaction: %w", err)
+			return nil, nil, fmt.Errorf("failed to check for duplicate transaction: %w", err)
 		}
 	}
 
-	// Start database transaction for atomicity
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	// Reversals recorded under an older external id format are found through their linkage
+	if params.ReversalOf != "" {
+		var existingTxId string
+		err := tx.QueryRowContext(ctx, queryCheckDuplicateReversal, params.ReversalOf).Scan(&existingTxId)
+		if err == nil {
+			correlation.Logger(ctx, s.logger).Warn("Transaction already reversed, skipping",
+				zap.String("reversal_of", params.ReversalOf),
+				zap.String("existing_internal_tx_id", existingTxId))
+			return nil, nil, fmt.Errorf("%w: %s is already reversed", ErrDuplicateTransaction, params.ReversalOf)
+		} else if err != sql.ErrNoRows {
+			return nil, nil, fmt.Errorf("failed to check for duplicate reversal: %w", err)
+		}
 	}
-	defer tx.Rollback()
 
 	// Get current balance (with row locking)
 	var currentBalanceStr string
+	var availableStr sql.NullString
 	var accountId string
 	var version int64
 
-	err = tx.QueryRowContext(ctx, queryGetAccountBalance, params.UserId, params.Asset).Scan(&accountId, &currentBalanceStr, &version)
+	err := tx.QueryRowContext(ctx, queryGetAccountBalance, params.UserId, params.Asset).Scan(&accountId, &currentBalanceStr, &availableStr, &version)
 
-	var currentBalance decimal.Decimal
+	var currentBalance, currentAvailable decimal.Decimal
 	if err == sql.ErrNoRows {
 		// Create new account balance record
 		accountId = uuid.New().String()
 		currentBalance = decimal.Zero
+		currentAvailable = decimal.Zero
 		version = 1
 
-		_, err = tx.ExecContext(ctx, queryInsertAccountBalance, accountId, params.UserId, params.Asset, "0", 1)
+		_, err = tx.ExecContext(ctx, queryInsertAccountBalance, accountId, params.UserId, params.Asset, "0", "0", 1)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create account balance: %w", err)
+			return nil, nil, fmt.Errorf("failed to create account balance: %w", err)
 		}
 	} else if err != nil {
-		return nil, fmt.Errorf("failed to get current balance: %w", err)
+		return nil, nil, fmt.Errorf("failed to get current balance: %w", err)
 	} else {
 		currentBalance, err = decimal.NewFromString(currentBalanceStr)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse current balance '%s': %w", currentBalanceStr, err)
+			return nil, nil, fmt.Errorf("failed to parse current balance '%s': %w", currentBalanceStr, err)
+		}
+		currentAvailable, err = parseAvailable(availableStr, currentBalance)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
 
-	// Calculate new balance
+	// Calculate new balance; held deposits add to the total but not to what is available, and
+	// captured withdrawals were taken out of what is available when they were reserved
 	newBalance := currentBalance.Add(params.Amount)
+	newAvailable := currentAvailable
+	if params.HoldReason == "" && !params.Reserved {
+		newAvailable = currentAvailable.Add(params.Amount)
+	}
+
+	// Debits that leave the account below zero are rejected for customer operations and flagged for syncs
+	goesNegative := newBalance.IsNegative() && params.Amount.IsNegative()
+	if goesNegative && policy == models.BalancePolicyCustomer {
+		return nil, nil, fmt.Errorf("%w: balance=%s, requested=%s, shortfall=%s",
+			ErrInsufficientBalance, currentBalance.String(), params.Amount.Neg().String(), newBalance.Neg().String())
+	}
+
+	// Customer debits may only spend the available balance
+	if policy == models.BalancePolicyCustomer && params.Amount.IsNegative() && newAvailable.IsNegative() {
+		return nil, nil, fmt.Errorf("%w: balance=%s, available=%s, requested=%s",
+			ErrInsufficientBalance, currentBalance.String(), currentAvailable.String(), params.Amount.Neg().String())
+	}
 
-	// Create transaction record
-	transactionId := uuid.New().String()
+	// Create transaction record; held deposits stay pending until their hold is released
+	transactionId := newTimeOrderedId()
 	now := time.Now()
 	transaction := &models.Transaction{}
+	status := TransactionStatusConfirmed
+	if params.HoldReason != "" {
+		status = TransactionStatusPending
+	}
 
 	var amountStr, balanceBeforeStr, balanceAfterStr string
 	err = tx.QueryRowContext(ctx, queryInsertTransaction,
 		transactionId, params.UserId, params.Asset, params.TransactionType,
 		params.Amount.String(), currentBalance.String(), newBalance.String(),
-		params.ExternalTxId, params.Address, params.Reference, "confirmed", now, now).
+		params.ExternalTxId, params.Address, params.Reference, status, now, now,
+		params.SourceType, params.SourceAddress, params.ReversalOf).
 		Scan(&transaction.Id, &transaction.UserId, &transaction.Asset, &transaction.TransactionType,
 			&amountStr, &balanceBeforeStr, &balanceAfterStr,
 			&transaction.ExternalTransactionId, &transaction.Address, &transaction.Reference,
-			&transaction.Status, &transaction.CreatedAt, &transaction.ProcessedAt)
+			&transaction.Status, &transaction.CreatedAt, &transaction.ProcessedAt,
+			&transaction.SourceType, &transaction.SourceAddress, &transaction.ReversalOf)
 	if err != nil {
-		return nil, fmt.Errorf("failed to insert transaction: %w", err)
+		return nil, nil, fmt.Errorf("failed to insert transaction: %w", err)
 	}
 
 	transaction.Amount, err = decimal.NewFromString(amountStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse returned amount: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse returned amount: %w", err)
 	}
 	transaction.BalanceBefore, err = decimal.NewFromString(balanceBeforeStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse returned balance_before: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse returned balance_before: %w", err)
 	}
 	transaction.BalanceAfter, err = decimal.NewFromString(balanceAfterStr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse returned balance_after: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse returned balance_after: %w", err)
 	}
 
 	// Update account balance (with optimistic locking)
-	result, err := tx.ExecContext(ctx, queryUpdateAccountBalance, newBalance.String(), transactionId, params.UserId, params.Asset, version)
+	result, err := tx.ExecContext(ctx, queryUpdateAccountBalance, newBalance.String(), newAvailable.String(), transactionId, params.UserId, params.Asset, version)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update balance: %w", err)
+		return nil, nil, fmt.Errorf("failed to update balance: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return nil, fmt.Errorf("failed to check rows affected: %w", err)
+		return nil, nil, fmt.Errorf("failed to check rows affected: %w", err)
 	}
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("balance update failed - %w", ErrConcurrentModification)
+		return nil, nil, fmt.Errorf("balance update failed - %w", ErrConcurrentModification)
 	}
 
 	// Optional: Add double-entry journal entries
 	if err := s.addJournalEntries(ctx, tx, transaction); err != nil {
-		return nil, fmt.Errorf("failed to add journal entries: %w", err)
+		return nil, nil, fmt.Errorf("failed to add journal entries: %w", err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit(); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	if params.HoldReason != "" {
+		if err := s.insertDepositHold(ctx, tx, transaction, params.HoldKind, params.HoldReason); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if params.ReversalOf != "" {
+		reversedStatus := params.ReversedStatus
+		if reversedStatus == "" {
+			reversedStatus = TransactionStatusReversed
+		}
+		if err := markReversed(ctx, tx, params.ReversalOf, reversedStatus, "reversed by "+transaction.Id); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	zap.L().Info("Transaction processed successfully",
+	var negativeEvent *models.NegativeBalanceEvent
+	if goesNegative {
+		negativeEvent, err = s.flagNegativeBalance(ctx, tx, transaction, policy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to flag negative balance: %w", err)
+		}
+	}
+
+	correlation.Logger(ctx, s.logger).Info("Transaction processed successfully",
 		zap.String("transaction_id", transactionId),
 		zap.String("user_id", params.UserId),
 		zap.String("asset_network", params.Asset),
 		zap.String("old_balance", currentBalance.String()),
 		zap.String("new_balance", newBalance.String()))
 
-	return transaction, nil
+	return transaction, negativeEvent, nil
 }
 
-// addJournalEntries creates double-entry bookkeeping entries
+// addJournalEntries creates double-entry bookkeeping entries against the configured chart of accounts
 func (s *SubledgerService) addJournalEntries(ctx context.Context, tx *sql.Tx, transaction *models.Transaction) error {
-	// For a deposit: Debit user asset account, Credit system liability account
-	// For a withdrawal: Credit user asset account, Debit system liability account
+	// For a deposit: Debit user asset account, Credit customer liability account
+	// For a withdrawal: Credit user asset account, Debit customer liability account
+	chart := s.chart
+	amount := transaction.Amount
 
-	journalEntries := []struct {
-		accountType  string
-		accountId    string
-		debitAmount  decimal.Decimal
-		creditAmount decimal.Decimal
-	}{}
+	var journalEntries []journalEntry
 
 	switch transaction.TransactionType {
 	case "deposit":
-		// User asset account increases (debit)
-		journalEntries = append(journalEntries, struct {
-			accountType  string
-			accountId    string
-			debitAmount  decimal.Decimal
-			creditAmount decimal.Decimal
-		}{"user_asset", fmt.Sprintf("%s_%s", transaction.UserId, transaction.Asset), transaction.Amount, decimal.Zero})
-
-		// System liability increases (credit) - we owe the user this amount
-		journalEntries = append(journalEntries, struct {
-			accountType  string
-			accountId    string
-			debitAmount  decimal.Decimal
-			creditAmount decimal.Decimal
-		}{"system_liability", fmt.Sprintf("user_deposits_%s", transaction.Asset), decimal.Zero, transaction.Amount})
+		// User asset account increases (debit), liability increases (credit) - we owe the user this amount
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.UserAsset, transaction, amount, decimal.Zero),
+			newJournalEntry(chart.CustomerLiability, transaction, decimal.Zero, amount))
 
 	case "withdrawal":
-		// User asset account decreases (credit)
-		journalEntries = append(journalEntries, struct {
-			accountType  string
-			accountId    string
-			debitAmount  decimal.Decimal
-			creditAmount decimal.Decimal
-		}{"user_asset", fmt.Sprintf("%s_%s", transaction.UserId, transaction.Asset), decimal.Zero, transaction.Amount.Neg()})
-
-		// System liability decreases (debit) - we no longer owe the user this amount
-		journalEntries = append(journalEntries, struct {
-			accountType  string
-			accountId    string
-			debitAmount  decimal.Decimal
-			creditAmount decimal.Decimal
-		}{"system_liability", fmt.Sprintf("user_deposits_%s", transaction.Asset), transaction.Amount.Neg(), decimal.Zero})
+		// User asset account decreases (credit), liability decreases (debit) - we no longer owe the user this amount
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.UserAsset, transaction, decimal.Zero, amount.Neg()),
+			newJournalEntry(chart.CustomerLiability, transaction, amount.Neg(), decimal.Zero))
+
+	case TransactionTypeReward:
+		// Rewards funding is drawn down (credit) - promotional credits are funded by the operator, not by deposits
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.UserAsset, transaction, amount, decimal.Zero),
+			newJournalEntry(chart.Rewards, transaction, decimal.Zero, amount))
+
+	case TransactionTypeInterest:
+		// Interest payable is funded by the operator (credit)
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.UserAsset, transaction, amount, decimal.Zero),
+			newJournalEntry(chart.Interest, transaction, decimal.Zero, amount))
+
+	case TransactionTypeSuspenseDeposit:
+		// Unmatched funds are held in suspense (debit) against the deposit liability (credit)
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.Suspense, transaction, amount, decimal.Zero),
+			newJournalEntry(chart.CustomerLiability, transaction, decimal.Zero, amount))
+
+	case TransactionTypeSuspenseRelease:
+		// Suspense decreases (credit) and moves through the clearing account (debit)
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.Suspense, transaction, decimal.Zero, amount.Neg()),
+			newJournalEntry(chart.SuspenseClearing, transaction, amount.Neg(), decimal.Zero))
+
+	case TransactionTypeSuspenseCredit:
+		// User asset account increases (debit) out of the clearing account (credit)
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.UserAsset, transaction, amount, decimal.Zero),
+			newJournalEntry(chart.SuspenseClearing, transaction, decimal.Zero, amount))
+
+	case TransactionTypeSuspenseReturn:
+		// Funds sent back to the sender: suspense decreases (credit), deposit liability decreases (debit)
+		journalEntries = append(journalEntries,
+			newJournalEntry(chart.Suspense, transaction, decimal.Zero, amount.Neg()),
+			newJournalEntry(chart.CustomerLiability, transaction, amount.Neg(), decimal.Zero))
 	}
 
 	for _, entry := range journalEntries {
-		entryId := uuid.New().String()
+		entryId := newTimeOrderedId()
 		_, err := tx.ExecContext(ctx, queryInsertJournalEntry,
 			entryId, transaction.Id, entry.accountType, entry.accountId, entry.debitAmount.String(), entry.creditAmount.String())
 		if err != nil {
@@ -225,63 +362,85 @@ func (s *SubledgerService) addJournalEntries(ctx context.Context, tx *sql.Tx, tr
 	return nil
 }
 
-// GetTransactionHistory returns paginated transaction history for a user
-func (s *SubledgerService) GetTransactionHistory(ctx context.Context, userId, asset string, limit, offset int) ([]models.Transaction, error) {
-	zap.L().Debug("Getting transaction history",
+// GetTransactionHistory returns up to limit of a user's transactions in an asset, newest first. Pages are
+// keyset paginated: before is the id of the last transaction of the previous page, or empty for the
+// newest page. An unknown id returns an empty page.
+func (s *SubledgerService) GetTransactionHistory(ctx context.Context, userId, asset string, limit int, before string) ([]models.Transaction, error) {
+	s.logger.Debug("Getting transaction history",
 		zap.String("user_id", userId),
 		zap.String("asset_network", asset),
 		zap.Int("limit", limit),
-		zap.Int("offset", offset))
+		zap.String("before", before))
 
-	rows, err := s.db.QueryContext(ctx, queryGetTransactionHistory, userId, asset, limit, offset)
+	rows, err := s.db.QueryContext(ctx, queryGetTransactionHistory, userId, asset, before, before, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction history: %w", err)
 	}
 	defer func(rows *sql.Rows) {
 		if err := rows.Close(); err != nil {
-			zap.L().Warn("Failed to close rows", zap.Error(err))
+			s.logger.Warn("Failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
 	var transactions []models.Transaction
 	for rows.Next() {
-		var tx models.Transaction
-		var amountStr, balanceBeforeStr, balanceAfterStr string
-		err := rows.Scan(&tx.Id, &tx.UserId, &tx.Asset, &tx.TransactionType,
-			&amountStr, &balanceBeforeStr, &balanceAfterStr,
-			&tx.ExternalTransactionId, &tx.Address, &tx.Reference,
-			&tx.Status, &tx.CreatedAt, &tx.ProcessedAt)
+		tx, err := scanTransaction(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan transaction: %w", err)
 		}
-
-		tx.Amount, err = decimal.NewFromString(amountStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
-		}
-
-		tx.BalanceBefore, err = decimal.NewFromString(balanceBeforeStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse balance before '%s': %w", balanceBeforeStr, err)
-		}
-
-		tx.BalanceAfter, err = decimal.NewFromString(balanceAfterStr)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse balance after '%s': %w", balanceAfterStr, err)
-		}
-
-		transactions = append(transactions, tx)
+		transactions = append(transactions, *tx)
 	}
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		zap.L().Error("Error during transaction row iteration", zap.Error(err))
+		s.logger.Error("Error during transaction row iteration", zap.Error(err))
 		return nil, fmt.Errorf("error iterating transaction rows: %w", err)
 	}
 
 	return transactions, nil
 }
 
+// newTimeOrderedId returns a UUIDv7, whose leading timestamp keeps new ledger rows in insertion order
+// within an index; it falls back to a random UUID if the clock cannot be read
+func newTimeOrderedId() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.New().String()
+	}
+	return id.String()
+}
+
+// scanTransaction scans a transactions row selected with the columns of queryGetTransactionHistory
+func scanTransaction(row rowScanner) (*models.Transaction, error) {
+	var tx models.Transaction
+	var amountStr, balanceBeforeStr, balanceAfterStr string
+	err := row.Scan(&tx.Id, &tx.UserId, &tx.Asset, &tx.TransactionType,
+		&amountStr, &balanceBeforeStr, &balanceAfterStr,
+		&tx.ExternalTransactionId, &tx.Address, &tx.Reference,
+		&tx.Status, &tx.CreatedAt, &tx.ProcessedAt,
+		&tx.SourceType, &tx.SourceAddress, &tx.ReversalOf)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+	}
+
+	tx.BalanceBefore, err = decimal.NewFromString(balanceBeforeStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse balance before '%s': %w", balanceBeforeStr, err)
+	}
+
+	tx.BalanceAfter, err = decimal.NewFromString(balanceAfterStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse balance after '%s': %w", balanceAfterStr, err)
+	}
+
+	return &tx, nil
+}
+
 // GetMostRecentTransactionTime returns the most recent transaction timestamp for recovery
 func (s *SubledgerService) GetMostRecentTransactionTime(ctx context.Context) (time.Time, error) {
 	var timestampStr sql.NullString
@@ -295,19 +454,24 @@ func (s *SubledgerService) GetMostRecentTransactionTime(ctx context.Context) (ti
 		return time.Now().Add(-2 * time.Hour), nil
 	}
 
-	// Parse the timestamp string - SQLite stores it with space instead of T
+	return parseSQLiteTimestamp(timestampStr.String)
+}
+
+// parseSQLiteTimestamp parses a TIMESTAMP value returned by an aggregate such as MAX(created_at)
+func parseSQLiteTimestamp(value string) (time.Time, error) {
+	// SQLite stores it with space instead of T
 	// First try SQLite's TIMESTAMP format: "2006-01-02 15:04:05.999999-07:00"
-	parsedTime, err := time.Parse("2006-01-02 15:04:05.999999-07:00", timestampStr.String)
+	parsedTime, err := time.Parse("2006-01-02 15:04:05.999999-07:00", value)
 	if err != nil {
 		// Try without microseconds: "2006-01-02 15:04:05-07:00"
-		parsedTime, err = time.Parse("2006-01-02 15:04:05-07:00", timestampStr.String)
+		parsedTime, err = time.Parse("2006-01-02 15:04:05-07:00", value)
 		if err != nil {
 			// Try RFC3339 format as fallback
-			parsedTime, err = time.Parse(time.RFC3339Nano, timestampStr.String)
+			parsedTime, err = time.Parse(time.RFC3339Nano, value)
 			if err != nil {
-				parsedTime, err = time.Parse(time.RFC3339, timestampStr.String)
+				parsedTime, err = time.Parse(time.RFC3339, value)
 				if err != nil {
-					return time.Time{}, fmt.Errorf("failed to parse timestamp %q: %w", timestampStr.String, err)
+					return time.Time{}, fmt.Errorf("failed to parse timestamp %q: %w", value, err)
 				}
 			}
 		}
@@ -315,3 +479,18 @@ func (s *SubledgerService) GetMostRecentTransactionTime(ctx context.Context) (ti
 
 	return parsedTime, nil
 }
+
+// createdAtSlack is how far a created_at range query reaches past its bounds. created_at is stored as
+// text with the writer's UTC offset, so comparing it in SQL can be off by up to a day; callers query the
+// widened range and apply the exact bounds to the parsed times.
+const createdAtSlack = 24 * time.Hour
+
+// createdAtFrom returns the value to compare created_at against for rows created at or after from
+func createdAtFrom(from time.Time) time.Time {
+	return from.Add(-createdAtSlack)
+}
+
+// createdAtTo returns the value to compare created_at against for rows created at or before to
+func createdAtTo(to time.Time) time.Time {
+	return to.Add(createdAtSlack)
+}
@@ -18,32 +18,25 @@ package database
 
 import (
 	"context"
-	"database/sql"
 	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
 )
 
+// setupTestDb opens an in-memory database with the full schema and returns its subledger
 func setupTestDb(t *testing.T) (*SubledgerService, func()) {
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		t.Fatalf("Failed to open test database: %v", err)
-	}
-
-	service := NewSubledgerService(db)
-
-	// Use the actual schema initialization
-	if err := service.InitSchema(); err != nil {
-		t.Fatalf("Failed to create test schema: %v", err)
-	}
-
-	cleanup := func() {
-		db.Close()
-	}
-
-	return service, cleanup
+	service := openTestDB(t, models.DatabaseConfig{})
+	return service.subledger, service.Close
 }
 
 func TestProcessTransaction_Deposit(t *testing.T) {
@@ -56,7 +49,7 @@ func TestProcessTransaction_Deposit(t *testing.T) {
 	amount := decimal.NewFromFloat(1.5)
 
 	// Process deposit
-	result, err := service.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "deposit", amount, "tx1", "addr1", "memo1"})
+	result, err := service.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "deposit", Amount: amount, ExternalTxId: "tx1", Address: "addr1", Reference: "memo1"})
 	if err != nil {
 		t.Fatalf("ProcessTransaction failed: %v", err)
 	}
@@ -86,14 +79,14 @@ func TestProcessTransaction_Withdrawal(t *testing.T) {
 
 	// First, make a deposit
 	depositAmount := decimal.NewFromFloat(2.0)
-	_, err := service.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "deposit", depositAmount, "tx1", "addr1", ""})
+	_, err := service.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "deposit", Amount: depositAmount, ExternalTxId: "tx1", Address: "addr1"})
 	if err != nil {
 		t.Fatalf("Initial deposit failed: %v", err)
 	}
 
 	// Now process withdrawal (should be negative amount)
 	withdrawalAmount := decimal.NewFromFloat(-0.5)
-	result, err := service.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "withdrawal", withdrawalAmount, "tx2", "", ""})
+	result, err := service.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "withdrawal", Amount: withdrawalAmount, ExternalTxId: "tx2"})
 	if err != nil {
 		t.Fatalf("ProcessTransaction withdrawal failed: %v", err)
 	}
@@ -116,23 +109,65 @@ func TestProcessTransaction_DuplicateHandling(t *testing.T) {
 	txId := "duplicate-tx"
 
 	// Process transaction first time
-	_, err := service.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "deposit", amount, txId, "addr1", ""})
+	_, err := service.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "deposit", Amount: amount, ExternalTxId: txId, Address: "addr1"})
 	if err != nil {
 		t.Fatalf("First ProcessTransaction failed: %v", err)
 	}
 
 	// Process same transaction again - should return error for duplicate
-	_, err = service.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "deposit", amount, txId, "addr1", ""})
+	_, err = service.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "deposit", Amount: amount, ExternalTxId: txId, Address: "addr1"})
 	if err == nil {
 		t.Fatalf("Expected duplicate transaction error, got nil")
 	}
 
-	// Should contain "duplicate transaction" in error message
+	// Should match the ErrDuplicateTransaction sentinel
 	if !errors.Is(err, ErrDuplicateTransaction) {
 		t.Errorf("Expected duplicate transaction error, got: %v", err)
 	}
 }
 
+func TestReverseWithdrawal_LinksReversals(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	amount := decimal.NewFromInt(5)
+
+	// A reversal recorded by an older version, before reversals were linked
+	_, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: amount, ExternalTxId: "legacy-key-reversal"})
+	if err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+	if err := service.subledger.InitSchema(); err != nil {
+		t.Fatalf("InitSchema failed: %v", err)
+	}
+
+	if err := service.ReverseWithdrawal(ctx, "user1", "ETH", amount, "legacy-key"); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("Expected a legacy reversal to block a second one, got %v", err)
+	}
+
+	if err := service.ReverseWithdrawal(ctx, "user1", "ETH", amount, "user1-key"); err != nil {
+		t.Fatalf("ReverseWithdrawal failed: %v", err)
+	}
+	if err := service.ReverseWithdrawal(ctx, "user1", "ETH", amount, "user1-key"); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("Expected a second reversal to be a duplicate, got %v", err)
+	}
+
+	reversal, err := service.FindReversal(ctx, "user1-key")
+	if err != nil {
+		t.Fatalf("FindReversal failed: %v", err)
+	}
+	if reversal == nil || reversal.ExternalTransactionId != ReversalExternalTxId("user1-key") || reversal.ReversalOf != "user1-key" {
+		t.Errorf("Unexpected reversal: %+v", reversal)
+	}
+
+	// The Prime transaction the listener later records under the original key is not mistaken for it
+	reversal, err = service.FindReversal(ctx, "prime-tx-1")
+	if err != nil || reversal != nil {
+		t.Errorf("Expected no reversal for an unrelated transaction, got %+v, %v", reversal, err)
+	}
+}
+
 func TestProcessTransaction_NegativeBalanceAllowed(t *testing.T) {
 	service, cleanup := setupTestDb(t)
 	defer cleanup()
@@ -143,7 +178,7 @@ func TestProcessTransaction_NegativeBalanceAllowed(t *testing.T) {
 
 	// Process withdrawal from zero balance (should be allowed for historical transactions)
 	withdrawalAmount := decimal.NewFromFloat(-1.0)
-	result, err := service.ProcessTransaction(ctx, ProcessTransactionParams{userId, asset, "withdrawal", withdrawalAmount, "tx1", "", ""})
+	result, err := service.ProcessTransaction(ctx, ProcessTransactionParams{UserId: userId, Asset: asset, TransactionType: "withdrawal", Amount: withdrawalAmount, ExternalTxId: "tx1"})
 	if err != nil {
 		t.Fatalf("ProcessTransaction with negative balance failed: %v", err)
 	}
@@ -153,3 +188,188 @@ func TestProcessTransaction_NegativeBalanceAllowed(t *testing.T) {
 		t.Errorf("Expected negative balance %s, got %s", withdrawalAmount.String(), result.BalanceAfter.String())
 	}
 }
+
+func TestProcessTransactionWithPolicy_NegativeBalance(t *testing.T) {
+	service, cleanup := setupTestDb(t)
+	defer cleanup()
+
+	var alerts []models.NegativeBalanceEvent
+	service.negativeBalanceHandler = func(event models.NegativeBalanceEvent) {
+		alerts = append(alerts, event)
+	}
+
+	ctx := context.Background()
+	withdrawal := ProcessTransactionParams{UserId: "user1", Asset: "BTC", TransactionType: "withdrawal", Amount: decimal.NewFromFloat(-1.0), ExternalTxId: "tx1"}
+
+	// Customer operations must not overdraw the account
+	_, err := service.ProcessTransactionWithPolicy(ctx, withdrawal, models.BalancePolicyCustomer)
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("Expected ErrInsufficientBalance, got %v", err)
+	}
+	if len(alerts) != 0 {
+		t.Fatalf("Expected no alert for rejected transaction, got %d", len(alerts))
+	}
+
+	// Sync operations are applied but flagged and alerted
+	result, err := service.ProcessTransactionWithPolicy(ctx, withdrawal, models.BalancePolicySync)
+	if err != nil {
+		t.Fatalf("Sync withdrawal failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].TransactionId != result.Id {
+		t.Fatalf("Expected one alert for transaction %s, got %+v", result.Id, alerts)
+	}
+
+	var flagged int
+	if err := service.db.QueryRow("SELECT COUNT(*) FROM negative_balance_events").Scan(&flagged); err != nil {
+		t.Fatalf("Failed to count negative balance events: %v", err)
+	}
+	if flagged != 1 {
+		t.Errorf("Expected 1 negative balance event, got %d", flagged)
+	}
+}
+
+func TestProcessTransaction_RecordsDepositSource(t *testing.T) {
+	service, cleanup := setupTestDb(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_, err := service.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId:          "user1",
+		Asset:           "BTC",
+		TransactionType: "deposit",
+		Amount:          decimal.NewFromFloat(0.25),
+		ExternalTxId:    "tx1",
+		Address:         "addr1",
+		SourceType:      "ADDRESS",
+		SourceAddress:   "bc1qsender",
+	})
+	if err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+
+	history, err := service.GetTransactionHistory(ctx, "user1", "BTC", 10, "")
+	if err != nil {
+		t.Fatalf("GetTransactionHistory failed: %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("Expected 1 transaction, got %d", len(history))
+	}
+	if history[0].SourceType != "ADDRESS" || history[0].SourceAddress != "bc1qsender" {
+		t.Errorf("Expected source ADDRESS/bc1qsender, got %s/%s", history[0].SourceType, history[0].SourceAddress)
+	}
+}
+
+func TestProcessTransaction_PessimisticLockingSerialisesDebits(t *testing.T) {
+	ctx := context.Background()
+	service, err := NewService(ctx, models.DatabaseConfig{
+		Path:           filepath.Join(t.TempDir(), "locking.db"),
+		MaxOpenConns:   8,
+		PingTimeout:    time.Second,
+		BusyTimeout:    10 * time.Second,
+		BalanceLocking: models.LockingPessimistic,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer service.Close()
+
+	_, err = service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "USDC", TransactionType: "deposit", Amount: decimal.NewFromInt(100), ExternalTxId: "funding"})
+	if err != nil {
+		t.Fatalf("Failed to fund account: %v", err)
+	}
+
+	// Every debit of the same account must apply; none may fail on a version conflict or a lock upgrade
+	const debits = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, debits)
+	for i := 0; i < debits; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := service.subledger.ProcessTransactionWithPolicy(ctx, ProcessTransactionParams{
+				UserId: "user1", Asset: "USDC", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-1),
+				ExternalTxId: fmt.Sprintf("debit-%d", i)}, models.BalancePolicyCustomer)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Debit failed under pessimistic locking: %v", err)
+		}
+	}
+
+	balance, err := service.subledger.GetBalance(ctx, "user1", "USDC")
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if !balance.Equal(decimal.NewFromInt(100 - debits)) {
+		t.Errorf("Expected balance %d, got %s", 100-debits, balance)
+	}
+}
+
+func TestGetTransactionHistory_KeysetPagination(t *testing.T) {
+	service, cleanup := setupTestDb(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		if _, err := service.ProcessTransaction(ctx, ProcessTransactionParams{
+			UserId: "user1", Asset: "BTC", TransactionType: "deposit", Amount: decimal.NewFromInt(1), ExternalTxId: fmt.Sprintf("tx%d", i),
+		}); err != nil {
+			t.Fatalf("ProcessTransaction failed: %v", err)
+		}
+	}
+
+	var seen []string
+	before := ""
+	for page := 0; page < 4; page++ {
+		history, err := service.GetTransactionHistory(ctx, "user1", "BTC", 2, before)
+		if err != nil {
+			t.Fatalf("GetTransactionHistory failed: %v", err)
+		}
+		if len(history) == 0 {
+			break
+		}
+		for _, tx := range history {
+			seen = append(seen, tx.ExternalTransactionId)
+		}
+		before = history[len(history)-1].Id
+	}
+
+	expected := []string{"tx4", "tx3", "tx2", "tx1", "tx0"}
+	if fmt.Sprint(seen) != fmt.Sprint(expected) {
+		t.Errorf("Expected pages to walk %v newest first, got %v", expected, seen)
+	}
+
+	history, err := service.GetTransactionHistory(ctx, "user1", "BTC", 2, "unknown")
+	if err != nil {
+		t.Fatalf("GetTransactionHistory failed: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Expected an empty page for an unknown cursor, got %d transactions", len(history))
+	}
+
+	// The page must be read from the history index rather than sorted
+	rows, err := service.db.QueryContext(ctx, "EXPLAIN QUERY PLAN "+queryGetTransactionHistory, "user1", "BTC", before, before, 2)
+	if err != nil {
+		t.Fatalf("EXPLAIN failed: %v", err)
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			t.Fatalf("Failed to scan plan: %v", err)
+		}
+		plan = append(plan, detail)
+	}
+	joined := strings.Join(plan, "; ")
+	if !strings.Contains(joined, "idx_transactions_history") || strings.Contains(joined, "TEMP B-TREE") {
+		t.Errorf("Expected the history query to use idx_transactions_history without sorting, got plan: %s", joined)
+	}
+}
@@ -0,0 +1,133 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Treasury top-up statuses
+const (
+	TopUpStatusRequested = "requested"
+	TopUpStatusFunded    = "funded"
+	TopUpStatusExpired   = "expired"
+)
+
+func (s *Service) initTreasurySchema() error {
+	schema := `
+	-- Vault to hot wallet transfers requested by the withdrawal worker
+	CREATE TABLE IF NOT EXISTS treasury_top_ups (
+		id TEXT PRIMARY KEY,
+		asset TEXT NOT NULL,
+		vault_wallet_id TEXT NOT NULL,
+		wallet_id TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		activity_id TEXT NOT NULL,
+		status TEXT NOT NULL DEFAULT 'requested',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_treasury_top_ups_wallet_status ON treasury_top_ups(wallet_id, status);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// RecordTreasuryTopUp stores a requested vault to hot wallet transfer
+func (s *Service) RecordTreasuryTopUp(ctx context.Context, asset, vaultWalletId, walletId string, amount decimal.Decimal, activityId string) (string, error) {
+	id := uuid.New().String()
+	if _, err := s.db.ExecContext(ctx, queryInsertTreasuryTopUp, id, asset, vaultWalletId, walletId, amount.String(), activityId); err != nil {
+		return "", fmt.Errorf("unable to record treasury top-up: %w", err)
+	}
+	return id, nil
+}
+
+// GetOpenTreasuryTopUp returns the outstanding top-up for a hot wallet, or nil if there is none
+func (s *Service) GetOpenTreasuryTopUp(ctx context.Context, walletId string) (*models.TreasuryTopUp, error) {
+	topUp, err := scanTreasuryTopUp(s.db.QueryRowContext(ctx, queryGetOpenTreasuryTopUp, walletId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get open treasury top-up: %w", err)
+	}
+	return topUp, nil
+}
+
+// UpdateTreasuryTopUpStatus moves a top-up to funded or expired
+func (s *Service) UpdateTreasuryTopUpStatus(ctx context.Context, id, status string) error {
+	if _, err := s.db.ExecContext(ctx, queryUpdateTreasuryTopUpStatus, status, id); err != nil {
+		return fmt.Errorf("unable to update treasury top-up: %w", err)
+	}
+	return nil
+}
+
+// ListTreasuryTopUps returns the most recent top-ups
+func (s *Service) ListTreasuryTopUps(ctx context.Context, limit int) ([]models.TreasuryTopUp, error) {
+	rows, err := s.db.QueryContext(ctx, queryListTreasuryTopUps, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list treasury top-ups: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var topUps []models.TreasuryTopUp
+	for rows.Next() {
+		topUp, err := scanTreasuryTopUp(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan treasury top-up: %w", err)
+		}
+		topUps = append(topUps, *topUp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating treasury top-ups: %w", err)
+	}
+
+	return topUps, nil
+}
+
+func scanTreasuryTopUp(row rowScanner) (*models.TreasuryTopUp, error) {
+	var topUp models.TreasuryTopUp
+	var amountStr string
+	if err := row.Scan(&topUp.Id, &topUp.Asset, &topUp.VaultWalletId, &topUp.WalletId, &amountStr,
+		&topUp.ActivityId, &topUp.Status, &topUp.CreatedAt, &topUp.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	topUp.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse top-up amount '%s': %w", amountStr, err)
+	}
+
+	return &topUp, nil
+}
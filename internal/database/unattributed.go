@@ -0,0 +1,299 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Unattributed deposit statuses
+const (
+	UnattributedStatusOpen      = "open"
+	UnattributedStatusClaimed   = "claimed"
+	UnattributedStatusDismissed = "dismissed"
+)
+
+// UnattributedDepositHandler is called once for each new deposit to an address no user owns. ctx
+// carries the correlation id of the deposit being processed.
+type UnattributedDepositHandler func(ctx context.Context, deposit models.UnattributedDeposit)
+
+// UnattributedDepositParams describes a deposit the listener could not attribute to a user
+type UnattributedDepositParams struct {
+	ExternalTxId string
+	Address      string
+	Asset        string
+	Network      string
+	Amount       decimal.Decimal
+	Source       models.DepositSource
+	Details      string
+}
+
+// logUnattributedDeposit is the default operator notification for unattributed deposits
+func (s *Service) logUnattributedDeposit(ctx context.Context, deposit models.UnattributedDeposit) {
+	correlation.Logger(ctx, s.logger).Error("ALERT: deposit to unrecognized address - resolve with cmd/claims",
+		zap.String("unattributed_id", deposit.Id),
+		zap.String("external_tx_id", deposit.ExternalTransactionId),
+		zap.String("address", deposit.Address),
+		zap.String("asset", deposit.Asset),
+		zap.String("network", deposit.Network),
+		zap.String("amount", deposit.Amount.String()),
+		zap.String("source_address", deposit.SourceAddress))
+}
+
+// SetUnattributedDepositHandler replaces the notification raised when a deposit cannot be attributed
+func (s *Service) SetUnattributedDepositHandler(handler UnattributedDepositHandler) {
+	s.unattributedHandler = handler
+}
+
+func (s *Service) initUnattributedSchema() error {
+	schema := `
+	-- Deposits to addresses no user owns, kept until an operator claims or dismisses them
+	CREATE TABLE IF NOT EXISTS unattributed_deposits (
+		id TEXT PRIMARY KEY,
+		external_transaction_id TEXT NOT NULL UNIQUE,
+		address TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		network TEXT NOT NULL DEFAULT '',
+		amount TEXT NOT NULL,
+		source_type TEXT NOT NULL DEFAULT '',
+		source_address TEXT NOT NULL DEFAULT '',
+		details TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'open',
+		claimed_user_id TEXT NOT NULL DEFAULT '',
+		resolution_note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		resolved_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_unattributed_deposits_status ON unattributed_deposits(status);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// RecordUnattributedDeposit stores a deposit to an unrecognized address and notifies operators.
+// A deposit seen again (e.g. after a restart) is not recorded or notified twice.
+func (s *Service) RecordUnattributedDeposit(ctx context.Context, params UnattributedDepositParams) error {
+	deposit := models.UnattributedDeposit{
+		Id:                    uuid.New().String(),
+		ExternalTransactionId: params.ExternalTxId,
+		Address:               params.Address,
+		Asset:                 params.Asset,
+		Network:               params.Network,
+		Amount:                params.Amount,
+		SourceType:            params.Source.Type,
+		SourceAddress:         params.Source.Address,
+		Details:               params.Details,
+		Status:                UnattributedStatusOpen,
+		CreatedAt:             time.Now().UTC(),
+	}
+
+	result, err := s.db.ExecContext(ctx, queryInsertUnattributedDeposit, deposit.Id, deposit.ExternalTransactionId,
+		deposit.Address, deposit.Asset, deposit.Network, deposit.Amount.String(), deposit.SourceType,
+		deposit.SourceAddress, deposit.Details)
+	if err != nil {
+		return fmt.Errorf("unable to record unattributed deposit: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil
+	}
+
+	handler := s.unattributedHandler
+	if handler == nil {
+		handler = s.logUnattributedDeposit
+	}
+	handler(ctx, deposit)
+
+	return nil
+}
+
+// ListUnattributedDeposits returns unattributed deposits, optionally filtered by status
+func (s *Service) ListUnattributedDeposits(ctx context.Context, status string) ([]models.UnattributedDeposit, error) {
+	query := querySelectUnattributedDeposits + " ORDER BY created_at DESC"
+	args := []interface{}{}
+	if status != "" {
+		query = querySelectUnattributedDeposits + " WHERE status = ? ORDER BY created_at DESC"
+		args = append(args, status)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query unattributed deposits: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var deposits []models.UnattributedDeposit
+	for rows.Next() {
+		deposit, err := scanUnattributedDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, *deposit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unattributed deposits: %w", err)
+	}
+
+	return deposits, nil
+}
+
+// GetUnattributedDeposit returns an unattributed deposit by id
+func (s *Service) GetUnattributedDeposit(ctx context.Context, id string) (*models.UnattributedDeposit, error) {
+	deposit, err := scanUnattributedDeposit(s.db.QueryRowContext(ctx, querySelectUnattributedDeposits+" WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrUnattributedNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return deposit, nil
+}
+
+// ClaimUnattributedDeposit credits an unattributed deposit to a user. asset overrides the recorded
+// Prime symbol when it differs from the ledger's canonical one (e.g. "USDC" for "BASEUSDC").
+// The Prime transaction id is the ledger reference, so the deposit cannot be credited twice.
+func (s *Service) ClaimUnattributedDeposit(ctx context.Context, id, userId, asset, note string) error {
+	deposit, err := s.openUnattributedDeposit(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.GetUserById(ctx, userId); err != nil {
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	if asset == "" {
+		asset = deposit.Asset
+	}
+	source := models.DepositSource{Type: deposit.SourceType, Address: deposit.SourceAddress}
+
+	holdKind, holdReason := s.depositHold(ctx, models.DepositScreening{
+		UserId:                userId,
+		Asset:                 asset,
+		Network:               deposit.Network,
+		Amount:                deposit.Amount,
+		ExternalTransactionId: deposit.ExternalTransactionId,
+		Address:               deposit.Address,
+		Source:                source,
+	}, models.AvailabilityImmediate)
+
+	_, err = s.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId:          userId,
+		Asset:           asset,
+		TransactionType: "deposit",
+		Amount:          deposit.Amount,
+		ExternalTxId:    deposit.ExternalTransactionId,
+		Address:         deposit.Address,
+		Reference:       fmt.Sprintf("Claimed unattributed deposit %s", deposit.Id),
+		SourceType:      source.Type,
+		SourceAddress:   source.Address,
+		HoldReason:      holdReason,
+		HoldKind:        holdKind,
+	})
+	if err != nil && !errors.Is(err, ErrDuplicateTransaction) {
+		return fmt.Errorf("error crediting unattributed deposit: %w", err)
+	}
+
+	return s.markUnattributedResolved(ctx, deposit, UnattributedStatusClaimed, userId, note)
+}
+
+// DismissUnattributedDeposit closes an unattributed deposit without crediting anyone, e.g. once the
+// funds were returned to the sender. note records why.
+func (s *Service) DismissUnattributedDeposit(ctx context.Context, id, note string) error {
+	deposit, err := s.openUnattributedDeposit(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.markUnattributedResolved(ctx, deposit, UnattributedStatusDismissed, "", note)
+}
+
+func (s *Service) openUnattributedDeposit(ctx context.Context, id string) (*models.UnattributedDeposit, error) {
+	deposit, err := s.GetUnattributedDeposit(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deposit.Status != UnattributedStatusOpen {
+		return nil, fmt.Errorf("unattributed deposit %s is already %s", id, deposit.Status)
+	}
+	return deposit, nil
+}
+
+func (s *Service) markUnattributedResolved(ctx context.Context, deposit *models.UnattributedDeposit, status, userId, note string) error {
+	result, err := s.db.ExecContext(ctx, queryResolveUnattributedDeposit, status, userId, note, deposit.Id)
+	if err != nil {
+		return fmt.Errorf("unable to resolve unattributed deposit: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("unattributed deposit %s was resolved concurrently - %w", deposit.Id, ErrConcurrentModification)
+	}
+
+	s.logger.Info("Unattributed deposit resolved",
+		zap.String("unattributed_id", deposit.Id),
+		zap.String("status", status),
+		zap.String("asset", deposit.Asset),
+		zap.String("amount", deposit.Amount.String()),
+		zap.String("user_id", userId))
+
+	return nil
+}
+
+func scanUnattributedDeposit(row rowScanner) (*models.UnattributedDeposit, error) {
+	var deposit models.UnattributedDeposit
+	var amountStr string
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&deposit.Id, &deposit.ExternalTransactionId, &deposit.Address, &deposit.Asset, &deposit.Network,
+		&amountStr, &deposit.SourceType, &deposit.SourceAddress, &deposit.Details, &deposit.Status,
+		&deposit.ClaimedUserId, &deposit.ResolutionNote, &deposit.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	deposit.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse unattributed deposit amount '%s': %w", amountStr, err)
+	}
+	if resolvedAt.Valid {
+		deposit.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &deposit, nil
+}
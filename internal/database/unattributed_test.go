@@ -0,0 +1,139 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestUnattributed_RecordAndClaim(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initUnattributedSchema(); err != nil {
+		t.Fatalf("Failed to create unattributed deposit schema: %v", err)
+	}
+
+	var notified []models.UnattributedDeposit
+	service.SetUnattributedDepositHandler(func(ctx context.Context, deposit models.UnattributedDeposit) {
+		notified = append(notified, deposit)
+	})
+
+	ctx := context.Background()
+	params := UnattributedDepositParams{
+		ExternalTxId: "prime-tx-1",
+		Address:      "0xunknown",
+		Asset:        "BASEUSDC",
+		Network:      "base-mainnet",
+		Amount:       decimal.NewFromInt(40),
+		Source:       models.DepositSource{Type: "ADDRESS", Address: "0xsender"},
+		Details:      `{"id":"prime-tx-1"}`,
+	}
+
+	// Seeing the same transaction again does not record or notify twice
+	for i := 0; i < 2; i++ {
+		if err := service.RecordUnattributedDeposit(ctx, params); err != nil {
+			t.Fatalf("RecordUnattributedDeposit failed: %v", err)
+		}
+	}
+	if len(notified) != 1 {
+		t.Fatalf("Expected operators to be notified once, got %d", len(notified))
+	}
+
+	deposits, err := service.ListUnattributedDeposits(ctx, UnattributedStatusOpen)
+	if err != nil {
+		t.Fatalf("ListUnattributedDeposits failed: %v", err)
+	}
+	if len(deposits) != 1 {
+		t.Fatalf("Expected 1 open unattributed deposit, got %d", len(deposits))
+	}
+	deposit := deposits[0]
+	if deposit.Id != notified[0].Id || deposit.SourceAddress != "0xsender" || deposit.Details != params.Details {
+		t.Errorf("Unexpected stored deposit: %+v", deposit)
+	}
+
+	if err := service.ClaimUnattributedDeposit(ctx, deposit.Id, "user1", "USDC", "customer ticket 42"); err != nil {
+		t.Fatalf("ClaimUnattributedDeposit failed: %v", err)
+	}
+
+	balance, err := service.GetUserBalance(ctx, "user1", "USDC")
+	if err != nil {
+		t.Fatalf("GetUserBalance failed: %v", err)
+	}
+	if !balance.Equal(decimal.NewFromInt(40)) {
+		t.Errorf("Expected balance 40 after claim, got %s", balance.String())
+	}
+
+	claimed, err := service.GetUnattributedDeposit(ctx, deposit.Id)
+	if err != nil {
+		t.Fatalf("GetUnattributedDeposit failed: %v", err)
+	}
+	if claimed.Status != UnattributedStatusClaimed || claimed.ClaimedUserId != "user1" || claimed.ResolvedAt == nil {
+		t.Errorf("Expected claimed deposit, got %+v", claimed)
+	}
+
+	if err := service.DismissUnattributedDeposit(ctx, deposit.Id, "duplicate"); err == nil {
+		t.Error("Expected resolving a claimed deposit to fail")
+	}
+}
+
+func TestUnattributed_Dismiss(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initUnattributedSchema(); err != nil {
+		t.Fatalf("Failed to create unattributed deposit schema: %v", err)
+	}
+
+	ctx := context.Background()
+	if _, err := service.GetUnattributedDeposit(ctx, "missing"); !errors.Is(err, ErrUnattributedNotFound) {
+		t.Errorf("Expected ErrUnattributedNotFound, got %v", err)
+	}
+
+	err := service.RecordUnattributedDeposit(ctx, UnattributedDepositParams{
+		ExternalTxId: "prime-tx-2",
+		Address:      "0xunknown",
+		Asset:        "ETH",
+		Amount:       decimal.NewFromInt(1),
+	})
+	if err != nil {
+		t.Fatalf("RecordUnattributedDeposit failed: %v", err)
+	}
+
+	deposits, err := service.ListUnattributedDeposits(ctx, "")
+	if err != nil || len(deposits) != 1 {
+		t.Fatalf("Expected 1 unattributed deposit, got %d (%v)", len(deposits), err)
+	}
+
+	if err := service.DismissUnattributedDeposit(ctx, deposits[0].Id, "returned in prime-return-1"); err != nil {
+		t.Fatalf("DismissUnattributedDeposit failed: %v", err)
+	}
+
+	open, err := service.ListUnattributedDeposits(ctx, UnattributedStatusOpen)
+	if err != nil {
+		t.Fatalf("ListUnattributedDeposits failed: %v", err)
+	}
+	if len(open) != 0 {
+		t.Errorf("Expected no open deposits after dismiss, got %d", len(open))
+	}
+}
@@ -27,26 +27,29 @@ import (
 	"go.uber.org/zap"
 )
 
+// ErrUserExists is returned by CreateUser when a user with the email address already exists
+var ErrUserExists = errors.New("user already exists")
+
 func (s *Service) GetUsers(ctx context.Context) ([]models.User, error) {
-	zap.L().Debug("Querying active users")
+	s.logger.Debug("Querying active users")
 
-	rows, err := s.db.QueryContext(ctx, queryGetActiveUsers)
+	rows, err := s.db.QueryContext(ctx, queryGetActiveUsers, s.tenantId, s.tenantId)
 	if err != nil {
-		zap.L().Error("Failed to query users", zap.Error(err))
+		s.logger.Error("Failed to query users", zap.Error(err))
 		return nil, fmt.Errorf("unable to query users: %w", err)
 	}
 	defer func(rows *sql.Rows) {
 		if err := rows.Close(); err != nil {
-			zap.L().Warn("Failed to close rows", zap.Error(err))
+			s.logger.Warn("Failed to close rows", zap.Error(err))
 		}
 	}(rows)
 
 	var users []models.User
 	for rows.Next() {
 		var user models.User
-		err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+		err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.TenantId, &user.CreatedAt, &user.UpdatedAt)
 		if err != nil {
-			zap.L().Error("Failed to scan user row", zap.Error(err))
+			s.logger.Error("Failed to scan user row", zap.Error(err))
 			return nil, fmt.Errorf("unable to scan user row: %w", err)
 		}
 
@@ -55,70 +58,70 @@ func (s *Service) GetUsers(ctx context.Context) ([]models.User, error) {
 
 	// Check for errors during iteration
 	if err := rows.Err(); err != nil {
-		zap.L().Error("Error during user row iteration", zap.Error(err))
+		s.logger.Error("Error during user row iteration", zap.Error(err))
 		return nil, fmt.Errorf("error iterating user rows: %w", err)
 	}
 
-	zap.L().Info("Retrieved users", zap.Int("count", len(users)))
+	s.logger.Info("Retrieved users", zap.Int("count", len(users)))
 	return users, nil
 }
 
 func (s *Service) GetUserById(ctx context.Context, userId string) (*models.User, error) {
-	zap.L().Debug("Querying user by ID", zap.String("user_id", userId))
+	s.logger.Debug("Querying user by ID", zap.String("user_id", userId))
 
 	var user models.User
-	err := s.db.QueryRowContext(ctx, queryGetUserById, userId).Scan(
-		&user.Id, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	err := s.db.QueryRowContext(ctx, queryGetUserById, userId, s.tenantId, s.tenantId).Scan(
+		&user.Id, &user.Name, &user.Email, &user.TenantId, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found: %s", userId)
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, userId)
 		}
-		zap.L().Error("Failed to query user by ID", zap.String("user_id", userId), zap.Error(err))
+		s.logger.Error("Failed to query user by ID", zap.String("user_id", userId), zap.Error(err))
 		return nil, fmt.Errorf("unable to query user by ID: %w", err)
 	}
 
-	zap.L().Debug("Retrieved user by ID", zap.String("user_id", userId), zap.String("name", user.Name))
+	s.logger.Debug("Retrieved user by ID", zap.String("user_id", userId), zap.String("name", user.Name))
 	return &user, nil
 }
 
 func (s *Service) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
-	zap.L().Debug("Querying user by email", zap.String("email", email))
+	s.logger.Debug("Querying user by email", zap.String("email", email))
 
 	var user models.User
-	err := s.db.QueryRowContext(ctx, queryGetUserByEmail, email).Scan(
-		&user.Id, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	err := s.db.QueryRowContext(ctx, queryGetUserByEmail, email, s.tenantId, s.tenantId).Scan(
+		&user.Id, &user.Name, &user.Email, &user.TenantId, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found: %s", email)
+			return nil, fmt.Errorf("%w: %s", ErrUserNotFound, email)
 		}
-		zap.L().Error("Failed to query user by email", zap.String("email", email), zap.Error(err))
+		s.logger.Error("Failed to query user by email", zap.String("email", email), zap.Error(err))
 		return nil, fmt.Errorf("unable to query user by email: %w", err)
 	}
 
-	zap.L().Debug("Retrieved user by email", zap.String("email", email), zap.String("name", user.Name))
+	s.logger.Debug("Retrieved user by email", zap.String("email", email), zap.String("name", user.Name))
 	return &user, nil
 }
 
 func (s *Service) CreateUser(ctx context.Context, userId, name, email string) (*models.User, error) {
-	zap.L().Info("Creating user", zap.String("id", userId), zap.String("name", name), zap.String("email", email))
+	s.logger.Info("Creating user", zap.String("id", userId), zap.String("name", name), zap.String("email", email))
 
-	result, err := s.db.ExecContext(ctx, queryInsertUser, userId, name, email)
+	result, err := s.db.ExecContext(ctx, queryInsertUser, userId, name, email, s.tenantOrDefault())
 	if err != nil {
-		zap.L().Error("Failed to insert user", zap.String("email", email), zap.Error(err))
+		s.logger.Error("Failed to insert user", zap.String("email", email), zap.Error(err))
 		return nil, fmt.Errorf("unable to insert user: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		zap.L().Error("Failed to get rows affected", zap.Error(err))
+		s.logger.Error("Failed to get rows affected", zap.Error(err))
 		return nil, fmt.Errorf("unable to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return nil, fmt.Errorf("user with email %s already exists", email)
+		return nil, fmt.Errorf("%w with email %s", ErrUserExists, email)
 	}
 
-	zap.L().Info("User created successfully", zap.String("id", userId), zap.String("name", name), zap.String("email", email))
+	s.logger.Info("User created successfully", zap.String("id", userId), zap.String("name", name), zap.String("email", email))
 
 	// Return the created user
 	return s.GetUserByEmail(ctx, email)
@@ -0,0 +1,141 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// ListVelocityEntries returns the deposits and withdrawals created since the given time, with
+// withdrawal amounts as positive values
+func (s *Service) ListVelocityEntries(ctx context.Context, since time.Time) ([]models.VelocityEntry, error) {
+	narrowed := createdAtFrom(since)
+	rows, err := s.db.QueryContext(ctx, queryListVelocityEntriesSince, narrowed, s.tenantId, s.tenantId,
+		narrowed, s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list velocity entries: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var entries []models.VelocityEntry
+	for rows.Next() {
+		var entry models.VelocityEntry
+		var amountStr string
+		if err := rows.Scan(&entry.UserId, &entry.Asset, &entry.Direction, &amountStr, &entry.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan velocity entry: %w", err)
+		}
+		if entry.CreatedAt.Before(since) {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse amount '%s': %w", amountStr, err)
+		}
+		entry.Amount = amount.Abs()
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate velocity entries: %w", err)
+	}
+	return entries, nil
+}
+
+// DetectVelocityAnomalies compares each user's deposits and withdrawals of an asset in the window ending
+// at now with their average per window over the baseline period before it. A user with no baseline
+// activity is flagged once the window reaches the minimum count or volume. Anomalies are sorted by user,
+// asset and direction.
+func DetectVelocityAnomalies(entries []models.VelocityEntry, now time.Time, cfg models.AnomalyConfig) []models.VelocityAnomaly {
+	windowStart := now.Add(-cfg.Window)
+	baselineStart := windowStart.Add(-cfg.Baseline)
+	periods := decimal.NewFromInt(int64(cfg.Baseline)).Div(decimal.NewFromInt(int64(cfg.Window)))
+
+	type activity struct {
+		count, baselineCount   int
+		volume, baselineVolume decimal.Decimal
+	}
+	byKey := make(map[[3]string]*activity)
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(baselineStart) || entry.CreatedAt.After(now) {
+			continue
+		}
+		key := [3]string{entry.UserId, entry.Asset, entry.Direction}
+		totals, ok := byKey[key]
+		if !ok {
+			totals = &activity{}
+			byKey[key] = totals
+		}
+		if entry.CreatedAt.Before(windowStart) {
+			totals.baselineCount++
+			totals.baselineVolume = totals.baselineVolume.Add(entry.Amount)
+		} else {
+			totals.count++
+			totals.volume = totals.volume.Add(entry.Amount)
+		}
+	}
+
+	var anomalies []models.VelocityAnomaly
+	for key, totals := range byKey {
+		if totals.count == 0 {
+			continue
+		}
+		anomaly := models.VelocityAnomaly{
+			UserId:         key[0],
+			Asset:          key[1],
+			Direction:      key[2],
+			Count:          totals.count,
+			Volume:         totals.volume,
+			BaselineCount:  decimal.NewFromInt(int64(totals.baselineCount)).Div(periods),
+			BaselineVolume: totals.baselineVolume.Div(periods),
+		}
+		if totals.count >= cfg.MinCount &&
+			decimal.NewFromInt(int64(totals.count)).GreaterThan(anomaly.BaselineCount.Mul(cfg.Multiplier)) {
+			anomaly.Reasons = append(anomaly.Reasons, "count")
+		}
+		if minVolume, ok := cfg.MinVolume[anomaly.Asset]; ok && totals.volume.GreaterThanOrEqual(minVolume) &&
+			totals.volume.GreaterThan(anomaly.BaselineVolume.Mul(cfg.Multiplier)) {
+			anomaly.Reasons = append(anomaly.Reasons, "volume")
+		}
+		if len(anomaly.Reasons) > 0 {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	sort.Slice(anomalies, func(i, j int) bool {
+		if anomalies[i].UserId != anomalies[j].UserId {
+			return anomalies[i].UserId < anomalies[j].UserId
+		}
+		if anomalies[i].Asset != anomalies[j].Asset {
+			return anomalies[i].Asset < anomalies[j].Asset
+		}
+		return anomalies[i].Direction < anomalies[j].Direction
+	})
+	return anomalies
+}
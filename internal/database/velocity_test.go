@@ -0,0 +1,151 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDetectVelocityAnomalies(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	cfg := models.AnomalyConfig{
+		Window:     24 * time.Hour,
+		Baseline:   10 * 24 * time.Hour,
+		Multiplier: decimal.NewFromInt(3),
+		MinCount:   3,
+		MinVolume:  map[string]decimal.Decimal{"USDC": decimal.NewFromInt(1000)},
+	}
+
+	var entries []models.VelocityEntry
+	add := func(userId, asset, direction string, amount int64, age time.Duration) {
+		entries = append(entries, models.VelocityEntry{
+			UserId: userId, Asset: asset, Direction: direction, Amount: decimal.NewFromInt(amount), CreatedAt: now.Add(-age),
+		})
+	}
+
+	// steady: one 100 USDC deposit a day, and one today
+	for day := 0; day <= 10; day++ {
+		add("steady", "USDC", "deposit", 100, time.Duration(day)*24*time.Hour+time.Hour)
+	}
+	// burst: one withdrawal a day in the baseline, then five today
+	for day := 1; day <= 10; day++ {
+		add("burst", "USDC", "withdrawal", 10, time.Duration(day)*24*time.Hour+time.Hour)
+	}
+	for i := 0; i < 5; i++ {
+		add("burst", "USDC", "withdrawal", 10, time.Hour)
+	}
+	// whale: no history, then a single deposit above the minimum volume
+	add("whale", "USDC", "deposit", 5000, time.Hour)
+	// small: no history and a deposit below the minimums
+	add("small", "USDC", "deposit", 50, time.Hour)
+	// Activity older than the baseline is ignored
+	add("old", "USDC", "deposit", 5000, 20*24*time.Hour)
+
+	anomalies := DetectVelocityAnomalies(entries, now, cfg)
+	if len(anomalies) != 2 {
+		t.Fatalf("Expected 2 anomalies, got %+v", anomalies)
+	}
+
+	burst := anomalies[0]
+	if burst.UserId != "burst" || burst.Direction != "withdrawal" || burst.Count != 5 {
+		t.Errorf("Unexpected burst anomaly: %+v", burst)
+	}
+	if !burst.BaselineCount.Equal(decimal.NewFromInt(1)) || len(burst.Reasons) != 1 || burst.Reasons[0] != "count" {
+		t.Errorf("Expected a count anomaly against a baseline of 1, got %+v", burst)
+	}
+
+	whale := anomalies[1]
+	if whale.UserId != "whale" || !whale.Volume.Equal(decimal.NewFromInt(5000)) || len(whale.Reasons) != 1 || whale.Reasons[0] != "volume" {
+		t.Errorf("Unexpected whale anomaly: %+v", whale)
+	}
+}
+
+func TestReviewCases_OpenDedupeAndClose(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-1",
+	}); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	if err := service.ReserveWithdrawal(ctx, ReserveWithdrawalParams{
+		UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(4), IdempotencyKey: "wd-1", Destination: "0xexternal",
+	}); err != nil {
+		t.Fatalf("Failed to reserve withdrawal: %v", err)
+	}
+
+	entries, err := service.ListVelocityEntries(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListVelocityEntries failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected the deposit and the held withdrawal, got %+v", entries)
+	}
+	for _, entry := range entries {
+		if entry.Direction == "withdrawal" && !entry.Amount.Equal(decimal.NewFromInt(4)) {
+			t.Errorf("Expected a held withdrawal of 4, got %+v", entry)
+		}
+	}
+
+	params := OpenReviewCaseParams{
+		Kind: ReviewCaseKindVelocity, UserId: "user1", Asset: "ETH", Key: "velocity:user1:ETH:withdrawal", Summary: "burst",
+	}
+	opened, err := service.OpenReviewCase(ctx, params, time.Now().Add(-time.Hour))
+	if err != nil || opened == nil {
+		t.Fatalf("Expected a new case, got %+v (%v)", opened, err)
+	}
+	again, err := service.OpenReviewCase(ctx, params, time.Now().Add(-time.Hour))
+	if err != nil || again != nil {
+		t.Fatalf("Expected the open case to suppress a duplicate, got %+v (%v)", again, err)
+	}
+
+	if err := service.CloseReviewCase(ctx, opened.Id, "customer payroll run"); err != nil {
+		t.Fatalf("CloseReviewCase failed: %v", err)
+	}
+	if err := service.CloseReviewCase(ctx, opened.Id, "again"); err == nil {
+		t.Error("Expected closing a closed case to fail")
+	}
+
+	// Closed within the window: not reopened. Closed before it: reopened.
+	if again, err := service.OpenReviewCase(ctx, params, time.Now().Add(-time.Hour)); err != nil || again != nil {
+		t.Fatalf("Expected a recently closed case not to reopen, got %+v (%v)", again, err)
+	}
+	reopened, err := service.OpenReviewCase(ctx, params, time.Now().Add(time.Minute))
+	if err != nil || reopened == nil {
+		t.Fatalf("Expected the case to reopen, got %+v (%v)", reopened, err)
+	}
+
+	cases, err := service.ListReviewCases(ctx, "")
+	if err != nil || len(cases) != 2 {
+		t.Fatalf("Expected 2 cases, got %+v (%v)", cases, err)
+	}
+	open, err := service.ListReviewCases(ctx, ReviewCaseStatusOpen)
+	if err != nil || len(open) != 1 || open[0].Id != reopened.Id {
+		t.Fatalf("Expected only the reopened case to be open, got %+v (%v)", open, err)
+	}
+}
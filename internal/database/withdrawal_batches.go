@@ -0,0 +1,227 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Withdrawal batch statuses
+const (
+	WithdrawalBatchStatusPending   = "pending"
+	WithdrawalBatchStatusSubmitted = "submitted"
+	WithdrawalBatchStatusCompleted = "completed"
+	WithdrawalBatchStatusFailed    = "failed"
+)
+
+// withdrawalBatchNamespace scopes batch ids derived from queue entry ids
+var withdrawalBatchNamespace = uuid.MustParse("6f1c7e2a-4b8d-4f0e-9a51-3c2d8e7b9f10")
+
+// WithdrawalBatchId returns a deterministic batch id for a set of queue entries. It doubles as the Prime
+// idempotency key. The id is stored on the entries when the batch is created and reused whenever they
+// are claimed again, so a resubmitted batch cannot be paid out twice.
+func WithdrawalBatchId(queueIds []string) string {
+	sorted := append([]string(nil), queueIds...)
+	sort.Strings(sorted)
+	return uuid.NewSHA1(withdrawalBatchNamespace, []byte(strings.Join(sorted, ","))).String()
+}
+
+// CreateWithdrawalBatch records a batch for queue entries sharing a wallet, asset and destination and
+// links the entries to it. Entries already linked to a batch keep it, and must be the whole of that
+// batch. The ledger entries of each withdrawal are left untouched.
+func (s *Service) CreateWithdrawalBatch(ctx context.Context, items []models.QueuedWithdrawal) (*models.WithdrawalBatch, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("withdrawal batch must contain at least one withdrawal")
+	}
+
+	first := items[0]
+	ids := make([]string, len(items))
+	total := decimal.Zero
+	for i, item := range items {
+		if item.WalletId != first.WalletId || item.AssetNetwork != first.AssetNetwork ||
+			item.DestinationType != first.DestinationType || item.Destination != first.Destination {
+			return nil, fmt.Errorf("withdrawal %s does not share the batch destination", item.Id)
+		}
+		if item.BatchId != first.BatchId {
+			return nil, fmt.Errorf("withdrawal %s belongs to another batch", item.Id)
+		}
+		ids[i] = item.Id
+		total = total.Add(item.Amount)
+	}
+
+	batchId := first.BatchId
+	if batchId == "" {
+		batchId = WithdrawalBatchId(ids)
+	} else {
+		existing, err := s.GetWithdrawalBatch(ctx, batchId)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && (existing.ItemCount != len(items) || !existing.TotalAmount.Equal(total)) {
+			return nil, fmt.Errorf("batch %s pays out %d withdrawals totalling %s, reclaimed %d totalling %s",
+				batchId, existing.ItemCount, existing.TotalAmount.String(), len(items), total.String())
+		}
+	}
+
+	batch := &models.WithdrawalBatch{
+		Id:              batchId,
+		Asset:           first.Asset,
+		AssetNetwork:    first.AssetNetwork,
+		WalletId:        first.WalletId,
+		DestinationType: destinationTypeOrDefault(first.DestinationType),
+		Destination:     first.Destination,
+		TotalAmount:     total,
+		ItemCount:       len(items),
+		Status:          WithdrawalBatchStatusPending,
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Error("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, queryUpsertWithdrawalBatch,
+		batch.Id, batch.Asset, batch.AssetNetwork, batch.WalletId, batch.DestinationType, batch.Destination,
+		batch.TotalAmount.String(), batch.ItemCount); err != nil {
+		return nil, fmt.Errorf("unable to create withdrawal batch: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, queryAssignWithdrawalBatch, batch.Id, id); err != nil {
+			return nil, fmt.Errorf("unable to assign withdrawal to batch: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit withdrawal batch: %w", err)
+	}
+
+	return batch, nil
+}
+
+// MarkWithdrawalBatchSubmitted records the Prime activity for a batch and marks all its entries submitted
+func (s *Service) MarkWithdrawalBatchSubmitted(ctx context.Context, batchId, activityId string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			s.logger.Error("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, queryUpdateWithdrawalBatch, WithdrawalBatchStatusSubmitted, activityId, batchId); err != nil {
+		return fmt.Errorf("unable to mark withdrawal batch submitted: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, queryMarkBatchWithdrawalsSubmitted, activityId, batchId); err != nil {
+		return fmt.Errorf("unable to mark batch withdrawals submitted: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateWithdrawalBatchStatus moves a batch to completed or failed
+func (s *Service) UpdateWithdrawalBatchStatus(ctx context.Context, batchId, status string) error {
+	if _, err := s.db.ExecContext(ctx, queryUpdateWithdrawalBatch, status, "", batchId); err != nil {
+		return fmt.Errorf("unable to update withdrawal batch: %w", err)
+	}
+	return nil
+}
+
+// GetWithdrawalBatch returns a batch by id (its Prime idempotency key), or nil if there is none
+func (s *Service) GetWithdrawalBatch(ctx context.Context, batchId string) (*models.WithdrawalBatch, error) {
+	batch, err := scanWithdrawalBatch(s.db.QueryRowContext(ctx, queryGetWithdrawalBatch, batchId))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get withdrawal batch: %w", err)
+	}
+	return batch, nil
+}
+
+// ListWithdrawalBatchItems returns the queue entries paid out by a batch
+func (s *Service) ListWithdrawalBatchItems(ctx context.Context, batchId string) ([]models.QueuedWithdrawal, error) {
+	rows, err := s.db.QueryContext(ctx, queryListBatchWithdrawals, batchId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list batch withdrawals: %w", err)
+	}
+	return scanQueuedWithdrawals(rows, s.logger)
+}
+
+// ListWithdrawalBatches returns the most recent batches
+func (s *Service) ListWithdrawalBatches(ctx context.Context, limit int) ([]models.WithdrawalBatch, error) {
+	rows, err := s.db.QueryContext(ctx, queryListWithdrawalBatches, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list withdrawal batches: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var batches []models.WithdrawalBatch
+	for rows.Next() {
+		batch, err := scanWithdrawalBatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan withdrawal batch: %w", err)
+		}
+		batches = append(batches, *batch)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating withdrawal batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+func scanWithdrawalBatch(row rowScanner) (*models.WithdrawalBatch, error) {
+	var batch models.WithdrawalBatch
+	var totalStr string
+	if err := row.Scan(&batch.Id, &batch.Asset, &batch.AssetNetwork, &batch.WalletId, &batch.DestinationType,
+		&batch.Destination, &totalStr, &batch.ItemCount, &batch.ActivityId, &batch.Status,
+		&batch.CreatedAt, &batch.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	batch.TotalAmount, err = decimal.NewFromString(totalStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse batch total '%s': %w", totalStr, err)
+	}
+
+	return &batch, nil
+}
@@ -0,0 +1,173 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawalBatch_CreateAndSubmit(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initWithdrawalQueueSchema(); err != nil {
+		t.Fatalf("Failed to create withdrawal queue schema: %v", err)
+	}
+
+	ctx := context.Background()
+	for i, amount := range []int64{10, 15, 25} {
+		_, err := service.EnqueueWithdrawal(ctx, EnqueueWithdrawalParams{
+			UserId:         "user1",
+			Asset:          "USDC",
+			AssetNetwork:   "USDC-ethereum-mainnet",
+			Amount:         decimal.NewFromInt(amount),
+			Destination:    "0xexchange",
+			WalletId:       "hot-wallet",
+			IdempotencyKey: fmt.Sprintf("user1-key-%d", i),
+		})
+		if err != nil {
+			t.Fatalf("EnqueueWithdrawal failed: %v", err)
+		}
+	}
+
+	items, err := service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+
+	batch, err := service.CreateWithdrawalBatch(ctx, items)
+	if err != nil {
+		t.Fatalf("CreateWithdrawalBatch failed: %v", err)
+	}
+	if !batch.TotalAmount.Equal(decimal.NewFromInt(50)) || batch.ItemCount != 3 {
+		t.Errorf("Expected 3 withdrawals totalling 50, got %d totalling %s", batch.ItemCount, batch.TotalAmount.String())
+	}
+
+	// The batch id is the Prime idempotency key and must not depend on claim order
+	reversed := []string{items[2].Id, items[1].Id, items[0].Id}
+	if WithdrawalBatchId(reversed) != batch.Id {
+		t.Error("Expected batch id to be independent of withdrawal order")
+	}
+
+	if err := service.MarkWithdrawalBatchSubmitted(ctx, batch.Id, "activity-1"); err != nil {
+		t.Fatalf("MarkWithdrawalBatchSubmitted failed: %v", err)
+	}
+
+	stored, err := service.GetWithdrawalBatch(ctx, batch.Id)
+	if err != nil {
+		t.Fatalf("GetWithdrawalBatch failed: %v", err)
+	}
+	if stored == nil || stored.Status != WithdrawalBatchStatusSubmitted || stored.ActivityId != "activity-1" {
+		t.Fatalf("Expected submitted batch with activity-1, got %+v", stored)
+	}
+
+	batchItems, err := service.ListWithdrawalBatchItems(ctx, batch.Id)
+	if err != nil {
+		t.Fatalf("ListWithdrawalBatchItems failed: %v", err)
+	}
+	if len(batchItems) != 3 {
+		t.Fatalf("Expected 3 batch items, got %d", len(batchItems))
+	}
+	for _, item := range batchItems {
+		if item.Status != WithdrawalQueueStatusSubmitted || item.ActivityId != "activity-1" {
+			t.Errorf("Expected item %s submitted with activity-1, got %s/%s", item.Id, item.Status, item.ActivityId)
+		}
+	}
+
+	// Withdrawals to another destination cannot join the batch
+	other := items[0]
+	other.Destination = "0xelsewhere"
+	if _, err := service.CreateWithdrawalBatch(ctx, []models.QueuedWithdrawal{items[1], other}); err == nil {
+		t.Error("Expected mixed destinations to be rejected")
+	}
+}
+
+func TestWithdrawalBatch_ReclaimedBatchKeepsItsId(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initWithdrawalQueueSchema(); err != nil {
+		t.Fatalf("Failed to create withdrawal queue schema: %v", err)
+	}
+
+	ctx := context.Background()
+	enqueue := func(key string, amount int64) {
+		_, err := service.EnqueueWithdrawal(ctx, EnqueueWithdrawalParams{
+			UserId:         "user1",
+			Asset:          "USDC",
+			AssetNetwork:   "USDC-ethereum-mainnet",
+			Amount:         decimal.NewFromInt(amount),
+			Destination:    "0xexchange",
+			WalletId:       "hot-wallet",
+			IdempotencyKey: key,
+		})
+		if err != nil {
+			t.Fatalf("EnqueueWithdrawal failed: %v", err)
+		}
+	}
+	enqueue("key-1", 10)
+	enqueue("key-2", 15)
+
+	items, err := service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+	batch, err := service.CreateWithdrawalBatch(ctx, items)
+	if err != nil {
+		t.Fatalf("CreateWithdrawalBatch failed: %v", err)
+	}
+
+	// The worker crashes before recording the submission; a later withdrawal is queued meanwhile
+	enqueue("key-3", 25)
+	if _, err := service.RequeueStaleWithdrawals(ctx); err != nil {
+		t.Fatalf("RequeueStaleWithdrawals failed: %v", err)
+	}
+
+	// Claiming one withdrawal of the batch claims the whole batch
+	reclaimed, err := service.ClaimQueuedWithdrawals(ctx, 1)
+	if err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+	if len(reclaimed) != 2 || reclaimed[0].BatchId != batch.Id || reclaimed[1].BatchId != batch.Id {
+		t.Fatalf("Expected both withdrawals of batch %s reclaimed, got %+v", batch.Id, reclaimed)
+	}
+
+	again, err := service.CreateWithdrawalBatch(ctx, reclaimed)
+	if err != nil {
+		t.Fatalf("CreateWithdrawalBatch failed for the reclaimed batch: %v", err)
+	}
+	if again.Id != batch.Id {
+		t.Errorf("Expected the reclaimed batch to keep id %s, got %s", batch.Id, again.Id)
+	}
+
+	// Part of a batch cannot be paid out under its key
+	if _, err := service.CreateWithdrawalBatch(ctx, reclaimed[:1]); err == nil {
+		t.Error("Expected part of a batch to be rejected")
+	}
+
+	// A withdrawal queued later is claimed without a batch
+	rest, err := service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil || len(rest) != 1 || rest[0].IdempotencyKey != "key-3" || rest[0].BatchId != "" {
+		t.Errorf("Expected key-3 claimed without a batch, got %+v (%v)", rest, err)
+	}
+}
@@ -0,0 +1,475 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+)
+
+const (
+	WithdrawalHoldStatusHeld     = "held"
+	WithdrawalHoldStatusCaptured = "captured"
+	WithdrawalHoldStatusReleased = "released"
+)
+
+func (s *Service) initWithdrawalHoldSchema() error {
+	schema := `
+	-- Customer withdrawals in flight: the amount is out of the available balance but still in the total
+	-- until the withdrawal is captured onto the ledger or released
+	CREATE TABLE IF NOT EXISTS withdrawal_holds (
+		id TEXT PRIMARY KEY,
+		idempotency_key TEXT NOT NULL UNIQUE,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		destination TEXT NOT NULL DEFAULT '',
+		reference TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'held',
+		transaction_id TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		resolved_at TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_withdrawal_holds_user_asset ON withdrawal_holds(user_id, asset, status);
+	`
+
+	_, err := s.db.Exec(schema)
+	return err
+}
+
+// ReserveWithdrawalParams contains the parameters for reserving a customer-initiated withdrawal
+type ReserveWithdrawalParams struct {
+	UserId          string
+	Asset           string
+	Amount          decimal.Decimal
+	IdempotencyKey  string
+	DestinationType string
+	Destination     string
+	// WalletId is the Prime wallet the withdrawal is sent from
+	WalletId string
+	// RefundOf is the ledger id of the deposit a refund returns
+	RefundOf string
+}
+
+// ReserveWithdrawal places a hold for a customer-initiated withdrawal before it is sent to Prime. The amount
+// leaves the available balance but stays in the total until CaptureWithdrawal or ReleaseWithdrawal.
+// Fails with ErrInsufficientBalance instead of reserving more than is available, unless the transaction
+// source on ctx is configured with the sync policy, and with
+// ErrDuplicateTransaction when the idempotency key was already used. The destination and, for refunds,
+// the refunded deposit are recorded on the ledger transaction when the hold is captured. The withdrawal
+// is also recorded in withdrawal_requests, which the listener attributes Prime transactions with.
+func (s *Service) ReserveWithdrawal(ctx context.Context, params ReserveWithdrawalParams) error {
+	if _, err := s.GetUserById(ctx, params.UserId); err != nil {
+		correlation.Logger(ctx, s.logger).Warn("Withdrawal for unknown user", zap.String("user_id", params.UserId))
+		return fmt.Errorf("error getting user: %w", err)
+	}
+
+	reference := fmt.Sprintf("destination_type=%s", destinationTypeOrDefault(params.DestinationType))
+	if params.RefundOf != "" {
+		reference += fmt.Sprintf(" refund_of=%s", params.RefundOf)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	// Withdrawals debited before holds were introduced are on the ledger under their idempotency key
+	var existingId string
+	err = tx.QueryRowContext(ctx, queryCheckDuplicateTransaction, params.IdempotencyKey).Scan(&existingId)
+	if err == nil {
+		return fmt.Errorf("%w: external_transaction_id %s already exists", ErrDuplicateTransaction, params.IdempotencyKey)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to check for duplicate transaction: %w", err)
+	}
+	if _, err := scanWithdrawalHold(tx.QueryRowContext(ctx, querySelectWithdrawalHold, params.IdempotencyKey)); err == nil {
+		return fmt.Errorf("%w: withdrawal %s is already reserved", ErrDuplicateTransaction, params.IdempotencyKey)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("unable to check withdrawal hold: %w", err)
+	}
+
+	available, version, err := availableForUpdate(ctx, tx, params.UserId, params.Asset)
+	if err != nil {
+		return err
+	}
+	if available.LessThan(params.Amount) {
+		if s.balancePolicy(ctx, models.BalancePolicyCustomer) == models.BalancePolicyCustomer {
+			return fmt.Errorf("%w: available=%s, requested=%s", ErrInsufficientBalance, available.String(), params.Amount.String())
+		}
+		correlation.Logger(ctx, s.logger).Warn("Reserving more than the available balance under the sync policy",
+			zap.String("user_id", params.UserId),
+			zap.String("asset", params.Asset),
+			zap.String("available", available.String()),
+			zap.String("requested", params.Amount.String()),
+			zap.String("source", string(TransactionSourceFrom(ctx))))
+	}
+	if err := setAvailable(ctx, tx, params.UserId, params.Asset, available.Sub(params.Amount), version); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, queryInsertWithdrawalHold, uuid.New().String(), params.IdempotencyKey, params.UserId,
+		params.Asset, params.Amount.String(), params.Destination, reference, now)
+	if err != nil {
+		return fmt.Errorf("unable to insert withdrawal hold: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, queryInsertWithdrawalRequest, params.IdempotencyKey, params.UserId, params.Asset,
+		params.Amount.String(), params.WalletId, params.Destination, now, now)
+	if err != nil {
+		return fmt.Errorf("unable to insert withdrawal request: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit withdrawal hold: %w", err)
+	}
+	s.subledger.notifyBalanceChange(params.UserId, params.Asset)
+
+	correlation.Logger(ctx, s.logger).Info("Withdrawal reserved",
+		zap.String("user_id", params.UserId),
+		zap.String("asset", params.Asset),
+		zap.String("amount", params.Amount.String()),
+		zap.String("idempotency_key", params.IdempotencyKey),
+		zap.String("available_after", available.Sub(params.Amount).String()))
+
+	return nil
+}
+
+// GetWithdrawalHold returns the hold placed under an idempotency key, or nil if there is none
+func (s *Service) GetWithdrawalHold(ctx context.Context, idempotencyKey string) (*models.WithdrawalHold, error) {
+	hold, err := scanWithdrawalHold(s.db.QueryRowContext(ctx, querySelectWithdrawalHold, idempotencyKey))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get withdrawal hold: %w", err)
+	}
+	return hold, nil
+}
+
+// CaptureWithdrawal debits a held withdrawal from the ledger, under its idempotency key, once Prime reports
+// it done. The hold already took the amount out of the available balance, so only the total changes. Returns
+// the hold, which is left as it is when it is no longer held, or nil when the key has no hold.
+func (s *Service) CaptureWithdrawal(ctx context.Context, idempotencyKey, note string) (*models.WithdrawalHold, error) {
+	hold, err := s.GetWithdrawalHold(ctx, idempotencyKey)
+	if err != nil || hold == nil || hold.Status != WithdrawalHoldStatusHeld {
+		return hold, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	// The funds have left Prime, so the debit is recorded like a synced withdrawal
+	transaction, negativeEvent, err := s.subledger.applyTransaction(ctx, tx, ProcessTransactionParams{
+		UserId:          hold.UserId,
+		Asset:           hold.Asset,
+		TransactionType: "withdrawal",
+		Amount:          hold.Amount.Neg(),
+		ExternalTxId:    hold.IdempotencyKey,
+		Address:         hold.Destination,
+		Reference:       hold.Reference,
+		Reserved:        true,
+	}, models.BalancePolicySync)
+	if errors.Is(err, ErrDuplicateTransaction) {
+		// Another listener instance captured it first
+		return s.GetWithdrawalHold(ctx, idempotencyKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error capturing withdrawal: %w", err)
+	}
+
+	if err := resolveWithdrawalHold(ctx, tx, hold, WithdrawalHoldStatusCaptured, transaction.Id, note); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit withdrawal capture: %w", err)
+	}
+	s.subledger.notifyBalanceChange(hold.UserId, hold.Asset)
+	if negativeEvent != nil {
+		s.subledger.negativeBalanceHandler(*negativeEvent)
+	}
+
+	hold.Status = WithdrawalHoldStatusCaptured
+	hold.TransactionId = transaction.Id
+	hold.Note = note
+
+	correlation.Logger(ctx, s.logger).Info("Withdrawal captured",
+		zap.String("user_id", hold.UserId),
+		zap.String("asset", hold.Asset),
+		zap.String("amount", hold.Amount.String()),
+		zap.String("idempotency_key", hold.IdempotencyKey),
+		zap.String("transaction_id", transaction.Id))
+
+	return hold, nil
+}
+
+// ReleaseWithdrawal returns a held withdrawal's amount to the available balance when it could not be sent
+// or Prime reports it failed; nothing is written to the ledger. Withdrawals debited before holds were
+// introduced have no hold and are reversed on the ledger instead. Returns ErrDuplicateTransaction when the
+// withdrawal was already released.
+func (s *Service) ReleaseWithdrawal(ctx context.Context, userId, asset string, amount decimal.Decimal, idempotencyKey, note string) error {
+	hold, err := s.GetWithdrawalHold(ctx, idempotencyKey)
+	if err != nil {
+		return err
+	}
+	if hold == nil {
+		return s.ReverseWithdrawal(ctx, userId, asset, amount, idempotencyKey)
+	}
+	switch hold.Status {
+	case WithdrawalHoldStatusReleased:
+		return fmt.Errorf("%w: withdrawal %s was already released", ErrDuplicateTransaction, idempotencyKey)
+	case WithdrawalHoldStatusCaptured:
+		return fmt.Errorf("withdrawal %s was already captured as %s", idempotencyKey, hold.TransactionId)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	if err := resolveWithdrawalHold(ctx, tx, hold, WithdrawalHoldStatusReleased, "", note); err != nil {
+		return err
+	}
+	available, version, err := availableForUpdate(ctx, tx, hold.UserId, hold.Asset)
+	if err != nil {
+		return err
+	}
+	if err := setAvailable(ctx, tx, hold.UserId, hold.Asset, available.Add(hold.Amount), version); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit withdrawal release: %w", err)
+	}
+	s.subledger.notifyBalanceChange(hold.UserId, hold.Asset)
+
+	correlation.Logger(ctx, s.logger).Info("Withdrawal hold released",
+		zap.String("user_id", hold.UserId),
+		zap.String("asset", hold.Asset),
+		zap.String("amount", hold.Amount.String()),
+		zap.String("idempotency_key", hold.IdempotencyKey),
+		zap.String("note", note))
+
+	return nil
+}
+
+// HeldWithdrawalTotals returns, per asset, the amount reserved by withdrawals that are neither captured nor released
+func (s *Service) HeldWithdrawalTotals(ctx context.Context) (map[string]decimal.Decimal, error) {
+	rows, err := s.db.QueryContext(ctx, queryListHeldWithdrawalAmounts, s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query held withdrawals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	totals := make(map[string]decimal.Decimal)
+	for rows.Next() {
+		var asset, amountStr string
+		if err := rows.Scan(&asset, &amountStr); err != nil {
+			return nil, fmt.Errorf("unable to scan held withdrawal: %w", err)
+		}
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse held amount '%s': %w", amountStr, err)
+		}
+		totals[asset] = totals[asset].Add(amount)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating held withdrawals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// ListUnsentWithdrawals returns held withdrawals with no record of reaching Prime, oldest first. A crash
+// between reserving a withdrawal and recording Prime's answer leaves one behind, as does a queued
+// withdrawal the worker gave up on without confirming whether Prime received it.
+func (s *Service) ListUnsentWithdrawals(ctx context.Context) ([]models.UnsentWithdrawal, error) {
+	return s.listUnsentWithdrawals(ctx, "")
+}
+
+// GetUnsentWithdrawal returns the withdrawal reserved under an idempotency key if it has no record of
+// reaching Prime, or nil otherwise
+func (s *Service) GetUnsentWithdrawal(ctx context.Context, idempotencyKey string) (*models.UnsentWithdrawal, error) {
+	if idempotencyKey == "" {
+		return nil, nil
+	}
+	withdrawals, err := s.listUnsentWithdrawals(ctx, idempotencyKey)
+	if err != nil || len(withdrawals) == 0 {
+		return nil, err
+	}
+	return &withdrawals[0], nil
+}
+
+func (s *Service) listUnsentWithdrawals(ctx context.Context, idempotencyKey string) ([]models.UnsentWithdrawal, error) {
+	rows, err := s.db.QueryContext(ctx, queryListUnsentWithdrawals, idempotencyKey, idempotencyKey, s.tenantId, s.tenantId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query unsent withdrawals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var withdrawals []models.UnsentWithdrawal
+	for rows.Next() {
+		var w models.UnsentWithdrawal
+		var amountStr, reference string
+		if err := rows.Scan(&w.IdempotencyKey, &w.UserId, &w.Asset, &amountStr, &reference, &w.Destination,
+			&w.WalletId, &w.QueueId, &w.BatchId, &w.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan unsent withdrawal: %w", err)
+		}
+		w.Amount, err = decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse withdrawal hold amount '%s': %w", amountStr, err)
+		}
+		w.DestinationType = destinationTypeFromReference(reference)
+		withdrawals = append(withdrawals, w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating unsent withdrawals: %w", err)
+	}
+	return withdrawals, nil
+}
+
+// destinationTypeFromReference reads the destination type ReserveWithdrawal records in a hold's reference
+func destinationTypeFromReference(reference string) string {
+	for _, field := range strings.Fields(reference) {
+		if value, ok := strings.CutPrefix(field, "destination_type="); ok {
+			return value
+		}
+	}
+	return destinationTypeOrDefault("")
+}
+
+// resolveWithdrawalHold moves a hold out of the held status, and its request to the matching outcome,
+// failing if another caller resolved it first
+func resolveWithdrawalHold(ctx context.Context, tx *sql.Tx, hold *models.WithdrawalHold, status, transactionId, note string) error {
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, queryResolveWithdrawalHold, status, transactionId, note, now, hold.IdempotencyKey)
+	if err != nil {
+		return fmt.Errorf("unable to update withdrawal hold: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("withdrawal hold %s was resolved concurrently - %w", hold.IdempotencyKey, ErrConcurrentModification)
+	}
+
+	_, _, err = advanceWithdrawalRequest(ctx, tx, hold.IdempotencyKey, WithdrawalTransition{
+		Status: withdrawalRequestStatus(status),
+		Note:   note,
+	})
+	return err
+}
+
+// availableForUpdate reads an account's available balance and version within tx. An account that does
+// not exist yet has nothing available.
+func availableForUpdate(ctx context.Context, tx *sql.Tx, userId, asset string) (decimal.Decimal, int64, error) {
+	var accountId, balanceStr string
+	var availableStr sql.NullString
+	var version int64
+	err := tx.QueryRowContext(ctx, queryGetAccountBalance, userId, asset).Scan(&accountId, &balanceStr, &availableStr, &version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return decimal.Zero, 0, nil
+	}
+	if err != nil {
+		return decimal.Zero, 0, fmt.Errorf("failed to get account balance: %w", err)
+	}
+
+	balance, err := decimal.NewFromString(balanceStr)
+	if err != nil {
+		return decimal.Zero, 0, fmt.Errorf("failed to parse balance '%s': %w", balanceStr, err)
+	}
+	available, err := parseAvailable(availableStr, balance)
+	if err != nil {
+		return decimal.Zero, 0, err
+	}
+	return available, version, nil
+}
+
+// setAvailable writes an account's available balance if its version is unchanged
+func setAvailable(ctx context.Context, tx *sql.Tx, userId, asset string, available decimal.Decimal, version int64) error {
+	result, err := tx.ExecContext(ctx, queryAdjustAvailableBalance, available.String(), userId, asset, version)
+	if err != nil {
+		return fmt.Errorf("failed to update available balance: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("available balance update failed - %w", ErrConcurrentModification)
+	}
+	return nil
+}
+
+func scanWithdrawalHold(row rowScanner) (*models.WithdrawalHold, error) {
+	var hold models.WithdrawalHold
+	var amountStr string
+	var resolvedAt sql.NullTime
+	if err := row.Scan(&hold.Id, &hold.IdempotencyKey, &hold.UserId, &hold.Asset, &amountStr, &hold.Destination,
+		&hold.Reference, &hold.Status, &hold.TransactionId, &hold.Note, &hold.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	hold.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse withdrawal hold amount '%s': %w", amountStr, err)
+	}
+	if resolvedAt.Valid {
+		hold.ResolvedAt = &resolvedAt.Time
+	}
+
+	return &hold, nil
+}
@@ -0,0 +1,264 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawalHold_ReserveCaptureRelease(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-1",
+	}); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	expectBalances := func(balance, available int64) {
+		t.Helper()
+		total, err := service.GetUserBalance(ctx, "user1", "ETH")
+		if err != nil || !total.Equal(decimal.NewFromInt(balance)) {
+			t.Errorf("Expected balance %d, got %s (%v)", balance, total, err)
+		}
+		spendable, err := service.GetAvailableBalance(ctx, "user1", "ETH")
+		if err != nil || !spendable.Equal(decimal.NewFromInt(available)) {
+			t.Errorf("Expected available balance %d, got %s (%v)", available, spendable, err)
+		}
+	}
+
+	reserve := func(key string, amount int64) error {
+		return service.ReserveWithdrawal(ctx, ReserveWithdrawalParams{
+			UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(amount), IdempotencyKey: key, Destination: "0xexternal",
+		})
+	}
+
+	// Reserving takes the amount out of what is available but leaves the total alone
+	if err := reserve("wd-1", 4); err != nil {
+		t.Fatalf("ReserveWithdrawal failed: %v", err)
+	}
+	expectBalances(10, 6)
+	if err := reserve("wd-1", 4); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("Expected a reused key to be rejected, got %v", err)
+	}
+	if err := reserve("wd-2", 7); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("Expected ErrInsufficientBalance, got %v", err)
+	}
+
+	// Held withdrawals count against a daily limit
+	withdrawn, err := service.WithdrawnSince(ctx, "user1", "ETH", time.Now().Add(-time.Hour))
+	if err != nil || !withdrawn.Equal(decimal.NewFromInt(4)) {
+		t.Errorf("Expected 4 withdrawn while held, got %s (%v)", withdrawn, err)
+	}
+
+	held, err := service.HeldWithdrawalTotals(ctx)
+	if err != nil || !held["ETH"].Equal(decimal.NewFromInt(4)) {
+		t.Errorf("Expected 4 ETH held, got %v (%v)", held, err)
+	}
+
+	// Capturing debits the ledger under the idempotency key without touching the available balance again
+	hold, err := service.CaptureWithdrawal(ctx, "wd-1", "prime-tx-1")
+	if err != nil || hold == nil || hold.Status != WithdrawalHoldStatusCaptured || hold.TransactionId == "" {
+		t.Fatalf("Expected the hold to be captured, got %+v (%v)", hold, err)
+	}
+	expectBalances(6, 6)
+	if hold, err := service.CaptureWithdrawal(ctx, "wd-1", "prime-tx-1"); err != nil || hold.Status != WithdrawalHoldStatusCaptured {
+		t.Errorf("Expected a second capture to leave the hold as it is, got %+v (%v)", hold, err)
+	}
+	if err := service.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(4), "wd-1", "failed"); err == nil {
+		t.Error("Expected a captured withdrawal not to be released")
+	}
+	withdrawn, err = service.WithdrawnSince(ctx, "user1", "ETH", time.Now().Add(-time.Hour))
+	if err != nil || !withdrawn.Equal(decimal.NewFromInt(4)) {
+		t.Errorf("Expected 4 withdrawn once captured, got %s (%v)", withdrawn, err)
+	}
+
+	// Releasing returns the amount to what is available and writes nothing to the ledger
+	if err := reserve("wd-3", 5); err != nil {
+		t.Fatalf("ReserveWithdrawal failed: %v", err)
+	}
+	expectBalances(6, 1)
+	if err := service.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(5), "wd-3", "prime unavailable"); err != nil {
+		t.Fatalf("ReleaseWithdrawal failed: %v", err)
+	}
+	expectBalances(6, 6)
+	if err := service.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(5), "wd-3", "prime unavailable"); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("Expected a second release to be a duplicate, got %v", err)
+	}
+	if hold, err := service.CaptureWithdrawal(ctx, "wd-3", "prime-tx-3"); err != nil || hold.Status != WithdrawalHoldStatusReleased {
+		t.Errorf("Expected a released hold not to be captured, got %+v (%v)", hold, err)
+	}
+	activity, err := service.SummarizeActivity(ctx, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SummarizeActivity failed: %v", err)
+	}
+	released := false
+	for _, total := range activity {
+		if total.Type == models.ActivityReversal && total.Count == 1 && total.Total.Equal(decimal.NewFromInt(5)) {
+			released = true
+		}
+	}
+	if !released {
+		t.Errorf("Expected the released hold to be summarized as a reversal, got %+v", activity)
+	}
+	history, err := service.GetTransactionHistory(ctx, "user1", "ETH", 10, "")
+	if err != nil || len(history) != 2 {
+		t.Errorf("Expected only the deposit and the captured withdrawal on the ledger, got %d entries (%v)", len(history), err)
+	}
+
+	// Withdrawals debited before holds were introduced are reversed on the ledger instead
+	if err := service.processWithdrawal(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(2), ExternalTxId: "legacy-wd",
+	}, models.BalancePolicyCustomer); err != nil {
+		t.Fatalf("Failed to debit legacy withdrawal: %v", err)
+	}
+	if err := reserve("legacy-wd", 1); !errors.Is(err, ErrDuplicateTransaction) {
+		t.Errorf("Expected a key already debited to be rejected, got %v", err)
+	}
+	if err := service.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(2), "legacy-wd", "failed"); err != nil {
+		t.Fatalf("ReleaseWithdrawal of a legacy debit failed: %v", err)
+	}
+	expectBalances(6, 6)
+	if reversal, err := service.FindReversal(ctx, "legacy-wd"); err != nil || reversal == nil {
+		t.Errorf("Expected the legacy debit to be reversed, got %+v (%v)", reversal, err)
+	}
+}
+
+func TestBalancePolicy_PerTransactionSource(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{BalancePolicies: map[string]string{"listener": "customer", "cli": "sync"}})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-1",
+	}); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	// Backfilled history is replayed as it happened, even below zero
+	backfill := WithTransactionSource(ctx, models.TransactionSourceBackfill)
+	if err := service.ProcessWithdrawal(backfill, "user1", "ETH", decimal.NewFromInt(15), "wd-old"); err != nil {
+		t.Fatalf("Backfilled withdrawal failed: %v", err)
+	}
+
+	// The listener was configured to reject overdrafts
+	listener := WithTransactionSource(ctx, models.TransactionSourceListener)
+	if err := service.ProcessWithdrawal(listener, "user1", "ETH", decimal.NewFromInt(1), "wd-live"); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("Expected ErrInsufficientBalance for the listener, got %v", err)
+	}
+
+	reserve := func(ctx context.Context, key string) error {
+		return service.ReserveWithdrawal(ctx, ReserveWithdrawalParams{
+			UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(1), IdempotencyKey: key, Destination: "0xexternal",
+		})
+	}
+	if err := reserve(WithTransactionSource(ctx, models.TransactionSourceApi), "wd-api"); !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("Expected ErrInsufficientBalance for the api, got %v", err)
+	}
+	if err := reserve(WithTransactionSource(ctx, models.TransactionSourceCli), "wd-cli"); err != nil {
+		t.Errorf("Expected the cli to reserve under the sync policy, got %v", err)
+	}
+
+	balance, err := service.GetUserBalance(ctx, "user1", "ETH")
+	if err != nil || !balance.Equal(decimal.NewFromInt(-5)) {
+		t.Errorf("Expected balance -5, got %s (%v)", balance, err)
+	}
+}
+
+func TestListUnsentWithdrawals(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-1",
+	}); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	for _, key := range []string{"wd-sent", "wd-queued", "wd-failed", "wd-unsent"} {
+		if err := service.ReserveWithdrawal(ctx, ReserveWithdrawalParams{
+			UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(1), IdempotencyKey: key,
+			DestinationType: "counterparty", Destination: "cp-1", WalletId: "wallet-1",
+		}); err != nil {
+			t.Fatalf("ReserveWithdrawal failed: %v", err)
+		}
+	}
+	if err := service.MarkWithdrawalRequestSubmitted(ctx, "wd-sent", "activity-1"); err != nil {
+		t.Fatalf("MarkWithdrawalRequestSubmitted failed: %v", err)
+	}
+	queueIds := make(map[string]string)
+	for _, key := range []string{"wd-queued", "wd-failed"} {
+		id, err := service.EnqueueWithdrawal(ctx, EnqueueWithdrawalParams{
+			UserId: "user1", Asset: "ETH", AssetNetwork: "ETH-ethereum-mainnet", Amount: decimal.NewFromInt(1),
+			Destination: "cp-1", WalletId: "wallet-1", IdempotencyKey: key,
+		})
+		if err != nil {
+			t.Fatalf("EnqueueWithdrawal failed: %v", err)
+		}
+		queueIds[key] = id
+	}
+	// The worker gave up on wd-failed without learning whether Prime received it
+	if err := service.MarkWithdrawalFailed(ctx, queueIds["wd-failed"], "unconfirmed at Prime: timeout"); err != nil {
+		t.Fatalf("MarkWithdrawalFailed failed: %v", err)
+	}
+
+	unsent, err := service.ListUnsentWithdrawals(ctx)
+	if err != nil {
+		t.Fatalf("ListUnsentWithdrawals failed: %v", err)
+	}
+	if len(unsent) != 2 || unsent[0].IdempotencyKey != "wd-failed" || unsent[1].IdempotencyKey != "wd-unsent" {
+		t.Fatalf("Expected wd-failed and wd-unsent, got %+v", unsent)
+	}
+	if unsent[0].QueueId != queueIds["wd-failed"] || unsent[1].QueueId != "" {
+		t.Errorf("Expected only wd-failed to carry its queue id, got %q and %q", unsent[0].QueueId, unsent[1].QueueId)
+	}
+	w := unsent[1]
+	if w.UserId != "user1" || !w.Amount.Equal(decimal.NewFromInt(1)) || w.DestinationType != "counterparty" || w.Destination != "cp-1" || w.WalletId != "wallet-1" {
+		t.Errorf("Unexpected unsent withdrawal: %+v", w)
+	}
+
+	if got, err := service.GetUnsentWithdrawal(ctx, "wd-sent"); err != nil || got != nil {
+		t.Errorf("Expected a submitted withdrawal not to be unsent, got %+v (%v)", got, err)
+	}
+
+	// Once released it no longer needs recovery
+	if err := service.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(1), "wd-unsent", "not received by Prime"); err != nil {
+		t.Fatalf("ReleaseWithdrawal failed: %v", err)
+	}
+	if got, err := service.GetUnsentWithdrawal(ctx, "wd-unsent"); err != nil || got != nil {
+		t.Errorf("Expected a released withdrawal not to be unsent, got %+v (%v)", got, err)
+	}
+}
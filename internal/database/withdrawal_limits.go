@@ -0,0 +1,79 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// WithdrawnSince sums a user's withdrawals of an asset made at or after since, including withdrawals still
+// held while Prime processes them. Withdrawals that were rolled back or released are left out, so a failed
+// withdrawal does not count against a limit.
+func (s *Service) WithdrawnSince(ctx context.Context, userId, asset string, since time.Time) (decimal.Decimal, error) {
+	debited, err := s.sumWithdrawalsSince(ctx, queryListWithdrawalsSince, userId, asset, since)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	held, err := s.sumWithdrawalsSince(ctx, queryListHeldWithdrawalsSince, userId, asset, since)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return debited.Add(held), nil
+}
+
+// sumWithdrawalsSince totals the absolute amounts a withdrawal query returns for rows created at or after since
+func (s *Service) sumWithdrawalsSince(ctx context.Context, query, userId, asset string, since time.Time) (decimal.Decimal, error) {
+	rows, err := s.db.QueryContext(ctx, query, userId, asset, createdAtFrom(since))
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to list withdrawals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	total := decimal.Zero
+	for rows.Next() {
+		var amountStr string
+		var createdAt time.Time
+		if err := rows.Scan(&amountStr, &createdAt); err != nil {
+			return decimal.Zero, fmt.Errorf("failed to scan withdrawal: %w", err)
+		}
+		if createdAt.Before(since) {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to parse withdrawal amount '%s': %w", amountStr, err)
+		}
+		// Ledger withdrawals are recorded as negative amounts, holds as positive ones
+		total = total.Add(amount.Abs())
+	}
+	if err := rows.Err(); err != nil {
+		return decimal.Zero, fmt.Errorf("failed to iterate withdrawals: %w", err)
+	}
+
+	return total, nil
+}
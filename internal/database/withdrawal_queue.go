@@ -0,0 +1,295 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Withdrawal queue statuses
+const (
+	WithdrawalQueueStatusQueued     = "queued"
+	WithdrawalQueueStatusProcessing = "processing"
+	WithdrawalQueueStatusSubmitted  = "submitted"
+	WithdrawalQueueStatusFailed     = "failed"
+)
+
+// EnqueueWithdrawalParams contains the parameters for queueing a withdrawal
+type EnqueueWithdrawalParams struct {
+	UserId          string
+	Asset           string
+	AssetNetwork    string
+	Amount          decimal.Decimal
+	DestinationType string
+	Destination     string
+	WalletId        string
+	IdempotencyKey  string
+	// CorrelationId links the worker's log lines to the request that queued the withdrawal
+	CorrelationId string
+}
+
+func (s *Service) initWithdrawalQueueSchema() error {
+	schema := `
+	-- Withdrawals waiting to be submitted to Prime by the background worker
+	CREATE TABLE IF NOT EXISTS withdrawal_queue (
+		id TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		asset_network TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		destination_type TEXT NOT NULL DEFAULT 'address',
+		destination TEXT NOT NULL,
+		wallet_id TEXT NOT NULL,
+		idempotency_key TEXT NOT NULL UNIQUE,
+		status TEXT NOT NULL DEFAULT 'queued',
+		attempts INTEGER NOT NULL DEFAULT 0,
+		last_error TEXT,
+		activity_id TEXT,
+		next_attempt_at TIMESTAMP NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_withdrawal_queue_status_next ON withdrawal_queue(status, next_attempt_at);
+	CREATE INDEX IF NOT EXISTS idx_withdrawal_queue_user_id ON withdrawal_queue(user_id);
+
+	-- Prime withdrawals that pay out several queued withdrawals at once
+	CREATE TABLE IF NOT EXISTS withdrawal_batches (
+		id TEXT PRIMARY KEY,
+		asset TEXT NOT NULL,
+		asset_network TEXT NOT NULL,
+		wallet_id TEXT NOT NULL,
+		destination_type TEXT NOT NULL,
+		destination TEXT NOT NULL,
+		total_amount TEXT NOT NULL,
+		item_count INTEGER NOT NULL,
+		activity_id TEXT,
+		status TEXT NOT NULL DEFAULT 'pending',
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+
+	// Queues created before non-address destinations were supported
+	if err := addColumnIfMissing(s.db, s.logger, "withdrawal_queue", "destination_type", "TEXT NOT NULL DEFAULT 'address'"); err != nil {
+		return err
+	}
+
+	// Queues created before batching was supported
+	if err := addColumnIfMissing(s.db, s.logger, "withdrawal_queue", "batch_id", "TEXT"); err != nil {
+		return err
+	}
+
+	// Queues created before correlation ids were recorded
+	if err := addColumnIfMissing(s.db, s.logger, "withdrawal_queue", "correlation_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+
+	_, err := s.db.Exec(`CREATE INDEX IF NOT EXISTS idx_withdrawal_queue_batch_id ON withdrawal_queue(batch_id)`)
+	return err
+}
+
+// EnqueueWithdrawal stores a withdrawal for asynchronous submission to Prime.
+// Funds must already be reserved with a withdrawal hold before the withdrawal is queued.
+func (s *Service) EnqueueWithdrawal(ctx context.Context, params EnqueueWithdrawalParams) (string, error) {
+	id := uuid.New().String()
+
+	correlation.Logger(ctx, s.logger).Info("Enqueueing withdrawal",
+		zap.String("queue_id", id),
+		zap.String("user_id", params.UserId),
+		zap.String("asset", params.AssetNetwork),
+		zap.String("amount", params.Amount.String()),
+		zap.String("idempotency_key", params.IdempotencyKey))
+
+	_, err := s.db.ExecContext(ctx, queryEnqueueWithdrawal,
+		id, params.UserId, params.Asset, params.AssetNetwork, params.Amount.String(),
+		destinationTypeOrDefault(params.DestinationType), params.Destination, params.WalletId, params.IdempotencyKey, params.CorrelationId, time.Now().UTC())
+	if err != nil {
+		return "", fmt.Errorf("unable to enqueue withdrawal: %w", err)
+	}
+
+	return id, nil
+}
+
+// ClaimQueuedWithdrawals marks up to limit due withdrawals as processing and returns them
+func (s *Service) ClaimQueuedWithdrawals(ctx context.Context, limit int) ([]models.QueuedWithdrawal, error) {
+	rows, err := s.db.QueryContext(ctx, queryClaimQueuedWithdrawals, time.Now().UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to claim queued withdrawals: %w", err)
+	}
+	return scanQueuedWithdrawals(rows, s.logger)
+}
+
+// MarkWithdrawalSubmitted records a successful Prime submission for a queued withdrawal
+func (s *Service) MarkWithdrawalSubmitted(ctx context.Context, id, activityId string) error {
+	if _, err := s.db.ExecContext(ctx, queryMarkWithdrawalSubmitted, activityId, id); err != nil {
+		return fmt.Errorf("unable to mark withdrawal submitted: %w", err)
+	}
+	return nil
+}
+
+// ScheduleWithdrawalRetry puts a queued withdrawal back in the queue after a failed attempt
+func (s *Service) ScheduleWithdrawalRetry(ctx context.Context, id, lastError string, nextAttemptAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, queryScheduleWithdrawalRetry, lastError, nextAttemptAt.UTC(), id); err != nil {
+		return fmt.Errorf("unable to schedule withdrawal retry: %w", err)
+	}
+	return nil
+}
+
+// DeferQueuedWithdrawal returns a withdrawal to the queue without counting an attempt, e.g. while its
+// source wallet is being topped up
+func (s *Service) DeferQueuedWithdrawal(ctx context.Context, id, reason string, nextAttemptAt time.Time) error {
+	if _, err := s.db.ExecContext(ctx, queryDeferQueuedWithdrawal, reason, nextAttemptAt.UTC(), id); err != nil {
+		return fmt.Errorf("unable to defer queued withdrawal: %w", err)
+	}
+	return nil
+}
+
+// MarkWithdrawalFailed marks a queued withdrawal as permanently failed
+func (s *Service) MarkWithdrawalFailed(ctx context.Context, id, lastError string) error {
+	if _, err := s.db.ExecContext(ctx, queryMarkWithdrawalFailed, lastError, id); err != nil {
+		return fmt.Errorf("unable to mark withdrawal failed: %w", err)
+	}
+	return nil
+}
+
+// RequeueStaleWithdrawals returns withdrawals left in processing (e.g. after a crash) to the queue.
+// Resubmission is safe because Prime deduplicates on the idempotency key.
+func (s *Service) RequeueStaleWithdrawals(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, queryRequeueStaleWithdrawals)
+	if err != nil {
+		return 0, fmt.Errorf("unable to requeue stale withdrawals: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// ListQueuedWithdrawals returns the most recent queue entries, optionally filtered by status
+func (s *Service) ListQueuedWithdrawals(ctx context.Context, status string, limit int) ([]models.QueuedWithdrawal, error) {
+	rows, err := s.db.QueryContext(ctx, queryListQueuedWithdrawals, status, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list queued withdrawals: %w", err)
+	}
+	return scanQueuedWithdrawals(rows, s.logger)
+}
+
+// CountQueuedWithdrawalsByStatus returns the number of queue entries per status
+func (s *Service) CountQueuedWithdrawalsByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, queryCountQueuedWithdrawalsByStatus)
+	if err != nil {
+		return nil, fmt.Errorf("unable to count queued withdrawals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("unable to scan queue count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queue counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// PendingWithdrawalTotals returns, per asset, the amount reserved by withdrawals that have not been submitted to Prime yet
+func (s *Service) PendingWithdrawalTotals(ctx context.Context) (map[string]decimal.Decimal, error) {
+	rows, err := s.db.QueryContext(ctx, queryListPendingWithdrawalAmounts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query pending withdrawals: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	totals := make(map[string]decimal.Decimal)
+	for rows.Next() {
+		var asset, amountStr string
+		if err := rows.Scan(&asset, &amountStr); err != nil {
+			return nil, fmt.Errorf("unable to scan pending withdrawal: %w", err)
+		}
+		amount, err := decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse queued amount '%s': %w", amountStr, err)
+		}
+		totals[asset] = totals[asset].Add(amount)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating pending withdrawals: %w", err)
+	}
+
+	return totals, nil
+}
+
+func scanQueuedWithdrawals(rows *sql.Rows, logger *zap.Logger) ([]models.QueuedWithdrawal, error) {
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var withdrawals []models.QueuedWithdrawal
+	for rows.Next() {
+		var w models.QueuedWithdrawal
+		var amountStr string
+		err := rows.Scan(&w.Id, &w.UserId, &w.Asset, &w.AssetNetwork, &amountStr, &w.DestinationType, &w.Destination,
+			&w.WalletId, &w.IdempotencyKey, &w.Status, &w.Attempts, &w.LastError, &w.ActivityId,
+			&w.NextAttemptAt, &w.CreatedAt, &w.UpdatedAt, &w.CorrelationId, &w.BatchId)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan queued withdrawal: %w", err)
+		}
+
+		w.Amount, err = decimal.NewFromString(amountStr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse queued amount '%s': %w", amountStr, err)
+		}
+
+		withdrawals = append(withdrawals, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating queued withdrawals: %w", err)
+	}
+
+	return withdrawals, nil
+}
@@ -0,0 +1,165 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawalQueue_ClaimAndRetry(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initWithdrawalQueueSchema(); err != nil {
+		t.Fatalf("Failed to create withdrawal queue schema: %v", err)
+	}
+
+	ctx := context.Background()
+	id, err := service.EnqueueWithdrawal(ctx, EnqueueWithdrawalParams{
+		UserId:         "user1",
+		Asset:          "BTC",
+		AssetNetwork:   "BTC-bitcoin-mainnet",
+		Amount:         decimal.NewFromFloat(0.25),
+		Destination:    "bc1qdestination",
+		WalletId:       "wallet1",
+		IdempotencyKey: "user1-key",
+	})
+	if err != nil {
+		t.Fatalf("EnqueueWithdrawal failed: %v", err)
+	}
+
+	claimed, err := service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Id != id {
+		t.Fatalf("Expected to claim queued withdrawal %s, got %+v", id, claimed)
+	}
+	if claimed[0].Status != WithdrawalQueueStatusProcessing {
+		t.Errorf("Expected status %s, got %s", WithdrawalQueueStatusProcessing, claimed[0].Status)
+	}
+	if !claimed[0].Amount.Equal(decimal.NewFromFloat(0.25)) {
+		t.Errorf("Expected amount 0.25, got %s", claimed[0].Amount.String())
+	}
+
+	// A claimed withdrawal must not be handed out twice
+	again, err := service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("Second ClaimQueuedWithdrawals failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("Expected no claimable withdrawals, got %d", len(again))
+	}
+
+	// Retries scheduled in the future are not claimable yet
+	if err := service.ScheduleWithdrawalRetry(ctx, id, "prime unavailable", time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("ScheduleWithdrawalRetry failed: %v", err)
+	}
+	again, err = service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("Third ClaimQueuedWithdrawals failed: %v", err)
+	}
+	if len(again) != 0 {
+		t.Fatalf("Expected retry to be deferred, got %d claimable", len(again))
+	}
+
+	counts, err := service.CountQueuedWithdrawalsByStatus(ctx)
+	if err != nil {
+		t.Fatalf("CountQueuedWithdrawalsByStatus failed: %v", err)
+	}
+	if counts[WithdrawalQueueStatusQueued] != 1 {
+		t.Errorf("Expected 1 queued withdrawal, got %d", counts[WithdrawalQueueStatusQueued])
+	}
+}
+
+func TestWithdrawalQueue_DeferForTopUp(t *testing.T) {
+	service, cleanup := setupBalanceTestDB(t)
+	defer cleanup()
+
+	if err := service.initWithdrawalQueueSchema(); err != nil {
+		t.Fatalf("Failed to create withdrawal queue schema: %v", err)
+	}
+	if err := service.initTreasurySchema(); err != nil {
+		t.Fatalf("Failed to create treasury schema: %v", err)
+	}
+
+	ctx := context.Background()
+	id, err := service.EnqueueWithdrawal(ctx, EnqueueWithdrawalParams{
+		UserId:         "user1",
+		Asset:          "USDC",
+		AssetNetwork:   "USDC-ethereum-mainnet",
+		Amount:         decimal.NewFromInt(500),
+		Destination:    "0xdestination",
+		WalletId:       "hot-wallet",
+		IdempotencyKey: "user1-key",
+	})
+	if err != nil {
+		t.Fatalf("EnqueueWithdrawal failed: %v", err)
+	}
+
+	pending, err := service.PendingWithdrawalTotals(ctx)
+	if err != nil {
+		t.Fatalf("PendingWithdrawalTotals failed: %v", err)
+	}
+	if !pending["USDC"].Equal(decimal.NewFromInt(500)) {
+		t.Errorf("Expected 500 USDC pending, got %s", pending["USDC"].String())
+	}
+
+	if _, err := service.ClaimQueuedWithdrawals(ctx, 10); err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+	if err := service.DeferQueuedWithdrawal(ctx, id, "awaiting hot wallet top-up", time.Now().Add(-time.Second)); err != nil {
+		t.Fatalf("DeferQueuedWithdrawal failed: %v", err)
+	}
+
+	// Deferring does not use up a retry attempt
+	claimed, err := service.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].Attempts != 0 {
+		t.Fatalf("Expected deferred withdrawal to be claimable with 0 attempts, got %+v", claimed)
+	}
+
+	topUpId, err := service.RecordTreasuryTopUp(ctx, "USDC", "vault-wallet", "hot-wallet", decimal.NewFromInt(500), "activity-1")
+	if err != nil {
+		t.Fatalf("RecordTreasuryTopUp failed: %v", err)
+	}
+
+	open, err := service.GetOpenTreasuryTopUp(ctx, "hot-wallet")
+	if err != nil {
+		t.Fatalf("GetOpenTreasuryTopUp failed: %v", err)
+	}
+	if open == nil || open.Id != topUpId {
+		t.Fatalf("Expected open top-up %s, got %+v", topUpId, open)
+	}
+
+	if err := service.UpdateTreasuryTopUpStatus(ctx, topUpId, TopUpStatusFunded); err != nil {
+		t.Fatalf("UpdateTreasuryTopUpStatus failed: %v", err)
+	}
+	open, err = service.GetOpenTreasuryTopUp(ctx, "hot-wallet")
+	if err != nil {
+		t.Fatalf("GetOpenTreasuryTopUp failed: %v", err)
+	}
+	if open != nil {
+		t.Errorf("Expected no open top-up after funding, got %+v", open)
+	}
+}
@@ -0,0 +1,322 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+)
+
+// Withdrawal request statuses. A request is created with its withdrawal hold, submitted once Prime accepts
+// it, pending while Prime processes it, and ends completed, failed or cancelled.
+const (
+	WithdrawalRequestStatusCreated   = "created"
+	WithdrawalRequestStatusSubmitted = "submitted"
+	WithdrawalRequestStatusPending   = "pending"
+	WithdrawalRequestStatusCompleted = "completed"
+	WithdrawalRequestStatusFailed    = "failed"
+	WithdrawalRequestStatusCancelled = "cancelled"
+)
+
+// withdrawalRequestTransitions lists the statuses each status may move to. The listener can see a
+// withdrawal before its submission is recorded, so created may skip ahead. Releasing a hold marks its
+// request failed until the listener reports whether Prime cancelled it, and a failed withdrawal may still
+// complete when Prime sends it after its hold was released. A request may also stay in its status to
+// record a new Prime status.
+var withdrawalRequestTransitions = map[string][]string{
+	WithdrawalRequestStatusCreated: {WithdrawalRequestStatusSubmitted, WithdrawalRequestStatusPending,
+		WithdrawalRequestStatusCompleted, WithdrawalRequestStatusFailed, WithdrawalRequestStatusCancelled},
+	WithdrawalRequestStatusSubmitted: {WithdrawalRequestStatusPending, WithdrawalRequestStatusCompleted,
+		WithdrawalRequestStatusFailed, WithdrawalRequestStatusCancelled},
+	WithdrawalRequestStatusPending: {WithdrawalRequestStatusCompleted, WithdrawalRequestStatusFailed,
+		WithdrawalRequestStatusCancelled},
+	WithdrawalRequestStatusFailed: {WithdrawalRequestStatusCompleted, WithdrawalRequestStatusCancelled},
+}
+
+// WithdrawalTransition moves a withdrawal request to a new status. Empty fields leave the stored values
+// as they are; a request already linked to a Prime transaction keeps its first one.
+type WithdrawalTransition struct {
+	Status             string
+	PrimeStatus        string
+	PrimeTransactionId string
+	ActivityId         string
+	Note               string
+}
+
+// WithdrawalRequestFilter selects the requests ListWithdrawalRequests returns; empty fields match all
+type WithdrawalRequestFilter struct {
+	UserId string
+	Status string
+	Limit  int
+}
+
+func (s *Service) initWithdrawalRequestSchema() error {
+	var existing int
+	if err := s.db.QueryRow(queryCountTable, "withdrawal_requests").Scan(&existing); err != nil {
+		return err
+	}
+
+	schema := `
+	-- Customer withdrawals by idempotency key and where they are in their lifecycle. The listener
+	-- attributes withdrawal transactions to users through this table.
+	CREATE TABLE IF NOT EXISTS withdrawal_requests (
+		idempotency_key TEXT PRIMARY KEY,
+		user_id TEXT NOT NULL,
+		asset TEXT NOT NULL,
+		amount TEXT NOT NULL,
+		wallet_id TEXT NOT NULL DEFAULT '',
+		destination TEXT NOT NULL DEFAULT '',
+		status TEXT NOT NULL DEFAULT 'created',
+		activity_id TEXT NOT NULL DEFAULT '',
+		prime_transaction_id TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL,
+		updated_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_withdrawal_requests_user_id ON withdrawal_requests(user_id);
+
+	-- Every status change of a withdrawal request, oldest first
+	CREATE TABLE IF NOT EXISTS withdrawal_request_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		idempotency_key TEXT NOT NULL,
+		from_status TEXT NOT NULL,
+		to_status TEXT NOT NULL,
+		prime_status TEXT NOT NULL DEFAULT '',
+		note TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_withdrawal_request_events_key ON withdrawal_request_events(idempotency_key);
+	`
+
+	if _, err := s.db.Exec(schema); err != nil {
+		return err
+	}
+	if err := addColumnIfMissing(s.db, s.logger, "withdrawal_requests", "prime_status", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	// Requests were created as reserved before the lifecycle had a created status
+	if _, err := s.db.Exec(queryRenameReservedWithdrawalRequests); err != nil {
+		return fmt.Errorf("unable to migrate withdrawal request statuses: %w", err)
+	}
+	if existing > 0 {
+		return nil
+	}
+
+	// Withdrawals made before this table existed are recorded from their holds, queue entries and ledger
+	// debits, so the listener can still attribute them when Prime reports them
+	for _, backfill := range []string{queryBackfillRequestsFromHolds, queryBackfillRequestsFromQueue, queryBackfillRequestsFromLedger} {
+		if _, err := s.db.Exec(backfill); err != nil {
+			return fmt.Errorf("unable to backfill withdrawal requests: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetWithdrawalRequest returns the withdrawal request made under an idempotency key, or nil if there is none
+func (s *Service) GetWithdrawalRequest(ctx context.Context, idempotencyKey string) (*models.WithdrawalRequestRecord, error) {
+	request, err := scanWithdrawalRequest(s.db.QueryRowContext(ctx, querySelectWithdrawalRequests+" WHERE idempotency_key = ?", idempotencyKey))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get withdrawal request: %w", err)
+	}
+	return request, nil
+}
+
+// ListWithdrawalRequests returns withdrawal requests, newest first
+func (s *Service) ListWithdrawalRequests(ctx context.Context, filter WithdrawalRequestFilter) ([]models.WithdrawalRequestRecord, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := s.db.QueryContext(ctx, querySelectWithdrawalRequests+`
+		WHERE (? = '' OR user_id = ?) AND (? = '' OR status = ?)
+		ORDER BY created_at DESC LIMIT ?`,
+		filter.UserId, filter.UserId, filter.Status, filter.Status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query withdrawal requests: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var requests []models.WithdrawalRequestRecord
+	for rows.Next() {
+		request, err := scanWithdrawalRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating withdrawal requests: %w", err)
+	}
+	return requests, nil
+}
+
+// ListWithdrawalRequestEvents returns the status changes of a withdrawal request, oldest first
+func (s *Service) ListWithdrawalRequestEvents(ctx context.Context, idempotencyKey string) ([]models.WithdrawalStatusEvent, error) {
+	rows, err := s.db.QueryContext(ctx, queryListWithdrawalRequestEvents, idempotencyKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to query withdrawal request events: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			s.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var events []models.WithdrawalStatusEvent
+	for rows.Next() {
+		var event models.WithdrawalStatusEvent
+		if err := rows.Scan(&event.FromStatus, &event.ToStatus, &event.PrimeStatus, &event.Note, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("unable to scan withdrawal request event: %w", err)
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating withdrawal request events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkWithdrawalRequestSubmitted records that Prime accepted a withdrawal
+func (s *Service) MarkWithdrawalRequestSubmitted(ctx context.Context, idempotencyKey, activityId string) error {
+	_, err := s.AdvanceWithdrawalRequest(ctx, idempotencyKey, WithdrawalTransition{
+		Status:     WithdrawalRequestStatusSubmitted,
+		ActivityId: activityId,
+	})
+	return err
+}
+
+// AdvanceWithdrawalRequest moves a withdrawal request to a new status and records the change. Returns
+// false, leaving the request as it is, when there is no request under the key, when the move is not
+// allowed from its current status, or when nothing would change.
+func (s *Service) AdvanceWithdrawalRequest(ctx context.Context, idempotencyKey string, transition WithdrawalTransition) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			s.logger.Warn("Failed to rollback transaction", zap.Error(err))
+		}
+	}()
+
+	advanced, from, err := advanceWithdrawalRequest(ctx, tx, idempotencyKey, transition)
+	if err != nil || !advanced {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit withdrawal request update: %w", err)
+	}
+
+	correlation.Logger(ctx, s.logger).Debug("Withdrawal request advanced",
+		zap.String("idempotency_key", idempotencyKey),
+		zap.String("from_status", from),
+		zap.String("to_status", transition.Status),
+		zap.String("prime_status", transition.PrimeStatus))
+	return true, nil
+}
+
+// advanceWithdrawalRequest applies a transition within tx and returns the status it moved from
+func advanceWithdrawalRequest(ctx context.Context, tx *sql.Tx, idempotencyKey string, transition WithdrawalTransition) (bool, string, error) {
+	var current, primeStatus string
+	err := tx.QueryRowContext(ctx, querySelectWithdrawalRequestStatus, idempotencyKey).Scan(&current, &primeStatus)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, "", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("unable to get withdrawal request status: %w", err)
+	}
+	if current == transition.Status {
+		if transition.PrimeStatus == "" || transition.PrimeStatus == primeStatus {
+			return false, current, nil
+		}
+	} else if !canAdvanceWithdrawalRequest(current, transition.Status) {
+		return false, current, nil
+	}
+
+	now := time.Now()
+	result, err := tx.ExecContext(ctx, queryAdvanceWithdrawalRequest, transition.Status,
+		transition.PrimeStatus, transition.PrimeStatus, transition.PrimeTransactionId,
+		transition.ActivityId, transition.ActivityId, now, idempotencyKey, current)
+	if err != nil {
+		return false, "", fmt.Errorf("unable to update withdrawal request: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, "", fmt.Errorf("unable to check rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, "", fmt.Errorf("withdrawal request %s was updated concurrently - %w", idempotencyKey, ErrConcurrentModification)
+	}
+
+	_, err = tx.ExecContext(ctx, queryInsertWithdrawalRequestEvent, idempotencyKey, current, transition.Status,
+		transition.PrimeStatus, transition.Note, now)
+	if err != nil {
+		return false, "", fmt.Errorf("unable to record withdrawal request event: %w", err)
+	}
+	return true, current, nil
+}
+
+// canAdvanceWithdrawalRequest reports whether a request may move from one status to another
+func canAdvanceWithdrawalRequest(from, to string) bool {
+	for _, allowed := range withdrawalRequestTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// withdrawalRequestStatus maps a resolved hold status to the status of its request
+func withdrawalRequestStatus(holdStatus string) string {
+	if holdStatus == WithdrawalHoldStatusCaptured {
+		return WithdrawalRequestStatusCompleted
+	}
+	return WithdrawalRequestStatusFailed
+}
+
+func scanWithdrawalRequest(row rowScanner) (*models.WithdrawalRequestRecord, error) {
+	var request models.WithdrawalRequestRecord
+	var amountStr string
+	if err := row.Scan(&request.IdempotencyKey, &request.UserId, &request.Asset, &amountStr, &request.WalletId,
+		&request.Destination, &request.Status, &request.PrimeStatus, &request.ActivityId, &request.PrimeTransactionId,
+		&request.CreatedAt, &request.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	request.Amount, err = decimal.NewFromString(amountStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse withdrawal request amount '%s': %w", amountStr, err)
+	}
+	return &request, nil
+}
@@ -0,0 +1,189 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestWithdrawalRequest_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-1",
+	}); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+
+	reserve := func(key string) {
+		t.Helper()
+		if err := service.ReserveWithdrawal(ctx, ReserveWithdrawalParams{
+			UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(2), IdempotencyKey: key,
+			Destination: "0xexternal", WalletId: "wallet-1",
+		}); err != nil {
+			t.Fatalf("Failed to reserve %s: %v", key, err)
+		}
+	}
+	expectRequest := func(key, status, primeTxId string) {
+		t.Helper()
+		request, err := service.GetWithdrawalRequest(ctx, key)
+		if err != nil || request == nil {
+			t.Fatalf("Expected a request for %s, got %v (%v)", key, request, err)
+		}
+		if request.UserId != "user1" || request.WalletId != "wallet-1" || !request.Amount.Equal(decimal.NewFromInt(2)) {
+			t.Errorf("Unexpected request: %+v", request)
+		}
+		if request.Status != status || request.PrimeTransactionId != primeTxId {
+			t.Errorf("Expected %s with prime tx %q, got %s with %q", status, primeTxId, request.Status, request.PrimeTransactionId)
+		}
+	}
+
+	reserve("wd-done")
+	expectRequest("wd-done", WithdrawalRequestStatusCreated, "")
+	if err := service.MarkWithdrawalRequestSubmitted(ctx, "wd-done", "activity-1"); err != nil {
+		t.Fatalf("MarkWithdrawalRequestSubmitted failed: %v", err)
+	}
+	expectRequest("wd-done", WithdrawalRequestStatusSubmitted, "")
+	for _, primeStatus := range []string{"TRANSACTION_PROCESSING", "TRANSACTION_PROCESSING", "TRANSACTION_BROADCASTING"} {
+		if _, err := service.AdvanceWithdrawalRequest(ctx, "wd-done", WithdrawalTransition{
+			Status: WithdrawalRequestStatusPending, PrimeStatus: primeStatus, PrimeTransactionId: "prime-1",
+		}); err != nil {
+			t.Fatalf("AdvanceWithdrawalRequest failed: %v", err)
+		}
+	}
+	expectRequest("wd-done", WithdrawalRequestStatusPending, "prime-1")
+	if _, err := service.CaptureWithdrawal(ctx, "wd-done", "prime-1"); err != nil {
+		t.Fatalf("CaptureWithdrawal failed: %v", err)
+	}
+	// The listener records Prime's final status; the request keeps the first transaction it was linked to
+	if _, err := service.AdvanceWithdrawalRequest(ctx, "wd-done", WithdrawalTransition{
+		Status: WithdrawalRequestStatusCompleted, PrimeStatus: "TRANSACTION_DONE", PrimeTransactionId: "prime-2",
+	}); err != nil {
+		t.Fatalf("AdvanceWithdrawalRequest failed: %v", err)
+	}
+	expectRequest("wd-done", WithdrawalRequestStatusCompleted, "prime-1")
+
+	// Completed withdrawals do not move again
+	advanced, err := service.AdvanceWithdrawalRequest(ctx, "wd-done", WithdrawalTransition{Status: WithdrawalRequestStatusFailed})
+	if err != nil || advanced {
+		t.Errorf("Expected a completed request not to fail, got %v (%v)", advanced, err)
+	}
+	expectRequest("wd-done", WithdrawalRequestStatusCompleted, "prime-1")
+
+	events, err := service.ListWithdrawalRequestEvents(ctx, "wd-done")
+	if err != nil {
+		t.Fatalf("ListWithdrawalRequestEvents failed: %v", err)
+	}
+	var path []string
+	for _, event := range events {
+		path = append(path, event.FromStatus+">"+event.ToStatus+":"+event.PrimeStatus)
+	}
+	want := []string{"created>submitted:", "submitted>pending:TRANSACTION_PROCESSING", "pending>pending:TRANSACTION_BROADCASTING",
+		"pending>completed:", "completed>completed:TRANSACTION_DONE"}
+	if len(path) != len(want) {
+		t.Fatalf("Expected events %v, got %v", want, path)
+	}
+	for i := range want {
+		if path[i] != want[i] {
+			t.Errorf("Event %d: expected %s, got %s", i, want[i], path[i])
+		}
+	}
+
+	reserve("wd-failed")
+	if err := service.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(2), "wd-failed", "rejected"); err != nil {
+		t.Fatalf("ReleaseWithdrawal failed: %v", err)
+	}
+	expectRequest("wd-failed", WithdrawalRequestStatusFailed, "")
+	if _, err := service.AdvanceWithdrawalRequest(ctx, "wd-failed", WithdrawalTransition{
+		Status: WithdrawalRequestStatusCancelled, PrimeStatus: "TRANSACTION_CANCELLED", PrimeTransactionId: "prime-3",
+	}); err != nil {
+		t.Fatalf("AdvanceWithdrawalRequest failed: %v", err)
+	}
+	expectRequest("wd-failed", WithdrawalRequestStatusCancelled, "prime-3")
+
+	requests, err := service.ListWithdrawalRequests(ctx, WithdrawalRequestFilter{UserId: "user1", Status: WithdrawalRequestStatusCompleted})
+	if err != nil || len(requests) != 1 || requests[0].IdempotencyKey != "wd-done" {
+		t.Errorf("Expected only wd-done to be completed, got %+v (%v)", requests, err)
+	}
+	if requests, err := service.ListWithdrawalRequests(ctx, WithdrawalRequestFilter{Limit: 1}); err != nil || len(requests) != 1 {
+		t.Errorf("Expected one request with a limit of 1, got %d (%v)", len(requests), err)
+	}
+
+	if request, err := service.GetWithdrawalRequest(ctx, "unknown"); err != nil || request != nil {
+		t.Errorf("Expected no request for an unknown key, got %+v (%v)", request, err)
+	}
+}
+
+func TestWithdrawalRequest_Backfill(t *testing.T) {
+	ctx := context.Background()
+	service := openTestDB(t, models.DatabaseConfig{})
+	defer service.Close()
+
+	if _, err := service.CreateUser(ctx, "user1", "Test User", "test@example.com"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "deposit", Amount: decimal.NewFromInt(10), ExternalTxId: "dep-1",
+	}); err != nil {
+		t.Fatalf("Failed to deposit: %v", err)
+	}
+	// A withdrawal debited before holds were introduced, and one reserved with a hold
+	if _, err := service.subledger.ProcessTransaction(ctx, ProcessTransactionParams{
+		UserId: "user1", Asset: "ETH", TransactionType: "withdrawal", Amount: decimal.NewFromFloat(-1.5), ExternalTxId: "wd-legacy",
+	}); err != nil {
+		t.Fatalf("Failed to withdraw: %v", err)
+	}
+	if err := service.ReserveWithdrawal(ctx, ReserveWithdrawalParams{
+		UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(2), IdempotencyKey: "wd-held",
+	}); err != nil {
+		t.Fatalf("Failed to reserve: %v", err)
+	}
+
+	// Databases from before the table existed are backfilled when it is created
+	if _, err := service.db.Exec("DROP TABLE withdrawal_requests"); err != nil {
+		t.Fatalf("Failed to drop table: %v", err)
+	}
+	if err := service.initWithdrawalRequestSchema(); err != nil {
+		t.Fatalf("initWithdrawalRequestSchema failed: %v", err)
+	}
+
+	legacy, err := service.GetWithdrawalRequest(ctx, "wd-legacy")
+	if err != nil || legacy == nil {
+		t.Fatalf("Expected the legacy debit to be backfilled, got %v (%v)", legacy, err)
+	}
+	if legacy.UserId != "user1" || !legacy.Amount.Equal(decimal.NewFromFloat(1.5)) || legacy.Status != WithdrawalRequestStatusSubmitted {
+		t.Errorf("Unexpected legacy request: %+v", legacy)
+	}
+
+	held, err := service.GetWithdrawalRequest(ctx, "wd-held")
+	if err != nil || held == nil {
+		t.Fatalf("Expected the held withdrawal to be backfilled, got %v (%v)", held, err)
+	}
+	if held.UserId != "user1" || !held.Amount.Equal(decimal.NewFromInt(2)) || held.Status != WithdrawalRequestStatusSubmitted {
+		t.Errorf("Unexpected held request: %+v", held)
+	}
+}
@@ -0,0 +1,52 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package grpcapi serves the ledger to internal services over gRPC. It offers the same operations as the
+// REST API in httpapi, but calls are authorized by one service token instead of per-user tokens.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequireToken returns an interceptor that rejects calls without GRPC_TOKEN as a bearer token in the
+// authorization metadata. With no token configured every call is rejected.
+func RequireToken(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if token == "" {
+			return nil, status.Error(codes.PermissionDenied, "the gRPC service is disabled without GRPC_TOKEN")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			presented, ok := strings.CutPrefix(value, "Bearer ")
+			if ok && subtle.ConstantTimeCompare([]byte(strings.TrimSpace(presented)), []byte(token)) == 1 {
+				return handler(database.WithTransactionSource(ctx, models.TransactionSourceApi), req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+}
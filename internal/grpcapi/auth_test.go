@@ -0,0 +1,56 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	ledgerv1 "prime-send-receive-go/proto/ledger/v1"
+
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequireToken(t *testing.T) {
+	server := NewLedgerServer(&fakeLedger{}, fakeUsers{}, nil, false, zaptest.NewLogger(t))
+	req := &ledgerv1.GetBalanceRequest{UserId: "alice", Asset: "ETH"}
+
+	client := newTestClient(t, server, testToken)
+	tests := []struct {
+		name string
+		ctx  context.Context
+		code codes.Code
+	}{
+		{"valid token", authorized(), codes.OK},
+		{"missing token", context.Background(), codes.Unauthenticated},
+		{"wrong token", metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong"), codes.Unauthenticated},
+		{"not a bearer token", metadata.AppendToOutgoingContext(context.Background(), "authorization", testToken), codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		if _, err := client.GetBalance(tt.ctx, req); status.Code(err) != tt.code {
+			t.Errorf("%s: expected %s, got %v", tt.name, tt.code, err)
+		}
+	}
+
+	disabled := newTestClient(t, server, "")
+	if _, err := disabled.GetBalance(authorized(), req); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Expected PermissionDenied without a configured token, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strings"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+	ledgerv1 "prime-send-receive-go/proto/ledger/v1"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ledger is the part of api.LedgerService the gRPC service calls
+type Ledger interface {
+	GetUserBalance(ctx context.Context, userId, asset string) (decimal.Decimal, error)
+	GetAvailableBalance(ctx context.Context, userId, asset string) (decimal.Decimal, error)
+	GetTransactionHistory(ctx context.Context, userId, asset string, limit int, before string) ([]models.TransactionRecord, error)
+	CreateWithdrawalForUser(ctx context.Context, req models.WithdrawalRequest) (*models.WithdrawalResult, error)
+}
+
+// UserCreator creates ledger users
+type UserCreator interface {
+	CreateUser(ctx context.Context, userId, name, email string) (*models.User, error)
+}
+
+// LedgerServer implements ledger.v1.LedgerService. It must be served behind RequireToken.
+type LedgerServer struct {
+	ledgerv1.UnimplementedLedgerServiceServer
+	ledger Ledger
+	users  UserCreator
+	assets []models.AssetConfig
+	queue  bool
+	logger *zap.Logger
+}
+
+// NewLedgerServer builds the gRPC ledger service. With queue set withdrawals are left to the withdrawal
+// worker, otherwise they are submitted to Prime before the call returns. Assets whose withdrawals are
+// disabled in assets are rejected.
+func NewLedgerServer(ledger Ledger, users UserCreator, assets []models.AssetConfig, queue bool, logger *zap.Logger) *LedgerServer {
+	return &LedgerServer{ledger: ledger, users: users, assets: assets, queue: queue, logger: logger}
+}
+
+// GetBalance returns a user's balance and available balance in one asset
+func (s *LedgerServer) GetBalance(ctx context.Context, req *ledgerv1.GetBalanceRequest) (*ledgerv1.GetBalanceResponse, error) {
+	userId := strings.TrimSpace(req.GetUserId())
+	asset := strings.TrimSpace(req.GetAsset())
+	if userId == "" || asset == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and asset are required")
+	}
+
+	balance, err := s.ledger.GetUserBalance(ctx, userId, asset)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unable to get balance")
+	}
+	available, err := s.ledger.GetAvailableBalance(ctx, userId, asset)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unable to get balance")
+	}
+
+	return &ledgerv1.GetBalanceResponse{Balance: balance.String(), AvailableBalance: available.String()}, nil
+}
+
+// ListTransactions returns a page of a user's transactions in one asset, newest first
+func (s *LedgerServer) ListTransactions(ctx context.Context, req *ledgerv1.ListTransactionsRequest) (*ledgerv1.ListTransactionsResponse, error) {
+	userId := strings.TrimSpace(req.GetUserId())
+	asset := strings.TrimSpace(req.GetAsset())
+	if userId == "" || asset == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and asset are required")
+	}
+	if req.GetLimit() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit must not be negative")
+	}
+
+	transactions, err := s.ledger.GetTransactionHistory(ctx, userId, asset, int(req.GetLimit()), strings.TrimSpace(req.GetBefore()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "unable to get transactions")
+	}
+
+	response := &ledgerv1.ListTransactionsResponse{Transactions: make([]*ledgerv1.Transaction, 0, len(transactions))}
+	for _, tx := range transactions {
+		response.Transactions = append(response.Transactions, &ledgerv1.Transaction{
+			Id:          tx.Id,
+			Type:        tx.Type,
+			Asset:       tx.Asset,
+			Amount:      tx.Amount.String(),
+			Address:     tx.Address,
+			Status:      tx.Status,
+			ProcessedAt: timestamppb.New(tx.ProcessedAt),
+			TxHash:      tx.TxHash,
+			ExplorerUrl: tx.ExplorerUrl,
+		})
+	}
+	if len(transactions) > 0 {
+		response.NextBefore = transactions[len(transactions)-1].Id
+	}
+	return response, nil
+}
+
+// ProcessWithdrawal reserves a user's balance and sends or queues the withdrawal, as POST /withdrawals does
+func (s *LedgerServer) ProcessWithdrawal(ctx context.Context, req *ledgerv1.ProcessWithdrawalRequest) (*ledgerv1.ProcessWithdrawalResponse, error) {
+	user := strings.TrimSpace(req.GetUser())
+	if user == "" {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+	symbol, network, ok := strings.Cut(req.GetAsset(), "-")
+	if !ok || symbol == "" || network == "" {
+		return nil, status.Error(codes.InvalidArgument, "asset must be SYMBOL-network, e.g. ETH-ethereum-mainnet")
+	}
+	amount, err := decimal.NewFromString(strings.TrimSpace(req.GetAmount()))
+	if err != nil || !amount.IsPositive() {
+		return nil, status.Error(codes.InvalidArgument, "amount must be a decimal greater than zero")
+	}
+	destination := strings.TrimSpace(req.GetDestination())
+	if destination == "" {
+		return nil, status.Error(codes.InvalidArgument, "destination is required")
+	}
+	if _, err := prime.ParseDestinationType(req.GetDestinationType()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if asset, ok := common.FindAssetConfig(s.assets, symbol, network); ok && !asset.WithdrawalsEnabled() {
+		return nil, status.Error(codes.FailedPrecondition, "withdrawals are disabled for "+asset.AssetNetwork())
+	}
+
+	result, err := s.ledger.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+		User:            user,
+		Asset:           req.GetAsset(),
+		Amount:          amount,
+		DestinationType: req.GetDestinationType(),
+		Destination:     destination,
+		IdempotencyKey:  strings.TrimSpace(req.GetIdempotencyKey()),
+		Queue:           s.queue,
+	})
+	switch {
+	case errors.Is(err, database.ErrUserNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, database.ErrInsufficientBalance),
+		errors.Is(err, api.ErrDailyLimitExceeded),
+		errors.Is(err, api.ErrNoWalletForAsset):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, database.ErrConcurrentModification), errors.Is(err, database.ErrDuplicateTransaction):
+		return nil, status.Error(codes.Aborted, "another withdrawal changed the balance, please retry")
+	case err != nil:
+		s.logger.Error("Failed to create withdrawal", zap.String("user", user), zap.Error(err))
+		return nil, status.Error(codes.Internal, "unable to create withdrawal")
+	}
+
+	return &ledgerv1.ProcessWithdrawalResponse{
+		Status:           result.Status,
+		Asset:            result.Asset,
+		Amount:           result.Amount.String(),
+		DestinationType:  result.DestinationType,
+		Destination:      result.Destination,
+		IdempotencyKey:   result.IdempotencyKey,
+		ActivityId:       result.ActivityId,
+		QueueId:          result.QueueId,
+		AvailableBalance: result.AvailableBalance.String(),
+		CorrelationId:    result.CorrelationId,
+	}, nil
+}
+
+// CreateUser creates a ledger user without deposit addresses
+func (s *LedgerServer) CreateUser(ctx context.Context, req *ledgerv1.CreateUserRequest) (*ledgerv1.CreateUserResponse, error) {
+	name := strings.TrimSpace(req.GetName())
+	email := strings.TrimSpace(req.GetEmail())
+	if len(name) < 2 {
+		return nil, status.Error(codes.InvalidArgument, "name must be at least 2 characters")
+	}
+	if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+		return nil, status.Error(codes.InvalidArgument, "invalid email address")
+	}
+
+	user, err := s.users.CreateUser(ctx, uuid.New().String(), name, email)
+	if errors.Is(err, database.ErrUserExists) {
+		return nil, status.Error(codes.AlreadyExists, "a user with this email already exists")
+	}
+	if err != nil {
+		s.logger.Error("Failed to create user", zap.Error(err))
+		return nil, status.Error(codes.Internal, "unable to create user")
+	}
+
+	return &ledgerv1.CreateUserResponse{User: &ledgerv1.User{
+		Id:        user.Id,
+		Name:      user.Name,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+	}}, nil
+}
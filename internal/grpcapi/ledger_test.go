@@ -0,0 +1,194 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	ledgerv1 "prime-send-receive-go/proto/ledger/v1"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const testToken = "0123456789abcdef0123456789abcdef"
+
+// fakeLedger serves fixed balances and history and records withdrawal requests
+type fakeLedger struct {
+	withdrawals []models.WithdrawalRequest
+	withdrawErr error
+}
+
+func (f *fakeLedger) GetUserBalance(_ context.Context, _, _ string) (decimal.Decimal, error) {
+	return decimal.RequireFromString("2"), nil
+}
+
+func (f *fakeLedger) GetAvailableBalance(_ context.Context, _, _ string) (decimal.Decimal, error) {
+	return decimal.RequireFromString("1.5"), nil
+}
+
+func (f *fakeLedger) GetTransactionHistory(_ context.Context, userId, asset string, limit int, before string) ([]models.TransactionRecord, error) {
+	return []models.TransactionRecord{{Id: fmt.Sprintf("%s-%s-%d-%s", userId, asset, limit, before), Asset: asset, Amount: decimal.RequireFromString("0.1")}}, nil
+}
+
+func (f *fakeLedger) CreateWithdrawalForUser(_ context.Context, req models.WithdrawalRequest) (*models.WithdrawalResult, error) {
+	if f.withdrawErr != nil {
+		return nil, f.withdrawErr
+	}
+	f.withdrawals = append(f.withdrawals, req)
+	return &models.WithdrawalResult{Status: models.WithdrawalQueued, Asset: req.Asset, Amount: req.Amount, QueueId: "q-1"}, nil
+}
+
+type fakeUsers map[string]bool
+
+func (f fakeUsers) CreateUser(_ context.Context, userId, name, email string) (*models.User, error) {
+	if f[email] {
+		return nil, fmt.Errorf("%w with email %s", database.ErrUserExists, email)
+	}
+	f[email] = true
+	return &models.User{Id: userId, Name: name, Email: email}, nil
+}
+
+// newTestClient serves server behind RequireToken(token) on an in-memory listener
+func newTestClient(t *testing.T, server *LedgerServer, token string) ledgerv1.LedgerServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(RequireToken(token)))
+	ledgerv1.RegisterLedgerServiceServer(grpcServer, server)
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial test server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return ledgerv1.NewLedgerServiceClient(conn)
+}
+
+func authorized() context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+testToken)
+}
+
+func TestGetBalanceAndListTransactions(t *testing.T) {
+	client := newTestClient(t, NewLedgerServer(&fakeLedger{}, fakeUsers{}, nil, false, zaptest.NewLogger(t)), testToken)
+
+	balance, err := client.GetBalance(authorized(), &ledgerv1.GetBalanceRequest{UserId: "alice", Asset: "ETH"})
+	if err != nil {
+		t.Fatalf("GetBalance failed: %v", err)
+	}
+	if balance.Balance != "2" || balance.AvailableBalance != "1.5" {
+		t.Errorf("Expected balance 2 and available 1.5, got %s and %s", balance.Balance, balance.AvailableBalance)
+	}
+
+	_, err = client.GetBalance(authorized(), &ledgerv1.GetBalanceRequest{UserId: "alice"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument without an asset, got %v", err)
+	}
+
+	page, err := client.ListTransactions(authorized(), &ledgerv1.ListTransactionsRequest{UserId: "alice", Asset: "ETH", Limit: 5, Before: "tx-9"})
+	if err != nil {
+		t.Fatalf("ListTransactions failed: %v", err)
+	}
+	if len(page.Transactions) != 1 || page.Transactions[0].Amount != "0.1" {
+		t.Fatalf("Expected one transaction of 0.1, got %v", page.Transactions)
+	}
+	if page.NextBefore != "alice-ETH-5-tx-9" {
+		t.Errorf("Expected next_before alice-ETH-5-tx-9, got %q", page.NextBefore)
+	}
+}
+
+func TestProcessWithdrawal(t *testing.T) {
+	ledger := &fakeLedger{}
+	disabled := false
+	assets := []models.AssetConfig{{Symbol: "SOL", Network: "solana-mainnet", Listener: models.AssetListenerConfig{WithdrawalsEnabled: &disabled}}}
+	client := newTestClient(t, NewLedgerServer(ledger, fakeUsers{}, assets, true, zaptest.NewLogger(t)), testToken)
+
+	result, err := client.ProcessWithdrawal(authorized(), &ledgerv1.ProcessWithdrawalRequest{
+		User: "alice", Asset: "ETH-ethereum-mainnet", Amount: "0.5", Destination: " 0xabc ", IdempotencyKey: "key-1",
+	})
+	if err != nil {
+		t.Fatalf("ProcessWithdrawal failed: %v", err)
+	}
+	if result.Status != models.WithdrawalQueued || result.QueueId != "q-1" || result.Amount != "0.5" {
+		t.Errorf("Unexpected result: %v", result)
+	}
+	if len(ledger.withdrawals) != 1 {
+		t.Fatalf("Expected one withdrawal, got %d", len(ledger.withdrawals))
+	}
+	req := ledger.withdrawals[0]
+	if req.User != "alice" || req.Destination != "0xabc" || req.IdempotencyKey != "key-1" || !req.Queue {
+		t.Errorf("Unexpected withdrawal request: %+v", req)
+	}
+
+	tests := []struct {
+		name string
+		req  *ledgerv1.ProcessWithdrawalRequest
+		err  error
+		code codes.Code
+	}{
+		{"invalid amount", &ledgerv1.ProcessWithdrawalRequest{User: "alice", Asset: "ETH-ethereum-mainnet", Amount: "abc", Destination: "0xabc"}, nil, codes.InvalidArgument},
+		{"bad asset", &ledgerv1.ProcessWithdrawalRequest{User: "alice", Asset: "ETH", Amount: "1", Destination: "0xabc"}, nil, codes.InvalidArgument},
+		{"disabled asset", &ledgerv1.ProcessWithdrawalRequest{User: "alice", Asset: "SOL-solana-mainnet", Amount: "1", Destination: "abc"}, nil, codes.FailedPrecondition},
+		{"unknown user", &ledgerv1.ProcessWithdrawalRequest{User: "bob", Asset: "ETH-ethereum-mainnet", Amount: "1", Destination: "0xabc"},
+			fmt.Errorf("%w: bob", database.ErrUserNotFound), codes.NotFound},
+		{"insufficient", &ledgerv1.ProcessWithdrawalRequest{User: "alice", Asset: "ETH-ethereum-mainnet", Amount: "1", Destination: "0xabc"},
+			fmt.Errorf("withdrawal failed: %w", database.ErrInsufficientBalance), codes.FailedPrecondition},
+		{"concurrent", &ledgerv1.ProcessWithdrawalRequest{User: "alice", Asset: "ETH-ethereum-mainnet", Amount: "1", Destination: "0xabc"},
+			database.ErrConcurrentModification, codes.Aborted},
+	}
+	for _, tt := range tests {
+		ledger.withdrawErr = tt.err
+		if _, err := client.ProcessWithdrawal(authorized(), tt.req); status.Code(err) != tt.code {
+			t.Errorf("%s: expected %s, got %v", tt.name, tt.code, err)
+		}
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	client := newTestClient(t, NewLedgerServer(&fakeLedger{}, fakeUsers{}, nil, false, zaptest.NewLogger(t)), testToken)
+
+	created, err := client.CreateUser(authorized(), &ledgerv1.CreateUserRequest{Name: "Alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if created.User.GetId() == "" || created.User.GetEmail() != "alice@example.com" {
+		t.Errorf("Unexpected user: %v", created.User)
+	}
+
+	_, err = client.CreateUser(authorized(), &ledgerv1.CreateUserRequest{Name: "Alice", Email: "alice@example.com"})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("Expected AlreadyExists for a taken email, got %v", err)
+	}
+	_, err = client.CreateUser(authorized(), &ledgerv1.CreateUserRequest{Name: "Bob", Email: "not an email"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for a bad email, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package httpapi holds the client-facing HTTP API: bearer token authentication scoped to a single
+// user, the middleware in front of it and the ledger handlers. The server that mounts them is in app.
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// TokenAuthenticator resolves a presented bearer token to the API token it belongs to
+type TokenAuthenticator interface {
+	AuthenticateApiToken(ctx context.Context, token string) (*models.ApiToken, error)
+}
+
+type tokenContextKey struct{}
+
+// RequireToken rejects requests without a valid "Authorization: Bearer" token and stores the
+// authenticated token in the request context for downstream handlers
+func RequireToken(auth TokenAuthenticator, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || strings.TrimSpace(token) == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prime-send-receive"`)
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		apiToken, err := auth.AuthenticateApiToken(r.Context(), strings.TrimSpace(token))
+		if errors.Is(err, database.ErrInvalidApiToken) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prime-send-receive", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to authenticate api token", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to authenticate")
+			return
+		}
+
+		// Debits requested through the API run under the api balance policy
+		ctx := database.WithTransactionSource(context.WithValue(r.Context(), tokenContextKey{}, apiToken), models.TransactionSourceApi)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireAdminToken protects operator endpoints with the API_ADMIN_TOKEN bearer token. User tokens are
+// not accepted. With no admin token configured the endpoints are disabled.
+func RequireAdminToken(adminToken string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if adminToken == "" {
+			writeError(w, http.StatusForbidden, "admin endpoints are disabled")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(adminToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="prime-send-receive-admin"`)
+			writeError(w, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// TokenFromContext returns the token authenticated by RequireToken
+func TokenFromContext(ctx context.Context) (*models.ApiToken, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(*models.ApiToken)
+	return token, ok
+}
+
+// AuthorizeUser reports whether the request's token may read userId's balances, addresses and history.
+// It writes a 403 and returns false otherwise, so handlers can simply return.
+func AuthorizeUser(w http.ResponseWriter, r *http.Request, userId string) bool {
+	token, ok := TokenFromContext(r.Context())
+	if !ok || token.UserId != userId {
+		writeError(w, http.StatusForbidden, "token is not authorized for this user")
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
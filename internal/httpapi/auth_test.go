@@ -0,0 +1,73 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeAuthenticator map[string]string
+
+func (f fakeAuthenticator) AuthenticateApiToken(_ context.Context, token string) (*models.ApiToken, error) {
+	userId, ok := f[token]
+	if !ok {
+		return nil, database.ErrInvalidApiToken
+	}
+	return &models.ApiToken{Id: "tok-" + userId, UserId: userId}, nil
+}
+
+func TestRequireToken_ScopesRequestsToTokenUser(t *testing.T) {
+	auth := fakeAuthenticator{"psr_alice": "alice"}
+	handler := RequireToken(auth, zaptest.NewLogger(t), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !AuthorizeUser(w, r, r.URL.Query().Get("user")) {
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		user   string
+		want   int
+	}{
+		{"missing token", "", "alice", http.StatusUnauthorized},
+		{"unknown token", "Bearer psr_mallory", "alice", http.StatusUnauthorized},
+		{"own data", "Bearer psr_alice", "alice", http.StatusOK},
+		{"other user's data", "Bearer psr_alice", "bob", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/balances?user="+tt.user, nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+}
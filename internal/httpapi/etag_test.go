@@ -0,0 +1,109 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{`"1-3"`, true},
+		{`W/"1-3"`, true},
+		{`"0-0", "1-3"`, true},
+		{"*", true},
+		{`"1-2"`, false},
+		{"1-3", false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `"1-3"`); got != tt.want {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+// conditionalGet serves a GET with If-None-Match set to etag, when given
+func conditionalGet(handler http.Handler, target, etag string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("Authorization", "Bearer psr_alice")
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUserBalancesHandlerEtag(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ledger := &fakeLedger{
+		balances: map[string][]models.UserBalance{"alice": {{Asset: "ETH", Balance: decimal.RequireFromString("2")}}},
+		versions: map[string]string{"alice": "1-3"},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(UserBalancesPattern, RequireToken(fakeAuthenticator{"psr_alice": "alice"}, logger, UserBalancesHandler(ledger, logger)))
+
+	rec := conditionalGet(mux, "/users/alice/balances", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"1-3"` {
+		t.Fatalf(`Expected 200 with ETag "1-3", got %d with %q`, rec.Code, rec.Header().Get("ETag"))
+	}
+
+	rec = conditionalGet(mux, "/users/alice/balances", `"1-3"`)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("Expected an empty 304, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ledger.balanceReads != 1 {
+		t.Errorf("Expected the 304 not to read balances, got %d reads", ledger.balanceReads)
+	}
+
+	ledger.versions["alice"] = "1-4"
+	rec = conditionalGet(mux, "/users/alice/balances", `"1-3"`)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"1-4"` {
+		t.Errorf(`Expected 200 with ETag "1-4" after a balance change, got %d with %q`, rec.Code, rec.Header().Get("ETag"))
+	}
+}
+
+func TestAddressesHandlerEtag(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	addresses := fakeAddresses{{UserId: "alice", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xabc"}}
+	handler := RequireToken(fakeAuthenticator{"psr_alice": "alice"}, logger, AddressesHandler(addresses, logger))
+
+	rec := conditionalGet(handler, "/addresses", "")
+	etag := rec.Header().Get("ETag")
+	if rec.Code != http.StatusOK || etag == "" {
+		t.Fatalf("Expected 200 with an ETag, got %d with %q", rec.Code, etag)
+	}
+	if rec = conditionalGet(handler, "/addresses", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("Expected 304 for an unchanged address list, got %d", rec.Code)
+	}
+
+	addresses[0].Label = "primary"
+	if rec = conditionalGet(handler, "/addresses", etag); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 after a label change, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,162 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader is the request header carrying a client chosen idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// maxIdempotentBody bounds the request bodies hashed and the responses stored for replay
+const maxIdempotentBody = 1 << 20
+
+// IdempotencyStore persists idempotency keys and the responses to replay for them
+type IdempotencyStore interface {
+	BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*models.IdempotentResponse, error)
+	CompleteIdempotentRequest(ctx context.Context, scope, key string, response models.IdempotentResponse) error
+	ReleaseIdempotentRequest(ctx context.Context, scope, key string) error
+}
+
+// Idempotency makes POST requests carrying an Idempotency-Key safe to retry, with Prime's semantics:
+// the first request runs and its response is stored; a retry with the same key and body gets that
+// response replayed; the same key with a different body is rejected with 422, and a retry while the
+// first request is still running with 409. Server errors are not stored, so they can be retried.
+// Keys are scoped to the authenticated user, so it must run after RequireToken.
+func Idempotency(store IdempotencyStore, logger *zap.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > 255 {
+			writeError(w, http.StatusBadRequest, "idempotency key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "unable to read request body")
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			writeError(w, http.StatusRequestEntityTooLarge, "request body is too large")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := "anonymous"
+		if token, ok := TokenFromContext(r.Context()); ok {
+			scope = token.UserId
+		}
+
+		ctx := r.Context()
+		stored, err := store.BeginIdempotentRequest(ctx, scope, key, requestHash(r, body))
+		switch {
+		case errors.Is(err, database.ErrIdempotencyKeyReused):
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, database.ErrIdempotencyKeyInFlight):
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			logger.Error("Failed to check idempotency key", zap.String("key", key), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to check idempotency key")
+			return
+		}
+
+		if stored != nil {
+			logger.Info("Replaying idempotent response", zap.String("scope", scope), zap.String("key", key))
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		// Stored and released with a fresh context so a client disconnect does not strand the key
+		if recorder.statusCode >= 500 || recorder.overflow {
+			if err := store.ReleaseIdempotentRequest(context.WithoutCancel(ctx), scope, key); err != nil {
+				logger.Error("Failed to release idempotency key", zap.String("key", key), zap.Error(err))
+			}
+			return
+		}
+
+		response := models.IdempotentResponse{
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}
+		if err := store.CompleteIdempotentRequest(context.WithoutCancel(ctx), scope, key, response); err != nil {
+			logger.Error("Failed to store idempotent response", zap.String("key", key), zap.Error(err))
+		}
+	})
+}
+
+// requestHash identifies a request so a reused key can be told apart from a retry
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder passes a response through while keeping a copy for replay
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+	overflow    bool
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if r.body.Len()+len(b) > maxIdempotentBody {
+		r.overflow = true
+	} else {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
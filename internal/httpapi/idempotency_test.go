@@ -0,0 +1,112 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type storedKey struct {
+	hash     string
+	response *models.IdempotentResponse
+}
+
+type fakeIdempotencyStore map[string]*storedKey
+
+func (f fakeIdempotencyStore) BeginIdempotentRequest(_ context.Context, scope, key, requestHash string) (*models.IdempotentResponse, error) {
+	stored, ok := f[scope+"/"+key]
+	if !ok {
+		f[scope+"/"+key] = &storedKey{hash: requestHash}
+		return nil, nil
+	}
+	if stored.hash != requestHash {
+		return nil, database.ErrIdempotencyKeyReused
+	}
+	if stored.response == nil {
+		return nil, database.ErrIdempotencyKeyInFlight
+	}
+	return stored.response, nil
+}
+
+func (f fakeIdempotencyStore) CompleteIdempotentRequest(_ context.Context, scope, key string, response models.IdempotentResponse) error {
+	f[scope+"/"+key].response = &response
+	return nil
+}
+
+func (f fakeIdempotencyStore) ReleaseIdempotentRequest(_ context.Context, scope, key string) error {
+	delete(f, scope+"/"+key)
+	return nil
+}
+
+func TestIdempotency_ReplaysRetries(t *testing.T) {
+	store := fakeIdempotencyStore{}
+	calls := 0
+	failNext := false
+	handler := Idempotency(store, zaptest.NewLogger(t), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"w-1"}`))
+	}))
+
+	post := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/withdrawals", strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := post("key-1", `{"amount":"1"}`)
+	retry := post("key-1", `{"amount":"1"}`)
+	if calls != 1 {
+		t.Fatalf("Expected the handler to run once, ran %d times", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected the first response to be replayed, got %d %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("Expected replayed responses to be marked")
+	}
+
+	if rec := post("key-1", `{"amount":"2"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a reused key with a different body, got %d", rec.Code)
+	}
+
+	// Server errors are not stored, so the retry runs the request again
+	failNext = true
+	if rec := post("key-2", `{"amount":"1"}`); rec.Code != http.StatusBadGateway {
+		t.Fatalf("Expected the failure to pass through, got %d", rec.Code)
+	}
+	if rec := post("key-2", `{"amount":"1"}`); rec.Code != http.StatusCreated || calls != 3 {
+		t.Errorf("Expected the retry after a server error to run, got %d after %d calls", rec.Code, calls)
+	}
+}
@@ -0,0 +1,403 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Routes the ledger handlers expect to be mounted on
+const (
+	UserBalancesPattern     = "GET /users/{id}/balances"
+	UserTransactionsPattern = "GET /users/{id}/transactions"
+	WithdrawalsPattern      = "POST /withdrawals"
+	UsersPattern            = "POST /users"
+	AddressesPattern        = "GET /addresses"
+	InstructionsPattern     = "GET /deposit-instructions"
+)
+
+// maxJsonBody bounds the request bodies the ledger handlers decode
+const maxJsonBody = 64 << 10
+
+// BalanceSource returns a user's balances in every asset, and a version that changes whenever they do
+type BalanceSource interface {
+	GetUserBalances(ctx context.Context, userId string) ([]models.UserBalance, error)
+	GetUserBalancesVersion(ctx context.Context, userId string) (string, error)
+}
+
+// TransactionHistorySource returns a page of a user's ledger transactions in one asset
+type TransactionHistorySource interface {
+	GetTransactionHistory(ctx context.Context, userId, asset string, limit int, before string) ([]models.TransactionRecord, error)
+}
+
+// WithdrawalCreator debits a user and sends or queues the withdrawal
+type WithdrawalCreator interface {
+	CreateWithdrawalForUser(ctx context.Context, req models.WithdrawalRequest) (*models.WithdrawalResult, error)
+}
+
+// UserCreator creates ledger users
+type UserCreator interface {
+	CreateUser(ctx context.Context, userId, name, email string) (*models.User, error)
+}
+
+// AddressSource lists a user's deposit addresses
+type AddressSource interface {
+	GetAddresses(ctx context.Context, userId string, asset string, network string) ([]models.Address, error)
+	GetAllUserAddresses(ctx context.Context, userId string) ([]models.Address, error)
+}
+
+// InstructionsSource is what DepositInstructionsHandler needs from the ledger
+type InstructionsSource interface {
+	GetDepositInstructions(ctx context.Context, userId, symbol, network string) ([]models.DepositInstructions, error)
+}
+
+// WithdrawalRequest is the body of POST /withdrawals. Asset is the symbol and network, e.g. ETH-ethereum-mainnet.
+type WithdrawalRequest struct {
+	Asset           string          `json:"asset"`
+	Amount          decimal.Decimal `json:"amount"`
+	DestinationType string          `json:"destination_type"`
+	Destination     string          `json:"destination"`
+}
+
+// CreateUserRequest is the body of POST /users
+type CreateUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// UserResponse is a ledger user as returned by POST /users
+type UserResponse struct {
+	Id        string    `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddressResponse is a deposit address as returned by GET /addresses; wallet and Prime ids stay internal
+type AddressResponse struct {
+	Asset     string    `json:"asset"`
+	Network   string    `json:"network"`
+	Address   string    `json:"address"`
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserBalancesHandler returns a user's balance and available balance in every asset. The ETag is the
+// balances' version, which is read before the balances so that a change in between is never hidden, and
+// a matching If-None-Match gets a 304 without reading the balances. It must run behind RequireToken; a
+// token can only read its own user's balances.
+func UserBalancesHandler(balances BalanceSource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId := r.PathValue("id")
+		if !AuthorizeUser(w, r, userId) {
+			return
+		}
+
+		version, err := balances.GetUserBalancesVersion(r.Context(), userId)
+		if err != nil {
+			logger.Error("Failed to get balances version", zap.String("user_id", userId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get balances")
+			return
+		}
+		if notModified(w, r, `"`+version+`"`) {
+			return
+		}
+
+		result, err := balances.GetUserBalances(r.Context(), userId)
+		if err != nil {
+			logger.Error("Failed to get user balances", zap.String("user_id", userId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get balances")
+			return
+		}
+		writeJson(w, http.StatusOK, map[string]any{"balances": result})
+	})
+}
+
+// UserTransactionsHandler returns a page of a user's transactions in the asset given by ?asset=, newest
+// first, with up to ?limit= (at most 100, default 20) transactions. The response's next_before, passed as
+// ?before=, fetches the following page. It must run behind RequireToken; a token can only read its own
+// user's history.
+func UserTransactionsHandler(history TransactionHistorySource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId := r.PathValue("id")
+		if !AuthorizeUser(w, r, userId) {
+			return
+		}
+
+		query := r.URL.Query()
+		asset := strings.TrimSpace(query.Get("asset"))
+		if asset == "" {
+			writeError(w, http.StatusBadRequest, "asset is required")
+			return
+		}
+		limit, err := queryInt(query.Get("limit"), 20)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+
+		transactions, err := history.GetTransactionHistory(r.Context(), userId, asset, limit, strings.TrimSpace(query.Get("before")))
+		if err != nil {
+			logger.Error("Failed to get transaction history", zap.String("user_id", userId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get transactions")
+			return
+		}
+
+		response := map[string]any{"transactions": transactions}
+		if len(transactions) > 0 {
+			response["next_before"] = transactions[len(transactions)-1].Id
+		}
+		writeJson(w, http.StatusOK, response)
+	})
+}
+
+// WithdrawalsHandler withdraws from the token's user. With queue set the withdrawal worker submits it
+// to Prime, otherwise it is submitted before the response. Assets whose withdrawals are disabled in
+// assets are rejected. It must run behind RequireToken, and behind Idempotency so clients can retry.
+// The Idempotency-Key header is also the withdrawal's ledger idempotency key, so a retry the
+// Idempotency middleware lets through still reserves and submits the withdrawal only once.
+func WithdrawalsHandler(withdrawals WithdrawalCreator, assets []models.AssetConfig, queue bool, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := TokenFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		var req WithdrawalRequest
+		if !decodeJson(w, r, &req) {
+			return
+		}
+		symbol, network, ok := strings.Cut(req.Asset, "-")
+		if !ok || symbol == "" || network == "" {
+			writeError(w, http.StatusBadRequest, "asset must be SYMBOL-network, e.g. ETH-ethereum-mainnet")
+			return
+		}
+		if !req.Amount.IsPositive() {
+			writeError(w, http.StatusBadRequest, "amount must be greater than zero")
+			return
+		}
+		if strings.TrimSpace(req.Destination) == "" {
+			writeError(w, http.StatusBadRequest, "destination is required")
+			return
+		}
+		if _, err := prime.ParseDestinationType(req.DestinationType); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if asset, ok := common.FindAssetConfig(assets, symbol, network); ok && !asset.WithdrawalsEnabled() {
+			writeError(w, http.StatusUnprocessableEntity, "withdrawals are disabled for "+asset.AssetNetwork())
+			return
+		}
+
+		result, err := withdrawals.CreateWithdrawalForUser(r.Context(), models.WithdrawalRequest{
+			User:            token.UserId,
+			Asset:           req.Asset,
+			Amount:          req.Amount,
+			DestinationType: req.DestinationType,
+			Destination:     strings.TrimSpace(req.Destination),
+			IdempotencyKey:  r.Header.Get(IdempotencyKeyHeader),
+			Queue:           queue,
+		})
+		switch {
+		case errors.Is(err, database.ErrInsufficientBalance),
+			errors.Is(err, api.ErrDailyLimitExceeded),
+			errors.Is(err, api.ErrNoWalletForAsset):
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		case errors.Is(err, database.ErrConcurrentModification), errors.Is(err, database.ErrDuplicateTransaction):
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusConflict, "another withdrawal changed the balance, please retry")
+			return
+		case err != nil:
+			logger.Error("Failed to create withdrawal", zap.String("user_id", token.UserId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to create withdrawal")
+			return
+		}
+
+		status := http.StatusCreated
+		if result.Status == models.WithdrawalReplayed {
+			status = http.StatusOK
+		}
+		writeJson(w, status, result)
+	})
+}
+
+// CreateUserHandler creates a ledger user without deposit addresses. It must run behind RequireAdminToken.
+func CreateUserHandler(users UserCreator, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req CreateUserRequest
+		if !decodeJson(w, r, &req) {
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		email := strings.TrimSpace(req.Email)
+		if len(name) < 2 {
+			writeError(w, http.StatusBadRequest, "name must be at least 2 characters")
+			return
+		}
+		if address, err := mail.ParseAddress(email); err != nil || address.Address != email {
+			writeError(w, http.StatusBadRequest, "invalid email address")
+			return
+		}
+
+		user, err := users.CreateUser(r.Context(), uuid.New().String(), name, email)
+		if errors.Is(err, database.ErrUserExists) {
+			writeError(w, http.StatusConflict, "a user with this email already exists")
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to create user", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to create user")
+			return
+		}
+
+		writeJson(w, http.StatusCreated, UserResponse{Id: user.Id, Name: user.Name, Email: user.Email, CreatedAt: user.CreatedAt})
+	})
+}
+
+// AddressesHandler lists the token's user's deposit addresses, optionally for one ?asset= and ?network=.
+// Addresses have no version, so the ETag is a hash of the response; a matching If-None-Match gets a 304.
+// It must run behind RequireToken.
+func AddressesHandler(addresses AddressSource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := TokenFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		asset := strings.TrimSpace(r.URL.Query().Get("asset"))
+		network := strings.TrimSpace(r.URL.Query().Get("network"))
+		if (asset == "") != (network == "") {
+			writeError(w, http.StatusBadRequest, "asset and network must be given together")
+			return
+		}
+
+		var result []models.Address
+		var err error
+		if asset != "" {
+			result, err = addresses.GetAddresses(r.Context(), token.UserId, asset, network)
+		} else {
+			result, err = addresses.GetAllUserAddresses(r.Context(), token.UserId)
+		}
+		if err != nil {
+			logger.Error("Failed to list addresses", zap.String("user_id", token.UserId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to list addresses")
+			return
+		}
+
+		response := make([]AddressResponse, 0, len(result))
+		for _, a := range result {
+			response = append(response, AddressResponse{
+				Asset:     a.Asset,
+				Network:   a.Network,
+				Address:   a.Address,
+				Label:     a.Label,
+				CreatedAt: a.CreatedAt,
+			})
+		}
+		body := map[string]any{"addresses": response}
+		etag, err := contentEtag(body)
+		if err != nil {
+			logger.Error("Failed to compute addresses etag", zap.String("user_id", token.UserId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to list addresses")
+			return
+		}
+		if notModified(w, r, etag) {
+			return
+		}
+		writeJson(w, http.StatusOK, body)
+	})
+}
+
+// DepositInstructionsHandler tells the token's user how to deposit ?asset=, optionally on one ?network=.
+// With lazy deposit addresses, the user's first request creates the address, so responses carry no ETag.
+// It must run behind RequireToken.
+func DepositInstructionsHandler(ledger InstructionsSource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := TokenFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "missing bearer token")
+			return
+		}
+
+		asset := strings.TrimSpace(r.URL.Query().Get("asset"))
+		network := strings.TrimSpace(r.URL.Query().Get("network"))
+		if asset == "" {
+			writeError(w, http.StatusBadRequest, "asset is required")
+			return
+		}
+
+		instructions, err := ledger.GetDepositInstructions(r.Context(), token.UserId, asset, network)
+		if err != nil {
+			logger.Error("Failed to get deposit instructions",
+				zap.String("user_id", token.UserId),
+				zap.String("asset", asset),
+				zap.String("network", network),
+				zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get deposit instructions")
+			return
+		}
+		writeJson(w, http.StatusOK, map[string]any{"instructions": instructions})
+	})
+}
+
+// decodeJson reads a JSON request body into v, writing a 400 and returning false when it is invalid
+func decodeJson(w http.ResponseWriter, r *http.Request, v any) bool {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxJsonBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeJson(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func queryInt(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, errors.New("must be a non-negative integer")
+	}
+	return n, nil
+}
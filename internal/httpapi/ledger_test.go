@@ -0,0 +1,334 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeLedger serves balances and history per user and records withdrawal requests
+type fakeLedger struct {
+	balances     map[string][]models.UserBalance
+	versions     map[string]string
+	balanceReads int
+	withdrawals  []models.WithdrawalRequest
+	withdrawErr  error
+}
+
+func (f *fakeLedger) GetUserBalances(_ context.Context, userId string) ([]models.UserBalance, error) {
+	f.balanceReads++
+	return f.balances[userId], nil
+}
+
+func (f *fakeLedger) GetUserBalancesVersion(_ context.Context, userId string) (string, error) {
+	if version, ok := f.versions[userId]; ok {
+		return version, nil
+	}
+	return "0-0", nil
+}
+
+func (f *fakeLedger) GetTransactionHistory(_ context.Context, userId, asset string, limit int, before string) ([]models.TransactionRecord, error) {
+	return []models.TransactionRecord{{Id: fmt.Sprintf("%s-%s-%d-%s", userId, asset, limit, before), Asset: asset}}, nil
+}
+
+func (f *fakeLedger) CreateWithdrawalForUser(_ context.Context, req models.WithdrawalRequest) (*models.WithdrawalResult, error) {
+	if f.withdrawErr != nil {
+		return nil, f.withdrawErr
+	}
+	f.withdrawals = append(f.withdrawals, req)
+	return &models.WithdrawalResult{Status: models.WithdrawalQueued, Asset: req.Asset, Amount: req.Amount, QueueId: "q-1"}, nil
+}
+
+type fakeUsers map[string]bool
+
+func (f fakeUsers) CreateUser(_ context.Context, userId, name, email string) (*models.User, error) {
+	if f[email] {
+		return nil, fmt.Errorf("%w with email %s", database.ErrUserExists, email)
+	}
+	f[email] = true
+	return &models.User{Id: userId, Name: name, Email: email}, nil
+}
+
+type fakeAddresses []models.Address
+
+func (f fakeAddresses) GetAddresses(_ context.Context, userId, asset, network string) ([]models.Address, error) {
+	var result []models.Address
+	for _, a := range f {
+		if a.UserId == userId && a.Asset == asset && a.Network == network {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (f fakeAddresses) GetAllUserAddresses(_ context.Context, userId string) ([]models.Address, error) {
+	var result []models.Address
+	for _, a := range f {
+		if a.UserId == userId {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// fakeInstructions hands out one address per user/asset, creating it on the first request like lazy provisioning
+type fakeInstructions map[string]string
+
+func (f fakeInstructions) GetDepositInstructions(_ context.Context, userId, symbol, network string) ([]models.DepositInstructions, error) {
+	if symbol == "FAIL" {
+		return nil, errors.New("prime unavailable")
+	}
+	key := userId + "/" + symbol
+	if _, ok := f[key]; !ok {
+		f[key] = fmt.Sprintf("%s-address-%d", symbol, len(f)+1)
+	}
+	return []models.DepositInstructions{{Asset: symbol, Network: network, Address: f[key]}}, nil
+}
+
+func serve(t *testing.T, handler http.Handler, method, target, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestUserBalancesAndTransactionsHandlers(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	ledger := &fakeLedger{balances: map[string][]models.UserBalance{
+		"alice": {{Asset: "ETH", Balance: decimal.RequireFromString("2"), Available: decimal.RequireFromString("1.5")}},
+	}}
+	auth := fakeAuthenticator{"psr_alice": "alice"}
+	mux := http.NewServeMux()
+	mux.Handle(UserBalancesPattern, RequireToken(auth, logger, UserBalancesHandler(ledger, logger)))
+	mux.Handle(UserTransactionsPattern, RequireToken(auth, logger, UserTransactionsHandler(ledger, logger)))
+
+	rec := serve(t, mux, http.MethodGet, "/users/alice/balances", "psr_alice", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var balances struct {
+		Balances []models.UserBalance `json:"balances"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&balances); err != nil {
+		t.Fatalf("Failed to decode balances: %v", err)
+	}
+	if len(balances.Balances) != 1 || !balances.Balances[0].Available.Equal(decimal.RequireFromString("1.5")) {
+		t.Errorf("Unexpected balances %+v", balances.Balances)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		token  string
+		want   int
+	}{
+		{"other user's balances", "/users/bob/balances", "psr_alice", http.StatusForbidden},
+		{"no token", "/users/alice/balances", "", http.StatusUnauthorized},
+		{"transactions", "/users/alice/transactions?asset=ETH&limit=5&before=tx-9", "psr_alice", http.StatusOK},
+		{"transactions without asset", "/users/alice/transactions", "psr_alice", http.StatusBadRequest},
+		{"invalid limit", "/users/alice/transactions?asset=ETH&limit=-1", "psr_alice", http.StatusBadRequest},
+		{"other user's transactions", "/users/bob/transactions?asset=ETH", "psr_alice", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if rec := serve(t, mux, http.MethodGet, tt.target, tt.token, ""); rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+
+	rec = serve(t, mux, http.MethodGet, "/users/alice/transactions?asset=ETH&limit=5&before=tx-9", "psr_alice", "")
+	if !strings.Contains(rec.Body.String(), `"next_before":"alice-ETH-5-tx-9"`) {
+		t.Errorf("Expected the cursor to reach the ledger and come back as next_before, got %s", rec.Body.String())
+	}
+}
+
+func TestWithdrawalsHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	disabled := false
+	assets := []models.AssetConfig{{Symbol: "BTC", Network: "bitcoin-mainnet", Listener: models.AssetListenerConfig{WithdrawalsEnabled: &disabled}}}
+	ledger := &fakeLedger{}
+	handler := RequireToken(fakeAuthenticator{"psr_alice": "alice"}, logger, WithdrawalsHandler(ledger, assets, true, logger))
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid", `{"asset":"ETH-ethereum-mainnet","amount":"0.5","destination_type":"ADDRESS","destination":"0xabc"}`, http.StatusCreated},
+		{"bad asset", `{"asset":"ETH","amount":"0.5","destination_type":"ADDRESS","destination":"0xabc"}`, http.StatusBadRequest},
+		{"zero amount", `{"asset":"ETH-ethereum-mainnet","amount":"0","destination_type":"ADDRESS","destination":"0xabc"}`, http.StatusBadRequest},
+		{"bad destination type", `{"asset":"ETH-ethereum-mainnet","amount":"1","destination_type":"CARRIER_PIGEON","destination":"0xabc"}`, http.StatusBadRequest},
+		{"unknown field", `{"asset":"ETH-ethereum-mainnet","amount":"1","user":"bob","destination":"0xabc"}`, http.StatusBadRequest},
+		{"withdrawals disabled", `{"asset":"BTC-bitcoin-mainnet","amount":"1","destination_type":"ADDRESS","destination":"bc1q"}`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		if rec := serve(t, handler, http.MethodPost, "/withdrawals", "psr_alice", tt.body); rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	if len(ledger.withdrawals) != 1 {
+		t.Fatalf("Expected one withdrawal to reach the ledger, got %d", len(ledger.withdrawals))
+	}
+	if got := ledger.withdrawals[0]; got.User != "alice" || !got.Queue || !got.Amount.Equal(decimal.RequireFromString("0.5")) {
+		t.Errorf("Expected a queued withdrawal for the token's user, got %+v", got)
+	}
+
+	if ledger.withdrawals[0].IdempotencyKey != "" {
+		t.Errorf("Expected no ledger idempotency key without the header, got %q", ledger.withdrawals[0].IdempotencyKey)
+	}
+
+	// The Idempotency-Key header becomes the withdrawal's ledger idempotency key
+	req := httptest.NewRequest(http.MethodPost, "/withdrawals",
+		strings.NewReader(`{"asset":"ETH-ethereum-mainnet","amount":"0.5","destination_type":"ADDRESS","destination":"0xabc"}`))
+	req.Header.Set("Authorization", "Bearer psr_alice")
+	req.Header.Set(IdempotencyKeyHeader, "client-key-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated || ledger.withdrawals[1].IdempotencyKey != "client-key-1" {
+		t.Errorf("Expected the header forwarded to the ledger, got %d with %+v", rec.Code, ledger.withdrawals[1])
+	}
+
+	ledger.withdrawErr = fmt.Errorf("%w: available=0, requested=1", database.ErrInsufficientBalance)
+	rec = serve(t, handler, http.MethodPost, "/withdrawals", "psr_alice",
+		`{"asset":"ETH-ethereum-mainnet","amount":"1","destination_type":"ADDRESS","destination":"0xabc"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an insufficient balance, got %d", rec.Code)
+	}
+}
+
+func TestCreateUserHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	adminToken := strings.Repeat("a", 32)
+	users := fakeUsers{"taken@example.com": true}
+	handler := RequireAdminToken(adminToken, CreateUserHandler(users, logger))
+
+	tests := []struct {
+		name  string
+		token string
+		body  string
+		want  int
+	}{
+		{"created", adminToken, `{"name":"Alice","email":"alice@example.com"}`, http.StatusCreated},
+		{"duplicate email", adminToken, `{"name":"Taken","email":"taken@example.com"}`, http.StatusConflict},
+		{"invalid email", adminToken, `{"name":"Alice","email":"not an email"}`, http.StatusBadRequest},
+		{"short name", adminToken, `{"name":"A","email":"a@example.com"}`, http.StatusBadRequest},
+		{"wrong token", "psr_alice", `{"name":"Bob","email":"bob@example.com"}`, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if rec := serve(t, handler, http.MethodPost, "/users", tt.token, tt.body); rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d: %s", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	disabled := RequireAdminToken("", CreateUserHandler(users, logger))
+	if rec := serve(t, disabled, http.MethodPost, "/users", "", `{"name":"Bob","email":"bob@example.com"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without an admin token configured, got %d", rec.Code)
+	}
+}
+
+func TestAddressesHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	addresses := fakeAddresses{
+		{UserId: "alice", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xabc", WalletId: "wallet-1"},
+		{UserId: "alice", Asset: "BTC", Network: "bitcoin-mainnet", Address: "bc1q"},
+		{UserId: "bob", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xdef"},
+	}
+	handler := RequireToken(fakeAuthenticator{"psr_alice": "alice"}, logger, AddressesHandler(addresses, logger))
+
+	tests := []struct {
+		target string
+		want   int
+		count  int
+	}{
+		{"/addresses", http.StatusOK, 2},
+		{"/addresses?asset=ETH&network=ethereum-mainnet", http.StatusOK, 1},
+		{"/addresses?asset=ETH", http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		rec := serve(t, handler, http.MethodGet, tt.target, "psr_alice", "")
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.target, tt.want, rec.Code)
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+		if strings.Contains(rec.Body.String(), "wallet-1") {
+			t.Errorf("%s: wallet ids must not be exposed: %s", tt.target, rec.Body.String())
+		}
+		var response struct {
+			Addresses []AddressResponse `json:"addresses"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("%s: failed to decode addresses: %v", tt.target, err)
+		}
+		if len(response.Addresses) != tt.count {
+			t.Errorf("%s: expected %d addresses, got %+v", tt.target, tt.count, response.Addresses)
+		}
+	}
+}
+
+func TestDepositInstructionsHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	instructions := fakeInstructions{}
+	handler := RequireToken(fakeAuthenticator{"psr_alice": "alice"}, logger, DepositInstructionsHandler(instructions, logger))
+
+	if rec := serve(t, handler, http.MethodGet, "/deposit-instructions", "psr_alice", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without an asset, got %d", rec.Code)
+	}
+	if rec := serve(t, handler, http.MethodGet, "/deposit-instructions?asset=FAIL", "psr_alice", ""); rec.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when provisioning fails, got %d", rec.Code)
+	}
+
+	var addresses []string
+	for i := 0; i < 2; i++ {
+		rec := serve(t, handler, http.MethodGet, "/deposit-instructions?asset=SOL&network=solana-mainnet", "psr_alice", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+		}
+		var response struct {
+			Instructions []models.DepositInstructions `json:"instructions"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode instructions: %v", err)
+		}
+		if len(response.Instructions) != 1 || response.Instructions[0].Network != "solana-mainnet" {
+			t.Fatalf("Expected one solana-mainnet instruction, got %+v", response.Instructions)
+		}
+		addresses = append(addresses, response.Instructions[0].Address)
+	}
+	if addresses[0] != addresses[1] || instructions["alice/SOL"] != addresses[0] {
+		t.Errorf("Expected the same address on both requests, got %v", addresses)
+	}
+}
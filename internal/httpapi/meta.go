@@ -0,0 +1,53 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// MetaPattern is the route MetaHandler expects to be mounted on
+const MetaPattern = "GET /meta"
+
+// Response headers set by StampVersion
+const (
+	VersionHeader       = "X-Ledger-Version"
+	SchemaVersionHeader = "X-Ledger-Schema-Version"
+)
+
+// MetaHandler returns the deployment's version, schema version, enabled features and supported assets
+func MetaHandler(meta models.ServiceMeta) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(meta)
+	})
+}
+
+// StampVersion adds the build and schema version to every response, so a client can tell which
+// deployment answered without calling /meta
+func StampVersion(meta models.ServiceMeta, next http.Handler) http.Handler {
+	schemaVersion := strconv.Itoa(meta.SchemaVersion)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(VersionHeader, meta.Version)
+		w.Header().Set(SchemaVersionHeader, schemaVersion)
+		next.ServeHTTP(w, r)
+	})
+}
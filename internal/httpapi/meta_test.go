@@ -0,0 +1,59 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestMetaHandler(t *testing.T) {
+	meta := models.ServiceMeta{
+		Version:       "v1.2.3",
+		SchemaVersion: 4,
+		Features:      []string{"listener", "metrics"},
+		Assets:        []models.MetaAsset{{Symbol: "ETH", Network: "ethereum-mainnet", Deposits: true, Withdrawals: false}},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(MetaPattern, MetaHandler(meta))
+	handler := StampVersion(meta, mux)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/meta", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	var got models.ServiceMeta
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("Failed to decode meta: %v", err)
+	}
+	if got.Version != "v1.2.3" || got.SchemaVersion != 4 || len(got.Features) != 2 || len(got.Assets) != 1 || got.Assets[0].Withdrawals {
+		t.Errorf("Unexpected meta: %+v", got)
+	}
+
+	// Every response is stamped, including ones the meta route does not serve
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if rec.Header().Get(VersionHeader) != "v1.2.3" || rec.Header().Get(SchemaVersionHeader) != "4" {
+		t.Errorf("Expected version headers on every response, got %v", rec.Header())
+	}
+}
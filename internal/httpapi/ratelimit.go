@@ -0,0 +1,196 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Rate limit scopes, used as the label on throttle metrics
+const (
+	ScopeIp    = "ip"
+	ScopeToken = "token"
+)
+
+// idleBucketTTL is how long a client's bucket is kept after its last request
+const idleBucketTTL = 10 * time.Minute
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// quota is a token bucket refilled at rate per second, holding at most burst requests
+type quota struct {
+	rate  float64
+	burst float64
+}
+
+// RateLimiter enforces per-IP and per-token request quotas with token buckets.
+// A zero per-minute quota disables limiting for that scope.
+type RateLimiter struct {
+	quotas          map[string]quota
+	trustForwardFor bool
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+
+	throttledIp    atomic.Uint64
+	throttledToken atomic.Uint64
+
+	logger *zap.Logger
+}
+
+// NewRateLimiter creates a limiter from the API configuration
+func NewRateLimiter(cfg models.ApiConfig, logger *zap.Logger) *RateLimiter {
+	quotas := make(map[string]quota)
+	burst := float64(cfg.RateLimitBurst)
+	if cfg.RateLimitPerIp > 0 {
+		quotas[ScopeIp] = quota{rate: float64(cfg.RateLimitPerIp) / 60, burst: math.Max(burst, 1)}
+	}
+	if cfg.RateLimitPerToken > 0 {
+		quotas[ScopeToken] = quota{rate: float64(cfg.RateLimitPerToken) / 60, burst: math.Max(burst, 1)}
+	}
+
+	return &RateLimiter{
+		quotas:          quotas,
+		trustForwardFor: cfg.TrustForwardedFor,
+		buckets:         make(map[string]*bucket),
+		now:             time.Now,
+		logger:          logger,
+	}
+}
+
+// LimitByIp throttles requests per client address. Mount it in front of RequireToken so
+// that guessing tokens is throttled too.
+func (l *RateLimiter) LimitByIp(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !l.allow(w, ScopeIp, l.clientIp(r)) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// LimitByToken throttles requests per API token. It must run after RequireToken.
+func (l *RateLimiter) LimitByToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token, ok := TokenFromContext(r.Context()); ok && !l.allow(w, ScopeToken, token.Id) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Throttled returns the number of requests rejected so far, by scope
+func (l *RateLimiter) Throttled() map[string]uint64 {
+	return map[string]uint64{
+		ScopeIp:    l.throttledIp.Load(),
+		ScopeToken: l.throttledToken.Load(),
+	}
+}
+
+// allow takes a request from the key's bucket, writing a 429 with Retry-After when it is empty
+func (l *RateLimiter) allow(w http.ResponseWriter, scope, key string) bool {
+	q, ok := l.quotas[scope]
+	if !ok {
+		return true
+	}
+
+	wait := l.take(scope+":"+key, q)
+	if wait == 0 {
+		return true
+	}
+
+	if scope == ScopeIp {
+		l.throttledIp.Add(1)
+	} else {
+		l.throttledToken.Add(1)
+	}
+	l.logger.Debug("Request throttled", zap.String("scope", scope), zap.String("key", key))
+
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+	return false
+}
+
+// take removes one request from the bucket, returning zero if it was allowed or how long
+// until a request will be
+func (l *RateLimiter) take(key string, q quota) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: q.burst}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(q.burst, b.tokens+now.Sub(b.lastSeen).Seconds()*q.rate)
+	}
+	b.lastSeen = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / q.rate * float64(time.Second))
+}
+
+// sweep drops buckets of clients that have gone quiet; idle buckets are full anyway
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleBucketTTL {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// clientIp is the request's remote address, or the first X-Forwarded-For hop when the
+// server runs behind a trusted proxy
+func (l *RateLimiter) clientIp(r *http.Request) string {
+	if l.trustForwardFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
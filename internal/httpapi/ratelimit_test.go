@@ -0,0 +1,74 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func TestRateLimiter_ThrottlesPerIpAndRefills(t *testing.T) {
+	limiter := NewRateLimiter(models.ApiConfig{RateLimitPerIp: 60, RateLimitBurst: 2}, zaptest.NewLogger(t))
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	handler := limiter.LimitByIp(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	request := func(addr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/balances", nil)
+		req.RemoteAddr = addr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("10.0.0.1:1234"); rec.Code != http.StatusOK {
+			t.Fatalf("Request %d within burst: expected 200, got %d", i+1, rec.Code)
+		}
+	}
+
+	rec := request("10.0.0.1:5678")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 once the burst is spent, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "1" {
+		t.Errorf("Expected Retry-After 1, got %q", rec.Header().Get("Retry-After"))
+	}
+
+	// Other clients have their own quota
+	if rec := request("10.0.0.2:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected a different IP to be allowed, got %d", rec.Code)
+	}
+
+	// 60 per minute refills one request per second
+	now = now.Add(time.Second)
+	if rec := request("10.0.0.1:1234"); rec.Code != http.StatusOK {
+		t.Errorf("Expected a request to be allowed after refill, got %d", rec.Code)
+	}
+
+	if throttled := limiter.Throttled(); throttled[ScopeIp] != 1 || throttled[ScopeToken] != 0 {
+		t.Errorf("Unexpected throttle counts: %v", throttled)
+	}
+}
@@ -0,0 +1,61 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// WithdrawalReceiptPattern is the route WithdrawalReceiptHandler expects to be mounted on
+const WithdrawalReceiptPattern = "GET /withdrawals/{activity_id}/receipt"
+
+// WithdrawalReceiptSource looks up the stored Prime response of a submitted withdrawal
+type WithdrawalReceiptSource interface {
+	GetWithdrawalReceipt(ctx context.Context, activityId string) (*models.WithdrawalReceipt, error)
+}
+
+// WithdrawalReceiptHandler returns the receipt of a withdrawal by Prime activity id. It must run behind
+// RequireToken; a token can only read receipts of its own user's withdrawals.
+func WithdrawalReceiptHandler(receipts WithdrawalReceiptSource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receipt, err := receipts.GetWithdrawalReceipt(r.Context(), r.PathValue("activity_id"))
+		if errors.Is(err, database.ErrReceiptNotFound) {
+			writeError(w, http.StatusNotFound, "receipt not found")
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get withdrawal receipt", zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get receipt")
+			return
+		}
+
+		if !AuthorizeUser(w, r, receipt.UserId) {
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(receipt)
+	})
+}
@@ -0,0 +1,83 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeReceipts map[string]models.WithdrawalReceipt
+
+func (f fakeReceipts) GetWithdrawalReceipt(_ context.Context, activityId string) (*models.WithdrawalReceipt, error) {
+	receipt, ok := f[activityId]
+	if !ok {
+		return nil, database.ErrReceiptNotFound
+	}
+	return &receipt, nil
+}
+
+func TestWithdrawalReceiptHandler(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	receipts := fakeReceipts{
+		"act-1": {ActivityId: "act-1", UserId: "alice", Symbol: "ETH", Amount: "1.5", Fee: "0.001"},
+		"act-2": {ActivityId: "act-2", UserId: "bob", Symbol: "ETH", Amount: "2"},
+	}
+	mux := http.NewServeMux()
+	mux.Handle(WithdrawalReceiptPattern, RequireToken(fakeAuthenticator{"psr_alice": "alice"}, logger,
+		WithdrawalReceiptHandler(receipts, logger)))
+
+	tests := []struct {
+		name       string
+		activityId string
+		want       int
+	}{
+		{"own receipt", "act-1", http.StatusOK},
+		{"other user's receipt", "act-2", http.StatusForbidden},
+		{"unknown activity", "act-3", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/withdrawals/"+tt.activityId+"/receipt", nil)
+		req.Header.Set("Authorization", "Bearer psr_alice")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, rec.Code)
+			continue
+		}
+		if tt.want != http.StatusOK {
+			continue
+		}
+
+		var receipt models.WithdrawalReceipt
+		if err := json.NewDecoder(rec.Body).Decode(&receipt); err != nil {
+			t.Fatalf("%s: failed to decode receipt: %v", tt.name, err)
+		}
+		if receipt.ActivityId != "act-1" || receipt.Fee != "0.001" {
+			t.Errorf("%s: unexpected receipt %+v", tt.name, receipt)
+		}
+	}
+}
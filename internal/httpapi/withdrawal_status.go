@@ -0,0 +1,109 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Routes the withdrawal status handlers expect to be mounted on
+const (
+	WithdrawalStatusPattern = "GET /withdrawals/{idempotency_key}"
+	UserWithdrawalsPattern  = "GET /users/{id}/withdrawals"
+)
+
+// WithdrawalStatusSource looks up withdrawal requests and their status changes
+type WithdrawalStatusSource interface {
+	GetWithdrawalRequest(ctx context.Context, idempotencyKey string) (*models.WithdrawalRequestRecord, error)
+	ListWithdrawalRequests(ctx context.Context, filter database.WithdrawalRequestFilter) ([]models.WithdrawalRequestRecord, error)
+	ListWithdrawalRequestEvents(ctx context.Context, idempotencyKey string) ([]models.WithdrawalStatusEvent, error)
+}
+
+// WithdrawalStatusHandler returns a withdrawal by idempotency key with every status change it went
+// through. It must run behind RequireToken; a token can only read its own user's withdrawals.
+func WithdrawalStatusHandler(withdrawals WithdrawalStatusSource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.PathValue("idempotency_key")
+		request, err := withdrawals.GetWithdrawalRequest(r.Context(), key)
+		if err != nil {
+			logger.Error("Failed to get withdrawal request", zap.String("idempotency_key", key), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get withdrawal")
+			return
+		}
+		if request == nil {
+			writeError(w, http.StatusNotFound, "withdrawal not found")
+			return
+		}
+
+		if !AuthorizeUser(w, r, request.UserId) {
+			return
+		}
+
+		events, err := withdrawals.ListWithdrawalRequestEvents(r.Context(), key)
+		if err != nil {
+			logger.Error("Failed to get withdrawal request events", zap.String("idempotency_key", key), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to get withdrawal")
+			return
+		}
+		if events == nil {
+			events = []models.WithdrawalStatusEvent{}
+		}
+
+		writeJson(w, http.StatusOK, map[string]any{"withdrawal": request, "events": events})
+	})
+}
+
+// UserWithdrawalsHandler lists a user's withdrawals, newest first, optionally only those in one status.
+// It must run behind RequireToken.
+func UserWithdrawalsHandler(withdrawals WithdrawalStatusSource, logger *zap.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId := r.PathValue("id")
+		if !AuthorizeUser(w, r, userId) {
+			return
+		}
+
+		query := r.URL.Query()
+		limit, err := queryInt(query.Get("limit"), 20)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+
+		requests, err := withdrawals.ListWithdrawalRequests(r.Context(), database.WithdrawalRequestFilter{
+			UserId: userId,
+			Status: strings.TrimSpace(query.Get("status")),
+			Limit:  limit,
+		})
+		if err != nil {
+			logger.Error("Failed to list withdrawal requests", zap.String("user_id", userId), zap.Error(err))
+			writeError(w, http.StatusInternalServerError, "unable to list withdrawals")
+			return
+		}
+		if requests == nil {
+			requests = []models.WithdrawalRequestRecord{}
+		}
+
+		writeJson(w, http.StatusOK, map[string]any{"withdrawals": requests})
+	})
+}
@@ -0,0 +1,126 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+type fakeWithdrawalStatus struct {
+	requests []models.WithdrawalRequestRecord
+	events   map[string][]models.WithdrawalStatusEvent
+}
+
+func (f fakeWithdrawalStatus) GetWithdrawalRequest(_ context.Context, idempotencyKey string) (*models.WithdrawalRequestRecord, error) {
+	for _, request := range f.requests {
+		if request.IdempotencyKey == idempotencyKey {
+			return &request, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f fakeWithdrawalStatus) ListWithdrawalRequests(_ context.Context, filter database.WithdrawalRequestFilter) ([]models.WithdrawalRequestRecord, error) {
+	var requests []models.WithdrawalRequestRecord
+	for _, request := range f.requests {
+		if request.UserId == filter.UserId && (filter.Status == "" || request.Status == filter.Status) {
+			requests = append(requests, request)
+		}
+	}
+	return requests, nil
+}
+
+func (f fakeWithdrawalStatus) ListWithdrawalRequestEvents(_ context.Context, idempotencyKey string) ([]models.WithdrawalStatusEvent, error) {
+	return f.events[idempotencyKey], nil
+}
+
+func TestWithdrawalStatusHandlers(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	withdrawals := fakeWithdrawalStatus{
+		requests: []models.WithdrawalRequestRecord{
+			{IdempotencyKey: "wd-1", UserId: "alice", Asset: "ETH", Status: database.WithdrawalRequestStatusPending, PrimeStatus: "TRANSACTION_PROCESSING"},
+			{IdempotencyKey: "wd-2", UserId: "alice", Asset: "ETH", Status: database.WithdrawalRequestStatusCompleted},
+			{IdempotencyKey: "wd-3", UserId: "bob", Asset: "ETH", Status: database.WithdrawalRequestStatusCreated},
+		},
+		events: map[string][]models.WithdrawalStatusEvent{
+			"wd-1": {
+				{FromStatus: "created", ToStatus: "submitted"},
+				{FromStatus: "submitted", ToStatus: "pending", PrimeStatus: "TRANSACTION_PROCESSING"},
+			},
+		},
+	}
+	auth := fakeAuthenticator{"psr_alice": "alice"}
+	mux := http.NewServeMux()
+	mux.Handle(WithdrawalStatusPattern, RequireToken(auth, logger, WithdrawalStatusHandler(withdrawals, logger)))
+	mux.Handle(UserWithdrawalsPattern, RequireToken(auth, logger, UserWithdrawalsHandler(withdrawals, logger)))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer psr_alice")
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"own withdrawal", "/withdrawals/wd-1", http.StatusOK},
+		{"other user's withdrawal", "/withdrawals/wd-3", http.StatusForbidden},
+		{"unknown withdrawal", "/withdrawals/wd-4", http.StatusNotFound},
+		{"own withdrawals", "/users/alice/withdrawals", http.StatusOK},
+		{"other user's withdrawals", "/users/bob/withdrawals", http.StatusForbidden},
+		{"invalid limit", "/users/alice/withdrawals?limit=x", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if rec := get(tt.path); rec.Code != tt.want {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+
+	var status struct {
+		Withdrawal models.WithdrawalRequestRecord `json:"withdrawal"`
+		Events     []models.WithdrawalStatusEvent `json:"events"`
+	}
+	if err := json.NewDecoder(get("/withdrawals/wd-1").Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode withdrawal: %v", err)
+	}
+	if status.Withdrawal.PrimeStatus != "TRANSACTION_PROCESSING" || len(status.Events) != 2 || status.Events[1].ToStatus != "pending" {
+		t.Errorf("Unexpected withdrawal status %+v", status)
+	}
+
+	var list struct {
+		Withdrawals []models.WithdrawalRequestRecord `json:"withdrawals"`
+	}
+	if err := json.NewDecoder(get("/users/alice/withdrawals?status=completed").Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode withdrawals: %v", err)
+	}
+	if len(list.Withdrawals) != 1 || list.Withdrawals[0].IdempotencyKey != "wd-2" {
+		t.Errorf("Expected only wd-2, got %+v", list.Withdrawals)
+	}
+}
@@ -19,47 +19,91 @@ package listener
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
 	"prime-send-receive-go/internal/api"
 	"prime-send-receive-go/internal/common"
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/custody"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
-	"prime-send-receive-go/internal/prime"
 
 	"go.uber.org/zap"
 )
 
 // SendReceiveListenerConfig contains configuration for SendReceiveListener
 type SendReceiveListenerConfig struct {
-	PrimeService    *prime.Service
+	Custody         custody.Provider
 	ApiService      *api.LedgerService
 	DbService       *database.Service
 	PortfolioId     string
 	LookbackWindow  time.Duration
 	PollingInterval time.Duration
 	CleanupInterval time.Duration
+	MaxConcurrency  int
+	// StartupScanWindow, when positive, replaces the per-wallet checkpoints as the startup recovery range
+	StartupScanWindow time.Duration
+	Coordinator       coordination.Store
+	InstanceId        string
+	WalletLeaseTTL    time.Duration
+	// FundsAvailability is the default availability policy for deposits; assets.yaml may override it per asset
+	FundsAvailability string
+	// TransactionTypes are the Prime transaction types applied to the ledger (default DEPOSIT and WITHDRAWAL)
+	TransactionTypes []string
+	// FullScanInterval, when positive, enables incremental polls between full lookback scans
+	FullScanInterval time.Duration
+	// PageLimit caps the transactions fetched per wallet poll (0 = the provider default)
+	PageLimit int
+	// IdlePollingInterval is the longest a wallet's polling interval backs off to while it is idle
+	IdlePollingInterval time.Duration
+	Logger              *zap.Logger
 }
 
 // SendReceiveListener polls Prime API for new deposits and processes them
 type SendReceiveListener struct {
-	primeService *prime.Service
-	apiService   *api.LedgerService
-	dbService    *database.Service
+	custody    custody.Provider
+	apiService *api.LedgerService
+	dbService  *database.Service
 
-	// State management for processed transactions
-	processedTxIds  map[string]time.Time
-	mutex           sync.RWMutex
-	lookbackWindow  time.Duration
-	pollingInterval time.Duration
-	cleanupInterval time.Duration
+	// Transaction step dedupe, rate limits and wallet leases (in-memory or shared via Redis)
+	coordinator    coordination.Store
+	instanceId     string
+	walletLeaseTTL time.Duration
+
+	lookbackWindow    time.Duration
+	pollingInterval   time.Duration
+	cleanupInterval   time.Duration
+	maxConcurrency    int
+	startupScanWindow time.Duration
+
+	// Incremental polling: full scan schedule, page size and idle backoff ceiling
+	fullScanInterval    time.Duration
+	pageLimit           int
+	idlePollingInterval time.Duration
+
+	fundsAvailability string
+
+	// Transaction types applied to the ledger, and how many transactions of other types were skipped
+	transactionTypes map[string]bool
+	skippedMu        sync.Mutex
+	skipped          map[string]uint64
 
 	// Monitoring configuration
 	portfolioId      string
 	monitoredWallets []models.WalletInfo
 
+	// Per-asset settings from assets.yaml and what each wallet's earlier polls saw
+	assets       []models.AssetConfig
+	tickInterval time.Duration
+	pollStates   map[string]*walletPollState
+
+	// process applies one transaction for processInOrder. It is processTransaction outside of tests.
+	process func(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error
+
+	logger *zap.Logger
+
 	// Control channels
 	stopChan chan struct{}
 	doneChan chan struct{}
@@ -67,26 +111,65 @@ type SendReceiveListener struct {
 
 // NewSendReceiveListener creates a new deposit listener
 func NewSendReceiveListener(cfg SendReceiveListenerConfig) *SendReceiveListener {
-	return &SendReceiveListener{
-		primeService:    cfg.PrimeService,
-		apiService:      cfg.ApiService,
-		dbService:       cfg.DbService,
-		processedTxIds:  make(map[string]time.Time),
-		lookbackWindow:  cfg.LookbackWindow,
-		pollingInterval: cfg.PollingInterval,
-		cleanupInterval: cfg.CleanupInterval,
-		portfolioId:     cfg.PortfolioId,
-		stopChan:        make(chan struct{}),
-		doneChan:        make(chan struct{}),
+	maxConcurrency := cfg.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+
+	coordinator := cfg.Coordinator
+	if coordinator == nil {
+		coordinator = coordination.NewMemoryStore()
+	}
+
+	walletLeaseTTL := cfg.WalletLeaseTTL
+	if walletLeaseTTL <= 0 {
+		walletLeaseTTL = 2 * cfg.PollingInterval
 	}
+
+	transactionTypes := make(map[string]bool)
+	for _, txType := range cfg.TransactionTypes {
+		transactionTypes[txType] = true
+	}
+	if len(transactionTypes) == 0 {
+		transactionTypes["DEPOSIT"] = true
+		transactionTypes["WITHDRAWAL"] = true
+	}
+
+	d := &SendReceiveListener{
+		custody:             cfg.Custody,
+		apiService:          cfg.ApiService,
+		dbService:           cfg.DbService,
+		coordinator:         coordinator,
+		instanceId:          cfg.InstanceId,
+		walletLeaseTTL:      walletLeaseTTL,
+		lookbackWindow:      cfg.LookbackWindow,
+		pollingInterval:     cfg.PollingInterval,
+		cleanupInterval:     cfg.CleanupInterval,
+		maxConcurrency:      maxConcurrency,
+		startupScanWindow:   cfg.StartupScanWindow,
+		fullScanInterval:    cfg.FullScanInterval,
+		pageLimit:           cfg.PageLimit,
+		idlePollingInterval: cfg.IdlePollingInterval,
+		fundsAvailability:   cfg.FundsAvailability,
+		transactionTypes:    transactionTypes,
+		skipped:             make(map[string]uint64),
+		portfolioId:         cfg.PortfolioId,
+		tickInterval:        cfg.PollingInterval,
+		pollStates:          make(map[string]*walletPollState),
+		logger:              cfg.Logger,
+		stopChan:            make(chan struct{}),
+		doneChan:            make(chan struct{}),
+	}
+	d.process = d.processTransaction
+	return d
 }
 
-func getUniqueAssetSymbols(assetConfigs []common.AssetConfig) map[string]bool {
-	assetSymbols := make(map[string]bool)
-	for _, assetConfig := range assetConfigs {
-		assetSymbols[assetConfig.Symbol] = true
+func getEnabledAssetNetworks(assetConfigs []models.AssetConfig) map[string]bool {
+	assetNetworks := make(map[string]bool)
+	for _, assetConfig := range common.EnabledAssets(assetConfigs) {
+		assetNetworks[assetConfig.AssetNetwork()] = true
 	}
-	return assetSymbols
+	return assetNetworks
 }
 
 func getUserAddresses(ctx context.Context, dbService *database.Service, userId string) ([]models.Address, error) {
@@ -97,10 +180,10 @@ func getUserAddresses(ctx context.Context, dbService *database.Service, userId s
 	return addresses, nil
 }
 
-func extractWalletsFromAddresses(addresses []models.Address, assetSymbols map[string]bool) map[string]models.WalletInfo {
+func extractWalletsFromAddresses(addresses []models.Address, assetNetworks map[string]bool) map[string]models.WalletInfo {
 	walletMap := make(map[string]models.WalletInfo)
 	for _, addr := range addresses {
-		if assetSymbols[addr.Asset] && addr.WalletId != "" {
+		if assetNetworks[addr.Asset+"-"+addr.Network] && addr.WalletId != "" {
 			walletMap[addr.WalletId] = models.WalletInfo{
 				Id:          addr.WalletId,
 				AssetSymbol: addr.Asset,
@@ -110,19 +193,19 @@ func extractWalletsFromAddresses(addresses []models.Address, assetSymbols map[st
 	return walletMap
 }
 
-func collectWalletsFromAllUsers(ctx context.Context, dbService *database.Service, users []models.User, assetSymbols map[string]bool) map[string]models.WalletInfo {
+func (d *SendReceiveListener) collectWalletsFromAllUsers(ctx context.Context, users []models.User, assetNetworks map[string]bool) map[string]models.WalletInfo {
 	allWallets := make(map[string]models.WalletInfo)
 
 	for _, user := range users {
-		addresses, err := getUserAddresses(ctx, dbService, user.Id)
+		addresses, err := getUserAddresses(ctx, d.dbService, user.Id)
 		if err != nil {
-			zap.L().Error("Failed to get addresses for user",
+			d.logger.Error("Failed to get addresses for user",
 				zap.String("user_id", user.Id),
 				zap.Error(err))
 			continue
 		}
 
-		userWallets := extractWalletsFromAddresses(addresses, assetSymbols)
+		userWallets := extractWalletsFromAddresses(addresses, assetNetworks)
 		for walletId, wallet := range userWallets {
 			allWallets[walletId] = wallet
 		}
@@ -133,7 +216,7 @@ func collectWalletsFromAllUsers(ctx context.Context, dbService *database.Service
 
 // LoadMonitoredWallets loads the list of trading wallets from the database
 func (d *SendReceiveListener) LoadMonitoredWallets(ctx context.Context, assetsFile string) error {
-	zap.L().Info("Loading monitored wallets from database")
+	d.logger.Info("Loading monitored wallets from database")
 
 	// Load asset configs from file
 	assetConfigs, err := common.LoadAssetConfig(assetsFile)
@@ -141,13 +224,15 @@ func (d *SendReceiveListener) LoadMonitoredWallets(ctx context.Context, assetsFi
 		return fmt.Errorf("failed to load assets from YAML: %w", err)
 	}
 
-	zap.L().Info("Loaded assets from file",
+	d.logger.Info("Loaded assets from file",
 		zap.String("file", assetsFile),
 		zap.Int("count", len(assetConfigs)))
 
-	// Get unique asset symbols
-	assetSymbols := getUniqueAssetSymbols(assetConfigs)
-	zap.L().Info("Unique assets to monitor", zap.Int("count", len(assetSymbols)))
+	d.assets = assetConfigs
+
+	// Disabled assets are not monitored
+	assetNetworks := getEnabledAssetNetworks(assetConfigs)
+	d.logger.Info("Enabled assets to monitor", zap.Int("count", len(assetNetworks)))
 
 	// Query all users
 	users, err := d.dbService.GetUsers(ctx)
@@ -156,7 +241,7 @@ func (d *SendReceiveListener) LoadMonitoredWallets(ctx context.Context, assetsFi
 	}
 
 	// Collect wallets from all users
-	walletMap := collectWalletsFromAllUsers(ctx, d.dbService, users, assetSymbols)
+	walletMap := d.collectWalletsFromAllUsers(ctx, users, assetNetworks)
 
 	// Convert map to slice
 	d.monitoredWallets = make([]models.WalletInfo, 0, len(walletMap))
@@ -164,84 +249,190 @@ func (d *SendReceiveListener) LoadMonitoredWallets(ctx context.Context, assetsFi
 		d.monitoredWallets = append(d.monitoredWallets, wallet)
 	}
 
-	zap.L().Info("Loaded monitored wallets",
+	// Poll often enough for the shortest per-asset polling interval
+	d.tickInterval = d.pollingInterval
+	for _, wallet := range d.monitoredWallets {
+		interval := d.walletPollingInterval(wallet)
+		if interval < d.tickInterval {
+			d.tickInterval = interval
+		}
+		if interval > d.lookbackWindow {
+			d.logger.Warn("Asset polling interval exceeds the lookback window - transactions may be missed",
+				zap.String("asset_symbol", wallet.AssetSymbol),
+				zap.Duration("polling_interval", interval),
+				zap.Duration("lookback_window", d.lookbackWindow))
+		}
+	}
+
+	d.logger.Info("Loaded monitored wallets",
 		zap.Int("count", len(d.monitoredWallets)),
 		zap.Any("wallets", d.monitoredWallets))
 
 	return nil
 }
 
-// fetchWalletTransactions calls Prime API to get wallet transactions
-func (d *SendReceiveListener) fetchWalletTransactions(ctx context.Context, walletId string, since time.Time) ([]models.PrimeTransaction, error) {
-	zap.L().Debug("Fetching wallet transactions from Prime API",
-		zap.String("wallet_id", walletId),
-		zap.Time("since", since))
-
-	// Call Prime SDK
-	response, err := d.primeService.ListWalletTransactions(ctx, d.portfolioId, walletId, since)
-	if err != nil {
-		return nil, fmt.Errorf("Prime API call failed: %w", err)
-	}
-
-	// Convert Prime SDK response to our internal format
-	transactions := make([]models.PrimeTransaction, 0)
-
-	for _, tx := range response.Transactions {
-		// Transaction times are already time.Time in the SDK
-		createdAt := tx.Created
-		completedAt := tx.Completed
-
-		// Convert to our internal format
-		primeTransaction := models.PrimeTransaction{
-			Id:             tx.Id,
-			WalletId:       tx.WalletId,
-			Type:           tx.Type,
-			Status:         tx.Status,
-			Symbol:         tx.Symbol,
-			Amount:         tx.Amount,
-			CreatedAt:      createdAt,
-			CompletedAt:    completedAt,
-			TransactionId:  tx.TransactionId,
-			Network:        tx.Network,
-			IdempotencyKey: tx.IdempotencyKey,
+// assetConfig returns the assets.yaml settings for a Prime symbol and network, or the defaults when the
+// asset is not listed
+func (d *SendReceiveListener) assetConfig(symbol, network string) models.AssetConfig {
+	symbol = normalizeSymbol(symbol)
+	if asset, ok := common.FindAssetConfig(d.assets, symbol, network); ok {
+		return asset
+	}
+	// Without a network, fall back to the only configured network for the symbol
+	if network == "" {
+		var matches []models.AssetConfig
+		for _, asset := range d.assets {
+			if asset.Symbol == symbol {
+				matches = append(matches, asset)
+			}
+		}
+		if len(matches) == 1 {
+			return matches[0]
 		}
+	}
+	return models.AssetConfig{Symbol: symbol, Network: network}
+}
 
-		// Extract transfer_to information
-		if tx.TransferTo != nil {
-			primeTransaction.TransferTo.Type = tx.TransferTo.Type
-			primeTransaction.TransferTo.Value = tx.TransferTo.Value
-			primeTransaction.TransferTo.Address = tx.TransferTo.Address
-			primeTransaction.TransferTo.AccountIdentifier = tx.TransferTo.AccountIdentifier
+// walletPollingInterval returns the shortest polling interval configured for the wallet's asset
+func (d *SendReceiveListener) walletPollingInterval(wallet models.WalletInfo) time.Duration {
+	interval := d.pollingInterval
+	for _, asset := range common.EnabledAssets(d.assets) {
+		if asset.Symbol == wallet.AssetSymbol && asset.Listener.PollingInterval > 0 && asset.Listener.PollingInterval < interval {
+			interval = asset.Listener.PollingInterval
 		}
+	}
+	return interval
+}
+
+// walletPollState returns the polling state for a wallet, creating it on first use
+func (d *SendReceiveListener) walletPollState(walletId string) *walletPollState {
+	state, ok := d.pollStates[walletId]
+	if !ok {
+		state = &walletPollState{}
+		d.pollStates[walletId] = state
+	}
+	return state
+}
 
-		transactions = append(transactions, primeTransaction)
+// walletDue reports whether a wallet's current polling interval has elapsed, allowing half a tick of
+// ticker jitter. Idle wallets back off from the configured interval (see walletPollState.adapt).
+func (d *SendReceiveListener) walletDue(wallet models.WalletInfo, now time.Time) bool {
+	state, ok := d.pollStates[wallet.Id]
+	if !ok || state.lastAttempt.IsZero() {
+		return true
+	}
+	interval := state.interval
+	if interval <= 0 {
+		interval = d.walletPollingInterval(wallet)
 	}
+	return now.Sub(state.lastAttempt) >= interval-d.tickInterval/2
+}
 
-	zap.L().Debug("Converted Prime transactions",
+// fetchWalletTransactions fetches up to limit of a wallet's transactions created since the given time
+// from the custody provider; a limit of 0 uses the provider's default page size
+func (d *SendReceiveListener) fetchWalletTransactions(ctx context.Context, walletId string, since time.Time, limit int) ([]models.PrimeTransaction, error) {
+	d.logger.Debug("Fetching wallet transactions",
+		zap.String("provider", d.custody.Name()),
 		zap.String("wallet_id", walletId),
-		zap.Int("count", len(transactions)))
+		zap.Time("since", since),
+		zap.Int("limit", limit))
 
+	transactions, err := d.custody.WalletTransactions(ctx, d.portfolioId, walletId, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s API call failed: %w", d.custody.Name(), err)
+	}
 	return transactions, nil
 }
 
-// isTransactionProcessed checks if we've already processed this transaction
-func (d *SendReceiveListener) isTransactionProcessed(txId string) bool {
-	d.mutex.RLock()
-	defer d.mutex.RUnlock()
+// isTransactionProcessed reports whether the listener has finished with a Prime transaction. The
+// processed_transactions table survives restarts, so a transaction is handled once however often it
+// is fetched again.
+func (d *SendReceiveListener) isTransactionProcessed(ctx context.Context, txId string) bool {
+	processed, err := d.dbService.IsTransactionProcessed(ctx, txId)
+	if err != nil {
+		// Fall through to processing - the ledger's external_transaction_id check prevents double credits
+		correlation.Logger(ctx, d.logger).Warn("Failed to check processed transaction", zap.String("transaction_id", txId), zap.Error(err))
+		return false
+	}
+	return processed
+}
+
+// markTransactionProcessed records that the listener has finished with a Prime transaction. If the
+// listener stops before this, the ledger's external_transaction_id check rejects the second attempt.
+func (d *SendReceiveListener) markTransactionProcessed(ctx context.Context, txId string) {
+	if err := d.dbService.MarkTransactionProcessed(ctx, txId); err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to mark transaction processed", zap.String("transaction_id", txId), zap.Error(err))
+	}
+}
+
+// isStepDone reports whether an idempotent side step for a transaction, such as storing its raw payload,
+// ran within the lookback window. These are kept in the coordination store, which expires them.
+func (d *SendReceiveListener) isStepDone(ctx context.Context, key string) bool {
+	done, err := d.coordinator.IsProcessed(ctx, key)
+	if err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to check transaction step", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return done
+}
+
+// markStepDone records that an idempotent side step for a transaction ran
+func (d *SendReceiveListener) markStepDone(ctx context.Context, key string) {
+	if err := d.coordinator.MarkProcessed(ctx, key, d.lookbackWindow); err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to mark transaction step", zap.String("key", key), zap.Error(err))
+	}
+}
+
+// observe records why a transaction was passed over, so unapplied transactions can be explained
+// without debug logs. A failure is logged and does not stop processing.
+func (d *SendReceiveListener) observe(ctx context.Context, tx models.PrimeTransaction, reason, detail string) {
+	if err := d.dbService.RecordTransactionObservation(ctx, tx, reason, detail); err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to record transaction observation",
+			zap.String("transaction_id", tx.Id),
+			zap.String("reason", reason),
+			zap.Error(err))
+	}
+}
+
+// saveReceipt records the on-chain hash of a completed transaction. It runs before the processed check
+// because deposits credited before completion are already processed when Prime reports them done.
+func (d *SendReceiveListener) saveReceipt(ctx context.Context, tx models.PrimeTransaction) {
+	hash := tx.TxHash()
+	receiptKey := "receipt:" + tx.Id
+	if hash == "" || d.isStepDone(ctx, receiptKey) {
+		return
+	}
 
-	_, exists := d.processedTxIds[txId]
-	return exists
+	err := d.dbService.SaveTransactionReceipt(ctx, models.TransactionReceipt{
+		PrimeTransactionId: tx.Id,
+		IdempotencyKey:     tx.IdempotencyKey,
+		Network:            tx.Network,
+		TxHash:             hash,
+		CompletedAt:        tx.CompletedAt,
+	})
+	if err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to save transaction receipt", zap.String("transaction_id", tx.Id), zap.Error(err))
+		return
+	}
+	d.markStepDone(ctx, receiptKey)
 }
 
-// markTransactionProcessed marks a transaction as processed
-func (d *SendReceiveListener) markTransactionProcessed(txId string) {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
+// saveRawTransaction keeps the payload Prime reported for each status a transaction passes through,
+// so support can inspect exactly what was received
+func (d *SendReceiveListener) saveRawTransaction(ctx context.Context, tx models.PrimeTransaction) {
+	rawKey := "raw:" + tx.Id + ":" + tx.Status
+	if len(tx.Raw) == 0 || d.isStepDone(ctx, rawKey) {
+		return
+	}
 
-	d.processedTxIds[txId] = time.Now()
+	if err := d.dbService.SavePrimeTransaction(ctx, tx); err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to save raw prime transaction", zap.String("transaction_id", tx.Id), zap.Error(err))
+		return
+	}
+	d.markStepDone(ctx, rawKey)
 }
 
-// cleanupLoop periodically cleans old processed transaction IDs
+// cleanupLoop periodically sweeps expired entries from the coordination store
 func (d *SendReceiveListener) cleanupLoop(ctx context.Context) {
 	ticker := time.NewTicker(d.cleanupInterval)
 	defer ticker.Stop()
@@ -249,7 +440,7 @@ func (d *SendReceiveListener) cleanupLoop(ctx context.Context) {
 	for {
 		select {
 		case <-ticker.C:
-			d.cleanupProcessedTransactions()
+			d.sweepCoordination(ctx)
 		case <-d.stopChan:
 			return
 		case <-ctx.Done():
@@ -258,58 +449,95 @@ func (d *SendReceiveListener) cleanupLoop(ctx context.Context) {
 	}
 }
 
-// cleanupProcessedTransactions removes old entries from processed transactions map
-func (d *SendReceiveListener) cleanupProcessedTransactions() {
-	d.mutex.Lock()
-	defer d.mutex.Unlock()
-
-	cutoff := time.Now().UTC().Add(-d.lookbackWindow)
-	cleaned := 0
-
-	for txId, processedTime := range d.processedTxIds {
-		if processedTime.Before(cutoff) {
-			delete(d.processedTxIds, txId)
-			cleaned++
-		}
+// sweepCoordination removes expired step markers, rate limit counters and leases from the coordination store
+func (d *SendReceiveListener) sweepCoordination(ctx context.Context) {
+	cleaned, err := d.coordinator.Sweep(ctx)
+	if err != nil {
+		d.logger.Warn("Failed to sweep coordination store", zap.Error(err))
+		return
 	}
 
 	if cleaned > 0 {
-		zap.L().Debug("Cleaned up old processed transactions",
-			zap.Int("cleaned", cleaned),
-			zap.Int("remaining", len(d.processedTxIds)))
+		d.logger.Debug("Swept expired coordination entries",
+			zap.Int("cleaned", cleaned))
 	}
 }
 
-// findUserByIdempotencyKeyPrefix finds a user whose Id matches the prefix of the idempotency key
-func (d *SendReceiveListener) findUserByIdempotencyKeyPrefix(ctx context.Context, idempotencyKey string) (string, error) {
-	if idempotencyKey == "" {
-		return "", fmt.Errorf("empty idempotency key")
+// acquireWalletLease claims a wallet for this instance so that only one listener polls it at a time
+func (d *SendReceiveListener) acquireWalletLease(ctx context.Context, walletId string) bool {
+	acquired, err := d.coordinator.AcquireLease(ctx, "wallet:"+walletId, d.instanceId, d.walletLeaseTTL)
+	if err != nil {
+		d.logger.Warn("Failed to acquire wallet lease - skipping wallet this cycle",
+			zap.String("wallet_id", walletId),
+			zap.Error(err))
+		return false
 	}
+	if !acquired {
+		d.logger.Debug("Wallet leased by another instance - skipping",
+			zap.String("wallet_id", walletId),
+			zap.String("instance_id", d.instanceId))
+	}
+	return acquired
+}
 
-	// Extract the first UUID segment from idempotency key (before first hyphen)
-	parts := strings.Split(idempotencyKey, "-")
-	if len(parts) == 0 {
-		return "", fmt.Errorf("invalid idempotency key format: %s", idempotencyKey)
+// releaseWalletLeases gives up all wallet leases held by this instance
+func (d *SendReceiveListener) releaseWalletLeases() {
+	ctx := context.Background()
+	for _, wallet := range d.monitoredWallets {
+		if err := d.coordinator.ReleaseLease(ctx, "wallet:"+wallet.Id, d.instanceId); err != nil {
+			d.logger.Warn("Failed to release wallet lease", zap.String("wallet_id", wallet.Id), zap.Error(err))
+		}
 	}
-	idempotencyPrefix := parts[0]
+}
 
-	// Get all users from database
-	users, err := d.dbService.GetUsers(ctx)
-	if err != nil {
-		return "", fmt.Errorf("failed to get users: %w", err)
+// findWithdrawalRequest returns the withdrawal request a Prime withdrawal was submitted under. A withdrawal
+// sent from a different wallet than its request names is not attributed to it.
+func (d *SendReceiveListener) findWithdrawalRequest(ctx context.Context, tx models.PrimeTransaction) (*models.WithdrawalRequestRecord, error) {
+	if tx.IdempotencyKey == "" {
+		return nil, fmt.Errorf("empty idempotency key")
 	}
 
-	// Look for a user whose Id starts with the same prefix
-	for _, user := range users {
-		userParts := strings.Split(user.Id, "-")
-		if len(userParts) > 0 && userParts[0] == idempotencyPrefix {
-			zap.L().Debug("Matched withdrawal to user by UUID prefix",
-				zap.String("user_id", user.Id),
-				zap.String("idempotency_key", idempotencyKey),
-				zap.String("matched_prefix", idempotencyPrefix))
-			return user.Id, nil
-		}
+	request, err := d.dbService.GetWithdrawalRequest(ctx, tx.IdempotencyKey)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, fmt.Errorf("no withdrawal request with idempotency key %s", tx.IdempotencyKey)
 	}
+	if request.WalletId != "" && tx.WalletId != "" && request.WalletId != tx.WalletId {
+		return nil, fmt.Errorf("withdrawal request %s was made from wallet %s, not %s", tx.IdempotencyKey, request.WalletId, tx.WalletId)
+	}
+
+	correlation.Logger(ctx, d.logger).Debug("Matched withdrawal to user by withdrawal request",
+		zap.String("user_id", request.UserId),
+		zap.String("idempotency_key", tx.IdempotencyKey),
+		zap.String("request_status", request.Status))
+	return request, nil
+}
 
-	return "", fmt.Errorf("no user found with UUID prefix matching idempotency key prefix %s: %s", idempotencyPrefix, idempotencyKey)
+// settleWithdrawalRequest links a withdrawal request to its Prime transaction and records the outcome.
+// The ledger is already settled, so a failed update is only logged.
+func (d *SendReceiveListener) settleWithdrawalRequest(ctx context.Context, tx models.PrimeTransaction, status string) {
+	d.advanceWithdrawalRequest(ctx, tx.IdempotencyKey, tx, status)
+}
+
+// trackWithdrawalRequest records that Prime is still processing a withdrawal, and the status it reports
+func (d *SendReceiveListener) trackWithdrawalRequest(ctx context.Context, tx models.PrimeTransaction) {
+	d.advanceWithdrawalRequest(ctx, tx.IdempotencyKey, tx, database.WithdrawalRequestStatusPending)
+}
+
+// advanceWithdrawalRequest moves the request made under idempotencyKey to status, recording the Prime
+// transaction that paid it out. A batched withdrawal's requests are keyed by item, not by tx.
+func (d *SendReceiveListener) advanceWithdrawalRequest(ctx context.Context, idempotencyKey string, tx models.PrimeTransaction, status string) {
+	if idempotencyKey == "" {
+		return
+	}
+	_, err := d.dbService.AdvanceWithdrawalRequest(ctx, idempotencyKey, database.WithdrawalTransition{
+		Status:             status,
+		PrimeStatus:        tx.Status,
+		PrimeTransactionId: tx.Id,
+	})
+	if err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to update withdrawal request", zap.String("transaction_id", tx.Id), zap.Error(err))
+	}
 }
@@ -0,0 +1,45 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap/zaptest"
+)
+
+// testWallet is the one wallet monitored by listeners from newTestListener
+var testWallet = models.WalletInfo{Id: "wallet-eth", AssetSymbol: "ETH"}
+
+// newTestListener returns a listener over dbService that monitors testWallet and has no custody provider
+func newTestListener(t *testing.T, dbService *database.Service) *SendReceiveListener {
+	t.Helper()
+	logger := zaptest.NewLogger(t)
+	d := NewSendReceiveListener(SendReceiveListenerConfig{
+		ApiService:      api.NewLedgerService(dbService, logger),
+		DbService:       dbService,
+		PollingInterval: time.Second,
+		Logger:          logger,
+	})
+	d.monitoredWallets = []models.WalletInfo{testWallet}
+	return d
+}
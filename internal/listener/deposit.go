@@ -18,6 +18,7 @@ package listener
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -25,60 +26,82 @@ import (
 
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
 )
 
 // processDeposit processes a deposit transaction
 func (d *SendReceiveListener) processDeposit(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
-	if tx.Status != "TRANSACTION_IMPORTED" {
-		zap.L().Debug("Skipping non-imported deposit - waiting for completion",
+	asset := d.assetConfig(tx.Symbol, tx.Network)
+	if tx.Status != asset.CreditStatus() {
+		correlation.Logger(ctx, d.logger).Debug("Skipping non-imported deposit - waiting for completion",
 			zap.String("transaction_id", tx.Id),
 			zap.String("status", tx.Status),
+			zap.String("credit_status", asset.CreditStatus()),
 			zap.String("symbol", tx.Symbol),
 			zap.String("amount", tx.Amount),
 			zap.Time("created_at", tx.CreatedAt))
+		d.observe(ctx, tx, models.ObservationStatus, "credited at "+asset.CreditStatus())
 		return nil
 	}
 
-	amount, err := decimal.NewFromString(tx.Amount)
-	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
+	amount := tx.SignedAmount
+	if amount.LessThanOrEqual(decimal.Zero) {
+		correlation.Logger(ctx, d.logger).Debug("Skipping zero/negative amount transaction",
+			zap.String("transaction_id", tx.Id),
+			zap.String("amount", amount.String()))
+		d.observe(ctx, tx, models.ObservationZeroAmount, "")
+		return nil
 	}
 
-	if amount.LessThanOrEqual(decimal.Zero) {
-		zap.L().Debug("Skipping zero/negative amount transaction",
+	if !asset.DepositsEnabled() {
+		// Left unprocessed so it is credited if deposits are re-enabled within the lookback window
+		correlation.Logger(ctx, d.logger).Warn("Deposits disabled for asset in assets.yaml - not crediting",
 			zap.String("transaction_id", tx.Id),
+			zap.String("asset_network", asset.AssetNetwork()),
 			zap.String("amount", amount.String()))
+		d.observe(ctx, tx, models.ObservationDepositsDisabled, asset.AssetNetwork())
+		return nil
+	}
+
+	if amount.LessThan(asset.Listener.DustThreshold) {
+		correlation.Logger(ctx, d.logger).Info("Deposit below dust threshold - marking as processed without crediting",
+			zap.String("transaction_id", tx.Id),
+			zap.String("asset_network", asset.AssetNetwork()),
+			zap.String("amount", amount.String()),
+			zap.String("dust_threshold", asset.Listener.DustThreshold.String()))
+		d.observe(ctx, tx, models.ObservationDust, "threshold "+asset.Listener.DustThreshold.String())
+		d.markTransactionProcessed(ctx, tx.Id)
 		return nil
 	}
 
 	var lookupAddress string
 	if tx.TransferTo.AccountIdentifier != "" {
 		lookupAddress = tx.TransferTo.AccountIdentifier
-		zap.L().Debug("Using account_identifier for address lookup",
+		correlation.Logger(ctx, d.logger).Debug("Using account_identifier for address lookup",
 			zap.String("transaction_id", tx.Id),
 			zap.String("account_identifier", tx.TransferTo.AccountIdentifier),
 			zap.String("address", tx.TransferTo.Address))
 	} else {
 		lookupAddress = tx.TransferTo.Address
-		zap.L().Debug("Using address for lookup",
+		correlation.Logger(ctx, d.logger).Debug("Using address for lookup",
 			zap.String("transaction_id", tx.Id),
 			zap.String("address", tx.TransferTo.Address))
 	}
 
 	if lookupAddress == "" {
-		zap.L().Debug("No address or account_identifier found in transfer_to",
+		correlation.Logger(ctx, d.logger).Debug("No address or account_identifier found in transfer_to - trying counterparty attribution",
 			zap.String("transaction_id", tx.Id),
 			zap.String("transfer_to_type", tx.TransferTo.Type),
 			zap.String("transfer_to_value", tx.TransferTo.Value))
-		return nil
+		return d.processCounterpartyDeposit(ctx, tx, amount, d.depositAvailability(asset, tx))
 	}
 
 	assetNetwork := fmt.Sprintf("%s-%s", tx.Symbol, tx.Network)
 	assetNetwork = strings.TrimSuffix(assetNetwork, "-")
 
-	zap.L().Info("Processing imported deposit",
+	correlation.Logger(ctx, d.logger).Info("Processing imported deposit",
 		zap.String("transaction_id", tx.Id),
 		zap.String("lookup_address", lookupAddress),
 		zap.String("deposit_address", tx.TransferTo.Address),
@@ -92,51 +115,58 @@ func (d *SendReceiveListener) processDeposit(ctx context.Context, tx models.Prim
 
 	// Pass Prime API symbol to ledger - ProcessDeposit will use canonical symbol from address lookup
 	// This handles cases where Prime API returns "BASEUSDC" but we store as "USDC" with network="base-mainnet"
-	result, err := d.apiService.ProcessDeposit(ctx, lookupAddress, tx.Symbol, amount, tx.Id)
+	result, err := d.apiService.ProcessDeposit(ctx, lookupAddress, tx.Symbol, amount, tx.Id, depositSource(tx), d.depositAvailability(asset, tx))
 	if err != nil {
 		if errors.Is(err, database.ErrDuplicateTransaction) {
-			zap.L().Info("Duplicate transaction detected - already processed, marking as handled",
+			correlation.Logger(ctx, d.logger).Info("Duplicate transaction detected - already processed, marking as handled",
 				zap.String("transaction_id", tx.Id))
-			d.markTransactionProcessed(tx.Id)
+			d.markTransactionProcessed(ctx, tx.Id)
 			return nil
 		}
 		if errors.Is(err, database.ErrUserNotFound) {
-			zap.L().Warn("Deposit to unrecognized address - marking as processed to avoid repeated errors",
+			correlation.Logger(ctx, d.logger).Warn("Deposit to unrecognized address - recording for cmd/claims",
 				zap.String("transaction_id", tx.Id),
 				zap.String("address", lookupAddress),
 				zap.String("asset_network", assetNetwork),
 				zap.String("amount", amount.String()))
-			d.markTransactionProcessed(tx.Id)
-			return nil
+			return d.recordUnattributedDeposit(ctx, tx, lookupAddress, amount)
 		}
 		return fmt.Errorf("failed to process deposit: %w", err)
 	}
 
 	if !result.Success {
-		// Check if this is a duplicate transaction error (result.Error is a plain string)
-		if result.Error == database.ErrDuplicateTransaction.Error() {
-			zap.L().Info("Duplicate transaction detected - already processed, marking as handled",
+		if errors.Is(result.Err, database.ErrDuplicateTransaction) {
+			correlation.Logger(ctx, d.logger).Info("Duplicate transaction detected - already processed, marking as handled",
 				zap.String("transaction_id", tx.Id))
-			d.markTransactionProcessed(tx.Id)
+			d.markTransactionProcessed(ctx, tx.Id)
+			return nil
+		}
+		// Mismatched assets are credited to suspense for an operator to resolve
+		if errors.Is(result.Err, database.ErrDepositSuspended) {
+			correlation.Logger(ctx, d.logger).Warn("Deposit held in suspense - resolve with cmd/suspense",
+				zap.String("transaction_id", tx.Id),
+				zap.String("asset_network", assetNetwork),
+				zap.String("amount", amount.String()))
+			d.markTransactionProcessed(ctx, tx.Id)
 			return nil
 		}
 		// Check if this is an unrecognized address
-		if result.Error == database.ErrUserNotFound.Error() {
-			zap.L().Warn("Deposit to unrecognized address - marking as processed to avoid repeated errors",
+		if errors.Is(result.Err, database.ErrUserNotFound) {
+			correlation.Logger(ctx, d.logger).Warn("Deposit to unrecognized address - recording for cmd/claims",
 				zap.String("transaction_id", tx.Id),
+				zap.String("address", lookupAddress),
 				zap.String("error", result.Error))
-			d.markTransactionProcessed(tx.Id)
-			return nil
+			return d.recordUnattributedDeposit(ctx, tx, lookupAddress, amount)
 		}
-		zap.L().Warn("Deposit processing failed",
+		correlation.Logger(ctx, d.logger).Warn("Deposit processing failed",
 			zap.String("transaction_id", tx.Id),
 			zap.String("error", result.Error))
 		return fmt.Errorf("deposit processing failed: %s", result.Error)
 	}
 
-	d.markTransactionProcessed(tx.Id)
+	d.markTransactionProcessed(ctx, tx.Id)
 
-	zap.L().Info("Deposit processed successfully - balance updated",
+	correlation.Logger(ctx, d.logger).Info("Deposit processed successfully - balance updated",
 		zap.String("transaction_id", tx.Id),
 		zap.String("user_id", result.UserId),
 		zap.String("asset", result.Asset),
@@ -146,3 +176,130 @@ func (d *SendReceiveListener) processDeposit(ctx context.Context, tx models.Prim
 
 	return nil
 }
+
+// processCounterpartyDeposit attributes an internal transfer with no deposit address using
+// the match reference or the sender's counterparty mapping
+func (d *SendReceiveListener) processCounterpartyDeposit(ctx context.Context, tx models.PrimeTransaction, amount decimal.Decimal, availability string) error {
+	userId, err := d.dbService.ProcessCounterpartyDeposit(ctx, database.CounterpartyDepositParams{
+		Asset:          normalizeSymbol(tx.Symbol),
+		Amount:         amount,
+		ExternalTxId:   tx.Id,
+		MatchReference: tx.MatchReference,
+		CounterpartyIds: []string{
+			tx.TransferFrom.Value,
+			tx.TransferFrom.AccountIdentifier,
+			tx.TransferFrom.Address,
+		},
+		Source:       depositSource(tx),
+		Availability: availability,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateTransaction) {
+			correlation.Logger(ctx, d.logger).Info("Duplicate transaction detected - already processed, marking as handled",
+				zap.String("transaction_id", tx.Id))
+			d.markTransactionProcessed(ctx, tx.Id)
+			return nil
+		}
+		if errors.Is(err, database.ErrUserNotFound) {
+			// Left unprocessed so it is picked up once a mapping or reference is registered
+			correlation.Logger(ctx, d.logger).Warn("Counterparty deposit could not be attributed - register it with cmd/counterparty",
+				zap.String("transaction_id", tx.Id),
+				zap.String("symbol", tx.Symbol),
+				zap.String("amount", amount.String()),
+				zap.String("transfer_from_type", tx.TransferFrom.Type),
+				zap.String("transfer_from_value", tx.TransferFrom.Value),
+				zap.String("match_reference", tx.MatchReference))
+			return nil
+		}
+		return fmt.Errorf("failed to process counterparty deposit: %w", err)
+	}
+
+	d.markTransactionProcessed(ctx, tx.Id)
+
+	correlation.Logger(ctx, d.logger).Info("Counterparty deposit processed successfully - balance updated",
+		zap.String("transaction_id", tx.Id),
+		zap.String("user_id", userId),
+		zap.String("symbol", tx.Symbol),
+		zap.String("amount", amount.String()),
+		zap.Time("processed_at", time.Now()))
+
+	return nil
+}
+
+// recordUnattributedDeposit keeps a deposit to an unrecognized address for an operator to claim or
+// dismiss. The transaction is only marked processed once it is recorded, so a failure is retried.
+func (d *SendReceiveListener) recordUnattributedDeposit(ctx context.Context, tx models.PrimeTransaction, address string, amount decimal.Decimal) error {
+	details := string(tx.Raw)
+	if details == "" {
+		raw, err := json.Marshal(tx)
+		if err != nil {
+			return fmt.Errorf("failed to encode unattributed deposit: %w", err)
+		}
+		details = string(raw)
+	}
+
+	err := d.dbService.RecordUnattributedDeposit(ctx, database.UnattributedDepositParams{
+		ExternalTxId: tx.Id,
+		Address:      address,
+		Asset:        tx.Symbol,
+		Network:      tx.Network,
+		Amount:       amount,
+		Source:       depositSource(tx),
+		Details:      details,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record unattributed deposit: %w", err)
+	}
+
+	d.markTransactionProcessed(ctx, tx.Id)
+	return nil
+}
+
+// depositSource captures the sending side of a Prime transaction for source-of-funds records
+func depositSource(tx models.PrimeTransaction) models.DepositSource {
+	address := tx.TransferFrom.Address
+	if address == "" {
+		address = tx.TransferFrom.Value
+	}
+	if address == "" {
+		address = tx.TransferFrom.AccountIdentifier
+	}
+	return models.DepositSource{
+		Type:    tx.TransferFrom.Type,
+		Address: address,
+	}
+}
+
+// depositAvailability resolves the asset's funds availability policy for a deposit. Under the "done"
+// policy a deposit credited once Prime already reports it done is available immediately.
+func (d *SendReceiveListener) depositAvailability(asset models.AssetConfig, tx models.PrimeTransaction) string {
+	policy := asset.FundsAvailability(d.fundsAvailability)
+	if policy == models.AvailabilityDone && tx.Status == "TRANSACTION_DONE" {
+		return models.AvailabilityImmediate
+	}
+	return policy
+}
+
+// releaseSettledDeposit makes a deposit credited before TRANSACTION_DONE available once Prime reports it done.
+// Each deposit is checked once per lookback window.
+func (d *SendReceiveListener) releaseSettledDeposit(ctx context.Context, tx models.PrimeTransaction) {
+	key := tx.Id + ":settled"
+	if d.isStepDone(ctx, key) {
+		return
+	}
+
+	released, err := d.dbService.ReleaseSettledDeposit(ctx, tx.Id)
+	if err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to release settled deposit - will retry next poll",
+			zap.String("transaction_id", tx.Id),
+			zap.Error(err))
+		return
+	}
+	if released {
+		correlation.Logger(ctx, d.logger).Info("Deposit reached TRANSACTION_DONE - funds now available",
+			zap.String("transaction_id", tx.Id),
+			zap.String("symbol", tx.Symbol),
+			zap.String("amount", tx.Amount))
+	}
+	d.markStepDone(ctx, key)
+}
@@ -0,0 +1,205 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/notify"
+	"prime-send-receive-go/internal/treasury"
+
+	"go.uber.org/zap"
+)
+
+// DigestJobConfig contains configuration for DigestJob
+type DigestJobConfig struct {
+	DbService *database.Service
+	// Channels each receive the digest; a channel that fails is retried on the next check
+	Channels      map[string]notify.Notifier
+	RunHour       int
+	CheckInterval time.Duration
+	// Reconciliation reports the last reconciliation run when the job runs in the same process
+	Reconciliation *ReconciliationJob
+	// Treasury adds treasury exposure when set; it costs two Prime balance calls per digest
+	Treasury *treasury.Service
+	Logger   *zap.Logger
+}
+
+// DigestJob sends a summary of the previous UTC day to every notification channel once per day
+type DigestJob struct {
+	dbService      *database.Service
+	channels       map[string]notify.Notifier
+	runHour        int
+	checkInterval  time.Duration
+	reconciliation *ReconciliationJob
+	treasury       *treasury.Service
+
+	// lastSent is the last digest day delivered per channel
+	lastSent map[string]string
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewDigestJob creates a new daily notification digest job
+func NewDigestJob(cfg DigestJobConfig) *DigestJob {
+	return &DigestJob{
+		dbService:      cfg.DbService,
+		channels:       cfg.Channels,
+		runHour:        cfg.RunHour,
+		checkInterval:  cfg.CheckInterval,
+		reconciliation: cfg.Reconciliation,
+		treasury:       cfg.Treasury,
+		logger:         cfg.Logger,
+		lastSent:       make(map[string]string),
+		stopChan:       make(chan struct{}),
+		doneChan:       make(chan struct{}),
+	}
+}
+
+// Start begins checking whether the daily digest is due
+func (j *DigestJob) Start(ctx context.Context) {
+	j.logger.Info("Starting notification digest job",
+		zap.Int("channels", len(j.channels)),
+		zap.Int("run_hour_utc", j.runHour),
+		zap.Duration("check_interval", j.checkInterval))
+
+	go j.runLoop(ctx)
+}
+
+// Stop gracefully stops the digest job
+func (j *DigestJob) Stop() {
+	j.logger.Info("Stopping notification digest job")
+	close(j.stopChan)
+	<-j.doneChan
+	j.logger.Info("Notification digest job stopped")
+}
+
+func (j *DigestJob) runLoop(ctx context.Context) {
+	defer close(j.doneChan)
+
+	ticker := time.NewTicker(j.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		j.runIfDue(ctx, time.Now().UTC())
+
+		select {
+		case <-ticker.C:
+		case <-j.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runIfDue sends the previous day's digest to each channel that has not had it yet, once the
+// configured hour has passed. Delivery is tracked in memory, so a restart after the run hour sends
+// that day's digest again.
+func (j *DigestJob) runIfDue(ctx context.Context, now time.Time) {
+	if now.Hour() < j.runHour {
+		return
+	}
+
+	day := now.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	dayKey := day.Format("2006-01-02")
+
+	var pending []string
+	for name := range j.channels {
+		if j.lastSent[name] != dayKey {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	digest, err := j.buildDigest(ctx, day)
+	if err != nil {
+		j.logger.Error("Failed to build notification digest - will retry on next check",
+			zap.String("day", dayKey),
+			zap.Error(err))
+		return
+	}
+	message := digest.Message()
+
+	for _, name := range pending {
+		if err := j.channels[name].Notify(ctx, message); err != nil {
+			j.logger.Error("Failed to send notification digest - will retry on next check",
+				zap.String("channel", name),
+				zap.String("day", dayKey),
+				zap.Error(err))
+			continue
+		}
+		j.lastSent[name] = dayKey
+		j.logger.Info("Notification digest sent", zap.String("channel", name), zap.String("day", dayKey))
+	}
+}
+
+// buildDigest gathers the day's activity and the current failure, reconciliation and treasury state
+func (j *DigestJob) buildDigest(ctx context.Context, day time.Time) (*notify.Digest, error) {
+	activity, err := j.dbService.SummarizeActivity(ctx, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	negative, err := j.dbService.ListNegativeBalances(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &notify.Digest{
+		Date:             day,
+		Activity:         activity,
+		NegativeBalances: len(negative),
+	}
+
+	if j.reconciliation != nil {
+		if result := j.reconciliation.LastResult(); !result.CompletedAt.IsZero() {
+			digest.Reconciliation = &notify.DigestReconciliation{
+				CompletedAt: result.CompletedAt,
+				Checked:     result.Checked,
+				Mismatches:  result.Mismatches,
+			}
+		}
+	}
+
+	if j.treasury != nil {
+		positions, err := j.treasury.Positions(ctx)
+		if err != nil {
+			// The rest of the digest is still worth sending
+			digest.ExposureError = err.Error()
+		}
+		for _, position := range positions {
+			digest.Exposure = append(digest.Exposure, notify.DigestExposure{
+				Asset:          position.Asset,
+				HotBalance:     position.HotBalance,
+				Liabilities:    position.Liabilities,
+				NetExposure:    position.NetExposure,
+				Recommendation: position.Recommendation,
+			})
+		}
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,134 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// InterestJobConfig contains configuration for InterestJob
+type InterestJobConfig struct {
+	DbService     *database.Service
+	Rates         map[string]decimal.Decimal
+	MinBalance    decimal.Decimal
+	RunHour       int
+	CheckInterval time.Duration
+	Logger        *zap.Logger
+}
+
+// InterestJob accrues one day of interest for the previous UTC day once per day
+type InterestJob struct {
+	dbService     *database.Service
+	rates         map[string]decimal.Decimal
+	minBalance    decimal.Decimal
+	runHour       int
+	checkInterval time.Duration
+
+	lastAccrued string
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewInterestJob creates a new daily interest accrual job
+func NewInterestJob(cfg InterestJobConfig) *InterestJob {
+	return &InterestJob{
+		dbService:     cfg.DbService,
+		rates:         cfg.Rates,
+		minBalance:    cfg.MinBalance,
+		runHour:       cfg.RunHour,
+		checkInterval: cfg.CheckInterval,
+		logger:        cfg.Logger,
+		stopChan:      make(chan struct{}),
+		doneChan:      make(chan struct{}),
+	}
+}
+
+// Start begins checking whether the daily accrual is due
+func (j *InterestJob) Start(ctx context.Context) {
+	j.logger.Info("Starting interest accrual job",
+		zap.Int("rated_assets", len(j.rates)),
+		zap.Int("run_hour_utc", j.runHour),
+		zap.Duration("check_interval", j.checkInterval))
+
+	go j.runLoop(ctx)
+}
+
+// Stop gracefully stops the interest job
+func (j *InterestJob) Stop() {
+	j.logger.Info("Stopping interest accrual job")
+	close(j.stopChan)
+	<-j.doneChan
+	j.logger.Info("Interest accrual job stopped")
+}
+
+func (j *InterestJob) runLoop(ctx context.Context) {
+	defer close(j.doneChan)
+
+	ticker := time.NewTicker(j.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		j.runIfDue(ctx, time.Now().UTC())
+
+		select {
+		case <-ticker.C:
+		case <-j.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runIfDue accrues interest for the previous day once the configured hour has passed.
+// Accruals are idempotent in the database, so a restart at worst repeats a no-op run.
+func (j *InterestJob) runIfDue(ctx context.Context, now time.Time) {
+	if now.Hour() < j.runHour {
+		return
+	}
+
+	accrualDate := now.AddDate(0, 0, -1)
+	dateKey := accrualDate.Format(database.InterestDateLayout)
+	if dateKey == j.lastAccrued {
+		return
+	}
+
+	accruals, err := j.dbService.AccrueInterest(ctx, database.AccrueInterestParams{
+		Date:       accrualDate,
+		Rates:      j.rates,
+		MinBalance: j.minBalance,
+	})
+	if err != nil {
+		j.logger.Error("Interest accrual failed - will retry on next check",
+			zap.String("accrual_date", dateKey),
+			zap.Int("accrued_before_failure", len(accruals)),
+			zap.Error(err))
+		return
+	}
+
+	j.lastAccrued = dateKey
+}
@@ -0,0 +1,153 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/notify"
+
+	"go.uber.org/zap"
+)
+
+// invariantBatchSize is how many transactions the invariant monitor reads per query
+const invariantBatchSize = 1000
+
+// InvariantJob checks ledger invariants on every transaction as it is written and alerts on the first
+// violation. The first run checks the whole history; later runs only read newer transactions.
+type InvariantJob struct {
+	dbService  *database.Service
+	notifier   notify.Notifier
+	interval   time.Duration
+	invariants *database.LedgerInvariants
+
+	// sequence is the last transaction checked
+	sequence int64
+	alerted  bool
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewInvariantJob creates a new ledger invariant monitor. notifier may be nil, in which case violations
+// are only logged.
+func NewInvariantJob(dbService *database.Service, notifier notify.Notifier, interval time.Duration, logger *zap.Logger) *InvariantJob {
+	return &InvariantJob{
+		dbService:  dbService,
+		notifier:   notifier,
+		interval:   interval,
+		invariants: database.NewLedgerInvariants(dbService.ChartOfAccounts()),
+		logger:     logger,
+		stopChan:   make(chan struct{}),
+		doneChan:   make(chan struct{}),
+	}
+}
+
+// Start begins checking new transactions on the configured interval
+func (j *InvariantJob) Start(ctx context.Context) {
+	j.logger.Info("Starting ledger invariant monitor", zap.Duration("interval", j.interval))
+	go j.runLoop(ctx)
+}
+
+// Stop gracefully stops the invariant monitor
+func (j *InvariantJob) Stop() {
+	j.logger.Info("Stopping ledger invariant monitor")
+	close(j.stopChan)
+	<-j.doneChan
+	j.logger.Info("Ledger invariant monitor stopped")
+}
+
+func (j *InvariantJob) runLoop(ctx context.Context) {
+	defer close(j.doneChan)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.run(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-j.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// run checks every transaction written since the last run
+func (j *InvariantJob) run(ctx context.Context) {
+	for {
+		entries, err := j.dbService.ListLedgerEntriesAfter(ctx, j.sequence, invariantBatchSize)
+		if err != nil {
+			j.logger.Error("Failed to read transactions for invariant check - will retry on next run", zap.Error(err))
+			return
+		}
+		if len(entries) == 0 {
+			return
+		}
+
+		violations := j.invariants.Add(entries)
+		j.sequence = entries[len(entries)-1].Sequence
+		for _, violation := range violations {
+			j.logger.Error("Ledger invariant violated",
+				zap.String("invariant", violation.Invariant),
+				zap.String("asset", violation.Asset),
+				zap.String("transaction_id", violation.TransactionId),
+				zap.String("detail", violation.Detail))
+		}
+		if len(violations) > 0 && !j.alerted {
+			j.alert(ctx, violations)
+		}
+
+		if len(entries) < invariantBatchSize {
+			return
+		}
+	}
+}
+
+// alert notifies operators of the first violations found. Later violations are only logged, since
+// the ledger needs investigating either way.
+func (j *InvariantJob) alert(ctx context.Context, violations []models.InvariantViolation) {
+	if j.notifier == nil {
+		j.alerted = true
+		return
+	}
+
+	var body strings.Builder
+	body.WriteString("The ledger invariant monitor found:\n\n")
+	for _, violation := range violations {
+		fmt.Fprintf(&body, "- %s (%s, transaction %s): %s\n", violation.Invariant, violation.Asset, violation.TransactionId, violation.Detail)
+	}
+	body.WriteString("\nFurther violations are logged but not emailed until the service restarts.\n")
+
+	message := notify.Message{Subject: "Ledger invariant violated", Body: body.String()}
+	if err := j.notifier.Notify(ctx, message); err != nil {
+		j.logger.Error("Failed to send invariant violation alert - will retry with the next violation", zap.Error(err))
+		return
+	}
+	j.alerted = true
+}
@@ -0,0 +1,107 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// MaintenanceJob periodically purges expired rows, then checkpoints, analyzes, checks and, when
+// fragmented, vacuums the database
+type MaintenanceJob struct {
+	dbService       *database.Service
+	interval        time.Duration
+	vacuumFreeRatio decimal.Decimal
+	retention       map[string]time.Duration
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewMaintenanceJob creates a new database maintenance job
+func NewMaintenanceJob(dbService *database.Service, interval time.Duration, vacuumFreeRatio decimal.Decimal, retention map[string]time.Duration, logger *zap.Logger) *MaintenanceJob {
+	return &MaintenanceJob{
+		dbService:       dbService,
+		interval:        interval,
+		vacuumFreeRatio: vacuumFreeRatio,
+		retention:       retention,
+		logger:          logger,
+		stopChan:        make(chan struct{}),
+		doneChan:        make(chan struct{}),
+	}
+}
+
+// Start begins running maintenance on the configured interval. The first run waits one interval so
+// that a restart loop does not vacuum repeatedly.
+func (j *MaintenanceJob) Start(ctx context.Context) {
+	j.logger.Info("Starting database maintenance job",
+		zap.Duration("interval", j.interval),
+		zap.String("vacuum_free_ratio", j.vacuumFreeRatio.String()))
+	go j.runLoop(ctx)
+}
+
+// Stop gracefully stops the maintenance job, waiting for a run in progress to finish
+func (j *MaintenanceJob) Stop() {
+	j.logger.Info("Stopping database maintenance job")
+	close(j.stopChan)
+	<-j.doneChan
+	j.logger.Info("Database maintenance job stopped")
+}
+
+func (j *MaintenanceJob) runLoop(ctx context.Context) {
+	defer close(j.doneChan)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			j.run(ctx)
+		case <-j.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (j *MaintenanceJob) run(ctx context.Context) {
+	report, err := j.dbService.RunMaintenance(ctx, database.MaintenanceOptions{
+		CheckpointMode:  database.CheckpointPassive,
+		VacuumFreeRatio: j.vacuumFreeRatio,
+		Retention:       j.retention,
+	})
+	if err != nil {
+		j.logger.Error("Database maintenance failed - will retry on next run", zap.Error(err))
+		return
+	}
+
+	if len(report.IntegrityProblems) > 0 {
+		j.logger.Error("Database integrity check found problems - VACUUM skipped, restore from backup or run cmd/maintenance to investigate",
+			zap.Strings("problems", report.IntegrityProblems))
+	}
+}
@@ -0,0 +1,154 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// walletTransaction pairs a Prime transaction with the monitored wallet it was fetched from
+type walletTransaction struct {
+	tx     models.PrimeTransaction
+	wallet models.WalletInfo
+}
+
+// processInOrder applies transactions so that those affecting the same user/asset run serially
+// in created_at order, while unrelated users are processed concurrently. It returns the number
+// of transactions processed without error and the Ids of those that failed.
+func (d *SendReceiveListener) processInOrder(ctx context.Context, transactions []walletTransaction) (int, map[string]bool) {
+	pending := make([]walletTransaction, 0, len(transactions))
+	for _, wt := range transactions {
+		if !d.isTransactionProcessed(ctx, wt.tx.Id) {
+			pending = append(pending, wt)
+		}
+	}
+
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].tx.CreatedAt.Before(pending[j].tx.CreatedAt)
+	})
+
+	// Group by ordering key, preserving created_at order within each group
+	groups := make(map[string][]walletTransaction)
+	var keys []string
+	for _, wt := range pending {
+		key := d.orderingKey(ctx, wt.tx)
+		if _, exists := groups[key]; !exists {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], wt)
+	}
+
+	d.logger.Debug("Dispatching transactions by ordering key",
+		zap.Int("transaction_count", len(pending)),
+		zap.Int("key_count", len(keys)),
+		zap.Int("max_concurrency", d.maxConcurrency))
+
+	var processed int64
+	var failedMu sync.Mutex
+	failed := make(map[string]bool)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, d.maxConcurrency)
+
+	for _, key := range keys {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(key string, group []walletTransaction) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			for i, wt := range group {
+				if ctx.Err() != nil {
+					// Transactions left unapplied stay open in the persisted cursor
+					failedMu.Lock()
+					for _, rest := range group[i:] {
+						failed[rest.tx.Id] = true
+					}
+					failedMu.Unlock()
+					return
+				}
+
+				// Each transaction gets its own correlation id for the log lines of its processing
+				txCtx, _ := correlation.Ensure(ctx)
+				correlation.Logger(txCtx, d.logger).Info("Processing transaction",
+					zap.String("ordering_key", key),
+					zap.String("transaction_id", wt.tx.Id),
+					zap.String("type", wt.tx.Type),
+					zap.String("status", wt.tx.Status),
+					zap.String("symbol", wt.tx.Symbol),
+					zap.String("amount", wt.tx.Amount),
+					zap.Time("created_at", wt.tx.CreatedAt))
+
+				if err := d.process(txCtx, wt.tx, wt.wallet); err != nil {
+					correlation.Logger(txCtx, d.logger).Error("Failed to process transaction",
+						zap.String("transaction_id", wt.tx.Id),
+						zap.String("wallet_id", wt.wallet.Id),
+						zap.Error(err))
+					failedMu.Lock()
+					failed[wt.tx.Id] = true
+					failedMu.Unlock()
+					continue
+				}
+				atomic.AddInt64(&processed, 1)
+			}
+		}(key, groups[key])
+	}
+
+	wg.Wait()
+	return int(processed), failed
+}
+
+// orderingKey returns the user/asset key a transaction is serialized on. Transactions that
+// cannot be attributed to a user get their own key, since they cannot affect a shared balance.
+func (d *SendReceiveListener) orderingKey(ctx context.Context, tx models.PrimeTransaction) string {
+	if !d.transactionTypes[tx.Type] {
+		return "tx:" + tx.Id
+	}
+	switch models.TransactionDirection(tx.Type) {
+	case models.DirectionInbound:
+		lookupAddress := tx.TransferTo.AccountIdentifier
+		if lookupAddress == "" {
+			lookupAddress = tx.TransferTo.Address
+		}
+		if lookupAddress == "" {
+			break
+		}
+		user, addr, err := d.dbService.FindUserByAddress(ctx, lookupAddress)
+		if err == nil && user != nil {
+			return fmt.Sprintf("%s:%s", user.Id, addr.Asset)
+		}
+	case models.DirectionOutbound:
+		request, err := d.findWithdrawalRequest(ctx, tx)
+		if err == nil {
+			return fmt.Sprintf("%s:%s", request.UserId, normalizeSymbol(tx.Symbol))
+		}
+	}
+	return "tx:" + tx.Id
+}
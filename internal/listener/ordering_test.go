@@ -0,0 +1,185 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/database/dbtest"
+	"prime-send-receive-go/internal/models"
+)
+
+// newOrderingListener returns a listener whose users user1 and user2 own the ETH deposit addresses
+// 0xuser1 and 0xuser2
+func newOrderingListener(t *testing.T, maxConcurrency int) *SendReceiveListener {
+	t.Helper()
+	dbService := dbtest.Open(t)
+	for _, userId := range []string{"user1", "user2"} {
+		dbtest.CreateUser(t, dbService, userId, userId, userId+"@example.com")
+		dbtest.StoreAddress(t, dbService, database.StoreAddressParams{
+			UserId: userId, Asset: "ETH", Network: "ethereum-mainnet", Address: "0x" + userId, WalletId: testWallet.Id,
+		})
+	}
+	d := newTestListener(t, dbService)
+	d.maxConcurrency = maxConcurrency
+	return d
+}
+
+// deposit is a completed deposit to address, created offset after a fixed base time
+func deposit(id, address string, offset time.Duration) walletTransaction {
+	return walletTransaction{
+		tx: models.PrimeTransaction{
+			Id: id, WalletId: testWallet.Id, Type: "DEPOSIT", Status: "TRANSACTION_DONE", Symbol: "ETH", Amount: "1",
+			CreatedAt:  time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC).Add(offset),
+			TransferTo: models.PrimeTransferInfo{Address: address},
+		},
+		wallet: testWallet,
+	}
+}
+
+func TestProcessInOrder_SerialPerUserAssetConcurrentAcrossUsers(t *testing.T) {
+	d := newOrderingListener(t, 2)
+
+	var mu sync.Mutex
+	order := make(map[string][]string)
+	inFlight := make(map[string]int)
+	var violations []string
+	user2Started := make(chan struct{})
+	var user2Once sync.Once
+
+	d.process = func(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
+		key := tx.TransferTo.Address
+		mu.Lock()
+		inFlight[key]++
+		if inFlight[key] > 1 {
+			violations = append(violations, fmt.Sprintf("%s overlapped another %s transaction", tx.Id, key))
+		}
+		order[key] = append(order[key], tx.Id)
+		mu.Unlock()
+
+		if key == "0xuser2" {
+			user2Once.Do(func() { close(user2Started) })
+		} else if tx.Id == "u1-a" {
+			// user1's first transaction only finishes once user2's group is running alongside it
+			select {
+			case <-user2Started:
+			case <-time.After(5 * time.Second):
+				mu.Lock()
+				violations = append(violations, "user2 was not processed while user1 was")
+				mu.Unlock()
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[key]--
+		mu.Unlock()
+		return nil
+	}
+
+	// Fetched out of created_at order, as polls of several wallets return them
+	processed, failed := d.processInOrder(context.Background(), []walletTransaction{
+		deposit("u1-c", "0xuser1", 3*time.Minute),
+		deposit("u2-a", "0xuser2", 2*time.Minute),
+		deposit("u1-a", "0xuser1", time.Minute),
+		deposit("u1-b", "0xuser1", 2*time.Minute),
+	})
+
+	if processed != 4 || len(failed) != 0 {
+		t.Errorf("Expected 4 processed and none failed, got %d and %v", processed, failed)
+	}
+	for _, violation := range violations {
+		t.Error(violation)
+	}
+	if want := []string{"u1-a", "u1-b", "u1-c"}; !reflect.DeepEqual(order["0xuser1"], want) {
+		t.Errorf("Expected user1 in created_at order %v, got %v", want, order["0xuser1"])
+	}
+}
+
+func TestProcessInOrder_CancelledContextFailsRestOfGroup(t *testing.T) {
+	d := newOrderingListener(t, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var applied []string
+	d.process = func(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
+		applied = append(applied, tx.Id)
+		cancel()
+		return nil
+	}
+
+	processed, failed := d.processInOrder(ctx, []walletTransaction{
+		deposit("u1-a", "0xuser1", time.Minute),
+		deposit("u1-b", "0xuser1", 2*time.Minute),
+		deposit("u1-c", "0xuser1", 3*time.Minute),
+	})
+
+	if processed != 1 || !reflect.DeepEqual(applied, []string{"u1-a"}) {
+		t.Errorf("Expected only u1-a to be applied, got %d applied: %v", processed, applied)
+	}
+	if want := map[string]bool{"u1-b": true, "u1-c": true}; !reflect.DeepEqual(failed, want) {
+		t.Errorf("Expected %v to be left failed, got %v", want, failed)
+	}
+}
+
+func TestProcessInOrder_FailedTransactionDoesNotStopGroup(t *testing.T) {
+	d := newOrderingListener(t, 1)
+
+	d.process = func(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
+		if tx.Id == "u1-a" {
+			return fmt.Errorf("ledger unavailable")
+		}
+		return nil
+	}
+
+	processed, failed := d.processInOrder(context.Background(), []walletTransaction{
+		deposit("u1-a", "0xuser1", time.Minute),
+		deposit("u1-b", "0xuser1", 2*time.Minute),
+	})
+
+	if processed != 1 || !reflect.DeepEqual(failed, map[string]bool{"u1-a": true}) {
+		t.Errorf("Expected u1-b processed and u1-a failed, got %d and %v", processed, failed)
+	}
+}
+
+func TestOrderingKey(t *testing.T) {
+	d := newOrderingListener(t, 1)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		tx   models.PrimeTransaction
+		want string
+	}{
+		{"deposit to a user's address", deposit("dep-1", "0xuser1", 0).tx, "user1:ETH"},
+		{"deposit to an unknown address", deposit("dep-2", "0xunknown", 0).tx, "tx:dep-2"},
+		{"withdrawal without a request", models.PrimeTransaction{Id: "wd-1", Type: "WITHDRAWAL", IdempotencyKey: "missing"}, "tx:wd-1"},
+		{"type not applied", models.PrimeTransaction{Id: "reward-1", Type: "REWARD"}, "tx:reward-1"},
+	}
+
+	for _, tt := range tests {
+		if got := d.orderingKey(ctx, tt.tx); got != tt.want {
+			t.Errorf("%s: expected key %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// incrementalOverlap is how far an incremental poll reaches back before the previous one, so that
+// transactions which show up in Prime shortly after their created_at are not missed
+const incrementalOverlap = time.Minute
+
+// terminalStatuses are the Prime statuses after which a transaction no longer changes
+var terminalStatuses = map[string]bool{
+	"TRANSACTION_DONE":      true,
+	"TRANSACTION_FAILED":    true,
+	"TRANSACTION_REJECTED":  true,
+	"TRANSACTION_CANCELLED": true,
+	"TRANSACTION_EXPIRED":   true,
+}
+
+// walletPollState remembers what a wallet's earlier polls saw, so that most polls only ask Prime for
+// transactions created since the previous poll and idle wallets can be polled less often
+type walletPollState struct {
+	// lastAttempt is when the wallet was last polled; cursor is when it was last polled successfully
+	lastAttempt  time.Time
+	cursor       time.Time
+	lastFullScan time.Time
+	// oldestOpen is the created_at of the oldest transaction not yet in a terminal status or that failed
+	// to apply; incremental polls reach back to it so it is seen again
+	oldestOpen time.Time
+	// lastSeenTime and lastSeenId identify the newest transaction fetched, persisted so a restart resumes there
+	lastSeenTime time.Time
+	lastSeenId   string
+	// interval is the wallet's current polling interval, lengthened while the wallet is idle
+	interval time.Duration
+	// seen holds the last status seen for each transaction, to tell activity from repeats
+	seen map[string]seenTransaction
+}
+
+type seenTransaction struct {
+	status    string
+	createdAt time.Time
+}
+
+// window returns where the next poll starts and whether it is a full scan of the lookback window.
+// A full scan is due every fullScanInterval; a zero interval makes every poll a full scan.
+func (s *walletPollState) window(now time.Time, lookback, fullScanInterval time.Duration) (time.Time, bool) {
+	earliest := now.Add(-lookback)
+	if fullScanInterval <= 0 || s.lastFullScan.IsZero() || now.Sub(s.lastFullScan) >= fullScanInterval {
+		return earliest, true
+	}
+
+	since := s.cursor.Add(-incrementalOverlap)
+	if !s.oldestOpen.IsZero() && s.oldestOpen.Before(since) {
+		since = s.oldestOpen
+	}
+	if since.Before(earliest) {
+		since = earliest
+	}
+	return since, false
+}
+
+// record updates the state with a successful poll and reports whether it found new transactions or
+// status changes. An incremental poll that filled its page may have missed older transactions, so
+// the next poll is a full scan. Transactions in failed are kept open so later polls retry them.
+func (s *walletPollState) record(transactions []models.PrimeTransaction, failed map[string]bool, now time.Time, full, truncated bool, lookback time.Duration) bool {
+	s.cursor = now
+	if full {
+		s.lastFullScan = now
+	} else if truncated {
+		s.lastFullScan = time.Time{}
+	}
+
+	if s.seen == nil {
+		s.seen = make(map[string]seenTransaction)
+	}
+	if full {
+		earliest := now.Add(-lookback)
+		for id, tx := range s.seen {
+			if tx.createdAt.Before(earliest) {
+				delete(s.seen, id)
+			}
+		}
+	}
+
+	active := false
+	s.oldestOpen = time.Time{}
+	for _, tx := range transactions {
+		if previous, ok := s.seen[tx.Id]; !ok || previous.status != tx.Status {
+			active = true
+		}
+		s.seen[tx.Id] = seenTransaction{status: tx.Status, createdAt: tx.CreatedAt}
+
+		open := !terminalStatuses[tx.Status] || failed[tx.Id]
+		if open && (s.oldestOpen.IsZero() || tx.CreatedAt.Before(s.oldestOpen)) {
+			s.oldestOpen = tx.CreatedAt
+		}
+		if tx.CreatedAt.After(s.lastSeenTime) || (tx.CreatedAt.Equal(s.lastSeenTime) && tx.Id > s.lastSeenId) {
+			s.lastSeenTime = tx.CreatedAt
+			s.lastSeenId = tx.Id
+		}
+	}
+	return active
+}
+
+// resume seeds the state with the cursor a previous run persisted
+func (s *walletPollState) resume(checkpoint models.ListenerCheckpoint) {
+	s.lastSeenTime = checkpoint.LastSeenTxTime
+	s.lastSeenId = checkpoint.LastSeenTxId
+}
+
+// checkpoint returns what is persisted so the next run resumes from this state
+func (s *walletPollState) checkpoint(walletId string, polledThrough time.Time) models.ListenerCheckpoint {
+	return models.ListenerCheckpoint{
+		WalletId:         walletId,
+		PolledThrough:    polledThrough,
+		LastSeenTxTime:   s.lastSeenTime,
+		LastSeenTxId:     s.lastSeenId,
+		OldestOpenTxTime: s.oldestOpen,
+	}
+}
+
+// adapt sets the wallet's next polling interval. A wallet with activity or open transactions is
+// polled at its base interval; each idle poll doubles the interval, up to idleMax.
+func (s *walletPollState) adapt(active bool, base, idleMax time.Duration) {
+	if active || !s.oldestOpen.IsZero() || idleMax <= base || s.interval < base {
+		s.interval = base
+		return
+	}
+	s.interval *= 2
+	if s.interval > idleMax {
+		s.interval = idleMax
+	}
+}
@@ -0,0 +1,269 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+var pollNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestWalletPollState_Window(t *testing.T) {
+	const lookback = 6 * time.Hour
+	const fullScanInterval = time.Hour
+
+	tests := []struct {
+		name             string
+		state            walletPollState
+		fullScanInterval time.Duration
+		wantSince        time.Time
+		wantFull         bool
+	}{
+		{
+			name:             "full scans disabled",
+			state:            walletPollState{cursor: pollNow.Add(-time.Minute), lastFullScan: pollNow.Add(-time.Minute)},
+			fullScanInterval: 0,
+			wantSince:        pollNow.Add(-lookback),
+			wantFull:         true,
+		},
+		{
+			name:             "first poll is a full scan",
+			state:            walletPollState{},
+			fullScanInterval: fullScanInterval,
+			wantSince:        pollNow.Add(-lookback),
+			wantFull:         true,
+		},
+		{
+			name:             "full scan due",
+			state:            walletPollState{cursor: pollNow.Add(-time.Minute), lastFullScan: pollNow.Add(-fullScanInterval)},
+			fullScanInterval: fullScanInterval,
+			wantSince:        pollNow.Add(-lookback),
+			wantFull:         true,
+		},
+		{
+			name:             "incremental poll overlaps the previous one",
+			state:            walletPollState{cursor: pollNow.Add(-30 * time.Second), lastFullScan: pollNow.Add(-10 * time.Minute)},
+			fullScanInterval: fullScanInterval,
+			wantSince:        pollNow.Add(-30 * time.Second).Add(-incrementalOverlap),
+			wantFull:         false,
+		},
+		{
+			name: "incremental poll reaches back to the oldest open transaction",
+			state: walletPollState{cursor: pollNow.Add(-30 * time.Second), lastFullScan: pollNow.Add(-10 * time.Minute),
+				oldestOpen: pollNow.Add(-2 * time.Hour)},
+			fullScanInterval: fullScanInterval,
+			wantSince:        pollNow.Add(-2 * time.Hour),
+			wantFull:         false,
+		},
+		{
+			name: "open transaction newer than the overlap does not narrow the window",
+			state: walletPollState{cursor: pollNow.Add(-30 * time.Second), lastFullScan: pollNow.Add(-10 * time.Minute),
+				oldestOpen: pollNow.Add(-10 * time.Second)},
+			fullScanInterval: fullScanInterval,
+			wantSince:        pollNow.Add(-30 * time.Second).Add(-incrementalOverlap),
+			wantFull:         false,
+		},
+		{
+			name: "reach-back is capped at the lookback window",
+			state: walletPollState{cursor: pollNow.Add(-30 * time.Second), lastFullScan: pollNow.Add(-10 * time.Minute),
+				oldestOpen: pollNow.Add(-48 * time.Hour)},
+			fullScanInterval: fullScanInterval,
+			wantSince:        pollNow.Add(-lookback),
+			wantFull:         false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			since, full := tt.state.window(pollNow, lookback, tt.fullScanInterval)
+			if !since.Equal(tt.wantSince) || full != tt.wantFull {
+				t.Errorf("Expected since %s full %v, got %s full %v", tt.wantSince, tt.wantFull, since, full)
+			}
+		})
+	}
+}
+
+func TestWalletPollState_Record(t *testing.T) {
+	const lookback = 6 * time.Hour
+	older := pollNow.Add(-2 * time.Hour)
+	newer := pollNow.Add(-time.Hour)
+	previousFullScan := pollNow.Add(-10 * time.Minute)
+
+	tests := []struct {
+		name             string
+		seen             map[string]seenTransaction
+		transactions     []models.PrimeTransaction
+		failed           map[string]bool
+		full, truncated  bool
+		wantActive       bool
+		wantOldestOpen   time.Time
+		wantLastFullScan time.Time
+		wantLastSeenId   string
+	}{
+		{
+			name: "new transactions are activity",
+			transactions: []models.PrimeTransaction{
+				{Id: "tx-a", Status: "TRANSACTION_DONE", CreatedAt: older},
+				{Id: "tx-b", Status: "TRANSACTION_DONE", CreatedAt: newer},
+			},
+			wantActive:       true,
+			wantLastFullScan: previousFullScan,
+			wantLastSeenId:   "tx-b",
+		},
+		{
+			name: "repeats are not activity",
+			seen: map[string]seenTransaction{"tx-a": {status: "TRANSACTION_DONE", createdAt: older}},
+			transactions: []models.PrimeTransaction{
+				{Id: "tx-a", Status: "TRANSACTION_DONE", CreatedAt: older},
+			},
+			wantActive:       false,
+			wantLastFullScan: previousFullScan,
+			wantLastSeenId:   "tx-a",
+		},
+		{
+			name: "status changes are activity",
+			seen: map[string]seenTransaction{"tx-a": {status: "TRANSACTION_PROCESSING", createdAt: older}},
+			transactions: []models.PrimeTransaction{
+				{Id: "tx-a", Status: "TRANSACTION_DONE", CreatedAt: older},
+			},
+			wantActive:       true,
+			wantLastFullScan: previousFullScan,
+			wantLastSeenId:   "tx-a",
+		},
+		{
+			name: "oldest non-terminal transaction stays open",
+			transactions: []models.PrimeTransaction{
+				{Id: "tx-a", Status: "TRANSACTION_PROCESSING", CreatedAt: newer},
+				{Id: "tx-b", Status: "TRANSACTION_BROADCASTING", CreatedAt: older},
+				{Id: "tx-c", Status: "TRANSACTION_DONE", CreatedAt: pollNow.Add(-3 * time.Hour)},
+			},
+			wantActive:       true,
+			wantOldestOpen:   older,
+			wantLastFullScan: previousFullScan,
+			wantLastSeenId:   "tx-a",
+		},
+		{
+			name: "terminal transaction that failed to apply stays open",
+			transactions: []models.PrimeTransaction{
+				{Id: "tx-a", Status: "TRANSACTION_DONE", CreatedAt: older},
+			},
+			failed:           map[string]bool{"tx-a": true},
+			wantActive:       true,
+			wantOldestOpen:   older,
+			wantLastFullScan: previousFullScan,
+			wantLastSeenId:   "tx-a",
+		},
+		{
+			name:             "full scan is recorded",
+			full:             true,
+			wantLastFullScan: pollNow,
+		},
+		{
+			name:             "truncated incremental poll forces a full scan",
+			truncated:        true,
+			wantLastFullScan: time.Time{},
+		},
+		{
+			name: "same created_at orders by id",
+			transactions: []models.PrimeTransaction{
+				{Id: "tx-b", Status: "TRANSACTION_DONE", CreatedAt: newer},
+				{Id: "tx-a", Status: "TRANSACTION_DONE", CreatedAt: newer},
+			},
+			wantActive:       true,
+			wantLastFullScan: previousFullScan,
+			wantLastSeenId:   "tx-b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := walletPollState{lastFullScan: previousFullScan, oldestOpen: pollNow.Add(-5 * time.Hour), seen: tt.seen}
+			active := state.record(tt.transactions, tt.failed, pollNow, tt.full, tt.truncated, lookback)
+
+			if active != tt.wantActive {
+				t.Errorf("Expected active %v, got %v", tt.wantActive, active)
+			}
+			if !state.cursor.Equal(pollNow) {
+				t.Errorf("Expected cursor %s, got %s", pollNow, state.cursor)
+			}
+			if !state.oldestOpen.Equal(tt.wantOldestOpen) {
+				t.Errorf("Expected oldest open %s, got %s", tt.wantOldestOpen, state.oldestOpen)
+			}
+			if !state.lastFullScan.Equal(tt.wantLastFullScan) {
+				t.Errorf("Expected last full scan %s, got %s", tt.wantLastFullScan, state.lastFullScan)
+			}
+			if state.lastSeenId != tt.wantLastSeenId {
+				t.Errorf("Expected last seen %q, got %q", tt.wantLastSeenId, state.lastSeenId)
+			}
+		})
+	}
+}
+
+func TestWalletPollState_RecordFullScanForgetsExpiredTransactions(t *testing.T) {
+	const lookback = 6 * time.Hour
+	state := walletPollState{seen: map[string]seenTransaction{
+		"expired": {status: "TRANSACTION_DONE", createdAt: pollNow.Add(-7 * time.Hour)},
+		"recent":  {status: "TRANSACTION_DONE", createdAt: pollNow.Add(-time.Hour)},
+	}}
+
+	state.record(nil, nil, pollNow, false, false, lookback)
+	if _, ok := state.seen["expired"]; !ok {
+		t.Error("Expected an incremental poll to keep transactions outside the lookback window")
+	}
+
+	state.record(nil, nil, pollNow, true, false, lookback)
+	if _, ok := state.seen["expired"]; ok {
+		t.Error("Expected a full scan to forget transactions outside the lookback window")
+	}
+	if _, ok := state.seen["recent"]; !ok {
+		t.Error("Expected a full scan to keep transactions inside the lookback window")
+	}
+}
+
+func TestWalletPollState_Adapt(t *testing.T) {
+	const base = 30 * time.Second
+	const idleMax = 5 * time.Minute
+
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		oldestOpen time.Time
+		active     bool
+		idleMax    time.Duration
+		want       time.Duration
+	}{
+		{"first poll starts at the base interval", 0, time.Time{}, false, idleMax, base},
+		{"idle wallet backs off", base, time.Time{}, false, idleMax, 2 * base},
+		{"backoff is capped", 4 * time.Minute, time.Time{}, false, idleMax, idleMax},
+		{"activity resets the interval", idleMax, time.Time{}, true, idleMax, base},
+		{"open transaction keeps the base interval", base, pollNow, false, idleMax, base},
+		{"backoff disabled", base, time.Time{}, false, 0, base},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := walletPollState{interval: tt.interval, oldestOpen: tt.oldestOpen}
+			state.adapt(tt.active, base, tt.idleMax)
+			if state.interval != tt.want {
+				t.Errorf("Expected interval %s, got %s", tt.want, state.interval)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ReconciliationResult summarises one reconciliation run
+type ReconciliationResult struct {
+	CompletedAt time.Time
+	Checked     int
+	Mismatches  int
+	// AssetTotals is the customer liability per asset at the end of the run
+	AssetTotals []models.AssetTotal
+}
+
+// ReconciliationJob periodically checks every non-zero balance against its transaction history
+type ReconciliationJob struct {
+	dbService *database.Service
+	interval  time.Duration
+
+	mu         sync.Mutex
+	lastResult ReconciliationResult
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewReconciliationJob creates a new balance reconciliation job
+func NewReconciliationJob(dbService *database.Service, interval time.Duration, logger *zap.Logger) *ReconciliationJob {
+	return &ReconciliationJob{
+		dbService: dbService,
+		interval:  interval,
+		logger:    logger,
+		stopChan:  make(chan struct{}),
+		doneChan:  make(chan struct{}),
+	}
+}
+
+// Start begins reconciling balances on the configured interval
+func (j *ReconciliationJob) Start(ctx context.Context) {
+	j.logger.Info("Starting balance reconciliation job", zap.Duration("interval", j.interval))
+	go j.runLoop(ctx)
+}
+
+// Stop gracefully stops the reconciliation job
+func (j *ReconciliationJob) Stop() {
+	j.logger.Info("Stopping balance reconciliation job")
+	close(j.stopChan)
+	<-j.doneChan
+	j.logger.Info("Balance reconciliation job stopped")
+}
+
+// LastResult returns the outcome of the most recent completed run
+func (j *ReconciliationJob) LastResult() ReconciliationResult {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastResult
+}
+
+func (j *ReconciliationJob) runLoop(ctx context.Context) {
+	defer close(j.doneChan)
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		j.run(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-j.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// run reconciles every balance; mismatches are logged by the ledger and counted here
+func (j *ReconciliationJob) run(ctx context.Context) {
+	balances, err := j.dbService.ListAccountBalances(ctx)
+	if err != nil {
+		j.logger.Error("Failed to list balances for reconciliation - will retry on next run", zap.Error(err))
+		return
+	}
+
+	result := ReconciliationResult{Checked: len(balances)}
+	for _, balance := range balances {
+		if err := j.dbService.ReconcileUserBalance(ctx, balance.UserId, balance.Asset); err != nil {
+			result.Mismatches++
+		}
+	}
+
+	totals, err := j.dbService.GetAssetTotals(ctx, 0)
+	if err != nil {
+		j.logger.Warn("Failed to load asset totals after reconciliation", zap.Error(err))
+	}
+	result.AssetTotals = totals
+	result.CompletedAt = time.Now().UTC()
+
+	j.mu.Lock()
+	j.lastResult = result
+	j.mu.Unlock()
+
+	if result.Mismatches > 0 {
+		j.logger.Error("Balance reconciliation found mismatches",
+			zap.Int("checked", result.Checked),
+			zap.Int("mismatches", result.Mismatches))
+		return
+	}
+	j.logger.Info("Balance reconciliation complete", zap.Int("checked", result.Checked))
+}
@@ -0,0 +1,63 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// ErrTransactionAcknowledged is returned when reprocessing a transaction an operator acknowledged
+var ErrTransactionAcknowledged = errors.New("transaction was acknowledged and is skipped")
+
+// Reprocess runs one transaction through the deposit or withdrawal processor even if it is already
+// marked processed. The ledger still rejects transactions it has applied, so a
+// transaction is never credited or debited twice. Debits run under the balance policy of the source
+// on ctx, or the listener's when the caller set none. Monitored wallets must have been loaded.
+func (d *SendReceiveListener) Reprocess(ctx context.Context, tx models.PrimeTransaction) error {
+	if database.TransactionSourceFrom(ctx) == "" {
+		ctx = database.WithTransactionSource(ctx, models.TransactionSourceListener)
+	}
+	wallet, err := d.monitoredWallet(tx.WalletId)
+	if err != nil {
+		return err
+	}
+
+	ack, acknowledged, err := d.dbService.GetPrimeTransactionAck(ctx, tx.Id)
+	if err != nil {
+		return err
+	}
+	if acknowledged {
+		return fmt.Errorf("%w: %s", ErrTransactionAcknowledged, ack.Reason)
+	}
+
+	correlation.Logger(ctx, d.logger).Info("Reprocessing transaction",
+		zap.String("transaction_id", tx.Id),
+		zap.String("wallet_id", wallet.Id),
+		zap.String("type", tx.Type),
+		zap.String("status", tx.Status))
+
+	d.recordTransaction(ctx, tx)
+	return d.applyTransaction(ctx, tx, *wallet)
+}
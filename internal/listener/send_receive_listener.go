@@ -23,12 +23,15 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
 )
 
 // Start begins the deposit monitoring process
 func (d *SendReceiveListener) Start(ctx context.Context, assetsFile string) error {
-	zap.L().Info("Starting deposit listener")
+	d.logger.Info("Starting deposit listener")
+	ctx = database.WithTransactionSource(ctx, models.TransactionSourceListener)
 
 	// Load monitored wallets
 	if err := d.LoadMonitoredWallets(ctx, assetsFile); err != nil {
@@ -36,21 +39,22 @@ func (d *SendReceiveListener) Start(ctx context.Context, assetsFile string) erro
 	}
 
 	if len(d.monitoredWallets) == 0 {
-		zap.L().Warn("No wallets to monitor - make sure addresses have been created")
+		d.logger.Warn("No wallets to monitor - make sure addresses have been created")
 		return fmt.Errorf("no wallets to monitor")
 	}
 
 	// Perform startup recovery to catch any missed transactions
-	if err := d.performStartupRecovery(ctx); err != nil {
-		zap.L().Error("Startup recovery failed", zap.Error(err))
+	if _, err := d.performStartupRecovery(ctx); err != nil {
+		d.logger.Error("Startup recovery failed", zap.Error(err))
 		return fmt.Errorf("startup recovery failed: %w", err)
 	}
 
 	go d.pollLoop(ctx)
 	go d.cleanupLoop(ctx)
 
-	zap.L().Info("Deposit listener started successfully",
+	d.logger.Info("Deposit listener started successfully",
 		zap.Duration("polling_interval", d.pollingInterval),
+		zap.Duration("tick_interval", d.tickInterval),
 		zap.Duration("lookback_window", d.lookbackWindow))
 
 	return nil
@@ -58,17 +62,18 @@ func (d *SendReceiveListener) Start(ctx context.Context, assetsFile string) erro
 
 // Stop gracefully stops the deposit listener
 func (d *SendReceiveListener) Stop() {
-	zap.L().Info("Stopping deposit listener")
+	d.logger.Info("Stopping deposit listener")
 	close(d.stopChan)
 	<-d.doneChan
-	zap.L().Info("Deposit listener stopped")
+	d.releaseWalletLeases()
+	d.logger.Info("Deposit listener stopped")
 }
 
 // pollLoop runs the main polling loop
 func (d *SendReceiveListener) pollLoop(ctx context.Context) {
 	defer close(d.doneChan)
 
-	ticker := time.NewTicker(d.pollingInterval)
+	ticker := time.NewTicker(d.tickInterval)
 	defer ticker.Stop()
 
 	d.pollWallets(ctx)
@@ -87,127 +92,253 @@ func (d *SendReceiveListener) pollLoop(ctx context.Context) {
 
 // pollWallets polls all monitored wallets for new transactions
 func (d *SendReceiveListener) pollWallets(ctx context.Context) {
-	zap.L().Info("Starting wallet polling cycle",
+	d.logger.Debug("Starting wallet polling cycle",
 		zap.Int("wallet_count", len(d.monitoredWallets)),
 		zap.Duration("lookback_window", d.lookbackWindow))
 
-	// Calculate time window for this poll
-	since := time.Now().UTC().Add(-d.lookbackWindow)
-	zap.L().Info("Polling for transactions since",
-		zap.Time("since", since))
+	// walletPoll is one wallet's fetch window and, once fetched, its transactions
+	type walletPoll struct {
+		wallet       models.WalletInfo
+		since        time.Time
+		full         bool
+		fetched      bool
+		transactions []models.PrimeTransaction
+	}
 
 	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var transactions []walletTransaction
+	var polledWallets []string
+	var polls []*walletPoll
 
+	now := time.Now()
 	for _, wallet := range d.monitoredWallets {
+		if !d.walletDue(wallet, now) {
+			continue
+		}
+		if !d.acquireWalletLease(ctx, wallet.Id) {
+			continue
+		}
+		state := d.walletPollState(wallet.Id)
+		state.lastAttempt = now
+
+		// Most polls only reach back to the previous poll and the oldest open transaction
+		since, full := state.window(now.UTC(), d.lookbackWindow, d.fullScanInterval)
+		poll := &walletPoll{wallet: wallet, since: since, full: full}
+		polls = append(polls, poll)
+
 		wg.Add(1)
 
-		// Poll each wallet concurrently
-		go func(w models.WalletInfo) {
+		// Fetch each wallet concurrently
+		go func(p *walletPoll) {
 			defer wg.Done()
 
-			if err := d.pollWallet(ctx, w, since); err != nil {
-				zap.L().Error("Failed to poll wallet",
-					zap.String("wallet_id", w.Id),
-					zap.String("asset_symbol", w.AssetSymbol),
+			fetched, err := d.pollWallet(ctx, p.wallet, p.since, p.full)
+			if err != nil {
+				d.logger.Error("Failed to poll wallet",
+					zap.String("wallet_id", p.wallet.Id),
+					zap.String("asset_symbol", p.wallet.AssetSymbol),
 					zap.Error(err))
+				return
 			}
-		}(wallet)
+
+			mu.Lock()
+			transactions = append(transactions, fetched...)
+			polledWallets = append(polledWallets, p.wallet.Id)
+			p.fetched = true
+			for _, wt := range fetched {
+				p.transactions = append(p.transactions, wt.tx)
+			}
+			mu.Unlock()
+		}(poll)
 	}
 
 	wg.Wait()
 
-	zap.L().Info("Wallet polling cycle complete")
+	// Apply per user/asset in created_at order; unrelated users run concurrently
+	processed, failed := d.processInOrder(ctx, transactions)
+
+	// Move each fetched wallet's cursor and adapt its interval to how active it was
+	for _, p := range polls {
+		if !p.fetched {
+			continue
+		}
+		state := d.walletPollState(p.wallet.Id)
+		truncated := d.pageLimit > 0 && len(p.transactions) >= d.pageLimit
+		active := state.record(p.transactions, failed, now.UTC(), p.full, truncated, d.lookbackWindow)
+		state.adapt(active, d.walletPollingInterval(p.wallet), d.idlePollingInterval)
+	}
+	d.saveCheckpoints(ctx, polledWallets, now)
+
+	if processed > 0 {
+		d.logger.Info("Wallet polling cycle complete",
+			zap.Int("fetched", len(transactions)),
+			zap.Int("processed", processed))
+	} else {
+		d.logger.Debug("Wallet polling cycle complete",
+			zap.Int("wallets", len(polls)),
+			zap.Int("fetched", len(transactions)))
+	}
 }
 
-// pollWallet polls a specific wallet for new transactions
-func (d *SendReceiveListener) pollWallet(ctx context.Context, wallet models.WalletInfo, since time.Time) error {
-	zap.L().Info("Polling wallet for transactions",
+// pollWallet fetches a wallet's transactions created since the given time
+func (d *SendReceiveListener) pollWallet(ctx context.Context, wallet models.WalletInfo, since time.Time, full bool) ([]walletTransaction, error) {
+	d.logger.Debug("Polling wallet for transactions",
 		zap.String("wallet_id", wallet.Id),
 		zap.String("asset_symbol", wallet.AssetSymbol),
-		zap.Time("since", since))
+		zap.Time("since", since),
+		zap.Bool("full_scan", full))
 
 	// Fetch transactions from Prime API
-	transactions, err := d.fetchWalletTransactions(ctx, wallet.Id, since)
+	transactions, err := d.fetchWalletTransactions(ctx, wallet.Id, since, d.pageLimit)
 	if err != nil {
-		return fmt.Errorf("failed to fetch wallet transactions: %w", err)
+		return nil, fmt.Errorf("failed to fetch wallet transactions: %w", err)
 	}
 
-	zap.L().Info("Fetched wallet transactions",
+	d.logger.Debug("Fetched wallet transactions",
 		zap.String("wallet_id", wallet.Id),
 		zap.String("asset_symbol", wallet.AssetSymbol),
 		zap.Int("transaction_count", len(transactions)))
 
-	for i, tx := range transactions {
-		if d.isTransactionProcessed(tx.Id) {
-			continue
-		}
-
-		zap.L().Info("Processing transaction",
-			zap.Int("tx_index", i+1),
-			zap.Int("total_txs", len(transactions)),
-			zap.String("transaction_id", tx.Id),
-			zap.String("type", tx.Type),
-			zap.String("status", tx.Status),
-			zap.String("symbol", tx.Symbol),
-			zap.String("amount", tx.Amount))
-
-		if err := d.processTransaction(ctx, tx, wallet); err != nil {
-			zap.L().Error("Failed to process transaction",
-				zap.String("transaction_id", tx.Id),
-				zap.String("wallet_id", wallet.Id),
-				zap.Error(err))
-		}
+	result := make([]walletTransaction, 0, len(transactions))
+	for _, tx := range transactions {
+		result = append(result, walletTransaction{tx: tx, wallet: wallet})
 	}
 
-	return nil
+	return result, nil
 }
 
 // processTransaction processes a single Prime transaction (deposit or withdrawal)
 func (d *SendReceiveListener) processTransaction(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
-	if d.isTransactionProcessed(tx.Id) {
-		zap.L().Debug("Transaction already processed, skipping",
+	ctx, _ = correlation.Ensure(ctx)
+	d.recordTransaction(ctx, tx)
+
+	if d.isTransactionProcessed(ctx, tx.Id) {
+		correlation.Logger(ctx, d.logger).Debug("Transaction already processed, skipping",
 			zap.String("transaction_id", tx.Id))
 		return nil
 	}
 
-	if tx.Type == "DEPOSIT" {
-		return d.processDeposit(ctx, tx, wallet)
-	} else if tx.Type == "WITHDRAWAL" {
-		return d.processWithdrawal(ctx, tx, wallet)
-	} else {
-		zap.L().Debug("Skipping unsupported transaction type",
+	// Operators acknowledge transactions that keep failing so they stop being retried
+	ack, acknowledged, err := d.dbService.GetPrimeTransactionAck(ctx, tx.Id)
+	if err != nil {
+		correlation.Logger(ctx, d.logger).Warn("Failed to check transaction acknowledgement", zap.String("transaction_id", tx.Id), zap.Error(err))
+	} else if acknowledged {
+		correlation.Logger(ctx, d.logger).Info("Transaction acknowledged by an operator, skipping",
 			zap.String("transaction_id", tx.Id),
-			zap.String("type", tx.Type))
+			zap.String("reason", ack.Reason))
+		d.observe(ctx, tx, models.ObservationAcknowledged, ack.Reason)
+		d.markTransactionProcessed(ctx, tx.Id)
 		return nil
 	}
+
+	return d.applyTransaction(ctx, tx, wallet)
+}
+
+// recordTransaction stores what Prime reported about a transaction and releases settled deposits.
+// It runs before the processed check because Prime reports status changes after a transaction is applied.
+func (d *SendReceiveListener) recordTransaction(ctx context.Context, tx models.PrimeTransaction) {
+	applied := d.transactionTypes[tx.Type]
+	// Deposits credited before completion stay pending until Prime reports them done
+	if applied && models.TransactionDirection(tx.Type) == models.DirectionInbound && tx.Status == "TRANSACTION_DONE" {
+		d.releaseSettledDeposit(ctx, tx)
+	}
+	d.saveRawTransaction(ctx, tx)
+	if applied && tx.Status == "TRANSACTION_DONE" {
+		d.saveReceipt(ctx, tx)
+	}
+}
+
+// applyTransaction passes a transaction of an applied type to the deposit or withdrawal processor by
+// the way it moves funds. Transactions of other types are counted and marked processed.
+func (d *SendReceiveListener) applyTransaction(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
+	if d.transactionTypes[tx.Type] {
+		switch models.TransactionDirection(tx.Type) {
+		case models.DirectionInbound:
+			return d.processDeposit(ctx, tx, wallet)
+		case models.DirectionOutbound:
+			return d.processWithdrawal(ctx, tx, wallet)
+		}
+	}
+
+	correlation.Logger(ctx, d.logger).Debug("Skipping transaction type not in LISTENER_TRANSACTION_TYPES",
+		zap.String("transaction_id", tx.Id),
+		zap.String("type", tx.Type))
+	d.skippedMu.Lock()
+	d.skipped[tx.Type]++
+	d.skippedMu.Unlock()
+	d.observe(ctx, tx, models.ObservationType, "")
+	d.markTransactionProcessed(ctx, tx.Id)
+	return nil
 }
 
-// performStartupRecovery checks for missed transactions during downtime
-func (d *SendReceiveListener) performStartupRecovery(ctx context.Context) error {
-	zap.L().Info("Starting startup recovery process")
+// SkippedTransactions returns how many transactions of each type were skipped because the type is
+// not applied
+func (d *SendReceiveListener) SkippedTransactions() map[string]uint64 {
+	d.skippedMu.Lock()
+	defer d.skippedMu.Unlock()
 
-	// Get the most recent transaction timestamp from our database
+	skipped := make(map[string]uint64, len(d.skipped))
+	for txType, count := range d.skipped {
+		skipped[txType] = count
+	}
+	return skipped
+}
+
+// performStartupRecovery rescans every wallet once before polling starts so that transactions missed
+// while the listener was down are applied. A wallet resumes from the cursor persisted in its checkpoint,
+// or is scanned over the startup scan window when one is configured. It returns how many transactions
+// were applied.
+func (d *SendReceiveListener) performStartupRecovery(ctx context.Context) (int, error) {
+	d.logger.Info("Starting startup recovery process")
+
+	checkpoints, err := d.dbService.GetListenerCheckpoints(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get listener checkpoints: %w", err)
+	}
+
+	// Wallets without a checkpoint (e.g. before checkpoints were kept) fall back to the most recent ledger transaction
 	mostRecentTime, err := d.dbService.GetMostRecentTransactionTime(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get most recent transaction time: %w", err)
+		return 0, fmt.Errorf("failed to get most recent transaction time: %w", err)
 	}
 
 	now := time.Now().UTC() // Ensure we work in UTC
-	recoveryStart := now.Add(-d.lookbackWindow)
 
-	zap.L().Info("Recovery window calculated",
+	d.logger.Info("Recovery window calculated",
 		zap.Time("most_recent_tx", mostRecentTime),
 		zap.Time("current_time", now),
-		zap.Time("recovery_start", recoveryStart),
-		zap.Duration("lookback_window", d.lookbackWindow))
-
-	// Poll all wallets for transactions in the recovery window
-	var totalRecovered int
-	var failedWallets []string
+		zap.Int("wallet_checkpoints", len(checkpoints)),
+		zap.Duration("lookback_window", d.lookbackWindow),
+		zap.Duration("startup_scan_window", d.startupScanWindow))
+
+	// Fetch all wallets for transactions in their recovery window
+	var recoveryTransactions []walletTransaction
+	var failedWallets, scannedWallets []string
+	fetchedByWallet := make(map[string][]models.PrimeTransaction)
+	earliestSince := now
 	for _, wallet := range d.monitoredWallets {
-		recovered, err := d.recoverWalletTransactions(ctx, wallet, recoveryStart)
+		checkpoint, ok := checkpoints[wallet.Id]
+		if !ok {
+			checkpoint = models.ListenerCheckpoint{WalletId: wallet.Id, PolledThrough: mostRecentTime}
+		}
+		since := d.recoveryStart(now, checkpoint)
+		if since.Before(earliestSince) {
+			earliestSince = since
+		}
+
+		d.walletPollState(wallet.Id).resume(checkpoint)
+		if !checkpoint.LastSeenTxTime.IsZero() {
+			d.logger.Debug("Resuming wallet from checkpoint",
+				zap.String("wallet_id", wallet.Id),
+				zap.String("last_seen_tx_id", checkpoint.LastSeenTxId),
+				zap.Time("last_seen_tx_time", checkpoint.LastSeenTxTime),
+				zap.Time("oldest_open_tx_time", checkpoint.OldestOpenTxTime))
+		}
+
+		fetched, err := d.recoverWalletTransactions(ctx, wallet, since)
 		if err != nil {
-			zap.L().Error("Failed to recover transactions for wallet",
+			d.logger.Error("Failed to recover transactions for wallet",
 				zap.String("wallet_id", wallet.Id),
 				zap.String("asset_symbol", wallet.AssetSymbol),
 				zap.Error(err))
@@ -215,74 +346,118 @@ func (d *SendReceiveListener) performStartupRecovery(ctx context.Context) error
 			// Continue with other wallets
 			continue
 		}
-		totalRecovered += recovered
+		recoveryTransactions = append(recoveryTransactions, fetched...)
+		scannedWallets = append(scannedWallets, wallet.Id)
+		for _, wt := range fetched {
+			fetchedByWallet[wallet.Id] = append(fetchedByWallet[wallet.Id], wt.tx)
+		}
 	}
 
+	// Apply recovered transactions with the same per user/asset ordering as normal polling
+	totalRecovered, failed := d.processInOrder(ctx, recoveryTransactions)
+
+	// The recovery scan stands in for a full scan, so the first poll can be incremental
+	for _, walletId := range scannedWallets {
+		transactions := fetchedByWallet[walletId]
+		truncated := d.pageLimit > 0 && len(transactions) >= d.pageLimit
+		d.walletPollState(walletId).record(transactions, failed, now, !truncated, truncated, d.lookbackWindow)
+	}
+	d.saveCheckpoints(ctx, scannedWallets, now)
+
 	// Log summary with warnings if some wallets failed
 	if len(failedWallets) > 0 {
-		zap.L().Warn("Startup recovery completed with some failures",
+		d.logger.Warn("Startup recovery completed with some failures",
+			zap.Int("total_transactions_fetched", len(recoveryTransactions)),
 			zap.Int("total_transactions_recovered", totalRecovered),
+			zap.Time("earliest_scan_start", earliestSince),
 			zap.Int("total_wallets", len(d.monitoredWallets)),
 			zap.Int("failed_wallets", len(failedWallets)),
 			zap.Strings("failed_wallet_details", failedWallets))
 
 		// If more than half the wallets failed, consider this a critical issue
 		if len(failedWallets) > len(d.monitoredWallets)/2 {
-			return fmt.Errorf("recovery failed for majority of wallets (%d/%d): %v",
+			return totalRecovered, fmt.Errorf("recovery failed for majority of wallets (%d/%d): %v",
 				len(failedWallets), len(d.monitoredWallets), failedWallets)
 		}
 	} else {
-		zap.L().Info("Startup recovery completed successfully",
+		d.logger.Info("Startup recovery completed successfully",
+			zap.Int("total_transactions_fetched", len(recoveryTransactions)),
 			zap.Int("total_transactions_recovered", totalRecovered),
+			zap.Time("earliest_scan_start", earliestSince),
 			zap.Int("total_wallets", len(d.monitoredWallets)))
 	}
 
-	return nil
+	return totalRecovered, nil
 }
 
-// recoverWalletTransactions recovers transactions for a specific wallet
-func (d *SendReceiveListener) recoverWalletTransactions(ctx context.Context, wallet models.WalletInfo, since time.Time) (int, error) {
-	zap.L().Debug("Recovering transactions for wallet",
+// Backfill scans every monitored wallet over window and applies the transactions the ledger is
+// missing, like the startup scan. Monitored wallets must have been loaded. Withdrawals it replays run
+// under the backfill balance policy. It returns how many transactions were applied.
+func (d *SendReceiveListener) Backfill(ctx context.Context, window time.Duration) (int, error) {
+	d.startupScanWindow = window
+	return d.performStartupRecovery(database.WithTransactionSource(ctx, models.TransactionSourceBackfill))
+}
+
+// recoveryStart returns when a wallet's startup scan begins. A configured startup scan window takes
+// precedence. A checkpoint with a cursor resumes just before the newest transaction seen, or at the
+// oldest transaction still open, but never earlier than one lookback window before it was polled.
+// Otherwise the scan reaches back one lookback window before the checkpoint, so transactions that were
+// still pending when the listener stopped are seen again.
+func (d *SendReceiveListener) recoveryStart(now time.Time, checkpoint models.ListenerCheckpoint) time.Time {
+	if d.startupScanWindow > 0 {
+		return now.Add(-d.startupScanWindow)
+	}
+	earliest := now.Add(-d.lookbackWindow)
+	if !checkpoint.PolledThrough.IsZero() && checkpoint.PolledThrough.Add(-d.lookbackWindow).Before(earliest) {
+		earliest = checkpoint.PolledThrough.Add(-d.lookbackWindow)
+	}
+	if checkpoint.LastSeenTxTime.IsZero() {
+		return earliest
+	}
+
+	since := checkpoint.LastSeenTxTime.Add(-incrementalOverlap)
+	if !checkpoint.OldestOpenTxTime.IsZero() && checkpoint.OldestOpenTxTime.Before(since) {
+		since = checkpoint.OldestOpenTxTime
+	}
+	if since.Before(earliest) {
+		since = earliest
+	}
+	return since
+}
+
+// saveCheckpoints persists the given wallets' cursors as polled up to polledThrough
+func (d *SendReceiveListener) saveCheckpoints(ctx context.Context, walletIds []string, polledThrough time.Time) {
+	for _, walletId := range walletIds {
+		checkpoint := d.walletPollState(walletId).checkpoint(walletId, polledThrough)
+		if err := d.dbService.SaveListenerCheckpoint(ctx, checkpoint); err != nil {
+			// A stale checkpoint only makes the next startup scan reach further back
+			d.logger.Warn("Failed to save listener checkpoint", zap.String("wallet_id", walletId), zap.Error(err))
+		}
+	}
+}
+
+// recoverWalletTransactions fetches transactions for a specific wallet during recovery
+func (d *SendReceiveListener) recoverWalletTransactions(ctx context.Context, wallet models.WalletInfo, since time.Time) ([]walletTransaction, error) {
+	d.logger.Debug("Recovering transactions for wallet",
 		zap.String("wallet_id", wallet.Id),
 		zap.String("asset_symbol", wallet.AssetSymbol),
 		zap.Time("since", since))
 
 	// Fetch transactions from Prime API
-	transactions, err := d.fetchWalletTransactions(ctx, wallet.Id, since)
+	transactions, err := d.fetchWalletTransactions(ctx, wallet.Id, since, d.pageLimit)
 	if err != nil {
-		return 0, fmt.Errorf("failed to fetch wallet transactions during recovery: %w", err)
+		return nil, fmt.Errorf("failed to fetch wallet transactions during recovery: %w", err)
 	}
 
-	zap.L().Debug("Fetched transactions for recovery",
+	d.logger.Debug("Fetched transactions for recovery",
 		zap.String("wallet_id", wallet.Id),
 		zap.String("asset_symbol", wallet.AssetSymbol),
 		zap.Int("transaction_count", len(transactions)))
 
-	var recovered int
+	result := make([]walletTransaction, 0, len(transactions))
 	for _, tx := range transactions {
-		// Skip if already processed
-		if d.isTransactionProcessed(tx.Id) {
-			zap.L().Debug("Transaction already processed during recovery, skipping",
-				zap.String("transaction_id", tx.Id))
-			continue
-		}
-
-		// Process transaction (duplicate prevention is handled in ProcessDepositV2)
-		if err := d.processTransaction(ctx, tx, wallet); err != nil {
-			// Log error but continue - the transaction might already exist
-			zap.L().Debug("Transaction processing during recovery",
-				zap.String("transaction_id", tx.Id),
-				zap.String("wallet_id", wallet.Id),
-				zap.Error(err))
-		} else {
-			recovered++
-			zap.L().Info("Recovered transaction",
-				zap.String("transaction_id", tx.Id),
-				zap.String("asset_symbol", tx.Symbol),
-				zap.String("network", tx.Network),
-				zap.String("amount", tx.Amount))
-		}
+		result = append(result, walletTransaction{tx: tx, wallet: wallet})
 	}
 
-	return recovered, nil
+	return result, nil
 }
@@ -0,0 +1,178 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/notify"
+
+	"go.uber.org/zap"
+)
+
+// VelocityJob looks for users whose deposits or withdrawals of an asset are far above their own rolling
+// average, opens a review case for each and notifies operators of the new cases
+type VelocityJob struct {
+	dbService *database.Service
+	notifier  notify.Notifier
+	poster    *notify.WebhookPoster
+	cfg       models.AnomalyConfig
+	now       func() time.Time
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewVelocityJob creates a new velocity anomaly detector. notifier and poster may be nil, in which case
+// new cases are only recorded and logged.
+func NewVelocityJob(dbService *database.Service, notifier notify.Notifier, poster *notify.WebhookPoster, cfg models.AnomalyConfig, logger *zap.Logger) *VelocityJob {
+	return &VelocityJob{
+		dbService: dbService,
+		notifier:  notifier,
+		poster:    poster,
+		cfg:       cfg,
+		now:       time.Now,
+		logger:    logger,
+		stopChan:  make(chan struct{}),
+		doneChan:  make(chan struct{}),
+	}
+}
+
+// Start begins checking for anomalies on the configured interval
+func (j *VelocityJob) Start(ctx context.Context) {
+	j.logger.Info("Starting velocity anomaly detector",
+		zap.Duration("interval", j.cfg.Interval),
+		zap.Duration("window", j.cfg.Window),
+		zap.Duration("baseline", j.cfg.Baseline))
+	go j.runLoop(ctx)
+}
+
+// Stop gracefully stops the velocity anomaly detector
+func (j *VelocityJob) Stop() {
+	j.logger.Info("Stopping velocity anomaly detector")
+	close(j.stopChan)
+	<-j.doneChan
+	j.logger.Info("Velocity anomaly detector stopped")
+}
+
+func (j *VelocityJob) runLoop(ctx context.Context) {
+	defer close(j.doneChan)
+
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		j.run(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-j.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// run opens a review case for each anomaly not already under review. A case closed during the current
+// window is not reopened for the same activity.
+func (j *VelocityJob) run(ctx context.Context) {
+	now := j.now().UTC()
+	entries, err := j.dbService.ListVelocityEntries(ctx, now.Add(-j.cfg.Window-j.cfg.Baseline))
+	if err != nil {
+		j.logger.Error("Failed to read activity for velocity check - will retry on next run", zap.Error(err))
+		return
+	}
+
+	var opened []models.ReviewCase
+	for _, anomaly := range database.DetectVelocityAnomalies(entries, now, j.cfg) {
+		details, err := json.Marshal(anomaly)
+		if err != nil {
+			j.logger.Error("Failed to encode velocity anomaly", zap.Error(err))
+			continue
+		}
+
+		reviewCase, err := j.dbService.OpenReviewCase(ctx, database.OpenReviewCaseParams{
+			Kind:    database.ReviewCaseKindVelocity,
+			UserId:  anomaly.UserId,
+			Asset:   anomaly.Asset,
+			Key:     fmt.Sprintf("%s:%s:%s:%s", database.ReviewCaseKindVelocity, anomaly.UserId, anomaly.Asset, anomaly.Direction),
+			Summary: j.summarize(anomaly),
+			Details: string(details),
+		}, now.Add(-j.cfg.Window))
+		if err != nil {
+			j.logger.Error("Failed to open review case for velocity anomaly - will retry on next run",
+				zap.String("user_id", anomaly.UserId),
+				zap.String("asset", anomaly.Asset),
+				zap.String("direction", anomaly.Direction),
+				zap.Error(err))
+			continue
+		}
+		if reviewCase == nil {
+			continue
+		}
+
+		opened = append(opened, *reviewCase)
+		if j.poster != nil {
+			if err := j.poster.Post(ctx, notify.EventReviewCaseOpened, reviewCase); err != nil {
+				j.logger.Error("Failed to post review case webhook", zap.String("case_id", reviewCase.Id), zap.Error(err))
+			}
+		}
+	}
+
+	if len(opened) > 0 {
+		j.alert(ctx, opened)
+	}
+}
+
+// summarize describes an anomaly in one line, e.g. "withdrawal USDC: 12 totalling 50000 in 24h (average 0.5
+// totalling 800)"
+func (j *VelocityJob) summarize(anomaly models.VelocityAnomaly) string {
+	return fmt.Sprintf("%s %s: %d totalling %s in %s (average %s totalling %s, exceeded: %s)",
+		anomaly.Direction, anomaly.Asset, anomaly.Count, anomaly.Volume.String(), j.cfg.Window,
+		anomaly.BaselineCount.Round(2).String(), anomaly.BaselineVolume.Round(8).String(),
+		strings.Join(anomaly.Reasons, ", "))
+}
+
+// alert emails the cases opened by one run. The cases are already stored, so a failed email is
+// logged and not retried.
+func (j *VelocityJob) alert(ctx context.Context, cases []models.ReviewCase) {
+	if j.notifier == nil {
+		return
+	}
+
+	var body strings.Builder
+	body.WriteString("The velocity anomaly detector opened these review cases:\n\n")
+	for _, reviewCase := range cases {
+		fmt.Fprintf(&body, "- %s (user %s): %s\n", reviewCase.Id, reviewCase.UserId, reviewCase.Summary)
+	}
+	body.WriteString("\nReview them with cmd/review-cases.\n")
+
+	message := notify.Message{Subject: fmt.Sprintf("%d velocity review case(s) opened", len(cases)), Body: body.String()}
+	if err := j.notifier.Notify(ctx, message); err != nil {
+		j.logger.Error("Failed to send velocity anomaly alert", zap.Int("cases", len(cases)), zap.Error(err))
+	}
+}
@@ -0,0 +1,71 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+
+	"go.uber.org/zap"
+)
+
+// ErrUnknownWallet is returned for pushed transactions on a wallet the listener does not monitor
+var ErrUnknownWallet = errors.New("transaction is not on a monitored wallet")
+
+// ErrInvalidTransaction is returned for pushed transactions whose amount cannot be normalized
+var ErrInvalidTransaction = errors.New("invalid transaction")
+
+// HandleTransaction processes a transaction pushed to the service (e.g. by a webhook) instead of
+// fetched by the poller. It goes through the same processing and dedupe as polled transactions,
+// so a transaction seen both ways is only applied once, and under the listener's balance policy.
+// The listener must have been started.
+func (d *SendReceiveListener) HandleTransaction(ctx context.Context, tx models.PrimeTransaction) error {
+	ctx = database.WithTransactionSource(ctx, models.TransactionSourceListener)
+	wallet, err := d.monitoredWallet(tx.WalletId)
+	if err != nil {
+		return err
+	}
+
+	// Polled transactions are normalized when converted from the Prime response
+	if err := prime.NormalizeAmount(&tx); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTransaction, err)
+	}
+
+	correlation.Logger(ctx, d.logger).Info("Processing pushed transaction",
+		zap.String("transaction_id", tx.Id),
+		zap.String("wallet_id", wallet.Id),
+		zap.String("type", tx.Type),
+		zap.String("status", tx.Status))
+
+	return d.processTransaction(ctx, tx, *wallet)
+}
+
+// monitoredWallet returns the monitored wallet with an id, or ErrUnknownWallet
+func (d *SendReceiveListener) monitoredWallet(walletId string) (*models.WalletInfo, error) {
+	for i := range d.monitoredWallets {
+		if d.monitoredWallets[i].Id == walletId {
+			return &d.monitoredWallets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownWallet, walletId)
+}
@@ -0,0 +1,112 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"testing"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/database/dbtest"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// releasedWithdrawal sets up a user whose withdrawal wd-1 was released and whose balance is then held
+// by wd-2, so debiting wd-1 again would overdraw the available balance
+func releasedWithdrawal(t *testing.T, policies map[string]string) *database.Service {
+	t.Helper()
+	ctx := context.Background()
+	dbService := dbtest.OpenConfig(t, models.DatabaseConfig{BalancePolicies: policies})
+	dbtest.CreateUser(t, dbService, "user1", "Test User", "test@example.com")
+	dbtest.StoreAddress(t, dbService, database.StoreAddressParams{
+		UserId: "user1", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xdeposit", WalletId: testWallet.Id,
+	})
+	dbtest.Deposit(t, dbService, "0xdeposit", "ETH", decimal.NewFromInt(10), "dep-1")
+
+	reserve := func(key string) {
+		if err := dbService.ReserveWithdrawal(ctx, database.ReserveWithdrawalParams{
+			UserId: "user1", Asset: "ETH", Amount: decimal.NewFromInt(10), IdempotencyKey: key, Destination: "0xexternal",
+		}); err != nil {
+			t.Fatalf("Failed to reserve %s: %v", key, err)
+		}
+	}
+	reserve("wd-1")
+	if err := dbService.ReleaseWithdrawal(ctx, "user1", "ETH", decimal.NewFromInt(10), "wd-1", "rejected"); err != nil {
+		t.Fatalf("Failed to release wd-1: %v", err)
+	}
+	reserve("wd-2")
+	return dbService
+}
+
+// completedWd1 is Prime reporting that the released withdrawal wd-1 went out after all
+var completedWd1 = models.PrimeTransaction{
+	Id: "prime-wd-1", WalletId: testWallet.Id, Type: "WITHDRAWAL", Status: "TRANSACTION_DONE",
+	Symbol: "ETH", Amount: "10", IdempotencyKey: "wd-1",
+}
+
+func TestHandleTransaction_UsesListenerBalancePolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		policies    map[string]string
+		wantErr     bool
+		wantBalance int64
+	}{
+		{"default sync policy applies and flags", nil, false, 0},
+		{"customer policy rejects the overdraft", map[string]string{"listener": "customer"}, true, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dbService := releasedWithdrawal(t, tt.policies)
+
+			// The handler is called with the webhook request's context, which carries no source
+			err := newTestListener(t, dbService).HandleTransaction(ctx, completedWd1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+
+			balance, err := dbService.GetUserBalance(ctx, "user1", "ETH")
+			if err != nil || !balance.Equal(decimal.NewFromInt(tt.wantBalance)) {
+				t.Errorf("Expected balance %d, got %s (%v)", tt.wantBalance, balance, err)
+			}
+		})
+	}
+}
+
+func TestReprocess_DefaultsToListenerBalancePolicy(t *testing.T) {
+	ctx := context.Background()
+	dbService := releasedWithdrawal(t, map[string]string{"listener": "customer", "cli": "sync"})
+	tx := completedWd1
+	tx.SignedAmount = decimal.NewFromInt(-10)
+
+	d := newTestListener(t, dbService)
+	if err := d.Reprocess(ctx, tx); err == nil {
+		t.Fatal("Expected the listener policy to reject the overdraft")
+	}
+
+	// An operator's reprocess runs under the cli policy
+	if err := d.Reprocess(database.WithTransactionSource(ctx, models.TransactionSourceCli), tx); err != nil {
+		t.Fatalf("Expected the cli policy to apply the withdrawal, got %v", err)
+	}
+	balance, err := dbService.GetUserBalance(ctx, "user1", "ETH")
+	if err != nil || !balance.IsZero() {
+		t.Errorf("Expected balance 0, got %s (%v)", balance, err)
+	}
+}
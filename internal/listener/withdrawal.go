@@ -25,6 +25,7 @@ import (
 
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"prime-send-receive-go/internal/correlation"
 	"prime-send-receive-go/internal/database"
 	"prime-send-receive-go/internal/models"
 )
@@ -41,7 +42,7 @@ func (d *SendReceiveListener) processWithdrawal(ctx context.Context, tx models.P
 
 	// Check if this is a terminal failure status
 	if terminalFailures[tx.Status] {
-		zap.L().Warn("Withdrawal failed with terminal status - crediting back",
+		correlation.Logger(ctx, d.logger).Warn("Withdrawal failed with terminal status - crediting back",
 			zap.String("transaction_id", tx.Id),
 			zap.String("status", tx.Status),
 			zap.String("symbol", tx.Symbol),
@@ -51,40 +52,49 @@ func (d *SendReceiveListener) processWithdrawal(ctx context.Context, tx models.P
 	}
 
 	if tx.Status != "TRANSACTION_DONE" {
-		zap.L().Debug("Skipping non-completed withdrawal - waiting for completion",
+		correlation.Logger(ctx, d.logger).Debug("Skipping non-completed withdrawal - waiting for completion",
 			zap.String("transaction_id", tx.Id),
 			zap.String("status", tx.Status),
 			zap.String("symbol", tx.Symbol),
 			zap.String("amount", tx.Amount),
 			zap.Time("created_at", tx.CreatedAt))
+		d.observe(ctx, tx, models.ObservationStatus, "captured at TRANSACTION_DONE")
+		d.trackWithdrawalRequest(ctx, tx)
 		return nil
 	}
 
-	amount, err := decimal.NewFromString(tx.Amount)
-	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
-	}
-
-	if amount.LessThan(decimal.Zero) {
-		amount = amount.Neg()
-	}
-
+	// The debit is applied as a positive amount; SignedAmount is negative for withdrawals
+	amount := tx.SignedAmount.Neg()
 	if amount.LessThanOrEqual(decimal.Zero) {
-		zap.L().Debug("Skipping zero amount withdrawal",
+		correlation.Logger(ctx, d.logger).Debug("Skipping zero amount withdrawal",
 			zap.String("transaction_id", tx.Id),
 			zap.String("amount", amount.String()))
+		d.observe(ctx, tx, models.ObservationZeroAmount, "")
 		return nil
 	}
 
-	// Find user by matching idempotency key prefix with user Id
-	userId, err := d.findUserByIdempotencyKeyPrefix(ctx, tx.IdempotencyKey)
+	// Batched withdrawals were reserved per user when they were queued
+	if handled, err := d.processBatchWithdrawal(ctx, tx, false); handled || err != nil {
+		return err
+	}
+
+	if handled, err := d.captureWithdrawal(ctx, tx, amount); handled || err != nil {
+		return err
+	}
+
+	// Withdrawals debited before holds were introduced are attributed by the request made under their idempotency key
+	request, err := d.findWithdrawalRequest(ctx, tx)
 	if err != nil {
-		zap.L().Debug("Could not match withdrawal to user via idempotency key - skipping",
+		correlation.Logger(ctx, d.logger).Debug("Could not match withdrawal to a withdrawal request - skipping",
 			zap.String("transaction_id", tx.Id),
 			zap.String("idempotency_key", tx.IdempotencyKey),
 			zap.Error(err))
+		d.observe(ctx, tx, models.ObservationUnmatched, err.Error())
 		return nil
 	}
+	userId := request.UserId
+	// Prime's outcome is final, so the request records it whatever happens on the ledger
+	defer d.settleWithdrawalRequest(ctx, tx, database.WithdrawalRequestStatusCompleted)
 
 	// Normalize symbol: Prime API returns network-specific symbols like "BASEUSDC" or "USDC"
 	// We need canonical symbol "USDC" for consistent balance tracking across networks
@@ -93,7 +103,7 @@ func (d *SendReceiveListener) processWithdrawal(ctx context.Context, tx models.P
 	assetNetwork := fmt.Sprintf("%s-%s", tx.Symbol, tx.Network)
 	assetNetwork = strings.TrimSuffix(assetNetwork, "-")
 
-	zap.L().Info("Processing completed withdrawal",
+	correlation.Logger(ctx, d.logger).Info("Processing completed withdrawal",
 		zap.String("transaction_id", tx.Id),
 		zap.String("user_id", userId),
 		zap.String("idempotency_key", tx.IdempotencyKey),
@@ -105,23 +115,21 @@ func (d *SendReceiveListener) processWithdrawal(ctx context.Context, tx models.P
 		zap.Time("created_at", tx.CreatedAt),
 		zap.Time("completed_at", tx.CompletedAt))
 
-	// Check if this withdrawal was already processed by the withdrawal CLI
-	// The CLI uses idempotency key as the transaction ID when debiting
-	// First try with idempotency key to see if it already exists
+	// Withdrawals submitted before holds were introduced were debited under their idempotency key,
+	// so try that first to see if it already exists
 	result, err := d.apiService.ProcessWithdrawal(ctx, userId, canonicalSymbol, amount, tx.IdempotencyKey)
 	if err != nil {
 		if errors.Is(err, database.ErrDuplicateTransaction) {
-			d.markTransactionProcessed(tx.Id)
-			return nil
+			return d.settleReversedWithdrawal(ctx, tx, userId, canonicalSymbol, amount)
 		}
-		zap.L().Debug("Idempotency key not found, trying with Prime transaction ID",
+		correlation.Logger(ctx, d.logger).Debug("Idempotency key not found, trying with Prime transaction ID",
 			zap.String("idempotency_key", tx.IdempotencyKey),
 			zap.String("prime_tx_id", tx.Id))
 
 		result, err = d.apiService.ProcessWithdrawal(ctx, userId, canonicalSymbol, amount, tx.Id)
 		if err != nil {
 			if errors.Is(err, database.ErrDuplicateTransaction) {
-				d.markTransactionProcessed(tx.Id)
+				d.markTransactionProcessed(ctx, tx.Id)
 				return nil
 			}
 			return fmt.Errorf("failed to process withdrawal: %w", err)
@@ -129,19 +137,18 @@ func (d *SendReceiveListener) processWithdrawal(ctx context.Context, tx models.P
 	}
 
 	if !result.Success {
-		if strings.Contains(result.Error, "duplicate transaction") {
-			d.markTransactionProcessed(tx.Id)
-			return nil
+		if errors.Is(result.Err, database.ErrDuplicateTransaction) {
+			return d.settleReversedWithdrawal(ctx, tx, userId, canonicalSymbol, amount)
 		}
-		zap.L().Warn("Withdrawal processing failed",
+		correlation.Logger(ctx, d.logger).Warn("Withdrawal processing failed",
 			zap.String("transaction_id", tx.Id),
 			zap.String("error", result.Error))
 		return fmt.Errorf("withdrawal processing failed: %s", result.Error)
 	}
 
-	d.markTransactionProcessed(tx.Id)
+	d.markTransactionProcessed(ctx, tx.Id)
 
-	zap.L().Info("Withdrawal processed successfully - balance debited",
+	correlation.Logger(ctx, d.logger).Info("Withdrawal processed successfully - balance debited",
 		zap.String("transaction_id", tx.Id),
 		zap.String("user_id", result.UserId),
 		zap.String("asset", result.Asset),
@@ -152,40 +159,41 @@ func (d *SendReceiveListener) processWithdrawal(ctx context.Context, tx models.P
 	return nil
 }
 
-// handleFailedWithdrawal credits back a withdrawal that failed on-chain
+// handleFailedWithdrawal releases the hold of, or credits back, a withdrawal that failed on-chain
 func (d *SendReceiveListener) handleFailedWithdrawal(ctx context.Context, tx models.PrimeTransaction, wallet models.WalletInfo) error {
-	amount, err := decimal.NewFromString(tx.Amount)
-	if err != nil {
-		return fmt.Errorf("invalid amount: %w", err)
-	}
-
-	if amount.LessThan(decimal.Zero) {
-		amount = amount.Neg()
-	}
-
+	amount := tx.SignedAmount.Neg()
 	if amount.LessThanOrEqual(decimal.Zero) {
-		zap.L().Debug("Skipping zero amount failed withdrawal",
+		correlation.Logger(ctx, d.logger).Debug("Skipping zero amount failed withdrawal",
 			zap.String("transaction_id", tx.Id),
 			zap.String("amount", amount.String()))
+		d.observe(ctx, tx, models.ObservationZeroAmount, "")
 		return nil
 	}
 
-	userId, err := d.findUserByIdempotencyKeyPrefix(ctx, tx.IdempotencyKey)
+	if handled, err := d.processBatchWithdrawal(ctx, tx, true); handled || err != nil {
+		return err
+	}
+
+	request, err := d.findWithdrawalRequest(ctx, tx)
 	if err != nil {
-		zap.L().Warn("Could not match failed withdrawal to user via idempotency key - may be external withdrawal",
+		correlation.Logger(ctx, d.logger).Warn("Could not match failed withdrawal to a withdrawal request - may be external withdrawal",
 			zap.String("transaction_id", tx.Id),
 			zap.String("idempotency_key", tx.IdempotencyKey),
 			zap.String("status", tx.Status),
 			zap.Error(err))
-		d.markTransactionProcessed(tx.Id)
+		d.observe(ctx, tx, models.ObservationUnmatched, err.Error())
+		d.markTransactionProcessed(ctx, tx.Id)
 		return nil
 	}
+	userId := request.UserId
+	// Prime's outcome is final, so the request records it whatever happens on the ledger
+	defer d.settleWithdrawalRequest(ctx, tx, failedWithdrawalStatus(tx.Status))
 
 	// Normalize symbol: Prime API returns network-specific symbols like "BASEUSDC" or "USDC"
 	// We need canonical symbol "USDC" for consistent balance tracking across networks
 	canonicalSymbol := normalizeSymbol(tx.Symbol)
 
-	zap.L().Info("Processing failed withdrawal - crediting back to user",
+	correlation.Logger(ctx, d.logger).Info("Processing failed withdrawal - crediting back to user",
 		zap.String("transaction_id", tx.Id),
 		zap.String("user_id", userId),
 		zap.String("idempotency_key", tx.IdempotencyKey),
@@ -195,29 +203,43 @@ func (d *SendReceiveListener) handleFailedWithdrawal(ctx context.Context, tx mod
 		zap.String("amount", amount.String()),
 		zap.Time("created_at", tx.CreatedAt))
 
-	// Credit back the amount (deposit to reverse the failed withdrawal)
-	// Use idempotency key as original transaction ID for tracking
+	// A withdrawal debited before holds were introduced may have been rolled back already, e.g. when the
+	// submit call returned an error
+	reversal, err := d.dbService.FindReversal(ctx, tx.IdempotencyKey)
+	if err != nil {
+		return fmt.Errorf("failed to check for ledger reversal: %w", err)
+	}
+	if reversal != nil {
+		correlation.Logger(ctx, d.logger).Info("Failed withdrawal already reversed on the ledger - skipping",
+			zap.String("transaction_id", tx.Id),
+			zap.String("idempotency_key", tx.IdempotencyKey),
+			zap.String("reversal_tx", reversal.ExternalTransactionId))
+		d.markTransactionProcessed(ctx, tx.Id)
+		return nil
+	}
+
+	// Release the hold, or credit back a debit made before holds, using the idempotency key to find it
 	result, err := d.apiService.CreditBackFailedWithdrawal(ctx, userId, canonicalSymbol, amount, tx.IdempotencyKey)
 	if err != nil {
 		return fmt.Errorf("failed to credit back failed withdrawal: %w", err)
 	}
 
 	if !result.Success {
-		if strings.Contains(result.Error, "duplicate transaction") {
-			zap.L().Info("Failed withdrawal reversal already processed - skipping",
+		if errors.Is(result.Err, database.ErrDuplicateTransaction) {
+			correlation.Logger(ctx, d.logger).Info("Failed withdrawal reversal already processed - skipping",
 				zap.String("transaction_id", tx.Id))
-			d.markTransactionProcessed(tx.Id)
+			d.markTransactionProcessed(ctx, tx.Id)
 			return nil
 		}
-		zap.L().Error("Failed withdrawal credit-back processing failed",
+		correlation.Logger(ctx, d.logger).Error("Failed withdrawal credit-back processing failed",
 			zap.String("transaction_id", tx.Id),
 			zap.String("error", result.Error))
 		return fmt.Errorf("failed withdrawal credit-back failed: %s", result.Error)
 	}
 
-	d.markTransactionProcessed(tx.Id)
+	d.markTransactionProcessed(ctx, tx.Id)
 
-	zap.L().Info("Failed withdrawal credited back successfully",
+	correlation.Logger(ctx, d.logger).Info("Failed withdrawal credited back successfully",
 		zap.String("transaction_id", tx.Id),
 		zap.String("user_id", result.UserId),
 		zap.String("asset", result.Asset),
@@ -229,6 +251,144 @@ func (d *SendReceiveListener) handleFailedWithdrawal(ctx context.Context, tx mod
 	return nil
 }
 
+// settleReversedWithdrawal handles a completed withdrawal whose debit is already on the ledger. If the
+// ledger rolled that debit back (the submit call failed but Prime sent the funds anyway), the withdrawal is
+// debited again under the Prime transaction id; otherwise there is nothing left to do.
+func (d *SendReceiveListener) settleReversedWithdrawal(ctx context.Context, tx models.PrimeTransaction, userId, asset string, amount decimal.Decimal) error {
+	reversal, err := d.dbService.FindReversal(ctx, tx.IdempotencyKey)
+	if err != nil {
+		return fmt.Errorf("failed to check for ledger reversal: %w", err)
+	}
+	if reversal == nil {
+		d.markTransactionProcessed(ctx, tx.Id)
+		return nil
+	}
+
+	correlation.Logger(ctx, d.logger).Warn("Withdrawal completed in Prime after the ledger rolled it back - debiting again",
+		zap.String("transaction_id", tx.Id),
+		zap.String("user_id", userId),
+		zap.String("idempotency_key", tx.IdempotencyKey),
+		zap.String("reversal_tx", reversal.ExternalTransactionId),
+		zap.String("amount", amount.String()))
+
+	return d.debitAgain(ctx, tx, userId, asset, amount)
+}
+
+// captureWithdrawal debits the hold placed when a customer withdrawal was submitted. If the hold was released
+// (the submit call failed but Prime sent the funds anyway) the withdrawal is debited under the Prime
+// transaction id instead. Returns false when the withdrawal has no hold, i.e. it was debited before holds
+// were introduced or was not made through the ledger.
+func (d *SendReceiveListener) captureWithdrawal(ctx context.Context, tx models.PrimeTransaction, amount decimal.Decimal) (bool, error) {
+	if tx.IdempotencyKey == "" {
+		return false, nil
+	}
+
+	hold, err := d.dbService.CaptureWithdrawal(ctx, tx.IdempotencyKey, tx.Id)
+	if err != nil {
+		return true, fmt.Errorf("failed to capture withdrawal: %w", err)
+	}
+	if hold == nil {
+		return false, nil
+	}
+	defer d.settleWithdrawalRequest(ctx, tx, database.WithdrawalRequestStatusCompleted)
+
+	if hold.Status == database.WithdrawalHoldStatusReleased {
+		correlation.Logger(ctx, d.logger).Warn("Withdrawal completed in Prime after its hold was released - debiting",
+			zap.String("transaction_id", tx.Id),
+			zap.String("user_id", hold.UserId),
+			zap.String("idempotency_key", tx.IdempotencyKey),
+			zap.String("amount", amount.String()))
+		return true, d.debitAgain(ctx, tx, hold.UserId, hold.Asset, amount)
+	}
+
+	d.markTransactionProcessed(ctx, tx.Id)
+	correlation.Logger(ctx, d.logger).Info("Withdrawal captured - balance debited",
+		zap.String("transaction_id", tx.Id),
+		zap.String("user_id", hold.UserId),
+		zap.String("asset", hold.Asset),
+		zap.String("amount", hold.Amount.String()),
+		zap.String("ledger_tx", hold.TransactionId))
+	return true, nil
+}
+
+// debitAgain debits a completed withdrawal whose amount the ledger had given back, under the Prime transaction id
+func (d *SendReceiveListener) debitAgain(ctx context.Context, tx models.PrimeTransaction, userId, asset string, amount decimal.Decimal) error {
+	result, err := d.apiService.ProcessWithdrawal(ctx, userId, asset, amount, tx.Id)
+	if err != nil && !errors.Is(err, database.ErrDuplicateTransaction) {
+		return fmt.Errorf("failed to debit returned withdrawal: %w", err)
+	}
+	if err == nil && !result.Success && !errors.Is(result.Err, database.ErrDuplicateTransaction) {
+		return fmt.Errorf("failed to debit returned withdrawal: %s", result.Error)
+	}
+
+	d.markTransactionProcessed(ctx, tx.Id)
+	return nil
+}
+
+// processBatchWithdrawal settles a Prime withdrawal that paid out a withdrawal batch, capturing every
+// withdrawal in the batch when it completed and releasing them when it failed. Returns false when tx is not a batch.
+func (d *SendReceiveListener) processBatchWithdrawal(ctx context.Context, tx models.PrimeTransaction, failed bool) (bool, error) {
+	if tx.IdempotencyKey == "" {
+		return false, nil
+	}
+
+	batch, err := d.dbService.GetWithdrawalBatch(ctx, tx.IdempotencyKey)
+	if err != nil {
+		return true, err
+	}
+	if batch == nil {
+		return false, nil
+	}
+
+	items, err := d.dbService.ListWithdrawalBatchItems(ctx, batch.Id)
+	if err != nil {
+		return true, err
+	}
+
+	if !failed {
+		// Items queued before holds were introduced have no hold; they were debited when queued
+		for _, item := range items {
+			if _, err := d.dbService.CaptureWithdrawal(ctx, item.IdempotencyKey, tx.Id); err != nil {
+				return true, fmt.Errorf("failed to capture batched withdrawal %s: %w", item.Id, err)
+			}
+			d.advanceWithdrawalRequest(ctx, item.IdempotencyKey, tx, database.WithdrawalRequestStatusCompleted)
+		}
+		if err := d.dbService.UpdateWithdrawalBatchStatus(ctx, batch.Id, database.WithdrawalBatchStatusCompleted); err != nil {
+			return true, err
+		}
+		d.markTransactionProcessed(ctx, tx.Id)
+		correlation.Logger(ctx, d.logger).Info("Withdrawal batch completed",
+			zap.String("transaction_id", tx.Id),
+			zap.String("batch_id", batch.Id),
+			zap.String("total_amount", batch.TotalAmount.String()),
+			zap.Int("withdrawals", batch.ItemCount))
+		return true, nil
+	}
+
+	for _, item := range items {
+		result, err := d.apiService.CreditBackFailedWithdrawal(ctx, item.UserId, item.Asset, item.Amount, item.IdempotencyKey)
+		if err != nil {
+			return true, fmt.Errorf("failed to credit back batched withdrawal %s: %w", item.Id, err)
+		}
+		if !result.Success && !errors.Is(result.Err, database.ErrDuplicateTransaction) {
+			return true, fmt.Errorf("batched withdrawal %s credit-back failed: %s", item.Id, result.Error)
+		}
+		d.advanceWithdrawalRequest(ctx, item.IdempotencyKey, tx, failedWithdrawalStatus(tx.Status))
+	}
+
+	if err := d.dbService.UpdateWithdrawalBatchStatus(ctx, batch.Id, database.WithdrawalBatchStatusFailed); err != nil {
+		return true, err
+	}
+	d.markTransactionProcessed(ctx, tx.Id)
+
+	correlation.Logger(ctx, d.logger).Warn("Withdrawal batch failed - released every withdrawal in the batch",
+		zap.String("transaction_id", tx.Id),
+		zap.String("batch_id", batch.Id),
+		zap.String("status", tx.Status),
+		zap.Int("withdrawals", len(items)))
+	return true, nil
+}
+
 // symbolMapping maps Prime API's network-specific symbols to canonical symbols
 var symbolMapping = map[string]string{
 	// USDC variants (canonical + network-specific)
@@ -249,3 +409,11 @@ func normalizeSymbol(symbol string) string {
 	}
 	return symbol
 }
+
+// failedWithdrawalStatus maps a terminal Prime failure status to the status of the withdrawal's request
+func failedWithdrawalStatus(primeStatus string) string {
+	if primeStatus == "TRANSACTION_CANCELLED" {
+		return database.WithdrawalRequestStatusCancelled
+	}
+	return database.WithdrawalRequestStatusFailed
+}
@@ -0,0 +1,617 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/coordination"
+	"prime-send-receive-go/internal/correlation"
+	"prime-send-receive-go/internal/custody"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+	"prime-send-receive-go/internal/treasury"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// WithdrawalWorkerConfig contains configuration for WithdrawalWorker
+type WithdrawalWorkerConfig struct {
+	Custody        custody.Provider
+	DbService      *database.Service
+	PortfolioId    string
+	PollInterval   time.Duration
+	SubmitInterval time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetryBackoff   time.Duration
+	MaxPerMinute   int
+	BatchWindow    time.Duration
+	Coordinator    coordination.Store
+	// Treasury enables automatic vault top-ups when set
+	Treasury *treasury.Service
+	// StepHook, when set, is called as each withdrawal reaches a saga step; crash-recovery tests use it
+	StepHook models.WithdrawalStepHook
+	Logger   *zap.Logger
+}
+
+// WithdrawalWorker drains the withdrawal queue and submits withdrawals to Prime
+type WithdrawalWorker struct {
+	custody     custody.Provider
+	dbService   *database.Service
+	portfolioId string
+
+	pollInterval   time.Duration
+	submitInterval time.Duration
+	batchSize      int
+	maxAttempts    int
+	retryBackoff   time.Duration
+	maxPerMinute   int
+	batchWindow    time.Duration
+	coordinator    coordination.Store
+	treasury       *treasury.Service
+	stepHook       models.WithdrawalStepHook
+
+	logger *zap.Logger
+
+	// Control channels
+	stopChan chan struct{}
+	doneChan chan struct{}
+}
+
+// NewWithdrawalWorker creates a new withdrawal queue worker
+func NewWithdrawalWorker(cfg WithdrawalWorkerConfig) *WithdrawalWorker {
+	coordinator := cfg.Coordinator
+	if coordinator == nil {
+		coordinator = coordination.NewMemoryStore()
+	}
+
+	return &WithdrawalWorker{
+		custody:        cfg.Custody,
+		dbService:      cfg.DbService,
+		portfolioId:    cfg.PortfolioId,
+		pollInterval:   cfg.PollInterval,
+		submitInterval: cfg.SubmitInterval,
+		batchSize:      cfg.BatchSize,
+		maxAttempts:    cfg.MaxAttempts,
+		retryBackoff:   cfg.RetryBackoff,
+		maxPerMinute:   cfg.MaxPerMinute,
+		batchWindow:    cfg.BatchWindow,
+		coordinator:    coordinator,
+		treasury:       cfg.Treasury,
+		stepHook:       cfg.StepHook,
+		logger:         cfg.Logger,
+		stopChan:       make(chan struct{}),
+		doneChan:       make(chan struct{}),
+	}
+}
+
+// Start requeues withdrawals interrupted by a previous shutdown and begins draining the queue
+func (w *WithdrawalWorker) Start(ctx context.Context) error {
+	w.logger.Info("Starting withdrawal queue worker")
+
+	if err := w.requeueInterrupted(ctx); err != nil {
+		return err
+	}
+
+	go w.drainLoop(ctx)
+
+	w.logger.Info("Withdrawal queue worker started",
+		zap.Duration("poll_interval", w.pollInterval),
+		zap.Duration("submit_interval", w.submitInterval),
+		zap.Int("max_attempts", w.maxAttempts))
+
+	return nil
+}
+
+// requeueInterrupted returns withdrawals a previous worker left in processing to the queue. Resubmitting
+// them reuses their idempotency keys, so Prime pays out each at most once.
+func (w *WithdrawalWorker) requeueInterrupted(ctx context.Context) error {
+	requeued, err := w.dbService.RequeueStaleWithdrawals(ctx)
+	if err != nil {
+		return err
+	}
+	if requeued > 0 {
+		w.logger.Warn("Requeued withdrawals left in processing state", zap.Int64("count", requeued))
+	}
+	return nil
+}
+
+// Stop gracefully stops the withdrawal worker
+func (w *WithdrawalWorker) Stop() {
+	w.logger.Info("Stopping withdrawal queue worker")
+	close(w.stopChan)
+	<-w.doneChan
+	w.logger.Info("Withdrawal queue worker stopped")
+}
+
+// drainLoop polls the queue and submits due withdrawals, spacing Prime calls by submitInterval
+func (w *WithdrawalWorker) drainLoop(ctx context.Context) {
+	defer close(w.doneChan)
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	limiter := time.NewTicker(w.submitInterval)
+	defer limiter.Stop()
+
+	for {
+		w.drainBatch(ctx, limiter)
+
+		select {
+		case <-ticker.C:
+		case <-w.stopChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// drainBatch claims one batch of due withdrawals and submits them
+func (w *WithdrawalWorker) drainBatch(ctx context.Context, limiter *time.Ticker) {
+	withdrawals, err := w.dbService.ClaimQueuedWithdrawals(ctx, w.batchSize)
+	if err != nil {
+		w.logger.Error("Failed to claim queued withdrawals", zap.Error(err))
+		return
+	}
+
+	if len(withdrawals) == 0 {
+		return
+	}
+
+	w.logger.Info("Claimed queued withdrawals", zap.Int("count", len(withdrawals)))
+
+	withdrawals, ok := w.resubmitBatches(ctx, limiter, withdrawals)
+	if !ok || len(withdrawals) == 0 {
+		return
+	}
+
+	if w.batchWindow > 0 {
+		w.drainBatched(ctx, limiter, withdrawals)
+		return
+	}
+
+	for _, queued := range withdrawals {
+		// Unsubmitted withdrawals stay in processing and are requeued on the next Start
+		if !w.waitForSubmitSlot(ctx, limiter) {
+			return
+		}
+
+		w.submit(ctx, queued)
+	}
+}
+
+// resubmitBatches submits claimed withdrawals that already belong to a batch as that batch again, whatever
+// the batch window, so Prime sees the batch's idempotency key rather than a new one. It returns the
+// withdrawals that belong to no batch, and false on shutdown.
+func (w *WithdrawalWorker) resubmitBatches(ctx context.Context, limiter *time.Ticker, withdrawals []models.QueuedWithdrawal) ([]models.QueuedWithdrawal, bool) {
+	var rest []models.QueuedWithdrawal
+	var batchIds []string
+	batches := make(map[string][]models.QueuedWithdrawal)
+	for _, queued := range withdrawals {
+		if queued.BatchId == "" {
+			rest = append(rest, queued)
+			continue
+		}
+		if _, ok := batches[queued.BatchId]; !ok {
+			batchIds = append(batchIds, queued.BatchId)
+		}
+		batches[queued.BatchId] = append(batches[queued.BatchId], queued)
+	}
+
+	for _, batchId := range batchIds {
+		// Unsubmitted batches stay in processing and are requeued on the next Start
+		if !w.waitForSubmitSlot(ctx, limiter) {
+			return nil, false
+		}
+		w.submitBatch(ctx, batches[batchId])
+	}
+	return rest, true
+}
+
+// waitForSubmitSlot blocks until the local submit interval has elapsed and, when a per-minute
+// quota is configured, the shared rate limit allows another Prime call. Returns false on shutdown.
+func (w *WithdrawalWorker) waitForSubmitSlot(ctx context.Context, limiter *time.Ticker) bool {
+	for {
+		select {
+		case <-limiter.C:
+		case <-w.stopChan:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+
+		if w.maxPerMinute <= 0 {
+			return true
+		}
+
+		allowed, err := w.coordinator.Allow(ctx, "prime:withdrawals", w.maxPerMinute, time.Minute)
+		if err != nil {
+			w.logger.Warn("Rate limit check failed - allowing submission", zap.Error(err))
+			return true
+		}
+		if allowed {
+			return true
+		}
+
+		w.logger.Debug("Withdrawal rate limit reached - waiting", zap.Int("max_per_minute", w.maxPerMinute))
+	}
+}
+
+// submit sends a single queued withdrawal to Prime and records the outcome
+func (w *WithdrawalWorker) submit(ctx context.Context, queued models.QueuedWithdrawal) {
+	ctx = queuedContext(ctx, queued)
+	attempt := queued.Attempts + 1
+
+	if w.treasury != nil && !w.hotWalletReady(ctx, []models.QueuedWithdrawal{queued}) {
+		return
+	}
+
+	if !w.reachStep(ctx, models.WithdrawalStepReserved, queued) {
+		return
+	}
+
+	withdrawal, err := w.custody.CreateWithdrawal(ctx, models.CreateWithdrawalParams{
+		PortfolioId:     w.portfolioId,
+		WalletId:        queued.WalletId,
+		DestinationType: queued.DestinationType,
+		Destination:     queued.Destination,
+		Amount:          queued.Amount.String(),
+		Asset:           queued.AssetNetwork,
+		IdempotencyKey:  queued.IdempotencyKey,
+	})
+	if err == nil {
+		if !w.reachStep(ctx, models.WithdrawalStepSubmitted, queued) {
+			return
+		}
+		if err := w.dbService.MarkWithdrawalSubmitted(ctx, queued.Id, withdrawal.ActivityId); err != nil {
+			correlation.Logger(ctx, w.logger).Error("Withdrawal submitted but queue status update failed",
+				zap.String("queue_id", queued.Id),
+				zap.String("activity_id", withdrawal.ActivityId),
+				zap.Error(err))
+			return
+		}
+		w.markRequestsSubmitted(ctx, withdrawal.ActivityId, queued)
+		w.saveReceipt(ctx, withdrawal.Receipt(queued.UserId))
+		w.captureUnreported(ctx, withdrawal.ActivityId, queued)
+		correlation.Logger(ctx, w.logger).Info("Queued withdrawal submitted to Prime",
+			zap.String("queue_id", queued.Id),
+			zap.String("user_id", queued.UserId),
+			zap.String("activity_id", withdrawal.ActivityId),
+			zap.Int("attempt", attempt))
+		return
+	}
+
+	w.handleSubmitFailure(ctx, queued, err)
+}
+
+// reachStep reports a saga step to the step hook. It returns false when the hook stops the withdrawal
+// there; the withdrawal is left in processing, as after a crash, for the next Start to requeue.
+func (w *WithdrawalWorker) reachStep(ctx context.Context, step models.WithdrawalStep, queued models.QueuedWithdrawal) bool {
+	if w.stepHook == nil {
+		return true
+	}
+	if err := w.stepHook(step, queued.IdempotencyKey); err != nil {
+		correlation.Logger(ctx, w.logger).Warn("Withdrawal stopped at saga step",
+			zap.String("queue_id", queued.Id),
+			zap.String("step", string(step)),
+			zap.Error(err))
+		return false
+	}
+	return true
+}
+
+// saveReceipt keeps Prime's response for later lookup; the withdrawal stands if it cannot be saved
+func (w *WithdrawalWorker) saveReceipt(ctx context.Context, receipt models.WithdrawalReceipt) {
+	if err := w.dbService.SaveWithdrawalReceipt(ctx, receipt); err != nil {
+		correlation.Logger(ctx, w.logger).Warn("Failed to save withdrawal receipt",
+			zap.String("activity_id", receipt.ActivityId),
+			zap.Error(err))
+	}
+}
+
+// markRequestsSubmitted records that Prime accepted the withdrawals; a failed update is only logged
+func (w *WithdrawalWorker) markRequestsSubmitted(ctx context.Context, activityId string, items ...models.QueuedWithdrawal) {
+	for _, item := range items {
+		if err := w.dbService.MarkWithdrawalRequestSubmitted(ctx, item.IdempotencyKey, activityId); err != nil {
+			correlation.Logger(ctx, w.logger).Warn("Failed to mark withdrawal request submitted",
+				zap.String("queue_id", item.Id),
+				zap.String("activity_id", activityId),
+				zap.Error(err))
+		}
+	}
+}
+
+// captureUnreported debits withdrawals the listener will never see complete as soon as Prime accepts them.
+// A withdrawal whose capture fails keeps its hold.
+func (w *WithdrawalWorker) captureUnreported(ctx context.Context, activityId string, items ...models.QueuedWithdrawal) {
+	for _, item := range items {
+		if prime.ReportedAsWithdrawal(item.DestinationType) {
+			continue
+		}
+		if _, err := w.dbService.CaptureWithdrawal(ctx, item.IdempotencyKey, activityId); err != nil {
+			correlation.Logger(ctx, w.logger).Error("Failed to capture withdrawal Prime does not report - hold left in place",
+				zap.String("queue_id", item.Id),
+				zap.String("activity_id", activityId),
+				zap.Error(err))
+		}
+	}
+}
+
+// handleSubmitFailure schedules a retry for a withdrawal Prime rejected, or releases its withdrawal hold
+// once attempts are exhausted. A hold is only released once Prime is known not to have the withdrawal.
+func (w *WithdrawalWorker) handleSubmitFailure(ctx context.Context, queued models.QueuedWithdrawal, err error) {
+	ctx = queuedContext(ctx, queued)
+	attempt := queued.Attempts + 1
+
+	if attempt < w.maxAttempts {
+		nextAttempt := time.Now().Add(w.retryBackoff * time.Duration(1<<(attempt-1)))
+		correlation.Logger(ctx, w.logger).Warn("Queued withdrawal submission failed - scheduling retry",
+			zap.String("queue_id", queued.Id),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", w.maxAttempts),
+			zap.Time("next_attempt_at", nextAttempt),
+			zap.Error(err))
+		if err := w.dbService.ScheduleWithdrawalRetry(ctx, queued.Id, err.Error(), nextAttempt); err != nil {
+			correlation.Logger(ctx, w.logger).Error("Failed to schedule withdrawal retry", zap.String("queue_id", queued.Id), zap.Error(err))
+		}
+		return
+	}
+
+	correlation.Logger(ctx, w.logger).Error("Queued withdrawal exhausted retries - releasing reserved balance",
+		zap.String("queue_id", queued.Id),
+		zap.String("user_id", queued.UserId),
+		zap.String("asset", queued.Asset),
+		zap.String("amount", queued.Amount.String()),
+		zap.Int("attempts", attempt),
+		zap.Error(err))
+
+	// A timeout or 5xx may hide a withdrawal Prime accepted, which must not be paid out of a released hold
+	if prime.SubmissionAmbiguous(err) && w.keepAmbiguousHold(ctx, queued, err) {
+		return
+	}
+
+	if !w.reachStep(ctx, models.WithdrawalStepRollback, queued) {
+		return
+	}
+
+	if rollbackErr := w.dbService.ReleaseWithdrawal(ctx, queued.UserId, queued.Asset, queued.Amount, queued.IdempotencyKey, err.Error()); rollbackErr != nil {
+		correlation.Logger(ctx, w.logger).Error("CRITICAL: Failed to release queued withdrawal - manual intervention required",
+			zap.String("queue_id", queued.Id),
+			zap.Error(rollbackErr))
+	}
+
+	if markErr := w.dbService.MarkWithdrawalFailed(ctx, queued.Id, err.Error()); markErr != nil {
+		correlation.Logger(ctx, w.logger).Error("Failed to mark queued withdrawal as failed", zap.String("queue_id", queued.Id), zap.Error(markErr))
+	}
+}
+
+// keepAmbiguousHold looks a withdrawal up at Prime by the idempotency key it was submitted under and
+// reports whether its hold must stay in place. A withdrawal Prime has is recorded as submitted and left
+// for the listener to settle. One whose absence cannot be confirmed is marked failed with its hold kept.
+func (w *WithdrawalWorker) keepAmbiguousHold(ctx context.Context, queued models.QueuedWithdrawal, err error) bool {
+	key := queued.IdempotencyKey
+	if queued.BatchId != "" {
+		key = queued.BatchId
+	}
+
+	tx, lookupErr := custody.FindWithdrawal(ctx, w.custody, w.portfolioId, queued.WalletId, key, queued.CreatedAt)
+	if lookupErr != nil {
+		correlation.Logger(ctx, w.logger).Error("Queued withdrawal not confirmed at Prime - hold kept for cmd/recover",
+			zap.String("queue_id", queued.Id),
+			zap.String("idempotency_key", key),
+			zap.Error(lookupErr))
+		if markErr := w.dbService.MarkWithdrawalFailed(ctx, queued.Id, "unconfirmed at Prime: "+err.Error()); markErr != nil {
+			correlation.Logger(ctx, w.logger).Error("Failed to mark queued withdrawal as failed", zap.String("queue_id", queued.Id), zap.Error(markErr))
+		}
+		return true
+	}
+	if tx == nil {
+		return false
+	}
+
+	correlation.Logger(ctx, w.logger).Warn("Queued withdrawal found at Prime despite failed submission - hold left for the listener",
+		zap.String("queue_id", queued.Id),
+		zap.String("idempotency_key", key),
+		zap.String("transaction_id", tx.Id))
+
+	// Prime's transaction id stands in for the activity id the lost response would have carried
+	var markErr error
+	if queued.BatchId != "" {
+		markErr = w.dbService.MarkWithdrawalBatchSubmitted(ctx, queued.BatchId, tx.Id)
+	} else {
+		markErr = w.dbService.MarkWithdrawalSubmitted(ctx, queued.Id, tx.Id)
+	}
+	if markErr != nil {
+		correlation.Logger(ctx, w.logger).Error("Withdrawal found at Prime but queue status update failed",
+			zap.String("queue_id", queued.Id),
+			zap.String("transaction_id", tx.Id),
+			zap.Error(markErr))
+		return true
+	}
+	w.markRequestsSubmitted(ctx, tx.Id, queued)
+	w.captureUnreported(ctx, tx.Id, queued)
+	return true
+}
+
+// hotWalletReady checks the source wallet can fund the withdrawals, requesting a vault top-up and
+// deferring them when it cannot. Errors are logged and the withdrawals are submitted as usual.
+func (w *WithdrawalWorker) hotWalletReady(ctx context.Context, items []models.QueuedWithdrawal) bool {
+	first := items[0]
+	total := decimal.Zero
+	for _, item := range items {
+		total = total.Add(item.Amount)
+	}
+
+	ready, err := w.treasury.EnsureHotBalance(ctx, first.WalletId, first.Asset, total)
+	if err != nil {
+		correlation.Logger(ctx, w.logger).Warn("Hot wallet balance check failed - submitting withdrawal",
+			zap.String("queue_id", first.Id),
+			zap.String("wallet_id", first.WalletId),
+			zap.Error(err))
+		return true
+	}
+	if ready {
+		return true
+	}
+
+	nextAttempt := time.Now().Add(w.treasury.TopUpRecheckInterval())
+	correlation.Logger(ctx, w.logger).Info("Hot wallet awaiting top-up - withdrawal stays queued",
+		zap.String("queue_id", first.Id),
+		zap.Int("withdrawals", len(items)),
+		zap.String("wallet_id", first.WalletId),
+		zap.String("asset", first.Asset),
+		zap.String("amount", total.String()),
+		zap.Time("next_attempt_at", nextAttempt))
+	for _, item := range items {
+		if err := w.dbService.DeferQueuedWithdrawal(ctx, item.Id, "awaiting hot wallet top-up", nextAttempt); err != nil {
+			correlation.Logger(ctx, w.logger).Error("Failed to defer queued withdrawal", zap.String("queue_id", item.Id), zap.Error(err))
+		}
+	}
+	return false
+}
+
+// drainBatched holds withdrawals until their batching window closes, then pays out each group of
+// withdrawals to the same wallet, asset and destination with a single Prime withdrawal
+func (w *WithdrawalWorker) drainBatched(ctx context.Context, limiter *time.Ticker, withdrawals []models.QueuedWithdrawal) {
+	now := time.Now()
+
+	var keys []string
+	groups := make(map[string][]models.QueuedWithdrawal)
+	for _, queued := range withdrawals {
+		// Windows are aligned to the clock so everything queued in the same window closes together
+		closesAt := queued.CreatedAt.Truncate(w.batchWindow).Add(w.batchWindow)
+		if closesAt.After(now) {
+			if err := w.dbService.DeferQueuedWithdrawal(ctx, queued.Id, "waiting for batch window", closesAt); err != nil {
+				w.logger.Error("Failed to defer queued withdrawal", zap.String("queue_id", queued.Id), zap.Error(err))
+			}
+			continue
+		}
+
+		key := strings.Join([]string{queued.WalletId, queued.AssetNetwork, queued.DestinationType, queued.Destination}, "|")
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], queued)
+	}
+
+	for _, key := range keys {
+		if !w.waitForSubmitSlot(ctx, limiter) {
+			return
+		}
+
+		group := groups[key]
+		if len(group) == 1 {
+			w.submit(ctx, group[0])
+			continue
+		}
+		w.submitBatch(ctx, group)
+	}
+}
+
+// submitBatch pays out several queued withdrawals with one Prime withdrawal. Each user's withdrawal hold
+// stays in place until the batch completes or fails; the batch record ties the Prime activity back to them.
+func (w *WithdrawalWorker) submitBatch(ctx context.Context, items []models.QueuedWithdrawal) {
+	// The batch logs under its own correlation id and lists the ids of the requests it pays out
+	ctx, _ = correlation.Ensure(ctx)
+	correlationIds := make([]string, 0, len(items))
+	for _, item := range items {
+		correlationIds = append(correlationIds, item.CorrelationId)
+	}
+
+	if w.treasury != nil && !w.hotWalletReady(ctx, items) {
+		return
+	}
+
+	batch, err := w.dbService.CreateWithdrawalBatch(ctx, items)
+	if err != nil && items[0].BatchId != "" {
+		// Prime may already have accepted the batch, so its withdrawals must not be sent on their own keys
+		nextAttempt := time.Now().Add(w.retryBackoff)
+		correlation.Logger(ctx, w.logger).Error("Failed to resubmit withdrawal batch - withdrawals stay queued",
+			zap.String("batch_id", items[0].BatchId),
+			zap.Time("next_attempt_at", nextAttempt),
+			zap.Error(err))
+		for _, item := range items {
+			if err := w.dbService.DeferQueuedWithdrawal(ctx, item.Id, "batch could not be resubmitted", nextAttempt); err != nil {
+				correlation.Logger(ctx, w.logger).Error("Failed to defer queued withdrawal", zap.String("queue_id", item.Id), zap.Error(err))
+			}
+		}
+		return
+	}
+	if err != nil {
+		correlation.Logger(ctx, w.logger).Error("Failed to create withdrawal batch - submitting individually", zap.Error(err))
+		for _, item := range items {
+			w.submit(ctx, item)
+		}
+		return
+	}
+
+	withdrawal, err := w.custody.CreateWithdrawal(ctx, models.CreateWithdrawalParams{
+		PortfolioId:     w.portfolioId,
+		WalletId:        batch.WalletId,
+		DestinationType: batch.DestinationType,
+		Destination:     batch.Destination,
+		Amount:          batch.TotalAmount.String(),
+		Asset:           batch.AssetNetwork,
+		IdempotencyKey:  batch.Id,
+	})
+	if err != nil {
+		if updateErr := w.dbService.UpdateWithdrawalBatchStatus(ctx, batch.Id, database.WithdrawalBatchStatusFailed); updateErr != nil {
+			correlation.Logger(ctx, w.logger).Error("Failed to mark withdrawal batch failed", zap.String("batch_id", batch.Id), zap.Error(updateErr))
+		}
+		for _, item := range items {
+			item.BatchId = batch.Id
+			w.handleSubmitFailure(ctx, item, err)
+		}
+		return
+	}
+
+	if err := w.dbService.MarkWithdrawalBatchSubmitted(ctx, batch.Id, withdrawal.ActivityId); err != nil {
+		correlation.Logger(ctx, w.logger).Error("Withdrawal batch submitted but queue status update failed",
+			zap.String("batch_id", batch.Id),
+			zap.String("activity_id", withdrawal.ActivityId),
+			zap.Error(err))
+		return
+	}
+	// A batch pays out for several users, so its receipt is not attributed to any one of them
+	w.markRequestsSubmitted(ctx, withdrawal.ActivityId, items...)
+	w.saveReceipt(ctx, withdrawal.Receipt(""))
+	w.captureUnreported(ctx, withdrawal.ActivityId, items...)
+
+	correlation.Logger(ctx, w.logger).Info("Withdrawal batch submitted to Prime",
+		zap.String("batch_id", batch.Id),
+		zap.String("activity_id", withdrawal.ActivityId),
+		zap.String("asset", batch.AssetNetwork),
+		zap.String("total_amount", batch.TotalAmount.String()),
+		zap.Int("withdrawals", batch.ItemCount),
+		zap.Strings("request_correlation_ids", correlationIds))
+}
+
+// queuedContext attaches the correlation id of the request that queued a withdrawal. Withdrawals queued
+// before correlation ids were recorded get a new one.
+func queuedContext(ctx context.Context, queued models.QueuedWithdrawal) context.Context {
+	if queued.CorrelationId != "" {
+		return correlation.WithId(ctx, queued.CorrelationId)
+	}
+	ctx, _ = correlation.Ensure(ctx)
+	return ctx
+}
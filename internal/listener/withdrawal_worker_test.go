@@ -0,0 +1,319 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package listener
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/api"
+	"prime-send-receive-go/internal/custody"
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/database/dbtest"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/core-go"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+var errCrash = errors.New("simulated crash")
+
+// fakeCustody accepts withdrawals the way Prime does: repeating an idempotency key returns the first
+// withdrawal instead of paying out again. Every other Provider method is left unimplemented.
+type fakeCustody struct {
+	custody.Provider
+
+	mu  sync.Mutex
+	err error
+	// lostErr is returned after the withdrawal is accepted, as when Prime's response times out
+	lostErr error
+	calls   int
+	payouts map[string]*models.Withdrawal
+}
+
+func (f *fakeCustody) CreateWithdrawal(ctx context.Context, params models.CreateWithdrawalParams) (*models.Withdrawal, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if withdrawal, ok := f.payouts[params.IdempotencyKey]; ok {
+		return withdrawal, nil
+	}
+	if f.payouts == nil {
+		f.payouts = make(map[string]*models.Withdrawal)
+	}
+	withdrawal := &models.Withdrawal{
+		ActivityId:  "activity-" + params.IdempotencyKey,
+		Asset:       params.Asset,
+		Amount:      params.Amount,
+		Destination: params.Destination,
+	}
+	f.payouts[params.IdempotencyKey] = withdrawal
+	if f.lostErr != nil {
+		return nil, f.lostErr
+	}
+	return withdrawal, nil
+}
+
+// WalletTransactions lists every accepted withdrawal under its idempotency key
+func (f *fakeCustody) WalletTransactions(ctx context.Context, portfolioId, walletId string, since time.Time, limit int) ([]models.PrimeTransaction, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var transactions []models.PrimeTransaction
+	for key := range f.payouts {
+		transactions = append(transactions, models.PrimeTransaction{Id: "tx-" + key, Type: "WITHDRAWAL", IdempotencyKey: key})
+	}
+	return transactions, nil
+}
+
+// newTestWorker returns a worker over dbService that submits to provider and gives up after maxAttempts
+func newTestWorker(t *testing.T, dbService *database.Service, provider custody.Provider, maxAttempts int, hook models.WithdrawalStepHook) *WithdrawalWorker {
+	t.Helper()
+	return NewWithdrawalWorker(WithdrawalWorkerConfig{
+		Custody:        provider,
+		DbService:      dbService,
+		PortfolioId:    "portfolio-1",
+		PollInterval:   time.Hour,
+		SubmitInterval: time.Millisecond,
+		BatchSize:      10,
+		MaxAttempts:    maxAttempts,
+		RetryBackoff:   time.Hour,
+		StepHook:       hook,
+		Logger:         zaptest.NewLogger(t),
+	})
+}
+
+// drainOnce claims and submits the due withdrawals once, as one pass of the drain loop does
+func drainOnce(w *WithdrawalWorker) {
+	limiter := time.NewTicker(time.Millisecond)
+	defer limiter.Stop()
+	w.drainBatch(context.Background(), limiter)
+}
+
+func TestWithdrawalWorker_RecoversFromCrashAtEachStep(t *testing.T) {
+	const key = "withdrawal-1"
+	amount := decimal.NewFromInt(4)
+
+	tests := []struct {
+		step models.WithdrawalStep
+		// primeErr makes every Prime call fail, so the withdrawal is rolled back once attempts run out
+		primeErr    error
+		wantQueue   string
+		wantHold    string
+		wantPayouts int
+		wantAvail   decimal.Decimal
+	}{
+		{models.WithdrawalStepReserved, nil, database.WithdrawalQueueStatusSubmitted, database.WithdrawalHoldStatusHeld, 1, decimal.NewFromInt(6)},
+		{models.WithdrawalStepSubmitted, nil, database.WithdrawalQueueStatusSubmitted, database.WithdrawalHoldStatusHeld, 1, decimal.NewFromInt(6)},
+		{models.WithdrawalStepRollback, errors.New("prime unavailable"), database.WithdrawalQueueStatusFailed, database.WithdrawalHoldStatusReleased, 0, decimal.NewFromInt(10)},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.step), func(t *testing.T) {
+			ctx := context.Background()
+			path := filepath.Join(t.TempDir(), "ledger.db")
+
+			db := dbtest.OpenFile(t, path)
+			dbtest.CreateUser(t, db, "user-1", "Alice", "alice@example.com")
+			dbtest.StoreAddress(t, db, database.StoreAddressParams{
+				UserId: "user-1", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xdeposit", WalletId: testWallet.Id,
+			})
+			dbtest.Deposit(t, db, "0xdeposit", "ETH", decimal.NewFromInt(10), "deposit-1")
+
+			_, err := api.NewLedgerService(db, zaptest.NewLogger(t)).CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+				User:           "user-1",
+				Asset:          "ETH-ethereum-mainnet",
+				Amount:         amount,
+				Destination:    "0xexternal",
+				IdempotencyKey: key,
+				Queue:          true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to queue withdrawal: %v", err)
+			}
+
+			provider := &fakeCustody{err: tt.primeErr}
+			crashed := false
+			drainOnce(newTestWorker(t, db, provider, 1, func(step models.WithdrawalStep, idempotencyKey string) error {
+				if step == tt.step {
+					crashed = true
+					return errCrash
+				}
+				return nil
+			}))
+			if !crashed {
+				t.Fatalf("Worker never reached the %s step", tt.step)
+			}
+
+			// The restarted worker opens the database afresh and sees only what the first one committed
+			db.Close()
+			db = dbtest.OpenFile(t, path)
+			restarted := newTestWorker(t, db, provider, 1, nil)
+			if err := restarted.requeueInterrupted(ctx); err != nil {
+				t.Fatalf("Failed to requeue interrupted withdrawals: %v", err)
+			}
+			drainOnce(restarted)
+
+			entries, err := db.ListQueuedWithdrawals(ctx, "", 10)
+			if err != nil || len(entries) != 1 || entries[0].Status != tt.wantQueue {
+				t.Errorf("Expected one %s queue entry, got %+v (%v)", tt.wantQueue, entries, err)
+			}
+			if len(provider.payouts) != tt.wantPayouts {
+				t.Errorf("Expected %d Prime payouts, got %d over %d calls", tt.wantPayouts, len(provider.payouts), provider.calls)
+			}
+
+			hold, err := db.GetWithdrawalHold(ctx, key)
+			if err != nil || hold == nil || hold.Status != tt.wantHold || !hold.Amount.Equal(amount) {
+				t.Errorf("Expected one %s hold of %s, got %+v (%v)", tt.wantHold, amount, hold, err)
+			}
+			// Only the hold moves the available balance; nothing is debited until Prime reports completion
+			available, err := db.GetAvailableBalance(ctx, "user-1", "ETH")
+			if err != nil || !available.Equal(tt.wantAvail) {
+				t.Errorf("Expected available balance %s, got %s (%v)", tt.wantAvail, available, err)
+			}
+			balance, err := db.GetUserBalance(ctx, "user-1", "ETH")
+			if err != nil || !balance.Equal(decimal.NewFromInt(10)) {
+				t.Errorf("Expected balance 10 with no debit, got %s (%v)", balance, err)
+			}
+		})
+	}
+}
+
+func TestWithdrawalWorker_ResubmitsInterruptedBatchUnderItsKey(t *testing.T) {
+	ctx := context.Background()
+	db := dbtest.Open(t)
+	dbtest.CreateUser(t, db, "user-1", "Alice", "alice@example.com")
+	dbtest.StoreAddress(t, db, database.StoreAddressParams{
+		UserId: "user-1", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xdeposit", WalletId: testWallet.Id,
+	})
+	dbtest.Deposit(t, db, "0xdeposit", "ETH", decimal.NewFromInt(10), "deposit-1")
+
+	ledger := api.NewLedgerService(db, zaptest.NewLogger(t))
+	queue := func(key string, amount int64) {
+		_, err := ledger.CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+			User:           "user-1",
+			Asset:          "ETH-ethereum-mainnet",
+			Amount:         decimal.NewFromInt(amount),
+			Destination:    "0xexternal",
+			IdempotencyKey: key,
+			Queue:          true,
+		})
+		if err != nil {
+			t.Fatalf("Failed to queue withdrawal %s: %v", key, err)
+		}
+	}
+	queue("withdrawal-1", 2)
+	queue("withdrawal-2", 3)
+
+	// A worker batched both and Prime accepted the batch, then the worker died before recording it
+	provider := &fakeCustody{}
+	claimed, err := db.ClaimQueuedWithdrawals(ctx, 10)
+	if err != nil {
+		t.Fatalf("ClaimQueuedWithdrawals failed: %v", err)
+	}
+	batch, err := db.CreateWithdrawalBatch(ctx, claimed)
+	if err != nil {
+		t.Fatalf("CreateWithdrawalBatch failed: %v", err)
+	}
+	if _, err := provider.CreateWithdrawal(ctx, models.CreateWithdrawalParams{Amount: "5", IdempotencyKey: batch.Id}); err != nil {
+		t.Fatalf("CreateWithdrawal failed: %v", err)
+	}
+
+	// Another withdrawal to the same destination arrives before the restart
+	queue("withdrawal-3", 1)
+	time.Sleep(5 * time.Millisecond)
+
+	restarted := newTestWorker(t, db, provider, 3, nil)
+	restarted.batchWindow = time.Millisecond
+	if err := restarted.requeueInterrupted(ctx); err != nil {
+		t.Fatalf("Failed to requeue interrupted withdrawals: %v", err)
+	}
+	drainOnce(restarted)
+
+	if _, ok := provider.payouts[batch.Id]; !ok || len(provider.payouts) != 2 {
+		t.Errorf("Expected the batch resubmitted under %s and withdrawal-3 on its own, got %v", batch.Id, provider.payouts)
+	}
+	items, err := db.ListWithdrawalBatchItems(ctx, batch.Id)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Expected the batch to keep its two withdrawals, got %+v (%v)", items, err)
+	}
+	for _, item := range items {
+		if item.Status != database.WithdrawalQueueStatusSubmitted || item.ActivityId != "activity-"+batch.Id {
+			t.Errorf("Expected %s submitted with the batch's activity, got %s/%s", item.IdempotencyKey, item.Status, item.ActivityId)
+		}
+	}
+}
+
+func TestWithdrawalWorker_KeepsHoldWhenPrimeMayHaveWithdrawal(t *testing.T) {
+	const key = "withdrawal-1"
+	amount := decimal.NewFromInt(4)
+	timeout := &core.ApiError{Message: "context deadline exceeded"}
+
+	tests := []struct {
+		name      string
+		provider  *fakeCustody
+		wantQueue string
+		wantHold  string
+	}{
+		{"accepted before timeout", &fakeCustody{lostErr: timeout}, database.WithdrawalQueueStatusSubmitted, database.WithdrawalHoldStatusHeld},
+		{"not received", &fakeCustody{err: timeout}, database.WithdrawalQueueStatusFailed, database.WithdrawalHoldStatusReleased},
+		{"rejected", &fakeCustody{err: &core.ApiError{CodeReceived: http.StatusBadRequest}}, database.WithdrawalQueueStatusFailed, database.WithdrawalHoldStatusReleased},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := dbtest.Open(t)
+			dbtest.CreateUser(t, db, "user-1", "Alice", "alice@example.com")
+			dbtest.StoreAddress(t, db, database.StoreAddressParams{
+				UserId: "user-1", Asset: "ETH", Network: "ethereum-mainnet", Address: "0xdeposit", WalletId: testWallet.Id,
+			})
+			dbtest.Deposit(t, db, "0xdeposit", "ETH", decimal.NewFromInt(10), "deposit-1")
+
+			_, err := api.NewLedgerService(db, zaptest.NewLogger(t)).CreateWithdrawalForUser(ctx, models.WithdrawalRequest{
+				User:           "user-1",
+				Asset:          "ETH-ethereum-mainnet",
+				Amount:         amount,
+				Destination:    "0xexternal",
+				IdempotencyKey: key,
+				Queue:          true,
+			})
+			if err != nil {
+				t.Fatalf("Failed to queue withdrawal: %v", err)
+			}
+
+			drainOnce(newTestWorker(t, db, tt.provider, 1, nil))
+
+			entries, err := db.ListQueuedWithdrawals(ctx, "", 10)
+			if err != nil || len(entries) != 1 || entries[0].Status != tt.wantQueue {
+				t.Errorf("Expected one %s queue entry, got %+v (%v)", tt.wantQueue, entries, err)
+			}
+			hold, err := db.GetWithdrawalHold(ctx, key)
+			if err != nil || hold == nil || hold.Status != tt.wantHold {
+				t.Errorf("Expected a %s hold, got %+v (%v)", tt.wantHold, hold, err)
+			}
+		})
+	}
+}
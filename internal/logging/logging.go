@@ -0,0 +1,93 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package logging builds the process logger. Components log through named children of it, so a
+// component's level can be raised or lowered on its own.
+package logging
+
+import (
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// New builds the production JSON logger. Entries from a logger named in cfg.Levels, or from one of
+// its children, are logged at that level; all other entries at cfg.Level.
+func New(cfg models.LogConfig) (*zap.Logger, error) {
+	zapCfg := zap.NewProductionConfig()
+	zapCfg.Level = zap.NewAtomicLevelAt(minLevel(cfg))
+
+	logger, err := zapCfg.Build()
+	if err != nil {
+		return nil, err
+	}
+	return logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewLevelCore(core, cfg)
+	})), nil
+}
+
+// NewLevelCore wraps core so each entry is filtered by the level configured for its logger name
+func NewLevelCore(core zapcore.Core, cfg models.LogConfig) zapcore.Core {
+	return &levelCore{Core: core, level: cfg.Level, levels: cfg.Levels}
+}
+
+// minLevel is the most verbose level any logger is configured for. The underlying core must let it
+// through, levelCore then drops what a logger's own level does not allow.
+func minLevel(cfg models.LogConfig) zapcore.Level {
+	level := cfg.Level
+	for _, override := range cfg.Levels {
+		if override < level {
+			level = override
+		}
+	}
+	return level
+}
+
+type levelCore struct {
+	zapcore.Core
+	level  zapcore.Level
+	levels map[string]zapcore.Level
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level, levels: c.levels}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < c.levelFor(entry.LoggerName) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
+
+// levelFor returns the level of the closest configured logger name, e.g. "listener" for
+// "listener.ledger"
+func (c *levelCore) levelFor(name string) zapcore.Level {
+	for name != "" {
+		if level, ok := c.levels[name]; ok {
+			return level
+		}
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			break
+		}
+		name = name[:dot]
+	}
+	return c.level
+}
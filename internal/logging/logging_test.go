@@ -0,0 +1,57 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package logging
+
+import (
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestLevelCore(t *testing.T) {
+	cfg := models.LogConfig{
+		Level:  zapcore.InfoLevel,
+		Levels: map[string]zapcore.Level{"listener": zapcore.DebugLevel, "database": zapcore.WarnLevel},
+	}
+	observed, logs := observer.New(minLevel(cfg))
+	logger := zap.New(NewLevelCore(observed, cfg))
+
+	logger.Named("listener").Debug("listener debug")
+	logger.Named("listener").Named("ledger").With(zap.String("k", "v")).Debug("child debug")
+	logger.Named("database").Info("database info")
+	logger.Named("database").Warn("database warn")
+	logger.Named("prime").Debug("prime debug")
+	logger.Named("prime").Info("prime info")
+
+	var got []string
+	for _, entry := range logs.All() {
+		got = append(got, entry.Message)
+	}
+	want := []string{"listener debug", "child debug", "database warn", "prime info"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	}
+}
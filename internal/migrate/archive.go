@@ -0,0 +1,398 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrate
+
+import (
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/buildinfo"
+	"prime-send-receive-go/internal/scrub"
+
+	"go.uber.org/zap"
+)
+
+// ArchiveFormat identifies a ledger state archive written by Export
+const ArchiveFormat = "prime-send-receive-state"
+
+const archiveVersion = 1
+
+// ArchiveHeader opens an archive and describes where it came from
+type ArchiveHeader struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	// ConfigHash identifies the configuration the ledger was built with, so an import can warn about a mismatch
+	ConfigHash string `json:"config_hash"`
+	// Scrubbed is set when user names and emails were replaced with pseudonyms
+	Scrubbed bool `json:"scrubbed"`
+	// Build identifies the binary that wrote the archive; archives from older builds do not have it
+	Build *buildinfo.Info `json:"build,omitempty"`
+}
+
+// ArchiveSummary closes an archive with what it holds, so an import can check it restored everything
+type ArchiveSummary struct {
+	Counts       map[string]int64 `json:"counts"`
+	Balances     Checksum         `json:"balances"`
+	Transactions Checksum         `json:"transactions"`
+}
+
+// ExportOptions controls what an archive contains
+type ExportOptions struct {
+	ConfigHash string
+	// Scrub replaces user names and emails with pseudonyms derived from the user id
+	Scrub bool
+}
+
+// ImportResult is an archive that was restored
+type ImportResult struct {
+	Header  ArchiveHeader
+	Summary ArchiveSummary
+}
+
+// archiveRecord is one line of an archive: the header, a table's columns, one of its rows, or the summary
+type archiveRecord struct {
+	Header  *ArchiveHeader  `json:"header,omitempty"`
+	Table   string          `json:"table,omitempty"`
+	Columns []Column        `json:"columns,omitempty"`
+	Values  []interface{}   `json:"values,omitempty"`
+	Summary *ArchiveSummary `json:"summary,omitempty"`
+}
+
+// scrubbedColumns are replaced with pseudonyms of the row id when exporting with Scrub
+var scrubbedColumns = map[string]map[string]func(id string) string{
+	"users": {
+		"name":  scrub.Name,
+		"email": scrub.Email,
+	},
+}
+
+// Export writes every ledger table in the source to w as a gzipped JSON Lines archive. The tables are
+// read in one transaction, so the archive is a consistent snapshot even while the listener writes.
+func (m *Migrator) Export(ctx context.Context, w io.Writer, opts ExportOptions) (*ArchiveSummary, error) {
+	tx, err := m.source.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin export transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+
+	header := ArchiveHeader{
+		Format:     ArchiveFormat,
+		Version:    archiveVersion,
+		CreatedAt:  time.Now().UTC(),
+		ConfigHash: opts.ConfigHash,
+		Scrubbed:   opts.Scrub,
+	}
+	build := buildinfo.Get()
+	header.Build = &build
+	if err := encoder.Encode(archiveRecord{Header: &header}); err != nil {
+		return nil, fmt.Errorf("unable to write archive header: %w", err)
+	}
+
+	summary := &ArchiveSummary{Counts: make(map[string]int64, len(Tables))}
+	for _, table := range Tables {
+		columns, err := m.sourceColumns(ctx, tx, table)
+		if err != nil {
+			return nil, err
+		}
+		if err := encoder.Encode(archiveRecord{Table: table, Columns: columns}); err != nil {
+			return nil, fmt.Errorf("unable to write %s columns: %w", table, err)
+		}
+
+		count, err := m.exportTable(ctx, tx, encoder, table, columns, opts.Scrub)
+		if err != nil {
+			return nil, err
+		}
+		summary.Counts[table] = count
+		m.logger.Info("Exported table", zap.String("table", table), zap.Int64("rows", count))
+	}
+
+	if summary.Balances, err = m.checksum(ctx, tx, balancesChecksumQuery); err != nil {
+		return nil, fmt.Errorf("unable to checksum balances: %w", err)
+	}
+	if summary.Transactions, err = m.checksum(ctx, tx, transactionsChecksumQuery); err != nil {
+		return nil, fmt.Errorf("unable to checksum transactions: %w", err)
+	}
+	if err := encoder.Encode(archiveRecord{Summary: summary}); err != nil {
+		return nil, fmt.Errorf("unable to write archive summary: %w", err)
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("unable to finish archive: %w", err)
+	}
+	return summary, nil
+}
+
+func (m *Migrator) exportTable(ctx context.Context, tx *sql.Tx, encoder *json.Encoder, table string, columns []Column, scrub bool) (int64, error) {
+	names := make([]string, len(columns))
+	idIndex := -1
+	for i, column := range columns {
+		names[i] = column.Name
+		if column.Name == "id" {
+			idIndex = i
+		}
+	}
+
+	scrubbers := make(map[int]func(string) string)
+	if scrub && idIndex >= 0 {
+		for i, name := range names {
+			if scrubber, ok := scrubbedColumns[table][name]; ok {
+				scrubbers[i] = scrubber
+			}
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s ORDER BY rowid", strings.Join(names, ", "), table))
+	if err != nil {
+		return 0, fmt.Errorf("unable to read %s: %w", table, err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			m.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var count int64
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return 0, fmt.Errorf("unable to scan %s row: %w", table, err)
+		}
+		for i, column := range columns {
+			values[i] = convertValue(column, values[i])
+		}
+		for i, scrubber := range scrubbers {
+			if values[i] != nil {
+				values[i] = scrubber(fmt.Sprint(values[idIndex]))
+			}
+		}
+
+		if err := encoder.Encode(archiveRecord{Values: values}); err != nil {
+			return 0, fmt.Errorf("unable to write %s row: %w", table, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error reading %s: %w", table, err)
+	}
+	return count, nil
+}
+
+// Import restores an archive written by Export into the target, whose schema must already exist and
+// whose ledger tables must be empty. The import runs in one transaction that is committed only if the
+// restored row counts and checksums match the archive's summary.
+func (m *Migrator) Import(ctx context.Context, r io.Reader) (*ImportResult, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a ledger state archive: %w", err)
+	}
+	decoder := json.NewDecoder(gz)
+	decoder.UseNumber()
+
+	tx, err := m.target.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to begin import transaction: %w", err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var header *ArchiveHeader
+	var summary *ArchiveSummary
+	var table string
+	var columns []Column
+	batch := make([]interface{}, 0)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		names := make([]string, len(columns))
+		for i, column := range columns {
+			names[i] = column.Name
+		}
+		statement := m.insertStatement(table, strings.Join(names, ", "), len(columns), len(batch)/len(columns))
+		if _, err := tx.ExecContext(ctx, statement, batch...); err != nil {
+			return fmt.Errorf("unable to insert into %s: %w", table, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		var record archiveRecord
+		err := decoder.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read archive: %w", err)
+		}
+
+		switch {
+		case header == nil:
+			if record.Header == nil || record.Header.Format != ArchiveFormat {
+				return nil, fmt.Errorf("not a ledger state archive")
+			}
+			if record.Header.Version != archiveVersion {
+				return nil, fmt.Errorf("unsupported archive version %d", record.Header.Version)
+			}
+			header = record.Header
+		case record.Summary != nil:
+			summary = record.Summary
+		case record.Table != "":
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			if err := m.checkImportTable(ctx, tx, record.Table, record.Columns); err != nil {
+				return nil, err
+			}
+			table, columns = record.Table, record.Columns
+		case record.Values != nil:
+			if table == "" || len(record.Values) != len(columns) {
+				return nil, fmt.Errorf("archive row does not match its table's columns")
+			}
+			for i, column := range columns {
+				value, err := archivedValue(column, record.Values[i])
+				if err != nil {
+					return nil, fmt.Errorf("invalid %s.%s value: %w", table, column.Name, err)
+				}
+				batch = append(batch, value)
+			}
+			if len(batch)/len(columns) >= m.batchSize {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if header == nil {
+		return nil, fmt.Errorf("archive is empty")
+	}
+	if summary == nil {
+		return nil, fmt.Errorf("archive is truncated: it has no summary")
+	}
+	problems, err := m.verifyImport(ctx, tx, summary)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("imported ledger does not match the archive: %s", strings.Join(problems, "; "))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("unable to commit import: %w", err)
+	}
+	return &ImportResult{Header: *header, Summary: *summary}, nil
+}
+
+// checkImportTable accepts only ledger tables that are empty in the target and have every archived column
+func (m *Migrator) checkImportTable(ctx context.Context, tx *sql.Tx, table string, columns []Column) error {
+	if !slices.Contains(Tables, table) {
+		return fmt.Errorf("archive contains unknown table %q", table)
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("archive has no columns for %s", table)
+	}
+
+	targetColumns, err := m.sourceColumns(ctx, tx, table)
+	if err != nil {
+		return err
+	}
+	for _, column := range columns {
+		if !slices.ContainsFunc(targetColumns, func(c Column) bool { return c.Name == column.Name }) {
+			return fmt.Errorf("target table %s has no column %s - run the archive's version of the schema first", table, column.Name)
+		}
+	}
+
+	var existing int64
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&existing); err != nil {
+		return fmt.Errorf("unable to count %s in target: %w", table, err)
+	}
+	if existing > 0 {
+		return fmt.Errorf("target table %s already has %d rows", table, existing)
+	}
+	return nil
+}
+
+// verifyImport compares what the transaction restored with the archive's summary
+func (m *Migrator) verifyImport(ctx context.Context, tx *sql.Tx, summary *ArchiveSummary) ([]string, error) {
+	var problems []string
+	for _, table := range Tables {
+		var count int64
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&count); err != nil {
+			return nil, fmt.Errorf("unable to count %s in target: %w", table, err)
+		}
+		if count != summary.Counts[table] {
+			problems = append(problems, fmt.Sprintf("%s: %d rows in archive, %d imported", table, summary.Counts[table], count))
+		}
+	}
+
+	balances, err := m.checksum(ctx, tx, balancesChecksumQuery)
+	if err != nil {
+		return nil, fmt.Errorf("unable to checksum imported balances: %w", err)
+	}
+	problems = append(problems, compareChecksums("balances", summary.Balances, balances)...)
+
+	transactions, err := m.checksum(ctx, tx, transactionsChecksumQuery)
+	if err != nil {
+		return nil, fmt.Errorf("unable to checksum imported transactions: %w", err)
+	}
+	problems = append(problems, compareChecksums("transaction amounts", summary.Transactions, transactions)...)
+	return problems, nil
+}
+
+// archivedValue converts a JSON-decoded value back to the column's type
+func archivedValue(column Column, value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case json.Number:
+		if column.Type == "BIGINT" || column.Type == "BOOLEAN" {
+			return strconv.ParseInt(v.String(), 10, 64)
+		}
+		return strconv.ParseFloat(v.String(), 64)
+	case string:
+		// Archives written before timestamps were mapped to TIMESTAMPTZ record them as TIMESTAMP
+		if column.Type == "TIMESTAMPTZ" || column.Type == "TIMESTAMP" {
+			if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+				return t, nil
+			}
+		}
+		return v, nil
+	}
+	return value, nil
+}
@@ -0,0 +1,126 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/scrub"
+
+	"go.uber.org/zap/zaptest"
+)
+
+func newLedgerDB(t *testing.T, path string, dummyUsers bool) *sql.DB {
+	t.Helper()
+	service, err := database.NewService(context.Background(), models.DatabaseConfig{
+		Path:             path,
+		MaxOpenConns:     1,
+		PingTimeout:      time.Second,
+		CreateDummyUsers: dummyUsers,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	service.Close()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestExportAndImport(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	logger := zaptest.NewLogger(t)
+
+	source := newLedgerDB(t, filepath.Join(dir, "source.db"), true)
+	var userId, email string
+	if err := source.QueryRow("SELECT id, email FROM users ORDER BY id LIMIT 1").Scan(&userId, &email); err != nil {
+		t.Fatalf("Failed to read user: %v", err)
+	}
+	seed := []string{
+		`INSERT INTO account_balances (id, user_id, asset, balance, available_balance) VALUES ('bal-1', '` + userId + `', 'ETH', 1.25, 1.25)`,
+		`INSERT INTO transactions (id, user_id, asset, transaction_type, amount, balance_before, balance_after)
+			VALUES ('tx-1', '` + userId + `', 'ETH', 'deposit', 1.25, 0, 1.25)`,
+	}
+	for _, statement := range seed {
+		if _, err := source.Exec(statement); err != nil {
+			t.Fatalf("Failed to seed source: %v", err)
+		}
+	}
+
+	var archive bytes.Buffer
+	summary, err := NewMigrator(source, nil, SQLite, 2, logger).Export(ctx, &archive, ExportOptions{ConfigHash: "abc", Scrub: true})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if summary.Counts["users"] != 3 || summary.Counts["transactions"] != 1 {
+		t.Errorf("Unexpected export counts: %v", summary.Counts)
+	}
+
+	target := newLedgerDB(t, filepath.Join(dir, "target.db"), false)
+	importer := NewMigrator(nil, target, SQLite, 2, logger)
+
+	// A truncated archive is rejected and leaves the target empty
+	if _, err := importer.Import(ctx, bytes.NewReader(archive.Bytes()[:archive.Len()/2])); err == nil {
+		t.Fatal("Expected a truncated archive to fail")
+	}
+	var users int
+	if err := target.QueryRow("SELECT COUNT(*) FROM users").Scan(&users); err != nil || users != 0 {
+		t.Fatalf("Expected no users after a failed import, got %d (%v)", users, err)
+	}
+
+	result, err := importer.Import(ctx, bytes.NewReader(archive.Bytes()))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Header.ConfigHash != "abc" || !result.Header.Scrubbed {
+		t.Errorf("Unexpected header: %+v", result.Header)
+	}
+
+	var importedEmail string
+	var balance float64
+	var createdAt time.Time
+	if err := target.QueryRow("SELECT email, created_at FROM users WHERE id = ?", userId).Scan(&importedEmail, &createdAt); err != nil {
+		t.Fatalf("Failed to read imported user: %v", err)
+	}
+	if importedEmail == email || importedEmail != scrub.Email(userId) {
+		t.Errorf("Expected a scrubbed email, got %q", importedEmail)
+	}
+	if createdAt.IsZero() {
+		t.Error("Expected created_at to survive the round trip")
+	}
+	if err := target.QueryRow("SELECT balance FROM account_balances WHERE id = 'bal-1'").Scan(&balance); err != nil || balance != 1.25 {
+		t.Errorf("Expected balance 1.25, got %v (%v)", balance, err)
+	}
+
+	// Importing twice would duplicate the ledger
+	if _, err := importer.Import(ctx, bytes.NewReader(archive.Bytes())); err == nil || !strings.Contains(err.Error(), "already has") {
+		t.Errorf("Expected Import to refuse a populated target, got %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package migrate copies the ledger from SQLite to another SQL database, such as Postgres, and
+// verifies the copy. Only database/sql is used, so the target driver is chosen by the caller.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// Tables are the ledger's core tables, copied first in this order so that rows are inserted after the
+// rows they refer to. Copy and Verify then cover every other table in the source schema as well.
+var Tables = []string{"users", "addresses", "account_balances", "transactions", "journal_entries"}
+
+// amountColumns hold decimal strings in SQLite (REAL in older databases) and become NUMERIC in the target
+var amountColumns = map[string]bool{
+	"account_balances.balance":           true,
+	"account_balances.available_balance": true,
+	"transactions.amount":                true,
+	"transactions.balance_before":        true,
+	"transactions.balance_after":         true,
+	"journal_entries.debit_amount":       true,
+	"journal_entries.credit_amount":      true,
+}
+
+// Dialect describes the target database
+type Dialect struct {
+	Name string
+	// Placeholder returns the bind parameter for the nth (1-based) value
+	Placeholder func(n int) string
+}
+
+// Postgres numbers bind parameters $1, $2, ...
+var Postgres = Dialect{Name: "postgres", Placeholder: func(n int) string { return fmt.Sprintf("$%d", n) }}
+
+// SQLite uses ? for every bind parameter
+var SQLite = Dialect{Name: "sqlite", Placeholder: func(int) string { return "?" }}
+
+// Column is a source column and the type it is created with in the target
+type Column struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	PrimaryKey bool   `json:"primary_key,omitempty"`
+}
+
+// Migrator copies tables from a SQLite source to a target database
+type Migrator struct {
+	source    *sql.DB
+	target    *sql.DB
+	dialect   Dialect
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewMigrator creates a migrator. The source must be a SQLite database.
+func NewMigrator(source, target *sql.DB, dialect Dialect, batchSize int, logger *zap.Logger) *Migrator {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	return &Migrator{source: source, target: target, dialect: dialect, batchSize: batchSize, logger: logger}
+}
+
+// Copy creates each table in the target and copies every row. A table that already has rows in
+// the target is refused, so a rerun cannot duplicate or overwrite data; drop the target tables to retry.
+// Each table is copied in one target transaction, so a failure leaves that table empty.
+func (m *Migrator) Copy(ctx context.Context) (map[string]int64, error) {
+	tables, err := m.sourceTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make(map[string]int64, len(tables))
+	for _, table := range tables {
+		columns, err := m.sourceColumns(ctx, m.source, table)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := m.target.ExecContext(ctx, createTableStatement(table, columns)); err != nil {
+			return nil, fmt.Errorf("unable to create %s in target: %w", table, err)
+		}
+
+		var existing int64
+		if err := m.target.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table).Scan(&existing); err != nil {
+			return nil, fmt.Errorf("unable to count %s in target: %w", table, err)
+		}
+		if existing > 0 {
+			return nil, fmt.Errorf("target table %s already has %d rows", table, existing)
+		}
+
+		count, err := m.copyTable(ctx, table, columns)
+		if err != nil {
+			return nil, err
+		}
+		copied[table] = count
+		m.logger.Info("Copied table", zap.String("table", table), zap.Int64("rows", count))
+	}
+	return copied, nil
+}
+
+func (m *Migrator) copyTable(ctx context.Context, table string, columns []Column) (int64, error) {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	columnList := strings.Join(names, ", ")
+
+	rows, err := m.source.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s ORDER BY rowid", columnList, table))
+	if err != nil {
+		return 0, fmt.Errorf("unable to read %s: %w", table, err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			m.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	tx, err := m.target.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("unable to begin transaction for %s: %w", table, err)
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var count int64
+	batch := make([]interface{}, 0, m.batchSize*len(columns))
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, m.insertStatement(table, columnList, len(columns), len(batch)/len(columns)), batch...); err != nil {
+			return fmt.Errorf("unable to insert into %s: %w", table, err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return 0, fmt.Errorf("unable to scan %s row: %w", table, err)
+		}
+		for i, column := range columns {
+			values[i] = convertValue(column, values[i])
+		}
+
+		batch = append(batch, values...)
+		count++
+		if count%int64(m.batchSize) == 0 {
+			if err := flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error reading %s: %w", table, err)
+	}
+	if err := flush(); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("unable to commit %s: %w", table, err)
+	}
+	return count, nil
+}
+
+func (m *Migrator) insertStatement(table, columnList string, columnCount, rowCount int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", table, columnList)
+	n := 1
+	for row := 0; row < rowCount; row++ {
+		if row > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for col := 0; col < columnCount; col++ {
+			if col > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(m.dialect.Placeholder(n))
+			n++
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// sourceTables lists every table in the source schema: Tables first, then the rest by name. Tables
+// SQLite maintains itself, such as sqlite_sequence, are left out.
+func (m *Migrator) sourceTables(ctx context.Context) ([]string, error) {
+	rows, err := m.source.QueryContext(ctx, `
+		SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list source tables: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			m.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	tables := slices.Clone(Tables)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("unable to scan source table: %w", err)
+		}
+		if !slices.Contains(Tables, name) {
+			tables = append(tables, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing source tables: %w", err)
+	}
+	return tables, nil
+}
+
+// queryer is satisfied by *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// sourceColumns reads a table's columns, including ones added by later migrations, and maps
+// their SQLite types to portable ones
+func (m *Migrator) sourceColumns(ctx context.Context, source queryer, table string) ([]Column, error) {
+	rows, err := source.QueryContext(ctx, "SELECT name, type, pk FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read columns of %s: %w", table, err)
+	}
+	defer func(rows *sql.Rows) {
+		if err := rows.Close(); err != nil {
+			m.logger.Warn("Failed to close rows", zap.Error(err))
+		}
+	}(rows)
+
+	var columns []Column
+	for rows.Next() {
+		var column Column
+		var sqliteType string
+		var pk int
+		if err := rows.Scan(&column.Name, &sqliteType, &pk); err != nil {
+			return nil, fmt.Errorf("unable to scan column of %s: %w", table, err)
+		}
+		column.Type = targetType(sqliteType)
+		if amountColumns[table+"."+column.Name] {
+			column.Type = "NUMERIC"
+		}
+		column.PrimaryKey = pk > 0
+		columns = append(columns, column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table %s does not exist in the source database", table)
+	}
+	return columns, nil
+}
+
+// targetType maps a declared SQLite type to the target column type. A REAL column becomes NUMERIC;
+// ledger amounts are mapped to NUMERIC by name since SQLite stores them as TEXT. Timestamps become
+// TIMESTAMPTZ: the SQLite driver reads them as UTC times, which a column without a zone would shift
+// to the target session's time zone.
+func targetType(sqliteType string) string {
+	switch strings.ToUpper(sqliteType) {
+	case "REAL":
+		return "NUMERIC"
+	case "INTEGER":
+		return "BIGINT"
+	case "BOOLEAN":
+		return "BOOLEAN"
+	case "TIMESTAMP":
+		return "TIMESTAMPTZ"
+	default:
+		return "TEXT"
+	}
+}
+
+// convertValue adapts a SQLite value to the target column type
+func convertValue(column Column, value interface{}) interface{} {
+	switch v := value.(type) {
+	case int64:
+		if column.Type == "BOOLEAN" {
+			return v != 0
+		}
+	case []byte:
+		if column.Type == "TEXT" || column.Type == "NUMERIC" {
+			return string(v)
+		}
+	}
+	return value
+}
+
+func createTableStatement(table string, columns []Column) string {
+	definitions := make([]string, len(columns))
+	var keys []string
+	for i, column := range columns {
+		definitions[i] = column.Name + " " + column.Type
+		if column.PrimaryKey {
+			keys = append(keys, column.Name)
+		}
+	}
+	if len(keys) > 0 {
+		definitions = append(definitions, "PRIMARY KEY ("+strings.Join(keys, ", ")+")")
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", table, strings.Join(definitions, ", "))
+}
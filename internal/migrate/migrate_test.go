@@ -0,0 +1,143 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestCopyAndVerify(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	sourcePath := filepath.Join(dir, "addresses.db")
+
+	// The source schema comes from the service, so columns added by migrations are covered
+	service, err := database.NewService(ctx, models.DatabaseConfig{
+		Path:             sourcePath,
+		MaxOpenConns:     1,
+		PingTimeout:      time.Second,
+		CreateDummyUsers: true,
+	}, zaptest.NewLogger(t))
+	if err != nil {
+		t.Fatalf("Failed to create source database: %v", err)
+	}
+	service.Close()
+
+	source, err := sql.Open("sqlite3", sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to open source: %v", err)
+	}
+	defer source.Close()
+
+	var userId string
+	if err := source.QueryRow("SELECT id FROM users LIMIT 1").Scan(&userId); err != nil {
+		t.Fatalf("Failed to read user: %v", err)
+	}
+	seed := []string{
+		`INSERT INTO addresses (id, user_id, asset, network, address, wallet_id, account_identifier)
+			VALUES ('addr-1', '` + userId + `', 'ETH', 'ethereum-mainnet', '0xabc', 'wallet-1', '0xabc')`,
+		`INSERT INTO account_balances (id, user_id, asset, balance, available_balance) VALUES ('bal-1', '` + userId + `', 'ETH', 1.25, 1.25)`,
+		`INSERT INTO account_balances (id, user_id, asset, balance, available_balance) VALUES ('bal-2', '` + userId + `', 'USDC', 0.1, 0.1)`,
+		`INSERT INTO transactions (id, user_id, asset, transaction_type, amount, balance_before, balance_after)
+			VALUES ('tx-1', '` + userId + `', 'ETH', 'deposit', 1.25, 0, 1.25)`,
+		`INSERT INTO transactions (id, user_id, asset, transaction_type, amount, balance_before, balance_after)
+			VALUES ('tx-2', '` + userId + `', 'USDC', 'deposit', 0.1, 0, 0.1)`,
+		`INSERT INTO journal_entries (id, transaction_id, account_type, account_id, debit_amount) VALUES ('je-1', 'tx-1', 'asset', 'ETH', 1.25)`,
+	}
+	for _, statement := range seed {
+		if _, err := source.Exec(statement); err != nil {
+			t.Fatalf("Failed to seed source: %v", err)
+		}
+	}
+
+	target, err := sql.Open("sqlite3", filepath.Join(dir, "target.db"))
+	if err != nil {
+		t.Fatalf("Failed to open target: %v", err)
+	}
+	defer target.Close()
+
+	migrator := NewMigrator(source, target, SQLite, 2, zaptest.NewLogger(t))
+	copied, err := migrator.Copy(ctx)
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if copied["users"] != 3 || copied["account_balances"] != 2 || copied["journal_entries"] != 1 {
+		t.Errorf("Unexpected copied counts: %v", copied)
+	}
+
+	// Every table in the source schema is copied, not just the ledger's core tables
+	var sourceTables int
+	if err := source.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%'").Scan(&sourceTables); err != nil {
+		t.Fatalf("Failed to count source tables: %v", err)
+	}
+	if _, ok := copied["withdrawal_holds"]; !ok || len(copied) != sourceTables {
+		t.Errorf("Expected all %d source tables copied, got %d: %v", sourceTables, len(copied), copied)
+	}
+
+	verification, err := migrator.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(verification.Problems) != 0 {
+		t.Fatalf("Expected a clean verification, got %v", verification.Problems)
+	}
+	if verification.SourceBalances.Digest == "" || verification.SourceBalances.Digest != verification.TargetBalances.Digest {
+		t.Errorf("Expected matching balance checksums, got %+v", verification)
+	}
+
+	// Timestamps keep their zone in the target
+	columns, err := migrator.sourceColumns(ctx, source, "transactions")
+	if err != nil {
+		t.Fatalf("Failed to read transaction columns: %v", err)
+	}
+	for _, column := range columns {
+		if column.Name == "created_at" && column.Type != "TIMESTAMPTZ" {
+			t.Errorf("Expected created_at as TIMESTAMPTZ, got %s", column.Type)
+		}
+	}
+
+	// A second run must not duplicate rows
+	if _, err := migrator.Copy(ctx); err == nil || !strings.Contains(err.Error(), "already has") {
+		t.Errorf("Expected Copy to refuse a populated target, got %v", err)
+	}
+
+	// An altered balance and missing rows are both reported
+	if _, err := target.Exec("UPDATE account_balances SET balance = 2 WHERE id = 'bal-1'"); err != nil {
+		t.Fatalf("Failed to alter target: %v", err)
+	}
+	if _, err := target.Exec("DELETE FROM journal_entries"); err != nil {
+		t.Fatalf("Failed to alter target: %v", err)
+	}
+	verification, err = migrator.Verify(ctx)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(verification.Problems) != 2 {
+		t.Errorf("Expected a count and a balance problem, got %v", verification.Problems)
+	}
+}
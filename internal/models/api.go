@@ -24,7 +24,29 @@ import (
 
 // UserBalance represents a user's balance for a specific asset
 type UserBalance struct {
-	Asset   string          `json:"asset"`
+	Asset     string          `json:"asset"`
+	Balance   decimal.Decimal `json:"balance"`
+	Available decimal.Decimal `json:"available"`
+}
+
+// UserAssetBalance is one user's balance in an asset, as returned by bulk balance lookups
+type UserAssetBalance struct {
+	UserId    string          `json:"user_id"`
+	Balance   decimal.Decimal `json:"balance"`
+	Available decimal.Decimal `json:"available"`
+}
+
+// AssetTotal aggregates user balances for one asset
+type AssetTotal struct {
+	Asset          string          `json:"asset"`
+	TotalLiability decimal.Decimal `json:"total_liability"`
+	Holders        int             `json:"holders"`
+	LargestHolders []AssetHolder   `json:"largest_holders,omitempty"`
+}
+
+// AssetHolder is one user's balance in an asset
+type AssetHolder struct {
+	UserId  string          `json:"user_id"`
 	Balance decimal.Decimal `json:"balance"`
 }
 
@@ -37,6 +59,154 @@ type TransactionRecord struct {
 	Address     string          `json:"address,omitempty"`
 	Status      string          `json:"status"`
 	ProcessedAt time.Time       `json:"processed_at"`
+	TxHash      string          `json:"tx_hash,omitempty"`
+	ExplorerUrl string          `json:"explorer_url,omitempty"`
+	// Links are the entries that reverse, debit again or refund this one, or that this one does that to
+	Links []TransactionLink `json:"links,omitempty"`
+}
+
+// Outcomes of a customer withdrawal request
+const (
+	WithdrawalSubmitted = "submitted"
+	WithdrawalQueued    = "queued"
+	// WithdrawalReplayed means the idempotency key was already used and no new withdrawal was made
+	WithdrawalReplayed = "replayed"
+)
+
+// WithdrawalRequest is a customer withdrawal addressed by the user's email or id instead of internal wallet ids
+type WithdrawalRequest struct {
+	// User is the user's email address or user id
+	User string
+	// Asset is the symbol and network, e.g. ETH-ethereum-mainnet
+	Asset           string
+	Amount          decimal.Decimal
+	DestinationType string
+	Destination     string
+	// IdempotencyKey is generated when empty
+	IdempotencyKey string
+	// Queue leaves submission to the withdrawal worker instead of calling Prime directly
+	Queue bool
+	// RefundOf is the ledger id of the deposit this withdrawal returns, recorded on the debit
+	RefundOf string
+}
+
+// WithdrawalResult is the outcome of a customer withdrawal request
+type WithdrawalResult struct {
+	Status           string          `json:"status"`
+	Asset            string          `json:"asset"`
+	Amount           decimal.Decimal `json:"amount"`
+	DestinationType  string          `json:"destination_type"`
+	Destination      string          `json:"destination"`
+	IdempotencyKey   string          `json:"idempotency_key"`
+	ActivityId       string          `json:"activity_id,omitempty"`
+	QueueId          string          `json:"queue_id,omitempty"`
+	AvailableBalance decimal.Decimal `json:"available_balance"`
+	// CorrelationId is attached to every log line of the request, for tracing it in log aggregation
+	CorrelationId string `json:"correlation_id"`
+}
+
+// RefundRequest returns a deposit to the address it was sent from
+type RefundRequest struct {
+	// DepositId is the deposit's ledger transaction id or Prime transaction id
+	DepositId string
+	// Amount is the full deposit when zero
+	Amount      decimal.Decimal
+	Reason      string
+	RequestedBy string
+	// Queue leaves submission to the withdrawal worker instead of calling Prime directly
+	Queue bool
+}
+
+// RefundResult is a refund and, once it was sent, the withdrawal that returned the funds
+type RefundResult struct {
+	Refund     *Refund
+	Withdrawal *WithdrawalResult
+}
+
+// WithdrawalCapacity previews how much of an asset a user can withdraw, for validating withdrawal forms
+type WithdrawalCapacity struct {
+	Asset     string          `json:"asset"`
+	Balance   decimal.Decimal `json:"balance"`
+	Available decimal.Decimal `json:"available"`
+	// Held is the part of the balance in unreleased deposit holds or reserved by withdrawals in flight
+	Held decimal.Decimal `json:"held"`
+	// DailyLimit and DailyLimitRemaining are omitted when the asset has no daily limit
+	DailyLimit          *decimal.Decimal `json:"daily_limit,omitempty"`
+	DailyLimitRemaining *decimal.Decimal `json:"daily_limit_remaining,omitempty"`
+	// Withdrawable is the available balance capped by the remaining daily limit
+	Withdrawable decimal.Decimal `json:"withdrawable"`
+	// EstimatedFees are averaged from recent withdrawals per network, and omitted when unknown
+	EstimatedFees []NetworkFeeEstimate `json:"estimated_fees,omitempty"`
+}
+
+// DepositInstructions tell a user how to deposit an asset on one network
+type DepositInstructions struct {
+	Asset   string `json:"asset"`
+	Network string `json:"network"`
+	Address string `json:"address"`
+	// Memo is the memo or destination tag to send with the deposit, set when the network requires one
+	Memo         string `json:"memo,omitempty"`
+	MemoRequired bool   `json:"memo_required"`
+	// MinimumDeposit is omitted when every deposit is credited
+	MinimumDeposit  *decimal.Decimal `json:"minimum_deposit,omitempty"`
+	DepositsEnabled bool             `json:"deposits_enabled"`
+	// CreditStatus is the Prime status at which deposits are credited and Availability when they can be spent
+	CreditStatus       string   `json:"credit_status"`
+	Availability       string   `json:"availability"`
+	ConfirmationPolicy string   `json:"confirmation_policy"`
+	Warnings           []string `json:"warnings"`
+}
+
+// HealthReport is the result of a health check, with one entry per dependency checked
+type HealthReport struct {
+	Healthy      bool               `json:"healthy"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+}
+
+// Add records a dependency's result; the report is unhealthy if any dependency is
+func (r *HealthReport) Add(dependency DependencyHealth) {
+	r.Dependencies = append(r.Dependencies, dependency)
+	r.Healthy = r.Healthy && dependency.Healthy
+}
+
+// DependencyHealth is the state of one dependency
+type DependencyHealth struct {
+	Name      string `json:"name"`
+	Healthy   bool   `json:"healthy"`
+	LatencyMs int64  `json:"latency_ms"`
+	Detail    string `json:"detail,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ServiceMeta describes a running deployment so clients and monitoring can check what it supports
+type ServiceMeta struct {
+	Version       string      `json:"version"`
+	Commit        string      `json:"commit"`
+	BuildDate     string      `json:"build_date"`
+	SchemaVersion int         `json:"schema_version"`
+	Features      []string    `json:"features"`
+	Assets        []MetaAsset `json:"assets"`
+}
+
+// MetaAsset is one asset/network from assets.yaml and the operations it allows
+type MetaAsset struct {
+	Symbol      string `json:"symbol"`
+	Network     string `json:"network"`
+	Deposits    bool   `json:"deposits"`
+	Withdrawals bool   `json:"withdrawals"`
+}
+
+// ProvisionResult is a user's deposit address for one asset after provisioning it on demand
+type ProvisionResult struct {
+	UserId   string `json:"user_id"`
+	Asset    string `json:"asset"`
+	Network  string `json:"network"`
+	Address  string `json:"address"`
+	WalletId string `json:"wallet_id"`
+	// AddressCreated is false when the user already had an address for the asset, which is returned instead
+	AddressCreated bool `json:"address_created"`
+	// WalletCreated means a new trading wallet was created; a running listener only monitors it after a restart
+	WalletCreated bool `json:"wallet_created"`
 }
 
 // DepositResult represents the result of processing a deposit
@@ -47,4 +217,6 @@ type DepositResult struct {
 	Amount     decimal.Decimal `json:"amount,omitempty"`
 	NewBalance decimal.Decimal `json:"new_balance,omitempty"`
 	Error      string          `json:"error,omitempty"`
+	// Err is the error behind Error, for matching database sentinels with errors.Is
+	Err error `json:"-"`
 }
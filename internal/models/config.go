@@ -16,23 +16,56 @@
 
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zapcore"
+)
 
 // Config represents the application configuration
 type Config struct {
-	Database DatabaseConfig
-	Listener ListenerConfig
+	Database        DatabaseConfig
+	Listener        ListenerConfig
+	WithdrawalQueue WithdrawalQueueConfig
+	Coordination    CoordinationConfig
+	Pricing         PricingConfig
+	Interest        InterestConfig
+	Treasury        TreasuryConfig
+	Serve           ServeConfig
+	Maintenance     MaintenanceConfig
+	Screening       ScreeningConfig
+	Anomaly         AnomalyConfig
+	Api             ApiConfig
+	Webhook         WebhookConfig
+	Custody         CustodyConfig
+	Tenant          TenantConfig
+	Wallet          WalletConfig
+	Notify          NotifyConfig
+	Explorer        ExplorerConfig
+	Log             LogConfig
+	Refund          RefundConfig
 }
 
 // DatabaseConfig holds database connection settings
 type DatabaseConfig struct {
-	Path             string
-	MaxOpenConns     int
-	MaxIdleConns     int
-	ConnMaxLifetime  time.Duration
-	ConnMaxIdleTime  time.Duration
-	PingTimeout      time.Duration
+	Path            string
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	PingTimeout     time.Duration
+	// BusyTimeout is how long a write waits for another connection's lock, e.g. during VACUUM
+	BusyTimeout time.Duration
+	// BalanceLocking is LockingOptimistic (the default) or LockingPessimistic
+	BalanceLocking string
+	// BalancePolicies overrides the negative-balance policy (sync or customer) per transaction source
+	// (api, cli, listener or backfill)
+	BalancePolicies  map[string]string
 	CreateDummyUsers bool
+	// Optional YAML file overriding the default journal account names
+	ChartOfAccountsFile string
 }
 
 // ListenerConfig holds transaction listener settings
@@ -40,5 +73,323 @@ type ListenerConfig struct {
 	LookbackWindow  time.Duration
 	PollingInterval time.Duration
 	CleanupInterval time.Duration
+	MaxConcurrency  int
 	AssetsFile      string
+	// StartupScanWindow, when positive, is how far back the listener rescans every wallet on start
+	// instead of resuming from each wallet's checkpoint
+	StartupScanWindow time.Duration
+	// FundsAvailability is the default availability policy for credited deposits (models.Availability*)
+	FundsAvailability string
+	// TransactionTypes are the Prime transaction types the listener applies; others are counted and skipped
+	TransactionTypes []string
+	// FullScanInterval, when positive, is how often each wallet is rescanned over the whole lookback
+	// window; polls in between only fetch transactions since the previous poll and open transactions
+	FullScanInterval time.Duration
+	// PageLimit is the most transactions fetched per wallet poll
+	PageLimit int
+	// IdlePollingInterval, when longer than a wallet's polling interval, is how far the interval backs
+	// off while the wallet sees no activity
+	IdlePollingInterval time.Duration
+}
+
+// WithdrawalQueueConfig holds settings for the background withdrawal worker
+type WithdrawalQueueConfig struct {
+	Enabled        bool
+	PollInterval   time.Duration
+	SubmitInterval time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	RetryBackoff   time.Duration
+	MaxPerMinute   int
+	// BatchWindow enables batching when positive: withdrawals to the same destination queued within
+	// the same window are paid out by one Prime withdrawal when it closes
+	BatchWindow time.Duration
+	// CrashAt is a crash drill: when set, the worker and cmd/withdrawal exit the process as a withdrawal
+	// reaches this saga step, leaving its state for a restart to recover. Never set it in production.
+	CrashAt WithdrawalStep
+}
+
+// CoordinationConfig selects where multi-instance coordination state is kept
+type CoordinationConfig struct {
+	Backend        string
+	RedisAddr      string
+	RedisPassword  string
+	RedisDB        int
+	RedisKeyPrefix string
+	InstanceId     string
+	WalletLeaseTTL time.Duration
+}
+
+// PricingConfig holds USD price lookup settings used for dust and AUM valuation
+type PricingConfig struct {
+	Source           string
+	CacheTTL         time.Duration
+	DustThresholdUsd decimal.Decimal
+}
+
+// InterestConfig holds settings for the daily interest accrual job
+type InterestConfig struct {
+	Enabled       bool
+	Rates         map[string]decimal.Decimal
+	MinBalance    decimal.Decimal
+	RunHour       int
+	CheckInterval time.Duration
+}
+
+// TreasuryConfig holds hot wallet thresholds, as fractions of customer liabilities held on top of
+// pending withdrawals, and the automatic vault top-up settings
+type TreasuryConfig struct {
+	MinHotRatio          decimal.Decimal
+	TargetHotRatio       decimal.Decimal
+	MaxHotRatio          decimal.Decimal
+	AutoTopUp            bool
+	TopUpRecheckInterval time.Duration
+	TopUpTimeout         time.Duration
+}
+
+// ServeConfig selects the components cmd/serve runs alongside the withdrawal worker and interest job,
+// which keep their own enable flags
+type ServeConfig struct {
+	ListenerEnabled        bool
+	ReconciliationEnabled  bool
+	ReconciliationInterval time.Duration
+	// The invariant monitor checks the transactions written since its last check every InvariantCheckInterval
+	InvariantCheckEnabled  bool
+	InvariantCheckInterval time.Duration
+	MetricsEnabled         bool
+	MetricsAddr            string
+	ApiEnabled             bool
+	GrpcEnabled            bool
+}
+
+// MaintenanceConfig holds settings for the SQLite maintenance job
+type MaintenanceConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	// VacuumFreeRatio is the fraction of free pages above which VACUUM runs; zero disables VACUUM
+	VacuumFreeRatio decimal.Decimal
+	// Retention is how long rows are kept in each purgeable table; tables without an entry are kept forever
+	Retention map[string]time.Duration
+}
+
+// ScreeningConfig holds the built-in inbound deposit screening rules
+type ScreeningConfig struct {
+	Enabled bool
+	// HoldAbove holds deposits larger than the amount configured for their asset
+	HoldAbove map[string]decimal.Decimal
+	// HoldUnknownSource holds deposits whose sending address Prime did not report
+	HoldUnknownSource bool
+	// Watchlist holds deposits sent from any of these addresses
+	Watchlist []string
+}
+
+// AnomalyConfig holds the per-user velocity anomaly detector settings. Each user's deposits and
+// withdrawals of an asset in the last Window are compared with their average per Window over the
+// Baseline before it.
+type AnomalyConfig struct {
+	Enabled  bool
+	Interval time.Duration
+	Window   time.Duration
+	Baseline time.Duration
+	// Multiplier is how many times the baseline average the window must exceed to be flagged
+	Multiplier decimal.Decimal
+	// MinCount and MinVolume keep small bursts, and first activity from new users, from being flagged.
+	// Assets without a MinVolume entry are only flagged on count.
+	MinCount  int
+	MinVolume map[string]decimal.Decimal
+}
+
+// LedgerAccount names a general ledger account used in journal entries.
+// Id may contain the placeholders {asset} and {user}.
+type LedgerAccount struct {
+	Type string `yaml:"type"`
+	Id   string `yaml:"id"`
+}
+
+// ChartOfAccounts maps each ledger role to the account journal entries are posted to
+type ChartOfAccounts struct {
+	UserAsset         LedgerAccount `yaml:"user_asset"`
+	CustomerLiability LedgerAccount `yaml:"customer_liability"`
+	Rewards           LedgerAccount `yaml:"rewards"`
+	Interest          LedgerAccount `yaml:"interest"`
+	Suspense          LedgerAccount `yaml:"suspense"`
+	SuspenseClearing  LedgerAccount `yaml:"suspense_clearing"`
+	Fees              LedgerAccount `yaml:"fees"`
+	Revenue           LedgerAccount `yaml:"revenue"`
+	Treasury          LedgerAccount `yaml:"treasury"`
+}
+
+// DefaultDepositCreditStatus is the Prime status at which deposits are credited unless an asset overrides it
+const DefaultDepositCreditStatus = "TRANSACTION_IMPORTED"
+
+// AssetConfig is an entry in assets.yaml
+type AssetConfig struct {
+	Symbol   string              `yaml:"symbol"`
+	Network  string              `yaml:"network"`
+	Listener AssetListenerConfig `yaml:"listener"`
+}
+
+// AssetListenerConfig holds optional per-asset listener and ledger settings; unset fields use the defaults
+type AssetListenerConfig struct {
+	// Enabled defaults to true; a disabled asset gets no new addresses and its wallets are not polled
+	Enabled            *bool         `yaml:"enabled"`
+	DepositsEnabled    *bool         `yaml:"deposits_enabled"`
+	WithdrawalsEnabled *bool         `yaml:"withdrawals_enabled"`
+	PollingInterval    time.Duration `yaml:"polling_interval"`
+	CreditStatus       string        `yaml:"credit_status"`
+	// Availability overrides FUNDS_AVAILABILITY for the asset: immediate, done or review
+	Availability string `yaml:"availability"`
+	// Deposits below the dust threshold are recorded as processed without crediting the user
+	DustThreshold decimal.Decimal `yaml:"-"`
+	DustAmount    string          `yaml:"dust_threshold"`
+	// MemoRequired marks networks where deposits must carry the address's account identifier as a memo or tag
+	MemoRequired bool `yaml:"memo_required"`
+}
+
+// AssetNetwork returns the SYMBOL-network key used for wallets and addresses
+func (a AssetConfig) AssetNetwork() string {
+	return a.Symbol + "-" + a.Network
+}
+
+// IsEnabled reports whether the asset is active
+func (a AssetConfig) IsEnabled() bool {
+	return a.Listener.Enabled == nil || *a.Listener.Enabled
+}
+
+// DepositsEnabled reports whether the listener credits deposits for the asset
+func (a AssetConfig) DepositsEnabled() bool {
+	return a.IsEnabled() && (a.Listener.DepositsEnabled == nil || *a.Listener.DepositsEnabled)
+}
+
+// WithdrawalsEnabled reports whether withdrawals may be created for the asset
+func (a AssetConfig) WithdrawalsEnabled() bool {
+	return a.IsEnabled() && (a.Listener.WithdrawalsEnabled == nil || *a.Listener.WithdrawalsEnabled)
+}
+
+// FundsAvailability returns the asset's availability policy, or defaultPolicy when it has none
+func (a AssetConfig) FundsAvailability(defaultPolicy string) string {
+	if a.Listener.Availability == "" {
+		return defaultPolicy
+	}
+	return a.Listener.Availability
+}
+
+// CreditStatus returns the Prime transaction status at which deposits are credited
+func (a AssetConfig) CreditStatus() string {
+	if a.Listener.CreditStatus == "" {
+		return DefaultDepositCreditStatus
+	}
+	return a.Listener.CreditStatus
+}
+
+// ApiConfig holds abuse protection settings for the client-facing HTTP API
+type ApiConfig struct {
+	// RateLimitPerIp and RateLimitPerToken are requests per minute; zero disables the limit
+	RateLimitPerIp    int
+	RateLimitPerToken int
+	// RateLimitBurst is how many requests a client may make at once before the per-minute rate applies
+	RateLimitBurst int
+	// TrustForwardedFor takes the client address from X-Forwarded-For, for servers behind a proxy
+	TrustForwardedFor bool
+	// BalanceCacheSize is how many balances the API keeps in memory; zero disables the cache
+	BalanceCacheSize int
+	// WithdrawalDailyLimits caps what one user may withdraw per UTC day, by asset symbol
+	WithdrawalDailyLimits map[string]decimal.Decimal
+	// Addr is the listen address of the REST API server
+	Addr string
+	// AdminToken authorizes operator endpoints such as creating users; they are disabled when empty
+	AdminToken string
+	// GrpcAddr is the listen address of the gRPC ledger service
+	GrpcAddr string
+	// GrpcToken authorizes every gRPC call; the gRPC service rejects all calls when it is empty
+	GrpcToken string
+}
+
+// RefundConfig holds settings for returning deposits to their sender
+type RefundConfig struct {
+	// ApprovalThresholds, by asset symbol, is the amount above which a refund needs a second person's
+	// approval; assets without a threshold are refunded without approval
+	ApprovalThresholds map[string]decimal.Decimal
+}
+
+// WebhookConfig holds settings for the inbound transaction webhook receiver
+type WebhookConfig struct {
+	Enabled bool
+	Addr    string
+	// Secret is the shared HMAC key senders sign requests with
+	Secret string
+	// Tolerance is how far a request's timestamp may be from now before it is rejected as a replay
+	Tolerance time.Duration
+}
+
+// CustodyConfig selects the custody backend the listener and withdrawal worker run against
+type CustodyConfig struct {
+	Provider string
+	// CheckEntitlements probes Prime at startup and fails if the API key lacks a required permission
+	CheckEntitlements bool
+}
+
+// CustodyProviderPrime is the Coinbase Prime custody provider and the default
+const CustodyProviderPrime = "prime"
+
+// IsCustodyProvider reports whether name is a custody provider this build can run against
+func IsCustodyProvider(name string) bool {
+	return name == CustodyProviderPrime
+}
+
+// TenantConfig scopes a process to one tenant. An empty Id sees every tenant and uses the default portfolio.
+type TenantConfig struct {
+	Id string
+}
+
+// WalletConfig holds the naming convention for the trading wallets created and recognized in Prime,
+// and when users' deposit addresses in them are created
+type WalletConfig struct {
+	// NameTemplate may contain {env} and {symbol}; empty uses the default "{symbol} Trading Wallet"
+	NameTemplate string
+	Environment  string
+	// LazyAddresses creates a user's deposit address when they first ask for deposit instructions,
+	// instead of setup and adduser creating one for every asset
+	LazyAddresses bool
+}
+
+// LogConfig sets the log level, with overrides for individual components by logger name
+// (e.g. "listener" or "database")
+type LogConfig struct {
+	Level  zapcore.Level
+	Levels map[string]zapcore.Level
+}
+
+// ExplorerConfig maps a Prime network id to a block explorer transaction URL, with {hash} standing
+// for the on-chain transaction hash
+type ExplorerConfig struct {
+	TxUrls map[string]string
+}
+
+// TxUrl returns the explorer link for a transaction, or "" when the network has no explorer configured
+func (c ExplorerConfig) TxUrl(network, hash string) string {
+	template := c.TxUrls[network]
+	if template == "" || hash == "" {
+		return ""
+	}
+	return strings.ReplaceAll(template, "{hash}", hash)
+}
+
+// NotifyConfig holds the email relay operator notifications are sent through
+type NotifyConfig struct {
+	SmtpAddr     string
+	SmtpUsername string
+	SmtpPassword string
+	EmailFrom    string
+	EmailTo      []string
+	// ProvisioningFailures sends a notification when adduser cannot create some deposit addresses
+	ProvisioningFailures bool
+	// DigestEnabled sends a summary of the previous UTC day once DigestHour (UTC) has passed
+	DigestEnabled       bool
+	DigestHour          int
+	DigestCheckInterval time.Duration
+	// WebhookUrl receives signed event posts (e.g. deposits to unrecognized addresses); empty disables them
+	WebhookUrl     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
 }
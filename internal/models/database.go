@@ -27,11 +27,13 @@ type User struct {
 	Id        string    `db:"id"`
 	Name      string    `db:"name"`
 	Email     string    `db:"email"`
+	TenantId  string    `db:"tenant_id"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
-// Address represents a user's deposit address
+// Address represents a user's deposit address.
+// Label tags the address's purpose, e.g. "primary" or "legacy", and is empty when unlabeled.
 type Address struct {
 	Id                string    `db:"id"`
 	UserId            string    `db:"user_id"`
@@ -40,15 +42,18 @@ type Address struct {
 	Address           string    `db:"address"`
 	WalletId          string    `db:"wallet_id"`
 	AccountIdentifier string    `db:"account_identifier"`
+	Label             string    `db:"label"`
 	CreatedAt         time.Time `db:"created_at"`
 }
 
-// AccountBalance represents current balance state (hot data)
+// AccountBalance represents current balance state (hot data).
+// Available is the part of Balance that may be withdrawn; held deposits are excluded.
 type AccountBalance struct {
 	Id                string          `db:"id"`
 	UserId            string          `db:"user_id"`
 	Asset             string          `db:"asset"`
 	Balance           decimal.Decimal `db:"balance"`
+	Available         decimal.Decimal `db:"available_balance"`
 	LastTransactionId string          `db:"last_transaction_id"`
 	Version           int64           `db:"version"`
 	UpdatedAt         time.Time       `db:"updated_at"`
@@ -69,4 +74,769 @@ type Transaction struct {
 	Status                string          `db:"status"`
 	CreatedAt             time.Time       `db:"created_at"`
 	ProcessedAt           time.Time       `db:"processed_at"`
+	SourceType            string          `db:"source_type"`
+	SourceAddress         string          `db:"source_address"`
+	// ReversalOf is the external id of the transaction this entry reverses, if any
+	ReversalOf string `db:"reversal_of"`
+}
+
+// TransactionLink is a ledger entry related to another one, e.g. the reversal of a withdrawal. Relation
+// reads from the entry the link belongs to: a reversal's link to its withdrawal is "reverses".
+type TransactionLink struct {
+	Relation              string          `json:"relation"`
+	TransactionId         string          `json:"transaction_id"`
+	ExternalTransactionId string          `json:"external_transaction_id"`
+	Amount                decimal.Decimal `json:"amount"`
+	CreatedAt             time.Time       `json:"created_at"`
+}
+
+// TransactionStatusEvent is one status change of a ledger entry
+type TransactionStatusEvent struct {
+	TransactionId string    `db:"transaction_id" json:"transaction_id"`
+	FromStatus    string    `db:"from_status" json:"from_status"`
+	ToStatus      string    `db:"to_status" json:"to_status"`
+	Note          string    `db:"note" json:"note,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// TransactionReceipt links a completed Prime deposit or withdrawal to its on-chain transaction
+type TransactionReceipt struct {
+	PrimeTransactionId string
+	IdempotencyKey     string
+	Network            string
+	TxHash             string
+	CompletedAt        time.Time
+}
+
+// WithdrawalReceipt is Prime's response to a submitted withdrawal, kept so it can be looked up later
+type WithdrawalReceipt struct {
+	ActivityId         string    `json:"activity_id"`
+	UserId             string    `json:"user_id"`
+	IdempotencyKey     string    `json:"idempotency_key"`
+	PrimeTransactionId string    `json:"prime_transaction_id,omitempty"`
+	Symbol             string    `json:"symbol"`
+	Asset              string    `json:"asset"`
+	Amount             string    `json:"amount"`
+	Fee                string    `json:"fee,omitempty"`
+	DestinationType    string    `json:"destination_type"`
+	Destination        string    `json:"destination"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// OperationLock is an advisory lock held by one process, typically a CLI command writing to the ledger
+type OperationLock struct {
+	Name       string
+	Owner      string
+	Operation  string
+	Pid        int
+	Hostname   string
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+}
+
+// PrimeTransactionRecord is the latest raw payload Prime reported for a transaction
+type PrimeTransactionRecord struct {
+	Id             string
+	WalletId       string
+	IdempotencyKey string
+	Type           string
+	Status         string
+	Payload        []byte
+	FirstSeenAt    time.Time
+	UpdatedAt      time.Time
+}
+
+// PrimeTransactionAck records that an operator chose to skip a Prime transaction the listener
+// could not process
+type PrimeTransactionAck struct {
+	PrimeTransactionId string
+	Reason             string
+	CreatedAt          time.Time
+}
+
+// Reasons the listener passed over a Prime transaction without applying it
+const (
+	ObservationStatus           = "status"            // not yet in the status the listener applies
+	ObservationZeroAmount       = "zero_amount"       // zero or wrongly signed amount
+	ObservationType             = "type"              // type not in LISTENER_TRANSACTION_TYPES
+	ObservationDepositsDisabled = "deposits_disabled" // deposits disabled for the asset in assets.yaml
+	ObservationDust             = "dust"              // below the asset's dust threshold
+	ObservationUnmatched        = "unmatched"         // withdrawal not matched to a user
+	ObservationAcknowledged     = "acknowledged"      // acknowledged by an operator
+)
+
+// TransactionObservation is the latest reason the listener saw a Prime transaction but did not
+// apply it. Applied reports whether the ledger has since recorded the transaction.
+type TransactionObservation struct {
+	PrimeTransactionId string
+	WalletId           string
+	Type               string
+	Status             string
+	Symbol             string
+	Network            string
+	Amount             string
+	IdempotencyKey     string
+	Reason             string
+	Detail             string
+	SeenCount          int
+	FirstSeenAt        time.Time
+	LastSeenAt         time.Time
+	Applied            bool
+}
+
+// DepositSource is the sender of a deposit as reported by Prime's transfer_from
+type DepositSource struct {
+	Type    string
+	Address string
+}
+
+// LedgerSyncEntry is a ledger transaction that should correspond to a Prime wallet transaction
+type LedgerSyncEntry struct {
+	TransactionId         string
+	UserId                string
+	Asset                 string
+	TransactionType       string
+	Amount                decimal.Decimal
+	ExternalTransactionId string
+	// BatchId and QueueStatus are set for withdrawals that went through the withdrawal queue
+	BatchId     string
+	QueueStatus string
+	CreatedAt   time.Time
+}
+
+// LedgerDigest is a deterministic hash of ledger state. Digest combines BalanceDigest and, when
+// transactions were included, TransactionDigest.
+type LedgerDigest struct {
+	Digest            string    `json:"digest"`
+	BalanceDigest     string    `json:"balance_digest"`
+	TransactionDigest string    `json:"transaction_digest,omitempty"`
+	Balances          int       `json:"balances"`
+	Transactions      int       `json:"transactions,omitempty"`
+	ComputedAt        time.Time `json:"computed_at"`
+}
+
+// RetentionTables lists the tables a retention policy can be set for, with the timestamp column that
+// decides when a row expires. Ledger tables are never purged.
+var RetentionTables = map[string]string{
+	"prime_transactions":       "updated_at",
+	"transaction_receipts":     "created_at",
+	"negative_balance_events":  "created_at",
+	"api_idempotency_keys":     "created_at",
+	"transaction_observations": "last_seen_at",
+}
+
+// MaintenanceReport summarises one database maintenance run
+type MaintenanceReport struct {
+	// WAL checkpoint result: Busy is set if readers or writers prevented a full checkpoint
+	CheckpointBusy     bool
+	WalFrames          int
+	CheckpointedFrames int
+	// Page counts before maintenance; free pages are reclaimed by VACUUM
+	PageCount     int64
+	FreelistCount int64
+	PageSize      int64
+	Vacuumed      bool
+	Analyzed      bool
+	// Purged counts the rows deleted from each table with a retention policy
+	Purged map[string]int64
+	// IntegrityProblems lists what quick_check reported, empty when the database and its indexes are healthy
+	IntegrityProblems []string
+	Duration          time.Duration
+}
+
+// QueuedWithdrawal represents a withdrawal waiting to be submitted to Prime by the background worker
+type QueuedWithdrawal struct {
+	Id              string          `db:"id"`
+	UserId          string          `db:"user_id"`
+	Asset           string          `db:"asset"`
+	AssetNetwork    string          `db:"asset_network"`
+	Amount          decimal.Decimal `db:"amount"`
+	DestinationType string          `db:"destination_type"`
+	Destination     string          `db:"destination"`
+	WalletId        string          `db:"wallet_id"`
+	IdempotencyKey  string          `db:"idempotency_key"`
+	Status          string          `db:"status"`
+	Attempts        int             `db:"attempts"`
+	LastError       string          `db:"last_error"`
+	ActivityId      string          `db:"activity_id"`
+	NextAttemptAt   time.Time       `db:"next_attempt_at"`
+	CreatedAt       time.Time       `db:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at"`
+	CorrelationId   string          `db:"correlation_id"`
+	// BatchId is set once the withdrawal is paid out as part of a batch; it is resubmitted only as that batch
+	BatchId string `db:"batch_id"`
+}
+
+// WithdrawalStep is a point in a withdrawal's saga at which a crash leaves state for recovery to resolve
+type WithdrawalStep string
+
+const (
+	// WithdrawalStepReserved is after the balance is held, before Prime is called
+	WithdrawalStepReserved WithdrawalStep = "reserved"
+	// WithdrawalStepSubmitted is after Prime accepted the withdrawal, before that is recorded
+	WithdrawalStepSubmitted WithdrawalStep = "submitted"
+	// WithdrawalStepRollback is after Prime rejected the withdrawal for good, before its hold is released
+	WithdrawalStepRollback WithdrawalStep = "rollback"
+)
+
+// IsWithdrawalStep reports whether step names a withdrawal saga step
+func IsWithdrawalStep(step string) bool {
+	switch WithdrawalStep(step) {
+	case WithdrawalStepReserved, WithdrawalStepSubmitted, WithdrawalStepRollback:
+		return true
+	}
+	return false
+}
+
+// WithdrawalStepHook is called as a withdrawal reaches each saga step. A non-nil error stops the withdrawal
+// at that step as if the process had died there; crash-recovery tests and drills use it.
+type WithdrawalStepHook func(step WithdrawalStep, idempotencyKey string) error
+
+// RewardProgram represents a promotional credit program with a fixed budget
+type RewardProgram struct {
+	Name      string          `db:"name"`
+	Asset     string          `db:"asset"`
+	Budget    decimal.Decimal `db:"budget"`
+	Granted   decimal.Decimal `db:"granted"`
+	Active    bool            `db:"active"`
+	CreatedAt time.Time       `db:"created_at"`
+}
+
+// RewardGrant represents a single reward credited to a user
+type RewardGrant struct {
+	Id            string          `db:"id"`
+	Program       string          `db:"program"`
+	UserId        string          `db:"user_id"`
+	Asset         string          `db:"asset"`
+	Amount        decimal.Decimal `db:"amount"`
+	Reference     string          `db:"reference"`
+	TransactionId string          `db:"transaction_id"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
+
+// InterestAccrual records interest credited to a user for one asset on one day
+type InterestAccrual struct {
+	Id            string          `db:"id"`
+	UserId        string          `db:"user_id"`
+	Asset         string          `db:"asset"`
+	AccrualDate   string          `db:"accrual_date"`
+	Balance       decimal.Decimal `db:"balance"`
+	AnnualRate    decimal.Decimal `db:"annual_rate"`
+	Amount        decimal.Decimal `db:"amount"`
+	TransactionId string          `db:"transaction_id"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
+
+// InactiveAccount is a user/asset account with no ledger activity since a cutoff.
+// LastActivityAt is zero when the account has no transactions at all.
+type InactiveAccount struct {
+	UserId         string
+	Asset          string
+	Balance        decimal.Decimal
+	LastActivityAt time.Time
+	UpdatedAt      time.Time
+}
+
+// ActivityReversal is the ActivityTotal type of credits that roll back failed withdrawals
+const ActivityReversal = "withdrawal_reversal"
+
+// BalanceChange is one account's balance movement between two points in time, with the transactions
+// that drove it in the order they were applied
+type BalanceChange struct {
+	UserId       string
+	Asset        string
+	Before       decimal.Decimal
+	After        decimal.Decimal
+	Net          decimal.Decimal
+	Transactions []Transaction
+}
+
+// ActivityTotal counts one transaction type in one asset over a period; amounts are absolute
+type ActivityTotal struct {
+	Type  string
+	Asset string
+	Count int
+	Total decimal.Decimal
+}
+
+// WalletActivity counts the ledger transactions of one asset through one wallet in one time bucket.
+// Volume sums absolute amounts; WalletId is empty when the wallet could not be determined.
+type WalletActivity struct {
+	BucketStart time.Time
+	WalletId    string
+	Asset       string
+	Count       int
+	Volume      decimal.Decimal
+}
+
+// DestinationActivity summarizes the ledger withdrawals sent to one destination. Volumes sums amounts per
+// asset; Users lists everyone who withdrew there, so a destination shared by several users stands out.
+type DestinationActivity struct {
+	Address   string
+	Count     int
+	Volumes   map[string]decimal.Decimal
+	Users     []DestinationUser
+	FirstSeen time.Time
+	LastSeen  time.Time
+}
+
+// DestinationUser is one user's share of the withdrawals to a destination
+type DestinationUser struct {
+	UserId string
+	Email  string
+	Count  int
+}
+
+// Ledger invariants checked by the invariant monitor
+const (
+	// InvariantBalanceArithmetic requires balance_after = balance_before + amount on every transaction
+	InvariantBalanceArithmetic = "balance_arithmetic"
+	// InvariantCustomerLiability requires the user_asset journal total of an asset to equal the
+	// system_liability total plus the operator-funded accounts posted against user credits
+	InvariantCustomerLiability = "customer_liability"
+)
+
+// JournalPosting is one side of a journal entry
+type JournalPosting struct {
+	AccountType string
+	Debit       decimal.Decimal
+	Credit      decimal.Decimal
+}
+
+// LedgerEntry is a ledger transaction and its journal postings. Sequence orders entries by when
+// they were written.
+type LedgerEntry struct {
+	Sequence      int64
+	TransactionId string
+	Asset         string
+	Type          string
+	Amount        decimal.Decimal
+	BalanceBefore decimal.Decimal
+	BalanceAfter  decimal.Decimal
+	Postings      []JournalPosting
+}
+
+// InvariantViolation is a ledger invariant that does not hold
+type InvariantViolation struct {
+	Invariant string
+	Asset     string
+	// TransactionId is the transaction that broke the invariant, or the last one checked for per-asset totals
+	TransactionId string
+	Detail        string
+}
+
+// NegativeBalanceEvent records a transaction that left an account below zero
+type NegativeBalanceEvent struct {
+	Id              string          `db:"id"`
+	UserId          string          `db:"user_id"`
+	Asset           string          `db:"asset"`
+	TransactionId   string          `db:"transaction_id"`
+	TransactionType string          `db:"transaction_type"`
+	BalanceBefore   decimal.Decimal `db:"balance_before"`
+	BalanceAfter    decimal.Decimal `db:"balance_after"`
+	Policy          string          `db:"policy"`
+	CreatedAt       time.Time       `db:"created_at"`
+}
+
+// SuspenseEntry is a deposit whose asset did not match the receiving address, held until an operator resolves it
+type SuspenseEntry struct {
+	Id                    string          `db:"id"`
+	ExternalTransactionId string          `db:"external_transaction_id"`
+	Address               string          `db:"address"`
+	AddressUserId         string          `db:"address_user_id"`
+	ExpectedAsset         string          `db:"expected_asset"`
+	ReceivedAsset         string          `db:"received_asset"`
+	Amount                decimal.Decimal `db:"amount"`
+	Status                string          `db:"status"`
+	ResolvedUserId        string          `db:"resolved_user_id"`
+	ResolutionReference   string          `db:"resolution_reference"`
+	ResolutionNote        string          `db:"resolution_note"`
+	CreatedAt             time.Time       `db:"created_at"`
+	ResolvedAt            *time.Time      `db:"resolved_at"`
+}
+
+// UnattributedDeposit is a deposit to an address no user owns, kept until an operator claims or dismisses it
+type UnattributedDeposit struct {
+	Id                    string          `db:"id" json:"id"`
+	ExternalTransactionId string          `db:"external_transaction_id" json:"external_transaction_id"`
+	Address               string          `db:"address" json:"address"`
+	Asset                 string          `db:"asset" json:"asset"`
+	Network               string          `db:"network" json:"network"`
+	Amount                decimal.Decimal `db:"amount" json:"amount"`
+	SourceType            string          `db:"source_type" json:"source_type"`
+	SourceAddress         string          `db:"source_address" json:"source_address"`
+	// Details is the Prime transaction JSON as received by the listener
+	Details        string     `db:"details" json:"details"`
+	Status         string     `db:"status" json:"status"`
+	ClaimedUserId  string     `db:"claimed_user_id" json:"claimed_user_id,omitempty"`
+	ResolutionNote string     `db:"resolution_note" json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	ResolvedAt     *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+}
+
+// ReviewCase is a finding raised for an operator to investigate, kept until it is closed
+type ReviewCase struct {
+	Id     string `db:"id" json:"id"`
+	Kind   string `db:"kind" json:"kind"`
+	UserId string `db:"user_id" json:"user_id"`
+	Asset  string `db:"asset" json:"asset"`
+	// Key identifies what the case is about, so the same finding is not opened twice while it is open
+	Key            string     `db:"case_key" json:"key"`
+	Summary        string     `db:"summary" json:"summary"`
+	Details        string     `db:"details" json:"details,omitempty"`
+	Status         string     `db:"status" json:"status"`
+	ResolutionNote string     `db:"resolution_note" json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	ResolvedAt     *time.Time `db:"resolved_at" json:"resolved_at,omitempty"`
+}
+
+// VelocityEntry is one deposit or withdrawal considered by the velocity anomaly detector
+type VelocityEntry struct {
+	UserId    string
+	Asset     string
+	Direction string
+	Amount    decimal.Decimal
+	CreatedAt time.Time
+}
+
+// VelocityAnomaly is a user's deposits or withdrawals of an asset in the detection window, compared
+// with their average per window over the baseline period
+type VelocityAnomaly struct {
+	UserId         string          `json:"user_id"`
+	Asset          string          `json:"asset"`
+	Direction      string          `json:"direction"`
+	Count          int             `json:"count"`
+	Volume         decimal.Decimal `json:"volume"`
+	BaselineCount  decimal.Decimal `json:"baseline_count"`
+	BaselineVolume decimal.Decimal `json:"baseline_volume"`
+	// Reasons lists the thresholds exceeded, "count" and/or "volume"
+	Reasons []string `json:"reasons"`
+}
+
+// Funds availability policies decide when credited deposits may be withdrawn
+const (
+	// AvailabilityImmediate makes deposits available as soon as they are credited
+	AvailabilityImmediate = "immediate"
+	// AvailabilityDone holds deposits credited before TRANSACTION_DONE until Prime reports it
+	AvailabilityDone = "done"
+	// AvailabilityReview holds every deposit until an operator releases it
+	AvailabilityReview = "review"
+)
+
+// IsAvailabilityPolicy reports whether policy is a supported funds availability policy
+func IsAvailabilityPolicy(policy string) bool {
+	return policy == AvailabilityImmediate || policy == AvailabilityDone || policy == AvailabilityReview
+}
+
+// Balance locking modes decide how concurrent updates to the same account are serialised
+const (
+	// LockingOptimistic reads the balance without locking and fails the update if its version changed
+	LockingOptimistic = "optimistic"
+	// LockingPessimistic takes the write lock when a ledger transaction begins, so concurrent
+	// updates wait for each other instead of failing
+	LockingPessimistic = "pessimistic"
+)
+
+// IsBalanceLocking reports whether mode is a supported balance locking mode
+func IsBalanceLocking(mode string) bool {
+	return mode == LockingOptimistic || mode == LockingPessimistic
+}
+
+// BalancePolicy decides how a debit that would leave an account below zero is handled
+type BalancePolicy string
+
+const (
+	// BalancePolicySync allows negative balances (historical syncs from Prime) but flags and alerts on them
+	BalancePolicySync BalancePolicy = "sync"
+
+	// BalancePolicyCustomer rejects debits that would leave the account below zero
+	BalancePolicyCustomer BalancePolicy = "customer"
+)
+
+// IsBalancePolicy reports whether policy is a supported balance policy
+func IsBalancePolicy(policy string) bool {
+	return policy == string(BalancePolicySync) || policy == string(BalancePolicyCustomer)
+}
+
+// TransactionSource is where a debit originated. Each source runs under its own BalancePolicy.
+type TransactionSource string
+
+const (
+	// TransactionSourceApi is a debit requested through the REST or gRPC API
+	TransactionSourceApi TransactionSource = "api"
+
+	// TransactionSourceCli is a debit requested by an operator command, e.g. cmd/withdrawal or cmd/refund
+	TransactionSourceCli TransactionSource = "cli"
+
+	// TransactionSourceListener is a withdrawal the listener syncs from Prime as it happens
+	TransactionSourceListener TransactionSource = "listener"
+
+	// TransactionSourceBackfill is history replayed from Prime, e.g. by cmd/bootstrap
+	TransactionSourceBackfill TransactionSource = "backfill"
+)
+
+// DefaultBalancePolicies rejects overdrafts requested through the API and CLI, and applies (and flags)
+// withdrawals synced or backfilled from Prime, since those already happened
+var DefaultBalancePolicies = map[TransactionSource]BalancePolicy{
+	TransactionSourceApi:      BalancePolicyCustomer,
+	TransactionSourceCli:      BalancePolicyCustomer,
+	TransactionSourceListener: BalancePolicySync,
+	TransactionSourceBackfill: BalancePolicySync,
+}
+
+// DepositScreening describes a deposit about to be credited, for inbound screening
+type DepositScreening struct {
+	UserId                string
+	Asset                 string
+	Network               string
+	Amount                decimal.Decimal
+	ExternalTransactionId string
+	Address               string
+	Source                DepositSource
+}
+
+// ScreeningDecision is a screener's verdict; held deposits are credited but not spendable until released
+type ScreeningDecision struct {
+	Hold   bool
+	Reason string
+}
+
+// DepositHold is a credited deposit that cannot be withdrawn until it is released, by an operator
+// (review) or by the listener once Prime reports the deposit done (settlement)
+type DepositHold struct {
+	Id                    string          `db:"id"`
+	TransactionId         string          `db:"transaction_id"`
+	ExternalTransactionId string          `db:"external_transaction_id"`
+	UserId                string          `db:"user_id"`
+	Asset                 string          `db:"asset"`
+	Amount                decimal.Decimal `db:"amount"`
+	Kind                  string          `db:"kind"`
+	Reason                string          `db:"reason"`
+	Status                string          `db:"status"`
+	ReleaseNote           string          `db:"release_note"`
+	CreatedAt             time.Time       `db:"created_at"`
+	ReleasedAt            *time.Time      `db:"released_at"`
+}
+
+// WithdrawalHold reserves a customer withdrawal's amount out of the available balance while Prime
+// processes it. It is captured onto the ledger at TRANSACTION_DONE or released if the withdrawal fails.
+type WithdrawalHold struct {
+	Id             string          `db:"id"`
+	IdempotencyKey string          `db:"idempotency_key"`
+	UserId         string          `db:"user_id"`
+	Asset          string          `db:"asset"`
+	Amount         decimal.Decimal `db:"amount"`
+	Destination    string          `db:"destination"`
+	Reference      string          `db:"reference"`
+	Status         string          `db:"status"`
+	// TransactionId is the ledger entry the hold was captured as
+	TransactionId string     `db:"transaction_id"`
+	Note          string     `db:"note"`
+	CreatedAt     time.Time  `db:"created_at"`
+	ResolvedAt    *time.Time `db:"resolved_at"`
+}
+
+// UnsentWithdrawal is a held withdrawal with no record of reaching Prime: its request was never marked
+// submitted and no worker will submit it, because it was never queued or its queue entry failed
+type UnsentWithdrawal struct {
+	IdempotencyKey  string
+	UserId          string
+	Asset           string
+	Amount          decimal.Decimal
+	DestinationType string
+	Destination     string
+	WalletId        string
+	// QueueId and BatchId are set when the withdrawal was queued, BatchId when it was paid out in a batch
+	QueueId   string
+	BatchId   string
+	CreatedAt time.Time
+}
+
+// Outcomes of recovering an unsent withdrawal
+const (
+	WithdrawalRecoveryReleased   = "released"   // Prime never received it; the hold was released
+	WithdrawalRecoverySubmitted  = "submitted"  // Prime has it; the hold is left for the listener to settle
+	WithdrawalRecoveryUnresolved = "unresolved" // Prime could not be checked; the hold is kept
+)
+
+// WithdrawalRecovery is what recovery did with one unsent withdrawal
+type WithdrawalRecovery struct {
+	Withdrawal UnsentWithdrawal
+	Outcome    string
+	// PrimeTransactionId is the withdrawal's Prime transaction when Prime has it
+	PrimeTransactionId string
+	Error              string
+}
+
+// WithdrawalRequestRecord is a customer withdrawal sent, or about to be sent, to Prime under its idempotency
+// key, and where it is in its lifecycle. The listener attributes withdrawal transactions to users through it.
+type WithdrawalRequestRecord struct {
+	IdempotencyKey string          `db:"idempotency_key" json:"idempotency_key"`
+	UserId         string          `db:"user_id" json:"user_id"`
+	Asset          string          `db:"asset" json:"asset"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	WalletId       string          `db:"wallet_id" json:"wallet_id"`
+	Destination    string          `db:"destination" json:"destination"`
+	Status         string          `db:"status" json:"status"`
+	// PrimeStatus is the last Prime transaction status the listener saw for the withdrawal
+	PrimeStatus string `db:"prime_status" json:"prime_status,omitempty"`
+	ActivityId  string `db:"activity_id" json:"activity_id,omitempty"`
+	// PrimeTransactionId is set once the listener sees the withdrawal's Prime transaction
+	PrimeTransactionId string    `db:"prime_transaction_id" json:"prime_transaction_id,omitempty"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// WithdrawalStatusEvent is one status change of a withdrawal request
+type WithdrawalStatusEvent struct {
+	FromStatus  string    `db:"from_status" json:"from_status"`
+	ToStatus    string    `db:"to_status" json:"to_status"`
+	PrimeStatus string    `db:"prime_status" json:"prime_status,omitempty"`
+	Note        string    `db:"note" json:"note,omitempty"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// ApiToken is a user-scoped API credential. Only a hash of the token is stored; Prefix identifies it in listings.
+type ApiToken struct {
+	Id         string     `db:"id"`
+	UserId     string     `db:"user_id"`
+	Prefix     string     `db:"prefix"`
+	Label      string     `db:"label"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+// IdempotentResponse is the stored response of an API request, replayed when its idempotency key is retried
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Tenant is a business entity served by the deployment. Its users are kept in their own Prime
+// portfolio; an empty PortfolioId means the default portfolio.
+type Tenant struct {
+	Id          string    `db:"id"`
+	Name        string    `db:"name"`
+	PortfolioId string    `db:"portfolio_id"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// CounterpartyMapping attributes internal transfers from a counterparty to a user
+type CounterpartyMapping struct {
+	CounterpartyId string    `db:"counterparty_id"`
+	UserId         string    `db:"user_id"`
+	Note           string    `db:"note"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+// DepositReference is an expected deposit (e.g. an invoice) matched by Prime's match reference id
+type DepositReference struct {
+	Reference            string          `db:"reference"`
+	UserId               string          `db:"user_id"`
+	Asset                string          `db:"asset"`
+	Amount               decimal.Decimal `db:"amount"`
+	Status               string          `db:"status"`
+	MatchedTransactionId string          `db:"matched_transaction_id"`
+	CreatedAt            time.Time       `db:"created_at"`
+}
+
+// InvoiceAddress is a deposit address generated for a single expected payment. Payments arriving
+// after ExpiresAt are still credited but the invoice is marked paid_late.
+type InvoiceAddress struct {
+	Reference     string          `db:"reference"`
+	UserId        string          `db:"user_id"`
+	Address       string          `db:"address"`
+	Asset         string          `db:"asset"`
+	Network       string          `db:"network"`
+	Amount        decimal.Decimal `db:"amount"`
+	ExpiresAt     time.Time       `db:"expires_at"`
+	Status        string          `db:"status"`
+	TransactionId string          `db:"transaction_id"`
+	CreatedAt     time.Time       `db:"created_at"`
+}
+
+// Expired reports whether an unpaid invoice has passed its expiry at now
+func (i InvoiceAddress) Expired(now time.Time) bool {
+	return i.Status == "open" && now.After(i.ExpiresAt)
+}
+
+// Refund is the return of a deposit to its source address. Refunds above the asset's approval
+// threshold wait in pending_approval until someone other than the requester approves them.
+type Refund struct {
+	Id                   string          `db:"id"`
+	DepositTransactionId string          `db:"deposit_transaction_id"`
+	UserId               string          `db:"user_id"`
+	Asset                string          `db:"asset"`
+	Amount               decimal.Decimal `db:"amount"`
+	Destination          string          `db:"destination"`
+	Reason               string          `db:"reason"`
+	Status               string          `db:"status"`
+	RequestedBy          string          `db:"requested_by"`
+	ApprovedBy           string          `db:"approved_by"`
+	IdempotencyKey       string          `db:"idempotency_key"`
+	LastError            string          `db:"last_error"`
+	CreatedAt            time.Time       `db:"created_at"`
+	UpdatedAt            time.Time       `db:"updated_at"`
+}
+
+// TreasuryTopUp is a vault to hot wallet transfer requested to fund queued withdrawals
+type TreasuryTopUp struct {
+	Id            string          `db:"id"`
+	Asset         string          `db:"asset"`
+	VaultWalletId string          `db:"vault_wallet_id"`
+	WalletId      string          `db:"wallet_id"`
+	Amount        decimal.Decimal `db:"amount"`
+	ActivityId    string          `db:"activity_id"`
+	Status        string          `db:"status"`
+	CreatedAt     time.Time       `db:"created_at"`
+	UpdatedAt     time.Time       `db:"updated_at"`
+}
+
+// WithdrawalBatch is a single Prime withdrawal that pays out several queued withdrawals to the same destination
+type WithdrawalBatch struct {
+	Id              string          `db:"id"`
+	Asset           string          `db:"asset"`
+	AssetNetwork    string          `db:"asset_network"`
+	WalletId        string          `db:"wallet_id"`
+	DestinationType string          `db:"destination_type"`
+	Destination     string          `db:"destination"`
+	TotalAmount     decimal.Decimal `db:"total_amount"`
+	ItemCount       int             `db:"item_count"`
+	ActivityId      string          `db:"activity_id"`
+	Status          string          `db:"status"`
+	CreatedAt       time.Time       `db:"created_at"`
+	UpdatedAt       time.Time       `db:"updated_at"`
+}
+
+// AssetLedgerStats summarises the ledger for one asset across all users
+type AssetLedgerStats struct {
+	Asset         string
+	TotalBalance  decimal.Decimal
+	Holders       int
+	DepositCount  int
+	LastDepositAt *time.Time
+}
+
+// CommandCheckpoint records how far an interrupted batch command got so that it can resume
+type CommandCheckpoint struct {
+	Command   string
+	UserId    string
+	Asset     string
+	WalletId  string
+	Processed int
+	UpdatedAt time.Time
+}
+
+// ListenerCheckpoint records where the listener got to in a wallet's transactions, so that a restart
+// resumes there instead of re-scanning the whole lookback window. Zero times mean nothing was seen.
+type ListenerCheckpoint struct {
+	WalletId      string
+	PolledThrough time.Time
+	// LastSeenTxTime and LastSeenTxId identify the newest transaction fetched from the wallet
+	LastSeenTxTime time.Time
+	LastSeenTxId   string
+	// OldestOpenTxTime is the created_at of the oldest transaction that was still open or failed to apply
+	OldestOpenTxTime time.Time
 }
@@ -16,7 +16,11 @@
 
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
 
 // WalletInfo represents a trading wallet we monitor for deposits
 type WalletInfo struct {
@@ -42,8 +46,76 @@ type PrimeTransaction struct {
 	Amount         string            `json:"amount"`
 	CreatedAt      time.Time         `json:"created_at"`
 	CompletedAt    time.Time         `json:"completed_at"`
+	TransferFrom   PrimeTransferInfo `json:"transfer_from"`
 	TransferTo     PrimeTransferInfo `json:"transfer_to"`
 	TransactionId  string            `json:"transaction_id"`
 	Network        string            `json:"network"`
 	IdempotencyKey string            `json:"idempotency_key"`
+	MatchReference string            `json:"match_reference"`
+	BlockchainIds  []string          `json:"blockchain_ids"`
+
+	// Raw is the transaction JSON as received from Prime, kept for forensics
+	Raw []byte `json:"-"`
+
+	// Direction and SignedAmount are derived from Type and Amount by prime.NormalizeAmount. SignedAmount
+	// is the effect on the wallet: positive for inbound transactions, negative for outbound ones.
+	Direction    TransferDirection `json:"-"`
+	SignedAmount decimal.Decimal   `json:"-"`
+}
+
+// TxHash returns the on-chain transaction hash, or "" for transactions that have not been broadcast
+// or never go on-chain (e.g. internal transfers)
+func (t PrimeTransaction) TxHash() string {
+	if len(t.BlockchainIds) == 0 {
+		return ""
+	}
+	return t.BlockchainIds[0]
+}
+
+// TransferDirection is which way a transaction moves funds relative to the wallet
+type TransferDirection string
+
+const (
+	DirectionInbound  TransferDirection = "inbound"
+	DirectionOutbound TransferDirection = "outbound"
+	// DirectionUnknown is used for transaction types that do not simply move funds in or out
+	DirectionUnknown TransferDirection = ""
+)
+
+// transactionDirections maps Prime transaction types to the way they move funds. Types not listed
+// (staking operations, conversions, TRANSACTION_TYPE_OTHER) have no known direction.
+var transactionDirections = map[string]TransferDirection{
+	"DEPOSIT":               DirectionInbound,
+	"INTERNAL_DEPOSIT":      DirectionInbound,
+	"SWEEP_DEPOSIT":         DirectionInbound,
+	"PROXY_DEPOSIT":         DirectionInbound,
+	"COINBASE_DEPOSIT":      DirectionInbound,
+	"DEPOSIT_ADJUSTMENT":    DirectionInbound,
+	"COINBASE_REFUND":       DirectionInbound,
+	"REWARD":                DirectionInbound,
+	"WITHDRAWAL":            DirectionOutbound,
+	"INTERNAL_WITHDRAWAL":   DirectionOutbound,
+	"SWEEP_WITHDRAWAL":      DirectionOutbound,
+	"PROXY_WITHDRAWAL":      DirectionOutbound,
+	"BILLING_WITHDRAWAL":    DirectionOutbound,
+	"WITHDRAWAL_ADJUSTMENT": DirectionOutbound,
+	"SLASH":                 DirectionOutbound,
+}
+
+// TransactionDirection returns which way a Prime transaction type moves funds
+func TransactionDirection(txType string) TransferDirection {
+	return transactionDirections[txType]
+}
+
+// CreateWithdrawalParams contains parameters for creating a withdrawal with a custody provider
+type CreateWithdrawalParams struct {
+	PortfolioId string
+	WalletId    string
+	// DestinationType is one of prime.DestinationTypes; empty means an on-chain address
+	DestinationType string
+	// Destination is the blockchain address, payment method id, wallet id or counterparty id
+	Destination    string
+	Amount         string
+	Asset          string
+	IdempotencyKey string
 }
@@ -16,10 +16,13 @@
 
 package models
 
+import "github.com/shopspring/decimal"
+
 // Portfolio represents a Prime portfolio
 type Portfolio struct {
-	Id   string
-	Name string
+	Id       string
+	Name     string
+	EntityId string
 }
 
 // Wallet represents a Prime wallet
@@ -40,9 +43,87 @@ type DepositAddress struct {
 
 // Withdrawal represents a Prime withdrawal transaction
 type Withdrawal struct {
-	ActivityId     string
-	Asset          string
-	Amount         string
-	Destination    string
-	IdempotencyKey string
+	ActivityId      string
+	Asset           string
+	Amount          string
+	DestinationType string
+	Destination     string
+	IdempotencyKey  string
+	Symbol          string
+	Fee             string
+	TransactionId   string
+}
+
+// Receipt returns the withdrawal as a receipt for userId
+func (w Withdrawal) Receipt(userId string) WithdrawalReceipt {
+	return WithdrawalReceipt{
+		ActivityId:         w.ActivityId,
+		UserId:             userId,
+		IdempotencyKey:     w.IdempotencyKey,
+		PrimeTransactionId: w.TransactionId,
+		Symbol:             w.Symbol,
+		Asset:              w.Asset,
+		Amount:             w.Amount,
+		Fee:                w.Fee,
+		DestinationType:    w.DestinationType,
+		Destination:        w.Destination,
+	}
+}
+
+// PortfolioBalance represents a Prime portfolio balance for one symbol
+type PortfolioBalance struct {
+	Symbol       string
+	Amount       decimal.Decimal
+	Holds        decimal.Decimal
+	Withdrawable decimal.Decimal
+}
+
+// AssetMetadata is Prime's description of an asset and the networks it supports
+type AssetMetadata struct {
+	Name             string
+	Symbol           string
+	DecimalPrecision string
+	ExplorerUrl      string
+	Networks         []AssetNetwork
+}
+
+// AssetNetwork is one network an asset can be sent and received on
+type AssetNetwork struct {
+	Id                     string
+	Name                   string
+	Type                   string
+	MaxDecimals            string
+	Default                bool
+	VaultSupported         bool
+	DestinationTagRequired bool
+}
+
+// NetworkFeeEstimate summarises the network fees paid by recent withdrawals on one network
+type NetworkFeeEstimate struct {
+	Network   string          `json:"network"`
+	FeeSymbol string          `json:"fee_symbol"`
+	Samples   int             `json:"samples"`
+	Average   decimal.Decimal `json:"average"`
+	Max       decimal.Decimal `json:"max"`
+}
+
+// Address verification outcomes
+const (
+	AddressVerified           = "VERIFIED"
+	AddressMissingInPrime     = "ADDRESS_MISSING"
+	AddressWalletAssetDrift   = "WALLET_ASSET_MISMATCH"
+	AddressWalletNotFound     = "WALLET_NOT_FOUND"
+	AddressVerificationFailed = "CHECK_FAILED"
+)
+
+// AddressVerification is the result of checking a stored deposit address against Prime
+type AddressVerification struct {
+	Address Address
+	Status  string
+	Detail  string
+}
+
+// Drifted reports whether Prime disagrees with the stored address
+func (v AddressVerification) Drifted() bool {
+	return v.Status != AddressVerified && v.Status != AddressVerificationFailed
 }
@@ -0,0 +1,130 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// Digest summarises one UTC day for the daily notification digest
+type Digest struct {
+	// Date is the start of the day the digest covers
+	Date     time.Time
+	Activity []models.ActivityTotal
+	// NegativeBalances is the number of accounts below zero when the digest was built
+	NegativeBalances int
+	// Reconciliation is the last in-process reconciliation run, nil when none has completed
+	Reconciliation *DigestReconciliation
+	// Exposure lists treasury positions; ExposureError is set when they could not be loaded
+	Exposure      []DigestExposure
+	ExposureError string
+}
+
+// DigestReconciliation is the outcome of a reconciliation run
+type DigestReconciliation struct {
+	CompletedAt time.Time
+	Checked     int
+	Mismatches  int
+}
+
+// DigestExposure is one asset's treasury position
+type DigestExposure struct {
+	Asset          string
+	HotBalance     decimal.Decimal
+	Liabilities    decimal.Decimal
+	NetExposure    decimal.Decimal
+	Recommendation string
+}
+
+// Message renders the digest as a plain text notification. The subject is flagged when withdrawals
+// were rolled back, accounts are negative or reconciliation found mismatches.
+func (d Digest) Message() Message {
+	day := d.Date.Format("2006-01-02")
+	var body strings.Builder
+	fmt.Fprintf(&body, "Daily summary for %s (UTC)\n", day)
+
+	body.WriteString("\nActivity\n")
+	writeTotals(&body, d.activity("deposit"), "Deposits")
+	writeTotals(&body, d.activity("withdrawal"), "Withdrawals")
+	for _, total := range d.Activity {
+		switch total.Type {
+		case "deposit", "withdrawal", models.ActivityReversal:
+		default:
+			fmt.Fprintf(&body, "  %s %s: %d totalling %s\n", total.Type, total.Asset, total.Count, total.Total.String())
+		}
+	}
+
+	reversals := d.activity(models.ActivityReversal)
+	body.WriteString("\nFailures\n")
+	writeTotals(&body, reversals, "Withdrawals rolled back")
+	fmt.Fprintf(&body, "  Negative balances: %d\n", d.NegativeBalances)
+
+	body.WriteString("\nReconciliation\n")
+	if d.Reconciliation == nil {
+		body.WriteString("  No reconciliation run has completed in this process\n")
+	} else {
+		fmt.Fprintf(&body, "  Last run %s: %d balances checked, %d mismatches\n",
+			d.Reconciliation.CompletedAt.Format(time.RFC3339), d.Reconciliation.Checked, d.Reconciliation.Mismatches)
+	}
+
+	body.WriteString("\nTreasury exposure\n")
+	switch {
+	case d.ExposureError != "":
+		fmt.Fprintf(&body, "  Unavailable: %s\n", d.ExposureError)
+	case len(d.Exposure) == 0:
+		body.WriteString("  No positions\n")
+	}
+	for _, position := range d.Exposure {
+		fmt.Fprintf(&body, "  %s: hot %s, liabilities %s, net %s (%s)\n", position.Asset,
+			position.HotBalance.String(), position.Liabilities.String(), position.NetExposure.String(), position.Recommendation)
+	}
+
+	subject := fmt.Sprintf("Daily digest %s", day)
+	if len(reversals) > 0 || d.NegativeBalances > 0 || (d.Reconciliation != nil && d.Reconciliation.Mismatches > 0) {
+		subject += " - needs attention"
+	}
+	return Message{Subject: subject, Body: body.String()}
+}
+
+// activity returns the totals of one transaction type
+func (d Digest) activity(transactionType string) []models.ActivityTotal {
+	var totals []models.ActivityTotal
+	for _, total := range d.Activity {
+		if total.Type == transactionType {
+			totals = append(totals, total)
+		}
+	}
+	return totals
+}
+
+// writeTotals lists per-asset totals under a label, or "none" when there were none
+func writeTotals(body *strings.Builder, totals []models.ActivityTotal, label string) {
+	if len(totals) == 0 {
+		fmt.Fprintf(body, "  %s: none\n", label)
+		return
+	}
+	fmt.Fprintf(body, "  %s:\n", label)
+	for _, total := range totals {
+		fmt.Fprintf(body, "    %s: %d totalling %s\n", total.Asset, total.Count, total.Total.String())
+	}
+}
@@ -0,0 +1,78 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDigestMessage(t *testing.T) {
+	date := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	quiet := Digest{Date: date}.Message()
+	if quiet.Subject != "Daily digest 2025-03-01" {
+		t.Errorf("Unexpected subject: %s", quiet.Subject)
+	}
+	for _, want := range []string{
+		"Deposits: none",
+		"Withdrawals: none",
+		"Withdrawals rolled back: none",
+		"Negative balances: 0",
+		"No reconciliation run has completed",
+		"No positions",
+	} {
+		if !strings.Contains(quiet.Body, want) {
+			t.Errorf("Expected body to contain %q, got:\n%s", want, quiet.Body)
+		}
+	}
+
+	busy := Digest{
+		Date: date,
+		Activity: []models.ActivityTotal{
+			{Type: "deposit", Asset: "ETH", Count: 2, Total: decimal.RequireFromString("6.5")},
+			{Type: "withdrawal", Asset: "ETH", Count: 1, Total: decimal.RequireFromString("2")},
+			{Type: models.ActivityReversal, Asset: "ETH", Count: 1, Total: decimal.RequireFromString("2")},
+		},
+		Reconciliation: &DigestReconciliation{CompletedAt: date, Checked: 10},
+		ExposureError:  "prime unavailable",
+	}.Message()
+	if busy.Subject != "Daily digest 2025-03-01 - needs attention" {
+		t.Errorf("Expected a reversal to flag the subject, got %s", busy.Subject)
+	}
+	for _, want := range []string{
+		"Deposits:\n    ETH: 2 totalling 6.5",
+		"Withdrawals:\n    ETH: 1 totalling 2",
+		"Withdrawals rolled back:\n    ETH: 1 totalling 2",
+		"10 balances checked, 0 mismatches",
+		"Unavailable: prime unavailable",
+	} {
+		if !strings.Contains(busy.Body, want) {
+			t.Errorf("Expected body to contain %q, got:\n%s", want, busy.Body)
+		}
+	}
+
+	mismatched := Digest{Date: date, Reconciliation: &DigestReconciliation{CompletedAt: date, Mismatches: 1}}.Message()
+	if !strings.HasSuffix(mismatched.Subject, "needs attention") {
+		t.Errorf("Expected mismatches to flag the subject, got %s", mismatched.Subject)
+	}
+}
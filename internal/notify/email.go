@@ -0,0 +1,95 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package notify sends operator notifications for problems that need a person to act on them
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// Message is an operator notification
+type Message struct {
+	Subject string
+	Body    string
+}
+
+// Notifier delivers operator notifications
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// EmailNotifier sends notifications by email through an SMTP relay
+type EmailNotifier struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewNotifier returns the notifier selected by configuration, or nil when notifications are not configured
+func NewNotifier(cfg models.NotifyConfig) (Notifier, error) {
+	if cfg.SmtpAddr == "" && len(cfg.EmailTo) == 0 {
+		return nil, nil
+	}
+	if cfg.SmtpAddr == "" || cfg.EmailFrom == "" || len(cfg.EmailTo) == 0 {
+		return nil, fmt.Errorf("email notifications need NOTIFY_SMTP_ADDR, NOTIFY_EMAIL_FROM and NOTIFY_EMAIL_TO")
+	}
+
+	host, _, err := net.SplitHostPort(cfg.SmtpAddr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NOTIFY_SMTP_ADDR %q: %w", cfg.SmtpAddr, err)
+	}
+
+	notifier := &EmailNotifier{addr: cfg.SmtpAddr, from: cfg.EmailFrom, to: cfg.EmailTo}
+	if cfg.SmtpUsername != "" {
+		notifier.auth = smtp.PlainAuth("", cfg.SmtpUsername, cfg.SmtpPassword, host)
+	}
+	return notifier, nil
+}
+
+// Notify sends the message to every configured recipient
+func (n *EmailNotifier) Notify(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := smtp.SendMail(n.addr, n.auth, n.from, n.to, buildEmail(n.from, n.to, message, time.Now())); err != nil {
+		return fmt.Errorf("unable to send notification email: %w", err)
+	}
+	return nil
+}
+
+// buildEmail renders a plain text email with the headers SMTP relays expect
+func buildEmail(from string, to []string, message Message, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
@@ -0,0 +1,66 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestNewNotifier(t *testing.T) {
+	notifier, err := NewNotifier(models.NotifyConfig{})
+	if err != nil || notifier != nil {
+		t.Errorf("Expected no notifier when unconfigured, got %v, %v", notifier, err)
+	}
+
+	if _, err := NewNotifier(models.NotifyConfig{SmtpAddr: "smtp.example.com:587"}); err == nil {
+		t.Error("Expected an error without sender and recipients")
+	}
+	if _, err := NewNotifier(models.NotifyConfig{SmtpAddr: "smtp.example.com", EmailFrom: "a@example.com", EmailTo: []string{"b@example.com"}}); err == nil {
+		t.Error("Expected an error for an address without a port")
+	}
+
+	notifier, err = NewNotifier(models.NotifyConfig{
+		SmtpAddr:  "smtp.example.com:587",
+		EmailFrom: "ledger@example.com",
+		EmailTo:   []string{"ops@example.com"},
+	})
+	if err != nil || notifier == nil {
+		t.Errorf("Expected an email notifier, got %v, %v", notifier, err)
+	}
+}
+
+func TestBuildEmail(t *testing.T) {
+	email := string(buildEmail("ledger@example.com", []string{"ops@example.com", "oncall@example.com"},
+		Message{Subject: "Provisioning failed", Body: "line one\nline two"},
+		time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)))
+
+	for _, want := range []string{
+		"From: ledger@example.com\r\n",
+		"To: ops@example.com, oncall@example.com\r\n",
+		"Subject: Provisioning failed\r\n",
+		"Date: Sat, 01 Mar 2025 12:00:00 +0000\r\n",
+		"\r\n\r\nline one\r\nline two",
+	} {
+		if !strings.Contains(email, want) {
+			t.Errorf("Expected email to contain %q, got:\n%s", want, email)
+		}
+	}
+}
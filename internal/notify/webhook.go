@@ -0,0 +1,114 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Headers on outbound webhook posts, matching what the inbound receiver in cmd/serve expects
+const (
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// EventUnattributedDeposit is posted when a deposit arrives at an address no user owns
+const EventUnattributedDeposit = "deposit.unattributed"
+
+// EventReviewCaseOpened is posted when a detector opens a review case, e.g. for unusual withdrawal velocity
+const EventReviewCaseOpened = "review_case.opened"
+
+// Event is the JSON body of an outbound webhook post
+type Event struct {
+	Id        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookPoster posts signed events to an operator endpoint
+type WebhookPoster struct {
+	url    string
+	secret []byte
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewWebhookPoster returns a poster for the configured endpoint, or nil when NOTIFY_WEBHOOK_URL is not set
+func NewWebhookPoster(cfg models.NotifyConfig) *WebhookPoster {
+	if cfg.WebhookUrl == "" {
+		return nil
+	}
+	return &WebhookPoster{
+		url:    cfg.WebhookUrl,
+		secret: []byte(cfg.WebhookSecret),
+		client: &http.Client{Timeout: cfg.WebhookTimeout},
+		now:    time.Now,
+	}
+}
+
+// Post sends an event. The body is signed as hex(HMAC-SHA256(secret, "<timestamp>.<body>")) so the
+// receiver can verify it came from this service. Any non-2xx response is an error.
+func (p *WebhookPoster) Post(ctx context.Context, eventType string, data interface{}) error {
+	now := p.now().UTC()
+	body, err := json.Marshal(Event{Id: uuid.New().String(), Type: eventType, CreatedAt: now, Data: data})
+	if err != nil {
+		return fmt.Errorf("unable to encode webhook event: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("unable to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, sign(p.secret, timestamp, body))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to post webhook event: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
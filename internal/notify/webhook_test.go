@@ -0,0 +1,68 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestNewWebhookPoster(t *testing.T) {
+	if poster := NewWebhookPoster(models.NotifyConfig{}); poster != nil {
+		t.Errorf("Expected no poster when unconfigured, got %v", poster)
+	}
+}
+
+func TestWebhookPoster_Post(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp := r.Header.Get(WebhookTimestampHeader)
+		if r.Header.Get(WebhookSignatureHeader) != sign([]byte(secret), timestamp, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := json.Unmarshal(body, &received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	poster := NewWebhookPoster(models.NotifyConfig{WebhookUrl: server.URL, WebhookSecret: secret, WebhookTimeout: time.Second})
+	if err := poster.Post(context.Background(), EventUnattributedDeposit, map[string]string{"address": "0xunknown"}); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if received.Type != EventUnattributedDeposit || received.Id == "" {
+		t.Errorf("Unexpected event: %+v", received)
+	}
+
+	// A receiver with a different secret rejects the post, which is reported as an error
+	poster = NewWebhookPoster(models.NotifyConfig{WebhookUrl: server.URL, WebhookSecret: "wrong", WebhookTimeout: time.Second})
+	if err := poster.Post(context.Background(), EventUnattributedDeposit, nil); err == nil {
+		t.Error("Expected an error for a rejected post")
+	}
+}
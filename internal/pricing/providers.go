@@ -0,0 +1,137 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Provider returns the USD spot price for an asset symbol
+type Provider interface {
+	Name() string
+	GetUsdPrice(ctx context.Context, symbol string) (decimal.Decimal, error)
+}
+
+// stablecoins are valued at par without calling a price provider
+var stablecoins = map[string]bool{
+	"USD":   true,
+	"USDC":  true,
+	"PYUSD": true,
+}
+
+// ExchangeProvider reads prices from the public Coinbase Exchange ticker endpoint
+type ExchangeProvider struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+// NewExchangeProvider creates a Coinbase Exchange price provider
+func NewExchangeProvider(httpClient *http.Client) *ExchangeProvider {
+	return &ExchangeProvider{
+		baseUrl:    "https://api.exchange.coinbase.com",
+		httpClient: httpClient,
+	}
+}
+
+func (p *ExchangeProvider) Name() string {
+	return SourceExchange
+}
+
+func (p *ExchangeProvider) GetUsdPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	var response struct {
+		Price string `json:"price"`
+	}
+	url := fmt.Sprintf("%s/products/%s-USD/ticker", p.baseUrl, strings.ToUpper(symbol))
+	if err := getJson(ctx, p.httpClient, url, &response); err != nil {
+		return decimal.Zero, err
+	}
+	return parsePrice(response.Price)
+}
+
+// AdvancedTradeProvider reads prices from the public Coinbase Advanced Trade market endpoint
+type AdvancedTradeProvider struct {
+	baseUrl    string
+	httpClient *http.Client
+}
+
+// NewAdvancedTradeProvider creates a Coinbase Advanced Trade price provider
+func NewAdvancedTradeProvider(httpClient *http.Client) *AdvancedTradeProvider {
+	return &AdvancedTradeProvider{
+		baseUrl:    "https://api.coinbase.com/api/v3/brokerage/market",
+		httpClient: httpClient,
+	}
+}
+
+func (p *AdvancedTradeProvider) Name() string {
+	return SourceAdvancedTrade
+}
+
+func (p *AdvancedTradeProvider) GetUsdPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	var response struct {
+		Price string `json:"price"`
+	}
+	url := fmt.Sprintf("%s/products/%s-USD", p.baseUrl, strings.ToUpper(symbol))
+	if err := getJson(ctx, p.httpClient, url, &response); err != nil {
+		return decimal.Zero, err
+	}
+	return parsePrice(response.Price)
+}
+
+func getJson(ctx context.Context, httpClient *http.Client, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("unable to build price request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("price request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("price request to %s returned status %d", url, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("unable to decode price response: %w", err)
+	}
+	return nil
+}
+
+func parsePrice(price string) (decimal.Decimal, error) {
+	if price == "" {
+		return decimal.Zero, fmt.Errorf("price missing from response")
+	}
+	parsed, err := decimal.NewFromString(price)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("invalid price %q: %w", price, err)
+	}
+	return parsed, nil
+}
+
+func newHttpClient() *http.Client {
+	return &http.Client{Timeout: 10 * time.Second}
+}
@@ -0,0 +1,146 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Price sources accepted by --price-source and PRICE_SOURCE
+const (
+	SourceAuto          = "auto"
+	SourceExchange      = "exchange"
+	SourceAdvancedTrade = "advanced-trade"
+)
+
+type cachedPrice struct {
+	price     decimal.Decimal
+	fetchedAt time.Time
+}
+
+// Service converts asset amounts to USD using an ordered list of providers with caching
+type Service struct {
+	providers []Provider
+	cacheTTL  time.Duration
+	logger    *zap.Logger
+
+	mutex sync.Mutex
+	cache map[string]cachedPrice
+}
+
+// NewService creates a pricing service for the given source. "auto" tries Coinbase Exchange
+// first and falls back to Advanced Trade.
+func NewService(source string, cacheTTL time.Duration, logger *zap.Logger) (*Service, error) {
+	httpClient := newHttpClient()
+
+	var providers []Provider
+	switch source {
+	case "", SourceAuto:
+		providers = []Provider{NewExchangeProvider(httpClient), NewAdvancedTradeProvider(httpClient)}
+	case SourceExchange:
+		providers = []Provider{NewExchangeProvider(httpClient)}
+	case SourceAdvancedTrade:
+		providers = []Provider{NewAdvancedTradeProvider(httpClient)}
+	default:
+		return nil, fmt.Errorf("unsupported price source: %q (expected %s, %s or %s)",
+			source, SourceAuto, SourceExchange, SourceAdvancedTrade)
+	}
+
+	return NewServiceWithProviders(providers, cacheTTL, logger), nil
+}
+
+// NewServiceWithProviders creates a pricing service with an explicit provider chain
+func NewServiceWithProviders(providers []Provider, cacheTTL time.Duration, logger *zap.Logger) *Service {
+	return &Service{
+		providers: providers,
+		cacheTTL:  cacheTTL,
+		logger:    logger,
+		cache:     make(map[string]cachedPrice),
+	}
+}
+
+// GetUsdPrice returns the USD price for a canonical asset symbol
+func (s *Service) GetUsdPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	symbol = strings.ToUpper(symbol)
+	if stablecoins[symbol] {
+		return decimal.NewFromInt(1), nil
+	}
+
+	s.mutex.Lock()
+	cached, ok := s.cache[symbol]
+	s.mutex.Unlock()
+	if ok && time.Since(cached.fetchedAt) < s.cacheTTL {
+		return cached.price, nil
+	}
+
+	var errs []error
+	for _, provider := range s.providers {
+		price, err := provider.GetUsdPrice(ctx, symbol)
+		if err != nil {
+			s.logger.Debug("Price provider failed - trying next",
+				zap.String("provider", provider.Name()),
+				zap.String("symbol", symbol),
+				zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		s.mutex.Lock()
+		s.cache[symbol] = cachedPrice{price: price, fetchedAt: time.Now()}
+		s.mutex.Unlock()
+		return price, nil
+	}
+
+	// Serve a stale price rather than nothing if every provider is down
+	if ok {
+		s.logger.Warn("All price providers failed - using stale cached price",
+			zap.String("symbol", symbol),
+			zap.Time("fetched_at", cached.fetchedAt))
+		return cached.price, nil
+	}
+
+	return decimal.Zero, fmt.Errorf("unable to price %s: %w", symbol, errors.Join(errs...))
+}
+
+// ToUsd converts an amount of symbol to USD
+func (s *Service) ToUsd(ctx context.Context, symbol string, amount decimal.Decimal) (decimal.Decimal, error) {
+	price, err := s.GetUsdPrice(ctx, symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(price), nil
+}
+
+// IsDust reports whether amount of symbol is worth less than thresholdUsd. A zero threshold disables dust detection.
+func (s *Service) IsDust(ctx context.Context, symbol string, amount, thresholdUsd decimal.Decimal) (bool, error) {
+	if thresholdUsd.LessThanOrEqual(decimal.Zero) {
+		return false, nil
+	}
+	usd, err := s.ToUsd(ctx, symbol, amount.Abs())
+	if err != nil {
+		return false, err
+	}
+	return usd.LessThan(thresholdUsd), nil
+}
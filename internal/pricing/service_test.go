@@ -0,0 +1,89 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pricing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap/zaptest"
+)
+
+type stubProvider struct {
+	name  string
+	price decimal.Decimal
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) GetUsdPrice(ctx context.Context, symbol string) (decimal.Decimal, error) {
+	p.calls++
+	return p.price, p.err
+}
+
+func TestGetUsdPrice_FallbackAndCache(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: errors.New("unavailable")}
+	fallback := &stubProvider{name: "fallback", price: decimal.NewFromInt(50000)}
+	service := NewServiceWithProviders([]Provider{primary, fallback}, time.Minute, zaptest.NewLogger(t))
+
+	ctx := context.Background()
+	price, err := service.GetUsdPrice(ctx, "btc")
+	if err != nil {
+		t.Fatalf("GetUsdPrice failed: %v", err)
+	}
+	if !price.Equal(decimal.NewFromInt(50000)) {
+		t.Errorf("Expected fallback price 50000, got %s", price.String())
+	}
+
+	// Second lookup must be served from cache
+	if _, err := service.GetUsdPrice(ctx, "BTC"); err != nil {
+		t.Fatalf("Cached GetUsdPrice failed: %v", err)
+	}
+	if primary.calls != 1 || fallback.calls != 1 {
+		t.Errorf("Expected one call per provider, got primary=%d fallback=%d", primary.calls, fallback.calls)
+	}
+}
+
+func TestIsDust(t *testing.T) {
+	service := NewServiceWithProviders([]Provider{&stubProvider{name: "stub", price: decimal.NewFromInt(100)}}, time.Minute, zaptest.NewLogger(t))
+	ctx := context.Background()
+	threshold := decimal.NewFromInt(1)
+
+	dust, err := service.IsDust(ctx, "SOL", decimal.RequireFromString("0.005"), threshold)
+	if err != nil {
+		t.Fatalf("IsDust failed: %v", err)
+	}
+	if !dust {
+		t.Errorf("Expected 0.005 SOL at $100 to be dust")
+	}
+
+	// Stablecoins are valued at par without a provider call
+	dust, err = service.IsDust(ctx, "USDC", decimal.NewFromInt(5), threshold)
+	if err != nil {
+		t.Fatalf("IsDust failed: %v", err)
+	}
+	if dust {
+		t.Errorf("Expected 5 USDC not to be dust")
+	}
+}
@@ -0,0 +1,131 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/core-go"
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/coinbase-samples/prime-sdk-go/wallets"
+)
+
+const walletAddressesPageSize = 100
+
+// ErrWalletNotFound is returned when Prime has no wallet with the requested id
+var ErrWalletNotFound = errors.New("wallet not found")
+
+// GetWallet returns a single Prime wallet, or ErrWalletNotFound if Prime does not know it
+func (s *Service) GetWallet(ctx context.Context, portfolioId, walletId string) (*models.Wallet, error) {
+	response, err := s.walletsSvc.GetWallet(ctx, &wallets.GetWalletRequest{
+		PortfolioId: portfolioId,
+		Id:          walletId,
+	})
+	var apiErr *core.ApiError
+	if errors.As(err, &apiErr) && apiErr.CodeReceived == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrWalletNotFound, walletId)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to get wallet %s: %w", walletId, err)
+	}
+	if response.Wallet == nil {
+		return nil, fmt.Errorf("%w: %s", ErrWalletNotFound, walletId)
+	}
+
+	return &models.Wallet{
+		Id:     response.Wallet.Id,
+		Name:   response.Wallet.Name,
+		Symbol: response.Wallet.Symbol,
+		Type:   response.Wallet.Type,
+	}, nil
+}
+
+// ListWalletAddresses returns every deposit address Prime holds for a wallet on one network
+func (s *Service) ListWalletAddresses(ctx context.Context, portfolioId, walletId, network string) ([]models.DepositAddress, error) {
+	var addresses []models.DepositAddress
+	pagination := &model.PaginationParams{Limit: walletAddressesPageSize}
+
+	for {
+		response, err := s.walletsSvc.ListWalletAddresses(ctx, &wallets.ListWalletAddressesRequest{
+			PortfolioId: portfolioId,
+			WalletId:    walletId,
+			NetworkId:   network,
+			Pagination:  pagination,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to list addresses for wallet %s: %w", walletId, err)
+		}
+
+		for _, a := range response.Addresses {
+			if a == nil {
+				continue
+			}
+			address := models.DepositAddress{
+				Id:      a.AccountIdentifier,
+				Address: a.Address,
+				Network: network,
+			}
+			if a.Network != nil && a.Network.Id != "" {
+				address.Network = a.Network.Id
+			}
+			addresses = append(addresses, address)
+		}
+
+		if !response.HasNext() || response.Pagination.NextCursor == "" {
+			return addresses, nil
+		}
+		pagination = &model.PaginationParams{Limit: walletAddressesPageSize, Cursor: response.Pagination.NextCursor}
+	}
+}
+
+// VerifyAddress compares a stored address with its wallet and the wallet's addresses in Prime
+func VerifyAddress(stored models.Address, wallet *models.Wallet, primeAddresses []models.DepositAddress) models.AddressVerification {
+	result := models.AddressVerification{Address: stored}
+
+	if wallet == nil {
+		result.Status = models.AddressWalletNotFound
+		result.Detail = fmt.Sprintf("wallet %s not found in Prime", stored.WalletId)
+		return result
+	}
+
+	if !strings.EqualFold(wallet.Symbol, stored.Asset) {
+		result.Status = models.AddressWalletAssetDrift
+		result.Detail = fmt.Sprintf("wallet %s holds %s, address is stored as %s", wallet.Id, wallet.Symbol, stored.Asset)
+		return result
+	}
+
+	for _, a := range primeAddresses {
+		if a.Address != stored.Address {
+			continue
+		}
+		if a.Network != "" && stored.Network != "" && a.Network != stored.Network {
+			continue
+		}
+		result.Status = models.AddressVerified
+		return result
+	}
+
+	result.Status = models.AddressMissingInPrime
+	result.Detail = fmt.Sprintf("address not listed for wallet %s on %s", wallet.Id, stored.Network)
+	return result
+}
@@ -0,0 +1,53 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+)
+
+func TestVerifyAddress(t *testing.T) {
+	stored := models.Address{Asset: "USDC", Network: "base-mainnet", Address: "0xabc", WalletId: "wallet-1"}
+	wallet := &models.Wallet{Id: "wallet-1", Symbol: "USDC"}
+
+	tests := []struct {
+		name           string
+		wallet         *models.Wallet
+		primeAddresses []models.DepositAddress
+		want           string
+	}{
+		{"verified", wallet, []models.DepositAddress{{Address: "0xabc", Network: "base-mainnet"}}, models.AddressVerified},
+		{"wallet missing", nil, nil, models.AddressWalletNotFound},
+		{"wallet for another asset", &models.Wallet{Id: "wallet-1", Symbol: "ETH"}, nil, models.AddressWalletAssetDrift},
+		{"address missing", wallet, []models.DepositAddress{{Address: "0xdef", Network: "base-mainnet"}}, models.AddressMissingInPrime},
+		{"address on another network", wallet, []models.DepositAddress{{Address: "0xabc", Network: "ethereum-mainnet"}}, models.AddressMissingInPrime},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := VerifyAddress(stored, tt.wallet, tt.primeAddresses)
+			if result.Status != tt.want {
+				t.Errorf("Expected %s, got %s (%s)", tt.want, result.Status, result.Detail)
+			}
+			if result.Drifted() != (tt.want != models.AddressVerified) {
+				t.Errorf("Unexpected Drifted() for %s", result.Status)
+			}
+		})
+	}
+}
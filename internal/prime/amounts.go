@@ -0,0 +1,55 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"errors"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrUnexpectedAmountSign is returned for an inbound transaction reported with a negative amount
+var ErrUnexpectedAmountSign = errors.New("amount sign does not match transaction direction")
+
+// NormalizeAmount sets a transaction's Direction and SignedAmount from its type and reported amount.
+// Prime reports withdrawals with either sign, so outbound amounts are always made negative; inbound
+// amounts must not be negative. Transactions of unknown direction keep the amount as reported.
+func NormalizeAmount(tx *models.PrimeTransaction) error {
+	tx.Direction = models.TransactionDirection(tx.Type)
+	tx.SignedAmount = decimal.Zero
+
+	amount, err := decimal.NewFromString(tx.Amount)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q on transaction %s: %w", tx.Amount, tx.Id, err)
+	}
+
+	switch tx.Direction {
+	case models.DirectionInbound:
+		if amount.IsNegative() {
+			return fmt.Errorf("%w: %s %s reported as %s", ErrUnexpectedAmountSign, tx.Type, tx.Id, tx.Amount)
+		}
+		tx.SignedAmount = amount
+	case models.DirectionOutbound:
+		tx.SignedAmount = amount.Abs().Neg()
+	default:
+		tx.SignedAmount = amount
+	}
+	return nil
+}
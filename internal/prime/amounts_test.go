@@ -0,0 +1,91 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"errors"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestNormalizeAmount(t *testing.T) {
+	tests := []struct {
+		txType        string
+		amount        string
+		wantDirection models.TransferDirection
+		wantSigned    string
+	}{
+		{"DEPOSIT", "1.5", models.DirectionInbound, "1.5"},
+		{"INTERNAL_DEPOSIT", "2", models.DirectionInbound, "2"},
+		{"SWEEP_DEPOSIT", "3", models.DirectionInbound, "3"},
+		{"PROXY_DEPOSIT", "4", models.DirectionInbound, "4"},
+		{"COINBASE_DEPOSIT", "5", models.DirectionInbound, "5"},
+		{"DEPOSIT_ADJUSTMENT", "0.1", models.DirectionInbound, "0.1"},
+		{"COINBASE_REFUND", "6", models.DirectionInbound, "6"},
+		{"REWARD", "0.01", models.DirectionInbound, "0.01"},
+		// Prime reports outbound amounts with either sign; both must come out negative exactly once
+		{"WITHDRAWAL", "1.5", models.DirectionOutbound, "-1.5"},
+		{"WITHDRAWAL", "-1.5", models.DirectionOutbound, "-1.5"},
+		{"INTERNAL_WITHDRAWAL", "2", models.DirectionOutbound, "-2"},
+		{"SWEEP_WITHDRAWAL", "-3", models.DirectionOutbound, "-3"},
+		{"PROXY_WITHDRAWAL", "4", models.DirectionOutbound, "-4"},
+		{"BILLING_WITHDRAWAL", "0.5", models.DirectionOutbound, "-0.5"},
+		{"WITHDRAWAL_ADJUSTMENT", "0.1", models.DirectionOutbound, "-0.1"},
+		{"SLASH", "0.2", models.DirectionOutbound, "-0.2"},
+		{"DEPOSIT", "0", models.DirectionInbound, "0"},
+		{"CONVERSION", "-7", models.DirectionUnknown, "-7"},
+		{"DELEGATION", "8", models.DirectionUnknown, "8"},
+		{"TRANSACTION_TYPE_OTHER", "9", models.DirectionUnknown, "9"},
+	}
+
+	for _, tt := range tests {
+		tx := models.PrimeTransaction{Id: "tx", Type: tt.txType, Amount: tt.amount}
+		if err := NormalizeAmount(&tx); err != nil {
+			t.Errorf("NormalizeAmount(%s %s) failed: %v", tt.txType, tt.amount, err)
+			continue
+		}
+		if tx.Direction != tt.wantDirection {
+			t.Errorf("NormalizeAmount(%s %s) direction = %q, want %q", tt.txType, tt.amount, tx.Direction, tt.wantDirection)
+		}
+		if !tx.SignedAmount.Equal(decimal.RequireFromString(tt.wantSigned)) {
+			t.Errorf("NormalizeAmount(%s %s) signed amount = %s, want %s", tt.txType, tt.amount, tx.SignedAmount, tt.wantSigned)
+		}
+
+		// Normalizing twice must not flip the sign again
+		if err := NormalizeAmount(&tx); err != nil || !tx.SignedAmount.Equal(decimal.RequireFromString(tt.wantSigned)) {
+			t.Errorf("NormalizeAmount(%s %s) is not idempotent: %s, %v", tt.txType, tt.amount, tx.SignedAmount, err)
+		}
+	}
+}
+
+func TestNormalizeAmount_Invalid(t *testing.T) {
+	tx := models.PrimeTransaction{Id: "tx", Type: "DEPOSIT", Amount: "-1"}
+	if err := NormalizeAmount(&tx); !errors.Is(err, ErrUnexpectedAmountSign) {
+		t.Errorf("Expected ErrUnexpectedAmountSign for a negative deposit, got %v", err)
+	}
+	if !tx.SignedAmount.IsZero() {
+		t.Errorf("Expected zero signed amount after a failed normalization, got %s", tx.SignedAmount)
+	}
+
+	tx = models.PrimeTransaction{Id: "tx", Type: "WITHDRAWAL", Amount: "abc"}
+	if err := NormalizeAmount(&tx); err == nil {
+		t.Error("Expected an error for an unparseable amount")
+	}
+}
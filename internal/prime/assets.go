@@ -0,0 +1,117 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/prime-sdk-go/assets"
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/shopspring/decimal"
+)
+
+// GetAsset returns Prime's metadata for a symbol, including the networks it supports
+func (s *Service) GetAsset(ctx context.Context, entityId, symbol string) (*models.AssetMetadata, error) {
+	response, err := s.assetsSvc.ListAssets(ctx, &assets.ListAssetsRequest{EntityId: entityId})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list assets: %w", err)
+	}
+
+	for _, a := range response.Assets {
+		if a == nil || !strings.EqualFold(a.Symbol, symbol) {
+			continue
+		}
+
+		metadata := &models.AssetMetadata{
+			Name:             a.Name,
+			Symbol:           a.Symbol,
+			DecimalPrecision: a.DecimalPrecision,
+			ExplorerUrl:      a.ExplorerUrl,
+		}
+		for _, n := range a.Networks {
+			if n == nil {
+				continue
+			}
+			network := models.AssetNetwork{
+				Name:                   n.Name,
+				MaxDecimals:            n.MaxDecimals,
+				Default:                n.Default,
+				VaultSupported:         n.VaultSupported,
+				DestinationTagRequired: n.DestinationTagRequired,
+			}
+			if n.Network != nil {
+				network.Id = n.Network.Id
+				network.Type = n.Network.Type
+			}
+			metadata.Networks = append(metadata.Networks, network)
+		}
+		return metadata, nil
+	}
+
+	return nil, fmt.Errorf("asset %s not found in Prime", symbol)
+}
+
+// RecentNetworkFees estimates network fees from the withdrawals a wallet has made since the given time
+func (s *Service) RecentNetworkFees(ctx context.Context, portfolioId, walletId string, since time.Time) ([]models.NetworkFeeEstimate, error) {
+	response, err := s.ListWalletTransactions(ctx, portfolioId, walletId, since, 0)
+	if err != nil {
+		return nil, err
+	}
+	return summarizeNetworkFees(response.Transactions), nil
+}
+
+// summarizeNetworkFees averages the network fees of withdrawals per network
+func summarizeNetworkFees(transactions []*model.Transaction) []models.NetworkFeeEstimate {
+	byNetwork := make(map[string]*models.NetworkFeeEstimate)
+	totals := make(map[string]decimal.Decimal)
+
+	for _, tx := range transactions {
+		if tx == nil || tx.Type != "WITHDRAWAL" || tx.NetworkFees == "" {
+			continue
+		}
+		fee, err := decimal.NewFromString(tx.NetworkFees)
+		if err != nil {
+			continue
+		}
+
+		estimate, ok := byNetwork[tx.Network]
+		if !ok {
+			estimate = &models.NetworkFeeEstimate{Network: tx.Network, FeeSymbol: tx.FeeSymbol}
+			byNetwork[tx.Network] = estimate
+		}
+		estimate.Samples++
+		totals[tx.Network] = totals[tx.Network].Add(fee)
+		if fee.GreaterThan(estimate.Max) {
+			estimate.Max = fee
+		}
+	}
+
+	estimates := make([]models.NetworkFeeEstimate, 0, len(byNetwork))
+	for network, estimate := range byNetwork {
+		estimate.Average = totals[network].Div(decimal.NewFromInt(int64(estimate.Samples)))
+		estimates = append(estimates, *estimate)
+	}
+	sort.Slice(estimates, func(i, j int) bool { return estimates[i].Network < estimates[j].Network })
+
+	return estimates
+}
@@ -0,0 +1,53 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"testing"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/shopspring/decimal"
+)
+
+func TestSummarizeNetworkFees(t *testing.T) {
+	transactions := []*model.Transaction{
+		{Type: "WITHDRAWAL", Network: "ethereum-mainnet", NetworkFees: "0.002", FeeSymbol: "ETH"},
+		{Type: "WITHDRAWAL", Network: "ethereum-mainnet", NetworkFees: "0.004", FeeSymbol: "ETH"},
+		{Type: "WITHDRAWAL", Network: "base-mainnet", NetworkFees: "0.0001", FeeSymbol: "ETH"},
+		{Type: "DEPOSIT", Network: "ethereum-mainnet", NetworkFees: "1"},
+		{Type: "WITHDRAWAL", Network: "ethereum-mainnet", NetworkFees: ""},
+	}
+
+	estimates := summarizeNetworkFees(transactions)
+	if len(estimates) != 2 {
+		t.Fatalf("Expected 2 networks, got %d", len(estimates))
+	}
+
+	base, ethereum := estimates[0], estimates[1]
+	if base.Network != "base-mainnet" || base.Samples != 1 {
+		t.Errorf("Unexpected base estimate: %+v", base)
+	}
+	if ethereum.Samples != 2 {
+		t.Errorf("Expected 2 ethereum samples, got %d", ethereum.Samples)
+	}
+	if !ethereum.Average.Equal(decimal.RequireFromString("0.003")) {
+		t.Errorf("Expected average 0.003, got %s", ethereum.Average)
+	}
+	if !ethereum.Max.Equal(decimal.RequireFromString("0.004")) {
+		t.Errorf("Expected max 0.004, got %s", ethereum.Max)
+	}
+}
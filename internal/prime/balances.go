@@ -0,0 +1,107 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"fmt"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/prime-sdk-go/balances"
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/shopspring/decimal"
+)
+
+// Portfolio balance types
+const (
+	BalanceTypeTrading = model.BalanceTypeTrading
+	BalanceTypeVault   = model.BalanceTypeVault
+)
+
+// ListPortfolioBalances returns per-symbol balances for a portfolio. balanceType selects trading
+// (hot) or vault balances.
+func (s *Service) ListPortfolioBalances(ctx context.Context, portfolioId, balanceType string) ([]models.PortfolioBalance, error) {
+	request := &balances.ListPortfolioBalancesRequest{
+		PortfolioId: portfolioId,
+		Type:        balanceType,
+	}
+
+	response, err := s.balancesSvc.ListPortfolioBalances(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list portfolio balances: %w", err)
+	}
+
+	balanceList := make([]models.PortfolioBalance, 0, len(response.Balances))
+	for _, b := range response.Balances {
+		balance, err := convertBalance(b)
+		if err != nil {
+			return nil, err
+		}
+		balanceList = append(balanceList, *balance)
+	}
+
+	return balanceList, nil
+}
+
+// GetWalletBalance returns the balance of a single wallet
+func (s *Service) GetWalletBalance(ctx context.Context, portfolioId, walletId string) (*models.PortfolioBalance, error) {
+	request := &balances.GetWalletBalanceRequest{
+		PortfolioId: portfolioId,
+		Id:          walletId,
+	}
+
+	response, err := s.balancesSvc.GetWalletBalance(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get wallet balance: %w", err)
+	}
+	if response.Balance == nil {
+		return nil, fmt.Errorf("no balance returned for wallet %s", walletId)
+	}
+
+	return convertBalance(response.Balance)
+}
+
+func convertBalance(b *model.Balance) (*models.PortfolioBalance, error) {
+	amount, err := parseBalanceAmount(b.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s amount %q: %w", b.Symbol, b.Amount, err)
+	}
+	holds, err := parseBalanceAmount(b.Holds)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s holds %q: %w", b.Symbol, b.Holds, err)
+	}
+	withdrawable, err := parseBalanceAmount(b.WithdrawableAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s withdrawable amount %q: %w", b.Symbol, b.WithdrawableAmount, err)
+	}
+
+	return &models.PortfolioBalance{
+		Symbol:       b.Symbol,
+		Amount:       amount,
+		Holds:        holds,
+		Withdrawable: withdrawable,
+	}, nil
+}
+
+// parseBalanceAmount treats empty balance fields as zero
+func parseBalanceAmount(value string) (decimal.Decimal, error) {
+	if value == "" {
+		return decimal.Zero, nil
+	}
+	return decimal.NewFromString(value)
+}
@@ -0,0 +1,209 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/core-go"
+	"github.com/coinbase-samples/prime-sdk-go/client"
+	"github.com/coinbase-samples/prime-sdk-go/transactions"
+	"go.uber.org/zap"
+)
+
+// Withdrawal destination types
+const (
+	// DestinationTypeAddress sends to an on-chain blockchain address
+	DestinationTypeAddress = "address"
+
+	// DestinationTypePaymentMethod sends to a Prime payment method (e.g. a linked Coinbase account)
+	DestinationTypePaymentMethod = "payment_method"
+
+	// DestinationTypeWallet transfers to another Prime wallet, including wallets in other portfolios
+	DestinationTypeWallet = "wallet"
+
+	// DestinationTypeCounterparty sends to a Prime counterparty by counterparty id
+	DestinationTypeCounterparty = "counterparty"
+)
+
+// DestinationTypes lists the supported withdrawal destination types
+var DestinationTypes = []string{
+	DestinationTypeAddress,
+	DestinationTypePaymentMethod,
+	DestinationTypeWallet,
+	DestinationTypeCounterparty,
+}
+
+// ParseDestinationType normalizes a destination type, defaulting to an on-chain address
+func ParseDestinationType(value string) (string, error) {
+	if value == "" {
+		return DestinationTypeAddress, nil
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(value, "-", "_"))
+	for _, destinationType := range DestinationTypes {
+		if normalized == destinationType {
+			return destinationType, nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported destination type %q (expected one of: %s)", value, strings.Join(DestinationTypes, ", "))
+}
+
+// counterpartyWithdrawalRequest is the withdrawal body for counterparty destinations, which the SDK does not model
+type counterpartyWithdrawalRequest struct {
+	PortfolioId     string                          `json:"portfolio_id"`
+	SourceWalletId  string                          `json:"wallet_id"`
+	Amount          string                          `json:"amount"`
+	DestinationType string                          `json:"destination_type"`
+	IdempotencyKey  string                          `json:"idempotency_key"`
+	Symbol          string                          `json:"currency_symbol"`
+	Counterparty    counterpartyWithdrawalRecipient `json:"counterparty"`
+}
+
+type counterpartyWithdrawalRecipient struct {
+	CounterpartyId string `json:"counterparty_id"`
+}
+
+// createPaymentMethodWithdrawal, createWalletTransfer and createCounterpartyWithdrawal return the
+// fields of Prime's response; createNonAddressWithdrawal fills in the request details
+func (s *Service) createPaymentMethodWithdrawal(ctx context.Context, params CreateWithdrawalParams, symbol string) (*models.Withdrawal, error) {
+	response, err := s.transactionsSvc.CreateWalletWithdrawal(ctx, &transactions.CreateWalletWithdrawalRequest{
+		PortfolioId:     params.PortfolioId,
+		SourceWalletId:  params.WalletId,
+		Amount:          params.Amount,
+		IdempotencyKey:  params.IdempotencyKey,
+		Symbol:          symbol,
+		DestinationType: "DESTINATION_PAYMENT_METHOD",
+		PaymentMethod:   &transactions.CreateWalletWithdrawalPaymentMethod{Id: params.Destination},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.Withdrawal{
+		ActivityId:    response.ActivityId,
+		Symbol:        response.Symbol,
+		Fee:           response.Fee,
+		TransactionId: response.TransactionId,
+	}, nil
+}
+
+func (s *Service) createWalletTransfer(ctx context.Context, params CreateWithdrawalParams, symbol string) (*models.Withdrawal, error) {
+	response, err := s.transactionsSvc.CreateWalletTransfer(ctx, &transactions.CreateWalletTransferRequest{
+		PortfolioId:         params.PortfolioId,
+		SourceWalletId:      params.WalletId,
+		Symbol:              symbol,
+		DestinationWalletId: params.Destination,
+		IdempotencyKey:      params.IdempotencyKey,
+		Amount:              params.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.Withdrawal{
+		ActivityId:    response.ActivityId,
+		Symbol:        response.Symbol,
+		Fee:           response.Fee,
+		TransactionId: response.TransactionId,
+	}, nil
+}
+
+func (s *Service) createCounterpartyWithdrawal(ctx context.Context, params CreateWithdrawalParams, symbol string) (*models.Withdrawal, error) {
+	request := &counterpartyWithdrawalRequest{
+		PortfolioId:     params.PortfolioId,
+		SourceWalletId:  params.WalletId,
+		Amount:          params.Amount,
+		DestinationType: "DESTINATION_COUNTERPARTY",
+		IdempotencyKey:  params.IdempotencyKey,
+		Symbol:          symbol,
+		Counterparty:    counterpartyWithdrawalRecipient{CounterpartyId: params.Destination},
+	}
+
+	path := fmt.Sprintf("/portfolios/%s/wallets/%s/withdrawals", params.PortfolioId, params.WalletId)
+	response := &transactions.CreateWalletWithdrawalResponse{}
+
+	if err := core.HttpPost(
+		ctx,
+		s.client,
+		path,
+		core.EmptyQueryParams,
+		client.DefaultSuccessHttpStatusCodes,
+		request,
+		response,
+		s.client.HeadersFunc(),
+	); err != nil {
+		return nil, err
+	}
+
+	return &models.Withdrawal{
+		ActivityId:    response.ActivityId,
+		Symbol:        response.Symbol,
+		Fee:           response.Fee,
+		TransactionId: response.TransactionId,
+	}, nil
+}
+
+// createNonAddressWithdrawal submits a withdrawal to a payment method, wallet or counterparty
+func (s *Service) createNonAddressWithdrawal(ctx context.Context, params CreateWithdrawalParams, destinationType, symbol string) (*models.Withdrawal, error) {
+	var withdrawal *models.Withdrawal
+	var err error
+
+	switch destinationType {
+	case DestinationTypePaymentMethod:
+		withdrawal, err = s.createPaymentMethodWithdrawal(ctx, params, symbol)
+	case DestinationTypeWallet:
+		withdrawal, err = s.createWalletTransfer(ctx, params, symbol)
+	case DestinationTypeCounterparty:
+		withdrawal, err = s.createCounterpartyWithdrawal(ctx, params, symbol)
+	default:
+		return nil, fmt.Errorf("unsupported destination type: %s", destinationType)
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to create withdrawal",
+			zap.String("wallet_id", params.WalletId),
+			zap.String("amount", params.Amount),
+			zap.String("asset", params.Asset),
+			zap.String("destination_type", destinationType),
+			zap.Error(err))
+		return nil, fmt.Errorf("unable to create withdrawal: %w", err)
+	}
+
+	s.logger.Info("Withdrawal created successfully",
+		zap.String("activity_id", withdrawal.ActivityId),
+		zap.String("wallet_id", params.WalletId),
+		zap.String("amount", params.Amount),
+		zap.String("asset", params.Asset),
+		zap.String("destination_type", destinationType))
+
+	withdrawal.Asset = params.Asset
+	withdrawal.Amount = params.Amount
+	withdrawal.DestinationType = destinationType
+	withdrawal.Destination = params.Destination
+	withdrawal.IdempotencyKey = params.IdempotencyKey
+	return withdrawal, nil
+}
+
+// ReportedAsWithdrawal reports whether Prime lists transfers to a destination type as withdrawal
+// transactions. Wallet transfers are not, so the listener never sees them complete.
+func ReportedAsWithdrawal(destinationType string) bool {
+	return destinationType != DestinationTypeWallet
+}
@@ -0,0 +1,45 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import "testing"
+
+func TestParseDestinationType(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", DestinationTypeAddress, false},
+		{"address", DestinationTypeAddress, false},
+		{"payment-method", DestinationTypePaymentMethod, false},
+		{"WALLET", DestinationTypeWallet, false},
+		{"counterparty", DestinationTypeCounterparty, false},
+		{"email", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDestinationType(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDestinationType(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDestinationType(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/coinbase-samples/core-go"
+	"github.com/coinbase-samples/prime-sdk-go/client"
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/coinbase-samples/prime-sdk-go/transactions"
+)
+
+// Entitlements the ledger needs from its API key
+const (
+	EntitlementReadTransactions  = "read transactions"
+	EntitlementCreateAddresses   = "create addresses"
+	EntitlementCreateWithdrawals = "create withdrawals"
+)
+
+// probeWalletId names a wallet that cannot exist, so write probes are rejected by validation and never create anything
+const probeWalletId = "00000000-0000-0000-0000-000000000000"
+
+// MissingEntitlements probes the endpoints the ledger uses and returns the entitlements the API key
+// lacks. Prime has no endpoint that lists a key's permissions, so each probe makes a request and
+// treats 401 or 403 as missing. Write probes send an empty body for a nonexistent wallet; any other
+// rejection, such as 400 or 404, means the request got past authorization.
+func (s *Service) MissingEntitlements(ctx context.Context, portfolioId string) ([]string, error) {
+	probes := []struct {
+		entitlement string
+		probe       func() error
+	}{
+		{EntitlementReadTransactions, func() error {
+			_, err := s.transactionsSvc.ListPortfolioTransactions(ctx, &transactions.ListPortfolioTransactionsRequest{
+				PortfolioId: portfolioId,
+				Pagination:  &model.PaginationParams{Limit: 1},
+			})
+			return err
+		}},
+		{EntitlementCreateAddresses, func() error {
+			return s.probePost(ctx, fmt.Sprintf("/portfolios/%s/wallets/%s/addresses", portfolioId, probeWalletId))
+		}},
+		{EntitlementCreateWithdrawals, func() error {
+			return s.probePost(ctx, fmt.Sprintf("/portfolios/%s/wallets/%s/withdrawals", portfolioId, probeWalletId))
+		}},
+	}
+
+	var missing []string
+	for _, p := range probes {
+		denied, err := probeDenied(p.probe())
+		if err != nil {
+			return nil, fmt.Errorf("unable to check %s entitlement: %w", p.entitlement, err)
+		}
+		if denied {
+			missing = append(missing, p.entitlement)
+		}
+	}
+	return missing, nil
+}
+
+// probeDenied reports whether a probe was refused authorization. Errors that say nothing about
+// authorization, such as network failures and 5xx responses, are returned.
+func probeDenied(err error) (bool, error) {
+	if err == nil {
+		return false, nil
+	}
+
+	var apiErr *core.ApiError
+	if !errors.As(err, &apiErr) || apiErr.CodeReceived == 0 || apiErr.CodeReceived >= http.StatusInternalServerError {
+		return false, err
+	}
+	return apiErr.CodeReceived == http.StatusUnauthorized || apiErr.CodeReceived == http.StatusForbidden, nil
+}
+
+func (s *Service) probePost(ctx context.Context, path string) error {
+	return core.HttpPost(
+		ctx,
+		s.client,
+		path,
+		core.EmptyQueryParams,
+		client.DefaultSuccessHttpStatusCodes,
+		&struct{}{},
+		&struct{}{},
+		s.client.HeadersFunc(),
+	)
+}
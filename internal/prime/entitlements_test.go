@@ -0,0 +1,52 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/coinbase-samples/core-go"
+)
+
+func TestProbeDenied(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantDenied bool
+		wantErr    bool
+	}{
+		{"allowed", nil, false, false},
+		{"forbidden", &core.ApiError{CodeReceived: http.StatusForbidden}, true, false},
+		{"unauthorized", &core.ApiError{CodeReceived: http.StatusUnauthorized}, true, false},
+		{"rejected by validation", &core.ApiError{CodeReceived: http.StatusBadRequest}, false, false},
+		{"unknown wallet", &core.ApiError{CodeReceived: http.StatusNotFound}, false, false},
+		{"server error", &core.ApiError{CodeReceived: http.StatusBadGateway}, false, true},
+		{"network error", &core.ApiError{Message: "connection refused"}, false, true},
+		{"other error", errors.New("context deadline exceeded"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			denied, err := probeDenied(tt.err)
+			if denied != tt.wantDenied || (err != nil) != tt.wantErr {
+				t.Errorf("probeDenied() = %t, %v; want %t, error %t", denied, err, tt.wantDenied, tt.wantErr)
+			}
+		})
+	}
+}
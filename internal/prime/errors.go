@@ -0,0 +1,39 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/coinbase-samples/core-go"
+)
+
+// SubmissionAmbiguous reports whether a failed request may still have been carried out by Prime: no
+// response came back, e.g. on a timeout, or Prime answered with a 5xx. A 4xx means Prime rejected the
+// request, so a withdrawal that failed that way was never created.
+func SubmissionAmbiguous(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *core.ApiError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.CodeReceived == 0 || apiErr.CodeReceived >= http.StatusInternalServerError
+}
@@ -0,0 +1,48 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/coinbase-samples/core-go"
+)
+
+func TestSubmissionAmbiguous(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"no error", nil, false},
+		{"rejected by validation", &core.ApiError{CodeReceived: http.StatusBadRequest}, false},
+		{"insufficient funds", fmt.Errorf("unable to create withdrawal: %w", &core.ApiError{CodeReceived: http.StatusUnprocessableEntity}), false},
+		{"server error", &core.ApiError{CodeReceived: http.StatusBadGateway}, true},
+		{"timeout", &core.ApiError{Message: "context deadline exceeded"}, true},
+		{"not from the client", context.DeadlineExceeded, true},
+		{"unknown", errors.New("connection reset"), true},
+	}
+	for _, tt := range tests {
+		if got := SubmissionAmbiguous(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
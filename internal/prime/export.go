@@ -0,0 +1,113 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/coinbase-samples/prime-sdk-go/transactions"
+)
+
+const exportPageSize = 500
+
+// WalletTransactionsQuery selects the wallet transactions to export
+type WalletTransactionsQuery struct {
+	PortfolioId string
+	WalletId    string
+	Start       time.Time
+	End         time.Time
+	Types       []string
+}
+
+// EachWalletTransaction pages through a wallet's transactions oldest first, calling fn for each one.
+// It returns the number of transactions passed to fn.
+func (s *Service) EachWalletTransaction(ctx context.Context, query WalletTransactionsQuery, fn func(*model.Transaction) error) (int, error) {
+	count := 0
+	pagination := &model.PaginationParams{Limit: exportPageSize, SortDirection: "ASC"}
+
+	for {
+		response, err := s.transactionsSvc.ListWalletTransactions(ctx, &transactions.ListWalletTransactionsRequest{
+			PortfolioId: query.PortfolioId,
+			WalletId:    query.WalletId,
+			Start:       query.Start,
+			End:         query.End,
+			Types:       query.Types,
+			Pagination:  pagination,
+		})
+		if err != nil {
+			return count, fmt.Errorf("unable to list wallet transactions: %w", err)
+		}
+
+		for _, tx := range response.Transactions {
+			if tx == nil {
+				continue
+			}
+			if err := fn(tx); err != nil {
+				return count, err
+			}
+			count++
+		}
+
+		if response.Pagination == nil || !response.Pagination.HasNext || response.Pagination.NextCursor == "" {
+			return count, nil
+		}
+		pagination = &model.PaginationParams{
+			Limit:         exportPageSize,
+			SortDirection: "ASC",
+			Cursor:        response.Pagination.NextCursor,
+		}
+	}
+}
+
+// TransactionCSVHeader names the columns written by TransactionCSVRecord
+var TransactionCSVHeader = []string{
+	"id", "wallet_id", "portfolio_id", "type", "status", "symbol", "network", "amount",
+	"fees", "network_fees", "fee_symbol", "created_at", "completed_at",
+	"transfer_from_type", "transfer_from_value", "transfer_from_address",
+	"transfer_to_type", "transfer_to_value", "transfer_to_address", "transfer_to_account_identifier",
+	"blockchain_ids", "transaction_id", "idempotency_key",
+}
+
+// TransactionCSVRecord flattens a Prime transaction into one CSV row
+func TransactionCSVRecord(tx *model.Transaction) []string {
+	var from, to model.Transfer
+	if tx.TransferFrom != nil {
+		from = *tx.TransferFrom
+	}
+	if tx.TransferTo != nil {
+		to = *tx.TransferTo
+	}
+
+	return []string{
+		tx.Id, tx.WalletId, tx.PortfolioId, tx.Type, tx.Status, tx.Symbol, tx.Network, tx.Amount,
+		tx.Fees, tx.NetworkFees, tx.FeeSymbol, formatExportTime(tx.Created), formatExportTime(tx.Completed),
+		from.Type, from.Value, from.Address,
+		to.Type, to.Value, to.Address, to.AccountIdentifier,
+		strings.Join(tx.BlockchainIds, ";"), tx.TransactionId, tx.IdempotencyKey,
+	}
+}
+
+func formatExportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
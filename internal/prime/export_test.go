@@ -0,0 +1,61 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+)
+
+func TestTransactionCSVRecord(t *testing.T) {
+	tx := &model.Transaction{
+		Id:            "tx-1",
+		Type:          "DEPOSIT",
+		Symbol:        "USDC",
+		Amount:        "10.5",
+		Created:       time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		TransferTo:    &model.Transfer{Type: "ADDRESS", Value: "0xabc", Address: "0xabc"},
+		BlockchainIds: []string{"0xhash1", "0xhash2"},
+	}
+
+	record := TransactionCSVRecord(tx)
+	if len(record) != len(TransactionCSVHeader) {
+		t.Fatalf("Expected %d columns, got %d", len(TransactionCSVHeader), len(record))
+	}
+
+	columns := make(map[string]string)
+	for i, name := range TransactionCSVHeader {
+		columns[name] = record[i]
+	}
+
+	expected := map[string]string{
+		"id":                  "tx-1",
+		"amount":              "10.5",
+		"created_at":          "2025-03-01T12:00:00Z",
+		"completed_at":        "",
+		"transfer_from_type":  "",
+		"transfer_to_address": "0xabc",
+		"blockchain_ids":      "0xhash1;0xhash2",
+	}
+	for name, want := range expected {
+		if columns[name] != want {
+			t.Errorf("Column %s: expected %q, got %q", name, want, columns[name])
+		}
+	}
+}
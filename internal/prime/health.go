@@ -0,0 +1,55 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/core-go"
+	"github.com/coinbase-samples/prime-sdk-go/portfolios"
+)
+
+// ErrCredentialsRejected is returned when Prime rejects the API key, e.g. because it expired or was revoked
+var ErrCredentialsRejected = errors.New("prime rejected the API credentials")
+
+// ErrPortfolioNotFound is returned when the portfolio is no longer visible to the API key
+var ErrPortfolioNotFound = errors.New("portfolio not found")
+
+// CheckPortfolio verifies Prime is reachable, accepts the credentials and still has the portfolio.
+// It makes a single portfolio list call, so it is cheap enough for health checks.
+func (s *Service) CheckPortfolio(ctx context.Context, portfolioId string) (*models.Portfolio, error) {
+	response, err := s.portfoliosSvc.ListPortfolios(ctx, &portfolios.ListPortfoliosRequest{})
+	var apiErr *core.ApiError
+	if errors.As(err, &apiErr) && (apiErr.CodeReceived == http.StatusUnauthorized || apiErr.CodeReceived == http.StatusForbidden) {
+		return nil, fmt.Errorf("%w (status %d)", ErrCredentialsRejected, apiErr.CodeReceived)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to list portfolios: %w", err)
+	}
+
+	for _, p := range response.Portfolios {
+		if p.Id == portfolioId {
+			return &models.Portfolio{Id: p.Id, Name: p.Name, EntityId: p.EntityId}, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPortfolioNotFound, portfolioId)
+}
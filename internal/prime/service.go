@@ -18,6 +18,7 @@ package prime
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
@@ -26,6 +27,8 @@ import (
 
 	"prime-send-receive-go/internal/models"
 
+	"github.com/coinbase-samples/prime-sdk-go/assets"
+	"github.com/coinbase-samples/prime-sdk-go/balances"
 	"github.com/coinbase-samples/prime-sdk-go/client"
 	"github.com/coinbase-samples/prime-sdk-go/credentials"
 	"github.com/coinbase-samples/prime-sdk-go/model"
@@ -37,14 +40,20 @@ import (
 	"golang.org/x/net/http2"
 )
 
+// defaultTransactionPageSize is how many wallet transactions are requested when no limit is given
+const defaultTransactionPageSize = 500
+
 type Service struct {
 	client          client.RestClient
 	portfoliosSvc   portfolios.PortfoliosService
+	assetsSvc       assets.AssetsService
+	balancesSvc     balances.BalancesService
 	walletsSvc      wallets.WalletsService
 	transactionsSvc transactions.TransactionsService
+	logger          *zap.Logger
 }
 
-func NewService(creds *credentials.Credentials) (*Service, error) {
+func NewService(creds *credentials.Credentials, logger *zap.Logger) (*Service, error) {
 	httpClient, err := createCustomHttpClient()
 	if err != nil {
 		return nil, fmt.Errorf("unable to create custom http client: %w", err)
@@ -55,11 +64,17 @@ func NewService(creds *credentials.Credentials) (*Service, error) {
 	return &Service{
 		client:          restClient,
 		portfoliosSvc:   portfolios.NewPortfoliosService(restClient),
+		assetsSvc:       assets.NewAssetsService(restClient),
+		balancesSvc:     balances.NewBalancesService(restClient),
 		walletsSvc:      wallets.NewWalletsService(restClient),
 		transactionsSvc: transactions.NewTransactionsService(restClient),
+		logger:          logger,
 	}, nil
 }
 
+// Name identifies Prime as the custody provider
+func (s *Service) Name() string { return "prime" }
+
 func createCustomHttpClient() (http.Client, error) {
 	tr := &http.Transport{
 		ResponseHeaderTimeout: 30 * time.Second,
@@ -97,8 +112,9 @@ func (s *Service) ListPortfolios(ctx context.Context) ([]models.Portfolio, error
 	portfolioList := make([]models.Portfolio, len(response.Portfolios))
 	for i, p := range response.Portfolios {
 		portfolioList[i] = models.Portfolio{
-			Id:   p.Id,
-			Name: p.Name,
+			Id:       p.Id,
+			Name:     p.Name,
+			EntityId: p.EntityId,
 		}
 	}
 
@@ -188,31 +204,34 @@ func (s *Service) CreateWallet(ctx context.Context, portfolioId, name, symbol, w
 }
 
 // CreateWithdrawalParams contains parameters for creating a withdrawal
-type CreateWithdrawalParams struct {
-	PortfolioId        string
-	WalletId           string
-	DestinationAddress string
-	Amount             string
-	Asset              string
-	IdempotencyKey     string
-}
+type CreateWithdrawalParams = models.CreateWithdrawalParams
 
 // CreateWithdrawal creates a withdrawal from a wallet
 func (s *Service) CreateWithdrawal(ctx context.Context, params CreateWithdrawalParams) (*models.Withdrawal, error) {
-	zap.L().Info("Creating withdrawal via Prime API",
+	s.logger.Info("Creating withdrawal via Prime API",
 		zap.String("portfolio_id", params.PortfolioId),
 		zap.String("wallet_id", params.WalletId),
 		zap.String("asset", params.Asset),
 		zap.String("amount", params.Amount),
-		zap.String("destination", params.DestinationAddress))
+		zap.String("destination_type", params.DestinationType),
+		zap.String("destination", params.Destination))
+
+	destinationType, err := ParseDestinationType(params.DestinationType)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse asset string: ETH-ethereum-mainnet --> ETH, ethereum, mainnet
 	// Or just: ETH --> ETH (defaults to ethereum-mainnet in Prime API)
 	parts := strings.Split(params.Asset, "-")
 	symbol := parts[0]
 
+	if destinationType != DestinationTypeAddress {
+		return s.createNonAddressWithdrawal(ctx, params, destinationType, symbol)
+	}
+
 	blockchainAddr := &model.BlockchainAddress{
-		Address: params.DestinationAddress,
+		Address: params.Destination,
 	}
 
 	// If network is specified, include it in the request
@@ -223,7 +242,7 @@ func (s *Service) CreateWithdrawal(ctx context.Context, params CreateWithdrawalP
 			Id:   networkId,
 			Type: networkType,
 		}
-		zap.L().Debug("Including network details in withdrawal",
+		s.logger.Debug("Including network details in withdrawal",
 			zap.String("network_id", networkId),
 			zap.String("network_type", networkType))
 	}
@@ -238,7 +257,7 @@ func (s *Service) CreateWithdrawal(ctx context.Context, params CreateWithdrawalP
 		BlockchainAddress: blockchainAddr,
 	}
 	// Debug: Log the request structure
-	zap.L().Debug("Withdrawal request details",
+	s.logger.Debug("Withdrawal request details",
 		zap.String("portfolio_id", request.PortfolioId),
 		zap.String("wallet_id", request.SourceWalletId),
 		zap.String("amount", request.Amount),
@@ -248,7 +267,7 @@ func (s *Service) CreateWithdrawal(ctx context.Context, params CreateWithdrawalP
 
 	response, err := s.transactionsSvc.CreateWalletWithdrawal(ctx, request)
 	if err != nil {
-		zap.L().Error("Failed to create withdrawal",
+		s.logger.Error("Failed to create withdrawal",
 			zap.String("wallet_id", params.WalletId),
 			zap.String("amount", params.Amount),
 			zap.String("asset", params.Asset),
@@ -256,55 +275,63 @@ func (s *Service) CreateWithdrawal(ctx context.Context, params CreateWithdrawalP
 		return nil, fmt.Errorf("unable to create withdrawal: %w", err)
 	}
 
-	zap.L().Info("Withdrawal created successfully",
+	s.logger.Info("Withdrawal created successfully",
 		zap.String("activity_id", response.ActivityId),
 		zap.String("wallet_id", params.WalletId),
 		zap.String("amount", params.Amount),
 		zap.String("asset", params.Asset))
 
 	return &models.Withdrawal{
-		ActivityId:     response.ActivityId,
-		Asset:          params.Asset,
-		Amount:         params.Amount,
-		Destination:    params.DestinationAddress,
-		IdempotencyKey: params.IdempotencyKey,
+		ActivityId:      response.ActivityId,
+		Asset:           params.Asset,
+		Amount:          params.Amount,
+		DestinationType: DestinationTypeAddress,
+		Destination:     params.Destination,
+		IdempotencyKey:  params.IdempotencyKey,
+		Symbol:          response.Symbol,
+		Fee:             response.Fee,
+		TransactionId:   response.TransactionId,
 	}, nil
 }
 
-// ListWalletTransactions fetches transactions for a specific wallet
-func (s *Service) ListWalletTransactions(ctx context.Context, portfolioId, walletId string, startTime time.Time) (*transactions.ListWalletTransactionsResponse, error) {
-	zap.L().Debug("Making Prime API request",
+// ListWalletTransactions fetches transactions of every type for a specific wallet, so callers can see
+// the types they do not handle
+func (s *Service) ListWalletTransactions(ctx context.Context, portfolioId, walletId string, startTime time.Time, limit int) (*transactions.ListWalletTransactionsResponse, error) {
+	if limit <= 0 {
+		limit = defaultTransactionPageSize
+	}
+
+	s.logger.Debug("Making Prime API request",
 		zap.String("portfolio_id", portfolioId),
 		zap.String("wallet_id", walletId),
 		zap.Time("start_time", startTime),
 		zap.String("start_time_formatted", startTime.UTC().Format("2006-01-02T15:04:05Z")),
-		zap.Strings("types", []string{"DEPOSIT", "WITHDRAWAL"}))
+		zap.Int("limit", limit))
 
 	request := &transactions.ListWalletTransactionsRequest{
 		PortfolioId: portfolioId,
 		WalletId:    walletId,
 		Start:       startTime,
-		Types:       []string{"DEPOSIT", "WITHDRAWAL"},
 		Pagination: &model.PaginationParams{
-			Limit: 500,
+			Limit: int32(limit),
 		},
 	}
 
 	response, err := s.transactionsSvc.ListWalletTransactions(ctx, request)
 	if err != nil {
-		zap.L().Error("Failed to list wallet transactions",
+		s.logger.Error("Failed to list wallet transactions",
 			zap.String("wallet_id", walletId),
 			zap.Error(err))
 		return nil, fmt.Errorf("unable to list wallet transactions: %w", err)
 	}
 
-	zap.L().Debug("Prime API response received",
+	s.logger.Debug("Prime API response received",
 		zap.String("wallet_id", walletId),
 		zap.Int("count", len(response.Transactions)))
 
 	// Log details of each transaction for debugging
 	for i, tx := range response.Transactions {
-		zap.L().Debug("Transaction details",
+		s.logger.Debug("Transaction details",
 			zap.Int("index", i),
 			zap.String("id", tx.Id),
 			zap.String("type", tx.Type),
@@ -316,3 +343,100 @@ func (s *Service) ListWalletTransactions(ctx context.Context, portfolioId, walle
 
 	return response, nil
 }
+
+// WalletTransactions lists up to limit of a wallet's most recent transactions since a time, converted
+// to the listener's format. A limit of zero uses the default page size.
+func (s *Service) WalletTransactions(ctx context.Context, portfolioId, walletId string, since time.Time, limit int) ([]models.PrimeTransaction, error) {
+	response, err := s.ListWalletTransactions(ctx, portfolioId, walletId, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert Prime SDK response to our internal format
+	transactions := make([]models.PrimeTransaction, 0)
+
+	for _, tx := range response.Transactions {
+		transactions = append(transactions, s.toPrimeTransaction(tx))
+	}
+
+	s.logger.Debug("Converted Prime transactions",
+		zap.String("wallet_id", walletId),
+		zap.Int("count", len(transactions)))
+
+	return transactions, nil
+}
+
+// GetTransaction fetches one transaction by id, converted to the listener's format
+func (s *Service) GetTransaction(ctx context.Context, portfolioId, transactionId string) (*models.PrimeTransaction, error) {
+	response, err := s.transactionsSvc.GetTransaction(ctx, &transactions.GetTransactionRequest{
+		PortfolioId:   portfolioId,
+		TransactionId: transactionId,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get transaction %s: %w", transactionId, err)
+	}
+	if response.Transaction == nil {
+		return nil, fmt.Errorf("transaction %s not found", transactionId)
+	}
+
+	transaction := s.toPrimeTransaction(response.Transaction)
+	return &transaction, nil
+}
+
+// toPrimeTransaction converts a Prime SDK transaction to the listener's format
+func (s *Service) toPrimeTransaction(tx *model.Transaction) models.PrimeTransaction {
+	// Transaction times are already time.Time in the SDK
+	createdAt := tx.Created
+	completedAt := tx.Completed
+
+	// Convert to our internal format
+	primeTransaction := models.PrimeTransaction{
+		Id:             tx.Id,
+		WalletId:       tx.WalletId,
+		Type:           tx.Type,
+		Status:         tx.Status,
+		Symbol:         tx.Symbol,
+		Amount:         tx.Amount,
+		CreatedAt:      createdAt,
+		CompletedAt:    completedAt,
+		TransactionId:  tx.TransactionId,
+		Network:        tx.Network,
+		IdempotencyKey: tx.IdempotencyKey,
+		BlockchainIds:  tx.BlockchainIds,
+	}
+
+	// Extract transfer_to information
+	if tx.TransferTo != nil {
+		primeTransaction.TransferTo.Type = tx.TransferTo.Type
+		primeTransaction.TransferTo.Value = tx.TransferTo.Value
+		primeTransaction.TransferTo.Address = tx.TransferTo.Address
+		primeTransaction.TransferTo.AccountIdentifier = tx.TransferTo.AccountIdentifier
+	}
+
+	// Extract transfer_from information (identifies the counterparty on internal transfers)
+	if tx.TransferFrom != nil {
+		primeTransaction.TransferFrom.Type = tx.TransferFrom.Type
+		primeTransaction.TransferFrom.Value = tx.TransferFrom.Value
+		primeTransaction.TransferFrom.Address = tx.TransferFrom.Address
+		primeTransaction.TransferFrom.AccountIdentifier = tx.TransferFrom.AccountIdentifier
+	}
+
+	if tx.Metadata != nil && tx.Metadata.MatchMetadata != nil {
+		primeTransaction.MatchReference = tx.Metadata.MatchMetadata.ReferenceId
+	}
+
+	if raw, err := json.Marshal(tx); err == nil {
+		primeTransaction.Raw = raw
+	}
+
+	// A transaction that cannot be normalized keeps a zero signed amount and is skipped by the listener
+	if err := NormalizeAmount(&primeTransaction); err != nil {
+		s.logger.Warn("Unable to normalize transaction amount",
+			zap.String("transaction_id", tx.Id),
+			zap.String("type", tx.Type),
+			zap.String("amount", tx.Amount),
+			zap.Error(err))
+	}
+
+	return primeTransaction
+}
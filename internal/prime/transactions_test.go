@@ -0,0 +1,52 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package prime
+
+import (
+	"testing"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"go.uber.org/zap/zaptest"
+)
+
+func TestToPrimeTransaction(t *testing.T) {
+	service := &Service{logger: zaptest.NewLogger(t)}
+	tx := service.toPrimeTransaction(&model.Transaction{
+		Id:             "prime-tx-1",
+		WalletId:       "wallet-1",
+		Type:           "WITHDRAWAL",
+		Status:         "TRANSACTION_DONE",
+		Symbol:         "ETH",
+		Amount:         "1.25",
+		Network:        "ethereum-mainnet",
+		IdempotencyKey: "user1-withdrawal",
+		TransferTo:     &model.Transfer{Type: "ADDRESS", Address: "0xdest"},
+	})
+
+	if tx.Id != "prime-tx-1" || tx.WalletId != "wallet-1" || tx.IdempotencyKey != "user1-withdrawal" {
+		t.Errorf("Unexpected identifiers: %+v", tx)
+	}
+	if tx.TransferTo.Address != "0xdest" || tx.TransferFrom.Address != "" {
+		t.Errorf("Unexpected transfer endpoints: %+v %+v", tx.TransferTo, tx.TransferFrom)
+	}
+	if tx.SignedAmount.String() != "-1.25" {
+		t.Errorf("Expected a normalized withdrawal amount of -1.25, got %s", tx.SignedAmount)
+	}
+	if len(tx.Raw) == 0 {
+		t.Error("Expected the raw payload to be kept")
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package screening
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"prime-send-receive-go/internal/models"
+)
+
+// RuleScreener applies the configured screening rules to inbound deposits. Its Screen method
+// satisfies database.DepositScreener; deployments with an external screening provider can
+// install their own function instead.
+type RuleScreener struct {
+	cfg       models.ScreeningConfig
+	watchlist map[string]bool
+}
+
+func NewRuleScreener(cfg models.ScreeningConfig) *RuleScreener {
+	watchlist := make(map[string]bool, len(cfg.Watchlist))
+	for _, address := range cfg.Watchlist {
+		watchlist[strings.ToLower(address)] = true
+	}
+	return &RuleScreener{cfg: cfg, watchlist: watchlist}
+}
+
+// Screen holds a deposit when its source is watchlisted or unknown (if configured), or when it
+// exceeds the hold threshold for its asset
+func (r *RuleScreener) Screen(ctx context.Context, deposit models.DepositScreening) (models.ScreeningDecision, error) {
+	source := deposit.Source.Address
+	if source != "" && r.watchlist[strings.ToLower(source)] {
+		return models.ScreeningDecision{Hold: true, Reason: fmt.Sprintf("source %s is on the watchlist", source)}, nil
+	}
+
+	if source == "" && r.cfg.HoldUnknownSource {
+		return models.ScreeningDecision{Hold: true, Reason: "source address unknown"}, nil
+	}
+
+	if threshold, ok := r.cfg.HoldAbove[deposit.Asset]; ok && deposit.Amount.GreaterThan(threshold) {
+		return models.ScreeningDecision{
+			Hold:   true,
+			Reason: fmt.Sprintf("amount %s exceeds %s hold threshold %s", deposit.Amount, deposit.Asset, threshold),
+		}, nil
+	}
+
+	return models.ScreeningDecision{}, nil
+}
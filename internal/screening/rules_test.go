@@ -0,0 +1,60 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package screening
+
+import (
+	"context"
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestRuleScreener(t *testing.T) {
+	screener := NewRuleScreener(models.ScreeningConfig{
+		Enabled:           true,
+		HoldAbove:         map[string]decimal.Decimal{"USDC": decimal.NewFromInt(10000)},
+		HoldUnknownSource: true,
+		Watchlist:         []string{"0xBAD"},
+	})
+
+	tests := []struct {
+		name    string
+		deposit models.DepositScreening
+		hold    bool
+	}{
+		{"clean", models.DepositScreening{Asset: "USDC", Amount: decimal.NewFromInt(50), Source: models.DepositSource{Address: "0xgood"}}, false},
+		{"watchlisted case-insensitive", models.DepositScreening{Asset: "USDC", Amount: decimal.NewFromInt(1), Source: models.DepositSource{Address: "0xbad"}}, true},
+		{"unknown source", models.DepositScreening{Asset: "USDC", Amount: decimal.NewFromInt(1)}, true},
+		{"above threshold", models.DepositScreening{Asset: "USDC", Amount: decimal.NewFromInt(10001), Source: models.DepositSource{Address: "0xgood"}}, true},
+		{"asset without threshold", models.DepositScreening{Asset: "ETH", Amount: decimal.NewFromInt(10001), Source: models.DepositSource{Address: "0xgood"}}, false},
+	}
+
+	for _, tt := range tests {
+		decision, err := screener.Screen(context.Background(), tt.deposit)
+		if err != nil {
+			t.Fatalf("%s: Screen failed: %v", tt.name, err)
+		}
+		if decision.Hold != tt.hold {
+			t.Errorf("%s: expected hold=%v, got %v (%s)", tt.name, tt.hold, decision.Hold, decision.Reason)
+		}
+		if decision.Hold && decision.Reason == "" {
+			t.Errorf("%s: held deposit has no reason", tt.name)
+		}
+	}
+}
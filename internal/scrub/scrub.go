@@ -0,0 +1,41 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package scrub replaces customer identities in exported data with pseudonyms. Pseudonyms are derived
+// from the user id, so the same user gets the same pseudonym in every export and datasets can still
+// be joined without revealing who the customers are.
+package scrub
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Name returns the pseudonym that replaces a user's name
+func Name(userId string) string {
+	return "User " + pseudonym(userId)
+}
+
+// Email returns the pseudonym that replaces a user's email address. The example.invalid domain
+// can never receive mail, so a scrubbed dataset cannot be used to contact anyone.
+func Email(userId string) string {
+	return "user-" + pseudonym(userId) + "@example.invalid"
+}
+
+func pseudonym(userId string) string {
+	sum := sha256.Sum256([]byte(userId))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,37 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package scrub
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPseudonymsAreStablePerUser(t *testing.T) {
+	if Email("user-1") != Email("user-1") || Name("user-1") != Name("user-1") {
+		t.Error("Expected the same pseudonym for the same user")
+	}
+	if Email("user-1") == Email("user-2") {
+		t.Error("Expected different users to get different pseudonyms")
+	}
+	if !strings.HasSuffix(Email("user-1"), "@example.invalid") || strings.Contains(Email("user-1"), "user-1@") {
+		t.Errorf("Unexpected scrubbed email %q", Email("user-1"))
+	}
+	if strings.TrimPrefix(Name("user-1"), "User ") != strings.TrimSuffix(strings.TrimPrefix(Email("user-1"), "user-"), "@example.invalid") {
+		t.Errorf("Expected name and email to share a pseudonym, got %q and %q", Name("user-1"), Email("user-1"))
+	}
+}
@@ -0,0 +1,170 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+	"prime-send-receive-go/internal/prime"
+
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// Recommendations for a hot wallet position
+const (
+	RecommendationNone  = "ok"
+	RecommendationTopUp = "top-up"
+	RecommendationSweep = "sweep"
+)
+
+// Position is the treasury view of one asset
+type Position struct {
+	Asset              string
+	HotBalance         decimal.Decimal
+	VaultBalance       decimal.Decimal
+	Liabilities        decimal.Decimal
+	PendingWithdrawals decimal.Decimal
+	// NetExposure is the hot balance less pending withdrawals and liabilities. Negative means
+	// customer funds are held outside the hot wallet (e.g. in vault).
+	NetExposure       decimal.Decimal
+	Recommendation    string
+	RecommendedAmount decimal.Decimal
+}
+
+// Service builds intraday treasury positions from the ledger and Prime portfolio balances
+type Service struct {
+	dbService    *database.Service
+	primeService *prime.Service
+	portfolioId  string
+	cfg          models.TreasuryConfig
+	logger       *zap.Logger
+}
+
+func NewService(dbService *database.Service, primeService *prime.Service, portfolioId string, cfg models.TreasuryConfig, logger *zap.Logger) *Service {
+	return &Service{
+		dbService:    dbService,
+		primeService: primeService,
+		portfolioId:  portfolioId,
+		cfg:          cfg,
+		logger:       logger,
+	}
+}
+
+// Positions returns the current position for every asset held in Prime or owed to customers
+func (s *Service) Positions(ctx context.Context) ([]Position, error) {
+	hot, err := s.portfolioBalances(ctx, prime.BalanceTypeTrading)
+	if err != nil {
+		return nil, err
+	}
+
+	vault, err := s.portfolioBalances(ctx, prime.BalanceTypeVault)
+	if err != nil {
+		return nil, err
+	}
+
+	assetTotals, err := s.dbService.GetAssetTotals(ctx, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load customer balances: %w", err)
+	}
+	liabilities := make(map[string]decimal.Decimal)
+	for _, total := range assetTotals {
+		liabilities[total.Asset] = total.TotalLiability
+	}
+
+	// Held withdrawals stay in the ledger balances until captured but are no longer owed once they settle;
+	// queued ones are covered as pending withdrawals instead
+	held, err := s.dbService.HeldWithdrawalTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for asset, amount := range held {
+		liabilities[asset] = liabilities[asset].Sub(amount)
+	}
+
+	pending, err := s.dbService.PendingWithdrawalTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildPositions(hot, vault, liabilities, pending, s.cfg), nil
+}
+
+func (s *Service) portfolioBalances(ctx context.Context, balanceType string) (map[string]decimal.Decimal, error) {
+	balances, err := s.primeService.ListPortfolioBalances(ctx, s.portfolioId, balanceType)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]decimal.Decimal)
+	for _, balance := range balances {
+		totals[balance.Symbol] = totals[balance.Symbol].Add(balance.Amount)
+	}
+	return totals, nil
+}
+
+// BuildPositions combines per-asset amounts into positions sorted by asset. Pending withdrawals must
+// always be covered by the hot wallet; on top of that the hot wallet should hold between the min and
+// max ratio of liabilities. Outside that band the recommendation moves it back to the target ratio.
+func BuildPositions(hot, vault, liabilities, pending map[string]decimal.Decimal, cfg models.TreasuryConfig) []Position {
+	assets := make(map[string]bool)
+	for _, amounts := range []map[string]decimal.Decimal{hot, liabilities, pending} {
+		for asset := range amounts {
+			assets[asset] = true
+		}
+	}
+
+	positions := make([]Position, 0, len(assets))
+	for asset := range assets {
+		position := Position{
+			Asset:              asset,
+			HotBalance:         hot[asset],
+			VaultBalance:       vault[asset],
+			Liabilities:        liabilities[asset],
+			PendingWithdrawals: pending[asset],
+			Recommendation:     RecommendationNone,
+		}
+		position.NetExposure = position.HotBalance.Sub(position.PendingWithdrawals).Sub(position.Liabilities)
+
+		// Overdrawn customer accounts are not a reason to hold less in the hot wallet
+		owed := decimal.Max(position.Liabilities, decimal.Zero)
+		low := position.PendingWithdrawals.Add(owed.Mul(cfg.MinHotRatio))
+		target := position.PendingWithdrawals.Add(owed.Mul(cfg.TargetHotRatio))
+		high := position.PendingWithdrawals.Add(owed.Mul(cfg.MaxHotRatio))
+
+		switch {
+		case position.HotBalance.LessThan(low):
+			position.Recommendation = RecommendationTopUp
+			position.RecommendedAmount = target.Sub(position.HotBalance)
+		case position.HotBalance.GreaterThan(high):
+			position.Recommendation = RecommendationSweep
+			position.RecommendedAmount = position.HotBalance.Sub(target)
+		}
+
+		positions = append(positions, position)
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Asset < positions[j].Asset
+	})
+
+	return positions
+}
@@ -0,0 +1,106 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package treasury
+
+import (
+	"testing"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestBuildPositions(t *testing.T) {
+	cfg := models.TreasuryConfig{
+		MinHotRatio:    decimal.NewFromFloat(0.1),
+		TargetHotRatio: decimal.NewFromFloat(0.2),
+		MaxHotRatio:    decimal.NewFromFloat(0.4),
+	}
+
+	hot := map[string]decimal.Decimal{
+		"USDC": decimal.NewFromInt(50),
+		"ETH":  decimal.NewFromInt(30),
+		"SOL":  decimal.NewFromInt(60),
+	}
+	vault := map[string]decimal.Decimal{
+		"USDC": decimal.NewFromInt(900),
+	}
+	liabilities := map[string]decimal.Decimal{
+		"USDC": decimal.NewFromInt(1000),
+		"ETH":  decimal.NewFromInt(100),
+		"SOL":  decimal.NewFromInt(100),
+	}
+	pending := map[string]decimal.Decimal{
+		"USDC": decimal.NewFromInt(20),
+	}
+
+	positions := BuildPositions(hot, vault, liabilities, pending, cfg)
+	if len(positions) != 3 {
+		t.Fatalf("Expected 3 positions, got %d", len(positions))
+	}
+
+	want := map[string]struct {
+		recommendation string
+		amount         int64
+		netExposure    int64
+	}{
+		// Needs 20 pending + 100 minimum; top up to 20 + 200
+		"USDC": {RecommendationTopUp, 170, -970},
+		// Between 10 and 40
+		"ETH": {RecommendationNone, 0, -70},
+		// Above 40; sweep down to 20
+		"SOL": {RecommendationSweep, 40, -40},
+	}
+
+	for _, position := range positions {
+		expected := want[position.Asset]
+		if position.Recommendation != expected.recommendation {
+			t.Errorf("%s: expected recommendation %s, got %s", position.Asset, expected.recommendation, position.Recommendation)
+		}
+		if !position.RecommendedAmount.Equal(decimal.NewFromInt(expected.amount)) {
+			t.Errorf("%s: expected amount %d, got %s", position.Asset, expected.amount, position.RecommendedAmount)
+		}
+		if !position.NetExposure.Equal(decimal.NewFromInt(expected.netExposure)) {
+			t.Errorf("%s: expected net exposure %d, got %s", position.Asset, expected.netExposure, position.NetExposure)
+		}
+	}
+
+	if positions[0].Asset != "ETH" || positions[2].Asset != "USDC" {
+		t.Errorf("Expected positions sorted by asset, got %s..%s", positions[0].Asset, positions[2].Asset)
+	}
+}
+
+func TestTopUpAmount(t *testing.T) {
+	tests := []struct {
+		withdrawable int64
+		amount       int64
+		pending      int64
+		want         int64
+	}{
+		// Covers everything queued against the wallet
+		{10, 50, 120, 110},
+		// Pending totals exclude withdrawals submitted outside the queue
+		{10, 50, 0, 40},
+	}
+
+	for _, tt := range tests {
+		got := topUpAmount(decimal.NewFromInt(tt.withdrawable), decimal.NewFromInt(tt.amount), decimal.NewFromInt(tt.pending))
+		if !got.Equal(decimal.NewFromInt(tt.want)) {
+			t.Errorf("topUpAmount(%d, %d, %d) = %s, want %d", tt.withdrawable, tt.amount, tt.pending, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package treasury
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/prime"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+)
+
+// EnsureHotBalance reports whether a hot wallet can fund a withdrawal of amount. When it cannot, a
+// vault to hot wallet transfer is requested (once per wallet) and false is returned so the caller
+// keeps the withdrawal queued. The transfer still needs approval in Prime; the wallet balance is
+// rechecked on every call and the top-up is marked funded once it covers the withdrawal. A top-up
+// that is not funded within the timeout is expired and the withdrawal is let through.
+func (s *Service) EnsureHotBalance(ctx context.Context, walletId, asset string, amount decimal.Decimal) (bool, error) {
+	balance, err := s.primeService.GetWalletBalance(ctx, s.portfolioId, walletId)
+	if err != nil {
+		return false, err
+	}
+
+	openTopUp, err := s.dbService.GetOpenTreasuryTopUp(ctx, walletId)
+	if err != nil {
+		return false, err
+	}
+
+	if balance.Withdrawable.GreaterThanOrEqual(amount) {
+		if openTopUp != nil {
+			s.logger.Info("Hot wallet top-up funded",
+				zap.String("top_up_id", openTopUp.Id),
+				zap.String("wallet_id", walletId),
+				zap.String("asset", asset))
+			if err := s.dbService.UpdateTreasuryTopUpStatus(ctx, openTopUp.Id, database.TopUpStatusFunded); err != nil {
+				return true, err
+			}
+		}
+		return true, nil
+	}
+
+	if openTopUp != nil {
+		if time.Since(openTopUp.CreatedAt) < s.cfg.TopUpTimeout {
+			return false, nil
+		}
+
+		s.logger.Warn("Hot wallet top-up not funded before timeout - releasing withdrawals to Prime",
+			zap.String("top_up_id", openTopUp.Id),
+			zap.String("activity_id", openTopUp.ActivityId),
+			zap.String("wallet_id", walletId),
+			zap.Duration("timeout", s.cfg.TopUpTimeout))
+		if err := s.dbService.UpdateTreasuryTopUpStatus(ctx, openTopUp.Id, database.TopUpStatusExpired); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	pending, err := s.dbService.PendingWithdrawalTotals(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	topUp := topUpAmount(balance.Withdrawable, amount, pending[asset])
+	if err := s.requestTopUp(ctx, walletId, asset, topUp); err != nil {
+		return false, err
+	}
+
+	return false, nil
+}
+
+// topUpAmount covers every withdrawal waiting on the wallet, and at least the one being submitted
+func topUpAmount(withdrawable, amount, pending decimal.Decimal) decimal.Decimal {
+	return decimal.Max(amount, pending).Sub(withdrawable)
+}
+
+// requestTopUp transfers amount from the asset's vault wallet into the hot wallet
+func (s *Service) requestTopUp(ctx context.Context, walletId, asset string, amount decimal.Decimal) error {
+	vaults, err := s.primeService.ListWallets(ctx, s.portfolioId, "VAULT", []string{asset})
+	if err != nil {
+		return err
+	}
+	if len(vaults) == 0 {
+		return fmt.Errorf("no %s vault wallet to top up from", asset)
+	}
+	vaultWalletId := vaults[0].Id
+
+	transfer, err := s.primeService.CreateWithdrawal(ctx, prime.CreateWithdrawalParams{
+		PortfolioId:     s.portfolioId,
+		WalletId:        vaultWalletId,
+		DestinationType: prime.DestinationTypeWallet,
+		Destination:     walletId,
+		Amount:          amount.String(),
+		Asset:           asset,
+		IdempotencyKey:  uuid.New().String(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to request hot wallet top-up: %w", err)
+	}
+
+	topUpId, err := s.dbService.RecordTreasuryTopUp(ctx, asset, vaultWalletId, walletId, amount, transfer.ActivityId)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Warn("Requested vault to hot wallet top-up - approve the transfer in Prime",
+		zap.String("top_up_id", topUpId),
+		zap.String("activity_id", transfer.ActivityId),
+		zap.String("asset", asset),
+		zap.String("amount", amount.String()),
+		zap.String("vault_wallet_id", vaultWalletId),
+		zap.String("wallet_id", walletId))
+
+	return nil
+}
+
+// TopUpRecheckInterval is how long withdrawals wait between hot wallet balance checks
+func (s *Service) TopUpRecheckInterval() time.Duration {
+	return s.cfg.TopUpRecheckInterval
+}
@@ -0,0 +1,191 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdiff
+
+import (
+	"sort"
+	"time"
+
+	"prime-send-receive-go/internal/database"
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/shopspring/decimal"
+)
+
+// Finding kinds
+const (
+	KindPrimeOnly      = "PRIME_ONLY"
+	KindLedgerOnly     = "LEDGER_ONLY"
+	KindAmountMismatch = "AMOUNT_MISMATCH"
+)
+
+// Deposit statuses in the order Prime moves through them, used to decide whether a deposit should
+// have been credited yet
+var depositStatusRank = map[string]int{
+	"TRANSACTION_IMPORT_PENDING": 1,
+	"TRANSACTION_IMPORTED":       2,
+	"TRANSACTION_DONE":           3,
+}
+
+// Finding is one difference between Prime and the ledger
+type Finding struct {
+	Kind        string
+	Key         string
+	PrimeTx     *model.Transaction
+	LedgerEntry *models.LedgerSyncEntry
+	Suggestion  string
+}
+
+// Options controls which transactions are expected on both sides
+type Options struct {
+	// Start and End bound the reported range; transactions outside it are only used for matching
+	Start time.Time
+	End   time.Time
+	// CreditStatus returns the Prime status at which deposits on a network are credited
+	CreditStatus func(network string) string
+}
+
+// Result summarises a diff
+type Result struct {
+	Matched  int
+	Findings []Finding
+}
+
+// Diff cross-references Prime wallet transactions with ledger entries. A ledger entry matches a Prime
+// transaction by external transaction id, which is the Prime transaction id for deposits and the
+// idempotency key (or its batch id) for withdrawals.
+func Diff(primeTxs []*model.Transaction, ledger []models.LedgerSyncEntry, opts Options) Result {
+	ledgerByKey := make(map[string][]int)
+	for i, entry := range ledger {
+		key := ledgerKey(entry)
+		ledgerByKey[key] = append(ledgerByKey[key], i)
+	}
+
+	var result Result
+	matchedLedger := make(map[int]bool)
+
+	for _, tx := range primeTxs {
+		key, indexes := tx.Id, ledgerByKey[tx.Id]
+		if len(indexes) == 0 && tx.IdempotencyKey != "" {
+			key, indexes = tx.IdempotencyKey, ledgerByKey[tx.IdempotencyKey]
+		}
+
+		if len(indexes) == 0 {
+			if expectedInLedger(tx, opts) && inRange(tx.Created, opts) {
+				result.Findings = append(result.Findings, Finding{
+					Kind:       KindPrimeOnly,
+					Key:        tx.Id,
+					PrimeTx:    tx,
+					Suggestion: primeOnlySuggestion(tx),
+				})
+			}
+			continue
+		}
+
+		for _, i := range indexes {
+			matchedLedger[i] = true
+		}
+		result.Matched++
+
+		// Batched withdrawals debit several users, so only single entries are compared by amount
+		entry := ledger[indexes[0]]
+		if len(indexes) == 1 && entry.BatchId == "" {
+			if primeAmount, err := decimal.NewFromString(tx.Amount); err == nil && !primeAmount.Abs().Equal(entry.Amount.Abs()) {
+				result.Findings = append(result.Findings, Finding{
+					Kind:        KindAmountMismatch,
+					Key:         key,
+					PrimeTx:     tx,
+					LedgerEntry: &entry,
+					Suggestion:  "Ledger amount differs from Prime; correct the ledger entry after checking the transaction in Prime",
+				})
+			}
+		}
+	}
+
+	for i := range ledger {
+		if matchedLedger[i] || !inRange(ledger[i].CreatedAt, opts) {
+			continue
+		}
+		entry := ledger[i]
+		result.Findings = append(result.Findings, Finding{
+			Kind:        KindLedgerOnly,
+			Key:         entry.ExternalTransactionId,
+			LedgerEntry: &entry,
+			Suggestion:  ledgerOnlySuggestion(entry),
+		})
+	}
+
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		return findingTime(result.Findings[i]).Before(findingTime(result.Findings[j]))
+	})
+	return result
+}
+
+func ledgerKey(entry models.LedgerSyncEntry) string {
+	if entry.BatchId != "" {
+		return entry.BatchId
+	}
+	return entry.ExternalTransactionId
+}
+
+func inRange(t time.Time, opts Options) bool {
+	return !t.Before(opts.Start) && t.Before(opts.End)
+}
+
+// expectedInLedger reports whether the listener would have recorded the transaction by now
+func expectedInLedger(tx *model.Transaction, opts Options) bool {
+	switch tx.Type {
+	case "DEPOSIT":
+		creditStatus := models.DefaultDepositCreditStatus
+		if opts.CreditStatus != nil {
+			creditStatus = opts.CreditStatus(tx.Network)
+		}
+		rank, ok := depositStatusRank[tx.Status]
+		return ok && rank >= depositStatusRank[creditStatus]
+	case "WITHDRAWAL":
+		return tx.Status == "TRANSACTION_DONE"
+	}
+	return false
+}
+
+func primeOnlySuggestion(tx *model.Transaction) string {
+	if tx.Type == "DEPOSIT" {
+		return "Missed credit: check the deposit address is stored (cmd/addresses, cmd/import-addresses) - deposits to unknown addresses and dust are skipped by the listener"
+	}
+	return "Withdrawal not debited: if it was made outside this ledger no action is needed, otherwise debit the user manually"
+}
+
+func ledgerOnlySuggestion(entry models.LedgerSyncEntry) string {
+	switch {
+	case entry.QueueStatus == database.WithdrawalQueueStatusQueued || entry.QueueStatus == database.WithdrawalQueueStatusProcessing:
+		return "Queued withdrawal not yet submitted to Prime - no action needed unless it is stuck in the queue"
+	case entry.QueueStatus == database.WithdrawalQueueStatusFailed:
+		return "Queued withdrawal failed before reaching Prime - check the debit was reversed"
+	case entry.TransactionType == "withdrawal":
+		return "Phantom debit: no Prime withdrawal found - if the Prime call failed, credit the amount back to the user"
+	default:
+		return "Phantom credit: no Prime deposit found - check the transaction id in Prime and reverse the credit if it is not real"
+	}
+}
+
+func findingTime(f Finding) time.Time {
+	if f.PrimeTx != nil {
+		return f.PrimeTx.Created
+	}
+	return f.LedgerEntry.CreatedAt
+}
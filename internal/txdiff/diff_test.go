@@ -0,0 +1,72 @@
+/**
+ * Copyright 2025-present Coinbase Global, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package txdiff
+
+import (
+	"testing"
+	"time"
+
+	"prime-send-receive-go/internal/models"
+
+	"github.com/coinbase-samples/prime-sdk-go/model"
+	"github.com/shopspring/decimal"
+)
+
+func TestDiff(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return start.Add(time.Duration(hours) * time.Hour) }
+
+	primeTxs := []*model.Transaction{
+		{Id: "dep-matched", Type: "DEPOSIT", Status: "TRANSACTION_IMPORTED", Amount: "1", Created: at(1)},
+		{Id: "dep-missed", Type: "DEPOSIT", Status: "TRANSACTION_DONE", Amount: "2", Created: at(2)},
+		{Id: "dep-pending", Type: "DEPOSIT", Status: "TRANSACTION_IMPORT_PENDING", Amount: "3", Created: at(3)},
+		{Id: "wd-1", IdempotencyKey: "key-1", Type: "WITHDRAWAL", Status: "TRANSACTION_DONE", Amount: "-4", Created: at(4)},
+		{Id: "wd-batch", IdempotencyKey: "batch-1", Type: "WITHDRAWAL", Status: "TRANSACTION_DONE", Amount: "-3", Created: at(5)},
+		{Id: "dep-wrong-amount", Type: "DEPOSIT", Status: "TRANSACTION_DONE", Amount: "7", Created: at(6)},
+		{Id: "dep-before-range", Type: "DEPOSIT", Status: "TRANSACTION_DONE", Amount: "1", Created: start.Add(-time.Hour)},
+	}
+	ledger := []models.LedgerSyncEntry{
+		{ExternalTransactionId: "dep-matched", TransactionType: "deposit", Amount: decimal.NewFromInt(1), CreatedAt: at(1)},
+		{ExternalTransactionId: "key-1", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-4), CreatedAt: at(4)},
+		{ExternalTransactionId: "item-a", BatchId: "batch-1", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-1), CreatedAt: at(5)},
+		{ExternalTransactionId: "item-b", BatchId: "batch-1", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-2), CreatedAt: at(5)},
+		{ExternalTransactionId: "dep-wrong-amount", TransactionType: "deposit", Amount: decimal.NewFromInt(6), CreatedAt: at(6)},
+		{ExternalTransactionId: "dep-phantom", TransactionType: "deposit", Amount: decimal.NewFromInt(9), CreatedAt: at(7)},
+		{ExternalTransactionId: "key-queued", QueueStatus: "queued", TransactionType: "withdrawal", Amount: decimal.NewFromInt(-1), CreatedAt: at(8)},
+	}
+
+	result := Diff(primeTxs, ledger, Options{Start: start, End: at(24)})
+
+	if result.Matched != 4 {
+		t.Errorf("Expected 4 matched Prime transactions, got %d", result.Matched)
+	}
+
+	want := []struct{ kind, key string }{
+		{KindPrimeOnly, "dep-missed"},
+		{KindAmountMismatch, "dep-wrong-amount"},
+		{KindLedgerOnly, "dep-phantom"},
+		{KindLedgerOnly, "key-queued"},
+	}
+	if len(result.Findings) != len(want) {
+		t.Fatalf("Expected %d findings, got %+v", len(want), result.Findings)
+	}
+	for i, w := range want {
+		if result.Findings[i].Kind != w.kind || result.Findings[i].Key != w.key {
+			t.Errorf("Finding %d: expected %s %s, got %s %s", i, w.kind, w.key, result.Findings[i].Kind, result.Findings[i].Key)
+		}
+	}
+}